@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "./SignatureChecker.sol";
+import "@openzeppelin/contracts/utils/cryptography/ECDSA.sol";
+import "@openzeppelin/contracts/utils/structs/EnumerableSet.sol";
+
+/**
+@title DelegationChecker
+@notice Verification of signatures by short-lived session keys, themselves
+authorised by a long-lived signer with the ethier Go eth.NewDelegatedSigner()
+function. This allows automated processes, e.g. minting bots, to hold only a
+session key that is limited in both scope and time.
+ */
+library DelegationChecker {
+    using EnumerableSet for EnumerableSet.AddressSet;
+    using SignatureChecker for EnumerableSet.AddressSet;
+
+    /**
+    @notice An authorisation for the session key to sign on behalf of the
+    delegating signer.
+    @dev The scope is defined by the verifying contract, conventionally as the
+    keccak256 hash of a human-readable name, and the expiry is a Unix timestamp,
+    in seconds, after which the delegation is no longer valid.
+     */
+    struct Delegation {
+        address session;
+        bytes32 scope;
+        uint256 expiry;
+    }
+
+    /**
+    @notice Returns the canonical encoding of the delegation, identical to that
+    of the Go eth.Delegation.Encode() method.
+     */
+    function encode(Delegation memory delegation)
+        internal
+        pure
+        returns (bytes memory)
+    {
+        return
+            abi.encode(
+                delegation.session,
+                delegation.scope,
+                delegation.expiry
+            );
+    }
+
+    /**
+    @notice Requires that the delegation is for the expected scope, has not
+    expired, and is signed by a member of the signers AddressSet, and that the
+    data, which MUST NOT have been used previously, is signed by the delegation's
+    session key. The data is then marked as used.
+    @param signers Set of addresses from which delegations are accepted.
+    @param delegation The delegation to the session key.
+    @param authorisation ECDSA signature of the delegation's encoding.
+    @param scope Scope that the delegation must have.
+    @param data Data signed by the session key.
+    @param signature ECDSA signature of data by the session key.
+    @param usedMessages Set of already-used messages.
+     */
+    function requireValidDelegatedSignature(
+        EnumerableSet.AddressSet storage signers,
+        Delegation memory delegation,
+        bytes calldata authorisation,
+        bytes32 scope,
+        bytes memory data,
+        bytes calldata signature,
+        mapping(bytes32 => bool) storage usedMessages
+    ) internal {
+        require(delegation.scope == scope, "DelegationChecker: Wrong scope");
+        require(
+            block.timestamp <= delegation.expiry,
+            "DelegationChecker: Expired"
+        );
+        signers.requireValidSignature(encode(delegation), authorisation);
+
+        bytes32 message = SignatureChecker.generateMessage(data);
+        require(
+            !usedMessages[message],
+            "SignatureChecker: Message already used"
+        );
+        usedMessages[message] = true;
+        require(
+            ECDSA.recover(message, signature) == delegation.session,
+            "DelegationChecker: Invalid session signature"
+        );
+    }
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/access/Ownable.sol";
+import "@openzeppelin/contracts/utils/cryptography/MerkleProof.sol";
+
+/**
+@title MerkleAllowlist
+@notice An allowlist of addresses, each with a maximum number of claimable
+items, committed to by a Merkle root. Trees and proofs are generated with the
+ethier Go eth/merkle package, using FromAllowances() and ProofForAllowance().
+ */
+abstract contract MerkleAllowlist is Ownable {
+    /// @notice Root of the tree of allowlistLeaf() values.
+    bytes32 public merkleRoot;
+
+    /// @notice Number of items already claimed by each address.
+    mapping(address => uint256) public allowlistClaimed;
+
+    /// @notice Sets the Merkle root of the allowlist.
+    function setMerkleRoot(bytes32 root) external onlyOwner {
+        merkleRoot = root;
+    }
+
+    /**
+    @notice Returns the leaf for the address and its allowance, identical to
+    the Go eth/merkle.AllowanceLeaf() function.
+     */
+    function allowlistLeaf(address addr, uint256 allowance)
+        public
+        pure
+        returns (bytes32)
+    {
+        return keccak256(abi.encodePacked(addr, allowance));
+    }
+
+    /**
+    @notice Requires that the proof demonstrates the address's allowance, and
+    that the address hasn't already claimed more than allowance-n items. The n
+    items are then recorded as claimed.
+    @dev Inheriting contracts SHOULD call this before minting n items to addr.
+     */
+    function _claimAllowlisted(
+        address addr,
+        uint256 n,
+        uint256 allowance,
+        bytes32[] calldata proof
+    ) internal {
+        require(
+            MerkleProof.verify(
+                proof,
+                merkleRoot,
+                allowlistLeaf(addr, allowance)
+            ),
+            "MerkleAllowlist: Invalid proof"
+        );
+
+        uint256 claimed = allowlistClaimed[addr] + n;
+        require(claimed <= allowance, "MerkleAllowlist: Exceeds allowance");
+        allowlistClaimed[addr] = claimed;
+    }
+}
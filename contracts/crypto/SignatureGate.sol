@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "./SignerManager.sol";
+import "@openzeppelin/contracts/utils/cryptography/ECDSA.sol";
+import "@openzeppelin/contracts/utils/cryptography/draft-EIP712.sol";
+import "@openzeppelin/contracts/utils/structs/EnumerableSet.sol";
+
+/**
+@title SignatureGate
+@notice Gates actions, e.g. minting, behind EIP-712 vouchers signed off-chain
+by any of a set of signers, managed by the contract owner. Vouchers are signed
+with the ethier Go eth.Signer.SignTypedVoucher() method.
+@dev Each voucher allows its recipient to claim up to its allowance, in any
+number of calls, until its expiry. Vouchers are identified by their nonces,
+which MUST therefore be unique; eth.NewVoucher() generates random nonces.
+ */
+abstract contract SignatureGate is SignerManager, EIP712 {
+    using EnumerableSet for EnumerableSet.AddressSet;
+
+    /**
+    @notice A signed authorisation for the recipient to claim up to allowance
+    items, valid until expiry (a Unix timestamp, in seconds).
+     */
+    struct Voucher {
+        address recipient;
+        uint256 allowance;
+        bytes32 nonce;
+        uint256 expiry;
+    }
+
+    /// @notice The EIP-712 type hash of a Voucher.
+    bytes32 public constant VOUCHER_TYPEHASH =
+        keccak256(
+            "Voucher(address recipient,uint256 allowance,bytes32 nonce,uint256 expiry)"
+        );
+
+    /// @notice Number of items claimed against each voucher, keyed by nonce.
+    mapping(bytes32 => uint256) public voucherClaimed;
+
+    /**
+    @param name EIP-712 domain name, which MUST be mirrored in the Go
+    eth.VoucherDomain.
+    @param version EIP-712 domain version, as with name.
+     */
+    constructor(string memory name, string memory version)
+        EIP712(name, version)
+    {} // solhint-disable-line no-empty-blocks
+
+    /// @notice Returns the EIP-712 digest of the voucher, as signed.
+    function voucherDigest(Voucher memory voucher)
+        public
+        view
+        returns (bytes32)
+    {
+        return
+            _hashTypedDataV4(
+                keccak256(
+                    abi.encode(
+                        VOUCHER_TYPEHASH,
+                        voucher.recipient,
+                        voucher.allowance,
+                        voucher.nonce,
+                        voucher.expiry
+                    )
+                )
+            );
+    }
+
+    /**
+    @notice Requires that the voucher has not expired, is signed by one of the
+    signers, and has at least n of its allowance remaining. The n items are
+    then recorded as claimed against the voucher.
+    @dev Inheriting contracts SHOULD call this before e.g. minting n items to
+    voucher.recipient; the caller isn't checked so anyone can submit a voucher
+    on behalf of its recipient.
+     */
+    function _claimWithVoucher(
+        Voucher memory voucher,
+        uint256 n,
+        bytes calldata signature
+    ) internal {
+        require(
+            block.timestamp <= voucher.expiry,
+            "SignatureGate: Expired"
+        );
+        require(
+            signers.contains(ECDSA.recover(voucherDigest(voucher), signature)),
+            "SignatureGate: Invalid signature"
+        );
+
+        uint256 claimed = voucherClaimed[voucher.nonce] + n;
+        require(
+            claimed <= voucher.allowance,
+            "SignatureGate: Exceeds allowance"
+        );
+        voucherClaimed[voucher.nonce] = claimed;
+    }
+}
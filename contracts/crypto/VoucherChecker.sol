@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "./SignatureChecker.sol";
+import "@openzeppelin/contracts/utils/structs/EnumerableSet.sol";
+
+/**
+@title VoucherChecker
+@notice Verification of single-use, expiring vouchers signed off-chain with the
+ethier Go eth.Signer.SignVoucher() method.
+ */
+library VoucherChecker {
+    using EnumerableSet for EnumerableSet.AddressSet;
+    using SignatureChecker for EnumerableSet.AddressSet;
+
+    /**
+    @notice A signed authorisation for the recipient to perform an action, e.g.
+    a lazy mint or a claim.
+    @dev The value is interpreted by the verifying contract; e.g. a token ID or
+    an allowance. The expiry is a Unix timestamp, in seconds, after which the
+    voucher is no longer valid.
+     */
+    struct Voucher {
+        address recipient;
+        uint256 value;
+        bytes32 nonce;
+        uint256 expiry;
+    }
+
+    /**
+    @notice Returns the canonical encoding of the voucher, identical to that of
+    the Go eth.Voucher.Encode() method.
+     */
+    function encode(Voucher memory voucher)
+        internal
+        pure
+        returns (bytes memory)
+    {
+        return
+            abi.encode(
+                voucher.recipient,
+                voucher.value,
+                voucher.nonce,
+                voucher.expiry
+            );
+    }
+
+    /**
+    @notice Requires that the voucher has not expired, has not been used
+    previously, and is signed by a member of the signers AddressSet. The voucher
+    is then marked as used.
+    @param signers Set of addresses from which signatures are accepted.
+    @param voucher The voucher to be verified.
+    @param signature ECDSA signature of the voucher's encoding.
+    @param usedMessages Set of already-used messages.
+     */
+    function requireValidVoucher(
+        EnumerableSet.AddressSet storage signers,
+        Voucher memory voucher,
+        bytes calldata signature,
+        mapping(bytes32 => bool) storage usedMessages
+    ) internal {
+        require(
+            block.timestamp <= voucher.expiry,
+            "VoucherChecker: Expired"
+        );
+        signers.requireValidSignature(
+            encode(voucher),
+            signature,
+            usedMessages
+        );
+    }
+}
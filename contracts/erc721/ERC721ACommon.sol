@@ -3,14 +3,20 @@
 pragma solidity >=0.8.0 <0.9.0;
 
 import "./ERC721APreApproval.sol";
+import "../utils/ERC2981SinglePercentual.sol";
 import "../utils/OwnerPausable.sol";
 
 /**
 @notice An ERC721A contract with common functionality:
  - OpenSea gas-free listings
  - Pausable with toggling functions exposed to Owner only
+ - ERC2981 royalties, configurable by Owner only
  */
-contract ERC721ACommon is ERC721APreApproval, OwnerPausable {
+contract ERC721ACommon is
+    ERC721APreApproval,
+    OwnerPausable,
+    ERC2981SinglePercentual
+{
     constructor(string memory name, string memory symbol)
         ERC721A(name, symbol)
     {} // solhint-disable-line no-empty-blocks
@@ -41,14 +47,46 @@ contract ERC721ACommon is ERC721APreApproval, OwnerPausable {
         super._beforeTokenTransfers(from, to, startTokenId, quantity);
     }
 
+    /**
+    @notice Sets the default royalty, in basis points, for all tokens without a
+    per-token royalty.
+     */
+    function setDefaultRoyalty(address receiver, uint96 basisPoints)
+        public
+        onlyOwner
+    {
+        _setDefaultRoyalty(receiver, basisPoints);
+    }
+
+    /// @notice Removes the default royalty.
+    function deleteDefaultRoyalty() public onlyOwner {
+        _deleteDefaultRoyalty();
+    }
+
+    /// @notice Sets the royalty, in basis points, for a specific token.
+    function setTokenRoyalty(
+        uint256 tokenId,
+        address receiver,
+        uint96 basisPoints
+    ) public onlyOwner {
+        _setTokenRoyalty(tokenId, receiver, basisPoints);
+    }
+
+    /// @notice Reverts the token's royalty to the default.
+    function resetTokenRoyalty(uint256 tokenId) public onlyOwner {
+        _resetTokenRoyalty(tokenId);
+    }
+
     /// @notice Overrides supportsInterface as required by inheritance.
     function supportsInterface(bytes4 interfaceId)
         public
         view
         virtual
-        override(ERC721A)
+        override(ERC721A, ERC2981SinglePercentual)
         returns (bool)
     {
-        return super.supportsInterface(interfaceId);
+        return
+            ERC721A.supportsInterface(interfaceId) ||
+            ERC2981SinglePercentual.supportsInterface(interfaceId);
     }
 }
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "./ERC721ACommon.sol";
+
+/// @notice The ERC-4907 rental interface.
+/// @dev See https://eips.ethereum.org/EIPS/eip-4907.
+interface IERC4907 {
+    /// @notice Emitted when the user or expiry of a token is changed.
+    event UpdateUser(
+        uint256 indexed tokenId,
+        address indexed user,
+        uint64 expires
+    );
+
+    /// @notice Sets the user and expiry timestamp of the token.
+    function setUser(
+        uint256 tokenId,
+        address user,
+        uint64 expires
+    ) external;
+
+    /// @notice Returns the user of the token, or the zero address if expired.
+    function userOf(uint256 tokenId) external view returns (address);
+
+    /// @notice Returns the timestamp at which the token's user expires.
+    function userExpires(uint256 tokenId) external view returns (uint256);
+}
+
+/**
+@notice An ERC721ACommon with ERC-4907 rentals, whereby the owner of a token,
+or an account approved to manage it, can grant a user time-limited rights.
+@dev The user is reset when the token is transferred.
+ */
+contract ERC721ARentable is ERC721ACommon, IERC4907 {
+    constructor(string memory name, string memory symbol)
+        ERC721ACommon(name, symbol)
+    {} // solhint-disable-line no-empty-blocks
+
+    struct UserInfo {
+        address user;
+        uint64 expires;
+    }
+
+    /// @notice The user of each token, regardless of expiry.
+    mapping(uint256 => UserInfo) private _users;
+
+    /**
+    @notice Sets the user and expiry timestamp of the token.
+    @dev Unlike ERC721ACommon.onlyApprovedOrOwner(), operators approved for all
+    of the owner's tokens are also allowed to set the user, in keeping with the
+    ERC-4907 reference implementation.
+     */
+    function setUser(
+        uint256 tokenId,
+        address user,
+        uint64 expires
+    ) public virtual override {
+        address owner = ownerOf(tokenId);
+        require(
+            _msgSender() == owner ||
+                getApproved(tokenId) == _msgSender() ||
+                isApprovedForAll(owner, _msgSender()),
+            "ERC721ARentable: Not approved nor owner"
+        );
+
+        _users[tokenId] = UserInfo(user, expires);
+        emit UpdateUser(tokenId, user, expires);
+    }
+
+    /// @notice Returns the user of the token, or the zero address if expired.
+    function userOf(uint256 tokenId)
+        public
+        view
+        virtual
+        override
+        returns (address)
+    {
+        UserInfo memory info = _users[tokenId];
+        // solhint-disable-next-line not-rely-on-time
+        if (uint256(info.expires) < block.timestamp) {
+            return address(0);
+        }
+        return info.user;
+    }
+
+    /// @notice Returns the timestamp at which the token's user expires.
+    function userExpires(uint256 tokenId)
+        public
+        view
+        virtual
+        override
+        returns (uint256)
+    {
+        return _users[tokenId].expires;
+    }
+
+    /// @notice Resets the user of transferred tokens.
+    function _beforeTokenTransfers(
+        address from,
+        address to,
+        uint256 startTokenId,
+        uint256 quantity
+    ) internal virtual override {
+        super._beforeTokenTransfers(from, to, startTokenId, quantity);
+
+        // Minted tokens have no user so there is no need to iterate.
+        if (from == address(0) || from == to) {
+            return;
+        }
+        uint256 end = startTokenId + quantity;
+        for (uint256 tokenId = startTokenId; tokenId < end; tokenId++) {
+            if (_users[tokenId].user == address(0)) {
+                continue;
+            }
+            delete _users[tokenId];
+            emit UpdateUser(tokenId, address(0), 0);
+        }
+    }
+
+    /// @notice Overrides supportsInterface to include IERC4907.
+    function supportsInterface(bytes4 interfaceId)
+        public
+        view
+        virtual
+        override
+        returns (bool)
+    {
+        return
+            interfaceId == type(IERC4907).interfaceId ||
+            super.supportsInterface(interfaceId);
+    }
+}
@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "./ERC721ACommon.sol";
+import "../utils/TransferRestricted.sol";
+
+/// @notice An ERC721ACommon with transfers restricted by TransferRestricted.
+contract ERC721ATransferRestricted is ERC721ACommon, TransferRestricted {
+    constructor(string memory name, string memory symbol)
+        ERC721ACommon(name, symbol)
+    {} // solhint-disable-line no-empty-blocks
+
+    /// @notice Blocks transfers that are prohibited by TransferRestricted.
+    function _beforeTokenTransfers(
+        address from,
+        address to,
+        uint256 startTokenId,
+        uint256 quantity
+    ) internal virtual override {
+        TransferRestricted._requireTransferAllowed(from, to);
+        super._beforeTokenTransfers(from, to, startTokenId, quantity);
+    }
+}
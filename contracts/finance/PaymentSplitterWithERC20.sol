@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/finance/PaymentSplitter.sol";
+import "@openzeppelin/contracts/token/ERC20/IERC20.sol";
+
+/**
+@notice An OpenZeppelin PaymentSplitter extended with views of amounts due to
+payees, and batch release of multiple ERC20 tokens. Functions that release to
+all payees are internal, for exposure by inheriting contracts.
+@dev Releasing to all payees reverts if any single payee can't receive ETH, in
+which case the standard, per-payee release() functions remain available.
+ */
+contract PaymentSplitterWithERC20 is PaymentSplitter {
+    /// @notice Number of payees, for iteration with payee(i).
+    uint256 public immutable numPayees;
+
+    constructor(address[] memory payees, uint256[] memory shares_)
+        payable
+        PaymentSplitter(payees, shares_)
+    {
+        numPayees = payees.length;
+    }
+
+    /// @notice Returns the amount of ETH due to the account but not released.
+    function releasable(address account) public view returns (uint256) {
+        return
+            _pendingPayment(
+                account,
+                address(this).balance + totalReleased(),
+                released(account)
+            );
+    }
+
+    /**
+    @notice Returns the amount of the ERC20 token due to the account but not
+    released.
+     */
+    function releasableERC20(IERC20 token, address account)
+        public
+        view
+        returns (uint256)
+    {
+        return
+            _pendingPayment(
+                account,
+                token.balanceOf(address(this)) + totalReleased(token),
+                released(token, account)
+            );
+    }
+
+    /// @dev Equivalent to PaymentSplitter._pendingPayment(), which is private.
+    function _pendingPayment(
+        address account,
+        uint256 totalReceived,
+        uint256 alreadyReleased
+    ) private view returns (uint256) {
+        return
+            (totalReceived * shares(account)) / totalShares() - alreadyReleased;
+    }
+
+    /// @notice Releases all ETH due to all payees.
+    function _releaseAll() internal {
+        for (uint256 i = 0; i < numPayees; i++) {
+            address payable account = payable(payee(i));
+            if (releasable(account) > 0) {
+                release(account);
+            }
+        }
+    }
+
+    /// @notice Releases all of the ERC20 token due to all payees.
+    function _releaseAll(IERC20 token) internal {
+        for (uint256 i = 0; i < numPayees; i++) {
+            address account = payee(i);
+            if (releasableERC20(token, account) > 0) {
+                release(token, account);
+            }
+        }
+    }
+
+    /**
+    @notice Releases all of each ERC20 token due to the account, for payees
+    receiving many tokens.
+     */
+    function releaseERC20s(IERC20[] calldata tokens, address account)
+        external
+    {
+        for (uint256 i = 0; i < tokens.length; i++) {
+            if (releasableERC20(tokens[i], account) > 0) {
+                release(tokens[i], account);
+            }
+        }
+    }
+}
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "./PaymentSplitterWithERC20.sol";
+import "@openzeppelin/contracts/access/Ownable.sol";
+
+/**
+@notice A PaymentSplitterWithERC20 with which the owner can push funds to all
+payees, as against each payee having to pull their own share. Payees can still
+pull funds themselves via release().
+@dev The owner should only push to payees known to be able to receive ETH, as a
+single reverting payee will cause the push to revert.
+ */
+contract PushPaymentSplitter is PaymentSplitterWithERC20, Ownable {
+    constructor(address[] memory payees, uint256[] memory shares_)
+        payable
+        PaymentSplitterWithERC20(payees, shares_)
+    {} // solhint-disable-line no-empty-blocks
+
+    /// @notice Releases all ETH due to all payees.
+    function releaseAll() external onlyOwner {
+        _releaseAll();
+    }
+
+    /// @notice Releases all of the ERC20 tokens due to all payees.
+    function releaseAllERC20s(IERC20[] calldata tokens) external onlyOwner {
+        for (uint256 i = 0; i < tokens.length; i++) {
+            _releaseAll(tokens[i]);
+        }
+    }
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/token/ERC20/IERC20.sol";
+import "@openzeppelin/contracts/token/ERC20/utils/SafeERC20.sol";
+
+/**
+@notice Vests any ERC20 tokens held by the contract to a single beneficiary,
+linearly from `start` until `start + duration`, with nothing vesting before the
+cliff.
+@dev The linear schedule is calculated from `start`, not the end of the cliff,
+so a portion of the tokens vests immediately at the cliff. Tokens received at
+any time are vested as if they had been held since `start`.
+ */
+contract TokenVesting {
+    using SafeERC20 for IERC20;
+
+    /// @notice Recipient of all vested tokens.
+    address public immutable beneficiary;
+
+    /// @notice Timestamp from which tokens vest.
+    uint64 public immutable start;
+
+    /// @notice Seconds after `start` before which no tokens are vested.
+    uint64 public immutable cliff;
+
+    /// @notice Seconds after `start` at which all tokens are vested.
+    uint64 public immutable duration;
+
+    /// @notice Amount of each token already released to the beneficiary.
+    mapping(IERC20 => uint256) public released;
+
+    /// @notice Emitted when tokens are released to the beneficiary.
+    event Released(IERC20 indexed token, uint256 amount);
+
+    constructor(
+        address _beneficiary,
+        uint64 _start,
+        uint64 _cliff,
+        uint64 _duration
+    ) {
+        require(
+            _beneficiary != address(0),
+            "TokenVesting: zero beneficiary"
+        );
+        require(_duration > 0, "TokenVesting: zero duration");
+        require(_cliff <= _duration, "TokenVesting: cliff > duration");
+
+        beneficiary = _beneficiary;
+        start = _start;
+        cliff = _cliff;
+        duration = _duration;
+    }
+
+    /**
+    @notice Returns the amount of the token vested by the timestamp, including
+    amounts already released.
+     */
+    function vestedAmount(IERC20 token, uint64 timestamp)
+        public
+        view
+        returns (uint256)
+    {
+        uint256 total = token.balanceOf(address(this)) + released[token];
+
+        if (timestamp < start + cliff) {
+            return 0;
+        }
+        if (timestamp >= start + duration) {
+            return total;
+        }
+        return (total * (timestamp - start)) / duration;
+    }
+
+    /// @notice Returns the amount of the token vested but not yet released.
+    function releasable(IERC20 token) public view returns (uint256) {
+        return
+            // solhint-disable-next-line not-rely-on-time
+            vestedAmount(token, uint64(block.timestamp)) - released[token];
+    }
+
+    /**
+    @notice Releases all vested tokens to the beneficiary. Anyone can call this
+    function as tokens can only be sent to the beneficiary.
+     */
+    function release(IERC20 token) external {
+        uint256 amount = releasable(token);
+        released[token] += amount;
+        emit Released(token, amount);
+        token.safeTransfer(beneficiary, amount);
+    }
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+/**
+@notice The subset of the delegate.cash registry's interface required for
+delegating from a vault to a hot wallet, and for honouring such delegation.
+@dev Checks at broader levels are inherited by narrower ones; e.g. delegation
+for all of a vault's assets also passes checkDelegateForToken().
+ */
+interface IDelegationRegistry {
+    event DelegateForAll(address vault, address delegate, bool value);
+    event DelegateForContract(
+        address vault,
+        address delegate,
+        address contract_,
+        bool value
+    );
+    event DelegateForToken(
+        address vault,
+        address delegate,
+        address contract_,
+        uint256 tokenId,
+        bool value
+    );
+
+    function delegateForAll(address delegate, bool value) external;
+
+    function delegateForContract(
+        address delegate,
+        address contract_,
+        bool value
+    ) external;
+
+    function delegateForToken(
+        address delegate,
+        address contract_,
+        uint256 tokenId,
+        bool value
+    ) external;
+
+    function checkDelegateForAll(address delegate, address vault)
+        external
+        view
+        returns (bool);
+
+    function checkDelegateForContract(
+        address delegate,
+        address vault,
+        address contract_
+    ) external view returns (bool);
+
+    function checkDelegateForToken(
+        address delegate,
+        address vault,
+        address contract_,
+        uint256 tokenId
+    ) external view returns (bool);
+}
@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "./OpenSeaGasFreeListing.sol";
+import "../../utils/OwnerPausable.sol";
+import "@openzeppelin/contracts/utils/Strings.sol";
+
+/**
+@notice An ERC1155 extension that allows for minting directly from OpenSea
+using "option IDs", the semi-fungible counterpart of OpenSeaERC721Mintable.
+@dev As with OpenSeaERC721Mintable, all factory logic is abstracted away such
+that users of this contract can generally ignore the existence of the extra
+contract, apart from requiring its address for OpenSea listings.
+ */
+abstract contract OpenSeaERC1155Mintable {
+    /// @notice Factory contract deployed by this one's constructor.
+    OpenSeaERC1155Factory public factory;
+
+    constructor(
+        string memory factoryName,
+        string memory factorySymbol,
+        uint256 numFactoryOptions,
+        string memory baseOptionURI
+    ) {
+        factory = new OpenSeaERC1155Factory(
+            factoryName,
+            factorySymbol,
+            baseOptionURI,
+            msg.sender,
+            numFactoryOptions
+        );
+    }
+
+    /**
+    @notice Returns whether the factory can currently mint the specified amount
+    of the option.
+     */
+    function factoryCanMint(uint256 optionId, uint256 amount)
+        public
+        view
+        virtual
+        returns (bool);
+
+    /**
+    @notice Mints the specified amount of the option for the recipient.
+    @dev Note that this has internal visibility and its access is subject to
+    caller requirements so no further checks are necessary.
+     */
+    function _factoryMint(
+        uint256 optionId,
+        address to,
+        uint256 amount
+    ) internal virtual;
+
+    /**
+    @notice Mints the specified amount of the option for the recipient.
+    @dev Only callable by the factory; instead use factory.mint().
+     */
+    function factoryMint(
+        uint256 optionId,
+        address to,
+        uint256 amount
+    ) external {
+        require(
+            msg.sender == address(factory),
+            "OpenSeaERC1155Mintable: only factory"
+        );
+
+        _factoryMint(optionId, to, amount);
+    }
+}
+
+/**
+@notice Factory contract to mint OpenSeaERC1155Mintable tokens.
+@dev There is likely no need to use this contract directly; intead, inherit from
+OpenSeaERC1155Mintable and implement the necessary virtual functions.
+ */
+contract OpenSeaERC1155Factory is OwnerPausable {
+    using Strings for uint256;
+
+    /// @notice Contract that deployed this factory.
+    OpenSeaERC1155Mintable public token;
+
+    /// @notice Factory name and symbol.
+    string private name_;
+    string private symbol_;
+
+    /// @notice Base URI for constructing uri values for options.
+    string private baseOptionURI;
+
+    /**
+    @notice Standard ERC1155 TransferSingle event, used to trigger OpenSea into
+    recognising the existence of the factory.
+     */
+    event TransferSingle(
+        address indexed operator,
+        address indexed from,
+        address indexed to,
+        uint256 id,
+        uint256 value
+    );
+
+    uint256 public immutable numOptions;
+
+    /**
+    @param owner Initial contract owner as it will be deployed by another
+    contract but ownership should be transferred to an EOA.
+     */
+    constructor(
+        string memory _name,
+        string memory _symbol,
+        string memory _baseOptionURI,
+        address owner,
+        uint256 _numOptions
+    ) {
+        name_ = _name;
+        symbol_ = _symbol;
+        token = OpenSeaERC1155Mintable(msg.sender);
+        setBaseOptionURI(_baseOptionURI);
+
+        numOptions = _numOptions;
+
+        super.transferOwnership(owner);
+        emitTransfers(address(0), owner);
+    }
+
+    /// @notice Sets the base URI for constructing uri values for options.
+    function setBaseOptionURI(string memory _baseOptionURI) public onlyOwner {
+        baseOptionURI = _baseOptionURI;
+    }
+
+    /// @notice Returns the factory name.
+    function name() external view returns (string memory) {
+        return name_;
+    }
+
+    /// @notice Returns the factory symbol.
+    function symbol() external view returns (string memory) {
+        return symbol_;
+    }
+
+    /**
+    @notice Emits standard ERC1155.TransferSingle events for all of the option
+    "tokens" to induce correct OpenSea behaviour. These are first emitted upon
+    contract deployment to signal "creation" of the option tokens, and on any
+    ownership transfer of the contract.
+     */
+    function emitTransfers(address from, address to) internal {
+        for (uint256 i = 0; i < numOptions; i++) {
+            emit TransferSingle(msg.sender, from, to, i, type(uint256).max);
+        }
+    }
+
+    /**
+    @notice Transfers contract ownership just as with OpenZeppelin's Ownable,
+    but also triggers TransferSingle events as OpenSea expects the option
+    "tokens" to be owned by the contract owner.
+     */
+    function transferOwnership(address to) public override onlyOwner {
+        emitTransfers(super.owner(), to);
+        super.transferOwnership(to);
+    }
+
+    /**
+    @notice Returns whether the amount of the option can be minted, deferring
+    the logic to the factoryCanMint() method of the contract that deployed this
+    factory.
+     */
+    function canMint(uint256 optionId, uint256 amount)
+        public
+        view
+        returns (bool)
+    {
+        return
+            !paused() &&
+            optionId < numOptions &&
+            token.factoryCanMint(optionId, amount);
+    }
+
+    /**
+    @notice Returns a URL specifying option metadata, conforming to standard
+    ERC1155 metadata format.
+     */
+    function uri(uint256 optionId) external view returns (string memory) {
+        return string(abi.encodePacked(baseOptionURI, optionId.toString()));
+    }
+
+    /**
+    @dev The OpenSea factory interface requires this instead of using EIP165
+    supportsInterface().
+    @return true.
+     */
+    function supportsFactoryInterface() external pure returns (bool) {
+        return true;
+    }
+
+    /// @notice Returns the token standard of the factory's options.
+    function factorySchemaName() external pure returns (string memory) {
+        return "ERC1155";
+    }
+
+    /**
+    @notice Requires that the caller is either the owner or the owner's OpenSea
+    Wyvern proxy, then proxies the call to the factoryMint() method of the
+    contract that deployed this factory.
+     */
+    function mint(
+        uint256 optionId,
+        address to,
+        uint256 amount
+    ) public whenNotPaused {
+        require(
+            msg.sender == owner() ||
+                msg.sender == OpenSeaGasFreeListing.proxyFor(owner()),
+            "OpenSeaERC1155Factory: only owner or proxy"
+        );
+        token.factoryMint(optionId, to, amount);
+    }
+
+    /**
+    @dev Calls mint(id, to, amount) to comply with OpenSea's overriding of the
+    use of the ERC1155 interface, equivalent to OpenSeaERC721Factory's
+    transferFrom().
+     */
+    function safeTransferFrom(
+        address,
+        address to,
+        uint256 id,
+        uint256 amount,
+        bytes calldata
+    ) external {
+        mint(id, to, amount);
+    }
+
+    /// @dev Calls mint() for each of the ids and respective amounts.
+    function safeBatchTransferFrom(
+        address,
+        address to,
+        uint256[] calldata ids,
+        uint256[] calldata amounts,
+        bytes calldata
+    ) external {
+        require(
+            ids.length == amounts.length,
+            "OpenSeaERC1155Factory: length mismatch"
+        );
+        for (uint256 i = 0; i < ids.length; i++) {
+            mint(ids[i], to, amounts[i]);
+        }
+    }
+
+    /**
+    @dev Returns true if owner is the contract owner, and either (a) operator is
+    the OpenSea Wyvern proxy for the owner; or (b) operator == owner. This is
+    required to comply with OpenSea's overriding of the use of the ERC1155
+    interface. See comment on safeTransferFrom().
+     */
+    function isApprovedForAll(address owner, address operator)
+        public
+        view
+        returns (bool)
+    {
+        return
+            owner == super.owner() &&
+            (owner == operator ||
+                OpenSeaGasFreeListing.isApprovedForAll(owner, operator));
+    }
+
+    /**
+    @dev Returns the maximum uint256 for the contract owner if at least one of
+    the option can be minted, otherwise zero. This is required to comply with
+    OpenSea's overriding of the use of the ERC1155 interface; actual limits are
+    enforced by the primary contract.
+     */
+    function balanceOf(address owner, uint256 optionId)
+        external
+        view
+        returns (uint256)
+    {
+        return
+            owner == super.owner() && canMint(optionId, 1)
+                ? type(uint256).max
+                : 0;
+    }
+}
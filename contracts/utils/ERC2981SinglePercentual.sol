@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/interfaces/IERC2981.sol";
+import "@openzeppelin/contracts/utils/introspection/ERC165.sol";
+
+/**
+@notice An ERC2981 implementation in which royalties are a single percentage,
+in basis points, of the sale price, paid to a single receiver. A default
+royalty applies to all tokens unless overridden on a per-token basis.
+@dev Unlike OpenZeppelin's ERC2981, royaltyInfo() never overflows, regardless
+of the sale price.
+ */
+abstract contract ERC2981SinglePercentual is IERC2981, ERC165 {
+    /// @notice The denominator of all basis-point fractions.
+    uint256 public constant BASIS_POINTS = 10_000;
+
+    struct Royalty {
+        address receiver;
+        uint96 basisPoints;
+    }
+
+    /// @notice Royalty applied to all tokens without a per-token override.
+    Royalty private _defaultRoyalty;
+
+    /// @notice Per-token overrides of the default royalty.
+    mapping(uint256 => Royalty) private _tokenRoyalty;
+
+    /// @notice Requires a valid royalty configuration.
+    modifier validRoyalty(address receiver, uint96 basisPoints) {
+        require(
+            basisPoints <= BASIS_POINTS,
+            "ERC2981SinglePercentual: Excessive basis points"
+        );
+        require(
+            receiver != address(0),
+            "ERC2981SinglePercentual: Zero receiver"
+        );
+        _;
+    }
+
+    /// @notice Sets the default royalty.
+    function _setDefaultRoyalty(address receiver, uint96 basisPoints)
+        internal
+        virtual
+        validRoyalty(receiver, basisPoints)
+    {
+        _defaultRoyalty = Royalty(receiver, basisPoints);
+    }
+
+    /// @notice Removes the default royalty, equivalent to zero royalties.
+    function _deleteDefaultRoyalty() internal virtual {
+        delete _defaultRoyalty;
+    }
+
+    /// @notice Sets the royalty for a specific token, overriding the default.
+    function _setTokenRoyalty(
+        uint256 tokenId,
+        address receiver,
+        uint96 basisPoints
+    ) internal virtual validRoyalty(receiver, basisPoints) {
+        _tokenRoyalty[tokenId] = Royalty(receiver, basisPoints);
+    }
+
+    /// @notice Reverts the token's royalty to the default.
+    function _resetTokenRoyalty(uint256 tokenId) internal virtual {
+        delete _tokenRoyalty[tokenId];
+    }
+
+    /**
+    @notice Returns the royalty receiver and amount for the token, floor(
+    salePrice * basisPoints / BASIS_POINTS).
+    @dev The amount is computed in two parts to avoid overflow of salePrice *
+    basisPoints while still being exact.
+     */
+    function royaltyInfo(uint256 tokenId, uint256 salePrice)
+        public
+        view
+        virtual
+        override
+        returns (address receiver, uint256 royaltyAmount)
+    {
+        Royalty memory r = _tokenRoyalty[tokenId];
+        if (r.receiver == address(0)) {
+            r = _defaultRoyalty;
+        }
+
+        receiver = r.receiver;
+        royaltyAmount =
+            (salePrice / BASIS_POINTS) *
+            r.basisPoints +
+            ((salePrice % BASIS_POINTS) * r.basisPoints) /
+            BASIS_POINTS;
+    }
+
+    /// @notice Overrides supportsInterface to include IERC2981.
+    function supportsInterface(bytes4 interfaceId)
+        public
+        view
+        virtual
+        override(IERC165, ERC165)
+        returns (bool)
+    {
+        return
+            interfaceId == type(IERC2981).interfaceId ||
+            super.supportsInterface(interfaceId);
+    }
+}
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/utils/Base64.sol";
+import "@openzeppelin/contracts/utils/Strings.sol";
+
+/**
+@notice Utilities for building SVG images and token metadata on-chain.
+@dev None of the functions escape their inputs, which MUST therefore be valid
+SVG / JSON fragments, as appropriate.
+ */
+library SVG {
+    using Strings for uint256;
+
+    /// @notice Returns `<tag attributes>children</tag>`.
+    function element(
+        string memory tag,
+        string memory attributes,
+        string memory children
+    ) internal pure returns (string memory) {
+        return
+            string(
+                abi.encodePacked(
+                    "<",
+                    tag,
+                    attributes,
+                    ">",
+                    children,
+                    "</",
+                    tag,
+                    ">"
+                )
+            );
+    }
+
+    /// @notice Returns the self-closing `<tag attributes/>`.
+    function element(string memory tag, string memory attributes)
+        internal
+        pure
+        returns (string memory)
+    {
+        return string(abi.encodePacked("<", tag, attributes, "/>"));
+    }
+
+    /**
+    @notice Returns ` name="value"`, including the leading space, so attributes
+    can be concatenated directly.
+     */
+    function attr(string memory name, string memory value)
+        internal
+        pure
+        returns (string memory)
+    {
+        return string(abi.encodePacked(" ", name, '="', value, '"'));
+    }
+
+    /// @notice Equivalent to attr(name, value.toString()).
+    function attr(string memory name, uint256 value)
+        internal
+        pure
+        returns (string memory)
+    {
+        return attr(name, value.toString());
+    }
+
+    /// @notice Returns `rgb(r,g,b)` for use as a colour attribute value.
+    function rgb(
+        uint8 r,
+        uint8 g,
+        uint8 b
+    ) internal pure returns (string memory) {
+        return
+            string(
+                abi.encodePacked(
+                    "rgb(",
+                    uint256(r).toString(),
+                    ",",
+                    uint256(g).toString(),
+                    ",",
+                    uint256(b).toString(),
+                    ")"
+                )
+            );
+    }
+
+    /// @notice Returns a root <svg> element with a viewBox of the dimensions.
+    function svg(
+        uint256 width,
+        uint256 height,
+        string memory children
+    ) internal pure returns (string memory) {
+        return
+            element(
+                "svg",
+                string(
+                    abi.encodePacked(
+                        attr("xmlns", "http://www.w3.org/2000/svg"),
+                        attr(
+                            "viewBox",
+                            string(
+                                abi.encodePacked(
+                                    "0 0 ",
+                                    width.toString(),
+                                    " ",
+                                    height.toString()
+                                )
+                            )
+                        )
+                    )
+                ),
+                children
+            );
+    }
+
+    /// @notice Returns a base64-encoded data URI of the SVG image.
+    function dataURI(string memory image)
+        internal
+        pure
+        returns (string memory)
+    {
+        return
+            string(
+                abi.encodePacked(
+                    "data:image/svg+xml;base64,",
+                    Base64.encode(bytes(image))
+                )
+            );
+    }
+
+    /**
+    @notice Returns a base64-encoded data URI of the JSON metadata, typically
+    for returning from tokenURI().
+     */
+    function jsonDataURI(string memory json)
+        internal
+        pure
+        returns (string memory)
+    {
+        return
+            string(
+                abi.encodePacked(
+                    "data:application/json;base64,",
+                    Base64.encode(bytes(json))
+                )
+            );
+    }
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/access/Ownable.sol";
+
+/**
+@notice A mixin for restricting transfers of tokens, either temporarily, with
+lock windows opened and closed by the owner, or permanently, making tokens
+soulbound. Minting and burning are never restricted.
+@dev Inheriting contracts MUST call _requireTransferAllowed() from their token
+transfer hook; see ERC721ATransferRestricted.
+ */
+abstract contract TransferRestricted is Ownable {
+    enum TransferRestriction {
+        None,
+        Locked,
+        Soulbound
+    }
+
+    /// @notice The current restriction on transfers.
+    TransferRestriction public transferRestriction;
+
+    /// @notice Emitted when the restriction on transfers is changed.
+    event TransferRestrictionChanged(TransferRestriction restriction);
+
+    /**
+    @notice Sets the restriction on transfers. Locked can be set and removed at
+    any time, but Soulbound is permanent.
+     */
+    function setTransferRestriction(TransferRestriction restriction)
+        public
+        onlyOwner
+    {
+        require(
+            transferRestriction != TransferRestriction.Soulbound,
+            "TransferRestricted: Soulbound is permanent"
+        );
+        transferRestriction = restriction;
+        emit TransferRestrictionChanged(restriction);
+    }
+
+    /**
+    @notice Reverts if the current restriction prohibits a transfer between the
+    addresses. Mints, from the zero address, and burns, to the zero address,
+    are always allowed.
+     */
+    function _requireTransferAllowed(address from, address to)
+        internal
+        view
+        virtual
+    {
+        if (from == address(0) || to == address(0)) {
+            return;
+        }
+
+        TransferRestriction r = transferRestriction;
+        require(
+            r != TransferRestriction.Soulbound,
+            "TransferRestricted: Soulbound"
+        );
+        require(r != TransferRestriction.Locked, "TransferRestricted: Locked");
+    }
+}
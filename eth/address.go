@@ -0,0 +1,37 @@
+package eth
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ParseAddress parses a hex address, with or without a 0x prefix, returning
+// an error if it isn't exactly 20 bytes or if it contains invalid characters.
+// Addresses in mixed case MUST carry a valid EIP-55 checksum; all-lowercase and
+// all-uppercase addresses are accepted as they don't encode a checksum.
+//
+// Unlike common.HexToAddress(), which silently truncates or pads its input,
+// ParseAddress() is suitable for user-provided values such as allowlists.
+func ParseAddress(s string) (common.Address, error) {
+	h := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if n := len(h); n != 2*common.AddressLength {
+		return common.Address{}, fmt.Errorf("address %q has %d hex characters; want %d", s, n, 2*common.AddressLength)
+	}
+
+	buf, err := hex.DecodeString(h)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("address %q: %v", s, err)
+	}
+	addr := common.BytesToAddress(buf)
+
+	if h == strings.ToLower(h) || h == strings.ToUpper(h) {
+		return addr, nil
+	}
+	if want := addr.Hex()[2:]; h != want {
+		return common.Address{}, fmt.Errorf("address %q has invalid EIP-55 checksum; want 0x%s", s, want)
+	}
+	return addr, nil
+}
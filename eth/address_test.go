@@ -0,0 +1,46 @@
+package eth_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestParseAddress(t *testing.T) {
+	const checksummed = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	want := common.HexToAddress(checksummed)
+
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{in: checksummed},
+		{in: checksummed[2:]},
+		{in: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+		{in: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED"},
+		{in: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", wantErr: true}, // bad checksum
+		{in: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", wantErr: true},   // too short
+		{in: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed00", wantErr: true},
+		{in: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaeg", wantErr: true}, // non-hex
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAddress(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseAddress(%q) got nil error; want error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseAddress(%q) error %v", tt.in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseAddress(%q) got %v; want %v", tt.in, got, want)
+		}
+	}
+}
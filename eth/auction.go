@@ -0,0 +1,70 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// A LinearDutchAuction is a reference model of the pricing of the
+// LinearDutchAuction contract's DutchAuctionConfig. Points are block numbers
+// or timestamps, in accordance with the contract's AuctionIntervalUnit, which
+// the model needn't know.
+type LinearDutchAuction struct {
+	// StartPoint is the block or timestamp at which the auction opens; zero
+	// disables the auction.
+	StartPoint uint64
+	// StartPrice is the price at StartPoint, decreasing by DecreaseSize after
+	// every DecreaseInterval, at most NumDecreases times.
+	StartPrice       *big.Int
+	DecreaseInterval uint64
+	DecreaseSize     *big.Int
+	NumDecreases     uint64
+}
+
+// ErrAuctionNotStarted is returned by LinearDutchAuction.Price() and Cost()
+// when the contract would revert with "LinearDutchAuction: Not started".
+var ErrAuctionNotStarted = errors.New("auction not started")
+
+// Reserve returns the floor price, reached after NumDecreases decreases. An
+// error is returned if the decreases would take the price below zero, which
+// the contract rejects as an incorrect reserve.
+func (a LinearDutchAuction) Reserve() (*big.Int, error) {
+	r := new(big.Int).Mul(a.DecreaseSize, new(big.Int).SetUint64(a.NumDecreases))
+	r.Sub(a.StartPrice, r)
+	if r.Sign() < 0 {
+		return nil, fmt.Errorf("%d decreases of %d below start price %d", a.NumDecreases, a.DecreaseSize, a.StartPrice)
+	}
+	return r, nil
+}
+
+// Price returns the price of a single item at the specified point, equivalent
+// to the contract's cost(1, ·) when called at the point.
+func (a LinearDutchAuction) Price(point uint64) (*big.Int, error) {
+	if a.DecreaseInterval == 0 {
+		return nil, errors.New("zero decrease interval")
+	}
+	if a.StartPoint == 0 || point < a.StartPoint {
+		return nil, ErrAuctionNotStarted
+	}
+	if _, err := a.Reserve(); err != nil {
+		return nil, err
+	}
+
+	decreases := (point - a.StartPoint) / a.DecreaseInterval
+	if decreases > a.NumDecreases {
+		decreases = a.NumDecreases
+	}
+	p := new(big.Int).Mul(a.DecreaseSize, new(big.Int).SetUint64(decreases))
+	return p.Sub(a.StartPrice, p), nil
+}
+
+// Cost returns the total cost of n items at the specified point, equivalent to
+// the contract's cost(n, ·) when called at the point.
+func (a LinearDutchAuction) Cost(n, point uint64) (*big.Int, error) {
+	p, err := a.Price(point)
+	if err != nil {
+		return nil, err
+	}
+	return p.Mul(p, new(big.Int).SetUint64(n)), nil
+}
@@ -0,0 +1,95 @@
+package eth_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestLinearDutchAuction(t *testing.T) {
+	a := LinearDutchAuction{
+		StartPoint:       100,
+		StartPrice:       Ether(10),
+		DecreaseInterval: 7,
+		DecreaseSize:     Ether(1),
+		NumDecreases:     5,
+	}
+
+	if got, err := a.Reserve(); err != nil || got.Cmp(Ether(5)) != 0 {
+		t.Errorf("%+v.Reserve() got %d, err %v; want %d, nil err", a, got, err, Ether(5))
+	}
+
+	tests := []struct {
+		point, n uint64
+		want     *big.Int
+		wantErr  error
+	}{
+		{point: 0, n: 1, wantErr: ErrAuctionNotStarted},
+		{point: 99, n: 1, wantErr: ErrAuctionNotStarted},
+		{point: 100, n: 1, want: Ether(10)},
+		{point: 106, n: 1, want: Ether(10)},
+		{point: 107, n: 1, want: Ether(9)},
+		{point: 107, n: 3, want: Ether(27)},
+		{point: 134, n: 1, want: Ether(6)},
+		{point: 135, n: 1, want: Ether(5)},
+		{point: 1000, n: 2, want: Ether(10)},
+	}
+
+	for _, tt := range tests {
+		got, err := a.Cost(tt.n, tt.point)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Cost(%d, %d) error %v; want %v", tt.n, tt.point, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr != nil {
+			continue
+		}
+		if got.Cmp(tt.want) != 0 {
+			t.Errorf("Cost(%d, %d) got %d; want %d", tt.n, tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestLinearDutchAuctionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		a    LinearDutchAuction
+	}{
+		{
+			name: "disabled",
+			a: LinearDutchAuction{
+				StartPrice:       Ether(1),
+				DecreaseInterval: 1,
+				DecreaseSize:     big.NewInt(0),
+			},
+		},
+		{
+			name: "zero interval",
+			a: LinearDutchAuction{
+				StartPoint:   1,
+				StartPrice:   Ether(1),
+				DecreaseSize: big.NewInt(0),
+			},
+		},
+		{
+			name: "negative reserve",
+			a: LinearDutchAuction{
+				StartPoint:       1,
+				StartPrice:       Ether(1),
+				DecreaseInterval: 1,
+				DecreaseSize:     Ether(1),
+				NumDecreases:     2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.a.Price(10); err == nil {
+				t.Errorf("%+v.Price(10) got %d, nil error; want error", tt.a, got)
+			}
+		})
+	}
+}
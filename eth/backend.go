@@ -0,0 +1,138 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// A SignerBackend signs on behalf of a single address. It is implemented by
+// Signer, for in-memory keys, and by kms.Signer, for keys held by cloud
+// key-management services, allowing them to be used interchangeably by helpers
+// that accept a SignerBackend.
+type SignerBackend interface {
+	// Address returns the address of the signing key.
+	Address() common.Address
+	// SignRawDigest returns a 65-byte ECDSA signature of the 32-byte digest,
+	// with V in {27,28}, or in 64-byte compact form as per EIP-2098.
+	// Signatures MUST have a low s value; see NormalizeSignature().
+	//
+	// The digest is signed as-is so callers MUST have constructed it
+	// themselves, as PersonalSign() and SignTx() do; digests of any other
+	// origin SHOULD be signed with SignDigest(), which binds them to a domain.
+	// The method is deliberately not named SignDigest, to avoid confusion
+	// with that domain-tagged helper, which backends also commonly expose as
+	// a method of the same name.
+	SignRawDigest(digest []byte) ([]byte, error)
+	// SignTypedData returns a signature, in the same form as SignRawDigest(),
+	// of TypedDataDigest(td).
+	SignTypedData(td apitypes.TypedData) ([]byte, error)
+}
+
+var _ SignerBackend = (*Signer)(nil)
+
+// A PersonalMessageSigner is a SignerBackend that signs EIP-191 personal
+// messages itself. It is typically implemented by backends, such as remote
+// signing services, that refuse to sign arbitrary digests; PersonalSign() uses
+// it in preference to SignRawDigest().
+type PersonalMessageSigner interface {
+	SignerBackend
+	// SignPersonalMessage returns an EIP-191 personal signature of buf, in any
+	// form accepted by RecoverAddress().
+	SignPersonalMessage(buf []byte) ([]byte, error)
+}
+
+// A TransactionSigner is a SignerBackend that signs transactions itself. As
+// with PersonalMessageSigner, SignTx() uses it in preference to
+// SignRawDigest().
+type TransactionSigner interface {
+	SignerBackend
+	// SignTransaction returns a signed copy of the transaction.
+	SignTransaction(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// SignRawDigest returns an ECDSA signature of the 32-byte digest. Unlike
+// RawSign(), it requires that buf is exactly 32 bytes, as is the case for
+// hashes. See SignerBackend re the origin of digests; prefer SignDigest().
+func (s *Signer) SignRawDigest(digest []byte) ([]byte, error) {
+	if n := len(digest); n != 32 {
+		return nil, fmt.Errorf("digest length %d; expecting 32", n)
+	}
+	return s.RawSign(digest)
+}
+
+// PersonalSign returns an EIP-191 personal signature of buf, in compact form,
+// by the backend. It is equivalent to Signer.PersonalSign().
+func PersonalSign(b SignerBackend, buf []byte) ([]byte, error) {
+	var (
+		sig []byte
+		err error
+	)
+	if p, ok := b.(PersonalMessageSigner); ok {
+		sig, err = p.SignPersonalMessage(buf)
+	} else {
+		sig, err = b.SignRawDigest(crypto.Keccak256(WithPersonalMessagePrefix(buf)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) == 64 {
+		return sig, nil
+	}
+	return CompactSignature(sig)
+}
+
+// SignVoucher returns PersonalSign(b, v.Encode()). It is equivalent to
+// Signer.SignVoucher().
+func SignVoucher(b SignerBackend, v Voucher) ([]byte, error) {
+	buf, err := v.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return PersonalSign(b, buf)
+}
+
+// SignTx signs the transaction for the specified chain with the backend,
+// returning a signed copy. See Signer.SignTx() re supported transaction types.
+func SignTx(b SignerBackend, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if chainID == nil {
+		return nil, bind.ErrNoChainID
+	}
+	if t, ok := b.(TransactionSigner); ok {
+		return t.SignTransaction(tx, chainID)
+	}
+	signer := types.LatestSignerForChainID(chainID)
+	sig, err := b.SignRawDigest(signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if sig, err = canonicalSignature(sig); err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// TransactOpts returns a TransactOpts that sends from the backend's address
+// and signs with SignTx().
+func TransactOpts(ctx context.Context, b SignerBackend, chainID *big.Int) (*bind.TransactOpts, error) {
+	if chainID == nil {
+		return nil, bind.ErrNoChainID
+	}
+	from := b.Address()
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if addr != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return SignTx(b, tx, chainID)
+		},
+		Context: ctx,
+	}, nil
+}
@@ -0,0 +1,182 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SignAddressesBatch returns s.PersonalSignAddress() for every address, in the
+// same order as addrs, signing concurrently across the specified number of
+// workers. If workers <= 0, runtime.NumCPU() workers are used.
+//
+// Signing stops at the first error, which is returned, or when ctx is
+// cancelled.
+func (s *Signer) SignAddressesBatch(ctx context.Context, addrs []common.Address, workers int) ([][]byte, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if n := len(addrs); workers > n {
+		workers = n
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sigs := make([][]byte, len(addrs))
+	idx := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				sig, err := s.PersonalSignAddress(addrs[i])
+				if err != nil {
+					fail(fmt.Errorf("sign address %d (%v): %v", i, addrs[i], err))
+					return
+				}
+				sigs[i] = sig
+			}
+		}()
+	}
+
+Feed:
+	for i := range addrs {
+		select {
+		case idx <- i:
+		case <-ctx.Done():
+			break Feed
+		}
+	}
+	close(idx)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return sigs, nil
+}
+
+// A SignatureFailure describes a single invalid signature in a batch.
+type SignatureFailure struct {
+	// Index is the position of the signature, and its digest, in the batch.
+	Index int
+	Err   error
+}
+
+// A BatchVerificationError is returned by VerifyBatch() if any of the
+// signatures are invalid. Unlike a typical error, it reports every failure
+// rather than only the first.
+type BatchVerificationError struct {
+	// Total is the number of signatures in the batch.
+	Total int
+	// Failures are sorted by Index.
+	Failures []SignatureFailure
+}
+
+// Error returns a summary of the failures, including up to the first 10 in
+// detail.
+func (e *BatchVerificationError) Error() string {
+	const maxDetail = 10
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d signatures invalid", len(e.Failures), e.Total)
+	for i, f := range e.Failures {
+		if i == maxDetail {
+			fmt.Fprintf(&b, "; and %d more", len(e.Failures)-maxDetail)
+			break
+		}
+		fmt.Fprintf(&b, "; [%d]: %v", f.Index, f.Err)
+	}
+	return b.String()
+}
+
+// VerifyBatch checks that every signature is of its respective 32-byte digest,
+// i.e. sigs[i] of digests[i], and was produced by the expected signer.
+// Signatures MAY be in any form accepted by RecoverAddress(). Verification is
+// performed concurrently across the specified number of workers; if workers <=
+// 0, runtime.NumCPU() workers are used.
+//
+// If any signatures are invalid, the returned error is a
+// *BatchVerificationError describing all of them. The only other errors are
+// for mismatched input lengths or ctx being cancelled.
+func VerifyBatch(ctx context.Context, digests, sigs [][]byte, expected common.Address, workers int) error {
+	if nd, ns := len(digests), len(sigs); nd != ns {
+		return fmt.Errorf("%d digests and %d signatures", nd, ns)
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []SignatureFailure
+	)
+	idx := make(chan int)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				got, err := RecoverAddress(digests[i], sigs[i])
+				if err == nil && got != expected {
+					err = fmt.Errorf("signed by %v", got)
+				}
+				if err == nil {
+					continue
+				}
+				mu.Lock()
+				failures = append(failures, SignatureFailure{Index: i, Err: err})
+				mu.Unlock()
+			}
+		}()
+	}
+
+Feed:
+	for i := range digests {
+		select {
+		case idx <- i:
+		case <-ctx.Done():
+			break Feed
+		}
+	}
+	close(idx)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Index < failures[j].Index
+	})
+	return &BatchVerificationError{
+		Total:    len(digests),
+		Failures: failures,
+	}
+}
@@ -0,0 +1,117 @@
+package eth_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestSignAddressesBatch(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	addrs := make([]common.Address, 500)
+	for i := range addrs {
+		addrs[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+
+	ctx := context.Background()
+	for _, workers := range []int{0, 1, 7, 1000} {
+		sigs, err := signer.SignAddressesBatch(ctx, addrs, workers)
+		if err != nil {
+			t.Fatalf("%T.SignAddressesBatch(ctx, [%d addresses], %d) error %v", signer, len(addrs), workers, err)
+		}
+		if got, want := len(sigs), len(addrs); got != want {
+			t.Fatalf("%T.SignAddressesBatch(ctx, [%d addresses], %d) got %d signatures; want %d", signer, len(addrs), workers, got, want)
+		}
+
+		for i, a := range addrs {
+			want, err := signer.PersonalSignAddress(a)
+			if err != nil {
+				t.Fatalf("%T.PersonalSignAddress(%v) error %v", signer, a, err)
+			}
+			if !bytes.Equal(sigs[i], want) {
+				t.Errorf("%T.SignAddressesBatch(…, %d)[%d] got %#x; want %#x", signer, workers, i, sigs[i], want)
+			}
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := signer.SignAddressesBatch(cancelled, addrs, 4); err == nil {
+		t.Errorf("%T.SignAddressesBatch([cancelled context]) got nil error; want error", signer)
+	}
+}
+
+func TestVerifyBatch(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+	other, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	const n = 200
+	digests := make([][]byte, n)
+	sigs := make([][]byte, n)
+	for i := range digests {
+		digests[i] = crypto.Keccak256(big.NewInt(int64(i)).Bytes())
+		sigs[i], err = signer.SignRawDigest(digests[i])
+		if err != nil {
+			t.Fatalf("%T.SignRawDigest() error %v", signer, err)
+		}
+	}
+
+	ctx := context.Background()
+	for _, workers := range []int{0, 1, 7} {
+		if err := VerifyBatch(ctx, digests, sigs, signer.Address(), workers); err != nil {
+			t.Errorf("VerifyBatch([all valid], %d workers) error %v", workers, err)
+		}
+	}
+
+	// Introduce failures, deliberately out of order.
+	wantBad := []int{3, 42, 150}
+	sigs[150], err = other.SignRawDigest(digests[150])
+	if err != nil {
+		t.Fatalf("%T.SignRawDigest() error %v", other, err)
+	}
+	sigs[3] = sigs[4]
+	sigs[42] = sigs[42][:10]
+
+	err = VerifyBatch(ctx, digests, sigs, signer.Address(), 4)
+	var batchErr *BatchVerificationError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("VerifyBatch([with invalid signatures]) got err %v; want %T", err, batchErr)
+	}
+	if got, want := batchErr.Total, n; got != want {
+		t.Errorf("VerifyBatch() error Total = %d; want %d", got, want)
+	}
+	var gotBad []int
+	for _, f := range batchErr.Failures {
+		gotBad = append(gotBad, f.Index)
+	}
+	if len(gotBad) != len(wantBad) {
+		t.Fatalf("VerifyBatch() error got failures at %v; want %v", gotBad, wantBad)
+	}
+	for i := range gotBad {
+		if gotBad[i] != wantBad[i] {
+			t.Errorf("VerifyBatch() error got failures at %v; want %v", gotBad, wantBad)
+			break
+		}
+	}
+
+	if err := VerifyBatch(ctx, digests, sigs[1:], signer.Address(), 0); err == nil || errors.As(err, &batchErr) {
+		t.Errorf("VerifyBatch([mismatched lengths]) got err %v; want non-batch error", err)
+	}
+}
@@ -0,0 +1,137 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// A Delegation authorises a session key to sign on behalf of a primary signer,
+// limited to a scope and until an expiry. It is the Go counterpart of the
+// DelegationChecker.Delegation Solidity struct, and its encoding is identical
+// to DelegationChecker.encode().
+type Delegation struct {
+	Session common.Address
+	// Scope is defined by the verifying contract; see DelegationScope().
+	Scope [32]byte
+	// Expiry is the Unix timestamp, in seconds, after which the delegation is
+	// no longer valid.
+	Expiry uint64
+}
+
+// DelegationScope returns keccak256(name), the conventional Delegation scope;
+// e.g. DelegationScope("mint") is equivalent to Solidity's keccak256("mint").
+func DelegationScope(name string) [32]byte {
+	return crypto.Keccak256Hash([]byte(name))
+}
+
+// delegationArgs are the ABI arguments for canonical Delegation encoding.
+var delegationArgs = func() abi.Arguments {
+	var args abi.Arguments
+	for _, t := range []string{"address", "bytes32", "uint256"} {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(fmt.Sprintf("abi.NewType(%q): %v", t, err))
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return args
+}()
+
+// Encode returns the canonical encoding of the Delegation, equivalent to
+// Solidity's abi.encode(session, scope, expiry).
+func (d Delegation) Encode() ([]byte, error) {
+	return delegationArgs.Pack(d.Session, d.Scope, new(big.Int).SetUint64(d.Expiry))
+}
+
+// A DelegatedSigner signs with a session key on behalf of a primary signer,
+// carrying the primary's authorisation of the session key. Only the session
+// key is held in memory, so a DelegatedSigner can be handed to automated
+// processes with limited exposure should it be compromised.
+type DelegatedSigner struct {
+	// Session is the short-lived key that signs all messages.
+	Session *Signer
+	// Primary is the address of the signer that authorised Session.
+	Primary    common.Address
+	Delegation Delegation
+	// Authorisation is Primary's personal signature of Delegation.Encode().
+	Authorisation []byte
+}
+
+// NewDelegatedSigner generates a new session key and has the primary signer
+// authorise it, with PersonalSign(), for the scope until the expiry time. The
+// returned signatures are verified by the DelegationChecker Solidity library,
+// or in Go with VerifyDelegated().
+func NewDelegatedSigner(primary SignerBackend, scope [32]byte, expiry time.Time) (*DelegatedSigner, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate session key: %v", err)
+	}
+	session := &Signer{key: key}
+
+	d := Delegation{
+		Session: session.Address(),
+		Scope:   scope,
+		Expiry:  uint64(expiry.Unix()),
+	}
+	buf, err := d.Encode()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := PersonalSign(primary, buf)
+	if err != nil {
+		return nil, fmt.Errorf("sign delegation: %v", err)
+	}
+
+	return &DelegatedSigner{
+		Session:       session,
+		Primary:       primary.Address(),
+		Delegation:    d,
+		Authorisation: auth,
+	}, nil
+}
+
+// PersonalSign returns d.Session.PersonalSign(buf).
+func (d *DelegatedSigner) PersonalSign(buf []byte) ([]byte, error) {
+	return d.Session.PersonalSign(buf)
+}
+
+// PersonalSignAddress returns d.Session.PersonalSignAddress(addr).
+func (d *DelegatedSigner) PersonalSignAddress(addr common.Address) ([]byte, error) {
+	return d.Session.PersonalSignAddress(addr)
+}
+
+// VerifyDelegated mirrors DelegationChecker.requireValidDelegatedSignature(),
+// except for replay protection, returning an error unless: the Delegation is
+// for the scope and hasn't expired at time now; the authorisation is a
+// personal signature of the Delegation by primary; and sig is a personal
+// signature of data by the Delegation's session key.
+func VerifyDelegated(primary common.Address, d Delegation, authorisation []byte, scope [32]byte, now time.Time, data, sig []byte) error {
+	if d.Scope != scope {
+		return fmt.Errorf("delegation scope %#x; expecting %#x", d.Scope, scope)
+	}
+	if exp := time.Unix(int64(d.Expiry), 0); now.After(exp) {
+		return fmt.Errorf("delegation expired at %v", exp)
+	}
+
+	buf, err := d.Encode()
+	if err != nil {
+		return err
+	}
+	if got, err := RecoverPersonalAddress(buf, authorisation); err != nil {
+		return fmt.Errorf("recover delegation signer: %v", err)
+	} else if got != primary {
+		return fmt.Errorf("delegation signed by %v; expecting %v", got, primary)
+	}
+
+	if got, err := RecoverPersonalAddress(data, sig); err != nil {
+		return fmt.Errorf("recover session signer: %v", err)
+	} else if got != d.Session {
+		return fmt.Errorf("data signed by %v; expecting session key %v", got, d.Session)
+	}
+	return nil
+}
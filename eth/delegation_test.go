@@ -0,0 +1,118 @@
+package eth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestDelegatedSigner(t *testing.T) {
+	primary, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+	other, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	now := time.Now()
+	scope := DelegationScope("mint")
+	if got, want := scope, crypto.Keccak256Hash([]byte("mint")); got != want {
+		t.Errorf("DelegationScope(%q) got %#x; want %#x", "mint", got, want)
+	}
+
+	d, err := NewDelegatedSigner(primary, scope, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("NewDelegatedSigner() error %v", err)
+	}
+	if d.Delegation.Session != d.Session.Address() {
+		t.Errorf("NewDelegatedSigner().Delegation.Session = %v; want session address %v", d.Delegation.Session, d.Session.Address())
+	}
+
+	data := []byte("hello")
+	sig, err := d.PersonalSign(data)
+	if err != nil {
+		t.Fatalf("%T.PersonalSign() error %v", d, err)
+	}
+	otherSig, err := other.PersonalSign(data)
+	if err != nil {
+		t.Fatalf("%T.PersonalSign() error %v", other, err)
+	}
+
+	tests := []struct {
+		name    string
+		primary *Signer
+		scope   [32]byte
+		now     time.Time
+		data    []byte
+		sig     []byte
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			primary: primary,
+			scope:   scope,
+			now:     now,
+			data:    data,
+			sig:     sig,
+		},
+		{
+			name:    "wrong primary",
+			primary: other,
+			scope:   scope,
+			now:     now,
+			data:    data,
+			sig:     sig,
+			wantErr: true,
+		},
+		{
+			name:    "wrong scope",
+			primary: primary,
+			scope:   DelegationScope("burn"),
+			now:     now,
+			data:    data,
+			sig:     sig,
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			primary: primary,
+			scope:   scope,
+			now:     now.Add(2 * time.Hour),
+			data:    data,
+			sig:     sig,
+			wantErr: true,
+		},
+		{
+			name:    "different data",
+			primary: primary,
+			scope:   scope,
+			now:     now,
+			data:    []byte("goodbye"),
+			sig:     sig,
+			wantErr: true,
+		},
+		{
+			name:    "not signed by session key",
+			primary: primary,
+			scope:   scope,
+			now:     now,
+			data:    data,
+			sig:     otherSig,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyDelegated(tt.primary.Address(), d.Delegation, d.Authorisation, tt.scope, tt.now, tt.data, tt.sig)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("VerifyDelegated() got err %v; want error = %t", err, tt.wantErr)
+			}
+		})
+	}
+}
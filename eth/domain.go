@@ -0,0 +1,48 @@
+package eth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrNoDomainTag is returned when signing a digest without a domain tag.
+var ErrNoDomainTag = errors.New("empty domain tag; raw digests are only signed within an application-specific domain")
+
+// DomainDigest returns the digest that is signed by SignDigest(), i.e.
+// keccak256(0x19 ‖ 0x01 ‖ keccak256(domainTag) ‖ digest). This follows the
+// EIP-191 version 0x01 structure used by EIP-712, with the hash of the tag in
+// place of a full domain separator, and is equivalent to Solidity's
+// ECDSA.toTypedDataHash(keccak256(bytes(domainTag)), digest).
+//
+// The domainTag SHOULD uniquely identify the application and purpose of the
+// signature, e.g. "ethier.example.mint/v1", and MUST NOT be empty or only
+// whitespace.
+func DomainDigest(domainTag string, digest [32]byte) (common.Hash, error) {
+	if strings.TrimSpace(domainTag) == "" {
+		return common.Hash{}, ErrNoDomainTag
+	}
+	sep := crypto.Keccak256([]byte(domainTag))
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, sep, digest[:]), nil
+}
+
+// SignDigest signs DomainDigest(domainTag, digest) with the backend. As
+// the digest is always bound to the domain, a signature produced for one
+// application can't be replayed against another that uses a different tag,
+// nor as a transaction, personal message, or typed-data signature.
+func SignDigest(b SignerBackend, domainTag string, digest [32]byte) ([]byte, error) {
+	d, err := DomainDigest(domainTag, digest)
+	if err != nil {
+		return nil, err
+	}
+	return b.SignRawDigest(d.Bytes())
+}
+
+// SignDigest returns SignDigest(s, domainTag, digest). Prefer it to RawSign()
+// and SignRawDigest() whenever the digest originates outside of ethier, as it
+// refuses to sign without an application-specific domain.
+func (s *Signer) SignDigest(domainTag string, digest [32]byte) ([]byte, error) {
+	return SignDigest(s, domainTag, digest)
+}
@@ -0,0 +1,51 @@
+package eth_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestSignDigest(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+	digest := crypto.Keccak256Hash([]byte("hello"))
+
+	for _, tag := range []string{"", " ", "\t\n"} {
+		if _, err := signer.SignDigest(tag, digest); !errors.Is(err, ErrNoDomainTag) {
+			t.Errorf("%T.SignDigest(%q, …) got err %v; want %v", signer, tag, err, ErrNoDomainTag)
+		}
+	}
+
+	const tag = "ethier.test/v1"
+	sig, err := signer.SignDigest(tag, digest)
+	if err != nil {
+		t.Fatalf("%T.SignDigest(%q, …) error %v", signer, tag, err)
+	}
+
+	want := crypto.Keccak256Hash([]byte{0x19, 0x01}, crypto.Keccak256([]byte(tag)), digest[:])
+	got, err := DomainDigest(tag, digest)
+	if err != nil {
+		t.Fatalf("DomainDigest(%q, …) error %v", tag, err)
+	}
+	if got != want {
+		t.Errorf("DomainDigest(%q, …) got %v; want %v", tag, got, want)
+	}
+
+	if addr, err := RecoverAddress(want.Bytes(), sig); err != nil || addr != signer.Address() {
+		t.Errorf("RecoverAddress(DomainDigest(), %T.SignDigest()) got %v, err = %v; want %v, nil err", signer, addr, err, signer.Address())
+	}
+
+	otherDomain, err := DomainDigest("other/v1", digest)
+	if err != nil {
+		t.Fatalf("DomainDigest() error %v", err)
+	}
+	if addr, err := RecoverAddress(otherDomain.Bytes(), sig); err == nil && addr == signer.Address() {
+		t.Errorf("Signature from %T.SignDigest(%q) recovered to signer under different domain", signer, tag)
+	}
+}
@@ -0,0 +1,33 @@
+package eth
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENSRegistry is the address of the ENS registry, which is the same on mainnet
+// and all major testnets.
+var ENSRegistry = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// NameHash returns the EIP-137 namehash of the ENS name, e.g. "vitalik.eth".
+// The name is lowercased but otherwise not normalised, so it MUST already
+// conform to UTS-46 if it contains non-ASCII characters.
+func NameHash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node[:], crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// ReverseName returns the ENS name, under addr.reverse, used for reverse
+// resolution of the address.
+func ReverseName(addr common.Address) string {
+	return strings.ToLower(addr.Hex()[2:]) + ".addr.reverse"
+}
@@ -0,0 +1,34 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNameHash(t *testing.T) {
+	// Test vectors from EIP-137.
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "0x0000000000000000000000000000000000000000000000000000000000000000"},
+		{"eth", "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{"foo.eth", "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+		{"FOO.eth", "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	}
+
+	for _, tt := range tests {
+		if got := NameHash(tt.name); got != common.HexToHash(tt.want) {
+			t.Errorf("NameHash(%q) got %v; want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReverseName(t *testing.T) {
+	addr := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+	const want = "d8da6bf26964af9d7eed9e03e53415d37aa96045.addr.reverse"
+	if got := ReverseName(addr); got != want {
+		t.Errorf("ReverseName(%v) got %q; want %q", addr, got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC1271MagicValue is returned by ERC-1271 isValidSignature(bytes32,bytes)
+// for valid signatures; it is the function's selector.
+var ERC1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+// erc1271ABI is the ERC-1271 interface.
+var erc1271ABI = func() abi.ABI {
+	a, err := abi.JSON(strings.NewReader(`[{"type":"function","name":"isValidSignature","stateMutability":"view","inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],"outputs":[{"name":"magicValue","type":"bytes4"}]}]`))
+	if err != nil {
+		panic(fmt.Sprintf("parse ERC-1271 ABI: %v", err))
+	}
+	return a
+}()
+
+// ValidateSignature returns whether sig is a valid signature of the digest by
+// the signer address. If the signer is a contract (e.g. a Safe or Argent
+// wallet), validity is determined by its ERC-1271 isValidSignature() function,
+// as is done by marketplaces; otherwise the address recovered from the
+// signature is compared to the signer.
+//
+// A contract that reverts or doesn't implement ERC-1271 is treated as having
+// rejected the signature, but errors in communicating with the backend are
+// returned.
+func ValidateSignature(ctx context.Context, backend bind.ContractCaller, signer common.Address, digest, sig []byte) (bool, error) {
+	if n := len(digest); n != 32 {
+		return false, fmt.Errorf("digest length %d; expecting 32", n)
+	}
+
+	code, err := backend.CodeAt(ctx, signer, nil)
+	if err != nil {
+		return false, fmt.Errorf("CodeAt(%v): %v", signer, err)
+	}
+	if len(code) == 0 {
+		addr, err := RecoverAddress(digest, sig)
+		if err != nil {
+			return false, nil
+		}
+		return addr == signer, nil
+	}
+
+	var hash [32]byte
+	copy(hash[:], digest)
+	data, err := erc1271ABI.Pack("isValidSignature", hash, sig)
+	if err != nil {
+		return false, fmt.Errorf("pack isValidSignature() call: %v", err)
+	}
+	out, err := backend.CallContract(ctx, ethereum.CallMsg{To: &signer, Data: data}, nil)
+	if err != nil {
+		// Both the simulated backend and JSON-RPC nodes report reverts with
+		// this message, with or without a reason.
+		if strings.Contains(err.Error(), "execution reverted") {
+			return false, nil
+		}
+		return false, fmt.Errorf("%v.isValidSignature(): %v", signer, err)
+	}
+	// The return value is left-aligned in a 32-byte word; anything shorter is
+	// a non-compliant contract.
+	if len(out) < 32 {
+		return false, nil
+	}
+	return bytes.Equal(out[:4], ERC1271MagicValue[:]), nil
+}
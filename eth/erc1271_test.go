@@ -0,0 +1,103 @@
+package eth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/divergencetech/ethier/ethtest"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+// deployRuntime deploys a contract with the specified runtime bytecode, which
+// MUST be no more than 255 bytes, and returns its address.
+func deployRuntime(t *testing.T, sim *ethtest.SimulatedBackend, runtime []byte) common.Address {
+	t.Helper()
+	// PUSH1 len; DUP1; PUSH1 11; PUSH1 0; CODECOPY; PUSH1 0; RETURN
+	init := []byte{0x60, byte(len(runtime)), 0x80, 0x60, 0x0b, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}
+	addr, _, _, err := bind.DeployContract(sim.Acc(0), abi.ABI{}, append(init, runtime...), sim)
+	if err != nil {
+		t.Fatalf("bind.DeployContract() error %v", err)
+	}
+	return addr
+}
+
+func TestValidateSignature(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, 1)
+
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+	other, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	digest := crypto.Keccak256([]byte("hello"))
+	sig, err := signer.SignRawDigest(digest)
+	if err != nil {
+		t.Fatalf("%T.SignRawDigest() error %v", signer, err)
+	}
+
+	// A wallet that accepts every signature: PUSH4 magic; PUSH1 224; SHL;
+	// PUSH1 0; MSTORE; PUSH1 32; PUSH1 0; RETURN
+	acceptAll := deployRuntime(t, sim, []byte{
+		0x63, 0x16, 0x26, 0xba, 0x7e, 0x60, 0xe0, 0x1b,
+		0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3,
+	})
+	// A contract without ERC-1271 support: STOP
+	nonCompliant := deployRuntime(t, sim, []byte{0x00})
+	// A contract that always reverts: PUSH1 0; DUP1; REVERT
+	reverter := deployRuntime(t, sim, []byte{0x60, 0x00, 0x80, 0xfd})
+
+	tests := []struct {
+		name   string
+		signer common.Address
+		want   bool
+	}{
+		{
+			name:   "EOA signer",
+			signer: signer.Address(),
+			want:   true,
+		},
+		{
+			name:   "different EOA",
+			signer: other.Address(),
+			want:   false,
+		},
+		{
+			name:   "ERC-1271 wallet accepting signature",
+			signer: acceptAll,
+			want:   true,
+		},
+		{
+			name:   "contract without ERC-1271",
+			signer: nonCompliant,
+			want:   false,
+		},
+		{
+			name:   "reverting contract",
+			signer: reverter,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateSignature(ctx, sim, tt.signer, digest, sig)
+			if err != nil {
+				t.Fatalf("ValidateSignature(ctx, sim, %v, …) error %v", tt.signer, err)
+			}
+			if got != tt.want {
+				t.Errorf("ValidateSignature(ctx, sim, %v, …) got %t; want %t", tt.signer, got, tt.want)
+			}
+		})
+	}
+}
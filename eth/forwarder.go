@@ -0,0 +1,79 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// A TrustedForwarder describes the EIP-712 domain of an EIP-2771 trusted
+// forwarder. For OpenZeppelin's MinimalForwarder, Name and Version are
+// "MinimalForwarder" and "0.0.1" respectively.
+type TrustedForwarder struct {
+	Name, Version string
+	ChainID       *big.Int
+	Address       common.Address
+}
+
+// A ForwardRequest is a meta-transaction, signed by From, that an EIP-2771
+// trusted forwarder relays to To. Nonce MUST equal the forwarder's current
+// getNonce(From) value.
+type ForwardRequest struct {
+	From, To          common.Address
+	Value, Gas, Nonce *big.Int
+	Data              []byte
+}
+
+// ForwardRequestTypedData returns the EIP-712 typed data for a ForwardRequest
+// as defined by OpenZeppelin's MinimalForwarder.
+func ForwardRequestTypedData(fwd TrustedForwarder, req ForwardRequest) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": EIP712DomainType,
+			"ForwardRequest": {
+				{Name: "from", Type: "address"},
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "gas", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+			},
+		},
+		PrimaryType: "ForwardRequest",
+		Domain:      EIP712Domain(fwd.Name, fwd.Version, fwd.ChainID, fwd.Address),
+		Message: apitypes.TypedDataMessage{
+			"from":  req.From.Hex(),
+			"to":    req.To.Hex(),
+			"value": (*math.HexOrDecimal256)(req.Value),
+			"gas":   (*math.HexOrDecimal256)(req.Gas),
+			"nonce": (*math.HexOrDecimal256)(req.Nonce),
+			"data":  hexutil.Bytes(req.Data),
+		},
+	}
+}
+
+// SignForwardRequest signs the ForwardRequest for relaying by the trusted
+// forwarder, returning a 65-byte signature, with V in {27,28}, as accepted by
+// the forwarder's execute() function. The request's From address MUST be the
+// Signer's address.
+func SignForwardRequest(s SignerBackend, fwd TrustedForwarder, req ForwardRequest) ([]byte, error) {
+	if req.From != s.Address() {
+		return nil, fmt.Errorf("forward request from %v is not signer %v", req.From, s.Address())
+	}
+
+	sig, err := s.SignTypedData(ForwardRequestTypedData(fwd, req))
+	if err != nil {
+		return nil, fmt.Errorf("sign forward request: %v", err)
+	}
+	// The Signer may be in compact mode but the forwarder requires all of v, r,
+	// and s.
+	if sig, err = canonicalSignature(sig); err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
@@ -0,0 +1,97 @@
+package eth_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestSignForwardRequest(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	fwd := TrustedForwarder{
+		Name:    "MinimalForwarder",
+		Version: "0.0.1",
+		ChainID: big.NewInt(1337),
+		Address: common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3"),
+	}
+	req := ForwardRequest{
+		From:  signer.Address(),
+		To:    common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		Value: big.NewInt(0),
+		Gas:   big.NewInt(1e5),
+		Nonce: big.NewInt(3),
+		Data:  []byte{0xde, 0xad, 0xbe, 0xef},
+	}
+
+	// Independently compute the digest as OpenZeppelin's MinimalForwarder
+	// does, to confirm that the typed-data encoding is correct.
+	word := func(x *big.Int) []byte {
+		return common.LeftPadBytes(x.Bytes(), 32)
+	}
+	addr := func(a common.Address) []byte {
+		return common.LeftPadBytes(a.Bytes(), 32)
+	}
+	domainSep := crypto.Keccak256(
+		crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")),
+		crypto.Keccak256([]byte(fwd.Name)),
+		crypto.Keccak256([]byte(fwd.Version)),
+		word(fwd.ChainID),
+		addr(fwd.Address),
+	)
+	structHash := crypto.Keccak256(
+		crypto.Keccak256([]byte("ForwardRequest(address from,address to,uint256 value,uint256 gas,uint256 nonce,bytes data)")),
+		addr(req.From),
+		addr(req.To),
+		word(req.Value),
+		word(req.Gas),
+		word(req.Nonce),
+		crypto.Keccak256(req.Data),
+	)
+	digest := crypto.Keccak256([]byte{0x19, 0x01}, domainSep, structHash)
+
+	got, err := TypedDataDigest(ForwardRequestTypedData(fwd, req))
+	if err != nil {
+		t.Fatalf("TypedDataDigest(ForwardRequestTypedData(…)) error %v", err)
+	}
+	if !bytes.Equal(got, digest) {
+		t.Errorf("TypedDataDigest(ForwardRequestTypedData(…)) got %#x; want %#x", got, digest)
+	}
+
+	for _, s := range []*Signer{signer, signer.Compact()} {
+		sig, err := SignForwardRequest(s, fwd, req)
+		if err != nil {
+			t.Fatalf("SignForwardRequest() error %v", err)
+		}
+		if n := len(sig); n != 65 {
+			t.Fatalf("SignForwardRequest() got %d-byte signature; want 65", n)
+		}
+		if v := sig[64]; v != 27 && v != 28 {
+			t.Errorf("SignForwardRequest() got v = %d; want 27 or 28", v)
+		}
+
+		rsv := append([]byte{}, sig...)
+		rsv[64] -= 27
+		pub, err := crypto.SigToPub(digest, rsv)
+		if err != nil {
+			t.Fatalf("crypto.SigToPub(<request digest>, <request signature>) error %v", err)
+		}
+		if got, want := crypto.PubkeyToAddress(*pub), req.From; got != want {
+			t.Errorf("SignForwardRequest() signature recovers to %v; want %v", got, want)
+		}
+	}
+
+	other := req
+	other.From = req.To
+	if _, err := SignForwardRequest(signer, fwd, other); err == nil {
+		t.Errorf("SignForwardRequest() with from != signer got nil error; want error")
+	}
+}
@@ -0,0 +1,385 @@
+// Package interactive provides an Ethereum signer for which every signature is
+// approved, and produced, by a browser wallet connected to a local session.
+// This allows people who don't handle private keys, e.g. artists or operations
+// staff, to review and approve signing runs from their usual wallet.
+//
+// A Session is an http.Handler serving a page that connects to the browser's
+// injected wallet (e.g. MetaMask). Each signing request is displayed on the
+// page, alongside a description, and forwarded to the wallet, which shows the
+// message or typed data for approval.
+//
+// Only the page served by the Session can use it: every other endpoint
+// requires a random, per-session token that is embedded in the page, and
+// requests MUST be to a loopback host from the same origin, which guards
+// against other websites, including via DNS rebinding, driving the session.
+package interactive
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/divergencetech/ethier/eth"
+
+	_ "embed"
+)
+
+//go:embed session.html
+var sessionHTML string
+
+// sessionTmpl is executed with the session token.
+var sessionTmpl = template.Must(template.New("session").Parse(sessionHTML))
+
+// TokenHeader is the HTTP header in which the session page sends the token
+// required by every endpoint other than the page itself.
+const TokenHeader = "X-Ethier-Session-Token"
+
+// Methods used for Request.Method, matching those of the wallet's JSON-RPC API.
+const (
+	PersonalSign  = "personal_sign"
+	SignTypedData = "eth_signTypedData_v4"
+)
+
+// A Request is a pending signature request, as sent to the browser.
+type Request struct {
+	ID     int    `json:"id"`
+	Method string `json:"method"`
+	// Description is shown to the user alongside the request.
+	Description string `json:"description"`
+	// Message is set for PersonalSign requests.
+	Message hexutil.Bytes `json:"message,omitempty"`
+	// TypedData is set for SignTypedData requests.
+	TypedData *apitypes.TypedData `json:"typedData,omitempty"`
+}
+
+// A response is the browser's reply to a Request.
+type response struct {
+	ID        int           `json:"id"`
+	Signature hexutil.Bytes `json:"signature"`
+	Error     string        `json:"error"`
+}
+
+// A request is a Request awaiting its response.
+type request struct {
+	Request
+	resp chan response
+}
+
+// A Session brokers signature requests between Signers and a browser wallet.
+// It MUST only be served on a loopback address, e.g. with a Listener from
+// Listen(), as the token embedded in its page is available to any local
+// client.
+type Session struct {
+	mux   *http.ServeMux
+	token string
+
+	connected chan struct{}
+	once      sync.Once
+	account   common.Address
+
+	mu      sync.Mutex
+	nextID  int
+	pending []*request
+}
+
+// NewSession returns a new Session with a random token, which is typically
+// served with http.Serve(<Listener from Listen()>, session).
+func NewSession() (*Session, error) {
+	tok := make([]byte, 32)
+	if _, err := rand.Read(tok); err != nil {
+		return nil, fmt.Errorf("generate session token: %v", err)
+	}
+
+	s := &Session{
+		mux:       http.NewServeMux(),
+		token:     hex.EncodeToString(tok),
+		connected: make(chan struct{}),
+	}
+	s.mux.HandleFunc("/", s.serveIndex)
+	s.mux.HandleFunc("/connect", s.serveConnect)
+	s.mux.HandleFunc("/next", s.serveNext)
+	s.mux.HandleFunc("/result", s.serveResult)
+	return s, nil
+}
+
+// Listen returns a TCP Listener on addr, which MUST have a loopback host, e.g.
+// localhost:8546.
+func Listen(addr string) (net.Listener, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if !isLoopback(host) {
+		return nil, fmt.Errorf("host %q is not a loopback address", host)
+	}
+	return net.Listen("tcp", addr)
+}
+
+// isLoopback returns whether host is "localhost" or a loopback IP.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ServeHTTP implements http.Handler, rejecting requests that aren't from the
+// session's own page.
+func (s *Session) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := s.checkOrigin(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if r.URL.Path != "/" && subtle.ConstantTimeCompare([]byte(r.Header.Get(TokenHeader)), []byte(s.token)) != 1 {
+		http.Error(w, "invalid session token", http.StatusForbidden)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// checkOrigin returns an error if the request's Host isn't a loopback address,
+// as is the case with DNS rebinding, or if it has an Origin other than the
+// Host, as is the case with cross-origin requests.
+func (s *Session) checkOrigin(r *http.Request) error {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if !isLoopback(host) {
+		return fmt.Errorf("host %q is not a loopback address", r.Host)
+	}
+
+	o := r.Header.Get("Origin")
+	if o == "" {
+		return nil
+	}
+	u, err := url.Parse(o)
+	if err != nil || u.Scheme != "http" || u.Host != r.Host {
+		return fmt.Errorf("cross-origin request from %q", o)
+	}
+	return nil
+}
+
+func (s *Session) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	sessionTmpl.Execute(w, s.token)
+}
+
+func (s *Session) serveConnect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Address common.Address `json:"address"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ok := false
+	s.once.Do(func() {
+		s.account = req.Address
+		close(s.connected)
+		ok = true
+	})
+	if !ok && req.Address != s.account {
+		http.Error(w, fmt.Sprintf("session already connected to %v", s.account), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Session) serveNext(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	var next *Request
+	if len(s.pending) > 0 {
+		next = &s.pending[0].Request
+	}
+	s.mu.Unlock()
+
+	if next == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(next)
+}
+
+func (s *Session) serveResult(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+	var resp response
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	var req *request
+	for i, p := range s.pending {
+		if p.ID == resp.ID {
+			req = p
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if req == nil {
+		http.Error(w, fmt.Sprintf("no pending request %d", resp.ID), http.StatusNotFound)
+		return
+	}
+	req.resp <- resp
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Connect blocks until a wallet has connected to the Session, returning a
+// Signer for the wallet's account.
+func (s *Session) Connect(ctx context.Context) (*Signer, error) {
+	select {
+	case <-s.connected:
+		return &Signer{session: s, addr: s.account, Timeout: DefaultTimeout}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// do queues the Request and waits for the browser's response.
+func (s *Session) do(ctx context.Context, r Request) ([]byte, error) {
+	req := &request{resp: make(chan response, 1)}
+
+	s.mu.Lock()
+	s.nextID++
+	r.ID = s.nextID
+	req.Request = r
+	s.pending = append(s.pending, req)
+	s.mu.Unlock()
+
+	select {
+	case resp := <-req.resp:
+		if resp.Error != "" {
+			return nil, fmt.Errorf("wallet: %s", resp.Error)
+		}
+		return resp.Signature, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for i, p := range s.pending {
+			if p == req {
+				s.pending = append(s.pending[:i], s.pending[i+1:]...)
+				break
+			}
+		}
+		s.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// ErrDigestUnsupported is returned by Signer.SignRawDigest().
+var ErrDigestUnsupported = errors.New("wallets don't sign raw digests")
+
+// DefaultTimeout is the default value of Signer.Timeout.
+const DefaultTimeout = 10 * time.Minute
+
+// A Signer requests signatures from the wallet connected to a Session. Every
+// returned signature is checked to have been produced by the connected account.
+type Signer struct {
+	session *Session
+	addr    common.Address
+
+	// Description, if non-empty, is shown alongside every request.
+	Description string
+	// Timeout limits the time to wait for the user's approval of each request.
+	Timeout time.Duration
+}
+
+var _ eth.PersonalMessageSigner = (*Signer)(nil)
+
+// String returns s.Address() as a string.
+func (s *Signer) String() string {
+	return s.Address().String()
+}
+
+// Address returns the address of the connected wallet account.
+func (s *Signer) Address() common.Address {
+	return s.addr
+}
+
+// SignRawDigest always returns ErrDigestUnsupported.
+func (s *Signer) SignRawDigest([]byte) ([]byte, error) {
+	return nil, ErrDigestUnsupported
+}
+
+func (s *Signer) request(r Request) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	r.Description = s.Description
+	return s.session.do(ctx, r)
+}
+
+// SignPersonalMessage requests the wallet's EIP-191 personal signature of buf,
+// returning it in canonical form as per eth.NormalizeSignature().
+func (s *Signer) SignPersonalMessage(buf []byte) ([]byte, error) {
+	sig, err := s.request(Request{
+		Method:  PersonalSign,
+		Message: buf,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if got, err := eth.RecoverPersonalAddress(buf, sig); err != nil {
+		return nil, err
+	} else if got != s.addr {
+		return nil, fmt.Errorf("wallet signature by %v; expecting %v", got, s.addr)
+	}
+	return eth.NormalizeSignature(sig)
+}
+
+// SignTypedData requests the wallet's EIP-712 signature of td, returning it in
+// canonical form as per eth.NormalizeSignature().
+func (s *Signer) SignTypedData(td apitypes.TypedData) ([]byte, error) {
+	digest, err := eth.TypedDataDigest(td)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.request(Request{
+		Method:    SignTypedData,
+		TypedData: &td,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if got, err := eth.RecoverAddress(digest, sig); err != nil {
+		return nil, err
+	} else if got != s.addr {
+		return nil, fmt.Errorf("wallet signature by %v; expecting %v", got, s.addr)
+	}
+	return eth.NormalizeSignature(sig)
+}
+
+// PersonalSign returns eth.PersonalSign(s, buf).
+func (s *Signer) PersonalSign(buf []byte) ([]byte, error) {
+	return eth.PersonalSign(s, buf)
+}
@@ -0,0 +1,309 @@
+package interactive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// browser mimics the session page, signing every request with the wallet
+// unless reject is true. As its methods are called from goroutines other than
+// the test's, failures are reported with Errorf() and not Fatalf().
+type browser struct {
+	t      *testing.T
+	url    string
+	token  string
+	wallet *eth.Signer
+	reject bool
+}
+
+// do sends the request with the session token.
+func (b *browser) do(method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, b.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TokenHeader, b.token)
+	return http.DefaultClient.Do(req)
+}
+
+func (b *browser) post(path string, body interface{}) {
+	b.t.Helper()
+	buf, err := json.Marshal(body)
+	if err != nil {
+		b.t.Errorf("json.Marshal(%T) error %v", body, err)
+		return
+	}
+	res, err := b.do(http.MethodPost, path, bytes.NewReader(buf))
+	if err != nil {
+		b.t.Errorf("POST %s error %v", path, err)
+		return
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		b.t.Errorf("POST %s got status %d; want %d", path, res.StatusCode, http.StatusNoContent)
+	}
+}
+
+// handleNext handles a single request, polling until one is available.
+func (b *browser) handleNext() {
+	b.t.Helper()
+	for {
+		res, err := b.do(http.MethodGet, "/next", nil)
+		if err != nil {
+			b.t.Errorf("GET /next error %v", err)
+			return
+		}
+		if res.StatusCode == http.StatusNoContent {
+			res.Body.Close()
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+
+		var req Request
+		err = json.NewDecoder(res.Body).Decode(&req)
+		res.Body.Close()
+		if err != nil {
+			b.t.Errorf("decode /next error %v", err)
+			return
+		}
+
+		if b.reject {
+			b.post("/result", response{ID: req.ID, Error: "User rejected the request."})
+			return
+		}
+
+		var sig []byte
+		switch req.Method {
+		case PersonalSign:
+			sig, err = b.wallet.EthSignMessage(req.Message)
+		case SignTypedData:
+			sig, err = b.wallet.SignTypedData(*req.TypedData)
+		}
+		if err != nil {
+			b.t.Errorf("signing %s request error %v", req.Method, err)
+			return
+		}
+		b.post("/result", response{ID: req.ID, Signature: sig})
+		return
+	}
+}
+
+func TestSession(t *testing.T) {
+	wallet, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	session, err := NewSession()
+	if err != nil {
+		t.Fatalf("NewSession() error %v", err)
+	}
+	srv := httptest.NewServer(session)
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET / error %v", err)
+	}
+	page, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("read GET / error %v", err)
+	}
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("GET / got Content-Type %q; want text/html", ct)
+	}
+	m := regexp.MustCompile(`name="ethier-session-token" content="([0-9a-f]+)"`).FindSubmatch(page)
+	if m == nil || string(m[1]) != session.token {
+		t.Fatalf("GET / page does not embed session token")
+	}
+
+	b := &browser{t: t, url: srv.URL, token: string(m[1]), wallet: wallet}
+	b.post("/connect", map[string]interface{}{"address": wallet.Address()})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s, err := session.Connect(ctx)
+	if err != nil {
+		t.Fatalf("%T.Connect() error %v", session, err)
+	}
+	if got, want := s.Address(), wallet.Address(); got != want {
+		t.Fatalf("%T.Connect().Address() got %v; want %v", session, got, want)
+	}
+	s.Timeout = 5 * time.Second
+
+	t.Run("PersonalSign", func(t *testing.T) {
+		b.t = t
+		go b.handleNext()
+
+		msg := []byte("hello")
+		sig, err := s.PersonalSign(msg)
+		if err != nil {
+			t.Fatalf("%T.PersonalSign() error %v", s, err)
+		}
+		if !wallet.VerifyPersonal(msg, sig) {
+			t.Errorf("%T.PersonalSign() signature not verified", s)
+		}
+	})
+
+	t.Run("SignTypedData", func(t *testing.T) {
+		b.t = t
+		go b.handleNext()
+
+		td := eth.PermitTypedData(
+			eth.PermitToken{Name: "Token", Version: "1", ChainID: big.NewInt(1), Address: common.HexToAddress("0x02")},
+			wallet.Address(), common.HexToAddress("0x03"), big.NewInt(100), big.NewInt(0), big.NewInt(1e9),
+		)
+		sig, err := s.SignTypedData(td)
+		if err != nil {
+			t.Fatalf("%T.SignTypedData() error %v", s, err)
+		}
+		digest, err := eth.TypedDataDigest(td)
+		if err != nil {
+			t.Fatalf("eth.TypedDataDigest() error %v", err)
+		}
+		if got, err := eth.RecoverAddress(digest, sig); err != nil || got != wallet.Address() {
+			t.Errorf("eth.RecoverAddress(…, %T.SignTypedData()) got %v, err = %v; want %v, nil err", s, got, err, wallet.Address())
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		b.t = t
+		b.reject = true
+		defer func() { b.reject = false }()
+		go b.handleNext()
+
+		if _, err := s.PersonalSign([]byte("nope")); err == nil {
+			t.Errorf("%T.PersonalSign() rejected by wallet got nil error; want error", s)
+		}
+	})
+
+	t.Run("wrong account", func(t *testing.T) {
+		other, err := eth.NewSigner(128)
+		if err != nil {
+			t.Fatalf("eth.NewSigner(128) error %v", err)
+		}
+		b.t = t
+		b.wallet = other
+		defer func() { b.wallet = wallet }()
+		go b.handleNext()
+
+		if _, err := s.PersonalSign([]byte("hello")); err == nil {
+			t.Errorf("%T.PersonalSign() signed by different account got nil error; want error", s)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		s := *s
+		s.Timeout = 50 * time.Millisecond
+		if _, err := s.PersonalSign([]byte("hello")); err != context.DeadlineExceeded {
+			t.Errorf("%T.PersonalSign() without browser got err %v; want %v", s, err, context.DeadlineExceeded)
+		}
+
+		res, err := b.do(http.MethodGet, "/next", nil)
+		if err != nil {
+			t.Fatalf("GET /next error %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusNoContent {
+			t.Errorf("GET /next after timeout got status %d; want %d", res.StatusCode, http.StatusNoContent)
+		}
+	})
+
+	t.Run("SignRawDigest", func(t *testing.T) {
+		if _, err := s.SignRawDigest(make([]byte, 32)); err != ErrDigestUnsupported {
+			t.Errorf("%T.SignRawDigest() got err %v; want %v", s, err, ErrDigestUnsupported)
+		}
+	})
+}
+
+func TestSessionRejectsOtherOrigins(t *testing.T) {
+	session, err := NewSession()
+	if err != nil {
+		t.Fatalf("NewSession() error %v", err)
+	}
+	const body = `{"address":"0x000000000000000000000000000000000000dEaD"}`
+
+	tests := []struct {
+		name           string
+		host, origin   string
+		token          string
+		wantStatusCode int
+	}{
+		{
+			name:           "no token",
+			host:           "localhost:8546",
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "wrong token",
+			host:           "localhost:8546",
+			token:          strings.Repeat("0", 64),
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "cross origin",
+			host:           "localhost:8546",
+			origin:         "https://evil.example",
+			token:          session.token,
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "DNS rebinding",
+			host:           "evil.example:8546",
+			origin:         "http://evil.example:8546",
+			token:          session.token,
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "same origin",
+			host:           "127.0.0.1:8546",
+			origin:         "http://127.0.0.1:8546",
+			token:          session.token,
+			wantStatusCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/connect", strings.NewReader(body))
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.token != "" {
+				req.Header.Set(TokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			session.ServeHTTP(rec, req)
+			if got := rec.Code; got != tt.wantStatusCode {
+				t.Errorf("POST /connect got status %d; want %d", got, tt.wantStatusCode)
+			}
+		})
+	}
+
+	t.Run("Listen", func(t *testing.T) {
+		if _, err := Listen("0.0.0.0:0"); err == nil {
+			t.Errorf("Listen(<non-loopback>) got nil error; want error")
+		}
+		l, err := Listen("127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Listen(<loopback>) error %v", err)
+		}
+		l.Close()
+	})
+}
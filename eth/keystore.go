@@ -0,0 +1,71 @@
+package eth
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/google/uuid"
+)
+
+// NewSignerFromKeystore reads the encrypted JSON key file at path, as produced
+// by geth, ethers, and most other wallets, and decrypts it with the password.
+// The returned Signer has no associated mnemonic.
+func NewSignerFromKeystore(path, password string) (*Signer, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore: %v", err)
+	}
+	return NewSignerFromKeystoreJSON(buf, password)
+}
+
+// NewSignerFromKeystoreJSON is equivalent to NewSignerFromKeystore() but
+// accepts the encrypted JSON key directly instead of reading it from a file.
+func NewSignerFromKeystoreJSON(keyJSON []byte, password string) (*Signer, error) {
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt keystore: %v", err)
+	}
+	return &Signer{key: key.PrivateKey}, nil
+}
+
+// SaveKeystore encrypts the Signer's private key with the password, using the
+// same standard scrypt parameters as geth, and writes it as a JSON key file to
+// path, which MUST NOT already exist. The resulting file can be loaded with
+// NewSignerFromKeystore() or imported into most wallets. The mnemonic, if any,
+// is not stored.
+func (s *Signer) SaveKeystore(path, password string) error {
+	buf, err := s.KeystoreJSON(password)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("create keystore file: %v", err)
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return fmt.Errorf("write keystore file: %v", err)
+	}
+	return f.Close()
+}
+
+// KeystoreJSON returns the encrypted JSON key that SaveKeystore() writes to
+// file.
+func (s *Signer) KeystoreJSON(password string) ([]byte, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("generate key ID: %v", err)
+	}
+	key := &keystore.Key{
+		Id:         id,
+		Address:    s.Address(),
+		PrivateKey: s.key,
+	}
+	buf, err := keystore.EncryptKey(key, password, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt key: %v", err)
+	}
+	return buf, nil
+}
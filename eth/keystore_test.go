@@ -0,0 +1,80 @@
+package eth_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestNewSignerFromKeystore(t *testing.T) {
+	const password = "hunter2"
+	acc, err := keystore.StoreKey(t.TempDir(), password, keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("keystore.StoreKey() error %v", err)
+	}
+	path := acc.URL.Path
+
+	t.Run("correct password", func(t *testing.T) {
+		s, err := NewSignerFromKeystore(path, password)
+		if err != nil {
+			t.Fatalf("NewSignerFromKeystore(…, %q) error %v", password, err)
+		}
+		if got, want := s.Address(), acc.Address; got != want {
+			t.Errorf("NewSignerFromKeystore(…, %q).Address() got %v; want %v", password, got, want)
+		}
+	})
+
+	t.Run("incorrect password", func(t *testing.T) {
+		if _, err := NewSignerFromKeystore(path, "wrong"); err == nil {
+			t.Errorf("NewSignerFromKeystore(…, [incorrect password]) got nil error; want error")
+		}
+	})
+}
+
+func TestSaveKeystore(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	const password = "correct horse battery staple"
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := signer.SaveKeystore(path, password); err != nil {
+		t.Fatalf("%T.SaveKeystore(%q, …) error %v", signer, path, err)
+	}
+
+	got, err := NewSignerFromKeystore(path, password)
+	if err != nil {
+		t.Fatalf("NewSignerFromKeystore(%q, …) error %v", path, err)
+	}
+	if got, want := got.Address(), signer.Address(); got != want {
+		t.Errorf("NewSignerFromKeystore(%T.SaveKeystore()).Address() got %v; want %v", signer, got, want)
+	}
+
+	if err := signer.SaveKeystore(path, password); err == nil {
+		t.Errorf("%T.SaveKeystore() to existing file got nil error; want error", signer)
+	}
+}
+
+func TestKeystoreJSON(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	const password = "hunter2"
+	buf, err := signer.KeystoreJSON(password)
+	if err != nil {
+		t.Fatalf("%T.KeystoreJSON() error %v", signer, err)
+	}
+	got, err := NewSignerFromKeystoreJSON(buf, password)
+	if err != nil {
+		t.Fatalf("NewSignerFromKeystoreJSON(%T.KeystoreJSON()) error %v", signer, err)
+	}
+	if got, want := got.Address(), signer.Address(); got != want {
+		t.Errorf("NewSignerFromKeystoreJSON(%T.KeystoreJSON()).Address() got %v; want %v", signer, got, want)
+	}
+}
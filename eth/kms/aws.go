@@ -0,0 +1,225 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSCredentials are the credentials used to sign requests to AWS KMS.
+type AWSCredentials struct {
+	AccessKeyID, SecretAccessKey string
+	// SessionToken is only required for temporary credentials.
+	SessionToken string
+}
+
+// AWS is a Backend for an asymmetric ECC_SECG_P256K1 key held in AWS KMS. It
+// communicates with the KMS JSON API directly, without requiring the AWS SDK;
+// the IAM principal requires kms:GetPublicKey and kms:Sign permissions.
+type AWS struct {
+	// KeyID is any identifier accepted by KMS; e.g. key ID, ARN, or alias.
+	KeyID       string
+	Region      string
+	Credentials AWSCredentials
+
+	// Endpoint, if non-empty, overrides the default regional KMS endpoint.
+	Endpoint string
+	// Client, if non-nil, is used instead of http.DefaultClient.
+	Client *http.Client
+}
+
+var _ Backend = (*AWS)(nil)
+
+// AWSFromEnv returns an AWS Backend for the key, with region and credentials
+// read from the standard AWS environment variables: AWS_REGION (or
+// AWS_DEFAULT_REGION), AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and the
+// optional AWS_SESSION_TOKEN.
+func AWSFromEnv(keyID string) (*AWS, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		return nil, fmt.Errorf("neither $AWS_REGION nor $AWS_DEFAULT_REGION set")
+	}
+
+	creds := AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("$AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY must both be set")
+	}
+
+	return &AWS{
+		KeyID:       keyID,
+		Region:      region,
+		Credentials: creds,
+	}, nil
+}
+
+// PublicKey returns the key's DER-encoded public key, via the KMS GetPublicKey
+// action.
+func (a *AWS) PublicKey(ctx context.Context) ([]byte, error) {
+	var resp struct {
+		PublicKey []byte
+		KeySpec   string
+	}
+	if err := a.call(ctx, "GetPublicKey", map[string]string{"KeyId": a.KeyID}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.KeySpec != "" && resp.KeySpec != "ECC_SECG_P256K1" {
+		return nil, fmt.Errorf("KMS key spec %q; expecting ECC_SECG_P256K1", resp.KeySpec)
+	}
+	return resp.PublicKey, nil
+}
+
+// SignDigest returns the key's DER-encoded signature of the digest, via the
+// KMS Sign action.
+func (a *AWS) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	req := struct {
+		KeyId            string
+		Message          []byte
+		MessageType      string
+		SigningAlgorithm string
+	}{
+		KeyId:            a.KeyID,
+		Message:          digest,
+		MessageType:      "DIGEST",
+		SigningAlgorithm: "ECDSA_SHA_256",
+	}
+	var resp struct {
+		Signature []byte
+	}
+	if err := a.call(ctx, "Sign", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// call performs a signed request to the KMS JSON API, decoding the response
+// into resp. Byte slices are base64 encoded by both encoding/json and KMS.
+func (a *AWS) call(ctx context.Context, action string, req, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(%T): %v", req, err)
+	}
+
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", a.Region)
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create %s request: %v", action, err)
+	}
+	r.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	r.Header.Set("X-Amz-Target", "TrentService."+action)
+	signV4(r, body, a.Credentials, a.Region, "kms", time.Now())
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(r)
+	if err != nil {
+		return fmt.Errorf("KMS %s: %v", action, err)
+	}
+	defer res.Body.Close()
+
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("read KMS %s response: %v", action, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("KMS %s: %s: %s", action, res.Status, buf)
+	}
+	if err := json.Unmarshal(buf, resp); err != nil {
+		return fmt.Errorf("json.Unmarshal(<KMS %s response>, %T): %v", action, resp, err)
+	}
+	return nil
+}
+
+// signV4 adds AWS Signature Version 4 authentication headers to the request.
+// All headers already present on the request, plus Host, are signed. See
+// https://docs.aws.amazon.com/general/latest/gr/sigv4_signing.html.
+func signV4(r *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	r.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": r.URL.Host}
+	for k, v := range r.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		fmt.Fprintf(&canonHeaders, "%s:%s\n", k, headers[k])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		r.Method,
+		path,
+		r.URL.Query().Encode(),
+		canonHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	toSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonical)),
+	}, "\n")
+
+	key := []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	r.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, sig,
+	))
+}
+
+func sha256Hex(buf []byte) string {
+	h := sha256.Sum256(buf)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
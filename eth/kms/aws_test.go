@@ -0,0 +1,116 @@
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestSignV4(t *testing.T) {
+	// The get-vanilla case from the AWS Signature Version 4 test suite.
+	r, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest() error %v", err)
+	}
+	creds := AWSCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	signV4(r, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	const want = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := r.Header.Get("Authorization"); got != want {
+		t.Errorf("signV4() got Authorization header %q; want %q", got, want)
+	}
+}
+
+func TestAWS(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("crypto.GenerateKey() error %v", err)
+	}
+	fake := &fakeBackend{key: key}
+
+	const keyID = "alias/ethier-test"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			http.Error(w, "unsigned request", http.StatusForbidden)
+			return
+		}
+
+		var req struct {
+			KeyId       string
+			Message     []byte
+			MessageType string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.KeyId != keyID {
+			http.Error(w, "unknown key", http.StatusNotFound)
+			return
+		}
+
+		var resp interface{}
+		switch target := r.Header.Get("X-Amz-Target"); target {
+		case "TrentService.GetPublicKey":
+			pub, _ := fake.PublicKey(r.Context())
+			resp = map[string]interface{}{"PublicKey": pub, "KeySpec": "ECC_SECG_P256K1"}
+		case "TrentService.Sign":
+			if req.MessageType != "DIGEST" {
+				http.Error(w, "non-digest message", http.StatusBadRequest)
+				return
+			}
+			sig, _ := fake.SignDigest(r.Context(), req.Message)
+			resp = map[string]interface{}{"Signature": sig}
+		default:
+			http.Error(w, "unknown target "+target, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	aws := &AWS{
+		KeyID:       keyID,
+		Region:      "us-east-1",
+		Credentials: AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		Endpoint:    srv.URL,
+		Client:      srv.Client(),
+	}
+	s, err := New(context.Background(), aws)
+	if err != nil {
+		t.Fatalf("New(%T) error %v", aws, err)
+	}
+	if got, want := s.Address(), crypto.PubkeyToAddress(key.PublicKey); got != want {
+		t.Errorf("New(%T).Address() got %v; want %v", aws, got, want)
+	}
+
+	digest := crypto.Keccak256([]byte("hello"))
+	sig, err := s.SignRawDigest(digest)
+	if err != nil {
+		t.Fatalf("%T.SignRawDigest() error %v", s, err)
+	}
+	got, err := eth.RecoverAddress(digest, sig)
+	if err != nil {
+		t.Fatalf("eth.RecoverAddress() error %v", err)
+	}
+	if want := s.Address(); got != want {
+		t.Errorf("%T.SignRawDigest() recovers to %v; want %v", s, got, want)
+	}
+
+	aws.Credentials.AccessKeyID = "other"
+	if _, err := aws.SignDigest(context.Background(), digest); err == nil {
+		t.Errorf("%T.SignDigest() rejected by server; got nil error", aws)
+	}
+}
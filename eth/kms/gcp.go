@@ -0,0 +1,144 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GCP is a Backend for an asymmetric EC_SIGN_SECP256K1_SHA256 key version held
+// in Google Cloud KMS. It communicates with the Cloud KMS REST API directly,
+// without requiring the Google Cloud SDK; the principal requires the
+// cloudkms.cryptoKeyVersions.viewPublicKey and
+// cloudkms.cryptoKeyVersions.useToSign permissions.
+type GCP struct {
+	// KeyVersion is the full resource name of the key version; i.e.
+	// projects/*/locations/*/keyRings/*/cryptoKeys/*/cryptoKeyVersions/*.
+	KeyVersion string
+	// Token returns an OAuth2 access token for each request. If nil,
+	// GCPTokenFromEnv is used.
+	Token func(context.Context) (string, error)
+
+	// Endpoint, if non-empty, overrides the default Cloud KMS endpoint.
+	Endpoint string
+	// Client, if non-nil, is used instead of http.DefaultClient.
+	Client *http.Client
+}
+
+var _ Backend = (*GCP)(nil)
+
+// GCPTokenFromEnv returns the value of the GOOGLE_OAUTH_ACCESS_TOKEN
+// environment variable if set, otherwise it falls back to the output of
+// `gcloud auth print-access-token`.
+func GCPTokenFromEnv(ctx context.Context) (string, error) {
+	if tok := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); tok != "" {
+		return tok, nil
+	}
+	out, err := exec.CommandContext(ctx, "gcloud", "auth", "print-access-token").Output()
+	if err != nil {
+		return "", fmt.Errorf("$GOOGLE_OAUTH_ACCESS_TOKEN not set and `gcloud auth print-access-token` failed: %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// PublicKey returns the key version's DER-encoded public key.
+func (g *GCP) PublicKey(ctx context.Context) ([]byte, error) {
+	var resp struct {
+		PEM       string `json:"pem"`
+		Algorithm string `json:"algorithm"`
+	}
+	if err := g.call(ctx, http.MethodGet, "/publicKey", nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Algorithm != "" && resp.Algorithm != "EC_SIGN_SECP256K1_SHA256" {
+		return nil, fmt.Errorf("KMS key algorithm %q; expecting EC_SIGN_SECP256K1_SHA256", resp.Algorithm)
+	}
+	block, _ := pem.Decode([]byte(resp.PEM))
+	if block == nil {
+		return nil, errors.New("no PEM block in KMS public key")
+	}
+	return block.Bytes, nil
+}
+
+// SignDigest returns the key version's DER-encoded signature of the digest.
+// Cloud KMS requires the digest to be labelled with a hash function, for which
+// SHA-256 is used, but the digest is signed as-is so Keccak256 digests are
+// supported.
+func (g *GCP) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	req := map[string]interface{}{
+		"digest": map[string][]byte{"sha256": digest},
+	}
+	var resp struct {
+		Signature []byte `json:"signature"`
+	}
+	if err := g.call(ctx, http.MethodPost, ":asymmetricSign", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// call performs an authenticated request against the key version's resource,
+// decoding the response into resp.
+func (g *GCP) call(ctx context.Context, method, suffix string, req, resp interface{}) error {
+	var body io.Reader
+	if req != nil {
+		buf, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("json.Marshal(%T): %v", req, err)
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = "https://cloudkms.googleapis.com"
+	}
+	url := fmt.Sprintf("%s/v1/%s%s", strings.TrimSuffix(endpoint, "/"), g.KeyVersion, suffix)
+	r, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("create request: %v", err)
+	}
+	if req != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	token := g.Token
+	if token == nil {
+		token = GCPTokenFromEnv
+	}
+	tok, err := token(ctx)
+	if err != nil {
+		return fmt.Errorf("obtain access token: %v", err)
+	}
+	r.Header.Set("Authorization", "Bearer "+tok)
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(r)
+	if err != nil {
+		return fmt.Errorf("KMS %s %s: %v", method, suffix, err)
+	}
+	defer res.Body.Close()
+
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("read KMS response: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("KMS %s %s: %s: %s", method, suffix, res.Status, buf)
+	}
+	if err := json.Unmarshal(buf, resp); err != nil {
+		return fmt.Errorf("json.Unmarshal(<KMS response>, %T): %v", resp, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestGCP(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("crypto.GenerateKey() error %v", err)
+	}
+	fake := &fakeBackend{key: key, highS: true}
+
+	const (
+		version = "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"
+		token   = "test-token"
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/"+version+"/publicKey", func(w http.ResponseWriter, r *http.Request) {
+		der, _ := fake.PublicKey(r.Context())
+		json.NewEncoder(w).Encode(map[string]string{
+			"pem":       string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+			"algorithm": "EC_SIGN_SECP256K1_SHA256",
+		})
+	})
+	mux.HandleFunc("/v1/"+version+":asymmetricSign", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Digest struct {
+				SHA256 []byte `json:"sha256"`
+			} `json:"digest"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sig, _ := fake.SignDigest(r.Context(), req.Digest.SHA256)
+		json.NewEncoder(w).Encode(map[string][]byte{"signature": sig})
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	gcp := &GCP{
+		KeyVersion: version,
+		Token: func(context.Context) (string, error) {
+			return token, nil
+		},
+		Endpoint: srv.URL,
+		Client:   srv.Client(),
+	}
+	s, err := New(context.Background(), gcp)
+	if err != nil {
+		t.Fatalf("New(%T) error %v", gcp, err)
+	}
+	if got, want := s.Address(), crypto.PubkeyToAddress(key.PublicKey); got != want {
+		t.Errorf("New(%T).Address() got %v; want %v", gcp, got, want)
+	}
+
+	digest := crypto.Keccak256([]byte("hello"))
+	sig, err := s.SignRawDigest(digest)
+	if err != nil {
+		t.Fatalf("%T.SignRawDigest() error %v", s, err)
+	}
+	got, err := eth.RecoverAddress(digest, sig)
+	if err != nil {
+		t.Fatalf("eth.RecoverAddress() error %v", err)
+	}
+	if want := s.Address(); got != want {
+		t.Errorf("%T.SignRawDigest() recovers to %v; want %v", s, got, want)
+	}
+
+	gcp.Token = func(context.Context) (string, error) {
+		return "wrong", nil
+	}
+	if _, err := gcp.SignDigest(context.Background(), digest); err == nil {
+		t.Errorf("%T.SignDigest() with incorrect token; got nil error", gcp)
+	}
+}
+
+func TestFromURIErrors(t *testing.T) {
+	for _, uri := range []string{
+		"",
+		"projects/p/locations/l/keyRings/r/cryptoKeys/k",
+		"gcp://",
+		"gcp://not/a/key",
+		"azure://vault/key",
+	} {
+		if _, err := FromURI(context.Background(), uri); err == nil {
+			t.Errorf("FromURI(%q) got nil error; want error", uri)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Package kms provides Ethereum signers backed by cloud key-management
+// services (KMS) such that private keys are never handled directly. Keys MUST
+// be asymmetric secp256k1 signing keys; e.g. ECC_SECG_P256K1 on AWS.
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// A Backend signs digests with a secp256k1 key held by a KMS.
+type Backend interface {
+	// PublicKey returns the DER-encoded X.509 SubjectPublicKeyInfo of the key.
+	PublicKey(context.Context) ([]byte, error)
+	// SignDigest returns a DER-encoded ECDSA signature of the 32-byte digest.
+	// The digest MUST be signed as-is, without further hashing.
+	SignDigest(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// DefaultTimeout is the default value of Signer.Timeout.
+const DefaultTimeout = 30 * time.Second
+
+// A Signer signs Ethereum messages and transactions with a KMS-held key. Unlike
+// eth.Signer, it never has access to the private key.
+type Signer struct {
+	backend Backend
+	pub     *ecdsa.PublicKey
+	addr    common.Address
+
+	// Timeout limits the duration of each request to the Backend.
+	Timeout time.Duration
+}
+
+var _ eth.SignerBackend = (*Signer)(nil)
+
+// New fetches the public key from the Backend and returns a Signer that uses
+// the Backend for all signing.
+func New(ctx context.Context, b Backend) (*Signer, error) {
+	der, err := b.PublicKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch public key: %v", err)
+	}
+	pub, err := parsePublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{
+		backend: b,
+		pub:     pub,
+		addr:    crypto.PubkeyToAddress(*pub),
+		Timeout: DefaultTimeout,
+	}, nil
+}
+
+// String returns s.Address() as a string.
+func (s *Signer) String() string {
+	return s.Address().String()
+}
+
+// Address returns the Ethereum address of the KMS key.
+func (s *Signer) Address() common.Address {
+	return s.addr
+}
+
+// SignRawDigest returns a 65-byte ECDSA signature of the 32-byte digest, with V
+// in {27,28}, in the same form as eth.Signer.RawSign(). The KMS's signature is
+// normalised to have a low s value, as required by Ethereum. The digest is
+// signed as-is; see SignDigest() for digests that don't originate in ethier.
+func (s *Signer) SignRawDigest(digest []byte) ([]byte, error) {
+	if n := len(digest); n != 32 {
+		return nil, fmt.Errorf("digest length %d; expecting 32", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	der, err := s.backend.SignDigest(ctx, digest)
+	if err != nil {
+		return nil, fmt.Errorf("KMS sign: %v", err)
+	}
+	return s.toRSV(digest, der)
+}
+
+// Sign returns an ECDSA signature of keccak256(buf).
+func (s *Signer) Sign(buf []byte) ([]byte, error) {
+	return s.SignRawDigest(crypto.Keccak256(buf))
+}
+
+// SignDigest returns eth.SignDigest(s, domainTag, digest). Unlike
+// SignRawDigest(), and the Backend's SignDigest() method, the signed digest is
+// bound to the domain, not the digest itself.
+func (s *Signer) SignDigest(domainTag string, digest [32]byte) ([]byte, error) {
+	return eth.SignDigest(s, domainTag, digest)
+}
+
+// PersonalSign returns eth.PersonalSign(s, buf).
+func (s *Signer) PersonalSign(buf []byte) ([]byte, error) {
+	return eth.PersonalSign(s, buf)
+}
+
+// SignTypedData returns an ECDSA signature of eth.TypedDataDigest(td).
+func (s *Signer) SignTypedData(td apitypes.TypedData) ([]byte, error) {
+	digest, err := eth.TypedDataDigest(td)
+	if err != nil {
+		return nil, err
+	}
+	return s.SignRawDigest(digest)
+}
+
+// SignTx returns eth.SignTx(s, tx, chainID).
+func (s *Signer) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return eth.SignTx(s, tx, chainID)
+}
+
+// TransactOptsWithChainID returns eth.TransactOpts(ctx, s, chainID).
+func (s *Signer) TransactOptsWithChainID(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error) {
+	return eth.TransactOpts(ctx, s, chainID)
+}
+
+var (
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1      = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// parsePublicKey parses a DER-encoded SubjectPublicKeyInfo of a secp256k1 key.
+// The standard library's x509 package doesn't support the curve.
+func parsePublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if rest, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("unmarshal SubjectPublicKeyInfo: %v", err)
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after SubjectPublicKeyInfo")
+	}
+
+	if !info.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, fmt.Errorf("public-key algorithm %v; expecting ECDSA %v", info.Algorithm.Algorithm, oidPublicKeyECDSA)
+	}
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &curve); err != nil {
+		return nil, fmt.Errorf("unmarshal curve OID: %v", err)
+	}
+	if !curve.Equal(oidSecp256k1) {
+		return nil, fmt.Errorf("curve %v; expecting secp256k1 %v", curve, oidSecp256k1)
+	}
+
+	pub, err := crypto.UnmarshalPubkey(info.PublicKey.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal secp256k1 public key: %v", err)
+	}
+	return pub, nil
+}
+
+// toRSV converts a DER-encoded ECDSA signature to the 65-byte [R || S || V]
+// form used by Ethereum, normalising S to the lower half of the curve order
+// (EIP-2) with eth.NormalizeSignature() and determining the recovery ID V, in
+// {27,28}, by trial recovery against the known public key.
+func (s *Signer) toRSV(digest, der []byte) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("unmarshal DER signature: %v", err)
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after DER signature")
+	}
+
+	rsv := make([]byte, 65)
+	for i, x := range []struct {
+		name string
+		val  *big.Int
+	}{
+		{"R", sig.R},
+		{"S", sig.S},
+	} {
+		if x.val.Sign() == -1 || x.val.BitLen() > 256 {
+			return nil, fmt.Errorf("DER signature %s exceeds 32 bytes", x.name)
+		}
+		x.val.FillBytes(rsv[32*i : 32*(i+1)])
+	}
+
+	// V is irrelevant to normalisation as it's determined below.
+	rsv, err := eth.NormalizeSignature(rsv)
+	if err != nil {
+		return nil, err
+	}
+
+	want := crypto.FromECDSAPub(s.pub)
+	for v := byte(0); v < 2; v++ {
+		rsv[64] = v
+		got, err := crypto.Ecrecover(digest, rsv)
+		if err == nil && string(got) == string(want) {
+			rsv[64] += 27
+			return rsv, nil
+		}
+	}
+	return nil, errors.New("KMS signature does not recover to the key's public key")
+}
@@ -0,0 +1,192 @@
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// fakeBackend mimics a KMS by holding a private key in memory and returning
+// DER-encoded public keys and signatures.
+type fakeBackend struct {
+	key *ecdsa.PrivateKey
+	// highS forces signatures to have S in the upper half of the curve order,
+	// which KMS providers don't guard against.
+	highS bool
+}
+
+func (f *fakeBackend) PublicKey(context.Context) ([]byte, error) {
+	curve, err := asn1.Marshal(oidSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: curve},
+		},
+		PublicKey: asn1.BitString{
+			Bytes:     crypto.FromECDSAPub(&f.key.PublicKey),
+			BitLength: 65 * 8,
+		},
+	})
+}
+
+func (f *fakeBackend) SignDigest(_ context.Context, digest []byte) ([]byte, error) {
+	rsv, err := crypto.Sign(digest, f.key)
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).SetBytes(rsv[:32])
+	s := new(big.Int).SetBytes(rsv[32:64])
+	if f.highS {
+		s.Sub(secp256k1N, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
+
+func TestSigner(t *testing.T) {
+	for _, highS := range []bool{false, true} {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("crypto.GenerateKey() error %v", err)
+		}
+		want := crypto.PubkeyToAddress(key.PublicKey)
+
+		s, err := New(context.Background(), &fakeBackend{key: key, highS: highS})
+		if err != nil {
+			t.Fatalf("New() error %v", err)
+		}
+		if got := s.Address(); got != want {
+			t.Errorf("New().Address() got %v; want %v", got, want)
+		}
+
+		msg := []byte("hello")
+		sig, err := s.Sign(msg)
+		if err != nil {
+			t.Fatalf("%T.Sign() error %v", s, err)
+		}
+		if sv := new(big.Int).SetBytes(sig[32:64]); sv.Cmp(secp256k1HalfN) == 1 {
+			t.Errorf("%T.Sign() with high-s KMS signature returned S = %d > N/2", s, sv)
+		}
+		if got, err := eth.RecoverAddress(crypto.Keccak256(msg), sig); err != nil || got != want {
+			t.Errorf("eth.RecoverAddress(…, %T.Sign()) got %v, err = %v; want %v, nil err", s, got, err, want)
+		}
+
+		personal, err := s.PersonalSign(msg)
+		if err != nil {
+			t.Fatalf("%T.PersonalSign() error %v", s, err)
+		}
+		if got, err := eth.RecoverPersonalAddress(msg, personal); err != nil || got != want {
+			t.Errorf("eth.RecoverPersonalAddress(…, %T.PersonalSign()) got %v, err = %v; want %v, nil err", s, got, err, want)
+		}
+
+		chainID := big.NewInt(1337)
+		to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+		tx, err := s.SignTx(types.NewTx(&types.DynamicFeeTx{ChainID: chainID, To: &to, Gas: 21000}), chainID)
+		if err != nil {
+			t.Fatalf("%T.SignTx() error %v", s, err)
+		}
+		if got, err := types.Sender(types.LatestSignerForChainID(chainID), tx); err != nil || got != want {
+			t.Errorf("types.Sender(%T.SignTx()) got %v, err = %v; want %v, nil err", s, got, err, want)
+		}
+	}
+}
+
+func TestToRSVRejectsOversizedValues(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("crypto.GenerateKey() error %v", err)
+	}
+	s, err := New(context.Background(), &fakeBackend{key: key})
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+
+	tooBig := new(big.Int).Lsh(common.Big1, 256)
+	for _, sig := range []struct{ R, S *big.Int }{
+		{tooBig, common.Big1},
+		{common.Big1, tooBig},
+	} {
+		der, err := asn1.Marshal(sig)
+		if err != nil {
+			t.Fatalf("asn1.Marshal(%+v) error %v", sig, err)
+		}
+		if _, err := s.toRSV(make([]byte, 32), der); err == nil {
+			t.Errorf("%T.toRSV(…, [DER of %+v]) got nil error; want error", s, sig)
+		}
+	}
+}
+
+func TestParsePublicKeyRejectsOtherCurves(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("crypto.GenerateKey() error %v", err)
+	}
+	f := &fakeBackend{key: key}
+	der, err := f.PublicKey(context.Background())
+	if err != nil {
+		t.Fatalf("%T.PublicKey() error %v", f, err)
+	}
+
+	// Replace the secp256k1 OID (1.3.132.0.10) with that of secp384r1
+	// (1.3.132.0.34), which has an identical encoded length.
+	for i := 0; i+5 <= len(der); i++ {
+		if der[i] == 0x2b && der[i+1] == 0x81 && der[i+2] == 0x04 && der[i+3] == 0x00 && der[i+4] == 0x0a {
+			der[i+4] = 0x22
+		}
+	}
+	if _, err := parsePublicKey(der); err == nil {
+		t.Errorf("parsePublicKey([non-secp256k1 key]) got nil error; want error")
+	}
+}
+
+func TestBackendHelpers(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("crypto.GenerateKey() error %v", err)
+	}
+	s, err := New(context.Background(), &fakeBackend{key: key})
+	if err != nil {
+		t.Fatalf("New() error %v", err)
+	}
+
+	v, err := eth.NewVoucher(s.Address(), big.NewInt(1), time.Now())
+	if err != nil {
+		t.Fatalf("eth.NewVoucher() error %v", err)
+	}
+	sig, err := eth.SignVoucher(s, v)
+	if err != nil {
+		t.Fatalf("eth.SignVoucher(%T, …) error %v", s, err)
+	}
+	buf, err := v.Encode()
+	if err != nil {
+		t.Fatalf("%T.Encode() error %v", v, err)
+	}
+	if got, err := eth.RecoverPersonalAddress(buf, sig); err != nil || got != s.Address() {
+		t.Errorf("eth.RecoverPersonalAddress(<voucher>, eth.SignVoucher(%T, …)) got %v, err = %v; want %v, nil err", s, got, err, s.Address())
+	}
+
+	token := eth.PermitToken{Name: "Token", Version: "1", ChainID: big.NewInt(1)}
+	if _, _, _, err := eth.SignPermit(s, token, s.Address(), common.Address{}, big.NewInt(1), big.NewInt(0), big.NewInt(1)); err != nil {
+		t.Errorf("eth.SignPermit(%T, …) error %v", s, err)
+	}
+}
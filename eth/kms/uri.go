@@ -0,0 +1,46 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// FromURI returns a Signer for the KMS key identified by the URI, allowing the
+// provider to be selected from configuration or the command line. Supported
+// schemes are:
+//
+//  aws://<key ID, ARN, or alias>
+//  gcp://projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>[/cryptoKeyVersions/<v>]
+//
+// AWS credentials are read with AWSFromEnv(), and GCP access tokens with
+// GCPTokenFromEnv(). If a GCP key version is not specified, version 1 is used.
+func FromURI(ctx context.Context, uri string) (*Signer, error) {
+	parts := strings.SplitN(uri, "://", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("invalid KMS URI %q; expecting <provider>://<key>", uri)
+	}
+	scheme, id := parts[0], parts[1]
+
+	var b Backend
+	switch scheme {
+	case "aws":
+		aws, err := AWSFromEnv(id)
+		if err != nil {
+			return nil, err
+		}
+		b = aws
+	case "gcp":
+		if !strings.HasPrefix(id, "projects/") || !strings.Contains(id, "/cryptoKeys/") {
+			return nil, fmt.Errorf("invalid GCP KMS key %q; expecting projects/…/cryptoKeys/…", id)
+		}
+		if !strings.Contains(id, "/cryptoKeyVersions/") {
+			id += "/cryptoKeyVersions/1"
+		}
+		b = &GCP{KeyVersion: id}
+	default:
+		return nil, fmt.Errorf("unsupported KMS provider %q in URI %q", scheme, uri)
+	}
+
+	return New(ctx, b)
+}
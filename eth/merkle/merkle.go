@@ -0,0 +1,185 @@
+// Package merkle builds Merkle trees that are compatible with OpenZeppelin's
+// MerkleProof library, for use as an alternative to signature-based
+// allowlists.
+//
+// Internal nodes are the keccak256 hash of their two children concatenated in
+// sorted order, which is the convention expected by MerkleProof.verify(). A
+// node without a sibling is promoted unchanged to the next level.
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// A Tree is an immutable Merkle tree over a set of leaves.
+type Tree struct {
+	// levels[0] holds the leaves and the final level holds only the root.
+	levels [][]common.Hash
+	// index maps each leaf to its first position in levels[0].
+	index map[common.Hash]int
+}
+
+// New returns a Tree over the leaves, which are used as-is without further
+// hashing. The order of leaves is preserved, and is reflected in proofs, but
+// doesn't otherwise affect verification. At least one leaf is required.
+func New(leaves []common.Hash) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("merkle tree requires at least one leaf")
+	}
+
+	t := &Tree{
+		levels: [][]common.Hash{append([]common.Hash(nil), leaves...)},
+		index:  make(map[common.Hash]int),
+	}
+	for i, l := range leaves {
+		if _, ok := t.index[l]; !ok {
+			t.index[l] = i
+		}
+	}
+
+	for level := t.levels[0]; len(level) > 1; {
+		next := make([]common.Hash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, HashPair(level[i], level[i+1]))
+		}
+		t.levels = append(t.levels, next)
+		level = next
+	}
+	return t, nil
+}
+
+// FromData returns a Tree with LeafHash(d) as the leaf for each d in data. This
+// allows for leaves of arbitrary ABI-encoded data, for example as produced by
+// eth.EncodePacked().
+func FromData(data [][]byte) (*Tree, error) {
+	leaves := make([]common.Hash, len(data))
+	for i, d := range data {
+		leaves[i] = LeafHash(d)
+	}
+	return New(leaves)
+}
+
+// FromAddresses returns a Tree with AddressLeaf(a) as the leaf for each a in
+// addrs.
+func FromAddresses(addrs []common.Address) (*Tree, error) {
+	leaves := make([]common.Hash, len(addrs))
+	for i, a := range addrs {
+		leaves[i] = AddressLeaf(a)
+	}
+	return New(leaves)
+}
+
+// An Allowance is an address's entitlement to claim up to Amount items from a
+// contract inheriting ethier's MerkleAllowlist.
+type Allowance struct {
+	Address common.Address
+	Amount  *big.Int
+}
+
+// FromAllowances returns a Tree with AllowanceLeaf(a.Address, a.Amount) as the
+// leaf for each a in allowances. The root is suitable for
+// MerkleAllowlist.setMerkleRoot().
+func FromAllowances(allowances []Allowance) (*Tree, error) {
+	leaves := make([]common.Hash, len(allowances))
+	for i, a := range allowances {
+		if a.Amount == nil || a.Amount.Sign() < 0 || a.Amount.BitLen() > 256 {
+			return nil, fmt.Errorf("allowance %d for %v: amount %v not a uint256", i, a.Address, a.Amount)
+		}
+		leaves[i] = AllowanceLeaf(a.Address, a.Amount)
+	}
+	return New(leaves)
+}
+
+// LeafHash returns keccak256(data).
+func LeafHash(data []byte) common.Hash {
+	return crypto.Keccak256Hash(data)
+}
+
+// AddressLeaf returns keccak256(abi.encodePacked(addr)), the conventional leaf
+// for address allowlists.
+func AddressLeaf(addr common.Address) common.Hash {
+	return LeafHash(addr.Bytes())
+}
+
+// AllowanceLeaf returns keccak256(abi.encodePacked(addr, amount)), with amount
+// as a uint256, which is identical to MerkleAllowlist.allowlistLeaf(). The
+// amount MUST be a valid uint256.
+func AllowanceLeaf(addr common.Address, amount *big.Int) common.Hash {
+	return crypto.Keccak256Hash(addr.Bytes(), math.U256Bytes(new(big.Int).Set(amount)))
+}
+
+// HashPair returns the keccak256 hash of the concatenation of a and b, in
+// sorted order, as used by OpenZeppelin's MerkleProof.
+func HashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a.Bytes(), b.Bytes()) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a.Bytes(), b.Bytes())
+}
+
+// Root returns the root of the tree.
+func (t *Tree) Root() common.Hash {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Leaves returns a copy of the tree's leaves, in the order in which they were
+// provided.
+func (t *Tree) Leaves() []common.Hash {
+	return append([]common.Hash(nil), t.levels[0]...)
+}
+
+// Proof returns the proof for the i'th leaf.
+func (t *Tree) Proof(i int) ([]common.Hash, error) {
+	if n := len(t.levels[0]); i < 0 || i >= n {
+		return nil, fmt.Errorf("leaf index %d out of range for %d leaves", i, n)
+	}
+
+	var proof []common.Hash
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if sib := i ^ 1; sib < len(level) {
+			proof = append(proof, level[sib])
+		}
+		i /= 2
+	}
+	return proof, nil
+}
+
+// ProofFor returns the proof for the leaf, which MUST be in the tree. If the
+// leaf appears more than once, the proof for its first occurrence is returned.
+func (t *Tree) ProofFor(leaf common.Hash) ([]common.Hash, error) {
+	i, ok := t.index[leaf]
+	if !ok {
+		return nil, fmt.Errorf("leaf %v not in tree", leaf)
+	}
+	return t.Proof(i)
+}
+
+// ProofForAddress is equivalent to t.ProofFor(AddressLeaf(addr)).
+func (t *Tree) ProofForAddress(addr common.Address) ([]common.Hash, error) {
+	return t.ProofFor(AddressLeaf(addr))
+}
+
+// ProofForAllowance is equivalent to t.ProofFor(AllowanceLeaf(a.Address,
+// a.Amount)).
+func (t *Tree) ProofForAllowance(a Allowance) ([]common.Hash, error) {
+	return t.ProofFor(AllowanceLeaf(a.Address, a.Amount))
+}
+
+// Verify reports whether proof demonstrates that leaf is in the tree with the
+// specified root. It is equivalent to OpenZeppelin's MerkleProof.verify().
+func Verify(root, leaf common.Hash, proof []common.Hash) bool {
+	for _, p := range proof {
+		leaf = HashPair(leaf, p)
+	}
+	return leaf == root
+}
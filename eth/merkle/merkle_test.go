@@ -0,0 +1,139 @@
+package merkle
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func addrs(n int) []common.Address {
+	a := make([]common.Address, n)
+	for i := range a {
+		a[i] = common.BytesToAddress(crypto.Keccak256([]byte{byte(i)}))
+	}
+	return a
+}
+
+func TestProofs(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		all := addrs(n)
+		tree, err := FromAddresses(all)
+		if err != nil {
+			t.Fatalf("FromAddresses([%d addresses]) error %v", n, err)
+		}
+		root := tree.Root()
+
+		for i, a := range all {
+			proof, err := tree.ProofForAddress(a)
+			if err != nil {
+				t.Fatalf("[%d leaves] ProofForAddress(%v) error %v", n, a, err)
+			}
+			if !Verify(root, AddressLeaf(a), proof) {
+				t.Errorf("[%d leaves] Verify(root, AddressLeaf(%v), [proof of leaf %d]) got false; want true", n, a, i)
+			}
+			if n > 1 && Verify(root, AddressLeaf(a), nil) {
+				t.Errorf("[%d leaves] Verify(root, AddressLeaf(%v), nil) got true; want false", n, a)
+			}
+		}
+
+		outsider := common.HexToAddress("0xdeadbeef")
+		if _, err := tree.ProofForAddress(outsider); err == nil {
+			t.Errorf("[%d leaves] ProofForAddress([not in tree]) got nil error; want error", n)
+		}
+	}
+}
+
+func TestRoot(t *testing.T) {
+	a, b, c := common.Hash{1}, common.Hash{2}, common.Hash{3}
+
+	tests := []struct {
+		name   string
+		leaves []common.Hash
+		want   common.Hash
+	}{
+		{
+			name:   "single leaf",
+			leaves: []common.Hash{a},
+			want:   a,
+		},
+		{
+			name:   "pair in order",
+			leaves: []common.Hash{a, b},
+			want:   crypto.Keccak256Hash(a[:], b[:]),
+		},
+		{
+			name:   "pair sorted",
+			leaves: []common.Hash{b, a},
+			want:   crypto.Keccak256Hash(a[:], b[:]),
+		},
+		{
+			name:   "odd leaf promoted",
+			leaves: []common.Hash{a, b, c},
+			want:   HashPair(HashPair(a, b), c),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := New(tt.leaves)
+			if err != nil {
+				t.Fatalf("New(%v) error %v", tt.leaves, err)
+			}
+			if got := tree.Root(); got != tt.want {
+				t.Errorf("New(%v).Root() got %v; want %v", tt.leaves, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := New(nil); err == nil {
+		t.Errorf("New(nil) got nil error; want error")
+	}
+}
+
+func TestAllowances(t *testing.T) {
+	var allowances []Allowance
+	for i, a := range addrs(9) {
+		allowances = append(allowances, Allowance{
+			Address: a,
+			Amount:  big.NewInt(int64(i + 1)),
+		})
+	}
+
+	tree, err := FromAllowances(allowances)
+	if err != nil {
+		t.Fatalf("FromAllowances() error %v", err)
+	}
+
+	for _, a := range allowances {
+		packed, err := eth.EncodePacked([]string{"address", "uint256"}, a.Address, a.Amount)
+		if err != nil {
+			t.Fatalf("eth.EncodePacked(%v, %d) error %v", a.Address, a.Amount, err)
+		}
+		leaf := AllowanceLeaf(a.Address, a.Amount)
+		if want := LeafHash(packed); leaf != want {
+			t.Errorf("AllowanceLeaf(%v, %d) got %v; want keccak256(abi.encodePacked(…)) = %v", a.Address, a.Amount, leaf, want)
+		}
+
+		proof, err := tree.ProofForAllowance(a)
+		if err != nil {
+			t.Fatalf("ProofForAllowance(%+v) error %v", a, err)
+		}
+		if !Verify(tree.Root(), leaf, proof) {
+			t.Errorf("Verify(root, AllowanceLeaf(%v, %d), [proof]) got false; want true", a.Address, a.Amount)
+		}
+
+		inflated := Allowance{Address: a.Address, Amount: new(big.Int).Add(a.Amount, big.NewInt(1))}
+		if Verify(tree.Root(), AllowanceLeaf(inflated.Address, inflated.Amount), proof) {
+			t.Errorf("Verify(root, AllowanceLeaf(%v, %d), [proof of allowance %d]) got true; want false", a.Address, inflated.Amount, a.Amount)
+		}
+	}
+
+	for _, amount := range []*big.Int{nil, big.NewInt(-1), new(big.Int).Lsh(big.NewInt(1), 256)} {
+		if _, err := FromAllowances([]Allowance{{Amount: amount}}); err == nil {
+			t.Errorf("FromAllowances([amount %v]) got nil error; want error", amount)
+		}
+	}
+}
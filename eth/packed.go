@@ -0,0 +1,162 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// EncodePacked returns the non-standard packed encoding of the values, as
+// described by the respective Solidity types, mirroring Solidity's
+// abi.encodePacked(). Supported types are address, bool, string, bytes,
+// bytes<N>, (u)int<N>, and arrays thereof; integers MAY be provided as any Go
+// integer type or as a *big.Int.
+func EncodePacked(types []string, values ...interface{}) ([]byte, error) {
+	if n, m := len(types), len(values); n != m {
+		return nil, fmt.Errorf("%d types for %d values", n, m)
+	}
+
+	var buf []byte
+	for i, t := range types {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("parse type %q: %v", t, err)
+		}
+		b, err := encodePacked(typ, values[i], false)
+		if err != nil {
+			return nil, fmt.Errorf("value %d (%s): %v", i, t, err)
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+// encodePacked encodes a single value of the specified type. Array elements
+// are padded to 32 bytes, as is done by Solidity, so inArray is propagated to
+// the encoding of individual elements.
+func encodePacked(typ abi.Type, val interface{}, inArray bool) ([]byte, error) {
+	switch typ.T {
+	case abi.SliceTy, abi.ArrayTy:
+		rv := reflect.ValueOf(val)
+		if k := rv.Kind(); k != reflect.Slice && k != reflect.Array {
+			return nil, fmt.Errorf("%T is not a slice or array", val)
+		}
+		if typ.T == abi.ArrayTy && rv.Len() != typ.Size {
+			return nil, fmt.Errorf("array of length %d; expecting %d", rv.Len(), typ.Size)
+		}
+		var buf []byte
+		for i, n := 0, rv.Len(); i < n; i++ {
+			b, err := encodePacked(*typ.Elem, rv.Index(i).Interface(), true)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", i, err)
+			}
+			buf = append(buf, b...)
+		}
+		return buf, nil
+
+	case abi.AddressTy:
+		a, ok := val.(common.Address)
+		if !ok {
+			return nil, fmt.Errorf("%T is not a common.Address", val)
+		}
+		return pad(a.Bytes(), inArray), nil
+
+	case abi.BoolTy:
+		b, ok := val.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%T is not a bool", val)
+		}
+		if b {
+			return pad([]byte{1}, inArray), nil
+		}
+		return pad([]byte{0}, inArray), nil
+
+	case abi.StringTy:
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("%T is not a string", val)
+		}
+		return []byte(s), nil
+
+	case abi.BytesTy:
+		b, ok := val.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("%T is not a []byte", val)
+		}
+		return b, nil
+
+	case abi.FixedBytesTy:
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.Array || rv.Type().Elem().Kind() != reflect.Uint8 || rv.Len() != typ.Size {
+			return nil, fmt.Errorf("%T is not a [%d]byte", val, typ.Size)
+		}
+		b := make([]byte, typ.Size)
+		reflect.Copy(reflect.ValueOf(b), rv)
+		if inArray {
+			return common.RightPadBytes(b, 32), nil
+		}
+		return b, nil
+
+	case abi.IntTy, abi.UintTy:
+		x, err := asBigInt(val)
+		if err != nil {
+			return nil, err
+		}
+		switch typ.T {
+		case abi.UintTy:
+			if x.Sign() == -1 {
+				return nil, fmt.Errorf("negative value %d for unsigned type", x)
+			}
+			if x.BitLen() > typ.Size {
+				return nil, fmt.Errorf("%d out of range for %s", x, typ)
+			}
+		case abi.IntTy:
+			// The range of int<N> is [-2^(N-1), 2^(N-1)).
+			max := new(big.Int).Lsh(big.NewInt(1), uint(typ.Size-1))
+			min := new(big.Int).Neg(max)
+			if x.Cmp(min) == -1 || x.Cmp(max) != -1 {
+				return nil, fmt.Errorf("%d out of range for %s", x, typ)
+			}
+		}
+		// U256Bytes() modifies its argument so we use a copy; the result is
+		// two's complement, from which we take the required low-order bytes.
+		word := math.U256Bytes(new(big.Int).Set(x))
+		if inArray {
+			return word, nil
+		}
+		return word[32-typ.Size/8:], nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", typ)
+}
+
+// pad left-pads b to 32 bytes iff inArray is true.
+func pad(b []byte, inArray bool) []byte {
+	if !inArray {
+		return b
+	}
+	return common.LeftPadBytes(b, 32)
+}
+
+// asBigInt converts any Go integer type, or a *big.Int, to a *big.Int.
+func asBigInt(val interface{}) (*big.Int, error) {
+	if x, ok := val.(*big.Int); ok {
+		if x == nil {
+			return nil, fmt.Errorf("nil %T", x)
+		}
+		return x, nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	}
+	return nil, fmt.Errorf("%T is not an integer", val)
+}
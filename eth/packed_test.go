@@ -0,0 +1,160 @@
+package eth_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestEncodePacked(t *testing.T) {
+	addr := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	tests := []struct {
+		name    string
+		types   []string
+		values  []interface{}
+		wantHex string
+		wantErr bool
+	}{
+		{
+			name:    "address",
+			types:   []string{"address"},
+			values:  []interface{}{addr},
+			wantHex: "000000000000000000000000000000000000dead",
+		},
+		{
+			name:    "small integers",
+			types:   []string{"uint8", "uint16", "int16"},
+			values:  []interface{}{1, uint16(0x1234), -1},
+			wantHex: "01" + "1234" + "ffff",
+		},
+		{
+			name:    "uint256 as big.Int",
+			types:   []string{"uint256"},
+			values:  []interface{}{big.NewInt(42)},
+			wantHex: strings.Repeat("00", 31) + "2a",
+		},
+		{
+			name:    "string, bytes, and bool",
+			types:   []string{"string", "bytes", "bool"},
+			values:  []interface{}{"abc", []byte{0xde, 0xad}, true},
+			wantHex: "616263" + "dead" + "01",
+		},
+		{
+			name:    "fixed bytes",
+			types:   []string{"bytes2", "bytes32"},
+			values:  []interface{}{[2]byte{0x12, 0x34}, [32]byte{31: 1}},
+			wantHex: "1234" + strings.Repeat("00", 31) + "01",
+		},
+		{
+			name:    "array elements are padded",
+			types:   []string{"uint16[]", "address[]"},
+			values:  []interface{}{[]uint16{1, 2}, []common.Address{addr}},
+			wantHex: strings.Repeat("00", 31) + "01" + strings.Repeat("00", 31) + "02" + strings.Repeat("00", 12) + "000000000000000000000000000000000000dead",
+		},
+		{
+			name:    "mismatched lengths",
+			types:   []string{"uint8"},
+			values:  nil,
+			wantErr: true,
+		},
+		{
+			name:    "negative unsigned",
+			types:   []string{"uint8"},
+			values:  []interface{}{-1},
+			wantErr: true,
+		},
+		{
+			name:    "integer bounds",
+			types:   []string{"uint8", "int8", "int8"},
+			values:  []interface{}{255, 127, -128},
+			wantHex: "ff" + "7f" + "80",
+		},
+		{
+			name:    "unsigned overflow",
+			types:   []string{"uint8"},
+			values:  []interface{}{256},
+			wantErr: true,
+		},
+		{
+			name:    "signed overflow",
+			types:   []string{"int8"},
+			values:  []interface{}{128},
+			wantErr: true,
+		},
+		{
+			name:    "signed underflow",
+			types:   []string{"int8"},
+			values:  []interface{}{-129},
+			wantErr: true,
+		},
+		{
+			name:    "uint256 overflow",
+			types:   []string{"uint256"},
+			values:  []interface{}{new(big.Int).Lsh(big.NewInt(1), 256)},
+			wantErr: true,
+		},
+		{
+			name:    "wrong Go type",
+			types:   []string{"address"},
+			values:  []interface{}{"0xdead"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodePacked(tt.types, tt.values...)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("EncodePacked(%q, %v) got err %v; want err = %t", tt.types, tt.values, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if want, _ := hex.DecodeString(tt.wantHex); !bytes.Equal(got, want) {
+				t.Errorf("EncodePacked(%q, %v) got %x; want %s", tt.types, tt.values, got, tt.wantHex)
+			}
+		})
+	}
+}
+
+func TestSignPacked(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	addr := signer.Address()
+	sig, err := signer.SignPacked([]string{"address", "uint256"}, addr, 42)
+	if err != nil {
+		t.Fatalf("SignPacked() error %v", err)
+	}
+	if n := len(sig); n != 65 {
+		t.Fatalf("SignPacked() got signature length %d; want 65", n)
+	}
+	if v := sig[64]; v != 27 && v != 28 {
+		t.Errorf("SignPacked() got V = %d; want 27 or 28", v)
+	}
+
+	// Equivalent to Solidity's
+	// ECDSA.toEthSignedMessageHash(abi.encodePacked(addr, uint256(42))).
+	packed := append(addr.Bytes(), common.LeftPadBytes([]byte{42}, 32)...)
+	digest := crypto.Keccak256(WithPersonalMessagePrefix(packed))
+
+	rsv := append([]byte{}, sig...)
+	rsv[64] -= 27
+	pub, err := crypto.SigToPub(digest, rsv)
+	if err != nil {
+		t.Fatalf("crypto.SigToPub() error %v", err)
+	}
+	if got := crypto.PubkeyToAddress(*pub); got != addr {
+		t.Errorf("SignPacked() signature recovers to %v; want %v", got, addr)
+	}
+}
@@ -0,0 +1,67 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// A PermitToken describes the EIP-712 domain of an ERC20 token implementing
+// ERC-2612 permits. Name and Version MUST match those passed to the token's
+// EIP712 constructor; for OpenZeppelin's ERC20Permit, Version is "1".
+type PermitToken struct {
+	Name, Version string
+	ChainID       *big.Int
+	Address       common.Address
+}
+
+// PermitTypedData returns the EIP-712 typed data for an ERC-2612 permit.
+func PermitTypedData(token PermitToken, owner, spender common.Address, value, nonce, deadline *big.Int) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": EIP712DomainType,
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      EIP712Domain(token.Name, token.Version, token.ChainID, token.Address),
+		Message: apitypes.TypedDataMessage{
+			"owner":    owner.Hex(),
+			"spender":  spender.Hex(),
+			"value":    (*math.HexOrDecimal256)(value),
+			"nonce":    (*math.HexOrDecimal256)(nonce),
+			"deadline": (*math.HexOrDecimal256)(deadline),
+		},
+	}
+}
+
+// SignPermit signs an ERC-2612 permit, allowing the spender to transfer up to
+// value of the owner's tokens, and returns the signature in the (v,r,s) form
+// accepted by the token's permit() function. The owner MUST be the Signer's
+// address, and nonce MUST equal the token's current nonces(owner) value.
+func SignPermit(s SignerBackend, token PermitToken, owner, spender common.Address, value, nonce, deadline *big.Int) (v uint8, r, ss [32]byte, err error) {
+	if owner != s.Address() {
+		return 0, r, ss, fmt.Errorf("permit owner %v is not signer %v", owner, s.Address())
+	}
+
+	sig, err := s.SignTypedData(PermitTypedData(token, owner, spender, value, nonce, deadline))
+	if err != nil {
+		return 0, r, ss, fmt.Errorf("sign permit: %v", err)
+	}
+	// The Signer may be in compact mode but permit() requires all of v, r, and
+	// s.
+	if sig, err = canonicalSignature(sig); err != nil {
+		return 0, r, ss, err
+	}
+	copy(r[:], sig[:32])
+	copy(ss[:], sig[32:64])
+	return sig[64] + 27, r, ss, nil
+}
@@ -0,0 +1,85 @@
+package eth_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestSignPermit(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	token := PermitToken{
+		Name:    "Token",
+		Version: "1",
+		ChainID: big.NewInt(1337),
+		Address: common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3"),
+	}
+	owner := signer.Address()
+	spender := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	value := Ether(42)
+	nonce := big.NewInt(7)
+	deadline := big.NewInt(1 << 40)
+
+	v, r, s, err := SignPermit(signer, token, owner, spender, value, nonce, deadline)
+	if err != nil {
+		t.Fatalf("SignPermit() error %v", err)
+	}
+	if v != 27 && v != 28 {
+		t.Errorf("SignPermit() got v = %d; want 27 or 28", v)
+	}
+
+	// Independently compute the digest as OpenZeppelin's ERC20Permit does, to
+	// confirm that the typed-data encoding is correct.
+	word := func(x *big.Int) []byte {
+		return common.LeftPadBytes(x.Bytes(), 32)
+	}
+	addr := func(a common.Address) []byte {
+		return common.LeftPadBytes(a.Bytes(), 32)
+	}
+	domainSep := crypto.Keccak256(
+		crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")),
+		crypto.Keccak256([]byte(token.Name)),
+		crypto.Keccak256([]byte(token.Version)),
+		word(token.ChainID),
+		addr(token.Address),
+	)
+	structHash := crypto.Keccak256(
+		crypto.Keccak256([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)")),
+		addr(owner),
+		addr(spender),
+		word(value),
+		word(nonce),
+		word(deadline),
+	)
+	digest := crypto.Keccak256([]byte{0x19, 0x01}, domainSep, structHash)
+
+	got, err := TypedDataDigest(PermitTypedData(token, owner, spender, value, nonce, deadline))
+	if err != nil {
+		t.Fatalf("TypedDataDigest(PermitTypedData(…)) error %v", err)
+	}
+	if !bytes.Equal(got, digest) {
+		t.Errorf("TypedDataDigest(PermitTypedData(…)) got %#x; want %#x", got, digest)
+	}
+
+	sig := append(append(r[:], s[:]...), v-27)
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		t.Fatalf("crypto.SigToPub(<permit digest>, <permit signature>) error %v", err)
+	}
+	if got, want := crypto.PubkeyToAddress(*pub), owner; got != want {
+		t.Errorf("SignPermit() signature recovers to %v; want %v", got, want)
+	}
+
+	if _, _, _, err := SignPermit(signer, token, spender, spender, value, nonce, deadline); err == nil {
+		t.Errorf("SignPermit() with owner != signer got nil error; want error")
+	}
+}
@@ -0,0 +1,272 @@
+// Package remote provides an Ethereum signer that delegates to an external
+// signing service over JSON-RPC, such as clef or Web3Signer, allowing ethier
+// tooling to be used with centrally managed keys.
+//
+// Such services deliberately refuse to sign arbitrary digests, so the Signer
+// implements eth.PersonalMessageSigner and eth.TransactionSigner, which are
+// used by the eth package's backend-generic helpers; e.g. eth.SignVoucher()
+// and eth.TransactOpts(). Its SignRawDigest() method always returns
+// ErrDigestUnsupported.
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// A Protocol is the JSON-RPC dialect spoken by a signing service.
+type Protocol int
+
+const (
+	// Clef is go-ethereum's external signer, using the account_* methods.
+	Clef Protocol = iota
+	// Web3Signer is Consensys' signing service, using its eth1 eth_* methods.
+	Web3Signer
+)
+
+// String returns the Protocol's name.
+func (p Protocol) String() string {
+	switch p {
+	case Clef:
+		return "clef"
+	case Web3Signer:
+		return "web3signer"
+	default:
+		return fmt.Sprintf("Protocol(%d)", int(p))
+	}
+}
+
+// methods returns the JSON-RPC method names for the Protocol.
+func (p Protocol) methods() (accounts, personal, typedData, tx string, err error) {
+	switch p {
+	case Clef:
+		return "account_list", "account_signData", "account_signTypedData", "account_signTransaction", nil
+	case Web3Signer:
+		return "eth_accounts", "eth_sign", "eth_signTypedData", "eth_signTransaction", nil
+	default:
+		return "", "", "", "", fmt.Errorf("unsupported %v", p)
+	}
+}
+
+// ErrDigestUnsupported is returned by Signer.SignRawDigest().
+var ErrDigestUnsupported = errors.New("remote signers don't sign raw digests")
+
+// DefaultTimeout is the default value of Signer.Timeout.
+const DefaultTimeout = 2 * time.Minute
+
+// A Signer signs messages and transactions for a single account held by a
+// remote signing service.
+type Signer struct {
+	client *rpc.Client
+	proto  Protocol
+	addr   common.Address
+
+	personalMethod, typedDataMethod, txMethod string
+
+	// Timeout limits the duration of each request. Signing services MAY
+	// require manual approval so the default is longer than for most requests.
+	Timeout time.Duration
+}
+
+var (
+	_ eth.PersonalMessageSigner = (*Signer)(nil)
+	_ eth.TransactionSigner     = (*Signer)(nil)
+)
+
+// Dial connects to the signing service at the URL, which MAY be an HTTP(S),
+// WebSocket, or IPC endpoint, and returns New(ctx, client, proto, addr).
+func Dial(ctx context.Context, url string, proto Protocol, addr common.Address) (*Signer, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("dial %v signer: %v", proto, err)
+	}
+	return New(ctx, client, proto, addr)
+}
+
+// New returns a Signer for the address, which MUST be one of the accounts
+// listed by the signing service.
+func New(ctx context.Context, client *rpc.Client, proto Protocol, addr common.Address) (*Signer, error) {
+	accounts, personal, typedData, tx, err := proto.methods()
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []common.Address
+	if err := client.CallContext(ctx, &addrs, accounts); err != nil {
+		return nil, fmt.Errorf("%s: %v", accounts, err)
+	}
+	found := false
+	for _, a := range addrs {
+		if a == addr {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%v signer doesn't hold account %v", proto, addr)
+	}
+
+	return &Signer{
+		client:          client,
+		proto:           proto,
+		addr:            addr,
+		personalMethod:  personal,
+		typedDataMethod: typedData,
+		txMethod:        tx,
+		Timeout:         DefaultTimeout,
+	}, nil
+}
+
+// String returns s.Address() as a string.
+func (s *Signer) String() string {
+	return s.Address().String()
+}
+
+// Address returns the address of the remote account.
+func (s *Signer) Address() common.Address {
+	return s.addr
+}
+
+// call performs a JSON-RPC request with s.Timeout.
+func (s *Signer) call(result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	if err := s.client.CallContext(ctx, result, method, args...); err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	return nil
+}
+
+// SignRawDigest always returns ErrDigestUnsupported.
+func (s *Signer) SignRawDigest([]byte) ([]byte, error) {
+	return nil, ErrDigestUnsupported
+}
+
+// SignPersonalMessage returns the remote signer's EIP-191 personal signature of
+// buf, normalised with eth.NormalizeSignature().
+func (s *Signer) SignPersonalMessage(buf []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	var err error
+	switch s.proto {
+	case Clef:
+		err = s.call(&sig, s.personalMethod, "text/plain", s.addr, hexutil.Bytes(buf))
+	default:
+		err = s.call(&sig, s.personalMethod, s.addr, hexutil.Bytes(buf))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return eth.NormalizeSignature(sig)
+}
+
+// SignTypedData returns the remote signer's EIP-712 signature of td, normalised
+// with eth.NormalizeSignature().
+func (s *Signer) SignTypedData(td apitypes.TypedData) ([]byte, error) {
+	var sig hexutil.Bytes
+	if err := s.call(&sig, s.typedDataMethod, s.addr, td); err != nil {
+		return nil, err
+	}
+	return eth.NormalizeSignature(sig)
+}
+
+// SignTransaction has the remote signer sign the transaction for the specified
+// chain, returning a signed copy.
+func (s *Signer) SignTransaction(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if chainID == nil {
+		return nil, bind.ErrNoChainID
+	}
+
+	// MixedcaseAddress only implements json.Marshaler with a pointer receiver.
+	args := TxArgs(s.addr, tx, chainID)
+	var raw hexutil.Bytes
+	switch s.proto {
+	case Clef:
+		// Clef additionally returns the decoded transaction, which is ignored.
+		var res struct {
+			Raw hexutil.Bytes `json:"raw"`
+		}
+		if err := s.call(&res, s.txMethod, &args); err != nil {
+			return nil, err
+		}
+		raw = res.Raw
+	default:
+		if err := s.call(&raw, s.txMethod, &args); err != nil {
+			return nil, err
+		}
+	}
+
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("decode signed transaction: %v", err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("recover transaction sender: %v", err)
+	}
+	if from != s.addr {
+		return nil, fmt.Errorf("transaction signed by %v; expecting %v", from, s.addr)
+	}
+	return signed, nil
+}
+
+// TxArgs returns the JSON-RPC arguments describing the transaction, as sent
+// from the address, for remote signing.
+func TxArgs(from common.Address, tx *types.Transaction, chainID *big.Int) apitypes.SendTxArgs {
+	data := hexutil.Bytes(tx.Data())
+	args := apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(from),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    &data,
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if to := tx.To(); to != nil {
+		mixed := common.NewMixedcaseAddress(*to)
+		args.To = &mixed
+	}
+
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+	if al := tx.AccessList(); tx.Type() != types.LegacyTxType {
+		args.AccessList = &al
+	}
+	return args
+}
+
+// PersonalSign returns eth.PersonalSign(s, buf).
+func (s *Signer) PersonalSign(buf []byte) ([]byte, error) {
+	return eth.PersonalSign(s, buf)
+}
+
+// SignTx returns eth.SignTx(s, tx, chainID).
+func (s *Signer) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return eth.SignTx(s, tx, chainID)
+}
+
+// TransactOptsWithChainID returns eth.TransactOpts(ctx, s, chainID).
+func (s *Signer) TransactOptsWithChainID(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error) {
+	return eth.TransactOpts(ctx, s, chainID)
+}
+
+// Close closes the underlying JSON-RPC client.
+func (s *Signer) Close() {
+	s.client.Close()
+}
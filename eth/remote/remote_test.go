@@ -0,0 +1,199 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// fakeSigner implements the signing methods common to both protocols, backed
+// by an in-memory eth.Signer.
+type fakeSigner struct {
+	s *eth.Signer
+}
+
+func (f *fakeSigner) check(addr common.Address) error {
+	if addr != f.s.Address() {
+		return errors.New("unknown account")
+	}
+	return nil
+}
+
+func (f *fakeSigner) personal(addr common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if err := f.check(addr); err != nil {
+		return nil, err
+	}
+	return f.s.EthSignMessage(data)
+}
+
+func (f *fakeSigner) SignTypedData(addr common.MixedcaseAddress, td apitypes.TypedData) (hexutil.Bytes, error) {
+	if err := f.check(addr.Address()); err != nil {
+		return nil, err
+	}
+	return f.s.SignTypedData(td)
+}
+
+func (f *fakeSigner) signTx(args apitypes.SendTxArgs) (hexutil.Bytes, error) {
+	if err := f.check(args.From.Address()); err != nil {
+		return nil, err
+	}
+	tx, err := f.s.SignTx(args.ToTransaction(), (*big.Int)(args.ChainID))
+	if err != nil {
+		return nil, err
+	}
+	return tx.MarshalBinary()
+}
+
+// clef is registered under the "account" namespace.
+type clef struct{ fakeSigner }
+
+func (c *clef) List() []common.Address {
+	return []common.Address{c.s.Address()}
+}
+
+func (c *clef) SignData(contentType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if contentType != "text/plain" {
+		return nil, errors.New("unsupported content type")
+	}
+	return c.personal(addr.Address(), data)
+}
+
+func (c *clef) SignTransaction(args apitypes.SendTxArgs) (map[string]interface{}, error) {
+	raw, err := c.signTx(args)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": raw}, nil
+}
+
+// web3Signer is registered under the "eth" namespace.
+type web3Signer struct{ fakeSigner }
+
+func (w *web3Signer) Accounts() []common.Address {
+	return []common.Address{w.s.Address()}
+}
+
+func (w *web3Signer) Sign(addr common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	return w.personal(addr, data)
+}
+
+func (w *web3Signer) SignTransaction(args apitypes.SendTxArgs) (hexutil.Bytes, error) {
+	return w.signTx(args)
+}
+
+func TestSigner(t *testing.T) {
+	key, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+	f := fakeSigner{key}
+
+	tests := []struct {
+		proto     Protocol
+		namespace string
+		service   interface{}
+	}{
+		{Clef, "account", &clef{f}},
+		{Web3Signer, "eth", &web3Signer{f}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.proto.String(), func(t *testing.T) {
+			srv := rpc.NewServer()
+			defer srv.Stop()
+			if err := srv.RegisterName(tt.namespace, tt.service); err != nil {
+				t.Fatalf("%T.RegisterName(%q) error %v", srv, tt.namespace, err)
+			}
+			client := rpc.DialInProc(srv)
+			ctx := context.Background()
+
+			if _, err := New(ctx, client, tt.proto, common.HexToAddress("0x01")); err == nil {
+				t.Errorf("New(…, [unknown address]) got nil error; want error")
+			}
+			s, err := New(ctx, client, tt.proto, key.Address())
+			if err != nil {
+				t.Fatalf("New() error %v", err)
+			}
+
+			t.Run("SignRawDigest", func(t *testing.T) {
+				if _, err := s.SignRawDigest(make([]byte, 32)); err != ErrDigestUnsupported {
+					t.Errorf("%T.SignRawDigest() got err %v; want %v", s, err, ErrDigestUnsupported)
+				}
+			})
+
+			t.Run("PersonalSign", func(t *testing.T) {
+				msg := []byte("hello")
+				sig, err := s.PersonalSign(msg)
+				if err != nil {
+					t.Fatalf("%T.PersonalSign() error %v", s, err)
+				}
+				if !key.VerifyPersonal(msg, sig) {
+					t.Errorf("%T.VerifyPersonal(msg, [remote %T.PersonalSign(msg)]) got false; want true", key, s)
+				}
+			})
+
+			t.Run("SignVoucher", func(t *testing.T) {
+				v := eth.Voucher{Value: big.NewInt(1)}
+				sig, err := eth.SignVoucher(s, v)
+				if err != nil {
+					t.Fatalf("eth.SignVoucher(%T, …) error %v", s, err)
+				}
+				buf, err := v.Encode()
+				if err != nil {
+					t.Fatalf("%T.Encode() error %v", v, err)
+				}
+				if !key.VerifyPersonal(buf, sig) {
+					t.Errorf("eth.SignVoucher(%T, …) signature not verified", s)
+				}
+			})
+
+			t.Run("SignTypedData", func(t *testing.T) {
+				td := eth.PermitTypedData(
+					eth.PermitToken{Name: "Token", Version: "1", ChainID: big.NewInt(1), Address: common.HexToAddress("0x02")},
+					key.Address(), common.HexToAddress("0x03"), big.NewInt(100), big.NewInt(0), big.NewInt(1e9),
+				)
+				sig, err := s.SignTypedData(td)
+				if err != nil {
+					t.Fatalf("%T.SignTypedData() error %v", s, err)
+				}
+				digest, err := eth.TypedDataDigest(td)
+				if err != nil {
+					t.Fatalf("eth.TypedDataDigest() error %v", err)
+				}
+				if got, err := eth.RecoverAddress(digest, sig); err != nil || got != key.Address() {
+					t.Errorf("eth.RecoverAddress(…, %T.SignTypedData()) got %v, err = %v; want %v, nil err", s, got, err, key.Address())
+				}
+			})
+
+			t.Run("SignTx", func(t *testing.T) {
+				chainID := big.NewInt(1337)
+				to := common.HexToAddress("0x04")
+				for _, tx := range []*types.Transaction{
+					types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(2)}),
+					types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 2, To: &to, Gas: 21000, GasFeeCap: big.NewInt(3), GasTipCap: big.NewInt(1), Data: []byte{42}}),
+				} {
+					signed, err := s.SignTx(tx, chainID)
+					if err != nil {
+						t.Fatalf("%T.SignTx([type %d]) error %v", s, tx.Type(), err)
+					}
+					from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+					if err != nil || from != key.Address() {
+						t.Errorf("types.Sender(%T.SignTx([type %d])) got %v, err = %v; want %v, nil err", s, tx.Type(), from, err, key.Address())
+					}
+					if signed.Nonce() != tx.Nonce() || signed.Type() != tx.Type() {
+						t.Errorf("%T.SignTx() got nonce %d, type %d; want %d, %d", s, signed.Nonce(), signed.Type(), tx.Nonce(), tx.Type())
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,332 @@
+// Package seaport constructs and signs orders for the Seaport marketplace
+// protocol, allowing listing and fulfillment flows for ethier-based collections
+// to be tested and automated from Go.
+package seaport
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// Canonical Seaport deployment, identical on all chains that it supports.
+const (
+	Version = "1.1"
+	Name    = "Seaport"
+)
+
+var (
+	// Address is the canonical address of Seaport 1.1.
+	Address = common.HexToAddress("0x00000000006c3852cbEf3e08E8dF289169EdE581")
+	// ConduitControllerAddress is the canonical address of Seaport's conduit
+	// controller.
+	ConduitControllerAddress = common.HexToAddress("0x00000000F9490004C11Cef243f5400493c00Ad63")
+	// OpenSeaConduitKey is the conduit key used by OpenSea listings; the zero
+	// key causes Seaport itself to transfer tokens.
+	OpenSeaConduitKey = common.HexToHash("0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000")
+)
+
+// An ItemType is the type of token in an OfferItem or ConsiderationItem.
+type ItemType uint8
+
+// ItemTypes, in the same order as the Solidity enum.
+const (
+	Native ItemType = iota
+	ERC20
+	ERC721
+	ERC1155
+	ERC721WithCriteria
+	ERC1155WithCriteria
+)
+
+// An OrderType determines whether an order may be partially filled, and
+// whether it is restricted to fulfillment via its zone.
+type OrderType uint8
+
+// OrderTypes, in the same order as the Solidity enum.
+const (
+	FullOpen OrderType = iota
+	PartialOpen
+	FullRestricted
+	PartialRestricted
+)
+
+// An OfferItem is offered by an order's offerer.
+type OfferItem struct {
+	ItemType             ItemType
+	Token                common.Address
+	IdentifierOrCriteria *big.Int
+	StartAmount          *big.Int
+	EndAmount            *big.Int
+}
+
+// A ConsiderationItem is received by its recipient when an order is fulfilled.
+type ConsiderationItem struct {
+	ItemType             ItemType
+	Token                common.Address
+	IdentifierOrCriteria *big.Int
+	StartAmount          *big.Int
+	EndAmount            *big.Int
+	Recipient            common.Address
+}
+
+// OrderComponents are the signed fields of an order. Counter MUST equal the
+// offerer's current value of Seaport's getCounter().
+type OrderComponents struct {
+	Offerer       common.Address
+	Zone          common.Address
+	Offer         []OfferItem
+	Consideration []ConsiderationItem
+	OrderType     OrderType
+	StartTime     *big.Int
+	EndTime       *big.Int
+	ZoneHash      [32]byte
+	Salt          *big.Int
+	ConduitKey    [32]byte
+	Counter       *big.Int
+}
+
+// Types are the EIP-712 type definitions of an order.
+var Types = apitypes.Types{
+	"EIP712Domain": eth.EIP712DomainType,
+	"OrderComponents": {
+		{Name: "offerer", Type: "address"},
+		{Name: "zone", Type: "address"},
+		{Name: "offer", Type: "OfferItem[]"},
+		{Name: "consideration", Type: "ConsiderationItem[]"},
+		{Name: "orderType", Type: "uint8"},
+		{Name: "startTime", Type: "uint256"},
+		{Name: "endTime", Type: "uint256"},
+		{Name: "zoneHash", Type: "bytes32"},
+		{Name: "salt", Type: "uint256"},
+		{Name: "conduitKey", Type: "bytes32"},
+		{Name: "counter", Type: "uint256"},
+	},
+	"OfferItem": {
+		{Name: "itemType", Type: "uint8"},
+		{Name: "token", Type: "address"},
+		{Name: "identifierOrCriteria", Type: "uint256"},
+		{Name: "startAmount", Type: "uint256"},
+		{Name: "endAmount", Type: "uint256"},
+	},
+	"ConsiderationItem": {
+		{Name: "itemType", Type: "uint8"},
+		{Name: "token", Type: "address"},
+		{Name: "identifierOrCriteria", Type: "uint256"},
+		{Name: "startAmount", Type: "uint256"},
+		{Name: "endAmount", Type: "uint256"},
+		{Name: "recipient", Type: "address"},
+	},
+}
+
+// Domain returns the EIP-712 domain of the Seaport contract at the address, on
+// the specified chain.
+func Domain(chainID *big.Int, seaport common.Address) apitypes.TypedDataDomain {
+	return eth.EIP712Domain(Name, Version, chainID, seaport)
+}
+
+// TypedData returns the EIP-712 typed data of the order, for signing against
+// the Seaport contract at the address.
+func (o OrderComponents) TypedData(chainID *big.Int, seaport common.Address) (apitypes.TypedData, error) {
+	msg, err := o.message()
+	if err != nil {
+		return apitypes.TypedData{}, err
+	}
+	return apitypes.TypedData{
+		Types:       Types,
+		PrimaryType: "OrderComponents",
+		Domain:      Domain(chainID, seaport),
+		Message:     msg,
+	}, nil
+}
+
+// Sign returns the backend's EIP-712 signature of the order, for fulfillment
+// via the Seaport contract at the address. Seaport accepts signatures in both
+// 65-byte and 64-byte EIP-2098 form.
+func (o OrderComponents) Sign(b eth.SignerBackend, chainID *big.Int, seaport common.Address) ([]byte, error) {
+	if o.Offerer != b.Address() {
+		return nil, fmt.Errorf("order offerer %v is not signer %v", o.Offerer, b.Address())
+	}
+	td, err := o.TypedData(chainID, seaport)
+	if err != nil {
+		return nil, err
+	}
+	return b.SignTypedData(td)
+}
+
+// Parameters returns the order's fulfillment parameters, which differ from its
+// components only by replacing the counter with the number of consideration
+// items.
+func (o OrderComponents) Parameters() OrderParameters {
+	return OrderParameters{
+		Offerer:                         o.Offerer,
+		Zone:                            o.Zone,
+		Offer:                           o.Offer,
+		Consideration:                   o.Consideration,
+		OrderType:                       o.OrderType,
+		StartTime:                       o.StartTime,
+		EndTime:                         o.EndTime,
+		ZoneHash:                        o.ZoneHash,
+		Salt:                            o.Salt,
+		ConduitKey:                      o.ConduitKey,
+		TotalOriginalConsiderationItems: big.NewInt(int64(len(o.Consideration))),
+	}
+}
+
+// OrderParameters mirror the Seaport struct of the same name, passed to its
+// fulfillment functions.
+type OrderParameters struct {
+	Offerer                         common.Address
+	Zone                            common.Address
+	Offer                           []OfferItem
+	Consideration                   []ConsiderationItem
+	OrderType                       OrderType
+	StartTime                       *big.Int
+	EndTime                         *big.Int
+	ZoneHash                        [32]byte
+	Salt                            *big.Int
+	ConduitKey                      [32]byte
+	TotalOriginalConsiderationItems *big.Int
+}
+
+// An Order is a signed order, as passed to Seaport's fulfillOrder().
+type Order struct {
+	Parameters OrderParameters
+	Signature  []byte
+}
+
+// A Fee is paid from the sale price of a listing.
+type Fee struct {
+	Recipient   common.Address
+	BasisPoints int64
+}
+
+// NewListing returns the components of an order offering a single ERC721 token
+// for a fixed price in the chain's native token, valid between start and end.
+// Fees are deducted from the price and the remainder is received by the
+// offerer. The order uses a random salt and no conduit.
+func NewListing(offerer, token common.Address, tokenID, price *big.Int, fees []Fee, start, end time.Time, counter *big.Int) (OrderComponents, error) {
+	salt, err := randomSalt()
+	if err != nil {
+		return OrderComponents{}, err
+	}
+
+	proceeds := new(big.Int).Set(price)
+	var consideration []ConsiderationItem
+	for _, f := range fees {
+		amt := new(big.Int).Mul(price, big.NewInt(f.BasisPoints))
+		amt.Div(amt, big.NewInt(10000))
+		proceeds.Sub(proceeds, amt)
+		consideration = append(consideration, nativeItem(amt, f.Recipient))
+	}
+	if proceeds.Sign() < 0 {
+		return OrderComponents{}, fmt.Errorf("fees exceed price %v", price)
+	}
+	consideration = append([]ConsiderationItem{nativeItem(proceeds, offerer)}, consideration...)
+
+	return OrderComponents{
+		Offerer: offerer,
+		Offer: []OfferItem{{
+			ItemType:             ERC721,
+			Token:                token,
+			IdentifierOrCriteria: tokenID,
+			StartAmount:          big.NewInt(1),
+			EndAmount:            big.NewInt(1),
+		}},
+		Consideration: consideration,
+		OrderType:     FullOpen,
+		StartTime:     big.NewInt(start.Unix()),
+		EndTime:       big.NewInt(end.Unix()),
+		Salt:          salt,
+		Counter:       counter,
+	}, nil
+}
+
+// nativeItem returns a ConsiderationItem of amount of the chain's native token.
+func nativeItem(amount *big.Int, recipient common.Address) ConsiderationItem {
+	return ConsiderationItem{
+		ItemType:             Native,
+		IdentifierOrCriteria: new(big.Int),
+		StartAmount:          amount,
+		EndAmount:            new(big.Int).Set(amount),
+		Recipient:            recipient,
+	}
+}
+
+// randomSalt returns a random 256-bit salt.
+func randomSalt() (*big.Int, error) {
+	var buf [32]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return nil, fmt.Errorf("read random salt: %v", err)
+	}
+	return new(big.Int).SetBytes(buf[:]), nil
+}
+
+// message returns the order as an EIP-712 message.
+func (o OrderComponents) message() (apitypes.TypedDataMessage, error) {
+	ints := map[string]*big.Int{
+		"startTime": o.StartTime,
+		"endTime":   o.EndTime,
+		"salt":      o.Salt,
+		"counter":   o.Counter,
+	}
+	for name, v := range ints {
+		if v == nil {
+			return nil, fmt.Errorf("%T.%s is nil", o, name)
+		}
+	}
+
+	offer := make([]interface{}, len(o.Offer))
+	for i, it := range o.Offer {
+		if it.IdentifierOrCriteria == nil || it.StartAmount == nil || it.EndAmount == nil {
+			return nil, fmt.Errorf("offer item %d has nil field(s)", i)
+		}
+		offer[i] = map[string]interface{}{
+			"itemType":             uint8Value(uint8(it.ItemType)),
+			"token":                it.Token.Hex(),
+			"identifierOrCriteria": (*math.HexOrDecimal256)(it.IdentifierOrCriteria),
+			"startAmount":          (*math.HexOrDecimal256)(it.StartAmount),
+			"endAmount":            (*math.HexOrDecimal256)(it.EndAmount),
+		}
+	}
+
+	consideration := make([]interface{}, len(o.Consideration))
+	for i, it := range o.Consideration {
+		if it.IdentifierOrCriteria == nil || it.StartAmount == nil || it.EndAmount == nil {
+			return nil, fmt.Errorf("consideration item %d has nil field(s)", i)
+		}
+		consideration[i] = map[string]interface{}{
+			"itemType":             uint8Value(uint8(it.ItemType)),
+			"token":                it.Token.Hex(),
+			"identifierOrCriteria": (*math.HexOrDecimal256)(it.IdentifierOrCriteria),
+			"startAmount":          (*math.HexOrDecimal256)(it.StartAmount),
+			"endAmount":            (*math.HexOrDecimal256)(it.EndAmount),
+			"recipient":            it.Recipient.Hex(),
+		}
+	}
+
+	return apitypes.TypedDataMessage{
+		"offerer":       o.Offerer.Hex(),
+		"zone":          o.Zone.Hex(),
+		"offer":         offer,
+		"consideration": consideration,
+		"orderType":     uint8Value(uint8(o.OrderType)),
+		"startTime":     (*math.HexOrDecimal256)(o.StartTime),
+		"endTime":       (*math.HexOrDecimal256)(o.EndTime),
+		"zoneHash":      o.ZoneHash[:],
+		"salt":          (*math.HexOrDecimal256)(o.Salt),
+		"conduitKey":    o.ConduitKey[:],
+		"counter":       (*math.HexOrDecimal256)(o.Counter),
+	}, nil
+}
+
+func uint8Value(x uint8) *math.HexOrDecimal256 {
+	return (*math.HexOrDecimal256)(big.NewInt(int64(x)))
+}
@@ -0,0 +1,84 @@
+package seaport
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestTypeHash(t *testing.T) {
+	td := apitypes.TypedData{Types: Types}
+	// _ORDER_TYPEHASH as computed by Seaport's ConsiderationBase constructor.
+	want := common.HexToHash("0xfa445660b7e21515a59617fcd68910b487aa5808b8abda3d78bc85df364b2c2f")
+	if got := common.BytesToHash(td.TypeHash("OrderComponents")); got != want {
+		t.Errorf("TypeHash(OrderComponents) got %v; want %v", got, want)
+	}
+}
+
+func TestNewListing(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	token := common.HexToAddress("0x1234")
+	royalties := common.HexToAddress("0xabcd")
+	price := eth.Ether(1)
+	now := time.Now()
+
+	o, err := NewListing(signer.Address(), token, big.NewInt(42), price, []Fee{{royalties, 500}}, now, now.Add(time.Hour), new(big.Int))
+	if err != nil {
+		t.Fatalf("NewListing() error %v", err)
+	}
+
+	if n := len(o.Consideration); n != 2 {
+		t.Fatalf("NewListing() got %d consideration items; want 2", n)
+	}
+	for _, tt := range []struct {
+		item      ConsiderationItem
+		recipient common.Address
+		amount    *big.Int
+	}{
+		{o.Consideration[0], signer.Address(), eth.EtherFraction(95, 100)},
+		{o.Consideration[1], royalties, big.NewInt(params.Ether / 20)},
+	} {
+		if tt.item.Recipient != tt.recipient || tt.item.StartAmount.Cmp(tt.amount) != 0 || tt.item.EndAmount.Cmp(tt.amount) != 0 {
+			t.Errorf("NewListing() consideration %+v; want %v to %v", tt.item, tt.amount, tt.recipient)
+		}
+	}
+
+	if _, err := NewListing(signer.Address(), token, big.NewInt(42), price, []Fee{{royalties, 10001}}, now, now, new(big.Int)); err == nil {
+		t.Errorf("NewListing() with fees exceeding price got nil error; want error")
+	}
+
+	chainID := big.NewInt(1)
+	sig, err := o.Sign(signer, chainID, Address)
+	if err != nil {
+		t.Fatalf("%T.Sign() error %v", o, err)
+	}
+	td, err := o.TypedData(chainID, Address)
+	if err != nil {
+		t.Fatalf("%T.TypedData() error %v", o, err)
+	}
+	digest, err := eth.TypedDataDigest(td)
+	if err != nil {
+		t.Fatalf("eth.TypedDataDigest() error %v", err)
+	}
+	if got, err := eth.RecoverAddress(digest, sig); err != nil || got != signer.Address() {
+		t.Errorf("eth.RecoverAddress(…, %T.Sign()) got %v, err = %v; want %v, nil err", o, got, err, signer.Address())
+	}
+
+	other, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+	if _, err := o.Sign(other, chainID, Address); err == nil {
+		t.Errorf("%T.Sign() by non-offerer got nil error; want error", o)
+	}
+}
@@ -1,16 +1,20 @@
 package eth
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"os"
+	"strings"
 
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 	"github.com/tyler-smith/go-bip39"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/tink/go/prf"
 )
@@ -20,6 +24,11 @@ import (
 type Signer struct {
 	key      *ecdsa.PrivateKey
 	mnemonic string
+	// password and path are only set alongside mnemonic, allowing for further
+	// derivation with Derive().
+	password, path string
+	// compact forces all signatures to be in EIP-2098 form; see Compact().
+	compact bool
 }
 
 // NewSigner is equivalent to
@@ -32,6 +41,48 @@ func NewSigner(bitSize int) (*Signer, error) {
 	return DefaultHDPathPrefix.SignerFromSeedPhrase(m, "", 0)
 }
 
+// NewSignerFromHex returns a Signer wrapping the hex-encoded private key. The
+// 0x prefix is optional. The returned Signer has no associated mnemonic.
+func NewSignerFromHex(key string) (*Signer, error) {
+	k, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(key), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("parse hex private key: %v", err)
+	}
+	return &Signer{key: k}, nil
+}
+
+// NewSignerFromEnv returns NewSignerFromHex(os.Getenv(name)), returning an
+// error if the environment variable is unset or empty.
+func NewSignerFromEnv(name string) (*Signer, error) {
+	key, ok := os.LookupEnv(name)
+	if !ok || key == "" {
+		return nil, fmt.Errorf("environment variable %q not set", name)
+	}
+	s, err := NewSignerFromHex(key)
+	if err != nil {
+		return nil, fmt.Errorf("$%s: %v", name, err)
+	}
+	return s, nil
+}
+
+// NewDeterministicSigner returns a Signer with a private key derived from the
+// seed, such that the same seed always results in the same key. It is intended
+// for tests, e.g. to generate stable signature fixtures, and MUST NOT be used
+// for keys that secure value as the seed is the key.
+func NewDeterministicSigner(seed []byte) (*Signer, error) {
+	// Not all 32-byte values are valid secp256k1 keys (they must be non-zero
+	// and less than the curve order), so rehash until one is found, which is
+	// almost certain on the first attempt.
+	buf := crypto.Keccak256([]byte("ethier:deterministic-signer"), seed)
+	for i := 0; i < 256; i++ {
+		if k, err := crypto.ToECDSA(buf); err == nil {
+			return &Signer{key: k}, nil
+		}
+		buf = crypto.Keccak256(buf)
+	}
+	return nil, fmt.Errorf("no valid key derived from seed %#x", seed)
+}
+
 // NewMnemonic is a convenience wrapper around go-bip39 entropy and mnemonic
 // creation.
 func NewMnemonic(bitSize int) (string, error) {
@@ -58,6 +109,21 @@ const DefaultHDPathPrefix = HDPathPrefix("m/44'/60'/0'/0/")
 // SignerFromSeedPhrase confirms that the mnemonic is valid under BIP39 and then
 // uses it to derive a private key (see HDPathF)
 func (hdp HDPathPrefix) SignerFromSeedPhrase(mnemonic, password string, account uint) (*Signer, error) {
+	return signerFromSeedPhrase(mnemonic, password, fmt.Sprintf("%s%d", hdp, account))
+}
+
+// NewSignerFromMnemonic confirms that the mnemonic is valid under BIP39 and
+// then uses it to derive a private key at the full derivation path; e.g.
+// "m/44'/60'/0'/0/0" is the first account used by most wallets. See
+// HDPathPrefix for deriving sequential accounts and Signer.Derive() for
+// deriving further keys from the returned Signer.
+func NewSignerFromMnemonic(mnemonic, path string) (*Signer, error) {
+	return signerFromSeedPhrase(mnemonic, "", path)
+}
+
+// signerFromSeedPhrase implements SignerFromSeedPhrase() and
+// NewSignerFromMnemonic().
+func signerFromSeedPhrase(mnemonic, password, derivationPath string) (*Signer, error) {
 	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, password)
 	if err != nil {
 		return nil, fmt.Errorf("create seed from mnemoic: %v", err)
@@ -67,7 +133,7 @@ func (hdp HDPathPrefix) SignerFromSeedPhrase(mnemonic, password string, account
 		return nil, fmt.Errorf("create wallet from seed: %v", err)
 	}
 
-	path, err := hdwallet.ParseDerivationPath(fmt.Sprintf("%s%d", hdp, account))
+	path, err := hdwallet.ParseDerivationPath(derivationPath)
 	if err != nil {
 		return nil, fmt.Errorf("parse derivation path: %v", err)
 	}
@@ -80,7 +146,28 @@ func (hdp HDPathPrefix) SignerFromSeedPhrase(mnemonic, password string, account
 	if err != nil {
 		return nil, fmt.Errorf("obtain private key: %v", err)
 	}
-	return &Signer{key, mnemonic}, nil
+	return &Signer{
+		key:      key,
+		mnemonic: mnemonic,
+		password: password,
+		path:     path.String(),
+	}, nil
+}
+
+// Derive returns a new Signer derived from the same mnemonic (and password, if
+// any) as s, but at a different derivation path. It returns an error if s was
+// not derived from a mnemonic.
+func (s *Signer) Derive(path string) (*Signer, error) {
+	if s.mnemonic == "" {
+		return nil, fmt.Errorf("%T has no mnemonic from which to derive %q", s, path)
+	}
+	return signerFromSeedPhrase(s.mnemonic, s.password, path)
+}
+
+// DerivationPath returns the path used to derive the Signer's private key from
+// its mnemonic, or the empty string if there is no mnemonic.
+func (s *Signer) DerivationPath() string {
+	return s.path
 }
 
 // SignerFromPRF deterministically derives a private key from the pseudo-random
@@ -134,6 +221,8 @@ func (s *Signer) Address() common.Address {
 // (always 0 or 1), carried in the highest bit of the s parameter, as per
 // EIP-2098. Using compact signatures reduces gas by removing a word from
 // calldata, and is compatible with OpenZeppelin's ECDSA.recover() helper.
+//
+// For convenience, V MAY also be 27 or 28. The signature is modified in place.
 func CompactSignature(rsv []byte) ([]byte, error) {
 	// Convert the 65-byte signature returned by Sign() into a 64-byte
 	// compressed version, as described in
@@ -142,6 +231,9 @@ func CompactSignature(rsv []byte) ([]byte, error) {
 		return nil, fmt.Errorf("signature length %d; expecting 65", n)
 	}
 	v := rsv[64]
+	if v == 27 || v == 28 {
+		v -= 27
+	}
 	if v != 0 && v != 1 {
 		return nil, fmt.Errorf("signature V = %d; expecting 0 or 1", v)
 	}
@@ -149,6 +241,28 @@ func CompactSignature(rsv []byte) ([]byte, error) {
 	return rsv[:64], nil
 }
 
+// ExpandSignature is the inverse of CompactSignature(), returning a new 65-byte
+// signature with V in {27,28}, as returned by Sign().
+func ExpandSignature(compact []byte) ([]byte, error) {
+	if n := len(compact); n != 64 {
+		return nil, fmt.Errorf("signature length %d; expecting 64", n)
+	}
+	rsv := make([]byte, 65)
+	copy(rsv, compact)
+	rsv[64] = 27 + rsv[32]>>7
+	rsv[32] &= 0x7f
+	return rsv, nil
+}
+
+// Compact returns a copy of the Signer for which all signing methods return
+// 64-byte signatures in compact form, as per EIP-2098. Methods that already
+// return compact signatures, e.g. PersonalSign(), are unaffected.
+func (s *Signer) Compact() *Signer {
+	c := *s
+	c.compact = true
+	return &c
+}
+
 // AppendRandomNonce appends random 32 bytes to the buffer, commonly used in
 // signature nonces.
 func appendRandomNonce(buf []byte) ([]byte, [32]byte, error) {
@@ -167,6 +281,14 @@ func WithPersonalMessagePrefix(message []byte) []byte {
 	return append(prefix, message...)
 }
 
+// WithIntendedValidatorPrefix converts data to conform to version 0x00 of the
+// EIP-191 signed data standard, which binds the signature to the validator
+// contract that is intended to verify it; i.e. 0x19 ‖ 0x00 ‖ validator ‖ data.
+func WithIntendedValidatorPrefix(validator common.Address, data []byte) []byte {
+	buf := append([]byte{0x19, 0x00}, validator.Bytes()...)
+	return append(buf, data...)
+}
+
 type signOpts struct {
 	raw, compact, personal, withNonce bool
 }
@@ -174,10 +296,13 @@ type signOpts struct {
 // sign signs a given buffer depending on the chosen options:
 // withNonce = true, appends a nonce to the message
 // compact = true, returns a compactified version of the signature according to
-// EIP-2098.
+// EIP-2098; this is also forced by s.Compact().
 // personal = true, adds a prefix to the message to conform to the EIP-191
 // personal message standard.
 // raw = false, the message is hashed before signing
+//
+// Signatures are always canonical, i.e. with s in the lower half of the curve
+// order and, unless compact, with V in {27,28}.
 func (s *Signer) sign(buf []byte, opts signOpts) ([]byte, *[32]byte, error) {
 	var nonce *[32]byte
 	var err error
@@ -204,7 +329,8 @@ func (s *Signer) sign(buf []byte, opts signOpts) ([]byte, *[32]byte, error) {
 		return nil, nil, err
 	}
 
-	if !opts.compact {
+	if !opts.compact && !s.compact {
+		sig[64] += 27
 		return sig, nonce, nil
 	}
 
@@ -268,6 +394,46 @@ func (s *Signer) PersonalSignWithNonce(buf []byte) ([]byte, [32]byte, error) {
 
 }
 
+// EthSignMessage returns an EIP-191 personal signature of buf in the form
+// returned by the eth_sign and personal_sign JSON-RPC methods; i.e. a 65-byte
+// signature with V in {27,28}, unless s.Compact() is used. It is equivalent to
+// a Solidity signature check against ECDSA.toEthSignedMessageHash(buf).
+func (s *Signer) EthSignMessage(buf []byte) ([]byte, error) {
+	sig, _, err := s.sign(buf, signOpts{
+		raw:       false,
+		compact:   false,
+		personal:  true,
+		withNonce: false,
+	})
+	return sig, err
+}
+
+// SignPacked returns s.EthSignMessage(EncodePacked(types, values...)), which
+// mirrors Solidity's ECDSA.toEthSignedMessageHash(abi.encodePacked(values…)).
+// This allows arbitrary voucher schemas to be signed without bespoke encoding.
+func (s *Signer) SignPacked(types []string, values ...interface{}) ([]byte, error) {
+	buf, err := EncodePacked(types, values...)
+	if err != nil {
+		return nil, fmt.Errorf("abi.encodePacked(): %v", err)
+	}
+	return s.EthSignMessage(buf)
+}
+
+// SignForValidator returns an EIP-191 version 0x00 ("intended validator")
+// ECDSA signature of data, in compact form, i.e. a signature of
+// keccak256(WithIntendedValidatorPrefix(validator, data)). Unlike
+// PersonalSign(), the signature is only valid for the specified validator
+// contract.
+func (s *Signer) SignForValidator(validator common.Address, data []byte) ([]byte, error) {
+	sig, _, err := s.sign(WithIntendedValidatorPrefix(validator, data), signOpts{
+		raw:       false,
+		compact:   true,
+		personal:  false,
+		withNonce: false,
+	})
+	return sig, err
+}
+
 // SignAddress is a convenience wrapper for s.PersonalSign(addr.Bytes()).
 func (s *Signer) PersonalSignAddress(addr common.Address) ([]byte, error) {
 	return s.PersonalSign(addr.Bytes())
@@ -278,3 +444,20 @@ func (s *Signer) PersonalSignAddress(addr common.Address) ([]byte, error) {
 func (s *Signer) TransactorWithChainID(chainID *big.Int) (*bind.TransactOpts, error) {
 	return bind.NewKeyedTransactorWithChainID(s.key, chainID)
 }
+
+// SignTx signs the transaction for the specified chain, returning a signed
+// copy. Legacy, EIP-2930 access-list, and EIP-1559 dynamic-fee transactions
+// are supported. Blob (EIP-4844) transactions are NOT supported.
+func (s *Signer) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if chainID == nil {
+		return nil, bind.ErrNoChainID
+	}
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// TransactOptsWithChainID returns TransactOpts(ctx, s, chainID), for use with
+// generated contract bindings or directly when submitting transactions to a
+// live chain.
+func (s *Signer) TransactOptsWithChainID(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error) {
+	return TransactOpts(ctx, s, chainID)
+}
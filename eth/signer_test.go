@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/tink/go/keyset"
 	"github.com/google/tink/go/prf"
 	"github.com/google/tink/go/tink"
@@ -198,3 +199,282 @@ func TestTransactorWithChainID(t *testing.T) {
 		sendEth(t, opts, sim.Addr(0), Ether(1), "invalid chain id")
 	})
 }
+
+func TestNewSignerFromHex(t *testing.T) {
+	// The private key 1 is commonly used in examples; its address is well
+	// known.
+	want := common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf")
+	const key = "0000000000000000000000000000000000000000000000000000000000000001"
+
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: key},
+		{key: "0x" + key},
+		{key: " 0x" + key + "\n"},
+		{key: "0x1234", wantErr: true},
+		{key: "not hex", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		s, err := NewSignerFromHex(tt.key)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("NewSignerFromHex(%q) got err %v; want err = %t", tt.key, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if got := s.Address(); got != want {
+			t.Errorf("NewSignerFromHex(%q).Address() got %v; want %v", tt.key, got, want)
+		}
+	}
+
+	const env = "ETHIER_TEST_PRIVATE_KEY"
+	t.Setenv(env, "0x"+key)
+	s, err := NewSignerFromEnv(env)
+	if err != nil {
+		t.Fatalf("NewSignerFromEnv(%q) error %v", env, err)
+	}
+	if got := s.Address(); got != want {
+		t.Errorf("NewSignerFromEnv(%q).Address() got %v; want %v", env, got, want)
+	}
+
+	if _, err := NewSignerFromEnv(env + "_UNSET"); err == nil {
+		t.Errorf("NewSignerFromEnv([unset variable]) got nil error; want error")
+	}
+}
+
+func TestNewSignerFromMnemonic(t *testing.T) {
+	// The default Hardhat / Foundry development mnemonic, for which account
+	// addresses are well known.
+	const mnemonic = "test test test test test test test test test test test junk"
+
+	tests := []struct {
+		path string
+		want common.Address
+	}{
+		{
+			path: "m/44'/60'/0'/0/0",
+			want: common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"),
+		},
+		{
+			path: "m/44'/60'/0'/0/1",
+			want: common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		},
+	}
+
+	for _, tt := range tests {
+		s, err := NewSignerFromMnemonic(mnemonic, tt.path)
+		if err != nil {
+			t.Fatalf("NewSignerFromMnemonic(%q, %q) error %v", mnemonic, tt.path, err)
+		}
+		if got := s.Address(); got != tt.want {
+			t.Errorf("NewSignerFromMnemonic(%q, %q).Address() got %v; want %v", mnemonic, tt.path, got, tt.want)
+		}
+		if got := s.DerivationPath(); got != tt.path {
+			t.Errorf("NewSignerFromMnemonic(%q, %q).DerivationPath() got %q; want %q", mnemonic, tt.path, got, tt.path)
+		}
+
+		// Derivation from any Signer sharing the mnemonic must be equivalent.
+		first, err := NewSignerFromMnemonic(mnemonic, tests[0].path)
+		if err != nil {
+			t.Fatalf("NewSignerFromMnemonic(%q, %q) error %v", mnemonic, tests[0].path, err)
+		}
+		child, err := first.Derive(tt.path)
+		if err != nil {
+			t.Fatalf("%T.Derive(%q) error %v", first, tt.path, err)
+		}
+		if got := child.Address(); got != tt.want {
+			t.Errorf("%T.Derive(%q).Address() got %v; want %v", first, tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := NewSignerFromMnemonic("not a valid mnemonic", "m/44'/60'/0'/0/0"); err == nil {
+		t.Errorf("NewSignerFromMnemonic([invalid mnemonic]) got nil error; want error")
+	}
+
+	s, err := NewSignerFromHex("0x0000000000000000000000000000000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatalf("NewSignerFromHex() error %v", err)
+	}
+	if _, err := s.Derive("m/44'/60'/0'/0/0"); err == nil {
+		t.Errorf("%T.Derive() without mnemonic got nil error; want error", s)
+	}
+}
+
+func TestCompactSignatures(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+	compact := signer.Compact()
+	msg := []byte("hello")
+
+	full, err := signer.Sign(msg)
+	if err != nil {
+		t.Fatalf("%T.Sign() error %v", signer, err)
+	}
+	if n := len(full); n != 65 {
+		t.Fatalf("%T.Sign() got signature length %d; want 65", signer, n)
+	}
+
+	got, err := compact.Sign(msg)
+	if err != nil {
+		t.Fatalf("%T.Compact().Sign() error %v", signer, err)
+	}
+	if n := len(got); n != 64 {
+		t.Fatalf("%T.Compact().Sign() got signature length %d; want 64", signer, n)
+	}
+
+	// ECDSA signatures in go-ethereum are deterministic (RFC 6979) so the
+	// forms must be convertible to each other.
+	expanded, err := ExpandSignature(got)
+	if err != nil {
+		t.Fatalf("ExpandSignature() error %v", err)
+	}
+	if !bytes.Equal(expanded, full) {
+		t.Errorf("ExpandSignature(%T.Compact().Sign()) got %#x; want %#x", signer, expanded, full)
+	}
+
+	recompacted, err := CompactSignature(append([]byte{}, full...))
+	if err != nil {
+		t.Fatalf("CompactSignature() error %v", err)
+	}
+	if !bytes.Equal(recompacted, got) {
+		t.Errorf("CompactSignature(%T.Sign()) got %#x; want %#x", signer, recompacted, got)
+	}
+
+	ethSig, err := compact.EthSignMessage(msg)
+	if err != nil {
+		t.Fatalf("%T.Compact().EthSignMessage() error %v", signer, err)
+	}
+	if n := len(ethSig); n != 64 {
+		t.Errorf("%T.Compact().EthSignMessage() got signature length %d; want 64", signer, n)
+	}
+	if !signer.VerifyPersonal(msg, ethSig) {
+		t.Errorf("%T.VerifyPersonal(msg, %T.Compact().EthSignMessage(msg)) got false; want true", signer, signer)
+	}
+
+	if got, want := compact.Address(), signer.Address(); got != want {
+		t.Errorf("%T.Compact().Address() got %v; want %v", signer, got, want)
+	}
+}
+
+func TestSignTx(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+	chainID := big.NewInt(1337)
+	to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	tests := []struct {
+		name string
+		tx   types.TxData
+	}{
+		{
+			name: "legacy",
+			tx:   &types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)},
+		},
+		{
+			name: "access list",
+			tx:   &types.AccessListTx{ChainID: chainID, To: &to, Gas: 21000, GasPrice: big.NewInt(1)},
+		},
+		{
+			name: "dynamic fee",
+			tx:   &types.DynamicFeeTx{ChainID: chainID, To: &to, Gas: 21000, GasFeeCap: big.NewInt(2), GasTipCap: big.NewInt(1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := signer.SignTx(types.NewTx(tt.tx), chainID)
+			if err != nil {
+				t.Fatalf("%T.SignTx() error %v", signer, err)
+			}
+			got, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+			if err != nil {
+				t.Fatalf("types.Sender(<signed tx>) error %v", err)
+			}
+			if want := signer.Address(); got != want {
+				t.Errorf("types.Sender(%T.SignTx()) got %v; want %v", signer, got, want)
+			}
+		})
+	}
+
+	t.Run("TransactOptsWithChainID", func(t *testing.T) {
+		opts, err := signer.TransactOptsWithChainID(context.Background(), chainID)
+		if err != nil {
+			t.Fatalf("%T.TransactOptsWithChainID() error %v", signer, err)
+		}
+		if _, err := opts.Signer(to, types.NewTx(&types.LegacyTx{})); err == nil {
+			t.Errorf("%T.Signer(<other address>, …) got nil error; want error", opts)
+		}
+		if _, err := signer.TransactOptsWithChainID(context.Background(), nil); err == nil {
+			t.Errorf("%T.TransactOptsWithChainID(nil chain ID) got nil error; want error", signer)
+		}
+	})
+}
+
+func TestNewDeterministicSigner(t *testing.T) {
+	// As with TestDeterministicSigner, this locks in outputs to avoid
+	// regressions that would invalidate users' fixtures.
+	tests := []struct {
+		seed []byte
+		want common.Address
+	}{
+		{
+			seed: nil,
+			want: common.HexToAddress("0xae28b815E475E9dc21Ac27AfF6Bc5c214016087E"),
+		},
+		{
+			seed: []byte("hello"),
+			want: common.HexToAddress("0xaD59cE01F898dA9039CD991812FC416Eddc7C717"),
+		},
+	}
+
+	for _, tt := range tests {
+		s, err := NewDeterministicSigner(tt.seed)
+		if err != nil {
+			t.Fatalf("NewDeterministicSigner(%q) error %v", tt.seed, err)
+		}
+		if got := s.Address(); got != tt.want {
+			t.Errorf("NewDeterministicSigner(%q).Address() got %v; want %v", tt.seed, got, tt.want)
+		}
+	}
+}
+
+func TestSignForValidator(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+	validator := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	data := []byte("hello")
+
+	sig, err := signer.SignForValidator(validator, data)
+	if err != nil {
+		t.Fatalf("%T.SignForValidator() error %v", signer, err)
+	}
+
+	// As computed by Solidity:
+	// keccak256(abi.encodePacked(bytes1(0x19), bytes1(0), validator, data))
+	want := append([]byte{0x19, 0x00}, validator.Bytes()...)
+	want = append(want, data...)
+	if got := WithIntendedValidatorPrefix(validator, data); !bytes.Equal(got, want) {
+		t.Errorf("WithIntendedValidatorPrefix() got %#x; want %#x", got, want)
+	}
+
+	got, err := RecoverAddress(crypto.Keccak256(want), sig)
+	if err != nil {
+		t.Fatalf("RecoverAddress() error %v", err)
+	}
+	if got != signer.Address() {
+		t.Errorf("RecoverAddress(<intended-validator digest>, %T.SignForValidator()) got %v; want %v", signer, got, signer.Address())
+	}
+
+	if signer.VerifyPersonal(data, sig) {
+		t.Errorf("%T.VerifyPersonal(data, %T.SignForValidator(…, data)) got true; want false", signer, signer)
+	}
+}
@@ -0,0 +1,55 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// EIP712DomainType is the EIP712Domain type definition for domains that
+// include all of the name, version, chainId, and verifyingContract fields.
+// Its order MUST match that of the fields in the Solidity domain separator.
+var EIP712DomainType = []apitypes.Type{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+}
+
+// EIP712Domain returns a TypedDataDomain, compatible with EIP712DomainType, for
+// use in TypedData.
+func EIP712Domain(name, version string, chainID *big.Int, verifyingContract common.Address) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              name,
+		Version:           version,
+		ChainId:           (*math.HexOrDecimal256)(chainID),
+		VerifyingContract: verifyingContract.Hex(),
+	}
+}
+
+// TypedDataDigest returns the EIP-712 digest of the typed data; i.e.
+// keccak256(0x1901 ‖ domainSeparator ‖ hashStruct(message)).
+func TypedDataDigest(td apitypes.TypedData) ([]byte, error) {
+	domainSep, err := td.HashStruct("EIP712Domain", td.Domain.Map())
+	if err != nil {
+		return nil, fmt.Errorf("hash EIP712Domain: %v", err)
+	}
+	msg, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, fmt.Errorf("hash %s: %v", td.PrimaryType, err)
+	}
+	return crypto.Keccak256([]byte{0x19, 0x01}, domainSep, msg), nil
+}
+
+// SignTypedData returns an ECDSA signature of TypedDataDigest(td).
+func (s *Signer) SignTypedData(td apitypes.TypedData) ([]byte, error) {
+	digest, err := TypedDataDigest(td)
+	if err != nil {
+		return nil, err
+	}
+	return s.RawSign(digest)
+}
@@ -0,0 +1,174 @@
+package eth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AddressMatcher returns a predicate reporting whether an address's hex
+// representation begins with prefix and ends with suffix. Matching is case
+// insensitive and an optional 0x on prefix is ignored; both prefix and suffix
+// MUST otherwise consist only of hex characters.
+func AddressMatcher(prefix, suffix string) (func(common.Address) bool, error) {
+	prefix = strings.ToLower(strings.TrimPrefix(prefix, "0x"))
+	suffix = strings.ToLower(suffix)
+
+	for _, s := range []string{prefix, suffix} {
+		if len(s) > 2*common.AddressLength {
+			return nil, fmt.Errorf("pattern %q longer than an address", s)
+		}
+		for _, c := range s {
+			if !strings.ContainsRune("0123456789abcdef", c) {
+				return nil, fmt.Errorf("pattern %q contains non-hex character %q", s, c)
+			}
+		}
+	}
+	if len(prefix)+len(suffix) > 2*common.AddressLength {
+		return nil, fmt.Errorf("prefix %q and suffix %q overlap", prefix, suffix)
+	}
+
+	return func(addr common.Address) bool {
+		h := hex.EncodeToString(addr.Bytes())
+		return strings.HasPrefix(h, prefix) && strings.HasSuffix(h, suffix)
+	}, nil
+}
+
+// MineVanityKey generates random private keys until one is found whose address
+// matches the prefix and suffix, as defined by AddressMatcher(). Keys are
+// generated concurrently across the specified number of workers; if workers
+// <= 0, runtime.NumCPU() workers are used.
+//
+// Every additional hex character multiplies the expected search time by 16 so
+// ctx SHOULD carry a deadline or be otherwise cancellable; its error is
+// returned if it is cancelled before a match is found.
+func MineVanityKey(ctx context.Context, prefix, suffix string, workers int) (*Signer, error) {
+	match, err := AddressMatcher(prefix, suffix)
+	if err != nil {
+		return nil, err
+	}
+	return MineKey(ctx, match, workers)
+}
+
+// MineKey generates random private keys until one is found with an address
+// for which predicate returns true. It is otherwise identical to
+// MineVanityKey(), which is typically preferred, but allows for custom
+// predicates; e.g. to count attempts. The predicate MUST be safe for
+// concurrent use.
+func MineKey(ctx context.Context, predicate func(common.Address) bool, workers int) (*Signer, error) {
+	var found *Signer
+	err := mine(ctx, workers, func() (func() (func(), bool), error) {
+		return func() (func(), bool) {
+			key, err := crypto.GenerateKey()
+			if err != nil {
+				return nil, false
+			}
+			s := &Signer{key: key}
+			if !predicate(s.Address()) {
+				return nil, false
+			}
+			return func() { found = s }, true
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// MineCreate2Salt searches for a CREATE2 salt that, when used by deployer to
+// deploy contract code with the specified init-code hash, results in an
+// address for which predicate returns true. Salts are searched concurrently
+// across the specified number of workers, each starting from a random point;
+// if workers <= 0, runtime.NumCPU() workers are used. As with MineKey(), the
+// predicate MUST be safe for concurrent use.
+//
+// As with MineVanityKey(), ctx SHOULD be cancellable and its error is returned
+// if it is cancelled before a match is found.
+func MineCreate2Salt(ctx context.Context, deployer common.Address, initCodeHash common.Hash, predicate func(common.Address) bool, workers int) ([32]byte, common.Address, error) {
+	var (
+		salt [32]byte
+		addr common.Address
+	)
+	err := mine(ctx, workers, func() (func() (func(), bool), error) {
+		var s [32]byte
+		if _, err := rand.Read(s[:]); err != nil {
+			return nil, fmt.Errorf("read random salt: %v", err)
+		}
+		ctr := binary.BigEndian.Uint64(s[24:])
+
+		return func() (func(), bool) {
+			ctr++
+			binary.BigEndian.PutUint64(s[24:], ctr)
+			a := crypto.CreateAddress2(deployer, s, initCodeHash.Bytes())
+			if !predicate(a) {
+				return nil, false
+			}
+			return func() { salt, addr = s, a }, true
+		}, nil
+	})
+	if err != nil {
+		return [32]byte{}, common.Address{}, err
+	}
+	return salt, addr, nil
+}
+
+// mine runs the specified number of workers (runtime.NumCPU() if <= 0), each
+// calling newWorker() once and then repeatedly calling the returned function
+// until any one of them reports success or ctx is cancelled. A successful
+// attempt returns a function to record its result, which is called for
+// exactly one such attempt so it may safely write to the caller's scope.
+func mine(ctx context.Context, workers int, newWorker func() (func() (func(), bool), error)) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		done bool
+	)
+
+	for w := 0; w < workers; w++ {
+		try, err := newWorker()
+		if err != nil {
+			cancel()
+			wg.Wait()
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				record, ok := try()
+				if !ok {
+					continue
+				}
+				once.Do(func() {
+					record()
+					done = true
+					cancel()
+				})
+				return
+			}
+		}()
+	}
+	wg.Wait()
+
+	if done {
+		return nil
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,117 @@
+package eth_test
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestAddressMatcher(t *testing.T) {
+	addr := common.HexToAddress("0xAbCd000000000000000000000000000000001234")
+
+	tests := []struct {
+		prefix, suffix string
+		want           bool
+	}{
+		{"", "", true},
+		{"abcd", "", true},
+		{"0xABCD", "", true},
+		{"", "1234", true},
+		{"abcd", "1234", true},
+		{"abce", "", false},
+		{"", "1235", false},
+	}
+
+	for _, tt := range tests {
+		match, err := AddressMatcher(tt.prefix, tt.suffix)
+		if err != nil {
+			t.Fatalf("AddressMatcher(%q, %q) error %v", tt.prefix, tt.suffix, err)
+		}
+		if got := match(addr); got != tt.want {
+			t.Errorf("AddressMatcher(%q, %q)(%v) got %t; want %t", tt.prefix, tt.suffix, addr, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"xyz", strings.Repeat("0", 41)} {
+		if _, err := AddressMatcher(bad, ""); err == nil {
+			t.Errorf("AddressMatcher(%q, \"\") got nil error; want error", bad)
+		}
+	}
+}
+
+func TestMineVanityKey(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	const prefix, suffix = "0xe", "7"
+	s, err := MineVanityKey(ctx, prefix, suffix, 0)
+	if err != nil {
+		t.Fatalf("MineVanityKey(%q, %q) error %v", prefix, suffix, err)
+	}
+
+	got := strings.ToLower(s.Address().Hex())
+	if !strings.HasPrefix(got, prefix) || !strings.HasSuffix(got, suffix) {
+		t.Errorf("MineVanityKey(%q, %q) got address %s", prefix, suffix, got)
+	}
+}
+
+func TestMineKey(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var attempts uint64
+	predicate := func(a common.Address) bool {
+		atomic.AddUint64(&attempts, 1)
+		return a[0] == 0x42
+	}
+
+	s, err := MineKey(ctx, predicate, 0)
+	if err != nil {
+		t.Fatalf("MineKey() error %v", err)
+	}
+	if got := s.Address(); got[0] != 0x42 {
+		t.Errorf("MineKey() got address %v not matching predicate", got)
+	}
+	if atomic.LoadUint64(&attempts) == 0 {
+		t.Error("MineKey() didn't call predicate")
+	}
+}
+
+func TestMineVanityKeyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := MineVanityKey(ctx, "ffffffffffffffff", "", 2); err != context.Canceled {
+		t.Errorf("MineVanityKey([cancelled context]) got err %v; want %v", err, context.Canceled)
+	}
+}
+
+func TestMineCreate2Salt(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	deployer := common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+	initCodeHash := crypto.Keccak256Hash([]byte("init code"))
+	match, err := AddressMatcher("00", "")
+	if err != nil {
+		t.Fatalf("AddressMatcher() error %v", err)
+	}
+
+	salt, addr, err := MineCreate2Salt(ctx, deployer, initCodeHash, match, 0)
+	if err != nil {
+		t.Fatalf("MineCreate2Salt() error %v", err)
+	}
+	if !match(addr) {
+		t.Errorf("MineCreate2Salt() got address %v not matching predicate", addr)
+	}
+	if got := crypto.CreateAddress2(deployer, salt, initCodeHash.Bytes()); got != addr {
+		t.Errorf("MineCreate2Salt() got salt %#x and address %v; CreateAddress2() with salt = %v", salt, addr, got)
+	}
+}
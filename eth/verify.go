@@ -0,0 +1,148 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// RecoverAddress returns the address of the key that signed the 32-byte digest.
+// The signature MAY be in any of the common forms: 65 bytes with V in {0,1} or
+// {27,28}, or 64-byte compact form as per EIP-2098.
+func RecoverAddress(digest, sig []byte) (common.Address, error) {
+	if n := len(digest); n != 32 {
+		return common.Address{}, fmt.Errorf("digest length %d; expecting 32", n)
+	}
+	rsv, err := canonicalSignature(sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pub, err := crypto.SigToPub(digest, rsv)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("recover public key: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// RecoverPersonalAddress returns the address of the key that produced an
+// EIP-191 personal signature of msg, as produced by Signer.PersonalSign(),
+// Signer.EthSignMessage(), and wallets' personal_sign.
+func RecoverPersonalAddress(msg, sig []byte) (common.Address, error) {
+	return RecoverAddress(crypto.Keccak256(WithPersonalMessagePrefix(msg)), sig)
+}
+
+// Verify returns whether sig is a valid signature of keccak256(msg) by s, as
+// produced by s.Sign(). Signatures are accepted in any form supported by
+// RecoverAddress().
+func (s *Signer) Verify(msg, sig []byte) bool {
+	addr, err := RecoverAddress(crypto.Keccak256(msg), sig)
+	return err == nil && addr == s.Address()
+}
+
+// VerifyPersonal returns whether sig is a valid EIP-191 personal signature of
+// msg by s, as produced by s.PersonalSign() or s.EthSignMessage().
+func (s *Signer) VerifyPersonal(msg, sig []byte) bool {
+	addr, err := RecoverPersonalAddress(msg, sig)
+	return err == nil && addr == s.Address()
+}
+
+// canonicalSignature returns a copy of sig in the 65-byte form, with V in
+// {0,1}, expected by the go-ethereum crypto package.
+func canonicalSignature(sig []byte) ([]byte, error) {
+	switch n := len(sig); n {
+	case 64:
+		rsv, err := ExpandSignature(sig)
+		if err != nil {
+			return nil, err
+		}
+		rsv[64] -= 27
+		return rsv, nil
+	case 65:
+		rsv := make([]byte, 65)
+		copy(rsv, sig)
+		if rsv[64] >= 27 {
+			rsv[64] -= 27
+		}
+		if v := rsv[64]; v != 0 && v != 1 {
+			return nil, fmt.Errorf("signature V = %d; expecting 0, 1, 27, or 28", sig[64])
+		}
+		return rsv, nil
+	default:
+		return nil, fmt.Errorf("signature length %d; expecting 64 or 65", n)
+	}
+}
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// NormalizeSignature returns a new 65-byte signature equivalent to sig but in
+// canonical form, i.e. with s in the lower half of the curve order (EIP-2) and
+// V in {27,28}, as expected by ecrecover and OpenZeppelin's ECDSA library. The
+// signature MAY be in any form accepted by RecoverAddress(); a high s value is
+// replaced by N-s, and V flipped, which recovers the same address.
+func NormalizeSignature(sig []byte) ([]byte, error) {
+	rsv, err := canonicalSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRS(rsv); err != nil {
+		return nil, err
+	}
+
+	s := new(big.Int).SetBytes(rsv[32:64])
+	if s.Cmp(secp256k1HalfN) == 1 {
+		s.Sub(secp256k1N, s)
+		s.FillBytes(rsv[32:64])
+		rsv[64] ^= 1
+	}
+	rsv[64] += 27
+	return rsv, nil
+}
+
+// ErrMalleableSignature is returned by CheckSignatureMalleability() for
+// signatures with a high s value.
+var ErrMalleableSignature = errors.New("malleable signature: s > N/2")
+
+// CheckSignatureMalleability returns an error if sig is not in a form accepted
+// by RecoverAddress(), if r or s are outside of the range [1,N), or if s is in
+// the upper half of the curve order. For any valid signature, a second one with
+// s' = N-s also recovers to the same address, so contracts and backends that
+// use signatures as unique identifiers (e.g. to prevent replay) MUST reject
+// one of the pair; by convention, that with the high s value.
+//
+// All signatures produced by ethier signers pass this check.
+func CheckSignatureMalleability(sig []byte) error {
+	rsv, err := canonicalSignature(sig)
+	if err != nil {
+		return err
+	}
+	if err := checkRS(rsv); err != nil {
+		return err
+	}
+	if new(big.Int).SetBytes(rsv[32:64]).Cmp(secp256k1HalfN) == 1 {
+		return ErrMalleableSignature
+	}
+	return nil
+}
+
+// checkRS returns an error if either of r or s in the 65-byte signature are
+// outside of the range [1,N).
+func checkRS(rsv []byte) error {
+	for _, x := range []struct {
+		name string
+		val  *big.Int
+	}{
+		{"r", new(big.Int).SetBytes(rsv[:32])},
+		{"s", new(big.Int).SetBytes(rsv[32:64])},
+	} {
+		if x.val.Sign() == 0 || x.val.Cmp(secp256k1N) != -1 {
+			return fmt.Errorf("signature %s out of range [1,N)", x.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package eth_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestVerify(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+	other, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	msg := []byte("hello world")
+
+	sign := func(fn func([]byte) ([]byte, error), name string) []byte {
+		t.Helper()
+		sig, err := fn(msg)
+		if err != nil {
+			t.Fatalf("%s(%q) error %v", name, msg, err)
+		}
+		return sig
+	}
+
+	sig := sign(signer.Sign, "Sign")
+	personal := sign(signer.PersonalSign, "PersonalSign")
+	ethSign := sign(signer.EthSignMessage, "EthSignMessage")
+
+	t.Run("Verify", func(t *testing.T) {
+		if !signer.Verify(msg, sig) {
+			t.Errorf("%T.Verify(msg, %T.Sign(msg)) got false; want true", signer, signer)
+		}
+		if other.Verify(msg, sig) {
+			t.Errorf("%T.Verify() by other signer got true; want false", other)
+		}
+		if signer.Verify([]byte("goodbye"), sig) {
+			t.Errorf("%T.Verify() with different message got true; want false", signer)
+		}
+		if signer.Verify(msg, personal) {
+			t.Errorf("%T.Verify() with personal signature got true; want false", signer)
+		}
+	})
+
+	t.Run("VerifyPersonal", func(t *testing.T) {
+		for _, s := range [][]byte{personal, ethSign} {
+			if !signer.VerifyPersonal(msg, s) {
+				t.Errorf("%T.VerifyPersonal(msg, %#x) got false; want true", signer, s)
+			}
+			if other.VerifyPersonal(msg, s) {
+				t.Errorf("%T.VerifyPersonal() by other signer got true; want false", other)
+			}
+		}
+		if signer.VerifyPersonal(msg, sig) {
+			t.Errorf("%T.VerifyPersonal() with non-personal signature got true; want false", signer)
+		}
+	})
+
+	t.Run("RecoverAddress", func(t *testing.T) {
+		got, err := RecoverAddress(crypto.Keccak256(msg), sig)
+		if err != nil {
+			t.Fatalf("RecoverAddress() error %v", err)
+		}
+		if want := signer.Address(); got != want {
+			t.Errorf("RecoverAddress() got %v; want %v", got, want)
+		}
+
+		for _, bad := range [][]byte{nil, sig[:63], append(sig[:64:64], 5)} {
+			if _, err := RecoverAddress(crypto.Keccak256(msg), bad); err == nil {
+				t.Errorf("RecoverAddress(…, %#x) got nil error; want error", bad)
+			}
+		}
+		if _, err := RecoverAddress(msg, sig); err == nil {
+			t.Errorf("RecoverAddress([non-32-byte digest], …) got nil error; want error")
+		}
+	})
+}
+
+func TestNormalizeSignature(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+	msg := []byte("hello world")
+	digest := crypto.Keccak256(msg)
+
+	sig, err := signer.Sign(msg)
+	if err != nil {
+		t.Fatalf("%T.Sign() error %v", signer, err)
+	}
+	if v := sig[64]; v != 27 && v != 28 {
+		t.Errorf("%T.Sign() got V = %d; want 27 or 28", signer, v)
+	}
+	if err := CheckSignatureMalleability(sig); err != nil {
+		t.Errorf("CheckSignatureMalleability(%T.Sign()) error %v", signer, err)
+	}
+
+	// The malleated signature, with s' = N-s and V flipped, recovers to the same
+	// address.
+	n := crypto.S256().Params().N
+	high := make([]byte, 65)
+	copy(high, sig)
+	new(big.Int).Sub(n, new(big.Int).SetBytes(sig[32:64])).FillBytes(high[32:64])
+	high[64] = 27 + 28 - sig[64]
+
+	if got, err := RecoverAddress(digest, high); err != nil || got != signer.Address() {
+		t.Fatalf("RecoverAddress(…, [high-s signature]) got %v, err = %v; want %v, nil err", got, err, signer.Address())
+	}
+	if err := CheckSignatureMalleability(high); err != ErrMalleableSignature {
+		t.Errorf("CheckSignatureMalleability([high-s signature]) got err %v; want %v", err, ErrMalleableSignature)
+	}
+
+	compact, err := signer.Compact().Sign(msg)
+	if err != nil {
+		t.Fatalf("%T.Compact().Sign() error %v", signer, err)
+	}
+	zeroV := append(append([]byte{}, sig[:64]...), sig[64]-27)
+
+	for _, in := range [][]byte{sig, high, compact, zeroV} {
+		got, err := NormalizeSignature(in)
+		if err != nil {
+			t.Errorf("NormalizeSignature(%#x) error %v", in, err)
+			continue
+		}
+		if !bytes.Equal(got, sig) {
+			t.Errorf("NormalizeSignature(%#x) got %#x; want %#x", in, got, sig)
+		}
+	}
+
+	zeroR := append(make([]byte, 32), sig[32:]...)
+	for _, bad := range [][]byte{nil, sig[:63], zeroR} {
+		if _, err := NormalizeSignature(bad); err == nil {
+			t.Errorf("NormalizeSignature(%#x) got nil error; want error", bad)
+		}
+		if err := CheckSignatureMalleability(bad); err == nil {
+			t.Errorf("CheckSignatureMalleability(%#x) got nil error; want error", bad)
+		}
+	}
+}
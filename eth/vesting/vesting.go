@@ -0,0 +1,64 @@
+// Package vesting computes token-vesting schedules equivalent to ethier's
+// TokenVesting contract, for off-chain display of vested amounts and as a
+// reference model against which to test the contract.
+package vesting
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+// A Schedule vests tokens linearly from Start until Start+Duration, with
+// nothing vesting before Start+Cliff. All values are in seconds, as with the
+// contract's constructor arguments.
+type Schedule struct {
+	Start, Cliff, Duration uint64
+}
+
+// New returns a Schedule, validated as the contract's constructor does.
+func New(start time.Time, cliff, duration time.Duration) (Schedule, error) {
+	s := Schedule{
+		Start:    uint64(start.Unix()),
+		Cliff:    uint64(cliff / time.Second),
+		Duration: uint64(duration / time.Second),
+	}
+	if err := s.Validate(); err != nil {
+		return Schedule{}, err
+	}
+	return s, nil
+}
+
+// Validate returns an error if the Schedule would be rejected by the
+// contract's constructor.
+func (s Schedule) Validate() error {
+	if s.Duration == 0 {
+		return errors.New("zero duration")
+	}
+	if s.Cliff > s.Duration {
+		return errors.New("cliff > duration")
+	}
+	return nil
+}
+
+// Vested returns the amount vested by the timestamp out of total tokens ever
+// received, including amounts already released. This is equivalent to the
+// contract's vestedAmount() with total equal to its balance plus released.
+func (s Schedule) Vested(total *big.Int, timestamp uint64) *big.Int {
+	switch {
+	case timestamp < s.Start+s.Cliff:
+		return big.NewInt(0)
+	case timestamp >= s.Start+s.Duration:
+		return new(big.Int).Set(total)
+	}
+
+	v := new(big.Int).Mul(total, new(big.Int).SetUint64(timestamp-s.Start))
+	return v.Div(v, new(big.Int).SetUint64(s.Duration))
+}
+
+// Releasable returns the amount that the contract's release() would transfer
+// at the timestamp, given the amount already released.
+func (s Schedule) Releasable(total, released *big.Int, timestamp uint64) *big.Int {
+	v := s.Vested(total, timestamp)
+	return v.Sub(v, released)
+}
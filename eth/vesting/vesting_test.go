@@ -0,0 +1,94 @@
+package vesting
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestVested(t *testing.T) {
+	s := Schedule{
+		Start:    1000,
+		Cliff:    100,
+		Duration: 400,
+	}
+	total := big.NewInt(4000)
+
+	tests := []struct {
+		timestamp uint64
+		want      int64
+	}{
+		{timestamp: 0, want: 0},
+		{timestamp: 1000, want: 0},
+		{timestamp: 1099, want: 0},
+		// The linear schedule is from Start, not the end of the cliff.
+		{timestamp: 1100, want: 1000},
+		{timestamp: 1101, want: 1010},
+		{timestamp: 1399, want: 3990},
+		{timestamp: 1400, want: 4000},
+		{timestamp: 1 << 40, want: 4000},
+	}
+
+	for _, tt := range tests {
+		if got := s.Vested(total, tt.timestamp); got.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("%+v.Vested(%d, %d) got %d; want %d", s, total, tt.timestamp, got, tt.want)
+		}
+	}
+
+	if got, want := s.Releasable(total, big.NewInt(1000), 1200), big.NewInt(1000); got.Cmp(want) != 0 {
+		t.Errorf("%+v.Releasable(%d, 1000, 1200) got %d; want %d", s, total, got, want)
+	}
+}
+
+func TestVestedRoundsDown(t *testing.T) {
+	s := Schedule{Start: 0, Duration: 3}
+	if got, want := s.Vested(big.NewInt(10), 1), big.NewInt(3); got.Cmp(want) != 0 {
+		t.Errorf("%+v.Vested(10, 1) got %d; want %d", s, got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	start := time.Unix(1e9, 0)
+
+	tests := []struct {
+		name            string
+		cliff, duration time.Duration
+		want            Schedule
+		wantErr         bool
+	}{
+		{
+			name:     "valid",
+			cliff:    time.Hour,
+			duration: 24 * time.Hour,
+			want:     Schedule{Start: 1e9, Cliff: 3600, Duration: 86400},
+		},
+		{
+			name:     "cliff equal to duration",
+			cliff:    time.Hour,
+			duration: time.Hour,
+			want:     Schedule{Start: 1e9, Cliff: 3600, Duration: 3600},
+		},
+		{
+			name:    "zero duration",
+			wantErr: true,
+		},
+		{
+			name:     "cliff after duration",
+			cliff:    2 * time.Hour,
+			duration: time.Hour,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(start, tt.cliff, tt.duration)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("New(%v, %v, %v) got err %v; want err? %t", start, tt.cliff, tt.duration, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("New(%v, %v, %v) got %+v; want %+v", start, tt.cliff, tt.duration, got, tt.want)
+			}
+		})
+	}
+}
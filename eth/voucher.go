@@ -0,0 +1,129 @@
+package eth
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// A Voucher is a signed authorisation for a recipient to perform an action,
+// typically a lazy mint or a claim, limited to a single use by its nonce and to
+// a time window by its expiry. It is the Go counterpart of the
+// VoucherChecker.Voucher Solidity struct, and its encoding is identical to
+// VoucherChecker.encode().
+type Voucher struct {
+	Recipient common.Address
+	// Value is interpreted by the verifying contract; e.g. a token ID or an
+	// allowance.
+	Value *big.Int
+	Nonce [32]byte
+	// Expiry is the Unix timestamp, in seconds, after which the voucher is no
+	// longer valid.
+	Expiry uint64
+}
+
+// NewVoucher returns a Voucher with a random nonce, valid until the specified
+// expiry time.
+func NewVoucher(recipient common.Address, value *big.Int, expiry time.Time) (Voucher, error) {
+	_, nonce, err := appendRandomNonce(nil)
+	if err != nil {
+		return Voucher{}, err
+	}
+	return Voucher{
+		Recipient: recipient,
+		Value:     value,
+		Nonce:     nonce,
+		Expiry:    uint64(expiry.Unix()),
+	}, nil
+}
+
+// voucherArgs are the ABI arguments for canonical Voucher encoding.
+var voucherArgs = func() abi.Arguments {
+	var args abi.Arguments
+	for _, t := range []string{"address", "uint256", "bytes32", "uint256"} {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(fmt.Sprintf("abi.NewType(%q): %v", t, err))
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return args
+}()
+
+// Encode returns the canonical encoding of the Voucher, equivalent to Solidity's
+// abi.encode(recipient, value, nonce, expiry). Standard (i.e. non-packed)
+// encoding is used to avoid ambiguity between fields.
+func (v Voucher) Encode() ([]byte, error) {
+	if v.Value == nil {
+		return nil, fmt.Errorf("%T.Value is nil", v)
+	}
+	return voucherArgs.Pack(v.Recipient, v.Value, v.Nonce, new(big.Int).SetUint64(v.Expiry))
+}
+
+// SignVoucher returns s.PersonalSign(v.Encode()), which is verified by the
+// VoucherChecker Solidity library.
+func (s *Signer) SignVoucher(v Voucher) ([]byte, error) {
+	return SignVoucher(s, v)
+}
+
+// A VoucherDomain describes the EIP-712 domain of a contract inheriting the
+// SignatureGate Solidity contract. Name and Version are those passed to its
+// constructor.
+type VoucherDomain struct {
+	Name, Version string
+	ChainID       *big.Int
+	Address       common.Address
+}
+
+// VoucherTypedData returns the EIP-712 typed data of the Voucher as verified
+// by SignatureGate, with the Voucher's Value as the allowance.
+func VoucherTypedData(d VoucherDomain, v Voucher) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": EIP712DomainType,
+			"Voucher": {
+				{Name: "recipient", Type: "address"},
+				{Name: "allowance", Type: "uint256"},
+				{Name: "nonce", Type: "bytes32"},
+				{Name: "expiry", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Voucher",
+		Domain:      EIP712Domain(d.Name, d.Version, d.ChainID, d.Address),
+		Message: apitypes.TypedDataMessage{
+			"recipient": v.Recipient.Hex(),
+			"allowance": (*math.HexOrDecimal256)(v.Value),
+			"nonce":     hexutil.Bytes(v.Nonce[:]),
+			"expiry":    (*math.HexOrDecimal256)(new(big.Int).SetUint64(v.Expiry)),
+		},
+	}
+}
+
+// SignTypedVoucher signs VoucherTypedData(d, v) with the backend, returning a
+// 65-byte signature, with V in {27,28}, as verified by SignatureGate. Unlike
+// SignVoucher(), the signature is bound to the verifying contract and chain.
+func SignTypedVoucher(b SignerBackend, d VoucherDomain, v Voucher) ([]byte, error) {
+	if v.Value == nil {
+		return nil, fmt.Errorf("%T.Value is nil", v)
+	}
+	sig, err := b.SignTypedData(VoucherTypedData(d, v))
+	if err != nil {
+		return nil, fmt.Errorf("sign voucher: %v", err)
+	}
+	if sig, err = canonicalSignature(sig); err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// SignTypedVoucher returns SignTypedVoucher(s, d, v).
+func (s *Signer) SignTypedVoucher(d VoucherDomain, v Voucher) ([]byte, error) {
+	return SignTypedVoucher(s, d, v)
+}
@@ -0,0 +1,138 @@
+package eth_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	. "github.com/divergencetech/ethier/eth"
+)
+
+func TestVoucherEncode(t *testing.T) {
+	v := Voucher{
+		Recipient: common.HexToAddress("0x000000000000000000000000000000000000dEaD"),
+		Value:     big.NewInt(42),
+		Nonce:     [32]byte{0: 1, 31: 2},
+		Expiry:    1 << 32,
+	}
+
+	got, err := v.Encode()
+	if err != nil {
+		t.Fatalf("%T.Encode() error %v", v, err)
+	}
+
+	// abi.encode() pads every field to a full word.
+	var want []byte
+	want = append(want, common.LeftPadBytes(v.Recipient.Bytes(), 32)...)
+	want = append(want, common.LeftPadBytes(v.Value.Bytes(), 32)...)
+	want = append(want, v.Nonce[:]...)
+	want = append(want, common.LeftPadBytes(big.NewInt(1<<32).Bytes(), 32)...)
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("%T.Encode() got %#x; want %#x", v, got, want)
+	}
+
+	if _, err := (Voucher{}).Encode(); err == nil {
+		t.Errorf("%T{}.Encode() with nil Value; got nil error", v)
+	}
+}
+
+func TestNewVoucher(t *testing.T) {
+	to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	expiry := time.Unix(1e9, 0)
+
+	a, err := NewVoucher(to, big.NewInt(1), expiry)
+	if err != nil {
+		t.Fatalf("NewVoucher() error %v", err)
+	}
+	b, err := NewVoucher(to, big.NewInt(1), expiry)
+	if err != nil {
+		t.Fatalf("NewVoucher() error %v", err)
+	}
+
+	if a.Nonce == b.Nonce {
+		t.Errorf("NewVoucher() called twice returned identical nonces %#x", a.Nonce)
+	}
+	if a.Expiry != 1e9 {
+		t.Errorf("NewVoucher(…, %v).Expiry got %d; want %d", expiry, a.Expiry, uint64(1e9))
+	}
+}
+
+func TestSignTypedVoucher(t *testing.T) {
+	signer, err := NewSigner(128)
+	if err != nil {
+		t.Fatalf("NewSigner(128) error %v", err)
+	}
+
+	d := VoucherDomain{
+		Name:    "Gate",
+		Version: "1",
+		ChainID: big.NewInt(1337),
+		Address: common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3"),
+	}
+	v := Voucher{
+		Recipient: common.HexToAddress("0x000000000000000000000000000000000000dEaD"),
+		Value:     big.NewInt(3),
+		Nonce:     [32]byte{0: 1, 31: 2},
+		Expiry:    1 << 32,
+	}
+
+	// Independently compute the digest as SignatureGate.voucherDigest() does.
+	word := func(b []byte) []byte {
+		return common.LeftPadBytes(b, 32)
+	}
+	domainSep := crypto.Keccak256(
+		crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")),
+		crypto.Keccak256([]byte(d.Name)),
+		crypto.Keccak256([]byte(d.Version)),
+		word(d.ChainID.Bytes()),
+		word(d.Address.Bytes()),
+	)
+	structHash := crypto.Keccak256(
+		crypto.Keccak256([]byte("Voucher(address recipient,uint256 allowance,bytes32 nonce,uint256 expiry)")),
+		word(v.Recipient.Bytes()),
+		word(v.Value.Bytes()),
+		v.Nonce[:],
+		word(big.NewInt(1<<32).Bytes()),
+	)
+	digest := crypto.Keccak256([]byte{0x19, 0x01}, domainSep, structHash)
+
+	got, err := TypedDataDigest(VoucherTypedData(d, v))
+	if err != nil {
+		t.Fatalf("TypedDataDigest(VoucherTypedData(…)) error %v", err)
+	}
+	if !bytes.Equal(got, digest) {
+		t.Errorf("TypedDataDigest(VoucherTypedData(…)) got %#x; want %#x", got, digest)
+	}
+
+	for _, s := range []*Signer{signer, signer.Compact()} {
+		sig, err := s.SignTypedVoucher(d, v)
+		if err != nil {
+			t.Fatalf("SignTypedVoucher() error %v", err)
+		}
+		if n := len(sig); n != 65 {
+			t.Fatalf("SignTypedVoucher() got %d-byte signature; want 65", n)
+		}
+		if v := sig[64]; v != 27 && v != 28 {
+			t.Errorf("SignTypedVoucher() got v = %d; want 27 or 28", v)
+		}
+
+		rsv := append([]byte{}, sig...)
+		rsv[64] -= 27
+		pub, err := crypto.SigToPub(digest, rsv)
+		if err != nil {
+			t.Fatalf("crypto.SigToPub(<voucher digest>, <voucher signature>) error %v", err)
+		}
+		if got, want := crypto.PubkeyToAddress(*pub), signer.Address(); got != want {
+			t.Errorf("SignTypedVoucher() signature recovers to %v; want %v", got, want)
+		}
+	}
+
+	if _, err := signer.SignTypedVoucher(d, Voucher{}); err == nil {
+		t.Errorf("SignTypedVoucher(%T{}) with nil Value; got nil error", v)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	const short = "Encodes and decodes calldata with human-readable function signatures."
+
+	cmd := &cobra.Command{
+		Use:   "abi",
+		Short: short,
+		Long: short + `
+
+Signatures and arguments take the same form as for ethier call, e.g. 'mint(uint256,address)' 3 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed.`,
+	}
+
+	encode := &cobra.Command{
+		Use:   "encode <signature> [args...]",
+		Short: "Prints the calldata for calling the function with the arguments",
+		RunE:  abiEncode,
+		Args:  cobra.MinimumNArgs(1),
+	}
+	encode.Flags().Bool("no-selector", false, "Omit the 4-byte function selector, e.g. for constructor arguments")
+
+	decode := &cobra.Command{
+		Use:   "decode --sig <signature> <data>",
+		Short: "Decodes calldata, or return data, and prints each value on its own line",
+		RunE:  abiDecode,
+		Args:  cobra.ExactArgs(1),
+	}
+	decode.Flags().String("sig", "", "Function signature with which to decode the data")
+	decode.Flags().Bool("no-selector", false, "Data doesn't include the 4-byte function selector, e.g. for constructor arguments")
+	decode.Flags().Bool("output", false, "Decode data as the function's return values, declared as for ethier call")
+
+	cmd.AddCommand(encode, decode)
+	rootCmd.AddCommand(cmd)
+}
+
+// abiEncode implements the `ethier abi encode` command.
+func abiEncode(cmd *cobra.Command, args []string) error {
+	noSelector, err := cmd.Flags().GetBool("no-selector")
+	if err != nil {
+		return err
+	}
+
+	method, data, err := packCall(args[0], args[1:])
+	if err != nil {
+		return err
+	}
+	if noSelector {
+		data = data[len(method.ID):]
+	}
+	fmt.Println(hexutil.Encode(data))
+	return nil
+}
+
+// abiDecode implements the `ethier abi decode` command.
+func abiDecode(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	sig, err := flags.GetString("sig")
+	if err != nil {
+		return err
+	}
+	noSelector, err := flags.GetBool("no-selector")
+	if err != nil {
+		return err
+	}
+	output, err := flags.GetBool("output")
+	if err != nil {
+		return err
+	}
+	if sig == "" {
+		return errors.New("--sig is required")
+	}
+	if output && noSelector {
+		return errors.New("--output and --no-selector are mutually exclusive as return data never includes a selector")
+	}
+
+	method, err := parseFunctionSignature(sig)
+	if err != nil {
+		return err
+	}
+	data, err := hexutil.Decode(args[0])
+	if err != nil {
+		return fmt.Errorf("data: %v", err)
+	}
+
+	var vals []string
+	switch {
+	case output:
+		if len(method.Outputs) == 0 {
+			return fmt.Errorf("--output requires return types in signature, e.g. %s(uint256)", method.Sig)
+		}
+		vals, err = unpackReturn(method, data)
+	default:
+		vals, err = unpackCall(method, data, !noSelector)
+	}
+	if err != nil {
+		return err
+	}
+	for _, v := range vals {
+		fmt.Println(v)
+	}
+	return nil
+}
+
+// unpackCall returns human-readable representations of each of the method's
+// arguments, as encoded in the calldata. If withSelector is true, data MUST
+// begin with the method's selector.
+func unpackCall(method abi.Method, data []byte, withSelector bool) ([]string, error) {
+	if withSelector {
+		if len(data) < len(method.ID) {
+			return nil, fmt.Errorf("calldata of %d bytes too short for selector", len(data))
+		}
+		if sel := data[:len(method.ID)]; !bytes.Equal(sel, method.ID) {
+			return nil, fmt.Errorf("calldata selector %#x doesn't match %s selector %#x", sel, method.Sig, method.ID)
+		}
+		data = data[len(method.ID):]
+	}
+
+	vals, err := method.Inputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("unpack %s arguments: %v", method.Sig, err)
+	}
+	out := make([]string, len(vals))
+	for i, v := range vals {
+		out[i] = formatABIValue(v)
+	}
+	return out, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnpackCall(t *testing.T) {
+	const sig = "mint(uint256,address[],(bool,bytes))"
+	args := []string{
+		"3",
+		"[0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359]",
+		"(true,0xdeadbeef)",
+	}
+
+	method, data, err := packCall(sig, args)
+	if err != nil {
+		t.Fatalf("packCall(%q, %q) error %v", sig, args, err)
+	}
+
+	got, err := unpackCall(method, data, true)
+	if err != nil {
+		t.Fatalf("unpackCall(<packed>, true) error %v", err)
+	}
+	if diff := cmp.Diff(args, got); diff != "" {
+		t.Errorf("unpackCall(packCall(%q, args), true) diff (-want +got):\n%s", sig, diff)
+	}
+
+	got, err = unpackCall(method, data[4:], false)
+	if err != nil {
+		t.Fatalf("unpackCall(<packed without selector>, false) error %v", err)
+	}
+	if diff := cmp.Diff(args, got); diff != "" {
+		t.Errorf("unpackCall(<packed without selector>, false) diff (-want +got):\n%s", diff)
+	}
+
+	other, err := parseFunctionSignature("burn(uint256,address[],(bool,bytes))")
+	if err != nil {
+		t.Fatalf("parseFunctionSignature() error %v", err)
+	}
+	if _, err := unpackCall(other, data, true); err == nil {
+		t.Error("unpackCall() with mismatched selector got nil error; want error")
+	}
+	if _, err := unpackCall(method, data[:2], true); err == nil {
+		t.Error("unpackCall() with truncated selector got nil error; want error")
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// parsePackedArg parses s as a value of the Solidity type, returning it in a
+// form accepted by eth.EncodePacked(). Integers MAY be decimal or 0x-prefixed
+// hex, and are range-checked against the type; bytes MUST be 0x-prefixed hex.
+func parsePackedArg(typ string, s string) (interface{}, error) {
+	t, err := abi.NewType(typ, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse type %q: %v", typ, err)
+	}
+
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		return parseInteger(t, strings.TrimSpace(s))
+	case abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+	return parseABIValue(t, s)
+}
+
+// parseInteger parses s, which MAY be decimal or 0x-prefixed hex, as an
+// integer of type t, confirming that it is in range.
+func parseInteger(t abi.Type, s string) (*big.Int, error) {
+	x, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(t.Size))
+	if t.T == abi.IntTy {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	if x.Cmp(min) == -1 || x.Cmp(max) != -1 {
+		return nil, fmt.Errorf("%s out of range for %s", s, t)
+	}
+	return x, nil
+}
+
+// parseABIValue parses s as a value of type t, returning it as the Go type
+// expected by abi.Arguments.Pack(). Integers MAY be decimal or 0x-prefixed
+// hex; bytes MUST be 0x-prefixed hex. Arrays are of the form [a,b,c] and
+// tuples (a,b,c), nested as required; strings within them MUST NOT contain
+// commas or brackets.
+func parseABIValue(t abi.Type, s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+
+	switch t.T {
+	case abi.AddressTy:
+		return eth.ParseAddress(s)
+
+	case abi.BoolTy:
+		return strconv.ParseBool(s)
+
+	case abi.StringTy:
+		return s, nil
+
+	case abi.BytesTy:
+		return hexutil.Decode(s)
+
+	case abi.FixedBytesTy:
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != t.Size {
+			return nil, fmt.Errorf("%d bytes for %s", len(b), t)
+		}
+		arr := reflect.New(t.GetType()).Elem()
+		reflect.Copy(arr, reflect.ValueOf(b))
+		return arr.Interface(), nil
+
+	case abi.IntTy, abi.UintTy:
+		x, err := parseInteger(t, s)
+		if err != nil {
+			return nil, err
+		}
+		// Pack() requires native Go types for integers of up to 64 bits.
+		switch rt := t.GetType(); rt.Kind() {
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return reflect.ValueOf(x.Int64()).Convert(rt).Interface(), nil
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return reflect.ValueOf(x.Uint64()).Convert(rt).Interface(), nil
+		}
+		return x, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		elems, err := splitEnclosed(s, '[', ']')
+		if err != nil {
+			return nil, err
+		}
+		if t.T == abi.ArrayTy && len(elems) != t.Size {
+			return nil, fmt.Errorf("%d elements for %s", len(elems), t)
+		}
+
+		var v reflect.Value
+		if t.T == abi.SliceTy {
+			v = reflect.MakeSlice(t.GetType(), len(elems), len(elems))
+		} else {
+			v = reflect.New(t.GetType()).Elem()
+		}
+		for i, e := range elems {
+			x, err := parseABIValue(*t.Elem, e)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", i, err)
+			}
+			v.Index(i).Set(reflect.ValueOf(x))
+		}
+		return v.Interface(), nil
+
+	case abi.TupleTy:
+		elems, err := splitEnclosed(s, '(', ')')
+		if err != nil {
+			return nil, err
+		}
+		if len(elems) != len(t.TupleElems) {
+			return nil, fmt.Errorf("%d elements for %s", len(elems), t)
+		}
+		v := reflect.New(t.GetType()).Elem()
+		for i, e := range elems {
+			x, err := parseABIValue(*t.TupleElems[i], e)
+			if err != nil {
+				return nil, fmt.Errorf("tuple element %d: %v", i, err)
+			}
+			v.Field(i).Set(reflect.ValueOf(x))
+		}
+		return v.Interface(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// parseABIValues parses each of the strings as a value of the respective
+// argument's type, for use with args.Pack().
+func parseABIValues(args abi.Arguments, strs []string) ([]interface{}, error) {
+	if n, m := len(args), len(strs); n != m {
+		return nil, fmt.Errorf("%d values for %d arguments", m, n)
+	}
+	vals := make([]interface{}, len(strs))
+	for i, s := range strs {
+		v, err := parseABIValue(args[i].Type, s)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d (%s): %v", i, args[i].Type, err)
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+// splitEnclosed confirms that s is enclosed by the open and close runes, and
+// returns the comma-separated elements between them; see splitTopLevel().
+func splitEnclosed(s string, open, close byte) ([]string, error) {
+	if len(s) < 2 || s[0] != open || s[len(s)-1] != close {
+		return nil, fmt.Errorf("%q not enclosed in %c%c", s, open, close)
+	}
+	return splitTopLevel(s[1 : len(s)-1])
+}
+
+// splitTopLevel splits s by commas that aren't nested within brackets or
+// parentheses. An empty (or all-whitespace) s results in no elements.
+func splitTopLevel(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, c := range s {
+		switch c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced %c in %q", c, s)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced brackets in %q", s)
+	}
+	return append(parts, strings.TrimSpace(s[start:])), nil
+}
+
+// parseFunctionSignature parses a human-readable function signature of the
+// form name(inputs) or name(inputs)(outputs), e.g.
+// balanceOf(address)(uint256). Parameter names, if present, are ignored and
+// tuples are expressed as parenthesised types, e.g. f((uint256,address)[]).
+func parseFunctionSignature(sig string) (abi.Method, error) {
+	sig = strings.TrimSpace(sig)
+	open := strings.IndexByte(sig, '(')
+	if open <= 0 {
+		return abi.Method{}, fmt.Errorf("invalid function signature %q", sig)
+	}
+	name := sig[:open]
+
+	// Find the end of the inputs, which may contain nested parentheses.
+	depth, end := 0, -1
+	for i := open; i < len(sig) && end == -1; i++ {
+		switch sig[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end == -1 {
+		return abi.Method{}, fmt.Errorf("unbalanced parentheses in %q", sig)
+	}
+
+	inputs, err := parseArguments(sig[open : end+1])
+	if err != nil {
+		return abi.Method{}, fmt.Errorf("inputs of %q: %v", sig, err)
+	}
+	var outputs abi.Arguments
+	if rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(sig[end+1:]), "returns")); rest != "" {
+		if outputs, err = parseArguments(rest); err != nil {
+			return abi.Method{}, fmt.Errorf("outputs of %q: %v", sig, err)
+		}
+	}
+
+	return abi.NewMethod(name, name, abi.Function, "", false, false, inputs, outputs), nil
+}
+
+// parseArguments parses a parenthesised, comma-separated list of types, each
+// optionally followed by a parameter name.
+func parseArguments(s string) (abi.Arguments, error) {
+	types, err := splitEnclosed(strings.TrimSpace(s), '(', ')')
+	if err != nil {
+		return nil, err
+	}
+	args := make(abi.Arguments, len(types))
+	for i, typ := range types {
+		m, err := parseTypeString(typ)
+		if err != nil {
+			return nil, err
+		}
+		t, err := abi.NewType(m.Type, "", m.Components)
+		if err != nil {
+			return nil, fmt.Errorf("type %q: %v", typ, err)
+		}
+		args[i] = abi.Argument{Type: t}
+	}
+	return args, nil
+}
+
+// parseTypeString converts a single type, which MAY be a parenthesised tuple
+// with an array suffix and MAY be followed by a parameter name, to the form
+// expected by abi.NewType().
+func parseTypeString(s string) (abi.ArgumentMarshaling, error) {
+	s = strings.TrimSpace(s)
+	// Strip any parameter name, which can only follow the final bracket or
+	// type name.
+	if i := strings.LastIndexAny(s, " \t"); i != -1 && !strings.ContainsAny(s[i:], ")]") {
+		s = strings.TrimSpace(s[:i])
+	}
+	if s == "" {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("empty type")
+	}
+	if s[0] != '(' {
+		return abi.ArgumentMarshaling{Type: s}, nil
+	}
+
+	close := strings.LastIndexByte(s, ')')
+	elems, err := splitTopLevel(s[1:close])
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+	m := abi.ArgumentMarshaling{Type: "tuple" + s[close+1:]}
+	for i, e := range elems {
+		c, err := parseTypeString(e)
+		if err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+		// abi.NewType() converts component names to struct fields so they
+		// MUST be valid identifiers.
+		c.Name = fmt.Sprintf("field%d", i)
+		m.Components = append(m.Components, c)
+	}
+	return m, nil
+}
+
+// formatABIValue returns a human-readable representation of a value returned
+// by abi.Arguments.Unpack(), in the same form accepted by parseABIValue().
+func formatABIValue(v interface{}) string {
+	switch x := v.(type) {
+	case *big.Int:
+		return x.String()
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	case []byte:
+		return hexutil.Encode(x)
+	case string:
+		return x
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		elems := make([]string, rv.Len())
+		for i := range elems {
+			elems[i] = formatABIValue(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(elems, ",") + "]"
+	case reflect.Struct:
+		elems := make([]string, rv.NumField())
+		for i := range elems {
+			elems[i] = formatABIValue(rv.Field(i).Interface())
+		}
+		return "(" + strings.Join(elems, ",") + ")"
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePackedArg(t *testing.T) {
+	tests := []struct {
+		typ, s  string
+		want    interface{}
+		wantErr bool
+	}{
+		{typ: "address", s: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", want: common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")},
+		{typ: "address", s: "0x1234", wantErr: true},
+		{typ: "bool", s: "true", want: true},
+		{typ: "bool", s: "yes", wantErr: true},
+		{typ: "string", s: " hello ", want: "hello"},
+		{typ: "bytes", s: "0xdead", want: []byte{0xde, 0xad}},
+		{typ: "bytes2", s: "0xbeef", want: [2]byte{0xbe, 0xef}},
+		{typ: "bytes2", s: "0xbe", wantErr: true},
+		{typ: "uint256", s: "42", want: big.NewInt(42)},
+		{typ: "uint8", s: "0xff", want: big.NewInt(255)},
+		{typ: "uint8", s: "256", wantErr: true},
+		{typ: "uint8", s: "-1", wantErr: true},
+		{typ: "int8", s: "-128", want: big.NewInt(-128)},
+		{typ: "int8", s: "128", wantErr: true},
+		{typ: "uint256", s: "lots", wantErr: true},
+		{typ: "notatype", s: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePackedArg(tt.typ, tt.s)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("parsePackedArg(%q, %q) got err %v; want error = %t", tt.typ, tt.s, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got, cmp.Comparer(func(a, b *big.Int) bool { return a.Cmp(b) == 0 })); diff != "" {
+			t.Errorf("parsePackedArg(%q, %q) diff (-want +got):\n%s", tt.typ, tt.s, diff)
+		}
+	}
+}
+
+func TestParseFunctionSignature(t *testing.T) {
+	tests := []struct {
+		sig, wantSig string
+		wantSelector string
+		wantNOutputs int
+		wantErr      bool
+	}{
+		{
+			sig:          "balanceOf(address)(uint256)",
+			wantSig:      "balanceOf(address)",
+			wantSelector: "70a08231",
+			wantNOutputs: 1,
+		},
+		{
+			sig:          "transferFrom(address from, address to, uint256 tokenId)",
+			wantSig:      "transferFrom(address,address,uint256)",
+			wantSelector: "23b872dd",
+		},
+		{
+			sig:          "f((uint256,address)[] items, bytes32) returns (bool, (uint8,string))",
+			wantSig:      "f((uint256,address)[],bytes32)",
+			wantNOutputs: 2,
+		},
+		{
+			sig:          "totalSupply()(uint256)",
+			wantSig:      "totalSupply()",
+			wantSelector: "18160ddd",
+			wantNOutputs: 1,
+		},
+		{sig: "noParens", wantErr: true},
+		{sig: "(uint256)", wantErr: true},
+		{sig: "f(uint256", wantErr: true},
+		{sig: "f(notatype)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		m, err := parseFunctionSignature(tt.sig)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("parseFunctionSignature(%q) got err %v; want error = %t", tt.sig, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got := m.Sig; got != tt.wantSig {
+			t.Errorf("parseFunctionSignature(%q).Sig got %q; want %q", tt.sig, got, tt.wantSig)
+		}
+		if tt.wantSelector != "" {
+			if got := hex.EncodeToString(m.ID); got != tt.wantSelector {
+				t.Errorf("parseFunctionSignature(%q).ID got %s; want %s", tt.sig, got, tt.wantSelector)
+			}
+		}
+		if got := len(m.Outputs); got != tt.wantNOutputs {
+			t.Errorf("parseFunctionSignature(%q) got %d outputs; want %d", tt.sig, got, tt.wantNOutputs)
+		}
+	}
+}
+
+func TestABIValueRoundTrip(t *testing.T) {
+	m, err := parseFunctionSignature("f(uint8,int256,address,bool,string,bytes,bytes4,uint256[],(uint64,address[2]),bytes32[])")
+	if err != nil {
+		t.Fatalf("parseFunctionSignature() error %v", err)
+	}
+
+	in := []string{
+		"255",
+		"-42",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"true",
+		"hello",
+		"0xdeadbeef",
+		"0xcafebabe",
+		"[1,2,3]",
+		"(7,[0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359])",
+		"[]",
+	}
+	vals, err := parseABIValues(m.Inputs, in)
+	if err != nil {
+		t.Fatalf("parseABIValues() error %v", err)
+	}
+	packed, err := m.Inputs.Pack(vals...)
+	if err != nil {
+		t.Fatalf("Pack() error %v", err)
+	}
+	unpacked, err := m.Inputs.Unpack(packed)
+	if err != nil {
+		t.Fatalf("Unpack() error %v", err)
+	}
+
+	var got []string
+	for _, v := range unpacked {
+		got = append(got, formatABIValue(v))
+	}
+	if diff := cmp.Diff(in, got); diff != "" {
+		t.Errorf("formatABIValue(Unpack(Pack(parseABIValues()))) diff (-want +got):\n%s", diff)
+	}
+
+	for _, bad := range [][]string{
+		{"256"},
+		{"[1,2"},
+		{"(1,2)"},
+	} {
+		m, err := parseFunctionSignature("f(uint8)")
+		if err != nil {
+			t.Fatalf("parseFunctionSignature() error %v", err)
+		}
+		if _, err := parseABIValues(m.Inputs, bad); err == nil {
+			t.Errorf("parseABIValues(uint8, %q) got nil error; want error", bad)
+		}
+	}
+}
@@ -0,0 +1,654 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Transfers ERC20, ERC721, or ERC1155 tokens to recipients listed in a CSV file, in batches."
+
+	cmd := &cobra.Command{
+		Use:   "airdrop --token <address> --csv <file>",
+		Short: short,
+		Long: short + `
+
+The CSV file MUST have a header row with an "address" column, of hex addresses or ENS names, and, depending on --standard, "amount" (erc20), "id" (erc721), or "id" and "amount" (erc1155) columns. IDs and amounts are integers, in decimal or 0x-prefixed hex, with amounts in the token's smallest unit.
+
+ERC20 transfers are batched through a Disperse contract's disperseToken(), with --batch-size recipients per transaction, which requires that the sender has approved the contract to spend the total amount. Multicall contracts aren't used because they'd be the sender of the transfers, which requires approving a contract that anyone can call. ERC721 tokens are therefore transferred one per transaction, and ERC1155 tokens with a single safeBatchTransferFrom() per recipient.
+
+Transactions are sent one at a time, each waiting for --confirm confirmations, and progress is recorded in the --progress file both when a transaction is sent and once it's confirmed. Rerunning the same command resumes from the first incomplete batch, first waiting for any transaction that was sent but not confirmed, so no recipient receives tokens twice. A progress file can't be reused with a different CSV file, token, or sender.
+
+With --dry-run, the gas of each remaining batch is estimated and the expected cost reported, but nothing is sent. Otherwise, a CSV report of all batches is output once they're confirmed.`,
+		RunE: airdrop,
+		Args: cobra.NoArgs,
+	}
+	addRPCFlag(cmd)
+	addSignerFlags(cmd)
+	addFeeFlags(cmd)
+	cmd.Flags().String("token", "", "Address of the token contract")
+	cmd.Flags().String("csv", "", "CSV file of recipients")
+	cmd.Flags().String("standard", "erc20", "Token standard: erc20, erc721, or erc1155")
+	cmd.Flags().String("disperse", "0xD152f549545093347A162Dce210e7293f1452150", "Address of the Disperse contract used for ERC20 batches")
+	cmd.Flags().Int("batch-size", 200, "Maximum number of recipients per ERC20 batch")
+	cmd.Flags().Bool("dry-run", false, "Estimate gas without sending any transactions")
+	cmd.Flags().String("progress", "", "File in which progress is recorded; defaults to the CSV file with a .progress.json suffix")
+	cmd.Flags().Uint64("confirm", 1, "Number of confirmations to wait for after each transaction")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// airdrop implements the `ethier airdrop` command.
+func airdrop(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	tokenHex, err := flags.GetString("token")
+	if err != nil {
+		return err
+	}
+	csvPath, err := flags.GetString("csv")
+	if err != nil {
+		return err
+	}
+	standard, err := flags.GetString("standard")
+	if err != nil {
+		return err
+	}
+	disperseHex, err := flags.GetString("disperse")
+	if err != nil {
+		return err
+	}
+	batchSize, err := flags.GetInt("batch-size")
+	if err != nil {
+		return err
+	}
+	dryRun, err := flags.GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+	progressPath, err := flags.GetString("progress")
+	if err != nil {
+		return err
+	}
+	confirm, err := flags.GetUint64("confirm")
+	if err != nil {
+		return err
+	}
+
+	token, err := eth.ParseAddress(tokenHex)
+	if err != nil {
+		return fmt.Errorf("--token: %v", err)
+	}
+	disperse, err := eth.ParseAddress(disperseHex)
+	if err != nil {
+		return fmt.Errorf("--disperse: %v", err)
+	}
+	if csvPath == "" {
+		return errors.New("--csv required")
+	}
+	if progressPath == "" {
+		progressPath = strings.TrimSuffix(csvPath, filepath.Ext(csvPath)) + ".progress.json"
+	}
+	if batchSize < 1 {
+		return fmt.Errorf("invalid --batch-size %d", batchSize)
+	}
+	if confirm == 0 {
+		return errors.New("--confirm MUST be at least 1 for progress to be recorded")
+	}
+	fees := new(txParams)
+	if err := feesFromFlags(cmd, fees); err != nil {
+		return err
+	}
+
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return fmt.Errorf("open --csv: %v", err)
+	}
+	rows, err := readAirdropCSV(f, standard)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %v", csvPath, err)
+	}
+
+	signer, err := existingSignerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := resolveRecipients(ctx, &ensClient{caller: client, registry: eth.ENSRegistry}, rows); err != nil {
+		return err
+	}
+	batches, err := airdropBatches(standard, token, disperse, signer.Address(), rows, batchSize)
+	if err != nil {
+		return err
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("get chain ID: %v", err)
+	}
+	progress, err := loadAirdropProgress(progressPath, airdropFingerprint(chainID, signer.Address(), batches))
+	if err != nil {
+		return err
+	}
+
+	if standard == "erc20" {
+		if err := checkERC20Funds(ctx, client, token, signer.Address(), disperse, progress.remaining(batches)); err != nil {
+			return err
+		}
+	}
+
+	a := &airdropper{
+		client:   client,
+		signer:   signer,
+		chainID:  chainID,
+		fees:     fees,
+		confirm:  confirm,
+		poll:     4 * time.Second,
+		progress: progress,
+	}
+	if dryRun {
+		return a.dryRun(ctx, os.Stdout, batches)
+	}
+	if err := a.run(ctx, batches); err != nil {
+		return err
+	}
+	return writeAirdropReport(os.Stdout, batches, progress)
+}
+
+// An airdropRow is a single recipient from the CSV file.
+type airdropRow struct {
+	// line is the 1-indexed CSV record, including the header.
+	line int
+	// name is the recipient as it appears in the CSV, which MAY be an ENS
+	// name.
+	name       string
+	to         common.Address
+	id, amount *big.Int
+}
+
+// readAirdropCSV parses the CSV file, requiring the columns of the token
+// standard. Recipients that aren't hex addresses are assumed to be ENS names,
+// which are resolved separately with resolveRecipients().
+func readAirdropCSV(r io.Reader, standard string) ([]*airdropRow, error) {
+	var cols []string
+	switch standard {
+	case "erc20":
+		cols = []string{"amount"}
+	case "erc721":
+		cols = []string{"id"}
+	case "erc1155":
+		cols = []string{"id", "amount"}
+	default:
+		return nil, fmt.Errorf("unsupported token standard %q", standard)
+	}
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read CSV: %v", err)
+	}
+	if len(records) < 2 {
+		return nil, errors.New("no recipients; the first row MUST be a header")
+	}
+
+	idx := make(map[string]int)
+	for i, h := range records[0] {
+		idx[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, c := range append([]string{"address"}, cols...) {
+		if _, ok := idx[c]; !ok {
+			return nil, fmt.Errorf("missing %q column for %s", c, standard)
+		}
+	}
+
+	rows := make([]*airdropRow, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		row := &airdropRow{
+			line: i + 2,
+			name: strings.TrimSpace(rec[idx["address"]]),
+		}
+		if !strings.Contains(row.name, ".") {
+			if row.to, err = eth.ParseAddress(row.name); err != nil {
+				return nil, fmt.Errorf("line %d: %v", row.line, err)
+			}
+		}
+
+		for _, c := range cols {
+			s := strings.TrimSpace(rec[idx[c]])
+			v, ok := new(big.Int).SetString(s, 0)
+			if !ok || v.Sign() == -1 {
+				return nil, fmt.Errorf("line %d: invalid %s %q", row.line, c, s)
+			}
+			switch c {
+			case "id":
+				row.id = v
+			case "amount":
+				row.amount = v
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// resolveRecipients resolves the addresses of all rows with ENS names.
+func resolveRecipients(ctx context.Context, ens *ensClient, rows []*airdropRow) error {
+	resolved := make(map[string]common.Address)
+	for _, r := range rows {
+		if !strings.Contains(r.name, ".") {
+			continue
+		}
+		addr, ok := resolved[r.name]
+		if !ok {
+			var err error
+			if addr, err = ens.address(ctx, r.name); err != nil {
+				return fmt.Errorf("line %d: resolve %q: %v", r.line, r.name, err)
+			}
+			if addr == (common.Address{}) {
+				return fmt.Errorf("line %d: %q has no address record", r.line, r.name)
+			}
+			resolved[r.name] = addr
+			log.Printf("Resolved %s to %v", r.name, addr)
+		}
+		r.to = addr
+	}
+	return nil
+}
+
+// An airdropBatch is a single transaction of an airdrop.
+type airdropBatch struct {
+	rows []*airdropRow
+	to   common.Address
+	data []byte
+}
+
+// lines returns the CSV lines included in the batch, with consecutive lines
+// as ranges, e.g. "2-201" or "3 7-8".
+func (b *airdropBatch) lines() string {
+	var parts []string
+	for i := 0; i < len(b.rows); {
+		j := i
+		for j+1 < len(b.rows) && b.rows[j+1].line == b.rows[j].line+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(b.rows[i].line))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", b.rows[i].line, b.rows[j].line))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, " ")
+}
+
+// airdropBatches groups the rows into transactions, as described by the
+// `ethier airdrop` help.
+func airdropBatches(standard string, token, disperse, from common.Address, rows []*airdropRow, batchSize int) ([]*airdropBatch, error) {
+	var batches []*airdropBatch
+
+	switch standard {
+	case "erc20":
+		for i := 0; i < len(rows); i += batchSize {
+			end := i + batchSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+			var (
+				to      []common.Address
+				amounts []*big.Int
+			)
+			for _, r := range rows[i:end] {
+				to = append(to, r.to)
+				amounts = append(amounts, r.amount)
+			}
+			_, data, err := packValues("disperseToken(address,address[],uint256[])", token, to, amounts)
+			if err != nil {
+				return nil, err
+			}
+			batches = append(batches, &airdropBatch{rows: rows[i:end], to: disperse, data: data})
+		}
+
+	case "erc721":
+		for _, r := range rows {
+			_, data, err := packValues("safeTransferFrom(address,address,uint256)", from, r.to, r.id)
+			if err != nil {
+				return nil, err
+			}
+			batches = append(batches, &airdropBatch{rows: []*airdropRow{r}, to: token, data: data})
+		}
+
+	case "erc1155":
+		byRecipient := make(map[common.Address]*airdropBatch)
+		for _, r := range rows {
+			b, ok := byRecipient[r.to]
+			if !ok {
+				b = &airdropBatch{to: token}
+				byRecipient[r.to] = b
+				batches = append(batches, b)
+			}
+			b.rows = append(b.rows, r)
+		}
+		for _, b := range batches {
+			var ids, amounts []*big.Int
+			for _, r := range b.rows {
+				ids = append(ids, r.id)
+				amounts = append(amounts, r.amount)
+			}
+			_, data, err := packValues("safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)", from, b.rows[0].to, ids, amounts, []byte{})
+			if err != nil {
+				return nil, err
+			}
+			b.data = data
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported token standard %q", standard)
+	}
+	return batches, nil
+}
+
+// airdropFingerprint returns a hash identifying the exact set of transactions
+// of an airdrop, such that progress can't be resumed with different inputs.
+func airdropFingerprint(chainID *big.Int, from common.Address, batches []*airdropBatch) common.Hash {
+	parts := [][]byte{chainID.Bytes(), from.Bytes()}
+	for _, b := range batches {
+		parts = append(parts, b.to.Bytes(), crypto.Keccak256(b.data))
+	}
+	return crypto.Keccak256Hash(parts...)
+}
+
+// Statuses of airdrop batches.
+const (
+	batchPending  = "pending"
+	batchSuccess  = "success"
+	batchReverted = "reverted"
+)
+
+// airdropProgress is the resumable state of an airdrop, keyed by batch index.
+type airdropProgress struct {
+	Fingerprint common.Hash                `json:"fingerprint"`
+	Batches     map[int]*airdropBatchState `json:"batches"`
+
+	path string
+}
+
+// airdropBatchState is the state of a single batch of an airdrop.
+type airdropBatchState struct {
+	Tx      common.Hash `json:"tx"`
+	Status  string      `json:"status"`
+	Block   uint64      `json:"block,omitempty"`
+	GasUsed uint64      `json:"gasUsed,omitempty"`
+}
+
+// loadAirdropProgress reads the progress file if it exists, confirming that it
+// has the same fingerprint, otherwise it returns empty progress to be saved at
+// the path.
+func loadAirdropProgress(path string, fingerprint common.Hash) (*airdropProgress, error) {
+	p := &airdropProgress{
+		Fingerprint: fingerprint,
+		Batches:     make(map[int]*airdropBatchState),
+		path:        path,
+	}
+
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read progress: %v", err)
+	}
+	if err := json.Unmarshal(buf, p); err != nil {
+		return nil, fmt.Errorf("decode progress %q: %v", path, err)
+	}
+	if p.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("progress %q is from a different airdrop; use a different --progress file or remove it", path)
+	}
+	log.Printf("Resuming airdrop from %s", path)
+	return p, nil
+}
+
+// save atomically writes the progress to its file.
+func (p *airdropProgress) save() error {
+	buf, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode progress: %v", err)
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0644); err != nil {
+		return fmt.Errorf("write progress: %v", err)
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		return fmt.Errorf("write progress: %v", err)
+	}
+	return nil
+}
+
+// remaining returns the batches that haven't been successfully confirmed.
+func (p *airdropProgress) remaining(batches []*airdropBatch) []*airdropBatch {
+	var out []*airdropBatch
+	for i, b := range batches {
+		if s, ok := p.Batches[i]; !ok || s.Status != batchSuccess {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// checkERC20Funds returns an error if the sender's balance of, or the Disperse
+// contract's allowance over, the token is less than the total amount of the
+// batches.
+func checkERC20Funds(ctx context.Context, caller ethereum.ContractCaller, token, from, disperse common.Address, batches []*airdropBatch) error {
+	total := new(big.Int)
+	for _, b := range batches {
+		for _, r := range b.rows {
+			total.Add(total, r.amount)
+		}
+	}
+
+	for _, c := range []struct {
+		desc string
+		sig  string
+		args []interface{}
+	}{
+		{"balance", "balanceOf(address)(uint256)", []interface{}{from}},
+		{"allowance for Disperse contract " + disperse.Hex(), "allowance(address,address)(uint256)", []interface{}{from, disperse}},
+	} {
+		vals, err := callContract(ctx, caller, token, c.sig, c.args...)
+		if err != nil {
+			return err
+		}
+		if len(vals) == 0 {
+			return fmt.Errorf("%v returned no data for %s; is it an ERC20 contract?", token, c.sig)
+		}
+		if got := vals[0].(*big.Int); got.Cmp(total) == -1 {
+			return fmt.Errorf("%v %s of %v is %v; need %v", from, c.desc, token, got, total)
+		}
+	}
+	return nil
+}
+
+// An airdropBackend is the subset of a client required by an airdropper.
+type airdropBackend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	TransactionByHash(context.Context, common.Hash) (*types.Transaction, bool, error)
+}
+
+// An airdropper sends airdrop batches, recording its progress.
+type airdropper struct {
+	client  airdropBackend
+	signer  eth.SignerBackend
+	chainID *big.Int
+	// fees are copied to the txParams of every transaction.
+	fees     *txParams
+	confirm  uint64
+	poll     time.Duration
+	progress *airdropProgress
+}
+
+// run sends all batches that haven't already succeeded, one at a time, after
+// waiting for any pending transaction from a previous run. It stops at the
+// first batch that reverts, which is retried if run is called again.
+func (a *airdropper) run(ctx context.Context, batches []*airdropBatch) error {
+	for i, b := range batches {
+		st, ok := a.progress.Batches[i]
+		if ok && st.Status == batchSuccess {
+			continue
+		}
+
+		var tx *types.Transaction
+		if ok && st.Status == batchPending {
+			t, _, err := a.client.TransactionByHash(ctx, st.Tx)
+			if err != nil && !errors.Is(err, ethereum.NotFound) {
+				return fmt.Errorf("batch %d: get pending transaction %v: %v", i, st.Tx, err)
+			}
+			if t == nil {
+				// Resending is only safe if the transaction was dropped,
+				// which can't be distinguished from it being unknown to
+				// this particular node.
+				return fmt.Errorf("batch %d: pending transaction %v not found; confirm that it was dropped and remove it from %s", i, st.Tx, a.progress.path)
+			}
+			log.Printf("Batch %d (lines %s): waiting for previously sent transaction %v", i, b.lines(), st.Tx)
+			tx = t
+		} else {
+			p := *a.fees
+			p.to = b.to
+			p.data = b.data
+			p.value = big.NewInt(0)
+			p.nonce = -1
+
+			t, err := sendTx(ctx, a.client, a.signer, a.chainID, &p)
+			if err != nil {
+				return fmt.Errorf("batch %d (lines %s): %v", i, b.lines(), err)
+			}
+			tx = t
+			st = &airdropBatchState{Tx: tx.Hash(), Status: batchPending}
+			a.progress.Batches[i] = st
+			if err := a.progress.save(); err != nil {
+				return err
+			}
+			log.Printf("Batch %d (lines %s): sent %v", i, b.lines(), tx.Hash())
+		}
+
+		r, err := waitConfirmed(ctx, a.client, tx, a.confirm, a.poll)
+		if err != nil {
+			return fmt.Errorf("batch %d: %v", i, err)
+		}
+		st.Block = r.BlockNumber.Uint64()
+		st.GasUsed = r.GasUsed
+		st.Status = batchSuccess
+		if r.Status != types.ReceiptStatusSuccessful {
+			st.Status = batchReverted
+		}
+		if err := a.progress.save(); err != nil {
+			return err
+		}
+		if st.Status == batchReverted {
+			return fmt.Errorf("batch %d (lines %s): transaction %v reverted", i, b.lines(), tx.Hash())
+		}
+		log.Printf("Batch %d: mined in block %d; gas used %d", i, st.Block, st.GasUsed)
+	}
+	return nil
+}
+
+// dryRun estimates the gas of each batch that hasn't already succeeded,
+// writing a CSV row for each, and logs the total expected cost.
+func (a *airdropper) dryRun(ctx context.Context, w io.Writer, batches []*airdropBatch) error {
+	tip, maxFee, err := txFees(ctx, a.client, a.fees)
+	if err != nil {
+		return err
+	}
+	head, err := a.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("get latest header: %v", err)
+	}
+	fee := new(big.Int).Add(head.BaseFee, tip)
+	if fee.Cmp(maxFee) == 1 {
+		fee = maxFee
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"batch", "lines", "recipients", "gas"}); err != nil {
+		return err
+	}
+	total := new(big.Int)
+	for i, b := range batches {
+		if st, ok := a.progress.Batches[i]; ok && st.Status == batchSuccess {
+			continue
+		}
+		gas, err := a.client.EstimateGas(ctx, ethereum.CallMsg{
+			From:      a.signer.Address(),
+			To:        &b.to,
+			GasFeeCap: maxFee,
+			GasTipCap: tip,
+			Data:      b.data,
+		})
+		if err != nil {
+			return fmt.Errorf("batch %d (lines %s): estimate gas: %v", i, b.lines(), err)
+		}
+		total.Add(total, new(big.Int).SetUint64(gas))
+		if err := out.Write([]string{strconv.Itoa(i), b.lines(), strconv.Itoa(len(b.rows)), strconv.FormatUint(gas, 10)}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return err
+	}
+
+	ether := func(gasPrice *big.Int) string {
+		wei := new(big.Int).Mul(total, gasPrice)
+		return formatDecimal(new(big.Rat).SetFrac(wei, big.NewInt(params.Ether)))
+	}
+	log.Printf("Estimated %v gas; ≈%s ETH at the current base fee plus priority fee, at most %s ETH", total, ether(fee), ether(maxFee))
+	return nil
+}
+
+// writeAirdropReport writes a CSV report of the state of each batch.
+func writeAirdropReport(w io.Writer, batches []*airdropBatch, progress *airdropProgress) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"batch", "lines", "recipients", "tx", "block", "gasUsed", "status"}); err != nil {
+		return err
+	}
+	for i, b := range batches {
+		row := []string{strconv.Itoa(i), b.lines(), strconv.Itoa(len(b.rows)), "", "", "", ""}
+		if st, ok := progress.Batches[i]; ok {
+			row[3] = st.Tx.Hex()
+			row[4] = strconv.FormatUint(st.Block, 10)
+			row[5] = strconv.FormatUint(st.GasUsed, 10)
+			row[6] = st.Status
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/ethtest"
+)
+
+func TestReadAirdropCSV(t *testing.T) {
+	tests := []struct {
+		name, standard, csv string
+		want                []*airdropRow
+		wantErr             bool
+	}{
+		{
+			name:     "erc20",
+			standard: "erc20",
+			csv:      "Address,Amount\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,100\nvitalik.eth,0x10\n",
+			want: []*airdropRow{
+				{line: 2, name: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", to: common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"), amount: big.NewInt(100)},
+				{line: 3, name: "vitalik.eth", amount: big.NewInt(16)},
+			},
+		},
+		{
+			name:     "erc1155",
+			standard: "erc1155",
+			csv:      "id,address,amount\n7,0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,2\n",
+			want: []*airdropRow{
+				{line: 2, name: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", to: common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"), id: big.NewInt(7), amount: big.NewInt(2)},
+			},
+		},
+		{
+			name:     "missing column",
+			standard: "erc721",
+			csv:      "address,amount\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,1\n",
+			wantErr:  true,
+		},
+		{
+			name:     "negative amount",
+			standard: "erc20",
+			csv:      "address,amount\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,-1\n",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid address",
+			standard: "erc20",
+			csv:      "address,amount\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAe,1\n",
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported standard",
+			standard: "erc777",
+			csv:      "address,amount\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,1\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAirdropCSV(strings.NewReader(tt.csv), tt.standard)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("readAirdropCSV() got err %v; want error = %t", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(airdropRow{}), cmp.Comparer(func(a, b *big.Int) bool {
+				return a.Cmp(b) == 0
+			})); diff != "" {
+				t.Errorf("readAirdropCSV() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAirdropBatches(t *testing.T) {
+	token := common.HexToAddress("0x01")
+	disperse := common.HexToAddress("0x02")
+	from := common.HexToAddress("0x03")
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+
+	rows := []*airdropRow{
+		{line: 2, to: alice, id: big.NewInt(1), amount: big.NewInt(10)},
+		{line: 3, to: bob, id: big.NewInt(2), amount: big.NewInt(20)},
+		{line: 4, to: alice, id: big.NewInt(3), amount: big.NewInt(30)},
+	}
+
+	tests := []struct {
+		standard  string
+		wantTo    []common.Address
+		wantLines []string
+		wantSig   string
+	}{
+		{
+			standard:  "erc20",
+			wantTo:    []common.Address{disperse, disperse},
+			wantLines: []string{"2-3", "4"},
+			wantSig:   "disperseToken(address,address[],uint256[])",
+		},
+		{
+			standard:  "erc721",
+			wantTo:    []common.Address{token, token, token},
+			wantLines: []string{"2", "3", "4"},
+			wantSig:   "safeTransferFrom(address,address,uint256)",
+		},
+		{
+			standard:  "erc1155",
+			wantTo:    []common.Address{token, token},
+			wantLines: []string{"2 4", "3"},
+			wantSig:   "safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.standard, func(t *testing.T) {
+			batches, err := airdropBatches(tt.standard, token, disperse, from, rows, 2)
+			if err != nil {
+				t.Fatalf("airdropBatches() error %v", err)
+			}
+			method, err := parseFunctionSignature(tt.wantSig)
+			if err != nil {
+				t.Fatalf("parseFunctionSignature(%q) error %v", tt.wantSig, err)
+			}
+
+			var gotTo []common.Address
+			var gotLines []string
+			for _, b := range batches {
+				gotTo = append(gotTo, b.to)
+				gotLines = append(gotLines, b.lines())
+				if !bytes.HasPrefix(b.data, method.ID) {
+					t.Errorf("batch data %#x doesn't start with %s selector", b.data, tt.wantSig)
+				}
+			}
+			if diff := cmp.Diff(tt.wantTo, gotTo); diff != "" {
+				t.Errorf("batch recipients diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantLines, gotLines); diff != "" {
+				t.Errorf("batch lines diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("erc1155 arguments", func(t *testing.T) {
+		batches, err := airdropBatches("erc1155", token, disperse, from, rows, 2)
+		if err != nil {
+			t.Fatalf("airdropBatches() error %v", err)
+		}
+		got, err := unpackCall(mustParseFunctionSignature(t, "safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)"), batches[0].data, true)
+		if err != nil {
+			t.Fatalf("unpackCall() error %v", err)
+		}
+		want := []string{from.Hex(), alice.Hex(), "[1,3]", "[10,30]", "0x"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("safeBatchTransferFrom() arguments diff (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func mustParseFunctionSignature(t *testing.T, sig string) abi.Method {
+	t.Helper()
+	m, err := parseFunctionSignature(sig)
+	if err != nil {
+		t.Fatalf("parseFunctionSignature(%q) error %v", sig, err)
+	}
+	return m
+}
+
+func TestAirdropper(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, 1)
+	signer := newFundedSigner(ctx, t, sim)
+
+	// Addresses without code accept any calldata, which suffices to test
+	// sending and resumption.
+	var batches []*airdropBatch
+	for i := 0; i < 3; i++ {
+		batches = append(batches, &airdropBatch{
+			rows: []*airdropRow{{line: i + 2}},
+			to:   common.BigToAddress(big.NewInt(int64(0x100 + i))),
+			data: []byte{byte(i)},
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "progress.json")
+	fingerprint := airdropFingerprint(big.NewInt(1337), signer.Address(), batches)
+	newAirdropper := func(t *testing.T) *airdropper {
+		t.Helper()
+		p, err := loadAirdropProgress(path, fingerprint)
+		if err != nil {
+			t.Fatalf("loadAirdropProgress() error %v", err)
+		}
+		return &airdropper{
+			client:   sim,
+			signer:   signer,
+			chainID:  big.NewInt(1337),
+			fees:     new(txParams),
+			confirm:  1,
+			poll:     time.Millisecond,
+			progress: p,
+		}
+	}
+
+	nonce := func(t *testing.T) uint64 {
+		t.Helper()
+		n, err := sim.PendingNonceAt(ctx, signer.Address())
+		if err != nil {
+			t.Fatalf("PendingNonceAt() error %v", err)
+		}
+		return n
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := newAirdropper(t).dryRun(ctx, &buf, batches); err != nil {
+			t.Fatalf("dryRun() error %v", err)
+		}
+		if got, want := strings.Count(buf.String(), "\n"), 4; got != want {
+			t.Errorf("dryRun() output %q has %d lines; want %d", buf.String(), got, want)
+		}
+		if got := nonce(t); got != 0 {
+			t.Errorf("dryRun() sent transactions; nonce got %d; want 0", got)
+		}
+	})
+
+	t.Run("resume pending", func(t *testing.T) {
+		// Simulate a previous run that sent the first batch but exited before
+		// it was confirmed.
+		a := newAirdropper(t)
+		tx, err := sendTx(ctx, sim, signer, a.chainID, &txParams{
+			to:    batches[0].to,
+			data:  batches[0].data,
+			value: big.NewInt(0),
+			nonce: -1,
+		})
+		if err != nil {
+			t.Fatalf("sendTx() error %v", err)
+		}
+		a.progress.Batches[0] = &airdropBatchState{Tx: tx.Hash(), Status: batchPending}
+		if err := a.progress.save(); err != nil {
+			t.Fatalf("save() error %v", err)
+		}
+
+		a = newAirdropper(t)
+		if err := a.run(ctx, batches); err != nil {
+			t.Fatalf("run() error %v", err)
+		}
+		if got, want := nonce(t), uint64(len(batches)); got != want {
+			t.Errorf("after run(), nonce got %d; want %d", got, want)
+		}
+		if got := a.progress.Batches[0].Tx; got != tx.Hash() {
+			t.Errorf("first batch transaction got %v; want previously sent %v", got, tx.Hash())
+		}
+
+		var buf bytes.Buffer
+		if err := writeAirdropReport(&buf, batches, a.progress); err != nil {
+			t.Fatalf("writeAirdropReport() error %v", err)
+		}
+		if got, want := strings.Count(buf.String(), ","+batchSuccess+"\n"), len(batches); got != want {
+			t.Errorf("writeAirdropReport() got %d successful batches; want %d; report:\n%s", got, want, buf.String())
+		}
+	})
+
+	t.Run("resume complete", func(t *testing.T) {
+		if err := newAirdropper(t).run(ctx, batches); err != nil {
+			t.Fatalf("run() error %v", err)
+		}
+		if got, want := nonce(t), uint64(len(batches)); got != want {
+			t.Errorf("after rerunning run(), nonce got %d; want %d; i.e. no new transactions", got, want)
+		}
+	})
+
+	t.Run("different airdrop", func(t *testing.T) {
+		if _, err := loadAirdropProgress(path, common.Hash{}); err == nil {
+			t.Errorf("loadAirdropProgress() with different fingerprint got nil error")
+		}
+	})
+}
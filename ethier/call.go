@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Calls a contract function, without a transaction, and prints the decoded result."
+
+	cmd := &cobra.Command{
+		Use:   "call <address> <signature> [args...]",
+		Short: short,
+		Long: short + `
+
+The signature is human-readable, with return types in a second set of parentheses, e.g. 'balanceOf(address)(uint256)'. Arguments are parsed according to their types: integers as decimal or 0x-prefixed hex, bytes as 0x-prefixed hex, arrays as [a,b,c], and tuples as (a,b,c). Each return value is printed on its own line; if no return types are specified, the raw return data is printed instead.`,
+		RunE: call,
+		Args: cobra.MinimumNArgs(2),
+	}
+	addRPCFlag(cmd)
+	cmd.Flags().String("from", "", "Address from which the call is made")
+	cmd.Flags().Int64("block", -1, "Block number at which to call; -1 = latest")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// call implements the `ethier call` command.
+func call(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	from, err := flags.GetString("from")
+	if err != nil {
+		return err
+	}
+	block, err := flags.GetInt64("block")
+	if err != nil {
+		return err
+	}
+
+	to, err := eth.ParseAddress(args[0])
+	if err != nil {
+		return fmt.Errorf("contract address: %v", err)
+	}
+	method, data, err := packCall(args[1], args[2:])
+	if err != nil {
+		return err
+	}
+
+	msg := ethereum.CallMsg{
+		To:   &to,
+		Data: data,
+	}
+	if from != "" {
+		if msg.From, err = eth.ParseAddress(from); err != nil {
+			return fmt.Errorf("--from: %v", err)
+		}
+	}
+	var blockNum *big.Int
+	if block >= 0 {
+		blockNum = big.NewInt(block)
+	}
+
+	ctx := context.Background()
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ret, err := client.CallContract(ctx, msg, blockNum)
+	if err != nil {
+		return fmt.Errorf("call %s: %v", method.Sig, err)
+	}
+
+	out, err := unpackReturn(method, ret)
+	if err != nil {
+		return err
+	}
+	for _, o := range out {
+		fmt.Println(o)
+	}
+	return nil
+}
+
+// packCall parses the function signature and returns the method along with
+// the calldata for calling it with the arguments.
+func packCall(sig string, args []string) (abi.Method, []byte, error) {
+	method, err := parseFunctionSignature(sig)
+	if err != nil {
+		return abi.Method{}, nil, err
+	}
+	vals, err := parseABIValues(method.Inputs, args)
+	if err != nil {
+		return abi.Method{}, nil, fmt.Errorf("%s: %v", method.Sig, err)
+	}
+	packed, err := method.Inputs.Pack(vals...)
+	if err != nil {
+		return abi.Method{}, nil, fmt.Errorf("pack arguments to %s: %v", method.Sig, err)
+	}
+	return method, append(append([]byte{}, method.ID...), packed...), nil
+}
+
+// unpackReturn returns human-readable representations of each of the method's
+// return values, or the hex-encoded data if the method has no outputs.
+func unpackReturn(method abi.Method, data []byte) ([]string, error) {
+	if len(method.Outputs) == 0 {
+		return []string{hexutil.Encode(data)}, nil
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty return data; is the address a contract?")
+	}
+	vals, err := method.Outputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("unpack return data: %v", err)
+	}
+	out := make([]string, len(vals))
+	for i, v := range vals {
+		out[i] = formatABIValue(v)
+	}
+	return out, nil
+}
+
+// callContract calls the function, with human-readable signature as for ethier
+// call, on the contract at the address, with already-parsed arguments. Empty
+// return data, typically from a contract that doesn't implement the function,
+// results in nil values.
+func callContract(ctx context.Context, caller ethereum.ContractCaller, to common.Address, sig string, args ...interface{}) ([]interface{}, error) {
+	method, data, err := packValues(sig, args...)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := caller.CallContract(ctx, ethereum.CallMsg{
+		To:   &to,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("call %s on %v: %v", method.Sig, to, err)
+	}
+	if len(ret) == 0 {
+		return nil, nil
+	}
+	vals, err := method.Outputs.Unpack(ret)
+	if err != nil {
+		return nil, fmt.Errorf("unpack return data of %s: %v", method.Sig, err)
+	}
+	return vals, nil
+}
+
+// packValues is equivalent to packCall() except that the arguments are
+// already-parsed values instead of strings.
+func packValues(sig string, args ...interface{}) (abi.Method, []byte, error) {
+	method, err := parseFunctionSignature(sig)
+	if err != nil {
+		return abi.Method{}, nil, err
+	}
+	packed, err := method.Inputs.Pack(args...)
+	if err != nil {
+		return abi.Method{}, nil, fmt.Errorf("pack arguments to %s: %v", method.Sig, err)
+	}
+	return method, append(append([]byte{}, method.ID...), packed...), nil
+}
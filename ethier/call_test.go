@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPackCallUnpackReturn(t *testing.T) {
+	m, data, err := packCall("balanceOf(address)(uint256)", []string{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"})
+	if err != nil {
+		t.Fatalf("packCall() error %v", err)
+	}
+	const wantData = "0x70a082310000000000000000000000005aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	if got := hexutil.Encode(data); got != wantData {
+		t.Errorf("packCall() calldata got %s; want %s", got, wantData)
+	}
+
+	ret := common.LeftPadBytes([]byte{0x01, 0x00}, 32)
+	got, err := unpackReturn(m, ret)
+	if err != nil {
+		t.Fatalf("unpackReturn() error %v", err)
+	}
+	if diff := cmp.Diff([]string{"256"}, got); diff != "" {
+		t.Errorf("unpackReturn() diff (-want +got):\n%s", diff)
+	}
+	if _, err := unpackReturn(m, nil); err == nil {
+		t.Error("unpackReturn(<empty>) got nil error; want error")
+	}
+
+	if _, _, err := packCall("balanceOf(address)(uint256)", nil); err == nil {
+		t.Error("packCall() with missing argument got nil error; want error")
+	}
+
+	raw, _, err := packCall("foo()", nil)
+	if err != nil {
+		t.Fatalf("packCall() error %v", err)
+	}
+	got, err = unpackReturn(raw, []byte{0xab})
+	if err != nil {
+		t.Fatalf("unpackReturn() error %v", err)
+	}
+	if diff := cmp.Diff([]string{"0xab"}, got); diff != "" {
+		t.Errorf("unpackReturn(<no outputs>) diff (-want +got):\n%s", diff)
+	}
+}
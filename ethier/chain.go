@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	const short = "Prints the chain ID, latest block, fees, and sync status of the --rpc node."
+
+	cmd := &cobra.Command{
+		Use:   "chain",
+		Short: short,
+		Long: short + `
+
+Useful for sanity-checking an endpoint before using it with other commands. With --watch, a line is printed for every new block, as polled for at the --poll interval, until interrupted.`,
+		RunE: chain,
+		Args: cobra.NoArgs,
+	}
+	addRPCFlag(cmd)
+	cmd.Flags().Bool("watch", false, "Print a line for every new block until interrupted")
+	cmd.Flags().Duration("poll", 4*time.Second, "Interval at which new blocks are polled for with --watch")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// chain implements the `ethier chain` command.
+func chain(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	watch, err := flags.GetBool("watch")
+	if err != nil {
+		return err
+	}
+	poll, err := flags.GetDuration("poll")
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	info, err := fetchChainInfo(ctx, client)
+	if err != nil {
+		return err
+	}
+	if err := info.write(os.Stdout, time.Now()); err != nil {
+		return err
+	}
+	if !watch {
+		return nil
+	}
+	return watchChain(ctx, os.Stdout, client, poll)
+}
+
+// chainNames are human-readable names of common chain IDs.
+var chainNames = map[uint64]string{
+	1:        "mainnet",
+	5:        "goerli",
+	10:       "optimism",
+	137:      "polygon",
+	1337:     "development",
+	42161:    "arbitrum",
+	80001:    "mumbai",
+	11155111: "sepolia",
+}
+
+// A chainReader is the subset of ethclient.Client methods used by `ethier
+// chain`.
+type chainReader interface {
+	ethereum.ChainSyncReader
+	ChainID(context.Context) (*big.Int, error)
+	HeaderByNumber(context.Context, *big.Int) (*types.Header, error)
+	SuggestGasTipCap(context.Context) (*big.Int, error)
+}
+
+// chainInfo is a snapshot of the state of a chain, as seen by a node.
+type chainInfo struct {
+	chainID *big.Int
+	head    *types.Header
+	// tip is the suggested priority fee.
+	tip *big.Int
+	// sync is nil if the node isn't syncing.
+	sync *ethereum.SyncProgress
+}
+
+// fetchChainInfo returns the current chainInfo.
+func fetchChainInfo(ctx context.Context, client chainReader) (*chainInfo, error) {
+	var (
+		info = new(chainInfo)
+		err  error
+	)
+	if info.chainID, err = client.ChainID(ctx); err != nil {
+		return nil, fmt.Errorf("get chain ID: %v", err)
+	}
+	if info.head, err = client.HeaderByNumber(ctx, nil); err != nil {
+		return nil, fmt.Errorf("get latest block: %v", err)
+	}
+	if info.tip, err = client.SuggestGasTipCap(ctx); err != nil {
+		return nil, fmt.Errorf("get suggested priority fee: %v", err)
+	}
+	if info.sync, err = client.SyncProgress(ctx); err != nil {
+		return nil, fmt.Errorf("get sync status: %v", err)
+	}
+	return info, nil
+}
+
+// write writes the chainInfo as aligned key-value pairs, with the age of the
+// latest block relative to now.
+func (c *chainInfo) write(w io.Writer, now time.Time) error {
+	id := c.chainID.String()
+	if c.chainID.IsUint64() {
+		if name, ok := chainNames[c.chainID.Uint64()]; ok {
+			id += " (" + name + ")"
+		}
+	}
+
+	sync := "synced"
+	if s := c.sync; s != nil {
+		sync = fmt.Sprintf("syncing block %d of %d", s.CurrentBlock, s.HighestBlock)
+		if s.HighestBlock > s.StartingBlock {
+			pct := 100 * float64(s.CurrentBlock-s.StartingBlock) / float64(s.HighestBlock-s.StartingBlock)
+			sync += fmt.Sprintf(" (%.1f%%)", pct)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Chain ID:\t%s\n", id)
+	fmt.Fprintf(tw, "Latest block:\t%v (%s ago)\n", c.head.Number, blockAge(c.head, now))
+	fmt.Fprintf(tw, "Base fee:\t%s\n", formatGwei(c.head.BaseFee))
+	fmt.Fprintf(tw, "Priority fee:\t%s\n", formatGwei(c.tip))
+	fmt.Fprintf(tw, "Sync status:\t%s\n", sync)
+	return tw.Flush()
+}
+
+// watchChain polls for new blocks, printing a line for each, until ctx is
+// cancelled. Blocks mined between polls are fetched individually so none are
+// skipped.
+func watchChain(ctx context.Context, w io.Writer, client chainReader, poll time.Duration) error {
+	var last *big.Int
+	for {
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("get latest block: %v", err)
+		}
+
+		if head != nil && (last == nil || head.Number.Cmp(last) > 0) {
+			tip, err := client.SuggestGasTipCap(ctx)
+			if err != nil {
+				return fmt.Errorf("get suggested priority fee: %v", err)
+			}
+			next := new(big.Int).Set(head.Number)
+			if last != nil {
+				next.Add(last, big.NewInt(1))
+			}
+			for ; next.Cmp(head.Number) <= 0; next.Add(next, big.NewInt(1)) {
+				h := head
+				if next.Cmp(head.Number) != 0 {
+					if h, err = client.HeaderByNumber(ctx, next); err != nil {
+						return fmt.Errorf("get block %v: %v", next, err)
+					}
+				}
+				if _, err := fmt.Fprintln(w, watchLine(h, tip)); err != nil {
+					return err
+				}
+			}
+			last = head.Number
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(poll):
+		}
+	}
+}
+
+// watchLine returns the line printed by `ethier chain --watch` for the block,
+// along with the priority fee suggested at the time of polling.
+func watchLine(h *types.Header, tip *big.Int) string {
+	return fmt.Sprintf(
+		"%s block %v: gas used %d/%d; base fee %s; priority fee %s",
+		time.Unix(int64(h.Time), 0).UTC().Format(time.RFC3339),
+		h.Number, h.GasUsed, h.GasLimit, formatGwei(h.BaseFee), formatGwei(tip),
+	)
+}
+
+// blockAge returns the time since the block was mined, rounded to seconds.
+func blockAge(h *types.Header, now time.Time) time.Duration {
+	return now.Sub(time.Unix(int64(h.Time), 0)).Round(time.Second)
+}
+
+// formatGwei returns the wei amount formatted in gwei, or "n/a" if nil, as is the
+// case for the base fee of blocks before London.
+func formatGwei(wei *big.Int) string {
+	if wei == nil {
+		return "n/a"
+	}
+	return formatDecimal(new(big.Rat).SetFrac(wei, big.NewInt(params.GWei))) + " gwei"
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/ethtest"
+)
+
+// simChain adds the chainReader methods missing from a SimulatedBackend.
+type simChain struct {
+	*ethtest.SimulatedBackend
+	sync *ethereum.SyncProgress
+}
+
+func (simChain) ChainID(context.Context) (*big.Int, error) {
+	return big.NewInt(1337), nil
+}
+
+func (s simChain) SyncProgress(context.Context) (*ethereum.SyncProgress, error) {
+	return s.sync, nil
+}
+
+func TestChainInfo(t *testing.T) {
+	ctx := context.Background()
+	sim := simChain{SimulatedBackend: ethtest.NewSimulatedBackendTB(t, 1)}
+
+	info, err := fetchChainInfo(ctx, sim)
+	if err != nil {
+		t.Fatalf("fetchChainInfo() error %v", err)
+	}
+	now := time.Unix(int64(info.head.Time), 0).Add(12 * time.Second)
+
+	tests := []struct {
+		name     string
+		sync     *ethereum.SyncProgress
+		wantSync string
+	}{
+		{
+			name:     "synced",
+			wantSync: "synced",
+		},
+		{
+			name:     "syncing",
+			sync:     &ethereum.SyncProgress{StartingBlock: 100, CurrentBlock: 150, HighestBlock: 300},
+			wantSync: "syncing block 150 of 300 (25.0%)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info.sync = tt.sync
+			var buf bytes.Buffer
+			if err := info.write(&buf, now); err != nil {
+				t.Fatalf("write() error %v", err)
+			}
+
+			want := strings.Join([]string{
+				"Chain ID:      1337 (development)",
+				"Latest block:  " + info.head.Number.String() + " (12s ago)",
+				"Base fee:      " + formatGwei(info.head.BaseFee),
+				"Priority fee:  " + formatGwei(info.tip),
+				"Sync status:   " + tt.wantSync,
+				"",
+			}, "\n")
+			if diff := cmp.Diff(want, buf.String()); diff != "" {
+				t.Errorf("write() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWatchChain(t *testing.T) {
+	sim := simChain{SimulatedBackend: ethtest.NewSimulatedBackendTB(t, 1)}
+	for i := 0; i < 3; i++ {
+		sim.Commit()
+	}
+
+	// A cancelled context results in a single poll, which MUST print only the
+	// latest block.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var buf bytes.Buffer
+	if err := watchChain(ctx, &buf, sim, time.Hour); err != nil {
+		t.Fatalf("watchChain() error %v", err)
+	}
+
+	head, err := sim.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("HeaderByNumber(nil) error %v", err)
+	}
+	tip, err := sim.SuggestGasTipCap(context.Background())
+	if err != nil {
+		t.Fatalf("SuggestGasTipCap() error %v", err)
+	}
+	if got, want := buf.String(), watchLine(head, tip)+"\n"; got != want {
+		t.Errorf("watchChain() got %q; want %q", got, want)
+	}
+}
+
+func TestFormatGwei(t *testing.T) {
+	tests := []struct {
+		wei  *big.Int
+		want string
+	}{
+		{wei: nil, want: "n/a"},
+		{wei: big.NewInt(0), want: "0 gwei"},
+		{wei: big.NewInt(1_500_000_000), want: "1.5 gwei"},
+		{wei: big.NewInt(7), want: "0.000000007 gwei"},
+	}
+	for _, tt := range tests {
+		if got := formatGwei(tt.wei); got != tt.want {
+			t.Errorf("formatGwei(%v) got %q; want %q", tt.wei, got, tt.want)
+		}
+	}
+}
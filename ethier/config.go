@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Root flags selecting the configuration; see applyConfig().
+const (
+	configFlag  = "config"
+	networkFlag = "network"
+)
+
+// configFile is the name of the project-level configuration file.
+const configFile = ".ethier.yaml"
+
+func init() {
+	rootCmd.PersistentFlags().String(configFlag, "", "Configuration file; defaults to the first "+configFile+" found in the working directory or its parents, up to the Go module root")
+	rootCmd.PersistentFlags().String(networkFlag, "", "Network profile from the configuration file; defaults to its network field")
+	rootCmd.PersistentPreRunE = applyConfig
+}
+
+// An ethierConfig is the parsed contents of an .ethier.yaml file, e.g.
+//
+//	network: goerli
+//	solc:
+//	  optimize: true
+//	  optimizeRuns: 200
+//	networks:
+//	  mainnet:
+//	    rpc: https://eth-mainnet.alchemyapi.io/v2/${ALCHEMY_KEY}
+//	    keystore: keys/deployer.json
+//	    passwordFile: keys/deployer.pw
+//	    gas:
+//	      maxFee: 80
+//	      priorityFee: 1.5
+//	    etherscan:
+//	      apiKey: ${ETHERSCAN_API_KEY}
+//	  goerli:
+//	    rpc: http://localhost:8545
+//	    etherscan:
+//	      apiURL: https://api-goerli.etherscan.io/api
+//
+// Environment variables in string values are expanded, and relative file
+// paths are resolved against the directory containing the file.
+type ethierConfig struct {
+	// Network is the default network profile, used if --network isn't set.
+	Network  string                   `yaml:"network"`
+	Solc     solcConfig               `yaml:"solc"`
+	Networks map[string]networkConfig `yaml:"networks"`
+}
+
+// solcConfig defines the settings with which ethier runs solc.
+type solcConfig struct {
+	Optimize     *bool  `yaml:"optimize"`
+	OptimizeRuns *uint  `yaml:"optimizeRuns"`
+	EVMVersion   string `yaml:"evmVersion"`
+	ViaIR        *bool  `yaml:"viaIR"`
+}
+
+// networkConfig defines the settings specific to a single network.
+type networkConfig struct {
+	RPC          string `yaml:"rpc"`
+	Keystore     string `yaml:"keystore"`
+	PasswordFile string `yaml:"passwordFile"`
+	Gas          struct {
+		// MaxFee and PriorityFee are in gwei.
+		MaxFee            string  `yaml:"maxFee"`
+		PriorityFee       string  `yaml:"priorityFee"`
+		BaseFeeMultiplier *uint64 `yaml:"baseFeeMultiplier"`
+	} `yaml:"gas"`
+	Etherscan struct {
+		APIURL string `yaml:"apiURL"`
+		APIKey string `yaml:"apiKey"`
+	} `yaml:"etherscan"`
+}
+
+// findConfig returns the path of the first configFile in dir or its parents,
+// stopping at the Go module root. It returns an empty string if there is none.
+func findConfig(dir string) (string, error) {
+	for {
+		p := filepath.Join(dir, configFile)
+		_, err := os.Stat(p)
+		if err == nil {
+			return p, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("os.Stat(%q): %v", p, err)
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// readConfig parses the configuration file at path, expanding environment
+// variables and resolving relative paths.
+func readConfig(path string) (*ethierConfig, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %v", err)
+	}
+	c := new(ethierConfig)
+	if err := yaml.Unmarshal(buf, c); err != nil {
+		return nil, fmt.Errorf("parse config %q: %v", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		p = os.ExpandEnv(p)
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	for name, n := range c.Networks {
+		n.RPC = os.ExpandEnv(n.RPC)
+		n.Keystore = resolve(n.Keystore)
+		n.PasswordFile = resolve(n.PasswordFile)
+		n.Etherscan.APIURL = os.ExpandEnv(n.Etherscan.APIURL)
+		n.Etherscan.APIKey = os.ExpandEnv(n.Etherscan.APIKey)
+		c.Networks[name] = n
+	}
+	return c, nil
+}
+
+// flagDefaults returns the values, keyed by flag name, that the configuration
+// defines for the network. If network is empty, c.Network is used, and if both
+// are empty then only network-independent values are returned.
+func (c *ethierConfig) flagDefaults(network string) (map[string]string, error) {
+	vals := make(map[string]string)
+	set := func(flag, val string) {
+		if val != "" {
+			vals[flag] = val
+		}
+	}
+
+	s := c.Solc
+	if s.Optimize != nil {
+		set(optimizeFlag, strconv.FormatBool(*s.Optimize))
+	}
+	if s.OptimizeRuns != nil {
+		set(optimizeRunsFlag, strconv.FormatUint(uint64(*s.OptimizeRuns), 10))
+	}
+	set(evmVersionFlag, s.EVMVersion)
+	if s.ViaIR != nil {
+		set(viaIRFlag, strconv.FormatBool(*s.ViaIR))
+	}
+
+	if network == "" {
+		network = c.Network
+	}
+	if network == "" {
+		return vals, nil
+	}
+	n, ok := c.Networks[network]
+	if !ok {
+		names := make([]string, 0, len(c.Networks))
+		for k := range c.Networks {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown network %q; configured networks: %s", network, strings.Join(names, ", "))
+	}
+
+	set(rpcFlag, n.RPC)
+	set(keystoreFlag, n.Keystore)
+	set(passwordFileFlag, n.PasswordFile)
+	set("max-fee", n.Gas.MaxFee)
+	set("priority-fee", n.Gas.PriorityFee)
+	if m := n.Gas.BaseFeeMultiplier; m != nil {
+		set("base-fee-multiplier", strconv.FormatUint(*m, 10))
+	}
+	set("api-url", n.Etherscan.APIURL)
+	set("api-key", n.Etherscan.APIKey)
+	return vals, nil
+}
+
+// applyConfig reads the configuration file, if any, and uses its values for
+// any of the command's flags that weren't explicitly set, taking precedence
+// over environment-variable defaults. The configured keystore is only used by
+// commands that sign, and only if no other signer flag is set.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	path, err := flags.GetString(configFlag)
+	if err != nil {
+		return err
+	}
+	network, err := flags.GetString(networkFlag)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("os.Getwd(): %v", err)
+		}
+		if path, err = findConfig(pwd); err != nil {
+			return err
+		}
+	}
+	if path == "" {
+		if network != "" {
+			return fmt.Errorf("--%s %q requires a %s file", networkFlag, network, configFile)
+		}
+		return nil
+	}
+
+	c, err := readConfig(path)
+	if err != nil {
+		return err
+	}
+	vals, err := c.flagDefaults(network)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	// Commands such as keygen use --keystore as an output, so the configured
+	// key is limited to those with signer flags.
+	useKeystore := flags.Lookup(privateKeyFlag) != nil
+	for _, name := range []string{privateKeyFlag, keyFileFlag, keystoreFlag, accountFlag, mnemonicFlag, kmsFlag, remoteFlag, interactiveFlag} {
+		if f := flags.Lookup(name); f != nil && f.Changed {
+			useKeystore = false
+		}
+	}
+	if !useKeystore {
+		delete(vals, keystoreFlag)
+		delete(vals, passwordFileFlag)
+	}
+
+	for name, val := range vals {
+		f := flags.Lookup(name)
+		if f == nil || f.Changed {
+			continue
+		}
+		if err := f.Value.Set(val); err != nil {
+			return fmt.Errorf("%s: invalid value %q for --%s: %v", path, val, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+)
+
+const testConfig = `
+network: goerli
+solc:
+  optimize: true
+  optimizeRuns: 1000
+networks:
+  mainnet:
+    rpc: https://mainnet.example/${TEST_RPC_KEY}
+    keystore: keys/deployer.json
+    passwordFile: /abs/deployer.pw
+    gas:
+      maxFee: 80
+      priorityFee: 1.5
+      baseFeeMultiplier: 3
+  goerli:
+    rpc: http://localhost:8545
+    etherscan:
+      apiURL: https://api-goerli.etherscan.io/api
+`
+
+func TestApplyConfig(t *testing.T) {
+	t.Setenv("TEST_RPC_KEY", "secret")
+	dir := t.TempDir()
+	writeFile(t, dir, configFile, testConfig)
+	path := filepath.Join(dir, configFile)
+
+	// newCmd returns a command with the same flags as `ethier send`, plus the
+	// persistent root flags.
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String(configFlag, "", "")
+		cmd.Flags().String(networkFlag, "", "")
+		addRPCFlag(cmd)
+		addSignerFlags(cmd)
+		addSolcFlags(cmd)
+		cmd.Flags().String("max-fee", "", "")
+		cmd.Flags().String("priority-fee", "", "")
+		cmd.Flags().Uint64("base-fee-multiplier", 2, "")
+		cmd.Flags().String("api-url", "", "")
+		return cmd
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want map[string]string
+	}{
+		{
+			name: "default network",
+			args: []string{"--config", path},
+			want: map[string]string{
+				rpcFlag:          "http://localhost:8545",
+				"api-url":        "https://api-goerli.etherscan.io/api",
+				optimizeFlag:     "true",
+				optimizeRunsFlag: "1000",
+			},
+		},
+		{
+			name: "selected network",
+			args: []string{"--config", path, "--network", "mainnet"},
+			want: map[string]string{
+				rpcFlag:               "https://mainnet.example/secret",
+				keystoreFlag:          filepath.Join(dir, "keys/deployer.json"),
+				passwordFileFlag:      "/abs/deployer.pw",
+				"max-fee":             "80",
+				"priority-fee":        "1.5",
+				"base-fee-multiplier": "3",
+				optimizeFlag:          "true",
+				optimizeRunsFlag:      "1000",
+			},
+		},
+		{
+			name: "explicit flags take precedence",
+			args: []string{"--config", path, "--network", "mainnet", "--rpc", "http://other", "--private-key", "0x01", "--optimize-runs", "1"},
+			want: map[string]string{
+				rpcFlag:               "http://other",
+				privateKeyFlag:        "0x01",
+				"max-fee":             "80",
+				"priority-fee":        "1.5",
+				"base-fee-multiplier": "3",
+				optimizeFlag:          "true",
+				optimizeRunsFlag:      "1",
+			},
+		},
+		{
+			name: "interactive signer ignores configured keystore",
+			args: []string{"--config", path, "--network", "mainnet", "--interactive", "localhost:8546"},
+			want: map[string]string{
+				rpcFlag:               "https://mainnet.example/secret",
+				interactiveFlag:       "localhost:8546",
+				"max-fee":             "80",
+				"priority-fee":        "1.5",
+				"base-fee-multiplier": "3",
+				optimizeFlag:          "true",
+				optimizeRunsFlag:      "1000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ETH_RPC_URL", "")
+			cmd := newCmd()
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags(%q) error %v", tt.args, err)
+			}
+			if err := applyConfig(cmd, nil); err != nil {
+				t.Fatalf("applyConfig() error %v", err)
+			}
+
+			for name, want := range tt.want {
+				if got := cmd.Flags().Lookup(name).Value.String(); got != want {
+					t.Errorf("--%s got %q; want %q", name, got, want)
+				}
+			}
+			for _, name := range []string{keystoreFlag, privateKeyFlag, "api-url"} {
+				if _, ok := tt.want[name]; ok {
+					continue
+				}
+				if got := cmd.Flags().Lookup(name).Value.String(); got != "" {
+					t.Errorf("--%s got %q; want empty", name, got)
+				}
+			}
+		})
+	}
+
+	t.Run("unknown network", func(t *testing.T) {
+		cmd := newCmd()
+		args := []string{"--config", path, "--network", "rinkeby"}
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatalf("ParseFlags(%q) error %v", args, err)
+		}
+		if err := applyConfig(cmd, nil); err == nil {
+			t.Errorf("applyConfig() with unknown network; got nil error")
+		}
+	})
+}
+
+func TestFindConfig(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "go.mod", "module example.com/x\n")
+	writeFile(t, root, configFile, "")
+	sub := filepath.Join(root, "a", "b")
+	writeFile(t, sub, "x.sol", "")
+
+	got, err := findConfig(sub)
+	if err != nil {
+		t.Fatalf("findConfig(%q) error %v", sub, err)
+	}
+	if want := filepath.Join(root, configFile); got != want {
+		t.Errorf("findConfig(%q) got %q; want %q", sub, got, want)
+	}
+
+	other := t.TempDir()
+	writeFile(t, other, "go.mod", "module example.com/y\n")
+	if got, err := findConfig(other); err != nil || got != "" {
+		t.Errorf("findConfig(%q) got %q, err = %v; want empty, nil", other, got, err)
+	}
+}
+
+func TestSolcArgs(t *testing.T) {
+	cmd := &cobra.Command{}
+	addSolcFlags(cmd)
+	args := []string{"--optimize", "--evm-version", "london", "--via-ir"}
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags(%q) error %v", args, err)
+	}
+
+	got, err := solcArgs(cmd)
+	if err != nil {
+		t.Fatalf("solcArgs() error %v", err)
+	}
+	want := []string{"--optimize", "--optimize-runs", "200", "--evm-version", "london", "--via-ir"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("solcArgs() diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,611 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Starts an interactive session for exploratory calls and transactions."
+
+	cmd := &cobra.Command{
+		Use:   "console",
+		Short: short,
+		Long: short + `
+
+ABIs are loaded from JSON files, either the ABI itself or a build artifact with an "abi" field, with --abi [name=]path; the name defaults to the file's base name without extension, e.g. ERC721 for out/ERC721.sol/ERC721.json. Loaded ABIs are bound to deployed contracts with the "at" command, after which their functions are invoked as <contract>.<function> [args...], with arguments in the same form accepted by ethier call. View and pure functions are called, while all others send a transaction from the signer and wait for it to be mined. Tab completes commands, contracts, and their functions.
+
+If none of the signer flags are set, a new scratch key is generated for the session, which is useful against local chains where it can be funded by a pre-funded account.
+
+Input that isn't a terminal is read line by line without a prompt, allowing sessions to be scripted. Type "help" for a list of commands.`,
+		RunE: console,
+		Args: cobra.NoArgs,
+	}
+	addRPCFlag(cmd)
+	addSignerFlags(cmd)
+	addFeeFlags(cmd)
+	cmd.Flags().StringSlice("abi", nil, "ABI or artifact files to load, as [name=]path")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// console implements the `ethier console` command.
+func console(cmd *cobra.Command, args []string) error {
+	abiPaths, err := cmd.Flags().GetStringSlice("abi")
+	if err != nil {
+		return err
+	}
+	fees := new(txParams)
+	if err := feesFromFlags(cmd, fees); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("get chain ID: %v", err)
+	}
+	signer, err := signerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	s := newConsoleSession(client, signer, chainID, fees)
+	for _, p := range abiPaths {
+		name, path := "", p
+		if i := strings.Index(p, "="); i != -1 {
+			name, path = p[:i], p[i+1:]
+		}
+		if err := s.loadABI(name, path); err != nil {
+			return err
+		}
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		s.out = os.Stdout
+		sc := bufio.NewScanner(os.Stdin)
+		return s.run(ctx, func() (string, bool) {
+			if !sc.Scan() {
+				return "", false
+			}
+			return sc.Text(), true
+		})
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("set terminal to raw mode: %v", err)
+	}
+	defer term.Restore(fd, state)
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "> ")
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return s.complete(line, pos)
+	}
+	s.out = t
+	fmt.Fprintf(t, "Connected to chain %v as %v; type \"help\" for commands\n", chainID, signer.Address())
+
+	return s.run(ctx, func() (string, bool) {
+		l, err := t.ReadLine()
+		return l, err == nil
+	})
+}
+
+// A consoleBackend is the subset of a client used by a consoleSession.
+type consoleBackend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error)
+}
+
+// A consoleContract is a loaded ABI bound to an address.
+type consoleContract struct {
+	abiName string
+	abi     *abi.ABI
+	address common.Address
+}
+
+// A consoleSession holds the state of an `ethier console`.
+type consoleSession struct {
+	client  consoleBackend
+	signer  eth.SignerBackend
+	chainID *big.Int
+	fees    *txParams
+	// poll is the interval at which transaction receipts are polled for.
+	poll time.Duration
+
+	abis      map[string]*abi.ABI
+	contracts map[string]*consoleContract
+	out       io.Writer
+}
+
+func newConsoleSession(client consoleBackend, signer eth.SignerBackend, chainID *big.Int, fees *txParams) *consoleSession {
+	return &consoleSession{
+		client:    client,
+		signer:    signer,
+		chainID:   chainID,
+		fees:      fees,
+		poll:      time.Second,
+		abis:      make(map[string]*abi.ABI),
+		contracts: make(map[string]*consoleContract),
+		out:       io.Discard,
+	}
+}
+
+// run executes lines returned by next until it returns false or an exit
+// command is received. Errors from individual lines are printed and don't end
+// the session.
+func (s *consoleSession) run(ctx context.Context, next func() (string, bool)) error {
+	for {
+		line, ok := next()
+		if !ok {
+			return nil
+		}
+		if err := s.exec(ctx, line); err == errConsoleExit {
+			return nil
+		} else if err != nil {
+			fmt.Fprintf(s.out, "Error: %v\n", err)
+		}
+	}
+}
+
+// errConsoleExit is returned by exec() in response to an exit command.
+var errConsoleExit = errors.New("exit")
+
+// consoleCommands are the built-in commands, with their help text, excluding
+// contract functions.
+var consoleCommands = map[string]string{
+	"help":      "help: prints this message",
+	"load":      "load <name> <path>: loads a JSON ABI or artifact",
+	"abis":      "abis: lists loaded ABIs",
+	"at":        "at <contract> <abi> <address>: binds an ABI to a deployed contract, named for use as <contract>.<function>",
+	"contracts": "contracts: lists bound contracts",
+	"functions": "functions <contract>: lists a contract's functions",
+	"call":      "call <address> <signature> [args...]: calls a function by signature, as for ethier call",
+	"send":      "send <address> <signature> [args...]: sends a transaction calling a function by signature",
+	"balance":   "balance [address]: prints the ETH balance of the address, defaulting to the signer",
+	"block":     "block: prints the latest block number",
+	"signer":    "signer: prints the signer's address",
+	"exit":      "exit: ends the session",
+}
+
+// exec executes a single line of input.
+func (s *consoleSession) exec(ctx context.Context, line string) error {
+	args, err := splitConsoleArgs(line)
+	if err != nil || len(args) == 0 {
+		return err
+	}
+
+	cmd, args := args[0], args[1:]
+	nArgs := func(min, max int) error {
+		if n := len(args); n < min || n > max {
+			return fmt.Errorf("usage: %s", consoleCommands[cmd])
+		}
+		return nil
+	}
+
+	switch cmd {
+	case "exit", "quit":
+		return errConsoleExit
+
+	case "help":
+		var lines []string
+		for _, h := range consoleCommands {
+			lines = append(lines, "  "+h)
+		}
+		sort.Strings(lines)
+		s.printf("Commands:\n%s\n  <contract>.<function> [args...] [value=<amount>]: calls a view or pure function, otherwise sends a transaction; value is only accepted by payable functions\n", strings.Join(lines, "\n"))
+		return nil
+
+	case "load":
+		if err := nArgs(2, 2); err != nil {
+			return err
+		}
+		return s.loadABI(args[0], args[1])
+
+	case "abis":
+		names := make([]string, 0, len(s.abis))
+		for n := range s.abis {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			s.printf("%s\n", n)
+		}
+		return nil
+
+	case "at":
+		if err := nArgs(3, 3); err != nil {
+			return err
+		}
+		return s.bind(args[0], args[1], args[2])
+
+	case "contracts":
+		names := make([]string, 0, len(s.contracts))
+		for n := range s.contracts {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			c := s.contracts[n]
+			s.printf("%s\t%s\t%v\n", n, c.abiName, c.address)
+		}
+		return nil
+
+	case "functions":
+		if err := nArgs(1, 1); err != nil {
+			return err
+		}
+		c, ok := s.contracts[args[0]]
+		if !ok {
+			return fmt.Errorf("unknown contract %q", args[0])
+		}
+		names := make([]string, 0, len(c.abi.Methods))
+		for n := range c.abi.Methods {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			m := c.abi.Methods[n]
+			s.printf("%s\t%s\t%s\n", n, m.Sig, m.StateMutability)
+		}
+		return nil
+
+	case "call", "send":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: %s", consoleCommands[cmd])
+		}
+		to, err := s.address(args[0])
+		if err != nil {
+			return err
+		}
+		method, data, err := packCall(args[1], args[2:])
+		if err != nil {
+			return err
+		}
+		if cmd == "call" {
+			return s.call(ctx, to, method, data)
+		}
+		return s.transact(ctx, to, data, nil)
+
+	case "balance":
+		if err := nArgs(0, 1); err != nil {
+			return err
+		}
+		addr := s.signer.Address()
+		if len(args) == 1 {
+			if addr, err = s.address(args[0]); err != nil {
+				return err
+			}
+		}
+		bal, err := s.client.BalanceAt(ctx, addr, nil)
+		if err != nil {
+			return fmt.Errorf("get balance: %v", err)
+		}
+		s.printf("%s ETH\n", formatDecimal(new(big.Rat).SetFrac(bal, big.NewInt(1e18))))
+		return nil
+
+	case "block":
+		head, err := s.client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("get latest header: %v", err)
+		}
+		s.printf("%v\n", head.Number)
+		return nil
+
+	case "signer":
+		s.printf("%v\n", s.signer.Address())
+		return nil
+	}
+
+	if i := strings.Index(cmd, "."); i != -1 {
+		return s.invoke(ctx, cmd[:i], cmd[i+1:], args)
+	}
+	return fmt.Errorf("unknown command %q; type \"help\" for a list of commands", cmd)
+}
+
+func (s *consoleSession) printf(format string, a ...interface{}) {
+	fmt.Fprintf(s.out, format, a...)
+}
+
+// loadABI reads the ABI at path, naming it for the file if name is empty.
+func (s *consoleSession) loadABI(name, path string) error {
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	parsed, err := readABI(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	s.abis[name] = parsed
+	return nil
+}
+
+// bind binds the named, loaded ABI to the address.
+func (s *consoleSession) bind(contract, abiName, addr string) error {
+	if strings.Contains(contract, ".") {
+		return fmt.Errorf("contract name %q MUST NOT contain a period", contract)
+	}
+	if _, ok := consoleCommands[contract]; ok {
+		return fmt.Errorf("contract name %q clashes with command", contract)
+	}
+	a, ok := s.abis[abiName]
+	if !ok {
+		return fmt.Errorf("unknown ABI %q; load it first", abiName)
+	}
+	to, err := s.address(addr)
+	if err != nil {
+		return err
+	}
+	s.contracts[contract] = &consoleContract{
+		abiName: abiName,
+		abi:     a,
+		address: to,
+	}
+	return nil
+}
+
+// address parses s as either an address or the name of a bound contract.
+func (s *consoleSession) address(str string) (common.Address, error) {
+	if c, ok := s.contracts[str]; ok {
+		return c.address, nil
+	}
+	return eth.ParseAddress(str)
+}
+
+// invoke calls or transacts with the contract's function, depending on its
+// state mutability.
+func (s *consoleSession) invoke(ctx context.Context, contract, fn string, args []string) error {
+	c, ok := s.contracts[contract]
+	if !ok {
+		return fmt.Errorf("unknown contract %q", contract)
+	}
+	m, ok := c.abi.Methods[fn]
+	if !ok {
+		return fmt.Errorf("%s has no function %q", c.abiName, fn)
+	}
+
+	var value *big.Int
+	if n := len(args); n > 0 && strings.HasPrefix(args[n-1], "value=") && len(args) > len(m.Inputs) {
+		if !m.IsPayable() {
+			return fmt.Errorf("%s is not payable", m.Sig)
+		}
+		wei, err := convertUnits(strings.TrimPrefix(args[n-1], "value="), "wei", "wei", 18)
+		if err != nil {
+			return err
+		}
+		value, _ = new(big.Int).SetString(wei, 10)
+		args = args[:n-1]
+	}
+
+	// Arguments MAY be the names of bound contracts, in place of their
+	// addresses.
+	for i, in := range m.Inputs {
+		if i >= len(args) || in.Type.T != abi.AddressTy {
+			continue
+		}
+		if bound, ok := s.contracts[args[i]]; ok {
+			args[i] = bound.address.Hex()
+		}
+	}
+	vals, err := parseABIValues(m.Inputs, args)
+	if err != nil {
+		return fmt.Errorf("%s: %v", m.Sig, err)
+	}
+	data, err := c.abi.Pack(m.Name, vals...)
+	if err != nil {
+		return fmt.Errorf("pack arguments to %s: %v", m.Sig, err)
+	}
+
+	if m.IsConstant() {
+		return s.call(ctx, c.address, m, data)
+	}
+	return s.transact(ctx, c.address, data, value)
+}
+
+// call calls the method, with already-packed data, and prints its return
+// values.
+func (s *consoleSession) call(ctx context.Context, to common.Address, m abi.Method, data []byte) error {
+	ret, err := s.client.CallContract(ctx, ethereum.CallMsg{
+		From: s.signer.Address(),
+		To:   &to,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("call %s: %v", m.Sig, err)
+	}
+	out, err := unpackReturn(m, ret)
+	if err != nil {
+		return err
+	}
+	for _, o := range out {
+		s.printf("%s\n", o)
+	}
+	return nil
+}
+
+// transact sends a transaction from the signer, waits for it to be mined, and
+// prints its receipt, decoding logs with the ABIs of bound contracts. The
+// value MAY be nil.
+func (s *consoleSession) transact(ctx context.Context, to common.Address, data []byte, value *big.Int) error {
+	if value == nil {
+		value = new(big.Int)
+	}
+	p := *s.fees
+	p.to = to
+	p.data = data
+	p.value = value
+	p.nonce = -1
+
+	tx, err := sendTx(ctx, s.client, s.signer, s.chainID, &p)
+	if err != nil {
+		return err
+	}
+	s.printf("Sent %v\n", tx.Hash())
+
+	r, err := waitConfirmed(ctx, s.client, tx, 1, s.poll)
+	if err != nil {
+		return err
+	}
+	status := "success"
+	if r.Status != types.ReceiptStatusSuccessful {
+		status = "reverted"
+	}
+	s.printf("Mined in block %v; gas used %d; %s\n", r.BlockNumber, r.GasUsed, status)
+
+	for _, l := range r.Logs {
+		s.printf("  %s\n", s.formatLog(*l))
+	}
+	return nil
+}
+
+// formatLog returns a single-line representation of the log, decoded with the
+// ABI of the contract bound to the log's address, if any.
+func (s *consoleSession) formatLog(l types.Log) string {
+	d := new(logDecoder)
+	name := l.Address.Hex()
+	for n, c := range s.contracts {
+		if c.address == l.Address {
+			d.abi = c.abi
+			name = n
+			break
+		}
+	}
+
+	dec := d.decode(l)
+	if dec.Event == "" {
+		buf, _ := json.Marshal(struct {
+			Topics []common.Hash `json:"topics"`
+			Data   string        `json:"data"`
+		}{dec.Topics, dec.Data.String()})
+		return fmt.Sprintf("%s: %s", name, buf)
+	}
+	// Maps are marshalled with sorted keys, so output is deterministic.
+	buf, _ := json.Marshal(dec.Args)
+	return fmt.Sprintf("%s.%s %s", name, dec.Event, buf)
+}
+
+// complete implements term.Terminal.AutoCompleteCallback for the first word of
+// the line, completing commands, contracts, and <contract>.<function>. If
+// there are multiple candidates, the longest common prefix is completed.
+func (s *consoleSession) complete(line string, pos int) (string, int, bool) {
+	prefix := line[:pos]
+	if strings.ContainsAny(prefix, " \t") {
+		return "", 0, false
+	}
+
+	var candidates []string
+	add := func(c string) {
+		if strings.HasPrefix(c, prefix) {
+			candidates = append(candidates, c)
+		}
+	}
+	for c := range consoleCommands {
+		add(c)
+	}
+	for n, c := range s.contracts {
+		if !strings.Contains(prefix, ".") {
+			add(n + ".")
+			continue
+		}
+		for m := range c.abi.Methods {
+			add(n + "." + m)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	completed := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, completed) {
+			completed = completed[:len(completed)-1]
+		}
+	}
+	if len(candidates) == 1 && !strings.HasSuffix(completed, ".") {
+		completed += " "
+	}
+	if completed == prefix {
+		return "", 0, false
+	}
+	return completed + line[pos:], len(completed), true
+}
+
+// splitConsoleArgs splits the line on whitespace, except within single or
+// double quotes, which are removed.
+func splitConsoleArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+	)
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/ethtest"
+)
+
+func TestSplitConsoleArgs(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{line: "", want: nil},
+		{line: "  token.balanceOf   0x01 ", want: []string{"token.balanceOf", "0x01"}},
+		{line: `setName "hello world" [1,2]`, want: []string{"setName", "hello world", "[1,2]"}},
+		{line: `x '' "it's"`, want: []string{"x", "", "it's"}},
+		{line: `x "unterminated`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := splitConsoleArgs(tt.line)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("splitConsoleArgs(%q) got err %v; want error = %t", tt.line, err, tt.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("splitConsoleArgs(%q) diff (-want +got):\n%s", tt.line, diff)
+		}
+	}
+}
+
+const consoleTestABI = `[
+  {"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+  {"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+  {"type":"function","name":"deposit","stateMutability":"payable","inputs":[],"outputs":[]}
+]`
+
+func TestConsoleSession(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, 1)
+	signer := newFundedSigner(ctx, t, sim)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "Token.json", `{"abi":`+consoleTestABI+`}`)
+
+	s := newConsoleSession(sim, signer, big.NewInt(1337), new(txParams))
+	s.poll = time.Millisecond
+	var out bytes.Buffer
+	s.out = &out
+
+	// exec runs the line and returns its output.
+	exec := func(t *testing.T, line string) string {
+		t.Helper()
+		out.Reset()
+		if err := s.exec(ctx, line); err != nil {
+			t.Fatalf("exec(%q) error %v", line, err)
+		}
+		return out.String()
+	}
+
+	exec(t, "load Token "+filepath.Join(dir, "Token.json"))
+	// An address without code accepts any transaction, which suffices to test
+	// sending.
+	exec(t, "at token Token 0x0000000000000000000000000000000000000abc")
+
+	t.Run("completion", func(t *testing.T) {
+		tests := []struct {
+			line     string
+			wantLine string
+			wantOK   bool
+		}{
+			{line: "tok", wantLine: "token.", wantOK: true},
+			{line: "token.b", wantLine: "token.balanceOf ", wantOK: true},
+			{line: "token.", wantOK: false}, // ambiguous without a common prefix
+			{line: "ba", wantLine: "balance ", wantOK: true},
+			{line: "token.transfer 0x", wantOK: false},
+			{line: "zzz", wantOK: false},
+		}
+		for _, tt := range tests {
+			got, pos, ok := s.complete(tt.line, len(tt.line))
+			if ok != tt.wantOK || got != tt.wantLine || (ok && pos != len(got)) {
+				t.Errorf("complete(%q) got (%q, %d, %t); want (%q, %d, %t)", tt.line, got, pos, ok, tt.wantLine, len(tt.wantLine), tt.wantOK)
+			}
+		}
+	})
+
+	t.Run("listings", func(t *testing.T) {
+		if got, want := exec(t, "abis"), "Token\n"; got != want {
+			t.Errorf("abis got %q; want %q", got, want)
+		}
+		if got := exec(t, "contracts"); !strings.HasPrefix(got, "token\tToken\t0x0000000000000000000000000000000000000aBc") {
+			t.Errorf("contracts got %q", got)
+		}
+		if got := exec(t, "functions token"); !strings.Contains(got, "transfer\ttransfer(address,uint256)\tnonpayable") {
+			t.Errorf("functions token got %q", got)
+		}
+		if got, want := exec(t, "signer"), signer.Address().Hex()+"\n"; got != want {
+			t.Errorf("signer got %q; want %q", got, want)
+		}
+		if got, want := exec(t, "balance"), "10 ETH\n"; got != want {
+			t.Errorf("balance got %q; want %q", got, want)
+		}
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		got := exec(t, "token.transfer token 42")
+		if !strings.Contains(got, "; success\n") {
+			t.Errorf("token.transfer got %q; want successful transaction", got)
+		}
+		got = exec(t, "token.deposit value=1eth")
+		if !strings.Contains(got, "; success\n") {
+			t.Errorf("token.deposit got %q; want successful transaction", got)
+		}
+		if got, want := exec(t, "balance token"), "1 ETH\n"; got != want {
+			t.Errorf("balance token got %q; want %q", got, want)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, line := range []string{
+			"token.balanceOf 0x01", // no code, so empty return data
+			"token.transfer 0x01 1 value=1",
+			"token.unknown",
+			"unknown.balanceOf 0x01",
+			"at other Unknown 0x01",
+			"at help Token 0x01",
+			"at",
+			"nonsense",
+		} {
+			if err := s.exec(ctx, line); err == nil {
+				t.Errorf("exec(%q) got nil error", line)
+			}
+		}
+	})
+
+	t.Run("run", func(t *testing.T) {
+		lines := []string{"nonsense", "signer", "exit", "signer"}
+		out.Reset()
+		err := s.run(ctx, func() (string, bool) {
+			if len(lines) == 0 {
+				return "", false
+			}
+			l := lines[0]
+			lines = lines[1:]
+			return l, true
+		})
+		if err != nil {
+			t.Fatalf("run() error %v", err)
+		}
+		if got := strings.Count(out.String(), signer.Address().Hex()); got != 1 {
+			t.Errorf("run() printed signer %d times; want once, before exit; output %q", got, out.String())
+		}
+		if !strings.HasPrefix(out.String(), "Error: ") {
+			t.Errorf("run() output %q doesn't start with error from first line", out.String())
+		}
+	})
+}
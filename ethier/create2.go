@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Computes the address of a contract deployed with CREATE2, or mines a salt for a chosen address."
+
+	cmd := &cobra.Command{
+		Use:   "create2",
+		Short: short,
+		Long: short + `
+
+The address is determined by the --deployer, --salt and the contract's init code, i.e. its creation bytecode including ABI-encoded constructor arguments. The --init-code file, or stdin if it is "-", may contain either hex, as output by solc --bin, or raw bytes; alternatively its keccak256 hash can be provided with --init-code-hash. The default deployer is the deterministic-deployment proxy used by Foundry and Hardhat, which is deployed at the same address on most chains.
+
+With --find-salt, random salts are searched until the address matches all of --prefix, --suffix and --leading-zeros; the last counts zero bytes, each of which reduces the gas cost of calldata containing the address. Each additional hex character multiplies the expected search time by 16, and progress is logged to stderr every --progress interval.`,
+		RunE: create2,
+		Args: cobra.NoArgs,
+	}
+	cmd.Flags().String("deployer", "0x4e59b44847b379578588920cA78FbF26c0B4956C", "Address of the contract performing the CREATE2")
+	cmd.Flags().String("salt", "", "Salt, as hex of at most 32 bytes, left-padded with zeros; ignored with --find-salt")
+	cmd.Flags().String("init-code", "", `File containing contract init code; "-" for stdin`)
+	cmd.Flags().String("init-code-hash", "", "Keccak256 hash of the contract init code, instead of --init-code")
+	cmd.Flags().Bool("find-salt", false, "Mine a salt resulting in an address matching --prefix, --suffix, and --leading-zeros")
+	cmd.Flags().String("prefix", "", "Hex prefix of the address, with or without 0x; requires --find-salt")
+	cmd.Flags().String("suffix", "", "Hex suffix of the address; requires --find-salt")
+	cmd.Flags().Int("leading-zeros", 0, "Minimum number of leading zero bytes in the address; requires --find-salt")
+	cmd.Flags().IntP("workers", "w", 0, "Number of concurrent workers; 0 = number of CPUs")
+	cmd.Flags().Duration("progress", 5*time.Second, "Interval at which progress is logged; 0 to disable")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// create2 implements the `ethier create2` command.
+func create2(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	deployerHex, err := flags.GetString("deployer")
+	if err != nil {
+		return err
+	}
+	saltHex, err := flags.GetString("salt")
+	if err != nil {
+		return err
+	}
+	codePath, err := flags.GetString("init-code")
+	if err != nil {
+		return err
+	}
+	hashHex, err := flags.GetString("init-code-hash")
+	if err != nil {
+		return err
+	}
+	find, err := flags.GetBool("find-salt")
+	if err != nil {
+		return err
+	}
+	prefix, err := flags.GetString("prefix")
+	if err != nil {
+		return err
+	}
+	suffix, err := flags.GetString("suffix")
+	if err != nil {
+		return err
+	}
+	zeros, err := flags.GetInt("leading-zeros")
+	if err != nil {
+		return err
+	}
+	workers, err := flags.GetInt("workers")
+	if err != nil {
+		return err
+	}
+	progress, err := flags.GetDuration("progress")
+	if err != nil {
+		return err
+	}
+
+	deployer, err := eth.ParseAddress(deployerHex)
+	if err != nil {
+		return fmt.Errorf("--deployer: %v", err)
+	}
+
+	var initCodeHash common.Hash
+	switch {
+	case (codePath == "") == (hashHex == ""):
+		return errors.New("exactly one of --init-code or --init-code-hash required")
+	case codePath != "":
+		code, err := readInitCode(codePath)
+		if err != nil {
+			return err
+		}
+		initCodeHash = crypto.Keccak256Hash(code)
+	default:
+		h, err := hexutil.Decode(hashHex)
+		if err != nil {
+			return fmt.Errorf("--init-code-hash: %v", err)
+		}
+		if len(h) != common.HashLength {
+			return fmt.Errorf("--init-code-hash must be %d bytes; got %d", common.HashLength, len(h))
+		}
+		initCodeHash = common.BytesToHash(h)
+	}
+
+	if !find {
+		if prefix != "" || suffix != "" || zeros != 0 {
+			return errors.New("--prefix, --suffix, and --leading-zeros require --find-salt")
+		}
+		salt, err := parseSalt(saltHex)
+		if err != nil {
+			return fmt.Errorf("--salt: %v", err)
+		}
+		fmt.Println(crypto.CreateAddress2(deployer, salt, initCodeHash.Bytes()))
+		return nil
+	}
+
+	match, err := create2Matcher(prefix, suffix, zeros)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts uint64
+	count := func(addr common.Address) bool {
+		atomic.AddUint64(&attempts, 1)
+		return match(addr)
+	}
+	if progress > 0 {
+		go logVanityProgress(ctx, progress, &attempts, create2ExpectedAttempts(prefix, suffix, zeros))
+	}
+
+	salt, addr, err := eth.MineCreate2Salt(ctx, deployer, initCodeHash, count, workers)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Salt:    %#x\nAddress: %v\n", salt, addr)
+	return nil
+}
+
+// readInitCode reads contract init code from the file, or stdin if path is
+// "-". Files containing only hex, with optional 0x prefix and surrounding
+// whitespace, are decoded; all others are returned as raw bytes.
+func readInitCode(path string) ([]byte, error) {
+	var (
+		buf []byte
+		err error
+	)
+	if path == "-" {
+		buf, err = io.ReadAll(os.Stdin)
+	} else {
+		buf, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read init code: %v", err)
+	}
+
+	trimmed := bytes.TrimPrefix(bytes.TrimSpace(buf), []byte("0x"))
+	if code, err := hex.DecodeString(string(trimmed)); err == nil {
+		buf = code
+	}
+	if len(buf) == 0 {
+		return nil, fmt.Errorf("empty init code in %q", path)
+	}
+	return buf, nil
+}
+
+// parseSalt parses a hex salt of at most 32 bytes, left-padding it with zeros.
+// An empty string is the zero salt.
+func parseSalt(s string) ([32]byte, error) {
+	if s == "" {
+		return [32]byte{}, nil
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if len(b) > 32 {
+		return [32]byte{}, fmt.Errorf("%d bytes; must be at most 32", len(b))
+	}
+	return common.BytesToHash(b), nil
+}
+
+// create2Matcher returns a predicate reporting whether an address matches the
+// prefix and suffix, as defined by eth.AddressMatcher(), and begins with at
+// least the specified number of zero bytes.
+func create2Matcher(prefix, suffix string, leadingZeros int) (func(common.Address) bool, error) {
+	if leadingZeros < 0 || leadingZeros > common.AddressLength {
+		return nil, fmt.Errorf("--leading-zeros must be in [0,%d]; got %d", common.AddressLength, leadingZeros)
+	}
+	if prefix == "" && suffix == "" && leadingZeros == 0 {
+		return nil, errors.New("--find-salt requires at least one of --prefix, --suffix, or --leading-zeros")
+	}
+	match, err := eth.AddressMatcher(prefix, suffix)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(addr common.Address) bool {
+		for _, b := range addr[:leadingZeros] {
+			if b != 0 {
+				return false
+			}
+		}
+		return match(addr)
+	}, nil
+}
+
+// create2ExpectedAttempts returns the expected number of attempts to find an
+// address matched by create2Matcher(prefix, suffix, leadingZeros). Leading
+// zeros overlapping the prefix are assumed to be compatible with it.
+func create2ExpectedAttempts(prefix, suffix string, leadingZeros int) float64 {
+	n := len(strings.TrimPrefix(prefix, "0x"))
+	if z := 2 * leadingZeros; z > n {
+		n = z
+	}
+	return math.Pow(16, float64(n+len(suffix)))
+}
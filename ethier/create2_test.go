@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestCreate2Address(t *testing.T) {
+	dir := t.TempDir()
+
+	// Examples from EIP-1014.
+	tests := []struct {
+		deployer, salt, initCode string
+		want                     common.Address
+	}{
+		{
+			deployer: "0x0000000000000000000000000000000000000000",
+			salt:     "0x00",
+			initCode: "0x00",
+			want:     common.HexToAddress("0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"),
+		},
+		{
+			deployer: "0xdeadbeef00000000000000000000000000000000",
+			salt:     "0x000000000000000000000000feed000000000000000000000000000000000000",
+			initCode: "00\n",
+			want:     common.HexToAddress("0xD04116cDd17beBE565EB2422F2497E06cC1C9833"),
+		},
+		{
+			deployer: "0x00000000000000000000000000000000deadbeef",
+			salt:     "0xcafebabe",
+			initCode: "0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			want:     common.HexToAddress("0x1d8bfDC5D46DC4f61D6b6115972536eBE6A8854C"),
+		},
+		{
+			deployer: "0x0000000000000000000000000000000000000000",
+			salt:     "",
+			initCode: "",
+			want:     common.HexToAddress("0xE33C0C7F7df4809055C3ebA6c09CFe4BaF1BD9e0"),
+		},
+	}
+
+	for _, tt := range tests {
+		deployer, err := eth.ParseAddress(tt.deployer)
+		if err != nil {
+			t.Fatalf("ParseAddress(%q) error %v", tt.deployer, err)
+		}
+		salt, err := parseSalt(tt.salt)
+		if err != nil {
+			t.Fatalf("parseSalt(%q) error %v", tt.salt, err)
+		}
+
+		code := []byte{}
+		if tt.initCode != "" {
+			writeFile(t, dir, "init.bin", tt.initCode)
+			code, err = readInitCode(filepath.Join(dir, "init.bin"))
+			if err != nil {
+				t.Fatalf("readInitCode(%q) error %v", tt.initCode, err)
+			}
+		}
+
+		if got := crypto.CreateAddress2(deployer, salt, crypto.Keccak256(code)); got != tt.want {
+			t.Errorf("CREATE2 address with deployer %s, salt %q, init code %q got %v; want %v", tt.deployer, tt.salt, tt.initCode, got, tt.want)
+		}
+	}
+}
+
+func TestReadInitCodeRaw(t *testing.T) {
+	const raw = "\x60\x80\x60\x40\x52"
+	dir := t.TempDir()
+	writeFile(t, dir, "init.bin", raw)
+	got, err := readInitCode(filepath.Join(dir, "init.bin"))
+	if err != nil {
+		t.Fatalf("readInitCode() error %v", err)
+	}
+	if string(got) != raw {
+		t.Errorf("readInitCode(<raw bytes>) got %x; want %x", got, raw)
+	}
+}
+
+func TestParseSaltErrors(t *testing.T) {
+	for _, s := range []string{"00", "0xzz", "0x" + strings.Repeat("00", 33)} {
+		if _, err := parseSalt(s); err == nil {
+			t.Errorf("parseSalt(%q) got nil error", s)
+		}
+	}
+}
+
+func TestCreate2FindSalt(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	match, err := create2Matcher("", "7", 1)
+	if err != nil {
+		t.Fatalf("create2Matcher() error %v", err)
+	}
+
+	deployer := common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+	initCodeHash := crypto.Keccak256Hash([]byte{0x60, 0x80})
+	salt, addr, err := eth.MineCreate2Salt(ctx, deployer, initCodeHash, match, 0)
+	if err != nil {
+		t.Fatalf("MineCreate2Salt() error %v", err)
+	}
+	if addr[0] != 0 || addr[19]&0xf != 7 {
+		t.Errorf("MineCreate2Salt(<1 leading zero byte, suffix 7>) got address %v", addr)
+	}
+	if got := crypto.CreateAddress2(deployer, salt, initCodeHash.Bytes()); got != addr {
+		t.Errorf("CREATE2 address with mined salt %#x got %v; want %v", salt, got, addr)
+	}
+
+	for _, tt := range []struct {
+		prefix, suffix string
+		zeros          int
+	}{
+		{"", "", 0},
+		{"", "", -1},
+		{"", "", 21},
+		{"xyz", "", 0},
+	} {
+		if _, err := create2Matcher(tt.prefix, tt.suffix, tt.zeros); err == nil {
+			t.Errorf("create2Matcher(%q, %q, %d) got nil error", tt.prefix, tt.suffix, tt.zeros)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Resolves ENS names and reverse records."
+
+	cmd := &cobra.Command{
+		Use:   "ens",
+		Short: short,
+		Long: short + `
+
+Names are lowercased but otherwise not normalised, so those with non-ASCII characters MUST already conform to UTS-46.`,
+	}
+	cmd.PersistentFlags().String("registry", eth.ENSRegistry.Hex(), "Address of the ENS registry")
+
+	resolve := &cobra.Command{
+		Use:   "resolve <name>",
+		Short: "Prints records of an ENS name",
+		Long: `Prints records of an ENS name, one per line as <record>\t<value>, omitting those that aren't set.
+
+Records are the address, avatar, and contenthash, which is decoded to an ipfs:// or bzz:// URI where possible; any other record name is read as a text record, e.g. url or com.twitter. If only a single record is requested, only its value is printed, making it suitable for use in scripts.`,
+		RunE: ensResolve,
+		Args: cobra.ExactArgs(1),
+	}
+	addRPCFlag(resolve)
+	resolve.Flags().StringSlice("records", []string{ensAddressRecord, ensAvatarRecord, ensContentHashRecord}, "Records to print")
+	cmd.AddCommand(resolve)
+
+	reverse := &cobra.Command{
+		Use:   "reverse <address>",
+		Short: "Prints the primary ENS name of an address",
+		Long:  "Prints the primary ENS name of an address, as set by its reverse record. The name MUST resolve back to the address, otherwise the reverse record is untrusted and an error is returned.",
+		RunE:  ensReverse,
+		Args:  cobra.ExactArgs(1),
+	}
+	addRPCFlag(reverse)
+	cmd.AddCommand(reverse)
+
+	rootCmd.AddCommand(cmd)
+}
+
+// Names of records, other than text records, read by `ethier ens resolve`.
+const (
+	ensAddressRecord     = "address"
+	ensAvatarRecord      = "avatar"
+	ensContentHashRecord = "contenthash"
+)
+
+// ensClientFromFlags connects to the node and ENS registry specified by the
+// flags.
+func ensClientFromFlags(ctx context.Context, cmd *cobra.Command) (*ensClient, func(), error) {
+	registry, err := cmd.Flags().GetString("registry")
+	if err != nil {
+		return nil, nil, err
+	}
+	addr, err := eth.ParseAddress(registry)
+	if err != nil {
+		return nil, nil, fmt.Errorf("--registry: %v", err)
+	}
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ensClient{caller: client, registry: addr}, client.Close, nil
+}
+
+// ensResolve implements the `ethier ens resolve` command.
+func ensResolve(cmd *cobra.Command, args []string) error {
+	records, err := cmd.Flags().GetStringSlice("records")
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	c, done, err := ensClientFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	vals, err := c.records(ctx, args[0], records)
+	if err != nil {
+		return err
+	}
+	if len(records) == 1 {
+		if vals[0] == "" {
+			return fmt.Errorf("%s has no %s record", args[0], records[0])
+		}
+		fmt.Println(vals[0])
+		return nil
+	}
+	for i, r := range records {
+		if vals[i] != "" {
+			fmt.Printf("%s\t%s\n", r, vals[i])
+		}
+	}
+	return nil
+}
+
+// ensReverse implements the `ethier ens reverse` command.
+func ensReverse(cmd *cobra.Command, args []string) error {
+	addr, err := eth.ParseAddress(args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	c, done, err := ensClientFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	name, err := c.reverse(ctx, addr)
+	if err != nil {
+		return err
+	}
+	fmt.Println(name)
+	return nil
+}
+
+// An ensClient reads ENS records via an ENS registry.
+type ensClient struct {
+	caller   ethereum.ContractCaller
+	registry common.Address
+}
+
+// errNoResolver is returned by ensClient.resolver() if the name doesn't have
+// a resolver.
+var errNoResolver = errors.New("no resolver")
+
+// call is equivalent to callContract() with the client's caller.
+func (c *ensClient) call(ctx context.Context, to common.Address, sig string, args ...interface{}) ([]interface{}, error) {
+	return callContract(ctx, c.caller, to, sig, args...)
+}
+
+// resolver returns the address of the name's resolver, along with its node,
+// i.e. namehash.
+func (c *ensClient) resolver(ctx context.Context, name string) (common.Address, common.Hash, error) {
+	node := eth.NameHash(name)
+	vals, err := c.call(ctx, c.registry, "resolver(bytes32)(address)", node)
+	if err != nil {
+		return common.Address{}, node, err
+	}
+	if len(vals) == 0 || vals[0].(common.Address) == (common.Address{}) {
+		return common.Address{}, node, fmt.Errorf("%q: %w", name, errNoResolver)
+	}
+	return vals[0].(common.Address), node, nil
+}
+
+// address returns the address to which the name resolves, which is the zero
+// address if the record isn't set.
+func (c *ensClient) address(ctx context.Context, name string) (common.Address, error) {
+	vals, err := c.records(ctx, name, []string{ensAddressRecord})
+	if err != nil {
+		return common.Address{}, err
+	}
+	if vals[0] == "" {
+		return common.Address{}, nil
+	}
+	return common.HexToAddress(vals[0]), nil
+}
+
+// records returns the values of each of the named records of the ENS name,
+// with unset records as empty strings.
+func (c *ensClient) records(ctx context.Context, name string, records []string) ([]string, error) {
+	res, node, err := c.resolver(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(records))
+	for i, r := range records {
+		var (
+			vals []interface{}
+			err  error
+		)
+		switch r {
+		case ensAddressRecord:
+			vals, err = c.call(ctx, res, "addr(bytes32)(address)", node)
+			if err == nil && len(vals) > 0 && vals[0].(common.Address) != (common.Address{}) {
+				out[i] = vals[0].(common.Address).Hex()
+			}
+		case ensContentHashRecord:
+			vals, err = c.call(ctx, res, "contenthash(bytes32)(bytes)", node)
+			if err == nil && len(vals) > 0 {
+				out[i] = decodeContentHash(vals[0].([]byte))
+			}
+		default:
+			vals, err = c.call(ctx, res, "text(bytes32,string)(string)", node, r)
+			if err == nil && len(vals) > 0 {
+				out[i] = vals[0].(string)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s record of %q: %v", r, name, err)
+		}
+	}
+	return out, nil
+}
+
+// reverse returns the name set as the reverse record of the address, after
+// confirming that the name resolves back to the address.
+func (c *ensClient) reverse(ctx context.Context, addr common.Address) (string, error) {
+	res, node, err := c.resolver(ctx, eth.ReverseName(addr))
+	if errors.Is(err, errNoResolver) {
+		return "", fmt.Errorf("%v has no reverse record", addr)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	vals, err := c.call(ctx, res, "name(bytes32)(string)", node)
+	if err != nil {
+		return "", err
+	}
+	if len(vals) == 0 || vals[0].(string) == "" {
+		return "", fmt.Errorf("%v has no reverse record", addr)
+	}
+	name := vals[0].(string)
+
+	fwd, err := c.address(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("forward resolution of reverse record: %v", err)
+	}
+	if fwd != addr {
+		return "", fmt.Errorf("reverse record of %v is %q, which resolves to %v", addr, name, fwd)
+	}
+	return name, nil
+}
+
+// Multicodec prefixes of EIP-1577 content hashes.
+var (
+	ipfsContentPrefix  = []byte{0xe3, 0x01}
+	swarmContentPrefix = []byte{0xe4, 0x01}
+)
+
+// decodeContentHash returns an ipfs:// URI for content hashes of IPFS CIDv1
+// dag-pb sha256 multihashes, as the equivalent CIDv0, and a bzz:// URI for
+// Swarm hashes. All other content hashes are returned as hex.
+func decodeContentHash(h []byte) string {
+	switch {
+	case len(h) == 0:
+		return ""
+	case bytes.HasPrefix(h, ipfsContentPrefix):
+		cid := h[len(ipfsContentPrefix):]
+		// CIDv1 (0x01), dag-pb (0x70), sha256 (0x12) multihash of 32 bytes.
+		if len(cid) == 36 && bytes.HasPrefix(cid, []byte{0x01, 0x70, 0x12, 0x20}) {
+			return "ipfs://" + base58.Encode(cid[2:])
+		}
+	case bytes.HasPrefix(h, swarmContentPrefix):
+		// CIDv1, swarm-manifest (0xfa 0x01), keccak256 (0x1b) of 32 bytes.
+		ref := h[len(swarmContentPrefix):]
+		if len(ref) == 37 && bytes.HasPrefix(ref, []byte{0x01, 0xfa, 0x01, 0x1b, 0x20}) {
+			return "bzz://" + strings.TrimPrefix(hexutil.Encode(ref[5:]), "0x")
+		}
+	}
+	return hexutil.Encode(h)
+}
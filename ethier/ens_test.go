@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// fakeENS is an ethereum.ContractCaller that responds to ENS registry and
+// resolver calls from a map of the form ["address:Sig:node[:key]"] → value.
+type fakeENS struct {
+	t      *testing.T
+	values map[string]interface{}
+}
+
+func (f *fakeENS) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	for _, sig := range []string{
+		"resolver(bytes32)(address)",
+		"addr(bytes32)(address)",
+		"name(bytes32)(string)",
+		"contenthash(bytes32)(bytes)",
+		"text(bytes32,string)(string)",
+	} {
+		m, err := parseFunctionSignature(sig)
+		if err != nil {
+			f.t.Fatalf("parseFunctionSignature(%q) error %v", sig, err)
+		}
+		if string(msg.Data[:4]) != string(m.ID) {
+			continue
+		}
+
+		args, err := m.Inputs.Unpack(msg.Data[4:])
+		if err != nil {
+			f.t.Fatalf("%s.Inputs.Unpack() error %v", sig, err)
+		}
+		key := fmt.Sprintf("%v:%s:%s", *msg.To, m.Sig, common.Hash(args[0].([32]byte)))
+		if len(args) > 1 {
+			key += ":" + args[1].(string)
+		}
+		v, ok := f.values[key]
+		if !ok {
+			// Unset values are zero, not empty return data.
+			return m.Outputs.Pack(zeroOutput(sig))
+		}
+		return m.Outputs.Pack(v)
+	}
+	return nil, nil
+}
+
+func zeroOutput(sig string) interface{} {
+	switch sig {
+	case "resolver(bytes32)(address)", "addr(bytes32)(address)":
+		return common.Address{}
+	case "contenthash(bytes32)(bytes)":
+		return []byte{}
+	default:
+		return ""
+	}
+}
+
+func TestENS(t *testing.T) {
+	registry := common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+	resolver := common.HexToAddress("0x4976fb03C32e5B8cfe2b6cCB31c09Ba78EBaBa41")
+	owner := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+	other := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	node := eth.NameHash("vitalik.eth").Hex()
+	rev := eth.NameHash(eth.ReverseName(owner)).Hex()
+	otherRev := eth.NameHash(eth.ReverseName(other)).Hex()
+
+	cid := hexutil.MustDecode("0xe30101701220" + "6d0d0c1bc4b8d1d0a04c6cb6e0b7f8d2f2f5c6c4b5e4d8e1e0b6c8f8a7b6c5d4")
+
+	fake := &fakeENS{
+		t: t,
+		values: map[string]interface{}{
+			fmt.Sprintf("%v:resolver(bytes32):%s", registry, node):           resolver,
+			fmt.Sprintf("%v:addr(bytes32):%s", resolver, node):               owner,
+			fmt.Sprintf("%v:text(bytes32,string):%s:avatar", resolver, node): "eip155:1/erc721:0xb7F7F6C52F2e2fdb1963Eab30438024864c313F6/2430",
+			fmt.Sprintf("%v:contenthash(bytes32):%s", resolver, node):        cid,
+			fmt.Sprintf("%v:resolver(bytes32):%s", registry, rev):            resolver,
+			fmt.Sprintf("%v:name(bytes32):%s", resolver, rev):                "vitalik.eth",
+			fmt.Sprintf("%v:resolver(bytes32):%s", registry, otherRev):       resolver,
+			fmt.Sprintf("%v:name(bytes32):%s", resolver, otherRev):           "vitalik.eth",
+		},
+	}
+	c := &ensClient{caller: fake, registry: registry}
+	ctx := context.Background()
+
+	t.Run("records", func(t *testing.T) {
+		got, err := c.records(ctx, "Vitalik.eth", []string{ensAddressRecord, ensAvatarRecord, ensContentHashRecord, "url"})
+		if err != nil {
+			t.Fatalf("records() error %v", err)
+		}
+		want := []string{
+			owner.Hex(),
+			"eip155:1/erc721:0xb7F7F6C52F2e2fdb1963Eab30438024864c313F6/2430",
+			decodeContentHash(cid),
+			"",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("records() diff (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("no resolver", func(t *testing.T) {
+		if _, err := c.records(ctx, "unknown.eth", []string{ensAddressRecord}); err == nil {
+			t.Errorf("records(unknown.eth) got nil error")
+		}
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		got, err := c.reverse(ctx, owner)
+		if err != nil || got != "vitalik.eth" {
+			t.Errorf("reverse(%v) got %q, err = %v; want vitalik.eth, nil", owner, got, err)
+		}
+	})
+
+	t.Run("reverse mismatch", func(t *testing.T) {
+		if got, err := c.reverse(ctx, other); err == nil {
+			t.Errorf("reverse(%v) got %q, nil error; want error as forward resolution differs", other, got)
+		}
+	})
+}
+
+func TestDecodeContentHash(t *testing.T) {
+	tests := []struct {
+		hash string
+		want string
+	}{
+		{
+			// EIP-1577 example.
+			hash: "0xe3010170122029f2d17be6139079dc48696d1f582a8530eb9805b561eda517e22a892c7e3f1f",
+			want: "ipfs://QmRAQB6YaCyidP37UdDnjFY5vQuiBrcqdyoW1CuDgwxkD4",
+		},
+		{
+			hash: "0xe40101fa011b20d1de9994b4d039f6548d191eb26786769f580809256b4685ef316805265ea162",
+			want: "bzz://d1de9994b4d039f6548d191eb26786769f580809256b4685ef316805265ea162",
+		},
+		{
+			hash: "0xe50101",
+			want: "0xe50101",
+		},
+		{
+			hash: "0x",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := decodeContentHash(hexutil.MustDecode(tt.hash)); got != tt.want {
+			t.Errorf("decodeContentHash(%s) got %q; want %q", tt.hash, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// verifyEtherscanCmd returns the `ethier verify etherscan` command.
+func verifyEtherscanCmd() *cobra.Command {
+	const short = "Verifies a deployed contract's source code with Etherscan."
+
+	cmd := &cobra.Command{
+		Use:   "etherscan",
+		Short: short,
+		Long: short + `
+
+The contract's source file is compiled with solc, exactly as by ethier gen with the same solc flags, and the resulting metadata is used to assemble standard-JSON input with identical settings. This is submitted to the Etherscan API, which is then polled until verification either passes or fails.
+
+Constructor arguments are ABI-encoded hex, e.g. as output by ethier abi encode --no-selector. For networks other than mainnet, set --api-url to the respective Etherscan API, e.g. https://api-goerli.etherscan.io/api.`,
+		RunE: verifyEtherscan,
+		Args: cobra.NoArgs,
+	}
+	cmd.Flags().String("address", "", "Address of the deployed contract")
+	cmd.Flags().String("contract", "", "Solidity source file and contract name, e.g. src/Foo.sol:Foo")
+	cmd.Flags().String("constructor-args", "", "ABI-encoded constructor arguments, as hex")
+	cmd.Flags().String("api-key", os.Getenv("ETHERSCAN_API_KEY"), "Etherscan API key; defaults to $ETHERSCAN_API_KEY")
+	cmd.Flags().String("api-url", "https://api.etherscan.io/api", "Etherscan API endpoint")
+	cmd.Flags().Duration("poll", 5*time.Second, "Interval at which verification status is polled")
+	cmd.Flags().Duration("timeout", 5*time.Minute, "Maximum time to wait for verification")
+	addSolcFlags(cmd)
+	return cmd
+}
+
+// verifyEtherscan implements the `ethier verify etherscan` command.
+func verifyEtherscan(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	addrHex, err := flags.GetString("address")
+	if err != nil {
+		return err
+	}
+	contract, err := flags.GetString("contract")
+	if err != nil {
+		return err
+	}
+	ctorArgs, err := flags.GetString("constructor-args")
+	if err != nil {
+		return err
+	}
+	apiKey, err := flags.GetString("api-key")
+	if err != nil {
+		return err
+	}
+	apiURL, err := flags.GetString("api-url")
+	if err != nil {
+		return err
+	}
+	poll, err := flags.GetDuration("poll")
+	if err != nil {
+		return err
+	}
+	timeout, err := flags.GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	addr, err := eth.ParseAddress(addrHex)
+	if err != nil {
+		return fmt.Errorf("--address: %v", err)
+	}
+	colon := strings.LastIndexByte(contract, ':')
+	if colon <= 0 || colon == len(contract)-1 {
+		return fmt.Errorf("--contract %q must be of the form path/to/File.sol:Name", contract)
+	}
+	if apiKey == "" {
+		return errors.New("--api-key or $ETHERSCAN_API_KEY required")
+	}
+	ctor, err := hexutil.Decode("0x" + strings.TrimPrefix(ctorArgs, "0x"))
+	if ctorArgs != "" && err != nil {
+		return fmt.Errorf("--constructor-args: %v", err)
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("os.Getwd(): %v", err)
+	}
+	basePath, includePath := solcPaths(pwd)
+	settings, err := solcArgs(cmd)
+	if err != nil {
+		return err
+	}
+	sub := &etherscanSubmission{
+		address:         addr.Hex(),
+		constructorArgs: ctor,
+	}
+	sub.contractName, sub.compilerVersion, sub.input, err = compileStandardJSON(contract[:colon], contract[colon+1:], basePath, includePath, settings)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	c := &etherscanClient{
+		url:    apiURL,
+		apiKey: apiKey,
+		client: http.DefaultClient,
+	}
+	guid, err := c.submit(ctx, sub)
+	if errors.Is(err, errAlreadyVerified) {
+		log.Printf("%s is already verified", addr)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Submitted %s for verification as %s; GUID %s", addr, sub.contractName, guid)
+
+	if err := c.waitVerified(ctx, guid, poll); err != nil {
+		return err
+	}
+	log.Printf("Verified %s", addr)
+	return nil
+}
+
+// compileStandardJSON compiles the source file with solc and returns the
+// fully qualified name of the contract, the compiler version, and the
+// standard-JSON input that reproduces its compilation. The settings are
+// additional solc arguments, as returned by solcArgs().
+func compileStandardJSON(source, name, basePath, includePath string, settings []string) (string, string, []byte, error) {
+	args := append(append([]string{source}, settings...),
+		"--base-path", basePath,
+		"--include-path", includePath,
+		"--combined-json", "metadata",
+	)
+	solc := exec.Command("solc", args...)
+	solc.Stderr = os.Stderr
+	out, err := solc.Output()
+	if err != nil {
+		return "", "", nil, fmt.Errorf("`solc` returned: %v", err)
+	}
+
+	var combined struct {
+		Contracts map[string]struct {
+			Metadata string `json:"metadata"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(out, &combined); err != nil {
+		return "", "", nil, fmt.Errorf("json.Unmarshal([solc output], %T): %v", &combined, err)
+	}
+	keys := make([]string, 0, len(combined.Contracts))
+	for k := range combined.Contracts {
+		keys = append(keys, k)
+	}
+	key, err := matchContract(keys, source, name)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	version, input, err := standardJSONInput([]byte(combined.Contracts[key].Metadata), []string{basePath, includePath})
+	if err != nil {
+		return "", "", nil, err
+	}
+	return key, version, input, nil
+}
+
+// matchContract returns the key, of the form sourceUnit:Name, from solc's
+// combined JSON output that best matches the source file and contract name.
+// The source unit MAY differ from the file as it's relative to solc's base
+// path.
+func matchContract(keys []string, source, name string) (string, error) {
+	src := filepath.ToSlash(filepath.Clean(source))
+	if abs, err := filepath.Abs(source); err == nil {
+		src = filepath.ToSlash(abs)
+	}
+
+	var candidates []string
+	for _, k := range keys {
+		i := strings.LastIndexByte(k, ':')
+		if i == -1 || k[i+1:] != name {
+			continue
+		}
+		unit := k[:i]
+		if unit == source || strings.HasSuffix(src, "/"+strings.TrimPrefix(unit, "/")) {
+			candidates = append(candidates, k)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("contract %s not found in solc output for %s", name, source)
+	case 1:
+		return candidates[0], nil
+	}
+	sort.Strings(candidates)
+	return "", fmt.Errorf("contract %s:%s is ambiguous; candidates: %q", source, name, candidates)
+}
+
+// solcMetadata is the subset of a contract's metadata, as output by solc,
+// needed to reproduce its compilation.
+type solcMetadata struct {
+	Compiler struct {
+		Version string `json:"version"`
+	} `json:"compiler"`
+	Language string                     `json:"language"`
+	Settings map[string]json.RawMessage `json:"settings"`
+	Sources  map[string]struct {
+		Keccak256 string `json:"keccak256"`
+		Content   string `json:"content"`
+	} `json:"sources"`
+}
+
+// standardJSONInput converts solc metadata to the standard-JSON input with
+// which to reproduce the compilation, returning the compiler version in the
+// form expected by Etherscan, e.g. v0.8.13+commit.abaa5c0e. Source contents
+// are read from the first of paths in which they're found, and MUST match the
+// hashes in the metadata.
+func standardJSONInput(metadata []byte, paths []string) (string, []byte, error) {
+	var meta solcMetadata
+	if err := json.Unmarshal(metadata, &meta); err != nil {
+		return "", nil, fmt.Errorf("json.Unmarshal([metadata], %T): %v", &meta, err)
+	}
+	if meta.Compiler.Version == "" {
+		return "", nil, errors.New("metadata missing compiler version")
+	}
+
+	type source struct {
+		Content string `json:"content"`
+	}
+	input := struct {
+		Language string                     `json:"language"`
+		Sources  map[string]source          `json:"sources"`
+		Settings map[string]json.RawMessage `json:"settings"`
+	}{
+		Language: meta.Language,
+		Sources:  make(map[string]source),
+		Settings: make(map[string]json.RawMessage),
+	}
+
+	for unit, s := range meta.Sources {
+		content := s.Content
+		if content == "" {
+			var err error
+			if content, err = readSourceUnit(unit, paths); err != nil {
+				return "", nil, err
+			}
+		}
+		if got := hexutil.Encode(crypto.Keccak256([]byte(content))); s.Keccak256 != "" && got != s.Keccak256 {
+			return "", nil, fmt.Errorf("source %q has keccak256 %s; metadata has %s", unit, got, s.Keccak256)
+		}
+		input.Sources[unit] = source{Content: content}
+	}
+
+	for k, v := range meta.Settings {
+		switch k {
+		case "compilationTarget":
+			continue
+		case "libraries":
+			libs, err := nestLibraries(v)
+			if err != nil {
+				return "", nil, err
+			}
+			v = libs
+		}
+		input.Settings[k] = v
+	}
+	input.Settings["outputSelection"] = json.RawMessage(`{"*":{"*":["abi","evm.bytecode","evm.deployedBytecode","metadata"]}}`)
+
+	buf, err := json.Marshal(input)
+	if err != nil {
+		return "", nil, fmt.Errorf("json.Marshal(%T): %v", input, err)
+	}
+	return "v" + strings.TrimPrefix(meta.Compiler.Version, "v"), buf, nil
+}
+
+// readSourceUnit returns the contents of the source unit as found in the first
+// of paths that contains it.
+func readSourceUnit(unit string, paths []string) (string, error) {
+	for _, p := range paths {
+		buf, err := os.ReadFile(filepath.Join(p, unit))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("read source %q: %v", unit, err)
+		}
+		return string(buf), nil
+	}
+	return "", fmt.Errorf("source %q not found in %q", unit, paths)
+}
+
+// nestLibraries converts libraries from the flat form used by metadata, i.e.
+// {"path:Name": "0x..."}, to the nested form used by standard-JSON input, i.e.
+// {"path": {"Name": "0x..."}}.
+func nestLibraries(raw json.RawMessage) (json.RawMessage, error) {
+	var flat map[string]string
+	if err := json.Unmarshal(raw, &flat); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal([metadata libraries], %T): %v", &flat, err)
+	}
+	nested := make(map[string]map[string]string)
+	for k, addr := range flat {
+		i := strings.LastIndexByte(k, ':')
+		if i == -1 {
+			return nil, fmt.Errorf("library %q not of the form path:Name", k)
+		}
+		if nested[k[:i]] == nil {
+			nested[k[:i]] = make(map[string]string)
+		}
+		nested[k[:i]][k[i+1:]] = addr
+	}
+	return json.Marshal(nested)
+}
+
+// An etherscanSubmission is a request to verify a contract's source code.
+type etherscanSubmission struct {
+	address         string
+	contractName    string
+	compilerVersion string
+	input           []byte
+	constructorArgs []byte
+}
+
+// An etherscanClient calls the Etherscan contract-verification API.
+type etherscanClient struct {
+	url, apiKey string
+	client      *http.Client
+}
+
+// An etherscanResponse is the envelope of all Etherscan API responses.
+type etherscanResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Result  string `json:"result"`
+}
+
+// errAlreadyVerified is returned by etherscanClient.submit() if the contract
+// is already verified.
+var errAlreadyVerified = errors.New("already verified")
+
+// submit submits the contract for verification, returning the GUID with which
+// to check its status.
+func (c *etherscanClient) submit(ctx context.Context, s *etherscanSubmission) (string, error) {
+	params := url.Values{
+		"module":                {"contract"},
+		"action":                {"verifysourcecode"},
+		"contractaddress":       {s.address},
+		"sourceCode":            {string(s.input)},
+		"codeformat":            {"solidity-standard-json-input"},
+		"contractname":          {s.contractName},
+		"compilerversion":       {s.compilerVersion},
+		"constructorArguements": {strings.TrimPrefix(hexutil.Encode(s.constructorArgs), "0x")}, // sic
+	}
+	resp, err := c.do(ctx, http.MethodPost, params)
+	if err != nil {
+		return "", err
+	}
+	if resp.Status != "1" {
+		if strings.Contains(strings.ToLower(resp.Result), "already verified") {
+			return "", errAlreadyVerified
+		}
+		return "", fmt.Errorf("submit verification: %s: %s", resp.Message, resp.Result)
+	}
+	return resp.Result, nil
+}
+
+// waitVerified polls the status of the verification with the GUID until it
+// either passes or fails, or ctx is cancelled.
+func (c *etherscanClient) waitVerified(ctx context.Context, guid string, poll time.Duration) error {
+	params := url.Values{
+		"module": {"contract"},
+		"action": {"checkverifystatus"},
+		"guid":   {guid},
+	}
+	for {
+		resp, err := c.do(ctx, http.MethodGet, params)
+		if err != nil {
+			return err
+		}
+		switch res := strings.ToLower(resp.Result); {
+		case strings.Contains(res, "pending"):
+		case resp.Status == "1", strings.Contains(res, "already verified"):
+			return nil
+		default:
+			return fmt.Errorf("verification failed: %s", resp.Result)
+		}
+
+		select {
+		case <-time.After(poll):
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for verification: %w", ctx.Err())
+		}
+	}
+}
+
+// do calls the API with the parameters, plus the API key, as either a query
+// string or a form, depending on the method.
+func (c *etherscanClient) do(ctx context.Context, method string, params url.Values) (*etherscanResponse, error) {
+	params.Set("apikey", c.apiKey)
+
+	var (
+		req *http.Request
+		err error
+	)
+	switch method {
+	case http.MethodGet:
+		req, err = http.NewRequestWithContext(ctx, method, c.url+"?"+params.Encode(), nil)
+	default:
+		req, err = http.NewRequestWithContext(ctx, method, c.url, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("build %s request: %v", method, err)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, c.url, err)
+	}
+	defer res.Body.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(res.Body); err != nil {
+		return nil, fmt.Errorf("read response: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, c.url, res.Status, buf.String())
+	}
+	var resp etherscanResponse
+	if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(%q, %T): %v", buf.String(), &resp, err)
+	}
+	return &resp, nil
+}
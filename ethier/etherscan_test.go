@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStandardJSONInput(t *testing.T) {
+	base := t.TempDir()
+	modules := t.TempDir()
+	const (
+		foo  = "// SPDX-License-Identifier: MIT\nimport \"@openzeppelin/Ownable.sol\";\ncontract Foo is Ownable {}\n"
+		ozOw = "// SPDX-License-Identifier: MIT\ncontract Ownable {}\n"
+	)
+	writeFile(t, filepath.Join(base, "src"), "Foo.sol", foo)
+	writeFile(t, filepath.Join(modules, "@openzeppelin"), "Ownable.sol", ozOw)
+
+	hash := func(s string) string {
+		return hexutil.Encode(crypto.Keccak256([]byte(s)))
+	}
+	metadata := `{
+		"compiler": {"version": "0.8.13+commit.abaa5c0e"},
+		"language": "Solidity",
+		"settings": {
+			"compilationTarget": {"src/Foo.sol": "Foo"},
+			"evmVersion": "london",
+			"libraries": {"src/Lib.sol:Lib": "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+			"metadata": {"bytecodeHash": "ipfs"},
+			"optimizer": {"enabled": true, "runs": 200},
+			"remappings": []
+		},
+		"sources": {
+			"src/Foo.sol": {"keccak256": "` + hash(foo) + `"},
+			"@openzeppelin/Ownable.sol": {"keccak256": "` + hash(ozOw) + `"},
+			"literal.sol": {"keccak256": "` + hash("contract L {}") + `", "content": "contract L {}"}
+		}
+	}`
+
+	version, input, err := standardJSONInput([]byte(metadata), []string{base, modules})
+	if err != nil {
+		t.Fatalf("standardJSONInput() error %v", err)
+	}
+	if want := "v0.8.13+commit.abaa5c0e"; version != want {
+		t.Errorf("standardJSONInput() version got %q; want %q", version, want)
+	}
+
+	var got struct {
+		Language string `json:"language"`
+		Sources  map[string]struct {
+			Content string `json:"content"`
+		} `json:"sources"`
+		Settings map[string]interface{} `json:"settings"`
+	}
+	if err := json.Unmarshal(input, &got); err != nil {
+		t.Fatalf("json.Unmarshal(standardJSONInput()) error %v", err)
+	}
+
+	if got.Language != "Solidity" {
+		t.Errorf("standardJSONInput() language got %q; want Solidity", got.Language)
+	}
+	gotSources := make(map[string]string)
+	for k, v := range got.Sources {
+		gotSources[k] = v.Content
+	}
+	wantSources := map[string]string{
+		"src/Foo.sol":               foo,
+		"@openzeppelin/Ownable.sol": ozOw,
+		"literal.sol":               "contract L {}",
+	}
+	if diff := cmp.Diff(wantSources, gotSources); diff != "" {
+		t.Errorf("standardJSONInput() sources diff (-want +got):\n%s", diff)
+	}
+
+	if _, ok := got.Settings["compilationTarget"]; ok {
+		t.Error("standardJSONInput() settings include compilationTarget")
+	}
+	if _, ok := got.Settings["outputSelection"]; !ok {
+		t.Error("standardJSONInput() settings missing outputSelection")
+	}
+	wantLibs := map[string]interface{}{
+		"src/Lib.sol": map[string]interface{}{"Lib": "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+	}
+	if diff := cmp.Diff(wantLibs, got.Settings["libraries"]); diff != "" {
+		t.Errorf("standardJSONInput() libraries diff (-want +got):\n%s", diff)
+	}
+	wantOpt := map[string]interface{}{"enabled": true, "runs": float64(200)}
+	if diff := cmp.Diff(wantOpt, got.Settings["optimizer"]); diff != "" {
+		t.Errorf("standardJSONInput() optimizer diff (-want +got):\n%s", diff)
+	}
+
+	t.Run("modified source", func(t *testing.T) {
+		writeFile(t, filepath.Join(base, "src"), "Foo.sol", foo+"// modified\n")
+		if _, _, err := standardJSONInput([]byte(metadata), []string{base, modules}); err == nil {
+			t.Error("standardJSONInput() with modified source got nil error; want error")
+		}
+	})
+}
+
+func TestMatchContract(t *testing.T) {
+	keys := []string{
+		"src/Foo.sol:Foo",
+		"src/Foo.sol:Bar",
+		"lib/Foo.sol:Foo",
+		"@openzeppelin/Ownable.sol:Ownable",
+	}
+
+	tests := []struct {
+		source, name string
+		want         string
+		wantErr      bool
+	}{
+		{source: "src/Foo.sol", name: "Foo", want: "src/Foo.sol:Foo"},
+		{source: "src/Foo.sol", name: "Bar", want: "src/Foo.sol:Bar"},
+		{source: "./lib/Foo.sol", name: "Foo", want: "lib/Foo.sol:Foo"},
+		{source: "src/Foo.sol", name: "Baz", wantErr: true},
+		{source: "Foo.sol", name: "Foo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := matchContract(keys, tt.source, tt.name)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("matchContract(%q, %q) got err %v; want error = %t", tt.source, tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("matchContract(%q, %q) got %q; want %q", tt.source, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEtherscanClient(t *testing.T) {
+	const (
+		apiKey = "secret"
+		guid   = "abc123"
+	)
+
+	var (
+		mu        sync.Mutex
+		submitted map[string]string
+		checks    int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error %v", err)
+		}
+		if got := r.Form.Get("apikey"); got != apiKey {
+			t.Errorf("request apikey got %q; want %q", got, apiKey)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		var resp etherscanResponse
+		switch action := r.Form.Get("action"); action {
+		case "verifysourcecode":
+			if r.Method != http.MethodPost {
+				t.Errorf("%s method got %s; want POST", action, r.Method)
+			}
+			submitted = make(map[string]string)
+			for k := range r.PostForm {
+				submitted[k] = r.PostForm.Get(k)
+			}
+			resp = etherscanResponse{Status: "1", Message: "OK", Result: guid}
+
+		case "checkverifystatus":
+			if got := r.Form.Get("guid"); got != guid {
+				t.Errorf("%s guid got %q; want %q", action, got, guid)
+			}
+			checks++
+			resp = etherscanResponse{Status: "0", Message: "NOTOK", Result: "Pending in queue"}
+			if checks == 3 {
+				resp = etherscanResponse{Status: "1", Message: "OK", Result: "Pass - Verified"}
+			}
+
+		default:
+			t.Errorf("unexpected action %q", action)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := &etherscanClient{
+		url:    srv.URL,
+		apiKey: apiKey,
+		client: srv.Client(),
+	}
+
+	sub := &etherscanSubmission{
+		address:         "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		contractName:    "src/Foo.sol:Foo",
+		compilerVersion: "v0.8.13+commit.abaa5c0e",
+		input:           []byte(`{"language":"Solidity"}`),
+		constructorArgs: []byte{0xab, 0xcd},
+	}
+	got, err := c.submit(ctx, sub)
+	if err != nil {
+		t.Fatalf("submit() error %v", err)
+	}
+	if got != guid {
+		t.Errorf("submit() got GUID %q; want %q", got, guid)
+	}
+
+	wantSubmitted := map[string]string{
+		"apikey":                apiKey,
+		"module":                "contract",
+		"action":                "verifysourcecode",
+		"contractaddress":       sub.address,
+		"sourceCode":            string(sub.input),
+		"codeformat":            "solidity-standard-json-input",
+		"contractname":          sub.contractName,
+		"compilerversion":       sub.compilerVersion,
+		"constructorArguements": "abcd",
+	}
+	if diff := cmp.Diff(wantSubmitted, submitted); diff != "" {
+		t.Errorf("submit() form diff (-want +got):\n%s", diff)
+	}
+
+	if err := c.waitVerified(ctx, guid, time.Millisecond); err != nil {
+		t.Errorf("waitVerified() error %v", err)
+	}
+	if checks != 3 {
+		t.Errorf("waitVerified() made %d status checks; want 3", checks)
+	}
+}
+
+func TestEtherscanClientErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		resp etherscanResponse
+		// call is either submit() or waitVerified().
+		call    func(context.Context, *etherscanClient) error
+		wantErr error
+	}{
+		{
+			name: "submit already verified",
+			resp: etherscanResponse{Status: "0", Message: "NOTOK", Result: "Contract source code already verified"},
+			call: func(ctx context.Context, c *etherscanClient) error {
+				_, err := c.submit(ctx, &etherscanSubmission{})
+				return err
+			},
+			wantErr: errAlreadyVerified,
+		},
+		{
+			name: "submit rejected",
+			resp: etherscanResponse{Status: "0", Message: "NOTOK", Result: "Invalid API Key"},
+			call: func(ctx context.Context, c *etherscanClient) error {
+				_, err := c.submit(ctx, &etherscanSubmission{})
+				return err
+			},
+		},
+		{
+			name: "verification failed",
+			resp: etherscanResponse{Status: "0", Message: "NOTOK", Result: "Fail - Unable to verify"},
+			call: func(ctx context.Context, c *etherscanClient) error {
+				return c.waitVerified(ctx, "guid", time.Millisecond)
+			},
+		},
+		{
+			name: "timeout",
+			resp: etherscanResponse{Status: "0", Message: "NOTOK", Result: "Pending in queue"},
+			call: func(ctx context.Context, c *etherscanClient) error {
+				ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+				defer cancel()
+				return c.waitVerified(ctx, "guid", time.Millisecond)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(tt.resp)
+			}))
+			defer srv.Close()
+
+			c := &etherscanClient{url: srv.URL, client: srv.Client()}
+			err := tt.call(context.Background(), c)
+			if err == nil {
+				t.Fatal("got nil error; want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v; want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Filters, or streams, contract logs and prints them as decoded JSON lines."
+
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: short,
+		Long: short + `
+
+The --abi file MAY be either a JSON ABI or a build artifact with an "abi" field, as produced by solc, Foundry, and Hardhat. Logs for events not in the ABI are printed with their raw topics and data. Event arguments are printed in the same form accepted by ethier call.
+
+Logs are requested in ranges of at most --batch-size blocks to stay within node limits. With --follow, new blocks are polled for indefinitely.`,
+		RunE: events,
+		Args: cobra.NoArgs,
+	}
+	addRPCFlag(cmd)
+	cmd.Flags().StringSlice("address", nil, "Contract address(es) from which to filter logs; empty = all contracts")
+	cmd.Flags().String("abi", "", "JSON file containing the ABI with which to decode logs")
+	cmd.Flags().StringSlice("event", nil, "Only include the named event(s), which MUST be in the ABI")
+	cmd.Flags().Uint64("from-block", 0, "First block from which to filter logs")
+	cmd.Flags().Int64("to-block", -1, "Last block from which to filter logs; -1 = latest; ignored with --follow")
+	cmd.Flags().Bool("follow", false, "Poll for logs in new blocks until interrupted")
+	cmd.Flags().Duration("poll", 4*time.Second, "Interval at which new blocks are polled for with --follow")
+	cmd.Flags().Uint64("batch-size", 2000, "Maximum number of blocks per log request")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// events implements the `ethier events` command.
+func events(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	addrs, err := flags.GetStringSlice("address")
+	if err != nil {
+		return err
+	}
+	abiPath, err := flags.GetString("abi")
+	if err != nil {
+		return err
+	}
+	names, err := flags.GetStringSlice("event")
+	if err != nil {
+		return err
+	}
+	from, err := flags.GetUint64("from-block")
+	if err != nil {
+		return err
+	}
+	to, err := flags.GetInt64("to-block")
+	if err != nil {
+		return err
+	}
+	follow, err := flags.GetBool("follow")
+	if err != nil {
+		return err
+	}
+	poll, err := flags.GetDuration("poll")
+	if err != nil {
+		return err
+	}
+	batch, err := flags.GetUint64("batch-size")
+	if err != nil {
+		return err
+	}
+
+	dec := new(logDecoder)
+	if abiPath != "" {
+		f, err := os.Open(abiPath)
+		if err != nil {
+			return fmt.Errorf("open --abi: %v", err)
+		}
+		defer f.Close()
+		if dec.abi, err = readABI(f); err != nil {
+			return err
+		}
+	}
+
+	var q ethereum.FilterQuery
+	for _, a := range addrs {
+		addr, err := eth.ParseAddress(a)
+		if err != nil {
+			return fmt.Errorf("--address: %v", err)
+		}
+		q.Addresses = append(q.Addresses, addr)
+	}
+	if len(names) > 0 {
+		if q.Topics, err = dec.eventTopics(names); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	s := &logStreamer{
+		client: client,
+		query:  q,
+		batch:  batch,
+		poll:   poll,
+		follow: follow,
+	}
+	if !follow && to >= 0 {
+		last := uint64(to)
+		s.to = &last
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	return s.stream(ctx, from, func(l types.Log) error {
+		return enc.Encode(dec.decode(l))
+	})
+}
+
+// readABI parses a JSON ABI from r, which MAY be either the ABI itself or an
+// object with an "abi" field.
+func readABI(r io.Reader) (*abi.ABI, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read ABI: %v", err)
+	}
+	buf = bytes.TrimSpace(buf)
+
+	if len(buf) > 0 && buf[0] == '{' {
+		var artifact struct {
+			ABI json.RawMessage `json:"abi"`
+		}
+		if err := json.Unmarshal(buf, &artifact); err != nil {
+			return nil, fmt.Errorf("decode artifact: %v", err)
+		}
+		if len(artifact.ABI) == 0 {
+			return nil, errors.New("JSON object without abi field")
+		}
+		buf = artifact.ABI
+	}
+
+	parsed, err := abi.JSON(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("parse ABI: %v", err)
+	}
+	return &parsed, nil
+}
+
+// A logDecoder converts logs to JSON-friendly decodedLogs, using the abi if
+// non-nil.
+type logDecoder struct {
+	abi *abi.ABI
+}
+
+// eventTopics returns topic filters matching any of the named events.
+func (d *logDecoder) eventTopics(names []string) ([][]common.Hash, error) {
+	if d.abi == nil {
+		return nil, errors.New("--event requires --abi")
+	}
+	var ids []common.Hash
+	for _, n := range names {
+		ev, ok := d.abi.Events[n]
+		if !ok {
+			return nil, fmt.Errorf("event %q not in ABI", n)
+		}
+		ids = append(ids, ev.ID)
+	}
+	return [][]common.Hash{ids}, nil
+}
+
+// A decodedLog is a single line of output from `ethier events`.
+type decodedLog struct {
+	BlockNumber uint64         `json:"blockNumber"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	LogIndex    uint           `json:"logIndex"`
+	Address     common.Address `json:"address"`
+	// Event and Args are only populated if the log was decoded, otherwise
+	// Topics and Data are.
+	Event string            `json:"event,omitempty"`
+	Args  map[string]string `json:"args,omitempty"`
+	// Error describes why decoding failed, if it did.
+	Error  string        `json:"error,omitempty"`
+	Topics []common.Hash `json:"topics,omitempty"`
+	Data   hexutil.Bytes `json:"data,omitempty"`
+}
+
+// decode returns the decoded form of the log, falling back to its raw
+// topics and data if the event is unknown or can't be decoded.
+func (d *logDecoder) decode(l types.Log) *decodedLog {
+	out := &decodedLog{
+		BlockNumber: l.BlockNumber,
+		TxHash:      l.TxHash,
+		LogIndex:    l.Index,
+		Address:     l.Address,
+	}
+
+	args, name, err := d.decodeArgs(l)
+	if err != nil {
+		out.Error = err.Error()
+	}
+	if args == nil {
+		out.Topics = l.Topics
+		out.Data = l.Data
+		return out
+	}
+	out.Event = name
+	out.Args = args
+	return out
+}
+
+// decodeArgs returns the named and formatted arguments of the log's event, or
+// nil if the event isn't in the ABI.
+func (d *logDecoder) decodeArgs(l types.Log) (map[string]string, string, error) {
+	if d.abi == nil || len(l.Topics) == 0 {
+		return nil, "", nil
+	}
+	ev, err := d.abi.EventByID(l.Topics[0])
+	if err != nil {
+		return nil, "", nil
+	}
+
+	vals := make(map[string]interface{})
+	if len(l.Data) > 0 {
+		if err := ev.Inputs.UnpackIntoMap(vals, l.Data); err != nil {
+			return nil, "", fmt.Errorf("unpack %s data: %v", ev.Name, err)
+		}
+	}
+	var indexed abi.Arguments
+	for _, in := range ev.Inputs {
+		if in.Indexed {
+			indexed = append(indexed, in)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(vals, indexed, l.Topics[1:]); err != nil {
+		return nil, "", fmt.Errorf("parse %s topics: %v", ev.Name, err)
+	}
+
+	args := make(map[string]string)
+	for i, in := range ev.Inputs {
+		name := in.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		v, ok := vals[in.Name]
+		if !ok {
+			continue
+		}
+		if in.Indexed && isHashedTopic(in.Type) {
+			// Dynamic indexed arguments are only available as their hash.
+			v = common.BytesToHash(l.Topics[indexedPosition(ev.Inputs, i)].Bytes())
+		}
+		args[name] = formatABIValue(v)
+	}
+	return args, ev.Name, nil
+}
+
+// isHashedTopic returns whether an indexed argument of the type is stored as
+// the keccak256 hash of its value.
+func isHashedTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+// indexedPosition returns the topic index of the i'th input, which MUST be
+// indexed.
+func indexedPosition(inputs abi.Arguments, i int) int {
+	pos := 1 // topic 0 is the event ID
+	for _, in := range inputs[:i] {
+		if in.Indexed {
+			pos++
+		}
+	}
+	return pos
+}
+
+// A logClient is the subset of a client required by a logStreamer.
+type logClient interface {
+	ethereum.LogFilterer
+	HeaderByNumber(context.Context, *big.Int) (*types.Header, error)
+}
+
+// A logStreamer requests logs matching a query in batches of blocks.
+type logStreamer struct {
+	client logClient
+	query  ethereum.FilterQuery
+	batch  uint64
+	// to is the last block to include; if nil, the latest block at the
+	// time of the call to stream() is used unless following.
+	to     *uint64
+	follow bool
+	poll   time.Duration
+}
+
+// stream calls emit() for every matching log, in order, from the specified
+// block onwards. If s.follow is true, it only returns on error.
+func (s *logStreamer) stream(ctx context.Context, from uint64, emit func(types.Log) error) error {
+	batch := s.batch
+	if batch == 0 {
+		batch = 1
+	}
+
+	last := func() (uint64, error) {
+		if s.to != nil {
+			return *s.to, nil
+		}
+		head, err := s.client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return 0, fmt.Errorf("get latest header: %v", err)
+		}
+		return head.Number.Uint64(), nil
+	}
+
+	end, err := last()
+	if err != nil {
+		return err
+	}
+	for {
+		for ; from <= end; from += batch {
+			q := s.query
+			q.FromBlock = new(big.Int).SetUint64(from)
+			to := from + batch - 1
+			if to > end {
+				to = end
+			}
+			q.ToBlock = new(big.Int).SetUint64(to)
+
+			logs, err := s.client.FilterLogs(ctx, q)
+			if err != nil {
+				return fmt.Errorf("filter logs in blocks [%d,%d]: %v", from, to, err)
+			}
+			for _, l := range logs {
+				if err := emit(l); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !s.follow {
+			return nil
+		}
+		select {
+		case <-time.After(s.poll):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if end, err = last(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/ethtest"
+)
+
+const pingABI = `[{"type":"event","name":"Ping","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}]`
+
+// deployPinger deploys a minimal contract that, for every call, emits
+// Ping(msg.sender, <first 32 bytes of calldata>).
+func deployPinger(t *testing.T, sim *ethtest.SimulatedBackend) (common.Address, *bind.BoundContract) {
+	t.Helper()
+
+	parsed, err := abi.JSON(strings.NewReader(pingABI))
+	if err != nil {
+		t.Fatalf("abi.JSON(%q) error %v", pingABI, err)
+	}
+
+	topic := crypto.Keccak256Hash([]byte("Ping(address,uint256)"))
+	runtime := fmt.Sprintf(
+		// CALLDATACOPY(0, 0, 32); LOG2(0, 32, topic, CALLER); STOP
+		"6020600060003733%x%x60206000a200",
+		0x7f, topic.Bytes(),
+	)
+	// CODECOPY(0, len(init), len(runtime)); RETURN(0, len(runtime))
+	code := hexutil.MustDecode(fmt.Sprintf("0x60%02x80600b6000396000f3%s", len(runtime)/2, runtime))
+
+	addr, _, contract, err := bind.DeployContract(sim.Acc(0), parsed, code, sim)
+	if err != nil {
+		t.Fatalf("bind.DeployContract() error %v", err)
+	}
+	return addr, contract
+}
+
+func TestLogStreamer(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, 1)
+	addr, pinger := deployPinger(t, sim)
+
+	ping := func(v int64) {
+		t.Helper()
+		if _, err := pinger.RawTransact(sim.Acc(0), common.BigToHash(big.NewInt(v)).Bytes()); err != nil {
+			t.Fatalf("Ping(%d) error %v", v, err)
+		}
+	}
+	for i := int64(1); i <= 3; i++ {
+		ping(i)
+	}
+
+	parsed, err := readABI(strings.NewReader(pingABI))
+	if err != nil {
+		t.Fatalf("readABI() error %v", err)
+	}
+	dec := &logDecoder{abi: parsed}
+	from := sim.Addr(0).Hex()
+
+	wantArgs := func(vals ...int64) []map[string]string {
+		var want []map[string]string
+		for _, v := range vals {
+			want = append(want, map[string]string{
+				"from":  from,
+				"value": fmt.Sprintf("%d", v),
+			})
+		}
+		return want
+	}
+
+	t.Run("range", func(t *testing.T) {
+		for _, batch := range []uint64{1, 2, 100} {
+			s := &logStreamer{
+				client: sim,
+				query:  ethereum.FilterQuery{Addresses: []common.Address{addr}},
+				batch:  batch,
+			}
+
+			var got []map[string]string
+			err := s.stream(ctx, 0, func(l types.Log) error {
+				d := dec.decode(l)
+				if d.Event != "Ping" {
+					t.Errorf("decode(%+v).Event got %q; want Ping", l, d.Event)
+				}
+				got = append(got, d.Args)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("stream(batch = %d) error %v", batch, err)
+			}
+			if diff := cmp.Diff(wantArgs(1, 2, 3), got); diff != "" {
+				t.Errorf("stream(batch = %d) decoded args diff (-want +got):\n%s", batch, diff)
+			}
+		}
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		s := &logStreamer{
+			client: sim,
+			query:  ethereum.FilterQuery{Addresses: []common.Address{addr}},
+			batch:  1,
+			follow: true,
+			poll:   10 * time.Millisecond,
+		}
+
+		errDone := errors.New("done")
+		var got []map[string]string
+		errc := make(chan error)
+		go func() {
+			errc <- s.stream(ctx, sim.BlockNumber().Uint64()+1, func(l types.Log) error {
+				got = append(got, dec.decode(l).Args)
+				if len(got) == 2 {
+					return errDone
+				}
+				return nil
+			})
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		ping(4)
+		ping(5)
+
+		select {
+		case err := <-errc:
+			if !errors.Is(err, errDone) {
+				t.Fatalf("stream(follow) error %v; want %v", err, errDone)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("stream(follow) did not receive new logs")
+		}
+		if diff := cmp.Diff(wantArgs(4, 5), got); diff != "" {
+			t.Errorf("stream(follow) decoded args diff (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestLogDecoderUnknownEvent(t *testing.T) {
+	parsed, err := readABI(strings.NewReader(`{"abi":` + pingABI + `}`))
+	if err != nil {
+		t.Fatalf("readABI(<artifact>) error %v", err)
+	}
+
+	l := types.Log{
+		Topics: []common.Hash{crypto.Keccak256Hash([]byte("Pong()"))},
+		Data:   []byte{42},
+	}
+	for _, dec := range []*logDecoder{{}, {abi: parsed}} {
+		got := dec.decode(l)
+		want := &decodedLog{
+			Topics: l.Topics,
+			Data:   l.Data,
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("decode(<unknown event>) diff (-want +got):\n%s", diff)
+		}
+	}
+}
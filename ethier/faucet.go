@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Funds accounts with ETH on a local development chain."
+
+	cmd := &cobra.Command{
+		Use:   "faucet <address>...",
+		Short: short,
+		Long: short + `
+
+Each address's balance is increased by --amount, which is in ETH unless it has a unit suffix, e.g. 10eth or 1e18wei. With --method auto, anvil_setBalance and hardhat_setBalance are tried first, falling back on sending a transaction from the node's first unlocked account, as with geth --dev; either can be selected explicitly with --method set-balance or send.
+
+As a safeguard against unlocked accounts on other networks, the node's chain ID MUST be that of a development chain (1337 or 31337) unless --force is set.`,
+		RunE: faucet,
+		Args: cobra.MinimumNArgs(1),
+	}
+	addRPCFlag(cmd)
+	cmd.Flags().String("amount", "10eth", "Amount by which to increase each balance")
+	cmd.Flags().String("method", "auto", "Funding method: auto, set-balance, or send")
+	cmd.Flags().Duration("timeout", 30*time.Second, "Maximum time to wait for each transaction to be mined with --method send")
+	cmd.Flags().Bool("force", false, "Allow funding on chains other than 1337 and 31337")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// faucet implements the `ethier faucet` command.
+func faucet(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	amountStr, err := flags.GetString("amount")
+	if err != nil {
+		return err
+	}
+	method, err := flags.GetString("method")
+	if err != nil {
+		return err
+	}
+	timeout, err := flags.GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	force, err := flags.GetBool("force")
+	if err != nil {
+		return err
+	}
+
+	weiStr, err := convertUnits(amountStr, "eth", "wei", 0)
+	if err != nil {
+		return fmt.Errorf("--amount: %v", err)
+	}
+	amount, _ := new(big.Int).SetString(weiStr, 10)
+
+	var addrs []common.Address
+	for _, a := range args {
+		addr, err := eth.ParseAddress(a)
+		if err != nil {
+			return err
+		}
+		addrs = append(addrs, addr)
+	}
+
+	ctx := context.Background()
+	client, err := rpcClientFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	f := &devFaucet{rpc: client, method: method, poll: 100 * time.Millisecond, timeout: timeout}
+	if !force {
+		if err := f.checkDevChain(ctx); err != nil {
+			return err
+		}
+	}
+	for _, addr := range addrs {
+		bal, via, err := f.fund(ctx, addr, amount)
+		if err != nil {
+			return fmt.Errorf("fund %v: %v", addr, err)
+		}
+		fmt.Printf("%v: %s ETH (via %s)\n", addr, formatDecimal(new(big.Rat).SetFrac(bal, big.NewInt(params.Ether))), via)
+	}
+	return nil
+}
+
+// An rpcCaller makes JSON-RPC calls; it is implemented by *rpc.Client.
+type rpcCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// devChainIDs are the chain IDs on which `ethier faucet` runs without --force;
+// 1337 is used by geth --dev and 31337 by anvil and hardhat.
+var devChainIDs = map[uint64]bool{1337: true, 31337: true}
+
+// A devFaucet funds accounts on a development chain.
+type devFaucet struct {
+	rpc rpcCaller
+	// method is auto, set-balance, or send.
+	method        string
+	poll, timeout time.Duration
+}
+
+// checkDevChain returns an error if the node's chain ID isn't in devChainIDs.
+func (f *devFaucet) checkDevChain(ctx context.Context) error {
+	var id hexutil.Big
+	if err := f.rpc.CallContext(ctx, &id, "eth_chainId"); err != nil {
+		return fmt.Errorf("eth_chainId: %v", err)
+	}
+	b := id.ToInt()
+	if !b.IsUint64() || !devChainIDs[b.Uint64()] {
+		return fmt.Errorf("chain ID %v isn't a development chain; use --force to fund anyway", b)
+	}
+	return nil
+}
+
+// setBalanceMethods are the RPC methods tried, in order, to set a balance
+// directly.
+var setBalanceMethods = []string{"anvil_setBalance", "hardhat_setBalance"}
+
+// fund increases the address's balance by amount, returning the new balance
+// and a description of how it was funded.
+func (f *devFaucet) fund(ctx context.Context, addr common.Address, amount *big.Int) (*big.Int, string, error) {
+	var bal hexutil.Big
+	if err := f.rpc.CallContext(ctx, &bal, "eth_getBalance", addr, "latest"); err != nil {
+		return nil, "", fmt.Errorf("eth_getBalance: %v", err)
+	}
+	want := new(big.Int).Add(bal.ToInt(), amount)
+
+	var setErrs []error
+	switch f.method {
+	case "auto", "set-balance":
+		for _, m := range setBalanceMethods {
+			err := f.rpc.CallContext(ctx, nil, m, addr, (*hexutil.Big)(want))
+			if err == nil {
+				return want, m, nil
+			}
+			setErrs = append(setErrs, fmt.Errorf("%s: %v", m, err))
+		}
+		if f.method == "set-balance" {
+			return nil, "", fmt.Errorf("no set-balance method supported: %v", setErrs)
+		}
+	case "send":
+	default:
+		return nil, "", fmt.Errorf("unsupported method %q", f.method)
+	}
+
+	hash, err := f.send(ctx, addr, amount)
+	if err != nil {
+		if len(setErrs) > 0 {
+			return nil, "", fmt.Errorf("%v; after failing to set balance: %v", err, setErrs)
+		}
+		return nil, "", err
+	}
+	return want, fmt.Sprintf("transaction %v", hash), nil
+}
+
+// send sends amount to the address from the node's first unlocked account and
+// waits for the transaction to be mined.
+func (f *devFaucet) send(ctx context.Context, to common.Address, amount *big.Int) (common.Hash, error) {
+	var accounts []common.Address
+	if err := f.rpc.CallContext(ctx, &accounts, "eth_accounts"); err != nil {
+		return common.Hash{}, fmt.Errorf("eth_accounts: %v", err)
+	}
+	if len(accounts) == 0 {
+		return common.Hash{}, errors.New("node has no unlocked accounts")
+	}
+
+	tx := map[string]interface{}{
+		"from":  accounts[0],
+		"to":    to,
+		"value": (*hexutil.Big)(amount),
+	}
+	var hash common.Hash
+	if err := f.rpc.CallContext(ctx, &hash, "eth_sendTransaction", tx); err != nil {
+		return common.Hash{}, fmt.Errorf("eth_sendTransaction from %v: %v", accounts[0], err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+	for {
+		var receipt *struct {
+			Status hexutil.Uint64 `json:"status"`
+		}
+		if err := f.rpc.CallContext(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+			return hash, fmt.Errorf("eth_getTransactionReceipt(%v): %v", hash, err)
+		}
+		if receipt != nil {
+			if receipt.Status != 1 {
+				return hash, fmt.Errorf("transaction %v failed", hash)
+			}
+			return hash, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return hash, fmt.Errorf("transaction %v not mined: %v", hash, ctx.Err())
+		case <-time.After(f.poll):
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeDevEth implements the eth namespace methods used by devFaucet.
+type fakeDevEth struct {
+	chainID  uint64
+	balances map[common.Address]*big.Int
+	accounts []common.Address
+	// pending is the number of receipt polls before a sent transaction is
+	// mined.
+	pending int
+}
+
+func (e *fakeDevEth) ChainId() hexutil.Uint64 {
+	return hexutil.Uint64(e.chainID)
+}
+
+func (e *fakeDevEth) GetBalance(addr common.Address, _ string) *hexutil.Big {
+	b, ok := e.balances[addr]
+	if !ok {
+		b = new(big.Int)
+	}
+	return (*hexutil.Big)(b)
+}
+
+func (e *fakeDevEth) Accounts() []common.Address {
+	return e.accounts
+}
+
+type fakeTx struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+}
+
+func (e *fakeDevEth) SendTransaction(tx fakeTx) (common.Hash, error) {
+	if len(e.accounts) == 0 || tx.From != e.accounts[0] {
+		return common.Hash{}, errors.New("unknown account")
+	}
+	bal := e.GetBalance(tx.To, "latest").ToInt()
+	e.balances[tx.To] = new(big.Int).Add(bal, tx.Value.ToInt())
+	return common.Hash{1}, nil
+}
+
+func (e *fakeDevEth) GetTransactionReceipt(common.Hash) map[string]interface{} {
+	if e.pending > 0 {
+		e.pending--
+		return nil
+	}
+	return map[string]interface{}{"status": "0x1"}
+}
+
+// fakeAnvil implements anvil_setBalance.
+type fakeAnvil struct {
+	eth *fakeDevEth
+}
+
+func (a *fakeAnvil) SetBalance(addr common.Address, bal *hexutil.Big) {
+	a.eth.balances[addr] = bal.ToInt()
+}
+
+func TestDevFaucet(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0xfa")
+	dev := common.HexToAddress("0xde")
+	ten := new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18))
+
+	// dial returns a client for an in-process RPC server with the eth
+	// namespace and, if anvil is true, anvil_setBalance.
+	dial := func(t *testing.T, e *fakeDevEth, anvil bool) *rpc.Client {
+		t.Helper()
+		srv := rpc.NewServer()
+		if err := srv.RegisterName("eth", e); err != nil {
+			t.Fatalf("RegisterName(eth) error %v", err)
+		}
+		if anvil {
+			if err := srv.RegisterName("anvil", &fakeAnvil{eth: e}); err != nil {
+				t.Fatalf("RegisterName(anvil) error %v", err)
+			}
+		}
+		t.Cleanup(srv.Stop)
+		c := rpc.DialInProc(srv)
+		t.Cleanup(c.Close)
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		anvil   bool
+		method  string
+		wantVia string
+		wantErr bool
+	}{
+		{
+			name:    "anvil auto",
+			anvil:   true,
+			method:  "auto",
+			wantVia: "anvil_setBalance",
+		},
+		{
+			name:    "anvil send",
+			anvil:   true,
+			method:  "send",
+			wantVia: "transaction 0x01",
+		},
+		{
+			name:    "geth auto",
+			method:  "auto",
+			wantVia: "transaction 0x01",
+		},
+		{
+			name:    "geth set-balance",
+			method:  "set-balance",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported method",
+			method:  "magic",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &fakeDevEth{
+				chainID:  1337,
+				balances: map[common.Address]*big.Int{addr: big.NewInt(1)},
+				accounts: []common.Address{dev},
+				pending:  2,
+			}
+			f := &devFaucet{rpc: dial(t, e, tt.anvil), method: tt.method, poll: time.Millisecond, timeout: time.Second}
+
+			if err := f.checkDevChain(ctx); err != nil {
+				t.Fatalf("checkDevChain() error %v", err)
+			}
+			bal, via, err := f.fund(ctx, addr, ten)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("fund() got err %v; want error = %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			want := new(big.Int).Add(ten, big.NewInt(1))
+			if bal.Cmp(want) != 0 || e.balances[addr].Cmp(want) != 0 {
+				t.Errorf("fund() got balance %v, node balance %v; want %v", bal, e.balances[addr], want)
+			}
+			if !strings.HasPrefix(via, tt.wantVia) {
+				t.Errorf("fund() got via %q; want prefix %q", via, tt.wantVia)
+			}
+		})
+	}
+
+	t.Run("non-development chain", func(t *testing.T) {
+		f := &devFaucet{rpc: dial(t, &fakeDevEth{chainID: 1}, false)}
+		if err := f.checkDevChain(ctx); err == nil {
+			t.Errorf("checkDevChain() on chain 1 got nil error")
+		}
+	})
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	const short = "Flattens a Solidity file and its imports into a single file."
+
+	cmd := &cobra.Command{
+		Use:   "flatten <file.sol>",
+		Short: short,
+		Long: short + `
+
+Imports are resolved as by ethier gen, relative to the Go module root and its node_modules directory, after applying remappings from remappings.txt in the module root, if present, and from --remap. Every file is included exactly once, after all of its dependencies. Import statements are removed, pragmas are deduplicated, and SPDX license identifiers are combined into a single expression.
+
+Aliased imports, e.g. import {A as B} from "...", can't be flattened and are rejected.`,
+		RunE: flatten,
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().StringSlice("remap", nil, "Import remapping(s) of the form prefix=target")
+	cmd.Flags().StringP("out", "o", "", "File to which the flattened source is written; defaults to stdout")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// flatten implements the `ethier flatten` command.
+func flatten(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	remaps, err := flags.GetStringSlice("remap")
+	if err != nil {
+		return err
+	}
+	out, err := flags.GetString("out")
+	if err != nil {
+		return err
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("os.Getwd(): %v", err)
+	}
+	basePath, includePath := solcPaths(pwd)
+
+	f := &flattener{paths: []string{basePath, includePath}}
+	if buf, err := os.ReadFile(filepath.Join(basePath, "remappings.txt")); err == nil {
+		if err := f.addRemappings(bytes.NewReader(buf)); err != nil {
+			return fmt.Errorf("remappings.txt: %v", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read remappings.txt: %v", err)
+	}
+	if err := f.addRemappings(strings.NewReader(strings.Join(remaps, "\n"))); err != nil {
+		return fmt.Errorf("--remap: %v", err)
+	}
+
+	abs, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("filepath.Abs(%q): %v", args[0], err)
+	}
+	unit := filepath.ToSlash(abs)
+	if rel, err := filepath.Rel(basePath, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		unit = filepath.ToSlash(rel)
+	}
+
+	flat, err := f.flatten(unit)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err := os.Stdout.Write(flat)
+		return err
+	}
+	return os.WriteFile(out, flat, 0644)
+}
+
+// A remapping is a solc import remapping, optionally limited to imports from
+// files within context.
+type remapping struct {
+	context, prefix, target string
+}
+
+// A flattener combines Solidity source units and their imports into a single
+// file.
+type flattener struct {
+	// paths are searched, in order, for source units.
+	paths      []string
+	remappings []remapping
+
+	visited map[string]bool
+	order   []string
+	sources map[string]string
+}
+
+// addRemappings parses remappings, one per line, of the form
+// [context:]prefix=target.
+func (f *flattener) addRemappings(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		eq := strings.IndexByte(l, '=')
+		if eq <= 0 {
+			return fmt.Errorf("invalid remapping %q", l)
+		}
+		rm := remapping{prefix: l[:eq], target: l[eq+1:]}
+		if colon := strings.IndexByte(rm.prefix, ':'); colon != -1 {
+			rm.context, rm.prefix = rm.prefix[:colon], rm.prefix[colon+1:]
+		}
+		f.remappings = append(f.remappings, rm)
+	}
+	return s.Err()
+}
+
+// remap applies the remapping with the longest matching context and then
+// prefix, as solc does, to the import path from the importer.
+func (f *flattener) remap(importer, imp string) string {
+	var best *remapping
+	for i, rm := range f.remappings {
+		if !strings.HasPrefix(importer, rm.context) || !strings.HasPrefix(imp, rm.prefix) {
+			continue
+		}
+		if best == nil || len(rm.context) > len(best.context) || (len(rm.context) == len(best.context) && len(rm.prefix) >= len(best.prefix)) {
+			best = &f.remappings[i]
+		}
+	}
+	if best == nil {
+		return imp
+	}
+	return best.target + strings.TrimPrefix(imp, best.prefix)
+}
+
+// sourceUnit returns the source unit name of the import from the importer.
+func (f *flattener) sourceUnit(importer, imp string) string {
+	if strings.HasPrefix(imp, "./") || strings.HasPrefix(imp, "../") {
+		imp = path.Join(path.Dir(importer), imp)
+	}
+	return f.remap(importer, imp)
+}
+
+// read returns the contents of the source unit from the first of f.paths in
+// which it's found.
+func (f *flattener) read(unit string) (string, error) {
+	if filepath.IsAbs(unit) {
+		buf, err := os.ReadFile(unit)
+		if err != nil {
+			return "", fmt.Errorf("read %q: %v", unit, err)
+		}
+		return string(buf), nil
+	}
+	return readSourceUnit(unit, f.paths)
+}
+
+var (
+	importStmt = regexp.MustCompile(`(?m)^\s*import\s[^;]*;[ \t]*\n?`)
+	importPath = regexp.MustCompile(`["']([^"']+)["']`)
+	importAs   = regexp.MustCompile(`\bas\b`)
+	pragmaStmt = regexp.MustCompile(`(?m)^\s*pragma\s[^;]*;[ \t]*\n?`)
+	spdxLine   = regexp.MustCompile(`(?m)^[ \t]*//[ \t]*SPDX-License-Identifier:[ \t]*([^\n]*?)[ \t]*(?:\n|$)`)
+)
+
+// flatten returns the flattened source of the unit.
+func (f *flattener) flatten(unit string) ([]byte, error) {
+	f.visited = make(map[string]bool)
+	f.order = nil
+	f.sources = make(map[string]string)
+	if err := f.visit(unit); err != nil {
+		return nil, err
+	}
+
+	var (
+		licenses, pragmas []string
+		seen              = make(map[string]bool)
+		bodies            bytes.Buffer
+	)
+	for _, u := range f.order {
+		src := f.sources[u]
+
+		for _, m := range spdxLine.FindAllStringSubmatch(src, -1) {
+			if l := m[1]; !seen["spdx:"+l] {
+				seen["spdx:"+l] = true
+				licenses = append(licenses, l)
+			}
+		}
+		for _, p := range pragmaStmt.FindAllString(src, -1) {
+			p = strings.Join(strings.Fields(p), " ")
+			if !seen[p] {
+				seen[p] = true
+				pragmas = append(pragmas, p)
+			}
+		}
+
+		src = spdxLine.ReplaceAllString(src, "")
+		src = pragmaStmt.ReplaceAllString(src, "")
+		src = importStmt.ReplaceAllString(src, "")
+		fmt.Fprintf(&bodies, "\n// File: %s\n\n%s\n", u, strings.TrimSpace(src))
+	}
+
+	var out bytes.Buffer
+	if len(licenses) > 0 {
+		sort.Strings(licenses)
+		if len(licenses) > 1 {
+			for i, l := range licenses {
+				if strings.ContainsAny(l, " \t") {
+					licenses[i] = "(" + l + ")"
+				}
+			}
+		}
+		fmt.Fprintf(&out, "// SPDX-License-Identifier: %s\n", strings.Join(licenses, " AND "))
+	}
+	fmt.Fprintf(&out, "// Flattened by ethier from %s\n\n", unit)
+	for _, p := range pragmas {
+		fmt.Fprintln(&out, p)
+	}
+	out.Write(bodies.Bytes())
+	return out.Bytes(), nil
+}
+
+// visit reads the unit and, depth first, all of its imports, appending each
+// to f.order after its dependencies. Cyclic imports are only visited once.
+func (f *flattener) visit(unit string) error {
+	if f.visited[unit] {
+		return nil
+	}
+	f.visited[unit] = true
+
+	src, err := f.read(unit)
+	if err != nil {
+		return err
+	}
+	f.sources[unit] = src
+
+	for _, stmt := range importStmt.FindAllString(src, -1) {
+		m := importPath.FindStringSubmatch(stmt)
+		if m == nil {
+			return fmt.Errorf("%s: no path in %q", unit, strings.TrimSpace(stmt))
+		}
+		if importAs.MatchString(importPath.ReplaceAllString(stmt, "")) {
+			return fmt.Errorf("%s: aliased import %q can't be flattened", unit, strings.TrimSpace(stmt))
+		}
+		if err := f.visit(f.sourceUnit(unit, m[1])); err != nil {
+			return err
+		}
+	}
+
+	f.order = append(f.order, unit)
+	return nil
+}
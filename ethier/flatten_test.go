@@ -0,0 +1,109 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFlatten(t *testing.T) {
+	base := t.TempDir()
+	modules := filepath.Join(base, "node_modules")
+
+	writeFile(t, filepath.Join(base, "src"), "Token.sol", `// SPDX-License-Identifier: MIT
+pragma solidity >=0.8.0 <0.9.0;
+
+import "./Base.sol";
+import {
+    Lib,
+    Other
+} from "@oz/Lib.sol";
+
+contract Token is Base {}
+`)
+	writeFile(t, filepath.Join(base, "src"), "Base.sol", `// SPDX-License-Identifier: MIT
+pragma solidity >=0.8.0 <0.9.0;
+
+import '../vendor/Lib.sol';
+
+contract Base {}
+`)
+	writeFile(t, filepath.Join(modules, "openzeppelin"), "Lib.sol", `// SPDX-License-Identifier: Apache-2.0
+pragma solidity >=0.8.0 <0.9.0;
+pragma abicoder v2;
+
+library Lib {}
+library Other {}
+`)
+
+	f := &flattener{paths: []string{base, modules}}
+	remaps := "# comment\n@oz/=openzeppelin/\nsrc/:vendor/=openzeppelin/\n"
+	if err := f.addRemappings(strings.NewReader(remaps)); err != nil {
+		t.Fatalf("addRemappings(%q) error %v", remaps, err)
+	}
+
+	got, err := f.flatten("src/Token.sol")
+	if err != nil {
+		t.Fatalf("flatten() error %v", err)
+	}
+
+	want := `// SPDX-License-Identifier: Apache-2.0 AND MIT
+// Flattened by ethier from src/Token.sol
+
+pragma solidity >=0.8.0 <0.9.0;
+pragma abicoder v2;
+
+// File: openzeppelin/Lib.sol
+
+library Lib {}
+library Other {}
+
+// File: src/Base.sol
+
+contract Base {}
+
+// File: src/Token.sol
+
+contract Token is Base {}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("flatten() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestFlattenErrors(t *testing.T) {
+	tests := []struct {
+		name, src, wantErr string
+	}{
+		{
+			name:    "aliased symbol",
+			src:     `import {A as B} from "./A.sol";`,
+			wantErr: "aliased import",
+		},
+		{
+			name:    "aliased unit",
+			src:     `import * as A from "./A.sol";`,
+			wantErr: "aliased import",
+		},
+		{
+			name:    "missing import",
+			src:     `import "./Missing.sol";`,
+			wantErr: "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "Main.sol", tt.src)
+			writeFile(t, dir, "A.sol", "contract A {}")
+
+			f := &flattener{paths: []string{dir}}
+			if _, err := f.flatten("Main.sol"); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("flatten() got err %v; want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/ethtest"
+)
+
+func init() {
+	const short = "Runs Go tests against the simulated backend and reports gas used per contract method."
+
+	cmd := &cobra.Command{
+		Use:   "gas-report [packages...] [-- go test flags...]",
+		Short: short,
+		Long: short + `
+
+Packages default to ./... and any arguments after -- are passed to go test, e.g. -run. Every transaction committed by an ethtest.SimulatedBackend is recorded, and aggregated by contract and method; test output is written to stderr so that the report, on stdout, can be redirected.
+
+Contracts are named as registered by ethier gen bindings, otherwise by address. Methods are named from the function signatures in the tested packages' bindings, falling back on the signatures bundled with ethier selector.
+
+With --baseline, the mean gas of each method is compared to that in a report previously output with --format json.`,
+		RunE: gasReport,
+	}
+	cmd.Flags().String("format", "markdown", "Output format: markdown or json")
+	cmd.Flags().String("baseline", "", "JSON report against which to compare mean gas")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// gasReport implements the `ethier gas-report` command.
+func gasReport(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	format, err := flags.GetString("format")
+	if err != nil {
+		return err
+	}
+	baselinePath, err := flags.GetString("baseline")
+	if err != nil {
+		return err
+	}
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("unsupported --format %q", format)
+	}
+
+	pkgs, testArgs := args, []string(nil)
+	if n := cmd.ArgsLenAtDash(); n != -1 {
+		pkgs, testArgs = args[:n], args[n:]
+	}
+	if len(pkgs) == 0 {
+		pkgs = []string{"./..."}
+	}
+
+	var baseline []gasStats
+	if baselinePath != "" {
+		buf, err := os.ReadFile(baselinePath)
+		if err != nil {
+			return fmt.Errorf("read --baseline: %v", err)
+		}
+		if err := json.Unmarshal(buf, &baseline); err != nil {
+			return fmt.Errorf("decode --baseline: %v", err)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "ethier-gas-report-")
+	if err != nil {
+		return fmt.Errorf("create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	goTest := exec.Command("go", append(append([]string{"test", "-count=1"}, pkgs...), testArgs...)...)
+	goTest.Env = append(os.Environ(), ethtest.GasReportDirEnvVar+"="+dir)
+	goTest.Stdout = os.Stderr
+	goTest.Stderr = os.Stderr
+	if err := goTest.Run(); err != nil {
+		return fmt.Errorf("`go test` returned: %v", err)
+	}
+
+	recs, err := readGasRecords(dir)
+	if err != nil {
+		return err
+	}
+	names, err := selectorNames(pkgs)
+	if err != nil {
+		return err
+	}
+	stats := aggregateGas(recs, names)
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	return writeGasMarkdown(os.Stdout, stats, baseline)
+}
+
+// readGasRecords reads all GasRecords written to the directory by ethtest.
+func readGasRecords(dir string) ([]ethtest.GasRecord, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("find gas records: %v", err)
+	}
+
+	var recs []ethtest.GasRecord
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("open gas records: %v", err)
+		}
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			var r ethtest.GasRecord
+			if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("decode gas record %q: %v", s.Text(), err)
+			}
+			recs = append(recs, r)
+		}
+		err = s.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read gas records: %v", err)
+		}
+	}
+	return recs, nil
+}
+
+// selectorNames returns a map from hex-encoded selectors, without 0x, to
+// function signatures. Signatures are taken from the bindings in the packages,
+// and from those bundled with ethier selector.
+func selectorNames(pkgs []string) (map[string]string, error) {
+	names := make(map[string]string)
+
+	db := newSignatureDB()
+	if err := db.read(strings.NewReader(bundledSignatures)); err != nil {
+		return nil, fmt.Errorf("bundled signatures: %v", err)
+	}
+	for sel, sigs := range db.bySelector {
+		sort.Strings(sigs)
+		names[hexutil.Encode(sel[:])[2:]] = strings.Join(sigs, " | ")
+	}
+
+	list := exec.Command("go", append([]string{"list", "-f", "{{.Dir}}"}, pkgs...)...)
+	list.Stderr = os.Stderr
+	out, err := list.Output()
+	if err != nil {
+		return nil, fmt.Errorf("`go list` returned: %v", err)
+	}
+	for _, dir := range strings.Fields(string(out)) {
+		sigs, err := bindingSignatures(dir)
+		if err != nil {
+			return nil, err
+		}
+		for sel, sig := range sigs {
+			names[sel] = sig
+		}
+	}
+	return names, nil
+}
+
+// bindingSignatures parses the Go files in dir and returns the union of the
+// Sigs maps of all bind.MetaData literals, as generated by ethier gen.
+func bindingSignatures(dir string) (map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("find Go files in %q: %v", dir, err)
+	}
+
+	sigs := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("parse %q: %v", file, err)
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			kv, ok := n.(*ast.KeyValueExpr)
+			if !ok {
+				return true
+			}
+			if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "Sigs" {
+				return true
+			}
+			lit, ok := kv.Value.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			for _, el := range lit.Elts {
+				sel, sig, ok := stringPair(el)
+				if ok {
+					sigs[sel] = sig
+				}
+			}
+			return false
+		})
+	}
+	return sigs, nil
+}
+
+// stringPair returns the unquoted key and value of a map-literal element
+// with string-literal key and value.
+func stringPair(el ast.Expr) (string, string, bool) {
+	kv, ok := el.(*ast.KeyValueExpr)
+	if !ok {
+		return "", "", false
+	}
+	unquote := func(e ast.Expr) (string, bool) {
+		lit, ok := e.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(lit.Value)
+		return s, err == nil
+	}
+	k, ok := unquote(kv.Key)
+	if !ok {
+		return "", "", false
+	}
+	v, ok := unquote(kv.Value)
+	return k, v, ok
+}
+
+// gasStats summarises the gas used by all calls to a single contract method.
+type gasStats struct {
+	Contract string `json:"contract"`
+	Method   string `json:"method"`
+	Calls    int    `json:"calls"`
+	Min      uint64 `json:"min"`
+	Mean     uint64 `json:"mean"`
+	Max      uint64 `json:"max"`
+}
+
+// Method names for calls that don't have a selector.
+const (
+	deploymentMethod = "(deployment)"
+	fallbackMethod   = "(fallback)"
+)
+
+// methodName returns the name of the method called in the record, using
+// names, keyed by hex selector without 0x, when possible.
+func methodName(r ethtest.GasRecord, names map[string]string) string {
+	switch {
+	case r.Deployment:
+		return deploymentMethod
+	case len(r.Selector) == 0:
+		return fallbackMethod
+	}
+	sel := hexutil.Encode(r.Selector)
+	if n, ok := names[sel[2:]]; ok {
+		return n
+	}
+	return sel
+}
+
+// aggregateGas summarises the records by contract and method, sorted by both.
+func aggregateGas(recs []ethtest.GasRecord, names map[string]string) []gasStats {
+	type key struct{ contract, method string }
+	totals := make(map[key]uint64)
+	stats := make(map[key]*gasStats)
+
+	for _, r := range recs {
+		k := key{r.Contract, methodName(r, names)}
+		s, ok := stats[k]
+		if !ok {
+			s = &gasStats{
+				Contract: k.contract,
+				Method:   k.method,
+				Min:      r.GasUsed,
+			}
+			stats[k] = s
+		}
+		s.Calls++
+		totals[k] += r.GasUsed
+		if r.GasUsed < s.Min {
+			s.Min = r.GasUsed
+		}
+		if r.GasUsed > s.Max {
+			s.Max = r.GasUsed
+		}
+	}
+
+	out := make([]gasStats, 0, len(stats))
+	for k, s := range stats {
+		s.Mean = totals[k] / uint64(s.Calls)
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Contract != out[j].Contract {
+			return out[i].Contract < out[j].Contract
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// writeGasMarkdown writes the stats as a markdown table. If baseline is
+// non-nil, the change in mean gas is included, as are methods in the baseline
+// that are no longer called.
+func writeGasMarkdown(w io.Writer, stats, baseline []gasStats) error {
+	var buf bytes.Buffer
+	row := func(cells ...interface{}) {
+		strs := make([]string, len(cells))
+		for i, c := range cells {
+			strs[i] = fmt.Sprint(c)
+		}
+		fmt.Fprintf(&buf, "| %s |\n", strings.Join(strs, " | "))
+	}
+
+	if baseline == nil {
+		row("Contract", "Method", "Calls", "Min", "Mean", "Max")
+		row("---", "---", "---:", "---:", "---:", "---:")
+		for _, s := range stats {
+			row(s.Contract, "`"+s.Method+"`", s.Calls, s.Min, s.Mean, s.Max)
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	type key struct{ contract, method string }
+	base := make(map[key]gasStats)
+	for _, b := range baseline {
+		base[key{b.Contract, b.Method}] = b
+	}
+
+	row("Contract", "Method", "Calls", "Min", "Mean", "Max", "Δ Mean")
+	row("---", "---", "---:", "---:", "---:", "---:", "---:")
+	for _, s := range stats {
+		k := key{s.Contract, s.Method}
+		delta := "new"
+		if b, ok := base[k]; ok {
+			delta = gasDelta(b.Mean, s.Mean)
+			delete(base, k)
+		}
+		row(s.Contract, "`"+s.Method+"`", s.Calls, s.Min, s.Mean, s.Max, delta)
+	}
+
+	var removed []gasStats
+	for _, b := range base {
+		removed = append(removed, b)
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		if removed[i].Contract != removed[j].Contract {
+			return removed[i].Contract < removed[j].Contract
+		}
+		return removed[i].Method < removed[j].Method
+	})
+	for _, b := range removed {
+		row(b.Contract, "`"+b.Method+"`", "-", "-", "-", "-", "removed")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// gasDelta returns a human-readable change from old to new gas, e.g.
+// +1200 (+2.50%).
+func gasDelta(old, new uint64) string {
+	if old == new {
+		return "0"
+	}
+	diff := int64(new) - int64(old)
+	if old == 0 {
+		return fmt.Sprintf("%+d", diff)
+	}
+	return fmt.Sprintf("%+d (%+.2f%%)", diff, 100*float64(diff)/float64(old))
+}
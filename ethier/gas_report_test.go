@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/ethtest"
+)
+
+func TestAggregateGas(t *testing.T) {
+	recs := []ethtest.GasRecord{
+		{Contract: "src/Token.sol:Token", Deployment: true, GasUsed: 1000000},
+		{Contract: "src/Token.sol:Token", Selector: []byte{0xa9, 0x05, 0x9c, 0xbb}, GasUsed: 50000},
+		{Contract: "src/Token.sol:Token", Selector: []byte{0xa9, 0x05, 0x9c, 0xbb}, GasUsed: 30000},
+		{Contract: "src/Token.sol:Token", Selector: []byte{0xa9, 0x05, 0x9c, 0xbb}, GasUsed: 40001},
+		{Contract: "src/Token.sol:Token", Selector: []byte{0x12, 0x34, 0x56, 0x78}, GasUsed: 25000},
+		{Contract: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", GasUsed: 21000},
+	}
+	names := map[string]string{
+		"a9059cbb": "transfer(address,uint256)",
+	}
+
+	got := aggregateGas(recs, names)
+	want := []gasStats{
+		{
+			Contract: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			Method:   fallbackMethod,
+			Calls:    1,
+			Min:      21000,
+			Mean:     21000,
+			Max:      21000,
+		},
+		{
+			Contract: "src/Token.sol:Token",
+			Method:   deploymentMethod,
+			Calls:    1,
+			Min:      1000000,
+			Mean:     1000000,
+			Max:      1000000,
+		},
+		{
+			Contract: "src/Token.sol:Token",
+			Method:   "0x12345678",
+			Calls:    1,
+			Min:      25000,
+			Mean:     25000,
+			Max:      25000,
+		},
+		{
+			Contract: "src/Token.sol:Token",
+			Method:   "transfer(address,uint256)",
+			Calls:    3,
+			Min:      30000,
+			Mean:     40000,
+			Max:      50000,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("aggregateGas() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteGasMarkdown(t *testing.T) {
+	stats := []gasStats{
+		{Contract: "Token", Method: "mint()", Calls: 2, Min: 100, Mean: 150, Max: 200},
+		{Contract: "Token", Method: "transfer(address,uint256)", Calls: 1, Min: 50, Mean: 50, Max: 50},
+		{Contract: "Token", Method: "burn()", Calls: 1, Min: 10, Mean: 10, Max: 10},
+	}
+
+	tests := []struct {
+		name     string
+		baseline []gasStats
+		want     string
+	}{
+		{
+			name: "no baseline",
+			want: `| Contract | Method | Calls | Min | Mean | Max |
+| --- | --- | ---: | ---: | ---: | ---: |
+| Token | ` + "`mint()`" + ` | 2 | 100 | 150 | 200 |
+| Token | ` + "`transfer(address,uint256)`" + ` | 1 | 50 | 50 | 50 |
+| Token | ` + "`burn()`" + ` | 1 | 10 | 10 | 10 |
+`,
+		},
+		{
+			name: "with baseline",
+			baseline: []gasStats{
+				{Contract: "Token", Method: "mint()", Mean: 120},
+				{Contract: "Token", Method: "transfer(address,uint256)", Mean: 50},
+				{Contract: "Token", Method: "approve(address,uint256)", Mean: 40},
+			},
+			want: `| Contract | Method | Calls | Min | Mean | Max | Δ Mean |
+| --- | --- | ---: | ---: | ---: | ---: | ---: |
+| Token | ` + "`mint()`" + ` | 2 | 100 | 150 | 200 | +30 (+25.00%) |
+| Token | ` + "`transfer(address,uint256)`" + ` | 1 | 50 | 50 | 50 | 0 |
+| Token | ` + "`burn()`" + ` | 1 | 10 | 10 | 10 | new |
+| Token | ` + "`approve(address,uint256)`" + ` | - | - | - | - | removed |
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeGasMarkdown(&buf, stats, tt.baseline); err != nil {
+				t.Fatalf("writeGasMarkdown() error %v", err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("writeGasMarkdown() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBindingSignatures(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "generated.go", `package token
+
+import "github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+var TokenMetaData = &bind.MetaData{
+	ABI: "[]",
+	Sigs: map[string]string{
+		"a9059cbb": "transfer(address,uint256)",
+		"1249c58b": "mint()",
+	},
+	Bin: "0x00",
+}
+`)
+	writeFile(t, dir, "token_test.go", `package token
+
+var ignored = map[string]string{"Sigs": "x"}
+
+var alsoIgnored = struct{ Sigs map[string]string }{
+	Sigs: map[string]string{"deadbeef": "test()"},
+}
+`)
+
+	got, err := bindingSignatures(dir)
+	if err != nil {
+		t.Fatalf("bindingSignatures() error %v", err)
+	}
+	want := map[string]string{
+		"a9059cbb": "transfer(address,uint256)",
+		"1249c58b": "mint()",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("bindingSignatures() diff (-want +got):\n%s", diff)
+	}
+}
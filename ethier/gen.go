@@ -13,8 +13,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common/compiler"
 	"github.com/spf13/cobra"
@@ -23,7 +25,63 @@ import (
 	_ "embed"
 )
 
-const srcMapFlag = "experimental_src_map"
+const (
+	srcMapFlag        = "experimental_src_map"
+	watchFlag         = "watch"
+	watchIntervalFlag = "watch-interval"
+)
+
+// Flags controlling solc settings; see addSolcFlags().
+const (
+	optimizeFlag     = "optimize"
+	optimizeRunsFlag = "optimize-runs"
+	evmVersionFlag   = "evm-version"
+	viaIRFlag        = "via-ir"
+)
+
+// addSolcFlags adds flags to the command for controlling the settings with
+// which solc is run, which are then converted to arguments with solcArgs().
+func addSolcFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.Bool(optimizeFlag, false, "Enable the solc optimizer")
+	f.Uint(optimizeRunsFlag, 200, "Number of runs for which the optimizer tunes; requires --optimize")
+	f.String(evmVersionFlag, "", "EVM version for which solc compiles; defaults to solc's default")
+	f.Bool(viaIRFlag, false, "Compile via the Yul intermediate representation")
+}
+
+// solcArgs returns the solc arguments equivalent to the flags added with
+// addSolcFlags().
+func solcArgs(cmd *cobra.Command) ([]string, error) {
+	flags := cmd.Flags()
+	optimize, err := flags.GetBool(optimizeFlag)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := flags.GetUint(optimizeRunsFlag)
+	if err != nil {
+		return nil, err
+	}
+	evm, err := flags.GetString(evmVersionFlag)
+	if err != nil {
+		return nil, err
+	}
+	viaIR, err := flags.GetBool(viaIRFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	if optimize {
+		args = append(args, "--optimize", "--optimize-runs", fmt.Sprint(runs))
+	}
+	if evm != "" {
+		args = append(args, "--evm-version", evm)
+	}
+	if viaIR {
+		args = append(args, "--via-ir")
+	}
+	return args, nil
+}
 
 func init() {
 	cmd := &cobra.Command{
@@ -44,16 +102,93 @@ func init() {
 	}
 
 	cmd.Flags().Bool(srcMapFlag, false, "Generate source maps to determine Solidity code location from EVM traces")
+	cmd.Flags().Bool(watchFlag, false, "Watch the Solidity sources, including imports, and regenerate bindings whenever they change")
+	cmd.Flags().Duration(watchIntervalFlag, 500*time.Millisecond, "Interval at which sources are polled for changes with --watch")
+	cmd.Flags().String(foundryFlag, "", "Foundry output directory (e.g. out) from which to read artifacts built by `forge build` instead of running solc")
+	cmd.Flags().String(hardhatFlag, "", "Hardhat artifacts directory from which to read artifacts built by `hardhat compile` instead of running solc")
+	addSolcFlags(cmd)
 
 	rootCmd.AddCommand(cmd)
 }
 
-// gen runs `solc | abigen` on the Solidity source files passed as the args.
+// gen runs `solc | abigen` on the Solidity source files passed as the args,
+// or reads their artifacts from a build tool, repeatedly if --watch is set.
+func gen(cmd *cobra.Command, args []string) error {
+	generate, err := generator(cmd)
+	if err != nil {
+		return err
+	}
+
+	watch, err := cmd.Flags().GetBool(watchFlag)
+	if err != nil {
+		return fmt.Errorf("%T.Flags().GetBool(%q): %v", cmd, watchFlag, err)
+	}
+	if !watch {
+		_, err := generate(cmd, args)
+		return err
+	}
+
+	interval, err := cmd.Flags().GetDuration(watchIntervalFlag)
+	if err != nil {
+		return fmt.Errorf("%T.Flags().GetDuration(%q): %v", cmd, watchIntervalFlag, err)
+	}
+	return watchSources(args, interval, func() ([]string, error) {
+		return generate(cmd, args)
+	})
+}
+
+// generator returns the function with which gen() creates bindings, depending
+// on the source of compiled contracts.
+func generator(cmd *cobra.Command) (func(*cobra.Command, []string) ([]string, error), error) {
+	foundry, err := cmd.Flags().GetString(foundryFlag)
+	if err != nil {
+		return nil, fmt.Errorf("%T.Flags().GetString(%q): %v", cmd, foundryFlag, err)
+	}
+	hardhat, err := cmd.Flags().GetString(hardhatFlag)
+	if err != nil {
+		return nil, fmt.Errorf("%T.Flags().GetString(%q): %v", cmd, hardhatFlag, err)
+	}
+
+	var (
+		flag string
+		read func([]string) ([]*artifact, error)
+	)
+	switch {
+	case foundry != "" && hardhat != "":
+		return nil, fmt.Errorf("--%s and --%s are mutually exclusive", foundryFlag, hardhatFlag)
+	case foundry != "":
+		flag = foundryFlag
+		read = func(srcs []string) ([]*artifact, error) {
+			return readFoundryArtifacts(foundry, srcs)
+		}
+	case hardhat != "":
+		flag = hardhatFlag
+		read = func(srcs []string) ([]*artifact, error) {
+			return readHardhatArtifacts(hardhat, srcs)
+		}
+	default:
+		return solcGenerate, nil
+	}
+
+	srcMap, err := cmd.Flags().GetBool(srcMapFlag)
+	if err != nil {
+		return nil, fmt.Errorf("%T.Flags().GetBool(%q): %v", cmd, srcMapFlag, err)
+	}
+	if srcMap {
+		return nil, fmt.Errorf("--%s requires solc; unsupported with --%s", srcMapFlag, flag)
+	}
+	return func(_ *cobra.Command, args []string) ([]string, error) {
+		return generateFromArtifacts(args, read)
+	}, nil
+}
+
+// solcGenerate runs `solc | abigen` on the Solidity source files passed as the
+// args, returning the paths of all sources used by solc, including imports.
 // TODO: support wildcard / glob matching of files.
-func gen(cmd *cobra.Command, args []string) (retErr error) {
+func solcGenerate(cmd *cobra.Command, args []string) (_ []string, retErr error) {
 	pwd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("os.Getwd(): %v", err)
+		return nil, fmt.Errorf("os.Getwd(): %v", err)
 	}
 	// The Go package for abigen.
 	pkg := filepath.Base(pwd)
@@ -65,18 +200,14 @@ func gen(cmd *cobra.Command, args []string) (retErr error) {
 		}
 	}()
 
-	// solc requires a base-path within which absolute includes are found. We
-	// define this as the base path of the Go module.
-	basePath := pwd
-	for ; ; basePath = filepath.Join(basePath, "..") {
-		if _, err := os.Stat(filepath.Join(basePath, "go.mod")); !errors.Is(err, os.ErrNotExist) {
-			break
-		}
+	basePath, includePath := solcPaths(pwd)
+	settings, err := solcArgs(cmd)
+	if err != nil {
+		return nil, err
 	}
-	includePath := filepath.Join(basePath, "node_modules")
 
 	args = append(
-		args,
+		append(append([]string{}, args...), settings...),
 		"--base-path", basePath,
 		"--include-path", includePath,
 		"--combined-json", "abi,bin,bin-runtime,hashes,metadata,srcmap-runtime",
@@ -104,38 +235,132 @@ func gen(cmd *cobra.Command, args []string) (retErr error) {
 	abigen.Stdout = generated
 
 	if err := solc.Start(); err != nil {
-		return fmt.Errorf("start `solc`: %v", err)
+		return nil, fmt.Errorf("start `solc`: %v", err)
 	}
 	if err := abigen.Start(); err != nil {
-		return fmt.Errorf("start `abigen`: %v", err)
+		return nil, fmt.Errorf("start `abigen`: %v", err)
 	}
 	if err := solc.Wait(); err != nil {
 		w.Close()
-		return fmt.Errorf("`solc` returned: %v", err)
+		return nil, fmt.Errorf("`solc` returned: %v", err)
 	}
 	if err := w.Close(); err != nil {
-		return fmt.Errorf("close write-half of pipe from solc to abigen: %v", err)
+		return nil, fmt.Errorf("close write-half of pipe from solc to abigen: %v", err)
 	}
 	if err := abigen.Wait(); err != nil {
-		return fmt.Errorf("`abigen` returned: %v", err)
+		return nil, fmt.Errorf("`abigen` returned: %v", err)
 	}
 	if err := r.Close(); err != nil {
-		return fmt.Errorf("close read-half of pipe from solc to abigen: %v", err)
+		return nil, fmt.Errorf("close read-half of pipe from solc to abigen: %v", err)
 	}
 
 	extend, err := cmd.Flags().GetBool(srcMapFlag)
 	if err != nil {
-		return fmt.Errorf("%T.Flags().GetBool(%q): %v", cmd, srcMapFlag, err)
+		return nil, fmt.Errorf("%T.Flags().GetBool(%q): %v", cmd, srcMapFlag, err)
+	}
+	paths := []string{basePath, includePath}
+	sources, err := sourceFiles(combinedJSON.Bytes(), paths)
+	if err != nil {
+		return nil, err
+	}
+
+	out := generated.Bytes()
+	if extend {
+		out, err = extendGeneratedCode(generated, combinedJSON, paths)
+		if err != nil {
+			return nil, err
+		}
 	}
-	if !extend {
-		return os.WriteFile("generated.go", generated.Bytes(), 0644)
+	if err := os.WriteFile("generated.go", out, 0644); err != nil {
+		return nil, err
 	}
+	return sources, nil
+}
 
-	out, err := extendGeneratedCode(generated, combinedJSON, []string{basePath, includePath})
+// solcPaths returns the --base-path and --include-path with which solc is
+// run from within dir. solc requires a base-path within which absolute
+// includes are found. We define this as the base path of the Go module.
+func solcPaths(dir string) (basePath, includePath string) {
+	basePath = dir
+	for ; ; basePath = filepath.Join(basePath, "..") {
+		if _, err := os.Stat(filepath.Join(basePath, "go.mod")); !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	return basePath, filepath.Join(basePath, "node_modules")
+}
+
+// sourceFiles returns the paths of all files in the sourceList of solc's
+// combined JSON output, as found in the first of paths to contain each.
+// Sources that aren't found are skipped as the list is only used for watching.
+func sourceFiles(combinedJSON []byte, paths []string) ([]string, error) {
+	var meta struct {
+		SourceList []string `json:"sourceList"`
+	}
+	if err := json.Unmarshal(combinedJSON, &meta); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal([solc output], %T): %v", &meta, err)
+	}
+
+	var files []string
+SourceLoop:
+	for _, src := range meta.SourceList {
+		for _, p := range paths {
+			f := filepath.Join(p, src)
+			if _, err := os.Stat(f); err == nil {
+				files = append(files, f)
+				continue SourceLoop
+			}
+		}
+	}
+	return files, nil
+}
+
+// watchSources calls generate() immediately and then again whenever any of
+// the files it returned, or the args, are modified, as determined by polling
+// at the specified interval. Errors from generate() are logged rather than
+// returned, so as to keep watching for a fix, in which case the previous set
+// of sources continues to be watched. watchSources only returns if the
+// initial call to generate() fails.
+func watchSources(args []string, interval time.Duration, generate func() ([]string, error)) error {
+	sources, err := generate()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile("generated.go", out, 0644)
+	watched := append(append([]string{}, args...), sources...)
+	last := modTimes(watched)
+	log.Printf("Watching %d source files for changes", len(watched))
+
+	for range time.Tick(interval) {
+		now := modTimes(watched)
+		if reflect.DeepEqual(now, last) {
+			continue
+		}
+
+		sources, err := generate()
+		if err != nil {
+			log.Print(err)
+			last = now
+			continue
+		}
+		watched = append(append([]string{}, args...), sources...)
+		last = modTimes(watched)
+		log.Print("Regenerated bindings")
+	}
+	return nil
+}
+
+// modTimes returns the modification time of each file, keyed by path. Files
+// that can't be stat'd, e.g. because they've been deleted, have a zero time.
+func modTimes(files []string) map[string]time.Time {
+	times := make(map[string]time.Time)
+	for _, f := range files {
+		var t time.Time
+		if info, err := os.Stat(f); err == nil {
+			t = info.ModTime()
+		}
+		times[f] = t
+	}
+	return times
 }
 
 var (
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	foundryFlag = "foundry"
+	hardhatFlag = "hardhat"
+)
+
+// An artifact is a single compiled contract, read from the output of a build
+// tool instead of being compiled by solc.
+type artifact struct {
+	// path is the artifact file from which the contract was read.
+	path string
+	// source is the path of the Solidity file, as known to the compiler, and
+	// name is the contract's name within it. Together they determine the
+	// placeholder used when linking libraries.
+	source, name string
+
+	abi      json.RawMessage
+	bytecode string
+	// hashes map function signatures to selectors, as in solc's output.
+	hashes map[string]string
+}
+
+// foundryArtifact is the JSON format of files written to out/ by `forge
+// build`, limited to the fields used by ethier.
+type foundryArtifact struct {
+	ABI      json.RawMessage `json:"abi"`
+	Bytecode struct {
+		Object string `json:"object"`
+	} `json:"bytecode"`
+	MethodIdentifiers map[string]string `json:"methodIdentifiers"`
+	// Metadata is the solc metadata, either as an object or as a JSON string
+	// depending on the version of forge.
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+// compilationTarget returns the source path and contract name from the
+// artifact's metadata, or empty strings if they're unavailable.
+func (a *foundryArtifact) compilationTarget() (string, string) {
+	meta := a.Metadata
+	var s string
+	if err := json.Unmarshal(meta, &s); err == nil {
+		meta = json.RawMessage(s)
+	}
+
+	var m struct {
+		Settings struct {
+			CompilationTarget map[string]string `json:"compilationTarget"`
+		} `json:"settings"`
+	}
+	if err := json.Unmarshal(meta, &m); err != nil {
+		return "", ""
+	}
+	for src, name := range m.Settings.CompilationTarget {
+		return src, name
+	}
+	return "", ""
+}
+
+// readFoundryArtifacts reads the artifacts of all contracts defined in the
+// Solidity sources from outDir, the output directory of `forge build`. Forge
+// nests artifacts in directories named after the base name of each source
+// file.
+func readFoundryArtifacts(outDir string, sources []string) ([]*artifact, error) {
+	var arts []*artifact
+	for _, src := range sources {
+		dir := filepath.Join(outDir, filepath.Base(src))
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no Foundry artifacts for %q in %q; has `forge build` been run?", src, dir)
+		}
+
+		for _, f := range files {
+			buf, err := os.ReadFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("os.ReadFile(%q): %v", f, err)
+			}
+			fa := new(foundryArtifact)
+			if err := json.Unmarshal(buf, fa); err != nil {
+				return nil, fmt.Errorf("json.Unmarshal(%q, %T): %v", f, fa, err)
+			}
+
+			a := &artifact{
+				path:     f,
+				abi:      fa.ABI,
+				bytecode: fa.Bytecode.Object,
+				hashes:   fa.MethodIdentifiers,
+			}
+			a.source, a.name = fa.compilationTarget()
+			if a.name == "" {
+				a.source = src
+				a.name = strings.TrimSuffix(filepath.Base(f), ".json")
+			}
+			arts = append(arts, a)
+		}
+	}
+	return arts, nil
+}
+
+// hardhatArtifact is the JSON format of contract artifacts written to
+// artifacts/ by `hardhat compile`, limited to the fields used by ethier.
+type hardhatArtifact struct {
+	Format       string          `json:"_format"`
+	ContractName string          `json:"contractName"`
+	SourceName   string          `json:"sourceName"`
+	ABI          json.RawMessage `json:"abi"`
+	Bytecode     string          `json:"bytecode"`
+}
+
+// readHardhatArtifacts reads the artifacts of all contracts defined in the
+// Solidity sources from artifactsDir, the output directory of `hardhat
+// compile`. Sources are matched by path suffix against the source names
+// recorded in the artifacts, so contracts/Token.sol and Token.sol both match
+// an artifact with source name contracts/Token.sol.
+func readHardhatArtifacts(artifactsDir string, sources []string) ([]*artifact, error) {
+	matched := make(map[string]bool)
+	var arts []*artifact
+
+	err := filepath.Walk(artifactsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == "build-info" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".json") || strings.HasSuffix(path, ".dbg.json") {
+			return nil
+		}
+
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("os.ReadFile(%q): %v", path, err)
+		}
+		ha := new(hardhatArtifact)
+		if err := json.Unmarshal(buf, ha); err != nil {
+			return fmt.Errorf("json.Unmarshal(%q, %T): %v", path, ha, err)
+		}
+		if !strings.HasPrefix(ha.Format, "hh-sol-artifact") {
+			return nil
+		}
+
+		var match bool
+		for _, src := range sources {
+			src = filepath.ToSlash(filepath.Clean(src))
+			if ha.SourceName == src || strings.HasSuffix(ha.SourceName, "/"+src) {
+				matched[src] = true
+				match = true
+			}
+		}
+		if !match {
+			return nil
+		}
+
+		hashes, err := methodIdentifiers(ha.ABI)
+		if err != nil {
+			return fmt.Errorf("%q: %v", path, err)
+		}
+		arts = append(arts, &artifact{
+			path:     path,
+			source:   ha.SourceName,
+			name:     ha.ContractName,
+			abi:      ha.ABI,
+			bytecode: ha.Bytecode,
+			hashes:   hashes,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, src := range sources {
+		if !matched[filepath.ToSlash(filepath.Clean(src))] {
+			return nil, fmt.Errorf("no Hardhat artifacts for %q in %q; has `hardhat compile` been run?", src, artifactsDir)
+		}
+	}
+	return arts, nil
+}
+
+// methodIdentifiers returns a map from function signatures to selectors, as
+// in solc's output, for the methods in the ABI.
+func methodIdentifiers(abiJSON json.RawMessage) (map[string]string, error) {
+	parsed, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("parse ABI: %v", err)
+	}
+	ids := make(map[string]string)
+	for _, m := range parsed.Methods {
+		ids[m.Sig] = hex.EncodeToString(m.ID)
+	}
+	return ids, nil
+}
+
+// bindArtifacts returns Go bindings for the artifacts, equivalent to those
+// produced by abigen from solc's combined JSON.
+func bindArtifacts(pkg string, arts []*artifact) ([]byte, error) {
+	sort.Slice(arts, func(i, j int) bool {
+		return arts[i].name < arts[j].name
+	})
+
+	var (
+		types, abis, bins []string
+		sigs              []map[string]string
+	)
+	libs := make(map[string]string)
+	seen := make(map[string]string)
+
+	for _, a := range arts {
+		if prev, ok := seen[a.name]; ok {
+			return nil, fmt.Errorf("contract %q in both %q and %q; multiple compiler versions or duplicate names aren't supported", a.name, prev, a.path)
+		}
+		seen[a.name] = a.path
+
+		types = append(types, a.name)
+		abis = append(abis, string(a.abi))
+		bins = append(bins, a.bytecode)
+		sigs = append(sigs, a.hashes)
+		// solc's link placeholder is derived from the fully qualified name.
+		libs[crypto.Keccak256Hash([]byte(a.source + ":" + a.name)).String()[2:36]] = a.name
+	}
+
+	code, err := bind.Bind(types, abis, bins, sigs, pkg, bind.LangGo, libs, nil)
+	if err != nil {
+		return nil, fmt.Errorf("bind.Bind(): %v", err)
+	}
+	return []byte(code), nil
+}
+
+// generateFromArtifacts writes generated.go with bindings for the artifacts
+// returned by read(), returning their paths for use with --watch.
+func generateFromArtifacts(args []string, read func([]string) ([]*artifact, error)) ([]string, error) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("os.Getwd(): %v", err)
+	}
+	pkg := filepath.Base(pwd)
+	log.Printf("Generating package %q from artifacts: %s", pkg, args)
+
+	arts, err := read(args)
+	if err != nil {
+		return nil, fmt.Errorf("generating %q: %w", pkg, err)
+	}
+	code, err := bindArtifacts(pkg, arts)
+	if err != nil {
+		return nil, fmt.Errorf("generating %q: %w", pkg, err)
+	}
+	if err := os.WriteFile("generated.go", code, 0644); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, len(arts))
+	for i, a := range arts {
+		paths[i] = a.path
+	}
+	return paths, nil
+}
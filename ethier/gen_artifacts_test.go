@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// counterABI is the ABI of:
+//
+//	contract Counter {
+//	    uint256 public count;
+//	    function increment() external { count++; }
+//	}
+const counterABI = `[
+  {"inputs": [], "name": "count", "outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}], "stateMutability": "view", "type": "function"},
+  {"inputs": [], "name": "increment", "outputs": [], "stateMutability": "nonpayable", "type": "function"}
+]`
+
+// writeFile writes contents to dir/name, creating dir if necessary.
+func writeFile(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("os.MkdirAll(%q) error %v", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+		t.Fatalf("os.WriteFile(%q) error %v", name, err)
+	}
+}
+
+// checkBindings confirms that the code includes bindings for a Counter
+// contract, as compiled from counterABI.
+func checkBindings(t *testing.T, code []byte) {
+	t.Helper()
+	for _, want := range []string{
+		"package mypkg",
+		"func DeployCounter(",
+		"func (_Counter *CounterTransactor) Increment(",
+		"func (_Counter *CounterCaller) Count(",
+		`"d09de08a": "increment()"`,
+	} {
+		if !strings.Contains(string(code), want) {
+			t.Errorf("bindings missing %q", want)
+		}
+	}
+}
+
+func TestFoundryArtifacts(t *testing.T) {
+	out := t.TempDir()
+	writeFile(t, filepath.Join(out, "Counter.sol"), "Counter.json", `{
+  "abi": `+counterABI+`,
+  "bytecode": {"object": "0x6080604052", "sourceMap": "", "linkReferences": {}},
+  "deployedBytecode": {"object": "0x6080", "sourceMap": "", "linkReferences": {}},
+  "methodIdentifiers": {"count()": "06661abd", "increment()": "d09de08a"},
+  "metadata": {"compiler": {"version": "0.8.15"}, "settings": {"compilationTarget": {"src/Counter.sol": "Counter"}}}
+}`)
+
+	arts, err := readFoundryArtifacts(out, []string{"src/Counter.sol"})
+	if err != nil {
+		t.Fatalf("readFoundryArtifacts() error %v", err)
+	}
+	if got, want := len(arts), 1; got != want {
+		t.Fatalf("readFoundryArtifacts() got %d artifacts; want %d", got, want)
+	}
+	if got, want := arts[0].source, "src/Counter.sol"; got != want {
+		t.Errorf("readFoundryArtifacts() got source %q; want %q", got, want)
+	}
+
+	code, err := bindArtifacts("mypkg", arts)
+	if err != nil {
+		t.Fatalf("bindArtifacts() error %v", err)
+	}
+	checkBindings(t, code)
+
+	if _, err := readFoundryArtifacts(out, []string{"src/Missing.sol"}); err == nil {
+		t.Error("readFoundryArtifacts(<missing source>) got nil error; want error")
+	}
+	if _, err := bindArtifacts("mypkg", append(arts, arts[0])); err == nil {
+		t.Error("bindArtifacts(<duplicate contract>) got nil error; want error")
+	}
+}
+
+func TestHardhatArtifacts(t *testing.T) {
+	artifacts := t.TempDir()
+	dir := filepath.Join(artifacts, "contracts", "Counter.sol")
+	writeFile(t, dir, "Counter.json", `{
+  "_format": "hh-sol-artifact-1",
+  "contractName": "Counter",
+  "sourceName": "contracts/Counter.sol",
+  "abi": `+counterABI+`,
+  "bytecode": "0x6080604052",
+  "deployedBytecode": "0x6080",
+  "linkReferences": {},
+  "deployedLinkReferences": {}
+}`)
+	writeFile(t, dir, "Counter.dbg.json", `{"_format": "hh-sol-dbg-1", "buildInfo": "../../build-info/abc.json"}`)
+	writeFile(t, filepath.Join(artifacts, "build-info"), "abc.json", `{"_format": "hh-sol-build-info-1"}`)
+
+	for _, src := range []string{"contracts/Counter.sol", "Counter.sol"} {
+		arts, err := readHardhatArtifacts(artifacts, []string{src})
+		if err != nil {
+			t.Fatalf("readHardhatArtifacts(%q) error %v", src, err)
+		}
+		if got, want := len(arts), 1; got != want {
+			t.Fatalf("readHardhatArtifacts(%q) got %d artifacts; want %d", src, got, want)
+		}
+
+		code, err := bindArtifacts("mypkg", arts)
+		if err != nil {
+			t.Fatalf("bindArtifacts() error %v", err)
+		}
+		checkBindings(t, code)
+	}
+
+	for _, src := range []string{"Missing.sol", "ounter.sol"} {
+		if _, err := readHardhatArtifacts(artifacts, []string{src}); err == nil {
+			t.Errorf("readHardhatArtifacts(%q) got nil error; want error", src)
+		}
+	}
+}
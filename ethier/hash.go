@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Computes digests used in signing workflows."
+
+	cmd := &cobra.Command{
+		Use:   "hash",
+		Short: short,
+		Long: short + `
+
+Input is the single argument if provided, otherwise all of stdin with a single trailing newline removed. Input beginning 0x is decoded as hex, and all other input is used as UTF-8, unless --utf8 is set.`,
+	}
+	cmd.PersistentFlags().Bool("utf8", false, "Treat input as UTF-8 even if it begins 0x")
+
+	keccak := &cobra.Command{
+		Use:   "keccak [data]",
+		Short: "Prints keccak256(data)",
+		RunE:  hashKeccak,
+		Args:  cobra.MaximumNArgs(1),
+	}
+
+	eip191 := &cobra.Command{
+		Use:   "eip191 [data]",
+		Short: "Prints the EIP-191 digest of data, as signed by ethier sign messages",
+		Long: `Prints the EIP-191 digest of data, as signed by ethier sign messages.
+
+By default, version 0x45 (personal_sign) is used; i.e. keccak256("\x19Ethereum Signed Message:\n" ‖ len(data) ‖ data). With --validator, version 0x00 is used instead; i.e. keccak256(0x1900 ‖ validator ‖ data).`,
+		RunE: hashEIP191,
+		Args: cobra.MaximumNArgs(1),
+	}
+	eip191.Flags().String("validator", "", "Address of the intended validator, for version 0x00")
+
+	domain := &cobra.Command{
+		Use:   "eip712-domain",
+		Short: "Prints the EIP-712 domain separator",
+		Long: `Prints the EIP-712 domain separator; i.e. hashStruct(EIP712Domain).
+
+Only fields with flags set are included in the EIP712Domain type, in the order defined by EIP-712, so the result matches that of the Solidity domain separator for the same fields.`,
+		RunE: hashEIP712Domain,
+		Args: cobra.NoArgs,
+	}
+	domain.Flags().String("name", "", "Domain name")
+	domain.Flags().String("version", "", "Domain version")
+	domain.Flags().Int64("chain-id", -1, "Domain chain ID; -1 = omitted")
+	domain.Flags().String("verifying-contract", "", "Domain verifying contract")
+	domain.Flags().String("salt", "", "Domain salt, as 32-byte hex")
+
+	cmd.AddCommand(keccak, eip191, domain)
+	rootCmd.AddCommand(cmd)
+}
+
+// hashInput returns the data to be hashed by `ethier hash` subcommands.
+func hashInput(cmd *cobra.Command, args []string) ([]byte, error) {
+	utf8, err := cmd.Flags().GetBool("utf8")
+	if err != nil {
+		return nil, err
+	}
+
+	var in string
+	if len(args) > 0 {
+		in = args[0]
+	} else {
+		buf, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read stdin: %v", err)
+		}
+		buf = bytes.TrimSuffix(bytes.TrimSuffix(buf, []byte("\n")), []byte("\r"))
+		in = string(buf)
+	}
+
+	if utf8 {
+		return []byte(in), nil
+	}
+	return parseMessage(in)
+}
+
+// hashKeccak implements the `ethier hash keccak` command.
+func hashKeccak(cmd *cobra.Command, args []string) error {
+	data, err := hashInput(cmd, args)
+	if err != nil {
+		return err
+	}
+	fmt.Println(crypto.Keccak256Hash(data).Hex())
+	return nil
+}
+
+// hashEIP191 implements the `ethier hash eip191` command.
+func hashEIP191(cmd *cobra.Command, args []string) error {
+	validator, err := cmd.Flags().GetString("validator")
+	if err != nil {
+		return err
+	}
+	data, err := hashInput(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	if validator == "" {
+		data = eth.WithPersonalMessagePrefix(data)
+	} else {
+		addr, err := eth.ParseAddress(validator)
+		if err != nil {
+			return fmt.Errorf("--validator: %v", err)
+		}
+		data = eth.WithIntendedValidatorPrefix(addr, data)
+	}
+	fmt.Println(crypto.Keccak256Hash(data).Hex())
+	return nil
+}
+
+// hashEIP712Domain implements the `ethier hash eip712-domain` command.
+func hashEIP712Domain(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	name, err := flags.GetString("name")
+	if err != nil {
+		return err
+	}
+	version, err := flags.GetString("version")
+	if err != nil {
+		return err
+	}
+	chainID, err := flags.GetInt64("chain-id")
+	if err != nil {
+		return err
+	}
+	contract, err := flags.GetString("verifying-contract")
+	if err != nil {
+		return err
+	}
+	salt, err := flags.GetString("salt")
+	if err != nil {
+		return err
+	}
+
+	d := apitypes.TypedDataDomain{
+		Name:    name,
+		Version: version,
+	}
+	if chainID >= 0 {
+		d.ChainId = (*math.HexOrDecimal256)(big.NewInt(chainID))
+	}
+	if contract != "" {
+		addr, err := eth.ParseAddress(contract)
+		if err != nil {
+			return fmt.Errorf("--verifying-contract: %v", err)
+		}
+		d.VerifyingContract = addr.Hex()
+	}
+	if salt != "" {
+		b, err := hexutil.Decode(salt)
+		if err != nil {
+			return fmt.Errorf("--salt: %v", err)
+		}
+		if len(b) != common.HashLength {
+			return fmt.Errorf("--salt must be %d bytes; got %d", common.HashLength, len(b))
+		}
+		d.Salt = hexutil.Encode(b)
+	}
+
+	sep, err := domainSeparator(d)
+	if err != nil {
+		return err
+	}
+	fmt.Println(sep.Hex())
+	return nil
+}
+
+// domainSeparator returns the EIP-712 hashStruct of the domain, with a type
+// including only its populated fields.
+func domainSeparator(d apitypes.TypedDataDomain) (common.Hash, error) {
+	typ := domainType(d)
+	if len(typ) == 0 {
+		return common.Hash{}, errors.New("EIP712Domain must have at least one field")
+	}
+	td := apitypes.TypedData{
+		Types:  apitypes.Types{"EIP712Domain": typ},
+		Domain: d,
+	}
+	sep, err := td.HashStruct("EIP712Domain", d.Map())
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("hash EIP712Domain: %v", err)
+	}
+	return common.BytesToHash(sep), nil
+}
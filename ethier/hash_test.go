@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestDomainSeparator(t *testing.T) {
+	contract := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	salt := crypto.Keccak256Hash([]byte("salt"))
+
+	tests := []struct {
+		name   string
+		domain apitypes.TypedDataDomain
+		// want is computed as in Solidity:
+		// keccak256(abi.encode(typeHash, fields...)).
+		want common.Hash
+	}{
+		{
+			name:   "all but salt",
+			domain: eth.EIP712Domain("ethier", "1", big.NewInt(1), contract),
+			want: crypto.Keccak256Hash(
+				crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")),
+				crypto.Keccak256([]byte("ethier")),
+				crypto.Keccak256([]byte("1")),
+				common.LeftPadBytes([]byte{1}, 32),
+				common.LeftPadBytes(contract.Bytes(), 32),
+			),
+		},
+		{
+			name: "name and salt",
+			domain: apitypes.TypedDataDomain{
+				Name: "ethier",
+				Salt: salt.Hex(),
+			},
+			want: crypto.Keccak256Hash(
+				crypto.Keccak256([]byte("EIP712Domain(string name,bytes32 salt)")),
+				crypto.Keccak256([]byte("ethier")),
+				salt.Bytes(),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domainSeparator(tt.domain)
+			if err != nil {
+				t.Fatalf("domainSeparator(%+v) error %v", tt.domain, err)
+			}
+			if got != tt.want {
+				t.Errorf("domainSeparator(%+v) got %v; want %v", tt.domain, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := domainSeparator(apitypes.TypedDataDomain{}); err == nil {
+		t.Error("domainSeparator(<empty>) got nil error; want error")
+	}
+}
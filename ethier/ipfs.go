@@ -0,0 +1,464 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "ipfs",
+		Short: "Publishes token metadata to IPFS",
+	}
+
+	const short = "Uploads and pins a directory, typically of token metadata, and prints the resulting baseURI."
+
+	pin := &cobra.Command{
+		Use:   "pin <dir>",
+		Short: short,
+		Long: short + `
+
+All regular files under the directory, except hidden ones, are uploaded to the pinning --provider as a single directory, and its CID is checked to be well formed. Unless --gateway is empty, every file is then fetched back through the gateway and compared to the local copy; this can be slow for newly pinned content, which may take some time to propagate, so --timeout is generous by default.
+
+With --verify-schema, every file with a .json extension or no extension is first checked to be valid ERC-721 metadata, and nothing is uploaded if any isn't.
+
+The API token defaults to $PINATA_JWT or $WEB3_STORAGE_TOKEN, depending on the provider. The baseURI, ipfs://<CID>/, is printed to stdout so tokenURI(id) resolves to the file named id, which is typical of ethier's BaseTokenURI.`,
+		RunE: ipfsPin,
+		Args: cobra.ExactArgs(1),
+	}
+	pin.Flags().String("provider", "pinata", "Pinning provider: pinata or web3.storage")
+	pin.Flags().String("token", "", "API token for the provider; see help for defaults")
+	pin.Flags().String("api-url", "", "Override the provider's upload endpoint")
+	pin.Flags().String("name", "", "Name of the pin; defaults to the directory's name")
+	pin.Flags().String("gateway", "https://ipfs.io", "IPFS HTTP gateway through which uploaded files are verified; empty to skip")
+	pin.Flags().Bool("verify-schema", false, "Check that JSON files are valid token metadata before uploading")
+	pin.Flags().IntP("workers", "w", 8, "Number of files concurrently verified through the gateway")
+	pin.Flags().Duration("timeout", 30*time.Minute, "Maximum time for upload and verification")
+
+	cmd.AddCommand(pin)
+	rootCmd.AddCommand(cmd)
+}
+
+// ipfsProviders are the default upload endpoints, keyed by --provider.
+var ipfsProviders = map[string]string{
+	"pinata":       "https://api.pinata.cloud/pinning/pinFileToIPFS",
+	"web3.storage": "https://api.web3.storage/upload",
+}
+
+// ipfsTokenEnv are the environment variables from which API tokens are read,
+// keyed by --provider.
+var ipfsTokenEnv = map[string]string{
+	"pinata":       "PINATA_JWT",
+	"web3.storage": "WEB3_STORAGE_TOKEN",
+}
+
+// ipfsPin implements the `ethier ipfs pin` command.
+func ipfsPin(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	provider, err := flags.GetString("provider")
+	if err != nil {
+		return err
+	}
+	token, err := flags.GetString("token")
+	if err != nil {
+		return err
+	}
+	apiURL, err := flags.GetString("api-url")
+	if err != nil {
+		return err
+	}
+	name, err := flags.GetString("name")
+	if err != nil {
+		return err
+	}
+	gateway, err := flags.GetString("gateway")
+	if err != nil {
+		return err
+	}
+	verifySchema, err := flags.GetBool("verify-schema")
+	if err != nil {
+		return err
+	}
+	workers, err := flags.GetInt("workers")
+	if err != nil {
+		return err
+	}
+	timeout, err := flags.GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ipfsProviders[provider]; !ok {
+		return fmt.Errorf("unsupported --provider %q", provider)
+	}
+	if apiURL == "" {
+		apiURL = ipfsProviders[provider]
+	}
+	if token == "" {
+		token = os.Getenv(ipfsTokenEnv[provider])
+	}
+	if token == "" {
+		return fmt.Errorf("--token or $%s required", ipfsTokenEnv[provider])
+	}
+
+	dir := args[0]
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = filepath.Base(abs)
+	}
+	files, err := listPinFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	if verifySchema {
+		if err := checkMetadataDir(dir, files); err != nil {
+			return err
+		}
+		log.Printf("Metadata schema valid")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	p := &ipfsPinner{
+		provider: provider,
+		url:      apiURL,
+		token:    token,
+		client:   http.DefaultClient,
+	}
+	log.Printf("Uploading %d files to %s", len(files), provider)
+	cid, err := p.pin(ctx, name, dir, files)
+	if err != nil {
+		return err
+	}
+	if err := parseCID(cid); err != nil {
+		return fmt.Errorf("%s returned invalid CID %q: %v", provider, cid, err)
+	}
+	log.Printf("Pinned as %s", cid)
+
+	if gateway != "" {
+		if err := verifyGatewayFiles(ctx, http.DefaultClient, gateway, cid, dir, files, workers); err != nil {
+			return err
+		}
+		log.Printf("All %d files verified through %s", len(files), gateway)
+	}
+
+	fmt.Printf("ipfs://%s/\n", cid)
+	return nil
+}
+
+// listPinFiles returns the slash-separated paths, relative to dir, of all
+// regular files under it, excluding those in or of hidden files and
+// directories, in lexical order.
+func listPinFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files in %q", dir)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// checkMetadataDir runs checkMetadataSchema() over every file with a .json
+// extension, or no extension, returning an error describing all that fail.
+func checkMetadataDir(dir string, files []string) error {
+	var failed []string
+	for _, f := range files {
+		if ext := path.Ext(f); ext != "" && ext != ".json" {
+			continue
+		}
+		buf, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f)))
+		if err != nil {
+			return err
+		}
+		if err := checkMetadataSchema(buf); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", f, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d invalid metadata files:\n%s", len(failed), strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// An ipfsPinner uploads directories to a pinning provider.
+type ipfsPinner struct {
+	provider, url, token string
+	client               *http.Client
+}
+
+// pin uploads the files, relative to dir, as a single directory, returning
+// its CID.
+func (p *ipfsPinner) pin(ctx context.Context, name, dir string, files []string) (string, error) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(p.writeFiles(mw, name, dir, files))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, pr)
+	if err != nil {
+		pr.Close()
+		return "", fmt.Errorf("build request: %v", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	if p.provider == "web3.storage" {
+		req.Header.Set("X-Name", name)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("POST %s: %w", p.url, err)
+	}
+	defer res.Body.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(res.Body); err != nil {
+		return "", fmt.Errorf("read response: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("POST %s: %s: %s", p.url, res.Status, buf.String())
+	}
+
+	var resp struct {
+		PinataHash string `json:"IpfsHash"`
+		CID        string `json:"cid"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		return "", fmt.Errorf("json.Unmarshal(%q, %T): %v", buf.String(), &resp, err)
+	}
+	if p.provider == "pinata" {
+		return resp.PinataHash, nil
+	}
+	return resp.CID, nil
+}
+
+// writeFiles writes the multipart body of a pin() request, closing mw.
+func (p *ipfsPinner) writeFiles(mw *multipart.Writer, name, dir string, files []string) error {
+	for _, f := range files {
+		// Pinata only wraps files in a directory if they share a common
+		// directory prefix, whereas web3.storage always does so.
+		fileName := f
+		if p.provider == "pinata" {
+			fileName = path.Join(name, f)
+		}
+		w, err := mw.CreateFormFile("file", fileName)
+		if err != nil {
+			return err
+		}
+		if err := copyFile(w, filepath.Join(dir, filepath.FromSlash(f))); err != nil {
+			return err
+		}
+	}
+
+	if p.provider == "pinata" {
+		meta, err := json.Marshal(map[string]string{"name": name})
+		if err != nil {
+			return err
+		}
+		if err := mw.WriteField("pinataMetadata", string(meta)); err != nil {
+			return err
+		}
+		if err := mw.WriteField("pinataOptions", `{"cidVersion":1}`); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// copyFile copies the contents of the file at path to w.
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// parseCID returns an error if s isn't a well-formed CID; i.e. either a
+// base58btc CIDv0 or a base32 CIDv1, each including a multihash of the
+// declared length.
+func parseCID(s string) error {
+	var mh []byte
+	switch {
+	case strings.HasPrefix(s, "Qm"):
+		buf := base58.Decode(s)
+		if len(buf) != 34 || buf[0] != 0x12 || buf[1] != 32 {
+			return errors.New("CIDv0 not a base58btc sha2-256 multihash")
+		}
+		return nil
+
+	case strings.HasPrefix(s, "b"):
+		buf, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(s[1:]))
+		if err != nil {
+			return fmt.Errorf("CIDv1 not base32: %v", err)
+		}
+		version, n := binary.Uvarint(buf)
+		if n <= 0 || version != 1 {
+			return fmt.Errorf("CID version %d; expecting 1", version)
+		}
+		buf = buf[n:]
+		if _, n := binary.Uvarint(buf); n <= 0 {
+			return errors.New("CIDv1 invalid codec")
+		}
+		mh = buf[n:]
+
+	default:
+		return errors.New("neither base58btc CIDv0 nor base32 CIDv1")
+	}
+
+	_, n := binary.Uvarint(mh)
+	if n <= 0 {
+		return errors.New("invalid multihash code")
+	}
+	mh = mh[n:]
+	size, n := binary.Uvarint(mh)
+	if n <= 0 {
+		return errors.New("invalid multihash length")
+	}
+	if got := len(mh) - n; uint64(got) != size {
+		return fmt.Errorf("multihash digest of %d bytes; declared %d", got, size)
+	}
+	return nil
+}
+
+// verifyGatewayFiles fetches every file from the gateway, as
+// <gateway>/ipfs/<cid>/<file>, and compares it to the local copy under dir.
+// Files are fetched concurrently across the specified number of workers; if
+// workers <= 0, runtime.NumCPU() workers are used.
+func verifyGatewayFiles(ctx context.Context, client *http.Client, gateway, cid, dir string, files []string, workers int) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if n := len(files); workers > n {
+		workers = n
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	idx := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				if err := verifyGatewayFile(ctx, client, gateway, cid, dir, files[i]); err != nil {
+					fail(fmt.Errorf("verify %s: %v", files[i], err))
+					return
+				}
+			}
+		}()
+	}
+
+Feed:
+	for i := range files {
+		select {
+		case idx <- i:
+		case <-ctx.Done():
+			break Feed
+		}
+	}
+	close(idx)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// verifyGatewayFile fetches a single file for verifyGatewayFiles().
+func verifyGatewayFile(ctx context.Context, client *http.Client, gateway, cid, dir, file string) error {
+	want, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+	if err != nil {
+		return err
+	}
+
+	u := strings.TrimSuffix(gateway, "/") + "/ipfs/" + cid + "/" + (&url.URL{Path: file}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", u, res.Status)
+	}
+
+	got, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("read %s: %v", u, err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("GET %s returned %d bytes differing from the local %d", u, len(got), len(want))
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIPFSPin(t *testing.T) {
+	ctx := context.Background()
+
+	dir := filepath.Join(t.TempDir(), "metadata")
+	writeFile(t, dir, "0", `{"name": "Token 0"}`)
+	writeFile(t, dir, "1", `{"name": "Token 1"}`)
+	writeFile(t, filepath.Join(dir, "images"), "0.svg", "<svg/>")
+	writeFile(t, dir, ".DS_Store", "junk")
+	writeFile(t, filepath.Join(dir, ".git"), "HEAD", "junk")
+
+	files, err := listPinFiles(dir)
+	if err != nil {
+		t.Fatalf("listPinFiles() error %v", err)
+	}
+	if diff := cmp.Diff([]string{"0", "1", "images/0.svg"}, files); diff != "" {
+		t.Fatalf("listPinFiles() diff (-want +got):\n%s", diff)
+	}
+
+	const (
+		token = "secret"
+		cid   = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+	)
+
+	tests := []struct {
+		provider      string
+		wantFileNames []string
+		response      interface{}
+	}{
+		{
+			provider:      "pinata",
+			wantFileNames: []string{"metadata/0", "metadata/1", "metadata/images/0.svg"},
+			response:      map[string]string{"IpfsHash": cid},
+		},
+		{
+			provider:      "web3.storage",
+			wantFileNames: []string{"0", "1", "images/0.svg"},
+			response:      map[string]string{"cid": cid},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			uploaded := make(map[string]string)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got, want := r.Header.Get("Authorization"), "Bearer "+token; got != want {
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+				mr, err := r.MultipartReader()
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				var names []string
+				for {
+					part, err := mr.NextPart()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						http.Error(w, err.Error(), http.StatusBadRequest)
+						return
+					}
+					if part.FormName() != "file" {
+						continue
+					}
+					buf, err := io.ReadAll(part)
+					if err != nil {
+						http.Error(w, err.Error(), http.StatusBadRequest)
+						return
+					}
+					// part.FileName() strips directories, so parse the
+					// header directly.
+					_, params, err := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+					if err != nil {
+						http.Error(w, err.Error(), http.StatusBadRequest)
+						return
+					}
+					names = append(names, params["filename"])
+					uploaded[params["filename"]] = string(buf)
+				}
+				if diff := cmp.Diff(tt.wantFileNames, names); diff != "" {
+					t.Errorf("uploaded file names diff (-want +got):\n%s", diff)
+				}
+				json.NewEncoder(w).Encode(tt.response)
+			}))
+			defer srv.Close()
+
+			p := &ipfsPinner{provider: tt.provider, url: srv.URL, token: token, client: srv.Client()}
+			got, err := p.pin(ctx, "metadata", dir, files)
+			if err != nil {
+				t.Fatalf("pin() error %v", err)
+			}
+			if got != cid {
+				t.Errorf("pin() got CID %q; want %q", got, cid)
+			}
+			if got, want := uploaded[tt.wantFileNames[2]], "<svg/>"; got != want {
+				t.Errorf("uploaded %q got contents %q; want %q", tt.wantFileNames[2], got, want)
+			}
+
+			p.token = "wrong"
+			if _, err := p.pin(ctx, "metadata", dir, files); err == nil {
+				t.Errorf("pin() with wrong token got nil error")
+			}
+		})
+	}
+
+	t.Run("gateway", func(t *testing.T) {
+		gateway := httptest.NewServer(http.StripPrefix("/ipfs/"+cid+"/", http.FileServer(http.Dir(dir))))
+		defer gateway.Close()
+
+		if err := verifyGatewayFiles(ctx, gateway.Client(), gateway.URL, cid, dir, files, 2); err != nil {
+			t.Errorf("verifyGatewayFiles() error %v", err)
+		}
+
+		other := t.TempDir()
+		writeFile(t, other, "0", `{"name": "Token 0"}`)
+		writeFile(t, other, "1", `{"name": "Changed"}`)
+		if err := verifyGatewayFiles(ctx, gateway.Client(), gateway.URL, cid, other, files[:2], 2); err == nil {
+			t.Errorf("verifyGatewayFiles(<modified file>) got nil error")
+		}
+		if err := verifyGatewayFiles(ctx, gateway.Client(), gateway.URL, "bafymissing", dir, files, 2); err == nil {
+			t.Errorf("verifyGatewayFiles(<wrong CID>) got nil error")
+		}
+	})
+}
+
+func TestParseCID(t *testing.T) {
+	for _, s := range []string{
+		"QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG",
+		"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi",
+		"bafkreidon73zkcrwdb5iafqtijxildoonbwnpv7dyd6ef3qdgads2jc4su",
+	} {
+		if err := parseCID(s); err != nil {
+			t.Errorf("parseCID(%q) error %v", s, err)
+		}
+	}
+
+	for _, s := range []string{
+		"",
+		"QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbd",
+		"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzd",
+		"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi1",
+		"zdj7WWeQ43G6JJvLWQWZpyHuAMq6uYWRjkBXFad11vE2LHhQ7",
+		strings.ToUpper("bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"),
+	} {
+		if err := parseCID(s); err == nil {
+			t.Errorf("parseCID(%q) got nil error", s)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Generates a new private key and saves it as an encrypted keystore file."
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: short,
+		Long: short + `
+
+The keystore can be used with the --keystore and --password-file flags of other commands, e.g. ethier sign, and imported into most wallets. With --mnemonic, the key is derived from a newly generated BIP39 mnemonic, which is printed so that it can be recorded as a backup; the mnemonic itself is not stored in the keystore.`,
+		RunE: keygen,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().String(keystoreFlag, "", "Path to which the encrypted key is saved; MUST NOT already exist")
+	cmd.Flags().String(passwordFileFlag, "", "File containing the password with which to encrypt --keystore")
+	addKeygenFlags(cmd)
+
+	rootCmd.AddCommand(cmd)
+}
+
+// addKeygenFlags adds the flags used by generateKey() to the command.
+func addKeygenFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(mnemonicFlag, false, "Derive the key from a new BIP39 mnemonic, which is printed")
+	cmd.Flags().Int("words", 24, "Number of words in the mnemonic; one of 12, 15, 18, 21, or 24")
+	cmd.Flags().String(derivationPathFlag, string(eth.DefaultHDPathPrefix)+"0", "Derivation path used with --mnemonic")
+}
+
+// keygen implements the `ethier keygen` command.
+func keygen(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	path, err := flags.GetString(keystoreFlag)
+	if err != nil {
+		return err
+	}
+	pwFile, err := flags.GetString(passwordFileFlag)
+	if err != nil {
+		return err
+	}
+
+	if path == "" || pwFile == "" {
+		return fmt.Errorf("--%s and --%s are required", keystoreFlag, passwordFileFlag)
+	}
+	pw, err := readPasswordFile(pwFile)
+	if err != nil {
+		return err
+	}
+	if pw == "" {
+		return errors.New("empty password")
+	}
+
+	return generateKey(cmd, func(s *eth.Signer) (string, error) {
+		return path, s.SaveKeystore(path, pw)
+	})
+}
+
+// generateKey generates a new Signer in accordance with the flags added by
+// addKeygenFlags(), passes it to save(), which returns the path to which the
+// key was saved, and then prints the Signer's address and any mnemonic.
+func generateKey(cmd *cobra.Command, save func(*eth.Signer) (string, error)) error {
+	flags := cmd.Flags()
+	withMnemonic, err := flags.GetBool(mnemonicFlag)
+	if err != nil {
+		return err
+	}
+	words, err := flags.GetInt("words")
+	if err != nil {
+		return err
+	}
+	hdPath, err := flags.GetString(derivationPathFlag)
+	if err != nil {
+		return err
+	}
+
+	var (
+		s        *eth.Signer
+		mnemonic string
+	)
+	if withMnemonic {
+		s, mnemonic, err = newMnemonicSigner(words, hdPath)
+	} else {
+		s, err = eth.NewSigner(256)
+	}
+	if err != nil {
+		return err
+	}
+
+	path, err := save(s)
+	if err != nil {
+		return err
+	}
+	log.Printf("Key saved to %q", path)
+
+	fmt.Printf("Address: %v\n", s.Address())
+	if mnemonic != "" {
+		fmt.Printf("Mnemonic: %s\nPath: %s\n", mnemonic, hdPath)
+		log.Print("Record the mnemonic securely; anyone with it controls the key")
+	}
+	return nil
+}
+
+// newMnemonicSigner generates a new BIP39 mnemonic of the specified number of
+// words and returns it along with the Signer derived from it at path.
+func newMnemonicSigner(words int, path string) (*eth.Signer, string, error) {
+	switch words {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, "", fmt.Errorf("invalid mnemonic length %d words", words)
+	}
+	// Each word encodes 11 bits, of which 1/33 are checksum.
+	m, err := eth.NewMnemonic(words * 32 / 3)
+	if err != nil {
+		return nil, "", err
+	}
+	s, err := eth.NewSignerFromMnemonic(m, path)
+	if err != nil {
+		return nil, "", err
+	}
+	return s, m, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestNewMnemonicSigner(t *testing.T) {
+	const path = "m/44'/60'/0'/0/3"
+
+	for _, words := range []int{12, 15, 18, 21, 24} {
+		s, m, err := newMnemonicSigner(words, path)
+		if err != nil {
+			t.Fatalf("newMnemonicSigner(%d) error %v", words, err)
+		}
+		if got := len(strings.Fields(m)); got != words {
+			t.Errorf("newMnemonicSigner(%d) got %d-word mnemonic", words, got)
+		}
+
+		want, err := eth.NewSignerFromMnemonic(m, path)
+		if err != nil {
+			t.Fatalf("eth.NewSignerFromMnemonic() error %v", err)
+		}
+		if got, want := s.Address(), want.Address(); got != want {
+			t.Errorf("newMnemonicSigner(%d) got address %v; derived from mnemonic %v", words, got, want)
+		}
+	}
+
+	for _, words := range []int{0, 11, 25} {
+		if _, _, err := newMnemonicSigner(words, path); err == nil {
+			t.Errorf("newMnemonicSigner(%d) got nil error; want error", words)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/eth/merkle"
+)
+
+func init() {
+	const short = "Builds Merkle trees, compatible with OpenZeppelin's MerkleProof, from leaves read from stdin."
+
+	cmd := &cobra.Command{
+		Use:   "merkle",
+		Short: short,
+		Long: short + `
+
+Input is one value per line, interpreted according to --leaves:
+  address: leaf is keccak256(abi.encodePacked(address)), as for allowlists
+  data:    leaf is keccak256(data), with lines beginning 0x decoded as hex and others used as UTF-8
+  hash:    line is a 32-byte hex leaf, used as-is`,
+	}
+	cmd.PersistentFlags().String("leaves", "address", "Interpretation of input lines: address, data, or hash")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "root",
+			Short: "Outputs the Merkle root",
+			RunE:  merkleRoot,
+			Args:  cobra.NoArgs,
+		},
+		merkleProofsCmd(),
+	)
+	rootCmd.AddCommand(cmd)
+}
+
+func merkleProofsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proofs",
+		Short: "Outputs the Merkle root and a proof for every leaf",
+		Long: `Outputs the Merkle root and a proof for every leaf.
+
+With --format json (the default), output is a single object including the root and all entries. With --format csv, output has input, leaf, and proof columns, with proof elements separated by semicolons; the root is logged to stderr.`,
+		RunE: merkleProofs,
+		Args: cobra.NoArgs,
+	}
+	cmd.Flags().String("format", "json", "Output format: json or csv")
+	return cmd
+}
+
+// A merkleEntry is a single leaf, and its proof, output by `ethier merkle
+// proofs`.
+type merkleEntry struct {
+	// Input is the line from which the leaf was derived.
+	Input string        `json:"input"`
+	Leaf  common.Hash   `json:"leaf"`
+	Proof []common.Hash `json:"proof"`
+}
+
+// A merkleOutput is the JSON output of `ethier merkle proofs`.
+type merkleOutput struct {
+	Root    common.Hash   `json:"root"`
+	Entries []merkleEntry `json:"entries"`
+}
+
+// merkleTreeFromFlags builds a Merkle tree from leaves read from r, as
+// interpreted by the --leaves flag, returning the tree and the input lines.
+func merkleTreeFromFlags(cmd *cobra.Command, r io.Reader) (*merkle.Tree, []string, error) {
+	kind, err := cmd.Flags().GetString("leaves")
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs, leaves, err := readMerkleLeaves(r, kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	t, err := merkle.New(leaves)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, inputs, nil
+}
+
+// readMerkleLeaves reads non-empty lines from r and converts them to leaves of
+// the specified kind.
+func readMerkleLeaves(r io.Reader, kind string) ([]string, []common.Hash, error) {
+	var toLeaf func(string) (common.Hash, error)
+	switch kind {
+	case "address":
+		toLeaf = func(l string) (common.Hash, error) {
+			addr, err := eth.ParseAddress(l)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			return merkle.AddressLeaf(addr), nil
+		}
+	case "data":
+		toLeaf = func(l string) (common.Hash, error) {
+			buf, err := parseMessage(l)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			return merkle.LeafHash(buf), nil
+		}
+	case "hash":
+		toLeaf = func(l string) (common.Hash, error) {
+			buf, err := hexutil.Decode(l)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			if n := len(buf); n != common.HashLength {
+				return common.Hash{}, fmt.Errorf("hash length %d; expecting %d", n, common.HashLength)
+			}
+			return common.BytesToHash(buf), nil
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported --leaves %q", kind)
+	}
+
+	var (
+		inputs []string
+		leaves []common.Hash
+	)
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		l := strings.TrimSpace(s.Text())
+		if l == "" {
+			continue
+		}
+		leaf, err := toLeaf(l)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		inputs = append(inputs, l)
+		leaves = append(leaves, leaf)
+	}
+	if err := s.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read input: %v", err)
+	}
+	return inputs, leaves, nil
+}
+
+// merkleRoot implements the `ethier merkle root` command.
+func merkleRoot(cmd *cobra.Command, args []string) error {
+	t, _, err := merkleTreeFromFlags(cmd, os.Stdin)
+	if err != nil {
+		return err
+	}
+	fmt.Println(t.Root().Hex())
+	return nil
+}
+
+// merkleProofs implements the `ethier merkle proofs` command.
+func merkleProofs(cmd *cobra.Command, args []string) error {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unsupported --format %q", format)
+	}
+
+	t, inputs, err := merkleTreeFromFlags(cmd, os.Stdin)
+	if err != nil {
+		return err
+	}
+	out, err := newMerkleOutput(t, inputs)
+	if err != nil {
+		return err
+	}
+
+	if format == "csv" {
+		log.Printf("Merkle root %v", out.Root)
+		return writeMerkleCSV(os.Stdout, out)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// newMerkleOutput returns the root of t and a proof for each of its leaves,
+// which MUST have been derived from the respective inputs.
+func newMerkleOutput(t *merkle.Tree, inputs []string) (*merkleOutput, error) {
+	out := &merkleOutput{Root: t.Root()}
+	for i, leaf := range t.Leaves() {
+		proof, err := t.Proof(i)
+		if err != nil {
+			return nil, err
+		}
+		out.Entries = append(out.Entries, merkleEntry{
+			Input: inputs[i],
+			Leaf:  leaf,
+			Proof: proof,
+		})
+	}
+	return out, nil
+}
+
+// writeMerkleCSV writes the entries of out to w as CSV.
+func writeMerkleCSV(w io.Writer, out *merkleOutput) error {
+	c := csv.NewWriter(w)
+	if err := c.Write([]string{"input", "leaf", "proof"}); err != nil {
+		return err
+	}
+	for _, e := range out.Entries {
+		proof := make([]string, len(e.Proof))
+		for i, p := range e.Proof {
+			proof[i] = p.Hex()
+		}
+		if err := c.Write([]string{e.Input, e.Leaf.Hex(), strings.Join(proof, ";")}); err != nil {
+			return err
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/divergencetech/ethier/eth/merkle"
+)
+
+func TestMerkleProofs(t *testing.T) {
+	tests := []struct {
+		kind, in string
+		want     []common.Hash
+	}{
+		{
+			kind: "address",
+			in:   "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed\n\n0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359\n0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB\n",
+			want: []common.Hash{
+				merkle.AddressLeaf(common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")),
+				merkle.AddressLeaf(common.HexToAddress("0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359")),
+				merkle.AddressLeaf(common.HexToAddress("0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB")),
+			},
+		},
+		{
+			kind: "data",
+			in:   "hello\n0xc0ffee\n",
+			want: []common.Hash{
+				crypto.Keccak256Hash([]byte("hello")),
+				crypto.Keccak256Hash([]byte{0xc0, 0xff, 0xee}),
+			},
+		},
+		{
+			kind: "hash",
+			in:   "0x0000000000000000000000000000000000000000000000000000000000000001\n0x0000000000000000000000000000000000000000000000000000000000000002\n",
+			want: []common.Hash{
+				common.BigToHash(common.Big1),
+				common.BigToHash(common.Big2),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			inputs, leaves, err := readMerkleLeaves(strings.NewReader(tt.in), tt.kind)
+			if err != nil {
+				t.Fatalf("readMerkleLeaves() error %v", err)
+			}
+			if got, want := len(leaves), len(tt.want); got != want {
+				t.Fatalf("readMerkleLeaves() got %d leaves; want %d", got, want)
+			}
+			for i := range leaves {
+				if leaves[i] != tt.want[i] {
+					t.Errorf("readMerkleLeaves() leaf %d (%q) got %v; want %v", i, inputs[i], leaves[i], tt.want[i])
+				}
+			}
+
+			tree, err := merkle.New(leaves)
+			if err != nil {
+				t.Fatalf("merkle.New() error %v", err)
+			}
+			out, err := newMerkleOutput(tree, inputs)
+			if err != nil {
+				t.Fatalf("newMerkleOutput() error %v", err)
+			}
+			for i, e := range out.Entries {
+				if !merkle.Verify(out.Root, e.Leaf, e.Proof) {
+					t.Errorf("entry %d (%q) proof not verified against root", i, e.Input)
+				}
+			}
+
+			var buf bytes.Buffer
+			if err := writeMerkleCSV(&buf, out); err != nil {
+				t.Fatalf("writeMerkleCSV() error %v", err)
+			}
+			if got, want := strings.Count(buf.String(), "\n"), len(leaves)+1; got != want {
+				t.Errorf("writeMerkleCSV() got %d lines; want %d", got, want)
+			}
+		})
+	}
+
+	for _, tt := range []struct{ kind, in string }{
+		{"address", "0x1234"},
+		{"data", "0xnothex"},
+		{"hash", "0x1234"},
+		{"unknown", "0x1234"},
+	} {
+		if _, _, err := readMerkleLeaves(strings.NewReader(tt.in), tt.kind); err == nil {
+			t.Errorf("readMerkleLeaves(%q, %q) got nil error; want error", tt.in, tt.kind)
+		}
+	}
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd := &cobra.Command{
+		Use:   "metadata",
+		Short: "Checks token metadata",
+	}
+
+	const short = "Checks every token's metadata JSON in a directory against a marketplace's metadata standard."
+
+	validate := &cobra.Command{
+		Use:   "validate <dir>",
+		Short: short,
+		Long: short + `
+
+Token metadata files are those in the directory, but not subdirectories, named by a decimal token ID, or 64 hex characters as for ERC-1155 {id} substitution, with an optional .json extension. Other files, e.g. contract-level metadata, are ignored.
+
+With --standard erc721, only the types of fields defined by ERC-721 and ERC-1155 are checked, along with the de-facto attributes array. With --standard opensea (the default), each file MUST also have an image, a background_color MUST be 6 hex characters, and attributes with a display_type of number, boost_number, boost_percentage, or date MUST have numeric values, no greater than any max_value.
+
+Token IDs MUST be sequential, from --first-id or, by default, from whichever of 0 and 1 is the lowest present. Unless --check-uris is false, every image and animation_url MUST be reachable; ipfs:// URIs are fetched through --gateway and ar:// URIs through arweave.net.
+
+All problems are printed, one per line, before an error is returned.`,
+		RunE: metadataValidate,
+		Args: cobra.ExactArgs(1),
+	}
+	validate.Flags().String("standard", "opensea", "Metadata standard: erc721 or opensea")
+	validate.Flags().Int64("first-id", -1, "First token ID; -1 = lowest of 0 and 1 present")
+	validate.Flags().Bool("check-uris", true, "Check that image and animation URIs are reachable")
+	validate.Flags().String("gateway", "https://ipfs.io", "IPFS HTTP gateway through which ipfs:// URIs are checked")
+	validate.Flags().IntP("workers", "w", 8, "Number of URIs concurrently checked")
+	validate.Flags().Duration("timeout", 30*time.Second, "Maximum time to check each URI")
+
+	cmd.AddCommand(validate)
+	rootCmd.AddCommand(cmd)
+}
+
+// metadataValidate implements the `ethier metadata validate` command.
+func metadataValidate(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	standard, err := flags.GetString("standard")
+	if err != nil {
+		return err
+	}
+	firstID, err := flags.GetInt64("first-id")
+	if err != nil {
+		return err
+	}
+	checkURIs, err := flags.GetBool("check-uris")
+	if err != nil {
+		return err
+	}
+	gateway, err := flags.GetString("gateway")
+	if err != nil {
+		return err
+	}
+	workers, err := flags.GetInt("workers")
+	if err != nil {
+		return err
+	}
+	timeout, err := flags.GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	v := &metadataValidator{
+		standard: standard,
+		gateway:  gateway,
+		workers:  workers,
+	}
+	if firstID >= 0 {
+		v.firstID = big.NewInt(firstID)
+	}
+	if checkURIs {
+		v.client = &http.Client{Timeout: timeout}
+	}
+
+	n, problems, err := v.validateDir(context.Background(), args[0])
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problems in metadata for %d tokens", len(problems), n)
+	}
+	log.Printf("Metadata for %d tokens valid", n)
+	return nil
+}
+
+// A metadataValidator checks directories of token metadata.
+type metadataValidator struct {
+	// standard is erc721 or opensea.
+	standard string
+	// firstID, if non-nil, is the lowest expected token ID.
+	firstID *big.Int
+	// client, if non-nil, is used to check that URIs are reachable, with
+	// ipfs:// URIs resolved through the gateway.
+	client  *http.Client
+	gateway string
+	// workers is the number of URIs concurrently checked; if <= 0,
+	// runtime.NumCPU() is used.
+	workers int
+}
+
+// A metadataFile is a single token's metadata file.
+type metadataFile struct {
+	name string
+	id   *big.Int
+}
+
+// erc1155FileName matches the names of files to which ERC-1155 {id}
+// substitution resolves.
+var erc1155FileName = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// metadataTokenFiles returns the token metadata files in dir, in order of
+// token ID.
+func metadataTokenFiles(dir string) ([]*metadataFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*metadataFile
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		base := strings.TrimSuffix(e.Name(), ".json")
+		id, ok := new(big.Int), false
+		switch {
+		case erc1155FileName.MatchString(base):
+			id, ok = id.SetString(base, 16)
+		case base != "" && strings.Trim(base, "0123456789") == "" && (base == "0" || base[0] != '0'):
+			id, ok = id.SetString(base, 10)
+		}
+		if !ok {
+			continue
+		}
+		files = append(files, &metadataFile{name: e.Name(), id: id})
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no token metadata files in %q", dir)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].id.Cmp(files[j].id) < 0
+	})
+	return files, nil
+}
+
+// validateDir checks all token metadata in dir, returning the number of
+// tokens and a description of each problem found. The returned error is only
+// non-nil if validation itself fails, e.g. if files can't be read.
+func (v *metadataValidator) validateDir(ctx context.Context, dir string) (int, []string, error) {
+	var check func(map[string]interface{}) []string
+	switch v.standard {
+	case "erc721":
+		check = erc721MetadataProblems
+	case "opensea":
+		check = openSeaMetadataProblems
+	default:
+		return 0, nil, fmt.Errorf("unsupported metadata standard %q", v.standard)
+	}
+
+	files, err := metadataTokenFiles(dir)
+	if err != nil {
+		return 0, nil, err
+	}
+	problems := v.sequenceProblems(files)
+
+	var (
+		uris     []string
+		uriFiles = make(map[string][]string)
+	)
+	for _, f := range files {
+		buf, err := os.ReadFile(filepath.Join(dir, f.name))
+		if err != nil {
+			return 0, nil, err
+		}
+		md, err := parseMetadata(buf)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", f.name, err))
+			continue
+		}
+		for _, p := range check(md) {
+			problems = append(problems, fmt.Sprintf("%s: %s", f.name, p))
+		}
+
+		for _, field := range []string{"image", "animation_url"} {
+			u, ok := md[field].(string)
+			if !ok || u == "" {
+				continue
+			}
+			if _, seen := uriFiles[u]; !seen {
+				uris = append(uris, u)
+			}
+			uriFiles[u] = append(uriFiles[u], fmt.Sprintf("%s: %s", f.name, field))
+		}
+	}
+
+	if v.client != nil {
+		for i, err := range v.checkURIs(ctx, uris) {
+			if err == nil {
+				continue
+			}
+			for _, where := range uriFiles[uris[i]] {
+				problems = append(problems, fmt.Sprintf("%s %q unreachable: %v", where, uris[i], err))
+			}
+		}
+	}
+	return len(files), problems, nil
+}
+
+// sequenceProblems describes any duplicate or missing token IDs among the
+// files, which MUST be sorted by ID.
+func (v *metadataValidator) sequenceProblems(files []*metadataFile) []string {
+	var problems []string
+
+	next := v.firstID
+	if next == nil {
+		next = new(big.Int)
+		if files[0].id.Sign() > 0 {
+			next.SetInt64(1)
+		}
+	}
+	next = new(big.Int).Set(next)
+
+	for i, f := range files {
+		switch c := f.id.Cmp(next); {
+		case i > 0 && f.id.Cmp(files[i-1].id) == 0:
+			problems = append(problems, fmt.Sprintf("%s: duplicate token ID %v, also in %s", f.name, f.id, files[i-1].name))
+			continue
+		case c < 0:
+			problems = append(problems, fmt.Sprintf("%s: token ID %v before first ID %v", f.name, f.id, next))
+			continue
+		case c > 0:
+			last := new(big.Int).Sub(f.id, big.NewInt(1))
+			if last.Cmp(next) == 0 {
+				problems = append(problems, fmt.Sprintf("missing token ID %v", next))
+			} else {
+				problems = append(problems, fmt.Sprintf("missing token IDs %v to %v", next, last))
+			}
+		}
+		next = new(big.Int).Add(f.id, big.NewInt(1))
+	}
+	return problems
+}
+
+// checkURIs checks that each URI is reachable, returning errors in the same
+// order as the URIs.
+func (v *metadataValidator) checkURIs(ctx context.Context, uris []string) []error {
+	workers := v.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	errs := make([]error, len(uris))
+	idx := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				errs[i] = v.checkURI(ctx, uris[i])
+			}
+		}()
+	}
+	for i := range uris {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+	return errs
+}
+
+// checkURI checks that the URI is reachable, with a HEAD request if the server
+// supports it, otherwise a GET.
+func (v *metadataValidator) checkURI(ctx context.Context, uri string) error {
+	u, err := resolveMetadataURI(uri, v.gateway)
+	if err != nil || u == "" {
+		return err
+	}
+
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequestWithContext(ctx, method, u, nil)
+		if err != nil {
+			return err
+		}
+		res, err := v.client.Do(req)
+		if err != nil {
+			return err
+		}
+		io.Copy(io.Discard, io.LimitReader(res.Body, 1<<10))
+		res.Body.Close()
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			return nil
+		case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+			continue
+		}
+		return fmt.Errorf("%s %s: %s", method, u, res.Status)
+	}
+	return fmt.Errorf("GET %s not allowed", u)
+}
+
+// resolveMetadataURI returns the HTTP(S) URL from which the URI can be
+// fetched, with ipfs:// resolved through the gateway and ar:// through
+// arweave.net. Data URIs are returned as an empty string as they needn't be
+// fetched.
+func resolveMetadataURI(uri, gateway string) (string, error) {
+	i := strings.Index(uri, ":")
+	if i == -1 {
+		return "", errors.New("no URI scheme")
+	}
+
+	switch scheme, rest := strings.ToLower(uri[:i]), uri[i+1:]; scheme {
+	case "http", "https":
+		return uri, nil
+	case "data":
+		return "", nil
+	case "ipfs":
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, "//"), "ipfs/")
+		return strings.TrimSuffix(gateway, "/") + "/ipfs/" + rest, nil
+	case "ar":
+		return "https://arweave.net/" + strings.TrimPrefix(rest, "//"), nil
+	default:
+		return "", fmt.Errorf("unsupported URI scheme %q", scheme)
+	}
+}
+
+// parseMetadata parses buf as a JSON object.
+func parseMetadata(buf []byte) (map[string]interface{}, error) {
+	var md map[string]interface{}
+	if err := json.Unmarshal(buf, &md); err != nil {
+		return nil, fmt.Errorf("not a JSON object: %v", err)
+	}
+	if md == nil {
+		return nil, errors.New("not a JSON object: null")
+	}
+	return md, nil
+}
+
+// checkMetadataSchema checks that buf is token metadata JSON conforming to the
+// ERC-721 and ERC-1155 metadata schemas, as well as the de-facto standard for
+// attributes; i.e. an array of objects, each with a value. All problems are
+// included in the returned error.
+func checkMetadataSchema(buf []byte) error {
+	md, err := parseMetadata(buf)
+	if err != nil {
+		return err
+	}
+	if problems := erc721MetadataProblems(md); len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// erc721MetadataProblems describes all problems found by
+// checkMetadataSchema().
+func erc721MetadataProblems(md map[string]interface{}) []string {
+	var problems []string
+	for _, field := range []string{"name", "description", "image", "image_data", "external_url", "animation_url", "background_color"} {
+		v, ok := md[field]
+		if !ok {
+			continue
+		}
+		if _, ok := v.(string); !ok {
+			problems = append(problems, fmt.Sprintf("%q is %s; want string", field, jsonType(v)))
+		}
+	}
+
+	for i, attr := range metadataAttributes(md, &problems) {
+		if attr == nil {
+			continue
+		}
+		if _, ok := attr["value"]; !ok {
+			problems = append(problems, fmt.Sprintf(`attribute %d has no "value"`, i))
+		}
+		for _, field := range []string{"trait_type", "display_type"} {
+			if v, ok := attr[field]; ok {
+				if _, ok := v.(string); !ok {
+					problems = append(problems, fmt.Sprintf("attribute %d %q is %s; want string", i, field, jsonType(v)))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// openSeaDisplayTypes are the attribute display types supported by OpenSea,
+// all of which require numeric values.
+var openSeaDisplayTypes = map[string]bool{
+	"number":           true,
+	"boost_number":     true,
+	"boost_percentage": true,
+	"date":             true,
+}
+
+// backgroundColor matches OpenSea's background_color format.
+var backgroundColor = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// openSeaMetadataProblems describes all problems found by
+// erc721MetadataProblems(), plus those specific to OpenSea's metadata
+// standard.
+func openSeaMetadataProblems(md map[string]interface{}) []string {
+	problems := erc721MetadataProblems(md)
+
+	img, _ := md["image"].(string)
+	data, _ := md["image_data"].(string)
+	if img == "" && data == "" {
+		problems = append(problems, `no "image" or "image_data"`)
+	}
+	if c, ok := md["background_color"].(string); ok && !backgroundColor.MatchString(c) {
+		problems = append(problems, fmt.Sprintf(`"background_color" %q not 6 hex characters without #`, c))
+	}
+
+	var ignored []string // already reported by erc721MetadataProblems()
+	for i, attr := range metadataAttributes(md, &ignored) {
+		if attr == nil {
+			continue
+		}
+		val, hasVal := attr["value"]
+		switch val.(type) {
+		case string, float64:
+		default:
+			if hasVal {
+				problems = append(problems, fmt.Sprintf("attribute %d value is %s; want string or number", i, jsonType(val)))
+			}
+		}
+
+		dt, ok := attr["display_type"].(string)
+		if !ok {
+			continue
+		}
+		if !openSeaDisplayTypes[dt] {
+			problems = append(problems, fmt.Sprintf("attribute %d unsupported display_type %q", i, dt))
+			continue
+		}
+		num, ok := val.(float64)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("attribute %d with display_type %q has %s value; want number", i, dt, jsonType(val)))
+			continue
+		}
+		if max, ok := attr["max_value"]; ok {
+			m, ok := max.(float64)
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("attribute %d max_value is %s; want number", i, jsonType(max)))
+			case num > m:
+				problems = append(problems, fmt.Sprintf("attribute %d value %v exceeds max_value %v", i, num, m))
+			}
+		}
+	}
+	return problems
+}
+
+// metadataAttributes returns the objects in md's attributes array, appending
+// to problems if it, or any of its elements, is of the wrong type. Elements
+// that aren't objects are returned as nil.
+func metadataAttributes(md map[string]interface{}, problems *[]string) []map[string]interface{} {
+	raw, ok := md["attributes"]
+	if !ok {
+		return nil
+	}
+	arr, ok := raw.([]interface{})
+	if !ok {
+		*problems = append(*problems, fmt.Sprintf(`"attributes" is %s; want array`, jsonType(raw)))
+		return nil
+	}
+
+	attrs := make([]map[string]interface{}, len(arr))
+	for i, a := range arr {
+		attr, ok := a.(map[string]interface{})
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("attribute %d is %s; want object", i, jsonType(a)))
+			continue
+		}
+		attrs[i] = attr
+	}
+	return attrs
+}
+
+// jsonType returns the JSON type of a value decoded into an interface{}.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
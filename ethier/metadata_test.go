@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckMetadataSchema(t *testing.T) {
+	tests := []struct {
+		name, json string
+		wantErr    bool
+	}{
+		{
+			name: "valid",
+			json: `{"name": "Token 1", "description": "…", "image": "ipfs://Qm/1.png", "attributes": [{"trait_type": "Hat", "value": "Cap"}, {"display_type": "number", "trait_type": "Level", "value": 3}, {"value": "untyped"}]}`,
+		},
+		{
+			name: "empty object",
+			json: `{}`,
+		},
+		{
+			name:    "array",
+			json:    `[]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			json:    `{"name": "Token 1",}`,
+			wantErr: true,
+		},
+		{
+			name:    "numeric name",
+			json:    `{"name": 1}`,
+			wantErr: true,
+		},
+		{
+			name:    "attributes object",
+			json:    `{"attributes": {"Hat": "Cap"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "attribute without value",
+			json:    `{"attributes": [{"trait_type": "Hat"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "numeric trait type",
+			json:    `{"attributes": [{"trait_type": 1, "value": "Cap"}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMetadataSchema([]byte(tt.json))
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("checkMetadataSchema(%s) got err %v; want error = %t", tt.json, err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("dir", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "0", `{"name": "Token 0"}`)
+		writeFile(t, dir, "1.json", `{"name": "Token 1"}`)
+		writeFile(t, dir, "0.png", `not JSON`)
+
+		if err := checkMetadataDir(dir, []string{"0", "1.json", "0.png"}); err != nil {
+			t.Errorf("checkMetadataDir(<valid>) error %v", err)
+		}
+		writeFile(t, dir, "2", `{"name": 2}`)
+		if err := checkMetadataDir(dir, []string{"0", "1.json", "0.png", "2"}); err == nil {
+			t.Errorf("checkMetadataDir(<invalid>) got nil error")
+		}
+	})
+}
+
+func TestValidateMetadataDir(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ipfs/QmImages/1.png", "/2.png":
+		case "/head-not-allowed.png":
+			if r.Method == http.MethodHead {
+				http.Error(w, "", http.StatusMethodNotAllowed)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "1", `{"name": "One", "image": "ipfs://QmImages/1.png", "attributes": [{"display_type": "number", "trait_type": "Level", "value": 3, "max_value": 5}]}`)
+	writeFile(t, dir, "2.json", `{"name": "Two", "image": "`+srv.URL+`/2.png", "animation_url": "`+srv.URL+`/missing.mp4"}`)
+	writeFile(t, dir, "2", `{"name": "Two again", "image": "`+srv.URL+`/2.png"}`)
+	writeFile(t, dir, "3", `{"name": "Three", "image": "`+srv.URL+`/head-not-allowed.png", "background_color": "#ffffff"}`)
+	writeFile(t, dir, "6", `{"name": "Six", "image": "data:image/svg+xml;base64,PHN2Zy8+", "attributes": [{"display_type": "date", "trait_type": "Born", "value": "yesterday"}, {"trait_type": "Hat", "value": null}]}`)
+	writeFile(t, dir, "7", `{"name": "Seven"}`)
+	writeFile(t, dir, "collection.json", `{"name": "Collection"}`)
+	writeFile(t, dir, "01", `{"name": "Not a token"}`)
+
+	tests := []struct {
+		name         string
+		v            *metadataValidator
+		wantN        int
+		wantProblems []string
+	}{
+		{
+			name:  "opensea",
+			v:     &metadataValidator{standard: "opensea", client: srv.Client(), gateway: srv.URL, workers: 2},
+			wantN: 6,
+			wantProblems: []string{
+				"2.json: duplicate token ID 2, also in 2",
+				"missing token IDs 4 to 5",
+				`3: "background_color" "#ffffff" not 6 hex characters without #`,
+				`6: attribute 0 with display_type "date" has string value; want number`,
+				`6: attribute 1 value is null; want string or number`,
+				`7: no "image" or "image_data"`,
+				`2.json: animation_url "` + srv.URL + `/missing.mp4" unreachable: HEAD ` + srv.URL + `/missing.mp4: 404 Not Found`,
+			},
+		},
+		{
+			name:  "erc721 from 0 without URI checks",
+			v:     &metadataValidator{standard: "erc721", firstID: big.NewInt(0)},
+			wantN: 6,
+			wantProblems: []string{
+				"missing token ID 0",
+				"2.json: duplicate token ID 2, also in 2",
+				"missing token IDs 4 to 5",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, problems, err := tt.v.validateDir(ctx, dir)
+			if err != nil {
+				t.Fatalf("validateDir() error %v", err)
+			}
+			if n != tt.wantN {
+				t.Errorf("validateDir() got %d tokens; want %d", n, tt.wantN)
+			}
+			if diff := cmp.Diff(tt.wantProblems, problems); diff != "" {
+				t.Errorf("validateDir() problems diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("unsupported standard", func(t *testing.T) {
+		v := &metadataValidator{standard: "rarible"}
+		if _, _, err := v.validateDir(ctx, dir); err == nil {
+			t.Errorf("validateDir(<standard %q>) got nil error", v.standard)
+		}
+	})
+}
+
+func TestResolveMetadataURI(t *testing.T) {
+	const gateway = "https://gateway.example/"
+
+	tests := []struct {
+		uri, want string
+		wantErr   bool
+	}{
+		{uri: "ipfs://QmX/1.png", want: "https://gateway.example/ipfs/QmX/1.png"},
+		{uri: "ipfs://ipfs/QmX/1.png", want: "https://gateway.example/ipfs/QmX/1.png"},
+		{uri: "ar://abc", want: "https://arweave.net/abc"},
+		{uri: "https://example.com/1.png", want: "https://example.com/1.png"},
+		{uri: "data:image/svg+xml;utf8,<svg/>", want: ""},
+		{uri: "ftp://example.com/1.png", wantErr: true},
+		{uri: "1.png", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveMetadataURI(tt.uri, gateway)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("resolveMetadataURI(%q) got err %v; want error = %t", tt.uri, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveMetadataURI(%q) got %q; want %q", tt.uri, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Batches read-only contract calls through Multicall3 and prints the decoded results."
+
+	cmd := &cobra.Command{
+		Use:   "multicall",
+		Short: short,
+		Long: short + `
+
+The --calls file, or stdin if it is "-", is a JSON array of calls, each of the form {"label": "…", "to": "0x…", "function": "balanceOf(address)(uint256)", "args": ["0x…"]}, with the label and args optional. Functions and arguments are as for ethier call; return types are required to decode results, otherwise the raw return data is output.
+
+Calls are sent in batches of --batch-size, each as a single eth_call to Multicall3's aggregate3(), which is deployed at the same address on most chains. Failure of an individual call doesn't fail the batch; the revert reason is instead included in its result.
+
+Output is a JSON array of results, in the same order as the calls, or an aligned table with --format text.`,
+		RunE: multicall,
+		Args: cobra.NoArgs,
+	}
+	addRPCFlag(cmd)
+	cmd.Flags().String("calls", "-", `JSON file of calls; "-" for stdin`)
+	cmd.Flags().String("multicall", "0xcA11bde05977b3631167028862bE2a173976CA11", "Address of the Multicall3 contract")
+	cmd.Flags().Int("batch-size", 500, "Maximum number of calls per eth_call")
+	cmd.Flags().Int64("block", -1, "Block number at which to call; -1 = latest")
+	cmd.Flags().String("format", "json", "Output format: json or text")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// multicall implements the `ethier multicall` command.
+func multicall(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	callsPath, err := flags.GetString("calls")
+	if err != nil {
+		return err
+	}
+	mcHex, err := flags.GetString("multicall")
+	if err != nil {
+		return err
+	}
+	batchSize, err := flags.GetInt("batch-size")
+	if err != nil {
+		return err
+	}
+	block, err := flags.GetInt64("block")
+	if err != nil {
+		return err
+	}
+	format, err := flags.GetString("format")
+	if err != nil {
+		return err
+	}
+	if format != "json" && format != "text" {
+		return fmt.Errorf("unsupported --format %q", format)
+	}
+	if batchSize < 1 {
+		return fmt.Errorf("--batch-size must be positive; got %d", batchSize)
+	}
+
+	mc, err := eth.ParseAddress(mcHex)
+	if err != nil {
+		return fmt.Errorf("--multicall: %v", err)
+	}
+
+	var r io.Reader = os.Stdin
+	if callsPath != "-" {
+		f, err := os.Open(callsPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	calls, err := readMulticalls(r)
+	if err != nil {
+		return err
+	}
+
+	var blockNum *big.Int
+	if block >= 0 {
+		blockNum = big.NewInt(block)
+	}
+
+	ctx := context.Background()
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	results, err := aggregateCalls(ctx, client, mc, calls, batchSize, blockNum)
+	if err != nil {
+		return err
+	}
+
+	if format == "text" {
+		return writeMulticallTable(os.Stdout, results)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// A multicallSpec is a single call in the input to `ethier multicall`.
+type multicallSpec struct {
+	Label    string   `json:"label"`
+	To       string   `json:"to"`
+	Function string   `json:"function"`
+	Args     []string `json:"args"`
+}
+
+// A parsedMulticall is a multicallSpec that has been parsed and packed.
+type parsedMulticall struct {
+	spec   multicallSpec
+	to     common.Address
+	method abi.Method
+	data   []byte
+}
+
+// readMulticalls reads and parses a JSON array of multicallSpecs.
+func readMulticalls(r io.Reader) ([]*parsedMulticall, error) {
+	var specs []multicallSpec
+	if err := json.NewDecoder(r).Decode(&specs); err != nil {
+		return nil, fmt.Errorf("decode calls: %v", err)
+	}
+	if len(specs) == 0 {
+		return nil, errors.New("no calls")
+	}
+
+	calls := make([]*parsedMulticall, len(specs))
+	for i, s := range specs {
+		to, err := eth.ParseAddress(s.To)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %v", i, err)
+		}
+		method, data, err := packCall(s.Function, s.Args)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %v", i, err)
+		}
+		calls[i] = &parsedMulticall{spec: s, to: to, method: method, data: data}
+	}
+	return calls, nil
+}
+
+// multicall3ABI is the subset of the Multicall3 ABI used by `ethier
+// multicall`.
+const multicall3ABI = `[{"type":"function","name":"aggregate3","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}]`
+
+// multicall3 is the parsed multicall3ABI.
+var multicall3 = func() abi.ABI {
+	a, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		panic(fmt.Sprintf("parse Multicall3 ABI: %v", err))
+	}
+	return a
+}()
+
+// multicall3Call and multicall3Result are the Go equivalents of Multicall3's
+// Call3 and Result structs.
+type (
+	multicall3Call struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	multicall3Result struct {
+		Success    bool
+		ReturnData []byte
+	}
+)
+
+// A multicallResult is a single result output by `ethier multicall`.
+type multicallResult struct {
+	Label    string         `json:"label,omitempty"`
+	To       common.Address `json:"to"`
+	Function string         `json:"function"`
+	Args     []string       `json:"args,omitempty"`
+	Success  bool           `json:"success"`
+	Values   []string       `json:"values,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// aggregateCalls performs the calls via Multicall3.aggregate3() at the
+// address, in batches of at most batchSize, returning decoded results in the
+// same order as the calls.
+func aggregateCalls(ctx context.Context, caller ethereum.ContractCaller, mc common.Address, calls []*parsedMulticall, batchSize int, block *big.Int) ([]*multicallResult, error) {
+	results := make([]*multicallResult, 0, len(calls))
+	for start := 0; start < len(calls); start += batchSize {
+		end := start + batchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+		batch := calls[start:end]
+
+		in := make([]multicall3Call, len(batch))
+		for i, c := range batch {
+			in[i] = multicall3Call{Target: c.to, AllowFailure: true, CallData: c.data}
+		}
+		data, err := multicall3.Pack("aggregate3", in)
+		if err != nil {
+			return nil, fmt.Errorf("pack aggregate3: %v", err)
+		}
+		ret, err := caller.CallContract(ctx, ethereum.CallMsg{To: &mc, Data: data}, block)
+		if err != nil {
+			return nil, fmt.Errorf("calls %d to %d: aggregate3: %v", start, end-1, err)
+		}
+		if len(ret) == 0 {
+			return nil, fmt.Errorf("empty return data from aggregate3; is Multicall3 deployed at %v?", mc)
+		}
+
+		vals, err := multicall3.Unpack("aggregate3", ret)
+		if err != nil {
+			return nil, fmt.Errorf("unpack aggregate3 return data: %v", err)
+		}
+		var out []multicall3Result
+		out = *abi.ConvertType(vals[0], &out).(*[]multicall3Result)
+		if len(out) != len(batch) {
+			return nil, fmt.Errorf("aggregate3 returned %d results for %d calls", len(out), len(batch))
+		}
+
+		for i, c := range batch {
+			results = append(results, newMulticallResult(c, out[i]))
+		}
+	}
+	return results, nil
+}
+
+// newMulticallResult decodes the result of the call.
+func newMulticallResult(c *parsedMulticall, r multicall3Result) *multicallResult {
+	res := &multicallResult{
+		Label:    c.spec.Label,
+		To:       c.to,
+		Function: c.method.Sig,
+		Args:     c.spec.Args,
+		Success:  r.Success,
+	}
+	if !r.Success {
+		res.Error = "reverted"
+		if reason, err := abi.UnpackRevert(r.ReturnData); err == nil {
+			res.Error = fmt.Sprintf("reverted: %s", reason)
+		} else if len(r.ReturnData) > 0 {
+			res.Error = fmt.Sprintf("reverted: %s", hexutil.Encode(r.ReturnData))
+		}
+		return res
+	}
+
+	vals, err := unpackReturn(c.method, r.ReturnData)
+	if err != nil {
+		res.Success = false
+		res.Error = err.Error()
+		return res
+	}
+	res.Values = vals
+	return res
+}
+
+// writeMulticallTable writes the results as an aligned table, with one row per
+// result.
+func writeMulticallTable(w io.Writer, results []*multicallResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "label\tto\tfunction\tresult")
+	for _, r := range results {
+		label := r.Label
+		if label == "" {
+			label = "-"
+		}
+		fn := r.Function
+		if i := strings.IndexByte(fn, '('); i != -1 {
+			fn = fn[:i]
+		}
+		fn += "(" + strings.Join(r.Args, ", ") + ")"
+
+		result := strings.Join(r.Values, ", ")
+		if !r.Success {
+			result = "ERROR: " + r.Error
+		}
+		fmt.Fprintf(tw, "%s\t%v\t%s\t%s\n", label, r.To, fn, result)
+	}
+	return tw.Flush()
+}
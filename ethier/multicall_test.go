@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeMulticall3 implements aggregate3() by dispatching each call, by
+// selector, to a function returning its return data, or an error to simulate
+// a revert.
+type fakeMulticall3 struct {
+	addr    common.Address
+	funcs   map[string]func(target common.Address, args []byte) ([]byte, error)
+	batches int
+}
+
+func (f *fakeMulticall3) CallContract(ctx context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	if *msg.To != f.addr {
+		return nil, nil
+	}
+	f.batches++
+
+	vals, err := multicall3.Methods["aggregate3"].Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	var calls []multicall3Call
+	calls = *abi.ConvertType(vals[0], &calls).(*[]multicall3Call)
+
+	var results []multicall3Result
+	for _, c := range calls {
+		fn, ok := f.funcs[string(c.CallData[:4])]
+		if !ok {
+			results = append(results, multicall3Result{})
+			continue
+		}
+		ret, err := fn(c.Target, c.CallData[4:])
+		results = append(results, multicall3Result{Success: err == nil, ReturnData: ret})
+	}
+	return multicall3.Methods["aggregate3"].Outputs.Pack(results)
+}
+
+func TestAggregateCalls(t *testing.T) {
+	ctx := context.Background()
+
+	selector := func(sig string) string {
+		m, err := parseFunctionSignature(sig)
+		if err != nil {
+			t.Fatalf("parseFunctionSignature(%q) error %v", sig, err)
+		}
+		return string(m.ID)
+	}
+	revert, err := abi.JSON(strings.NewReader(`[{"type":"function","name":"Error","inputs":[{"type":"string"}]}]`))
+	if err != nil {
+		t.Fatalf("abi.JSON() error %v", err)
+	}
+
+	owner := common.HexToAddress("0x0e")
+	mc := &fakeMulticall3{
+		addr: common.HexToAddress("0xca11"),
+		funcs: map[string]func(common.Address, []byte) ([]byte, error){
+			selector("owner()"): func(common.Address, []byte) ([]byte, error) {
+				return owner.Hash().Bytes(), nil
+			},
+			selector("balanceOf(address)"): func(target common.Address, args []byte) ([]byte, error) {
+				// Balance is the sum of the last bytes of the token and
+				// owner addresses.
+				return common.BigToHash(big.NewInt(int64(target[19]) + int64(args[31]))).Bytes(), nil
+			},
+			selector("paused()"): func(common.Address, []byte) ([]byte, error) {
+				ret, err := revert.Pack("Error", "not implemented")
+				if err != nil {
+					return nil, err
+				}
+				return ret, errors.New("revert")
+			},
+		},
+	}
+
+	calls, err := readMulticalls(strings.NewReader(`[
+	  {"label": "owner", "to": "0x0000000000000000000000000000000000000001", "function": "owner()(address)"},
+	  {"to": "0x0000000000000000000000000000000000000001", "function": "balanceOf(address)(uint256)", "args": ["0x0000000000000000000000000000000000000002"]},
+	  {"to": "0x0000000000000000000000000000000000000003", "function": "balanceOf(address)(uint256)", "args": ["0x0000000000000000000000000000000000000004"]},
+	  {"label": "paused", "to": "0x0000000000000000000000000000000000000001", "function": "paused()(bool)"},
+	  {"label": "raw", "to": "0x0000000000000000000000000000000000000001", "function": "owner()"},
+	  {"label": "missing", "to": "0x0000000000000000000000000000000000000001", "function": "name()(string)"}
+	]`))
+	if err != nil {
+		t.Fatalf("readMulticalls() error %v", err)
+	}
+
+	got, err := aggregateCalls(ctx, mc, mc.addr, calls, 4, nil)
+	if err != nil {
+		t.Fatalf("aggregateCalls() error %v", err)
+	}
+	if mc.batches != 2 {
+		t.Errorf("aggregateCalls(%d calls, batch size 4) made %d batches; want 2", len(calls), mc.batches)
+	}
+
+	one := common.HexToAddress("0x01")
+	want := []*multicallResult{
+		{Label: "owner", To: one, Function: "owner()", Success: true, Values: []string{owner.Hex()}},
+		{To: one, Function: "balanceOf(address)", Args: []string{"0x0000000000000000000000000000000000000002"}, Success: true, Values: []string{"3"}},
+		{To: common.HexToAddress("0x03"), Function: "balanceOf(address)", Args: []string{"0x0000000000000000000000000000000000000004"}, Success: true, Values: []string{"7"}},
+		{Label: "paused", To: one, Function: "paused()", Error: "reverted: not implemented"},
+		{Label: "raw", To: one, Function: "owner()", Success: true, Values: []string{"0x000000000000000000000000000000000000000000000000000000000000000e"}},
+		{Label: "missing", To: one, Function: "name()", Error: "reverted"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("aggregateCalls() diff (-want +got):\n%s", diff)
+	}
+
+	var buf bytes.Buffer
+	if err := writeMulticallTable(&buf, got[:4]); err != nil {
+		t.Fatalf("writeMulticallTable() error %v", err)
+	}
+	wantTable := `label   to                                          function                                               result
+owner   0x0000000000000000000000000000000000000001  owner()                                                0x000000000000000000000000000000000000000E
+-       0x0000000000000000000000000000000000000001  balanceOf(0x0000000000000000000000000000000000000002)  3
+-       0x0000000000000000000000000000000000000003  balanceOf(0x0000000000000000000000000000000000000004)  7
+paused  0x0000000000000000000000000000000000000001  paused()                                               ERROR: reverted: not implemented
+`
+	if diff := cmp.Diff(wantTable, buf.String()); diff != "" {
+		t.Errorf("writeMulticallTable() diff (-want +got):\n%s", diff)
+	}
+
+	t.Run("no Multicall3", func(t *testing.T) {
+		if _, err := aggregateCalls(ctx, mc, common.HexToAddress("0xdead"), calls, 4, nil); err == nil {
+			t.Errorf("aggregateCalls(<wrong address>) got nil error")
+		}
+	})
+}
+
+func TestReadMulticallsErrors(t *testing.T) {
+	for _, in := range []string{
+		`[]`,
+		`{}`,
+		`[{"to": "0x01", "function": "owner()"}]`,
+		`[{"to": "0x0000000000000000000000000000000000000001", "function": "owner("}]`,
+		`[{"to": "0x0000000000000000000000000000000000000001", "function": "balanceOf(address)"}]`,
+	} {
+		if _, err := readMulticalls(strings.NewReader(in)); err == nil {
+			t.Errorf("readMulticalls(%s) got nil error", in)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/eth/merkle"
+)
+
+func init() {
+	const short = "Outputs the Merkle proof that a single address is in an allowlist."
+
+	cmd := &cobra.Command{
+		Use:   "proof",
+		Short: short,
+		Long: short + `
+
+The --list file, or stdin if it is "-", has one address per line, as for ethier merkle --leaves address, and the tree is built identically so the root and proof match those of ethier merkle proofs. Output is a JSON object including the root and the proof for --address; an error is returned if the address isn't in the list.
+
+If --root is set, typically to the value stored on-chain, an error is also returned if it differs from the root of the list, as this indicates that the wrong list was used.`,
+		RunE: proof,
+		Args: cobra.NoArgs,
+	}
+	cmd.Flags().String("list", "-", `File of addresses, one per line; "-" for stdin`)
+	cmd.Flags().String("address", "", "Address for which the proof is output")
+	cmd.Flags().String("root", "", "Expected Merkle root of the list")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// A merkleProofOutput is the JSON output of `ethier proof`.
+type merkleProofOutput struct {
+	Address common.Address `json:"address"`
+	Root    common.Hash    `json:"root"`
+	Leaf    common.Hash    `json:"leaf"`
+	Proof   []common.Hash  `json:"proof"`
+}
+
+// proof implements the `ethier proof` command.
+func proof(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	listPath, err := flags.GetString("list")
+	if err != nil {
+		return err
+	}
+	addrHex, err := flags.GetString("address")
+	if err != nil {
+		return err
+	}
+	rootHex, err := flags.GetString("root")
+	if err != nil {
+		return err
+	}
+
+	addr, err := eth.ParseAddress(addrHex)
+	if err != nil {
+		return fmt.Errorf("--address: %v", err)
+	}
+	var wantRoot *common.Hash
+	if rootHex != "" {
+		buf, err := hexutil.Decode(rootHex)
+		if err != nil {
+			return fmt.Errorf("--root: %v", err)
+		}
+		if n := len(buf); n != common.HashLength {
+			return fmt.Errorf("--root length %d; expecting %d", n, common.HashLength)
+		}
+		root := common.BytesToHash(buf)
+		wantRoot = &root
+	}
+
+	var r io.Reader = os.Stdin
+	if listPath != "-" {
+		f, err := os.Open(listPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	out, err := addressProof(r, addr, wantRoot)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// addressProof builds a Merkle tree from the addresses read from r and returns
+// the proof for addr. If wantRoot is non-nil, the tree's root MUST equal it.
+func addressProof(r io.Reader, addr common.Address, wantRoot *common.Hash) (*merkleProofOutput, error) {
+	_, leaves, err := readMerkleLeaves(r, "address")
+	if err != nil {
+		return nil, err
+	}
+	t, err := merkle.New(leaves)
+	if err != nil {
+		return nil, err
+	}
+	if wantRoot != nil && t.Root() != *wantRoot {
+		return nil, fmt.Errorf("list of %d addresses has root %v; want %v", len(leaves), t.Root(), *wantRoot)
+	}
+
+	p, err := t.ProofForAddress(addr)
+	if err != nil {
+		return nil, fmt.Errorf("address %v not in list of %d addresses", addr, len(leaves))
+	}
+	return &merkleProofOutput{
+		Address: addr,
+		Root:    t.Root(),
+		Leaf:    merkle.AddressLeaf(addr),
+		Proof:   p,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/divergencetech/ethier/eth/merkle"
+)
+
+func TestAddressProof(t *testing.T) {
+	addrs := []common.Address{
+		common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"),
+		common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"),
+		common.HexToAddress("0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"),
+	}
+	const list = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed\n\n0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359\n0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB\n"
+
+	tree, err := merkle.FromAddresses(addrs)
+	if err != nil {
+		t.Fatalf("merkle.FromAddresses() error %v", err)
+	}
+	root := tree.Root()
+
+	for _, addr := range addrs {
+		got, err := addressProof(strings.NewReader(list), addr, &root)
+		if err != nil {
+			t.Fatalf("addressProof(%v) error %v", addr, err)
+		}
+		if got.Root != root {
+			t.Errorf("addressProof(%v) got root %v; want %v", addr, got.Root, root)
+		}
+		if !merkle.Verify(root, merkle.AddressLeaf(addr), got.Proof) {
+			t.Errorf("addressProof(%v) got proof %v not verified against root", addr, got.Proof)
+		}
+	}
+
+	t.Run("not in list", func(t *testing.T) {
+		addr := common.HexToAddress("0x01")
+		if _, err := addressProof(strings.NewReader(list), addr, nil); err == nil {
+			t.Errorf("addressProof(%v) got nil error", addr)
+		}
+	})
+
+	t.Run("wrong root", func(t *testing.T) {
+		wrong := common.Hash{1}
+		if _, err := addressProof(strings.NewReader(list), addrs[0], &wrong); err == nil {
+			t.Errorf("addressProof(%v, root = %v) got nil error", addrs[0], wrong)
+		}
+	})
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Queries ERC2981 royaltyInfo() across a range of token IDs and summarises the results."
+
+	cmd := &cobra.Command{
+		Use:   "royalty --token <address> --ids <ranges> --sale-price <amount>[unit]",
+		Short: short,
+		Long: short + `
+
+Intended for verifying royalty configuration after deployment. Token IDs are comma-separated IDs and inclusive ranges, e.g. 1-1000,1005; --sale-price accepts the same units as ethier units, and amounts without a unit are in wei.
+
+The summary groups tokens by receiver and royalty amount, ordered by decreasing number of tokens, so a single line is expected for collection-wide royalties and any additional lines are per-token overrides. Fees are reported in basis points of the sale price. With --format csv, every token is instead listed individually.
+
+A warning is logged if the contract doesn't report ERC2981 support via ERC165, but royaltyInfo() is queried regardless.`,
+		RunE: royalty,
+		Args: cobra.NoArgs,
+	}
+	addRPCFlag(cmd)
+	cmd.Flags().String("token", "", "Address of the token contract")
+	cmd.Flags().String("ids", "", "Token IDs to query, e.g. 1-1000,1005")
+	cmd.Flags().String("sale-price", "1eth", "Sale price passed to royaltyInfo()")
+	cmd.Flags().String("format", "summary", "Output format: summary or csv")
+	cmd.Flags().IntP("workers", "w", 0, "Number of concurrent calls; 0 = number of CPUs")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// royalty implements the `ethier royalty` command.
+func royalty(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	tokenHex, err := flags.GetString("token")
+	if err != nil {
+		return err
+	}
+	idsStr, err := flags.GetString("ids")
+	if err != nil {
+		return err
+	}
+	priceStr, err := flags.GetString("sale-price")
+	if err != nil {
+		return err
+	}
+	format, err := flags.GetString("format")
+	if err != nil {
+		return err
+	}
+	workers, err := flags.GetInt("workers")
+	if err != nil {
+		return err
+	}
+
+	token, err := eth.ParseAddress(tokenHex)
+	if err != nil {
+		return fmt.Errorf("--token: %v", err)
+	}
+	ids, err := parseIDRanges(idsStr)
+	if err != nil {
+		return fmt.Errorf("--ids: %v", err)
+	}
+	weiStr, err := convertUnits(priceStr, "wei", "wei", 18)
+	if err != nil {
+		return fmt.Errorf("--sale-price: %v", err)
+	}
+	price, _ := new(big.Int).SetString(weiStr, 10)
+	switch format {
+	case "summary", "csv":
+	default:
+		return fmt.Errorf("unsupported --format %q", format)
+	}
+
+	ctx := context.Background()
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if !supportsERC2981(ctx, client, token) {
+		log.Printf("WARNING: %v doesn't report ERC2981 support via supportsInterface(0x2a55205a)", token)
+	}
+
+	infos, err := queryRoyalties(ctx, client, token, ids, price, workers)
+	if err != nil {
+		return err
+	}
+	if format == "csv" {
+		return writeRoyaltyCSV(os.Stdout, infos)
+	}
+	return writeRoyaltySummary(os.Stdout, summariseRoyalties(infos), price)
+}
+
+// maxIDRangeSize limits the number of token IDs parsed by parseIDRanges(), to
+// avoid accidentally querying, e.g., an entire uint256 range.
+const maxIDRangeSize = 1_000_000
+
+// parseIDRanges parses comma-separated token IDs and inclusive ranges of the
+// form first-last, returning the IDs in the order specified.
+func parseIDRanges(s string) ([]*big.Int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, fmt.Errorf("no token IDs")
+	}
+
+	parseID := func(s string) (*big.Int, error) {
+		id, ok := new(big.Int).SetString(strings.TrimSpace(s), 0)
+		if !ok || id.Sign() == -1 {
+			return nil, fmt.Errorf("invalid token ID %q", s)
+		}
+		return id, nil
+	}
+
+	var ids []*big.Int
+	for _, part := range strings.Split(s, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := parseID(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = parseID(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+		if first.Cmp(last) == 1 {
+			return nil, fmt.Errorf("range %q is descending", part)
+		}
+
+		n := new(big.Int).Sub(last, first)
+		if n.Add(n, big.NewInt(int64(len(ids)))).Cmp(big.NewInt(maxIDRangeSize)) != -1 {
+			return nil, fmt.Errorf("more than %d token IDs", maxIDRangeSize)
+		}
+		for id := first; id.Cmp(last) != 1; id = new(big.Int).Add(id, big.NewInt(1)) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// formatIDRanges is the inverse of parseIDRanges(), collapsing consecutive
+// IDs, which MUST be sorted, into ranges.
+func formatIDRanges(ids []*big.Int) string {
+	var parts []string
+	for i := 0; i < len(ids); {
+		j := i
+		for j+1 < len(ids) && new(big.Int).Sub(ids[j+1], ids[j]).Cmp(big.NewInt(1)) == 0 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, ids[i].String())
+		} else {
+			parts = append(parts, fmt.Sprintf("%v-%v", ids[i], ids[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// erc2981InterfaceID is the ERC165 interface ID of ERC2981.
+var erc2981InterfaceID = [4]byte{0x2a, 0x55, 0x20, 0x5a}
+
+// supportsERC2981 returns whether the token reports support for ERC2981 via
+// ERC165. Contracts that don't implement ERC165, including those that revert,
+// are reported as unsupported; other errors will resurface when calling
+// royaltyInfo().
+func supportsERC2981(ctx context.Context, caller ethereum.ContractCaller, token common.Address) bool {
+	vals, err := callContract(ctx, caller, token, "supportsInterface(bytes4)(bool)", erc2981InterfaceID)
+	if err != nil || vals == nil {
+		return false
+	}
+	return vals[0].(bool)
+}
+
+// A royaltyInfo is the return value of royaltyInfo() for a single token.
+type royaltyInfo struct {
+	id       *big.Int
+	receiver common.Address
+	amount   *big.Int
+}
+
+// queryRoyalties calls royaltyInfo() for every token ID at the sale price,
+// concurrently across the specified number of workers. If workers <= 0,
+// runtime.NumCPU() workers are used. The returned values are in the same order
+// as ids.
+func queryRoyalties(ctx context.Context, caller ethereum.ContractCaller, token common.Address, ids []*big.Int, price *big.Int, workers int) ([]*royaltyInfo, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if n := len(ids); workers > n {
+		workers = n
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	infos := make([]*royaltyInfo, len(ids))
+	idx := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				vals, err := callContract(ctx, caller, token, "royaltyInfo(uint256,uint256)(address,uint256)", ids[i], price)
+				if err != nil {
+					fail(fmt.Errorf("token %v: %v", ids[i], err))
+					return
+				}
+				if vals == nil {
+					fail(fmt.Errorf("token %v: royaltyInfo() returned no data; is %v an ERC2981 contract?", ids[i], token))
+					return
+				}
+				infos[i] = &royaltyInfo{
+					id:       ids[i],
+					receiver: vals[0].(common.Address),
+					amount:   vals[1].(*big.Int),
+				}
+			}
+		}()
+	}
+
+Feed:
+	for i := range ids {
+		select {
+		case idx <- i:
+		case <-ctx.Done():
+			break Feed
+		}
+	}
+	close(idx)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// A royaltyGroup is the set of tokens sharing the same receiver and royalty
+// amount.
+type royaltyGroup struct {
+	receiver common.Address
+	amount   *big.Int
+	ids      []*big.Int
+}
+
+// summariseRoyalties groups the infos by receiver and amount, sorted by
+// decreasing number of tokens, then by receiver and amount. IDs within each
+// group are sorted.
+func summariseRoyalties(infos []*royaltyInfo) []*royaltyGroup {
+	byKey := make(map[string]*royaltyGroup)
+	var groups []*royaltyGroup
+	for _, info := range infos {
+		key := fmt.Sprintf("%v:%v", info.receiver, info.amount)
+		g, ok := byKey[key]
+		if !ok {
+			g = &royaltyGroup{
+				receiver: info.receiver,
+				amount:   info.amount,
+			}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.ids = append(g.ids, info.id)
+	}
+
+	for _, g := range groups {
+		ids := g.ids
+		sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) == -1 })
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		gi, gj := groups[i], groups[j]
+		if len(gi.ids) != len(gj.ids) {
+			return len(gi.ids) > len(gj.ids)
+		}
+		if c := bytes.Compare(gi.receiver[:], gj.receiver[:]); c != 0 {
+			return c == -1
+		}
+		return gi.amount.Cmp(gj.amount) == -1
+	})
+	return groups
+}
+
+// royaltyBasisPoints returns the royalty amount in basis points of the sale
+// price, with two decimal places if not an integer.
+func royaltyBasisPoints(amount, price *big.Int) string {
+	if price.Sign() == 0 {
+		return "-"
+	}
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(amount, big.NewInt(10000)), price)
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	return r.FloatString(2)
+}
+
+// writeRoyaltySummary writes the groups as an aligned table.
+func writeRoyaltySummary(w io.Writer, groups []*royaltyGroup, price *big.Int) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "receiver\tamount (wei)\tbps\ttokens\tids\n")
+	for _, g := range groups {
+		fmt.Fprintf(tw, "%v\t%v\t%s\t%d\t%s\n", g.receiver.Hex(), g.amount, royaltyBasisPoints(g.amount, price), len(g.ids), formatIDRanges(g.ids))
+	}
+	return tw.Flush()
+}
+
+// writeRoyaltyCSV writes one row per token, in the order queried.
+func writeRoyaltyCSV(w io.Writer, infos []*royaltyInfo) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"id", "receiver", "amount"}); err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if err := out.Write([]string{info.id.String(), info.receiver.Hex(), info.amount.String()}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
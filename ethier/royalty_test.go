@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseIDRanges(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{s: "1-5", want: "1-5"},
+		{s: "7,1-3, 5", want: "7,1-3,5"},
+		{s: "0x10-0x11", want: "16-17"},
+		{s: "42", want: "42"},
+		{s: "", wantErr: true},
+		{s: "5-1", wantErr: true},
+		{s: "-1", wantErr: true},
+		{s: "a-b", wantErr: true},
+		{s: "0-1000000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseIDRanges(tt.s)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("parseIDRanges(%q) got err %v; want error = %t", tt.s, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		// Unsorted input is only collapsed where consecutive, which is
+		// sufficient to test the round trip.
+		if s := formatIDRanges(got); s != tt.want {
+			t.Errorf("formatIDRanges(parseIDRanges(%q)) got %q; want %q", tt.s, s, tt.want)
+		}
+	}
+}
+
+// fakeRoyalties is an ethereum.ContractCaller implementing ERC2981 with a
+// default receiver and fee, in basis points, and per-token overrides.
+type fakeRoyalties struct {
+	t         *testing.T
+	receiver  common.Address
+	bps       int64
+	overrides map[int64]common.Address
+}
+
+func (f *fakeRoyalties) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	m, err := parseFunctionSignature("royaltyInfo(uint256,uint256)(address,uint256)")
+	if err != nil {
+		f.t.Fatalf("parseFunctionSignature() error %v", err)
+	}
+	if !bytes.HasPrefix(msg.Data, m.ID) {
+		return nil, nil
+	}
+	args, err := m.Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		f.t.Fatalf("royaltyInfo().Inputs.Unpack() error %v", err)
+	}
+	id, price := args[0].(*big.Int), args[1].(*big.Int)
+
+	receiver, bps := f.receiver, f.bps
+	if r, ok := f.overrides[id.Int64()]; ok {
+		receiver, bps = r, 2*bps
+	}
+	amount := new(big.Int).Mul(price, big.NewInt(bps))
+	return m.Outputs.Pack(receiver, amount.Div(amount, big.NewInt(10000)))
+}
+
+func TestRoyalties(t *testing.T) {
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	fake := &fakeRoyalties{
+		t:        t,
+		receiver: alice,
+		bps:      500,
+		overrides: map[int64]common.Address{
+			3: bob,
+			4: bob,
+			7: bob,
+		},
+	}
+	ctx := context.Background()
+	token := common.HexToAddress("0x01")
+	price := big.NewInt(1e18)
+
+	if supportsERC2981(ctx, fake, token) {
+		t.Errorf("supportsERC2981() got true for contract without supportsInterface()")
+	}
+
+	ids, err := parseIDRanges("1-10")
+	if err != nil {
+		t.Fatalf("parseIDRanges() error %v", err)
+	}
+	infos, err := queryRoyalties(ctx, fake, token, ids, price, 3)
+	if err != nil {
+		t.Fatalf("queryRoyalties() error %v", err)
+	}
+	for i, info := range infos {
+		if info.id.Cmp(ids[i]) != 0 {
+			t.Errorf("queryRoyalties()[%d] got ID %v; want %v", i, info.id, ids[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeRoyaltySummary(&buf, summariseRoyalties(infos), price); err != nil {
+		t.Fatalf("writeRoyaltySummary() error %v", err)
+	}
+	want := `receiver                                    amount (wei)        bps   tokens  ids
+0x00000000000000000000000000000000000000A1  50000000000000000   500   7       1-2,5-6,8-10
+0x00000000000000000000000000000000000000B0  100000000000000000  1000  3       3-4,7
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("writeRoyaltySummary() diff (-want +got):\n%s", diff)
+	}
+
+	t.Run("not ERC2981", func(t *testing.T) {
+		// Neither function is implemented so return data is empty.
+		if _, err := queryRoyalties(ctx, &fakeENS{t: t}, token, ids, price, 3); err == nil {
+			t.Errorf("queryRoyalties() on non-ERC2981 contract got nil error")
+		}
+	})
+}
+
+func TestRoyaltyBasisPoints(t *testing.T) {
+	tests := []struct {
+		amount, price int64
+		want          string
+	}{
+		{amount: 75, price: 1000, want: "750"},
+		{amount: 1, price: 3, want: "3333.33"},
+		{amount: 1, price: 0, want: "-"},
+	}
+
+	for _, tt := range tests {
+		if got := royaltyBasisPoints(big.NewInt(tt.amount), big.NewInt(tt.price)); got != tt.want {
+			t.Errorf("royaltyBasisPoints(%d, %d) got %q; want %q", tt.amount, tt.price, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
+)
+
+// rpcFlag is the flag, added by addRPCFlag(), specifying the Ethereum node to
+// which commands connect.
+const rpcFlag = "rpc"
+
+// addRPCFlag adds the --rpc flag to the command, defaulting to the
+// ETH_RPC_URL environment variable as used by other Ethereum tooling.
+func addRPCFlag(cmd *cobra.Command) {
+	cmd.Flags().String(rpcFlag, os.Getenv("ETH_RPC_URL"), "URL of the Ethereum JSON-RPC endpoint; defaults to $ETH_RPC_URL")
+}
+
+// dialFromFlags connects to the node specified by the --rpc flag.
+func dialFromFlags(ctx context.Context, cmd *cobra.Command) (*ethclient.Client, error) {
+	client, err := rpcClientFromFlags(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
+
+// rpcClientFromFlags connects to the node specified by the --rpc flag,
+// returning the raw JSON-RPC client for methods, like those in the debug
+// namespace, that aren't exposed by an ethclient.Client.
+func rpcClientFromFlags(ctx context.Context, cmd *cobra.Command) (*rpc.Client, error) {
+	url, err := cmd.Flags().GetString(rpcFlag)
+	if err != nil {
+		return nil, err
+	}
+	if url == "" {
+		return nil, errors.New("--rpc or $ETH_RPC_URL required")
+	}
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("dial %q: %v", url, err)
+	}
+	return client, nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	const short = "Computes function selectors and event topics, or looks up signatures from them."
+
+	cmd := &cobra.Command{
+		Use:   "selector <signature|selector>...",
+		Short: short,
+		Long: short + `
+
+For each signature, e.g. 'transferFrom(address,address,uint256)', the 4-byte selector used by functions and errors, the 32-byte topic used by events, and the canonical signature are printed, separated by tabs. Parameter names and the indexed keyword are ignored.
+
+With --reverse, each argument is instead a 4- or 32-byte hex value and all matching signatures from a bundled database of common standards (ERC20, ERC721, ERC1155, Ownable, AccessControl, etc.) are printed. Additional signatures can be provided with --signatures.`,
+		RunE: selector,
+		Args: cobra.MinimumNArgs(1),
+	}
+	cmd.Flags().Bool("reverse", false, "Look up signatures matching 4-byte selectors or 32-byte topics")
+	cmd.Flags().String("signatures", "", "File of additional signatures for --reverse, one per line")
+
+	rootCmd.AddCommand(cmd)
+}
+
+//go:embed signatures.txt
+var bundledSignatures string
+
+// selector implements the `ethier selector` command.
+func selector(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	reverse, err := flags.GetBool("reverse")
+	if err != nil {
+		return err
+	}
+	extra, err := flags.GetString("signatures")
+	if err != nil {
+		return err
+	}
+
+	if !reverse {
+		for _, a := range args {
+			sig, err := canonicalSignature(a)
+			if err != nil {
+				return err
+			}
+			id := crypto.Keccak256(sig)
+			fmt.Printf("%#x\t%#x\t%s\n", id[:4], id, sig)
+		}
+		return nil
+	}
+
+	db, err := loadSignatureDB(extra)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range args {
+		id, err := hexutil.Decode(a)
+		if err != nil {
+			return fmt.Errorf("selector %q: %v", a, err)
+		}
+		sigs, err := db.lookup(id)
+		if err != nil {
+			return err
+		}
+		if len(sigs) == 0 {
+			fmt.Printf("%s\t<unknown>\n", a)
+		}
+		for _, s := range sigs {
+			fmt.Printf("%s\t%s\n", a, s)
+		}
+	}
+	return nil
+}
+
+// loadSignatureDB returns a signatureDB with the bundled signatures and, if
+// extra is non-empty, those in the file at the path.
+func loadSignatureDB(extra string) (*signatureDB, error) {
+	db := newSignatureDB()
+	if err := db.read(strings.NewReader(bundledSignatures)); err != nil {
+		return nil, fmt.Errorf("bundled signatures: %v", err)
+	}
+	if extra == "" {
+		return db, nil
+	}
+	f, err := os.Open(extra)
+	if err != nil {
+		return nil, fmt.Errorf("open --signatures: %v", err)
+	}
+	defer f.Close()
+	if err := db.read(f); err != nil {
+		return nil, fmt.Errorf("--signatures: %v", err)
+	}
+	return db, nil
+}
+
+// canonicalSignature returns the form of a human-readable signature that is
+// hashed to compute selectors and topics, e.g. Transfer(address indexed from,
+// address indexed to, uint256 value) becomes
+// Transfer(address,address,uint256).
+func canonicalSignature(sig string) ([]byte, error) {
+	fields := strings.Fields(sig)
+	kept := fields[:0]
+	for _, f := range fields {
+		if f != "indexed" {
+			kept = append(kept, f)
+		}
+	}
+
+	m, err := parseFunctionSignature(strings.Join(kept, " "))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(m.Sig), nil
+}
+
+// A signatureDB maps 4-byte selectors and 32-byte topics to the canonical
+// signatures from which they are derived.
+type signatureDB struct {
+	bySelector map[[4]byte][]string
+	byTopic    map[[32]byte][]string
+	seen       map[string]bool
+}
+
+func newSignatureDB() *signatureDB {
+	return &signatureDB{
+		bySelector: make(map[[4]byte][]string),
+		byTopic:    make(map[[32]byte][]string),
+		seen:       make(map[string]bool),
+	}
+}
+
+// read adds signatures read from r, one per line, to the database. Blank lines
+// and those beginning with # are ignored.
+func (db *signatureDB) read(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		sig, err := canonicalSignature(l)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		db.add(string(sig))
+	}
+	return s.Err()
+}
+
+// add adds the canonical signature to the database, ignoring duplicates.
+func (db *signatureDB) add(sig string) {
+	if db.seen[sig] {
+		return
+	}
+	db.seen[sig] = true
+
+	var topic [32]byte
+	copy(topic[:], crypto.Keccak256([]byte(sig)))
+	var sel [4]byte
+	copy(sel[:], topic[:4])
+
+	db.bySelector[sel] = append(db.bySelector[sel], sig)
+	db.byTopic[topic] = append(db.byTopic[topic], sig)
+}
+
+// lookup returns all signatures, sorted, that match id, which MUST be either a
+// 4-byte selector or a 32-byte topic.
+func (db *signatureDB) lookup(id []byte) ([]string, error) {
+	var sigs []string
+	switch len(id) {
+	case 4:
+		var sel [4]byte
+		copy(sel[:], id)
+		sigs = db.bySelector[sel]
+	case 32:
+		var topic [32]byte
+		copy(topic[:], id)
+		sigs = db.byTopic[topic]
+	default:
+		return nil, fmt.Errorf("%#x is %d bytes; must be a 4-byte selector or 32-byte topic", id, len(id))
+	}
+
+	if len(sigs) == 0 {
+		return nil, nil
+	}
+	out := append([]string{}, sigs...)
+	sort.Strings(out)
+	return out, nil
+}
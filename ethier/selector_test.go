@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCanonicalSignature(t *testing.T) {
+	tests := []struct {
+		sig, want string
+	}{
+		{
+			sig:  "transferFrom(address,address,uint256)",
+			want: "transferFrom(address,address,uint256)",
+		},
+		{
+			sig:  "Transfer(address indexed from, address indexed to, uint256 value)",
+			want: "Transfer(address,address,uint256)",
+		},
+		{
+			sig:  "balanceOf(address owner) returns (uint256)",
+			want: "balanceOf(address)",
+		},
+		{
+			sig:  "aggregate((address target, bytes data)[] calls)",
+			want: "aggregate((address,bytes)[])",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := canonicalSignature(tt.sig)
+		if err != nil {
+			t.Errorf("canonicalSignature(%q) error %v", tt.sig, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("canonicalSignature(%q) got %q; want %q", tt.sig, got, tt.want)
+		}
+	}
+}
+
+func TestSignatureDB(t *testing.T) {
+	db := newSignatureDB()
+	if err := db.read(strings.NewReader(bundledSignatures)); err != nil {
+		t.Fatalf("read(<bundled signatures>) error %v", err)
+	}
+	if err := db.read(strings.NewReader("# custom\nfoo(uint256 x)\ntransfer(address,uint256)\n")); err != nil {
+		t.Fatalf("read(<custom signatures>) error %v", err)
+	}
+
+	tests := []struct {
+		id      string
+		want    []string
+		wantErr bool
+	}{
+		{
+			id:   "0xa9059cbb",
+			want: []string{"transfer(address,uint256)"},
+		},
+		{
+			id:   "0x23b872dd",
+			want: []string{"transferFrom(address,address,uint256)"},
+		},
+		{
+			id:   "0x08c379a0",
+			want: []string{"Error(string)"},
+		},
+		{
+			id:   "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+			want: []string{"Transfer(address,address,uint256)"},
+		},
+		{
+			id:   "0x2fbebd38",
+			want: []string{"foo(uint256)"},
+		},
+		{
+			id: "0x00000000",
+		},
+		{
+			id:      "0xa9059c",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := db.lookup(hexutil.MustDecode(tt.id))
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("lookup(%s) got err %v; want error = %t", tt.id, err, tt.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("lookup(%s) diff (-want +got):\n%s", tt.id, diff)
+		}
+	}
+}
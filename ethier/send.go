@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Signs and sends a transaction calling a contract function, or transferring ETH."
+
+	cmd := &cobra.Command{
+		Use:   "send <address> [<signature> [args...]]",
+		Short: short,
+		Long: short + `
+
+The signature and arguments are as for ethier call, e.g. 'mint(address,uint256)' 0x… 3; if no signature is provided, the transaction only transfers --value. Transactions are EIP-1559 typed transactions signed by any of the key sources, including KMS and remote signers such as clef, which supports hardware wallets.
+
+Unless specified, the gas limit is estimated, the nonce is the account's pending nonce, the priority fee is suggested by the node, and the maximum fee is --base-fee-multiplier times the current base fee plus the priority fee.`,
+		RunE: send,
+		Args: cobra.MinimumNArgs(1),
+	}
+	addRPCFlag(cmd)
+	addSignerFlags(cmd)
+	cmd.Flags().String("value", "0", "Value to send, in wei unless it has a unit suffix, e.g. 0.1eth or 20gwei")
+	addFeeFlags(cmd)
+	cmd.Flags().Uint64("gas-limit", 0, "Gas limit; 0 = estimate")
+	cmd.Flags().Int64("nonce", -1, "Nonce override; -1 = pending nonce of the account")
+	cmd.Flags().Uint64("confirm", 1, "Number of confirmations to wait for; 0 = don't wait for the transaction to be mined")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// txParams are the parameters of a transaction sent by `ethier send`. Nil or
+// negative values are determined automatically.
+type txParams struct {
+	to          common.Address
+	data        []byte
+	value       *big.Int
+	maxFee      *big.Int
+	priorityFee *big.Int
+	gasLimit    uint64
+	nonce       int64
+	// baseFeeMultiplier is used to compute maxFee if it's nil, with 0 treated
+	// as the default of 2; see sendTx().
+	baseFeeMultiplier uint64
+}
+
+// send implements the `ethier send` command.
+func send(cmd *cobra.Command, args []string) error {
+	p, err := txParamsFromFlags(cmd, args)
+	if err != nil {
+		return err
+	}
+	confirm, err := cmd.Flags().GetUint64("confirm")
+	if err != nil {
+		return err
+	}
+
+	signer, err := existingSignerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("get chain ID: %v", err)
+	}
+
+	tx, err := sendTx(ctx, client, signer, chainID, p)
+	if err != nil {
+		return err
+	}
+	log.Printf("Sent transaction from %v with nonce %d", signer.Address(), tx.Nonce())
+	fmt.Println(tx.Hash().Hex())
+
+	if confirm == 0 {
+		return nil
+	}
+	r, err := waitConfirmed(ctx, client, tx, confirm, 4*time.Second)
+	if err != nil {
+		return err
+	}
+	log.Printf("Mined in block %v with %d confirmation(s); gas used %d", r.BlockNumber, confirm, r.GasUsed)
+	if r.Status != types.ReceiptStatusSuccessful {
+		return errors.New("transaction reverted")
+	}
+	return nil
+}
+
+// txParamsFromFlags parses the `ethier send` arguments and flags.
+func txParamsFromFlags(cmd *cobra.Command, args []string) (*txParams, error) {
+	flags := cmd.Flags()
+	p := new(txParams)
+
+	var err error
+	if p.to, err = eth.ParseAddress(args[0]); err != nil {
+		return nil, fmt.Errorf("address: %v", err)
+	}
+	if len(args) > 1 {
+		if _, p.data, err = packCall(args[1], args[2:]); err != nil {
+			return nil, err
+		}
+	}
+
+	value, err := flags.GetString("value")
+	if err != nil {
+		return nil, err
+	}
+	if p.value, err = parseValue(value); err != nil {
+		return nil, fmt.Errorf("--value: %v", err)
+	}
+	if err := feesFromFlags(cmd, p); err != nil {
+		return nil, err
+	}
+
+	if p.gasLimit, err = flags.GetUint64("gas-limit"); err != nil {
+		return nil, err
+	}
+	if p.nonce, err = flags.GetInt64("nonce"); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// addFeeFlags adds flags to the command for overriding the fees of
+// transactions, which are then parsed with feesFromFlags().
+func addFeeFlags(cmd *cobra.Command) {
+	cmd.Flags().String("max-fee", "", "Maximum fee per gas, in gwei")
+	cmd.Flags().String("priority-fee", "", "Maximum priority fee (tip) per gas, in gwei")
+	cmd.Flags().Uint64("base-fee-multiplier", 2, "Multiple of the current base fee used to compute the maximum fee if --max-fee isn't set")
+}
+
+// feesFromFlags populates the fee-related fields of p from the flags added
+// with addFeeFlags().
+func feesFromFlags(cmd *cobra.Command, p *txParams) error {
+	flags := cmd.Flags()
+	for _, f := range []struct {
+		name string
+		dst  **big.Int
+	}{
+		{"max-fee", &p.maxFee},
+		{"priority-fee", &p.priorityFee},
+	} {
+		s, err := flags.GetString(f.name)
+		if err != nil {
+			return err
+		}
+		if s == "" {
+			continue
+		}
+		if *f.dst, err = parseGwei(s); err != nil {
+			return fmt.Errorf("--%s: %v", f.name, err)
+		}
+	}
+
+	var err error
+	p.baseFeeMultiplier, err = flags.GetUint64("base-fee-multiplier")
+	return err
+}
+
+// parseGwei parses a non-negative decimal number of gwei, e.g. 1.5, returning
+// the equivalent number of wei.
+func parseGwei(s string) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() == -1 {
+		return nil, fmt.Errorf("invalid gwei amount %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(params.GWei))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%q gwei is not a whole number of wei", s)
+	}
+	return r.Num(), nil
+}
+
+// parseValue parses a non-negative amount of ETH, in wei unless it has a unit
+// suffix as accepted by `ethier units`, returning the equivalent number of wei.
+func parseValue(s string) (*big.Int, error) {
+	if _, unit := splitUnit(s, "wei"); unit == tokenUnit {
+		return nil, fmt.Errorf("invalid unit %q for ETH value", unit)
+	}
+	wei, err := convertUnits(s, "wei", "wei", 0)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := new(big.Int).SetString(wei, 10)
+	if !ok || v.Sign() == -1 {
+		return nil, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
+
+// sendTx populates any missing parameters, signs the resulting dynamic-fee
+// transaction with the signer, and sends it.
+func sendTx(ctx context.Context, client bind.ContractTransactor, signer eth.SignerBackend, chainID *big.Int, p *txParams) (*types.Transaction, error) {
+	from := signer.Address()
+
+	nonce := uint64(p.nonce)
+	if p.nonce < 0 {
+		n, err := client.PendingNonceAt(ctx, from)
+		if err != nil {
+			return nil, fmt.Errorf("get nonce: %v", err)
+		}
+		nonce = n
+	}
+
+	tip, maxFee, err := txFees(ctx, client, p)
+	if err != nil {
+		return nil, err
+	}
+
+	gas := p.gasLimit
+	if gas == 0 {
+		g, err := client.EstimateGas(ctx, ethereum.CallMsg{
+			From:      from,
+			To:        &p.to,
+			GasFeeCap: maxFee,
+			GasTipCap: tip,
+			Value:     p.value,
+			Data:      p.data,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("estimate gas: %v", err)
+		}
+		gas = g
+	}
+
+	to := p.to
+	tx, err := eth.SignTx(signer, types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: maxFee,
+		Gas:       gas,
+		To:        &to,
+		Value:     p.value,
+		Data:      p.data,
+	}), chainID)
+	if err != nil {
+		return nil, fmt.Errorf("sign transaction: %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("send transaction: %v", err)
+	}
+	return tx, nil
+}
+
+// txFees returns the priority and maximum fees per gas of p, determining
+// either of them from the client if unset.
+func txFees(ctx context.Context, client bind.ContractTransactor, p *txParams) (tip, maxFee *big.Int, _ error) {
+	tip = p.priorityFee
+	if tip == nil {
+		t, err := client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("suggest priority fee: %v", err)
+		}
+		tip = t
+	}
+	maxFee = p.maxFee
+	if maxFee == nil {
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get latest header: %v", err)
+		}
+		if head.BaseFee == nil {
+			return nil, nil, errors.New("chain doesn't support EIP-1559")
+		}
+		mul := p.baseFeeMultiplier
+		if mul == 0 {
+			mul = 2
+		}
+		maxFee = new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, new(big.Int).SetUint64(mul)))
+	}
+	if maxFee.Cmp(tip) == -1 {
+		return nil, nil, fmt.Errorf("max fee %v < priority fee %v", maxFee, tip)
+	}
+	return tip, maxFee, nil
+}
+
+// A confirmationBackend is the subset of a client required by
+// waitConfirmed().
+type confirmationBackend interface {
+	bind.DeployBackend
+	HeaderByNumber(context.Context, *big.Int) (*types.Header, error)
+}
+
+// waitConfirmed waits until tx is mined and then until the chain head is
+// confirmations-1 blocks beyond the block in which it was mined, polling at
+// the specified interval.
+func waitConfirmed(ctx context.Context, client confirmationBackend, tx *types.Transaction, confirmations uint64, poll time.Duration) (*types.Receipt, error) {
+	r, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		return nil, fmt.Errorf("wait for transaction to be mined: %v", err)
+	}
+
+	want := new(big.Int).Add(r.BlockNumber, new(big.Int).SetUint64(confirmations-1))
+	for {
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get latest header: %v", err)
+		}
+		if head.Number.Cmp(want) >= 0 {
+			return r, nil
+		}
+		select {
+		case <-time.After(poll):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
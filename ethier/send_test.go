@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+)
+
+func TestParseGwei(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    int64
+		wantErr bool
+	}{
+		{s: "1", want: params.GWei},
+		{s: "1.5", want: 1_500_000_000},
+		{s: "0.000000001", want: 1},
+		{s: "0.0000000001", wantErr: true},
+		{s: "-1", wantErr: true},
+		{s: "lots", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseGwei(tt.s)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("parseGwei(%q) got err %v; want error = %t", tt.s, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("parseGwei(%q) got %v; want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    *big.Int
+		wantErr bool
+	}{
+		{s: "0", want: big.NewInt(0)},
+		{s: "42", want: big.NewInt(42)},
+		{s: "42wei", want: big.NewInt(42)},
+		{s: "20gwei", want: big.NewInt(20 * params.GWei)},
+		{s: "10eth", want: eth.Ether(10)},
+		{s: "0.5 ether", want: new(big.Int).Div(eth.Ether(1), big.NewInt(2))},
+		{s: "1.5wei", wantErr: true},
+		{s: "-1eth", wantErr: true},
+		{s: "1token", wantErr: true},
+		{s: "lots", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseValue(tt.s)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("parseValue(%q) got err %v; want error = %t", tt.s, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Cmp(tt.want) != 0 {
+			t.Errorf("parseValue(%q) got %v; want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestSendTx(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, 1)
+	chainID := big.NewInt(1337)
+
+	signer := newFundedSigner(ctx, t, sim)
+
+	recipient := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	tx, err := sendTx(ctx, sim, signer, chainID, &txParams{
+		to:    recipient,
+		data:  []byte{1, 2, 3},
+		value: eth.Ether(1),
+		nonce: -1,
+	})
+	if err != nil {
+		t.Fatalf("sendTx() error %v", err)
+	}
+	if got, want := tx.Type(), uint8(types.DynamicFeeTxType); got != want {
+		t.Errorf("sendTx() transaction type got %d; want %d", got, want)
+	}
+
+	r, err := waitConfirmed(ctx, sim, tx, 1, time.Millisecond)
+	if err != nil {
+		t.Fatalf("waitConfirmed() error %v", err)
+	}
+	if r.Status != types.ReceiptStatusSuccessful {
+		t.Errorf("receipt status got %d; want success", r.Status)
+	}
+	if got := sim.BalanceOf(ctx, t, recipient); got.Cmp(eth.Ether(1)) != 0 {
+		t.Errorf("recipient balance got %v; want 1 ETH", got)
+	}
+
+	t.Run("overrides", func(t *testing.T) {
+		tx, err := sendTx(ctx, sim, signer, chainID, &txParams{
+			to:          recipient,
+			value:       big.NewInt(0),
+			maxFee:      big.NewInt(50 * params.GWei),
+			priorityFee: big.NewInt(2 * params.GWei),
+			gasLimit:    30000,
+			nonce:       1,
+		})
+		if err != nil {
+			t.Fatalf("sendTx() error %v", err)
+		}
+		if got, want := tx.Nonce(), uint64(1); got != want {
+			t.Errorf("nonce got %d; want %d", got, want)
+		}
+		if got, want := tx.Gas(), uint64(30000); got != want {
+			t.Errorf("gas limit got %d; want %d", got, want)
+		}
+		if got, want := tx.GasFeeCap(), big.NewInt(50*params.GWei); got.Cmp(want) != 0 {
+			t.Errorf("max fee got %v; want %v", got, want)
+		}
+
+		// Wait for multiple confirmations while blocks are mined.
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 2; i++ {
+				time.Sleep(10 * time.Millisecond)
+				sim.Commit()
+			}
+		}()
+		if _, err := waitConfirmed(ctx, sim, tx, 3, time.Millisecond); err != nil {
+			t.Errorf("waitConfirmed(3) error %v", err)
+		}
+		<-done
+	})
+
+	if _, err := sendTx(ctx, sim, signer, chainID, &txParams{
+		to:          recipient,
+		value:       big.NewInt(0),
+		maxFee:      big.NewInt(1),
+		priorityFee: big.NewInt(2),
+		nonce:       -1,
+	}); err == nil {
+		t.Error("sendTx() with max fee < priority fee got nil error; want error")
+	}
+}
+
+// newFundedSigner returns a new Signer with 10 ETH from the simulated
+// backend's first account.
+func newFundedSigner(ctx context.Context, t *testing.T, sim *ethtest.SimulatedBackend) *eth.Signer {
+	t.Helper()
+
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	acc := sim.Acc(0)
+	to := signer.Address()
+	nonce, err := sim.PendingNonceAt(ctx, acc.From)
+	if err != nil {
+		t.Fatalf("PendingNonceAt() error %v", err)
+	}
+	tx, err := acc.Signer(acc.From, types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: big.NewInt(10 * params.GWei),
+		Gas:      21000,
+		To:       &to,
+		Value:    eth.Ether(10),
+	}))
+	if err != nil {
+		t.Fatalf("sign funding transaction: %v", err)
+	}
+	if err := sim.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("send funding transaction: %v", err)
+	}
+	return signer
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+// signCmd is the parent of all `ethier sign` subcommands.
+var signCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Signs data read from stdin, e.g. for allowlists verified by SignatureChecker",
+}
+
+func init() {
+	rootCmd.AddCommand(signCmd)
+}
+
+// A signedEntry is a single signature output by `ethier sign`.
+type signedEntry struct {
+	Address common.Address `json:"address"`
+	// Fields are additional input values, e.g. CSV columns, keyed by name.
+	Fields    map[string]string `json:"fields,omitempty"`
+	Signature hexutil.Bytes     `json:"signature"`
+}
+
+// A signedList is the output of `ethier sign`, which includes the signer's
+// address so that signatures can be verified by `ethier verify`.
+type signedList struct {
+	Signer common.Address `json:"signer"`
+	// Packed are the Fields, as name:type, included in each message after
+	// the address, and Hashed is true if the keccak256 hash of the packed
+	// values was signed instead of the values themselves.
+	Packed  []string      `json:"packed,omitempty"`
+	Hashed  bool          `json:"hashed,omitempty"`
+	Entries []signedEntry `json:"entries"`
+}
@@ -0,0 +1,578 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Reads addresses from stdin and outputs EIP-191 personal signatures of each."
+
+	cmd := &cobra.Command{
+		Use:   "addresses",
+		Short: short,
+		Long: short + `
+
+By default, input is one address per line and the signed message is the address alone, compatible with SignatureChecker.requireValidSignature(signers, address, signature). Output is JSON, with signatures in compact (EIP-2098) form.
+
+With --format csv, input MUST have a header row including an "address" column. Other columns, e.g. an allowance or tier, are echoed in the output, which defaults to CSV with an additional "signature" column. Columns listed in --packed-columns, with their Solidity types, are also included in the signed message as abi.encodePacked(address, <packed columns in order>).
+
+The --with-nonce, --allowance-column and --expiry flags instead sign keccak256(abi.encodePacked(address, nonce, allowance, expiry)), as expected by common claim contracts, with each of the uint256 values only included if its flag is set. Nonces are taken from a "nonce" column if one exists, otherwise they are generated randomly and output alongside the expiry.
+
+Specify an existing key with one of the key flags so that signatures are reproducible and can be verified against a known signer address; otherwise a new key is generated.
+
+` + outFormatHelp + `
+
+` + signRunHelp + ` When resuming, use an absolute --expiry as durations are relative to the start of each run. With --stream, --out is written to directly, without checkpointing.`,
+		RunE: signAddresses,
+		Args: cobra.NoArgs,
+	}
+	addSignerFlags(cmd)
+	addOutFormatFlags(cmd)
+	addSignRunFlags(cmd)
+	cmd.Flags().String("format", "text", "Input format: text (one address per line) or csv")
+	cmd.Flags().StringSlice("packed-columns", nil, "CSV columns, as name:type, to include in the signed message; e.g. allowance:uint256,tier:uint8")
+	cmd.Flags().Bool("with-nonce", false, "Include a uint256 nonce in the hashed message")
+	cmd.Flags().String("allowance-column", "", "CSV column to include in the hashed message as a uint256 allowance")
+	cmd.Flags().Bool("stream", false, "Sign and write each address as it is read, with JSON output as one entry per line (JSONL) after a header line recording the signer, keeping memory usage constant for very large inputs")
+	cmd.Flags().String("expiry", "", "Expiry to include in the hashed message as a uint256 Unix timestamp; either a timestamp, RFC 3339 time, or duration from now, e.g. 72h")
+
+	signCmd.AddCommand(cmd)
+}
+
+// A signRow is a single input to `ethier sign addresses`.
+type signRow struct {
+	// line is the 1-indexed line (or CSV record) of the input.
+	line    int
+	address common.Address
+	// record is the full CSV record, including the address, plus any
+	// generated values.
+	record []string
+}
+
+// A packedColumn is a CSV column included in the signed message.
+type packedColumn struct {
+	name, typ string
+	idx       int
+}
+
+// A messageSpec describes how a signRow is converted to the signed message.
+type messageSpec struct {
+	packed []packedColumn
+	// hash, if true, results in the keccak256 hash of the packed values being
+	// signed instead of the values themselves.
+	hash bool
+}
+
+// message returns the message to be signed for the row.
+func (m *messageSpec) message(r signRow) ([]byte, error) {
+	types := []string{"address"}
+	values := []interface{}{r.address}
+
+	for _, c := range m.packed {
+		v, err := parsePackedArg(c.typ, r.record[c.idx])
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %v", c.name, err)
+		}
+		types = append(types, c.typ)
+		values = append(values, v)
+	}
+
+	buf, err := eth.EncodePacked(types, values...)
+	if err != nil || !m.hash {
+		return buf, err
+	}
+	return crypto.Keccak256(buf), nil
+}
+
+// signAddresses implements the `ethier sign addresses` command.
+func signAddresses(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	format, err := flags.GetString("format")
+	if err != nil {
+		return err
+	}
+	packed, err := flags.GetStringSlice("packed-columns")
+	if err != nil {
+		return err
+	}
+
+	stream, err := flags.GetBool("stream")
+	if err != nil {
+		return err
+	}
+	run, err := signRunFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if stream && run.resume {
+		return errors.New("--resume can't be used with --stream")
+	}
+
+	var scan *rowScanner
+	switch format {
+	case "text":
+		if len(packed) > 0 {
+			return errors.New("--packed-columns requires --format csv")
+		}
+		scan = scanAddressLines(os.Stdin)
+	case "csv":
+		scan, err = scanAddressCSV(os.Stdin)
+	default:
+		return fmt.Errorf("unsupported --format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	defaultOut := "json"
+	if format == "csv" {
+		defaultOut = "csv"
+	}
+	outFormat, fixtureName, err := outFormatFromFlags(cmd, defaultOut)
+	if err != nil {
+		return err
+	}
+	if stream && outFormat != "json" && outFormat != "csv" {
+		return fmt.Errorf("--stream requires --out-format json or csv")
+	}
+
+	claim, err := claimFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if claim.enabled() {
+		if len(packed) > 0 {
+			return errors.New("--packed-columns can't be combined with --with-nonce, --allowance-column, or --expiry")
+		}
+		if format != "csv" && claim.allowanceColumn != "" {
+			return errors.New("--allowance-column requires --format csv")
+		}
+	}
+
+	signer, err := signerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if stream {
+		w := os.Stdout
+		if run.out != "" {
+			if w, err = os.Create(run.out); err != nil {
+				return fmt.Errorf("create --out: %v", err)
+			}
+			defer w.Close()
+		}
+		n, err := signAddressStream(w, outFormat, signer, scan, claim, packed, time.Now())
+		log.Printf("Signed %d addresses with %v", n, signer.Address())
+		return err
+	}
+
+	header := scan.header
+	rows, err := scan.all()
+	if err != nil {
+		return err
+	}
+	if claim.enabled() {
+		header, packed, err = claim.columns(header, rows, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	spec, err := newMessageSpec(header, packed)
+	if err != nil {
+		return err
+	}
+	spec.hash = claim.enabled()
+
+	sigs, err := run.sign(signer, spec, header, rows)
+	if err != nil {
+		return err
+	}
+	log.Printf("Signed %d addresses with %v", len(sigs), signer.Address())
+
+	list := newSignedList(signer.Address(), spec, header, rows, sigs)
+	return run.write(func(w io.Writer) error {
+		return writeSignedList(w, outFormat, fixtureName, list, header, rows, sigs)
+	})
+}
+
+// A signedStreamHeader is the first line of streamed JSON output, recording the
+// same metadata as a signedList so that the signedEntry on each subsequent line
+// can be verified by `ethier verify`.
+type signedStreamHeader struct {
+	Signer common.Address `json:"signer"`
+	Packed []string       `json:"packed,omitempty"`
+	Hashed bool           `json:"hashed,omitempty"`
+}
+
+// signAddressStream is the streaming equivalent of the latter half of
+// signAddresses(), signing and writing each row as it is read so memory usage
+// is independent of input size. JSON output is a signedStreamHeader followed
+// by a single signedEntry per line (JSONL). The number of rows signed is
+// returned, even if there is an error.
+func signAddressStream(w io.Writer, format string, signer eth.SignerBackend, scan *rowScanner, claim *claimSpec, packed []string, now time.Time) (int, error) {
+	// Claim columns are computed one row at a time, so the header and packed
+	// columns are computed once, without rows, to build the spec. A copy of
+	// the input header is used every time as columns() appends to it.
+	header := scan.header
+	if claim.enabled() {
+		var err error
+		header, packed, err = claim.columns(append([]string{}, scan.header...), nil, now)
+		if err != nil {
+			return 0, err
+		}
+	}
+	spec, err := newMessageSpec(header, packed)
+	if err != nil {
+		return 0, err
+	}
+	spec.hash = claim.enabled()
+	log.Printf("Streaming signatures; packed columns %q; hashed %t", packed, spec.hash)
+
+	var (
+		enc   *json.Encoder
+		csvw  *csv.Writer
+		total int
+	)
+	switch format {
+	case "json":
+		enc = json.NewEncoder(w)
+		meta := newSignedList(signer.Address(), spec, header, nil, nil)
+		if err := enc.Encode(signedStreamHeader{
+			Signer: meta.Signer,
+			Packed: meta.Packed,
+			Hashed: meta.Hashed,
+		}); err != nil {
+			return 0, err
+		}
+	case "csv":
+		csvw = csv.NewWriter(w)
+		defer csvw.Flush()
+		if err := csvw.Write(append(append([]string{}, header...), "signature")); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("unsupported streaming format %q", format)
+	}
+
+	for {
+		r, err := scan.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+		rows := []signRow{r}
+		if claim.enabled() {
+			if _, _, err := claim.columns(append([]string{}, scan.header...), rows, now); err != nil {
+				return total, err
+			}
+		}
+		sigs, err := signRows(signer, spec, rows)
+		if err != nil {
+			return total, err
+		}
+
+		if enc != nil {
+			entry := newSignedList(signer.Address(), spec, header, rows, sigs).Entries[0]
+			err = enc.Encode(entry)
+		} else {
+			err = csvw.Write(append(rows[0].record, hexutil.Encode(sigs[0])))
+		}
+		if err != nil {
+			return total, err
+		}
+		total++
+	}
+
+	if csvw != nil {
+		csvw.Flush()
+		return total, csvw.Error()
+	}
+	return total, nil
+}
+
+// newMessageSpec parses the name:type values of the --packed-columns flag,
+// confirming that each is in the CSV header.
+func newMessageSpec(header, packed []string) (*messageSpec, error) {
+	spec := new(messageSpec)
+	for _, p := range packed {
+		parts := strings.SplitN(p, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid packed column %q; expecting name:type", p)
+		}
+		idx := columnIndex(header, parts[0])
+		if idx == -1 {
+			return nil, fmt.Errorf("packed column %q not in CSV header", parts[0])
+		}
+		spec.packed = append(spec.packed, packedColumn{name: parts[0], typ: parts[1], idx: idx})
+	}
+	return spec, nil
+}
+
+// columnIndex returns the index of the named column in the header, ignoring
+// case and surrounding whitespace, or -1 if it doesn't exist.
+func columnIndex(header []string, name string) int {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// A claimSpec describes the values included in claim-style messages,
+// keccak256(abi.encodePacked(address, nonce, allowance, expiry)).
+type claimSpec struct {
+	withNonce       bool
+	allowanceColumn string
+	expiry          string
+}
+
+// claimFromFlags returns the claimSpec defined by the command's flags.
+func claimFromFlags(cmd *cobra.Command) (*claimSpec, error) {
+	flags := cmd.Flags()
+	c := new(claimSpec)
+	var err error
+	if c.withNonce, err = flags.GetBool("with-nonce"); err != nil {
+		return nil, err
+	}
+	if c.allowanceColumn, err = flags.GetString("allowance-column"); err != nil {
+		return nil, err
+	}
+	if c.expiry, err = flags.GetString("expiry"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// enabled returns whether any of the claim values are included.
+func (c *claimSpec) enabled() bool {
+	return c.withNonce || c.allowanceColumn != "" || c.expiry != ""
+}
+
+// columns returns the packed columns, in the form accepted by
+// newMessageSpec(), for the enabled claim values. Nonce and expiry values that
+// aren't already in the input are added to the header and every row's record,
+// which are modified in place, so they are included in the output.
+func (c *claimSpec) columns(header []string, rows []signRow, now time.Time) ([]string, []string, error) {
+	var packed []string
+
+	if c.withNonce {
+		if columnIndex(header, "nonce") == -1 {
+			header = append(header, "nonce")
+			for i := range rows {
+				n, err := rand.Int(rand.Reader, abi.MaxUint256)
+				if err != nil {
+					return nil, nil, fmt.Errorf("generate nonce: %v", err)
+				}
+				rows[i].record = append(rows[i].record, n.String())
+			}
+		}
+		packed = append(packed, "nonce:uint256")
+	}
+
+	if c.allowanceColumn != "" {
+		packed = append(packed, c.allowanceColumn+":uint256")
+	}
+
+	if c.expiry != "" {
+		if columnIndex(header, "expiry") != -1 {
+			return nil, nil, errors.New("--expiry can't be used with an input expiry column")
+		}
+		exp, err := parseExpiry(c.expiry, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		header = append(header, "expiry")
+		for i := range rows {
+			rows[i].record = append(rows[i].record, strconv.FormatInt(exp.Unix(), 10))
+		}
+		packed = append(packed, "expiry:uint256")
+	}
+
+	return header, packed, nil
+}
+
+// parseExpiry parses s as a Unix timestamp, an RFC 3339 time, or a duration
+// after now.
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid expiry %q; expecting Unix timestamp, RFC 3339 time, or duration", s)
+}
+
+// A rowScanner reads signRows one at a time, allowing input to be streamed.
+type rowScanner struct {
+	header []string
+	// next returns io.EOF after the last row.
+	next func() (signRow, error)
+}
+
+// all returns all remaining rows.
+func (s *rowScanner) all() ([]signRow, error) {
+	var rows []signRow
+	for {
+		r, err := s.next()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r)
+	}
+}
+
+// scanAddressLines scans addresses from r, one per line, ignoring empty lines.
+// The header has a single "address" column, for consistency with
+// scanAddressCSV().
+func scanAddressLines(r io.Reader) *rowScanner {
+	s := bufio.NewScanner(r)
+	line := 0
+	return &rowScanner{
+		header: []string{"address"},
+		next: func() (signRow, error) {
+			for s.Scan() {
+				line++
+				l := strings.TrimSpace(s.Text())
+				if l == "" {
+					continue
+				}
+				addr, err := eth.ParseAddress(l)
+				if err != nil {
+					return signRow{}, fmt.Errorf("line %d: %v", line, err)
+				}
+				return signRow{line: line, address: addr, record: []string{l}}, nil
+			}
+			if err := s.Err(); err != nil {
+				return signRow{}, fmt.Errorf("read input: %v", err)
+			}
+			return signRow{}, io.EOF
+		},
+	}
+}
+
+// scanAddressCSV scans CSV records from r, the first of which MUST be a header
+// with an "address" column. The header is read immediately.
+func scanAddressCSV(r io.Reader) (*rowScanner, error) {
+	c := csv.NewReader(r)
+	c.TrimLeadingSpace = true
+
+	header, err := c.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %v", err)
+	}
+	addrIdx := columnIndex(header, "address")
+	if addrIdx == -1 {
+		return nil, fmt.Errorf("CSV header %q has no address column", header)
+	}
+
+	line := 1
+	return &rowScanner{
+		header: header,
+		next: func() (signRow, error) {
+			rec, err := c.Read()
+			if err == io.EOF {
+				return signRow{}, err
+			}
+			if err != nil {
+				return signRow{}, fmt.Errorf("read CSV: %v", err)
+			}
+			line++
+			addr, err := eth.ParseAddress(rec[addrIdx])
+			if err != nil {
+				return signRow{}, fmt.Errorf("record %d: %v", line, err)
+			}
+			return signRow{line: line, address: addr, record: rec}, nil
+		},
+	}, nil
+}
+
+// signRows returns signatures of spec.message() for every row, in order.
+func signRows(signer eth.SignerBackend, spec *messageSpec, rows []signRow) ([][]byte, error) {
+	sigs := make([][]byte, len(rows))
+	for i, r := range rows {
+		msg, err := spec.message(r)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		sigs[i], err = eth.PersonalSign(signer, msg)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: sign %v: %v", r.line, r.address, err)
+		}
+	}
+	return sigs, nil
+}
+
+// newSignedList returns the JSON output of `ethier sign addresses`. CSV
+// columns other than the address are included as Fields, and the spec is
+// recorded so the messages can be recomputed by `ethier verify`.
+func newSignedList(signer common.Address, spec *messageSpec, header []string, rows []signRow, sigs [][]byte) *signedList {
+	list := &signedList{
+		Signer: signer,
+		Hashed: spec.hash,
+	}
+	for _, c := range spec.packed {
+		list.Packed = append(list.Packed, c.name+":"+c.typ)
+	}
+	for i, r := range rows {
+		e := signedEntry{
+			Address:   r.address,
+			Signature: sigs[i],
+		}
+		for j, v := range r.record {
+			if strings.EqualFold(strings.TrimSpace(header[j]), "address") {
+				continue
+			}
+			if e.Fields == nil {
+				e.Fields = make(map[string]string)
+			}
+			e.Fields[header[j]] = v
+		}
+		list.Entries = append(list.Entries, e)
+	}
+	return list
+}
+
+// writeSignedCSV writes the input CSV records to w, each with its signature as
+// an additional column.
+func writeSignedCSV(w io.Writer, header []string, rows []signRow, sigs [][]byte) error {
+	c := csv.NewWriter(w)
+	if err := c.Write(append(append([]string{}, header...), "signature")); err != nil {
+		return err
+	}
+	for i, r := range rows {
+		if err := c.Write(append(append([]string{}, r.record...), hexutil.Encode(sigs[i]))); err != nil {
+			return err
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Reads messages from stdin, one per line, and outputs JSON signatures of each."
+
+	cmd := &cobra.Command{
+		Use:   "messages",
+		Short: short,
+		Long: short + `
+
+Lines beginning with 0x are decoded as hex, allowing arbitrary bytes to be signed; all other lines are signed as their UTF-8 encoding, excluding the line ending. Empty lines are ignored.
+
+By default, signatures are EIP-191 personal signatures, compatible with SignatureChecker.requireValidSignature(signers, data, signature). With --eip-191=false, the keccak256 hash of each message is signed directly, as checked by ecrecover(keccak256(data), ...). In both cases signatures are in compact (EIP-2098) form.`,
+		RunE: signMessages,
+		Args: cobra.NoArgs,
+	}
+	addSignerFlags(cmd)
+	cmd.Flags().Bool("eip-191", true, "Sign messages as EIP-191 personal messages instead of signing their hashes directly")
+
+	signCmd.AddCommand(cmd)
+}
+
+// A signedMessage is a single signature output by `ethier sign messages`.
+type signedMessage struct {
+	// Message is the input line, as provided.
+	Message   string        `json:"message"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// A signedMessageList is the output of `ethier sign messages`.
+type signedMessageList struct {
+	Signer  common.Address  `json:"signer"`
+	EIP191  bool            `json:"eip191"`
+	Entries []signedMessage `json:"entries"`
+}
+
+// signMessages implements the `ethier sign messages` command.
+func signMessages(cmd *cobra.Command, args []string) error {
+	eip191, err := cmd.Flags().GetBool("eip-191")
+	if err != nil {
+		return err
+	}
+	signer, err := signerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	list, err := signMessageList(signer, os.Stdin, eip191)
+	if err != nil {
+		return err
+	}
+	log.Printf("Signed %d messages with %v", len(list.Entries), signer.Address())
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+// signMessageList signs every non-empty line read from r.
+func signMessageList(signer eth.SignerBackend, r io.Reader, eip191 bool) (*signedMessageList, error) {
+	list := &signedMessageList{
+		Signer: signer.Address(),
+		EIP191: eip191,
+	}
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		l := strings.TrimRight(s.Text(), "\r")
+		if l == "" {
+			continue
+		}
+		msg, err := parseMessage(l)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		var sig []byte
+		if eip191 {
+			sig, err = eth.PersonalSign(signer, msg)
+		} else {
+			sig, err = signHash(signer, msg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: sign: %v", line, err)
+		}
+		list.Entries = append(list.Entries, signedMessage{
+			Message:   l,
+			Signature: sig,
+		})
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read input: %v", err)
+	}
+	return list, nil
+}
+
+// parseMessage returns the bytes of a message line, decoding it as hex iff it
+// has a 0x prefix.
+func parseMessage(l string) ([]byte, error) {
+	if strings.HasPrefix(l, "0x") || strings.HasPrefix(l, "0X") {
+		return hexutil.Decode("0x" + l[2:])
+	}
+	return []byte(l), nil
+}
+
+// signHash returns a compact signature of keccak256(msg).
+func signHash(signer eth.SignerBackend, msg []byte) ([]byte, error) {
+	sig, err := signer.SignRawDigest(crypto.Keccak256(msg))
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) == 64 {
+		return sig, nil
+	}
+	return eth.CompactSignature(sig)
+}
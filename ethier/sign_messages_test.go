@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestSignMessageList(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	const in = "hello world\r\n\n0xdeadbeef\n0XCAFE\n"
+	wantMsgs := [][]byte{
+		[]byte("hello world"),
+		{0xde, 0xad, 0xbe, 0xef},
+		{0xca, 0xfe},
+	}
+
+	for _, eip191 := range []bool{true, false} {
+		list, err := signMessageList(signer, strings.NewReader(in), eip191)
+		if err != nil {
+			t.Fatalf("signMessageList(eip191 = %t) error %v", eip191, err)
+		}
+		if got, want := len(list.Entries), len(wantMsgs); got != want {
+			t.Fatalf("signMessageList(eip191 = %t) got %d entries; want %d", eip191, got, want)
+		}
+
+		for i, e := range list.Entries {
+			if got, want := len(e.Signature), 64; got != want {
+				t.Errorf("signMessageList(eip191 = %t) entry %d signature length %d; want %d", eip191, i, got, want)
+			}
+
+			var got bool
+			if eip191 {
+				got = signer.VerifyPersonal(wantMsgs[i], e.Signature)
+			} else {
+				addr, err := eth.RecoverAddress(crypto.Keccak256(wantMsgs[i]), e.Signature)
+				got = err == nil && addr == signer.Address()
+			}
+			if !got {
+				t.Errorf("signMessageList(eip191 = %t) entry %d (%q) signature not verified", eip191, i, e.Message)
+			}
+		}
+	}
+
+	if _, err := signMessageList(signer, strings.NewReader("0xnothex"), true); err == nil {
+		t.Error("signMessageList() with invalid hex got nil error; want error")
+	}
+}
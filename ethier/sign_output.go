@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+// outFormatHelp documents the flags registered by addOutFormatFlags(), for
+// inclusion in commands' long help.
+const outFormatHelp = `With --out-format sol, output is a Solidity library, named by --fixture-name, with functions returning arrays of the addresses, signatures, and any packed values, for use in tests. With --out-format ts, output is a TypeScript module exporting a typed constant of the same name, with the same fields as the JSON output. Only JSON output can be checked by ethier verify.`
+
+// addOutFormatFlags registers the flags parsed by outFormatFromFlags().
+func addOutFormatFlags(cmd *cobra.Command) {
+	cmd.Flags().String("out-format", "", "Output format: json, csv, sol (Solidity library for tests), or ts (TypeScript module); defaults to json, or csv for CSV input")
+	cmd.Flags().String("fixture-name", "Signatures", "Name of the Solidity library or TypeScript constant with --out-format sol or ts")
+}
+
+// outFormatFromFlags returns the values of the --out-format and --fixture-name
+// flags, the former defaulting to def if empty.
+func outFormatFromFlags(cmd *cobra.Command, def string) (string, string, error) {
+	flags := cmd.Flags()
+	format, err := flags.GetString("out-format")
+	if err != nil {
+		return "", "", err
+	}
+	name, err := flags.GetString("fixture-name")
+	if err != nil {
+		return "", "", err
+	}
+
+	if format == "" {
+		format = def
+	}
+	switch format {
+	case "json", "csv":
+	case "sol", "ts":
+		if !identifier.MatchString(name) {
+			return "", "", fmt.Errorf("--fixture-name %q is not a valid identifier", name)
+		}
+	default:
+		return "", "", fmt.Errorf("unsupported --out-format %q", format)
+	}
+	return format, name, nil
+}
+
+// identifier matches valid Solidity and TypeScript identifiers.
+var identifier = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*$`)
+
+// writeSignedList writes the signatures in the format returned by
+// outFormatFromFlags(). The header, rows, and sigs are only used for CSV
+// output, which echoes the input columns in their original order.
+func writeSignedList(w io.Writer, format, name string, list *signedList, header []string, rows []signRow, sigs [][]byte) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	case "csv":
+		return writeSignedCSV(w, header, rows, sigs)
+	case "sol":
+		return writeSolidityFixture(w, name, list)
+	case "ts":
+		return writeTypeScriptFixture(w, name, list)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// A fixtureField is a packed field of a signedList, with its value from every
+// entry formatted as a Solidity literal.
+type fixtureField struct {
+	Name, Type string
+	Values     []string
+}
+
+// fixtureFields returns the list's packed fields.
+func fixtureFields(list *signedList) ([]fixtureField, error) {
+	var fields []fixtureField
+	for _, p := range list.Packed {
+		parts := strings.SplitN(p, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid packed field %q", p)
+		}
+		f := fixtureField{Name: parts[0], Type: parts[1]}
+		switch {
+		case !identifier.MatchString(f.Name):
+			return nil, fmt.Errorf("packed field %q is not a valid Solidity identifier", f.Name)
+		case f.Name == "addresses" || f.Name == "signatures":
+			return nil, fmt.Errorf("packed field %q clashes with fixture function of the same name", f.Name)
+		}
+
+		for i, e := range list.Entries {
+			v, err := parsePackedArg(f.Type, e.Fields[f.Name])
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: field %q: %v", i, f.Name, err)
+			}
+			lit, err := solidityLiteral(v)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: field %q: %v", i, f.Name, err)
+			}
+			f.Values = append(f.Values, lit)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// solidityLiteral returns the Solidity literal of a value returned by
+// parsePackedArg().
+func solidityLiteral(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case common.Address:
+		return v.Hex(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		// Solidity supports the same \x and \u escapes as Go.
+		return strconv.QuoteToASCII(v), nil
+	case []byte:
+		return fmt.Sprintf("hex%q", hexutil.Encode(v)[2:]), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Ptr:
+		if x, ok := v.(interface{ String() string }); ok {
+			return x.String(), nil // *big.Int
+		}
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return fmt.Sprintf("hex%q", hexutil.Encode(b)[2:]), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported value %v of type %T", v, v)
+}
+
+// writeSolidityFixture writes the list as a Solidity library with functions
+// returning arrays of addresses, signatures, and any packed fields, all in the
+// same order.
+func writeSolidityFixture(w io.Writer, name string, list *signedList) error {
+	fields, err := fixtureFields(list)
+	if err != nil {
+		return err
+	}
+
+	var addrs, sigs []string
+	for _, e := range list.Entries {
+		addrs = append(addrs, e.Address.Hex())
+		sigs = append(sigs, fmt.Sprintf("hex%q", hexutil.Encode(e.Signature)[2:]))
+	}
+	fields = append([]fixtureField{
+		{Name: "addresses", Type: "address", Values: addrs},
+		{Name: "signatures", Type: "bytes", Values: sigs},
+	}, fields...)
+
+	return solidityFixture.Execute(w, struct {
+		Name   string
+		List   *signedList
+		Fields []fixtureField
+	}{name, list, fields})
+}
+
+var solidityFixture = template.Must(template.New("sol").Parse(`// SPDX-License-Identifier: UNLICENSED
+// Generated by ethier sign; DO NOT EDIT.
+pragma solidity >=0.8.0 <0.9.0;
+
+/**
+@notice Signatures by {{.List.Signer.Hex}}, with all arrays in the same order.
+{{- if .List.Packed}}
+@dev Signed messages are {{if .List.Hashed}}keccak256({{end}}abi.encodePacked(address{{range .List.Packed}}, {{.}}{{end}}){{if .List.Hashed}}){{end}}.
+{{- end}}
+ */
+library {{.Name}} {
+    address internal constant SIGNER = {{.List.Signer.Hex}};
+{{range .Fields}}
+    function {{.Name}}() internal pure returns ({{.Type}}[] memory values) {
+        values = new {{.Type}}[]({{len .Values}});
+        {{- range $i, $v := .Values}}
+        values[{{$i}}] = {{$v}};
+        {{- end}}
+    }
+{{end -}}
+}
+`))
+
+// writeTypeScriptFixture writes the list as a TypeScript module exporting a
+// typed constant.
+func writeTypeScriptFixture(w io.Writer, name string, list *signedList) error {
+	var fields []string
+	for _, p := range list.Packed {
+		fields = append(fields, strings.SplitN(p, ":", 2)[0])
+	}
+	// Non-packed fields are still included in the output, as with JSON.
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		seen[f] = true
+	}
+	for _, e := range list.Entries {
+		for f := range e.Fields {
+			if !seen[f] {
+				seen[f] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	// Map iteration is random so sort the non-packed fields for
+	// reproducibility.
+	sort.Strings(fields[len(list.Packed):])
+
+	// JSON strings are valid TypeScript string literals, and keys.
+	quote := func(s string) string {
+		b, _ := json.Marshal(s)
+		return string(b)
+	}
+	var entries []string
+	for _, e := range list.Entries {
+		parts := []string{
+			"address: " + quote(e.Address.Hex()),
+			"signature: " + quote(e.Signature.String()),
+		}
+		for _, f := range fields {
+			parts = append(parts, quote(f)+": "+quote(e.Fields[f]))
+		}
+		entries = append(entries, "{ "+strings.Join(parts, ", ")+" }")
+	}
+
+	var types []string
+	for _, f := range fields {
+		types = append(types, quote(f)+": string;")
+	}
+	var packed []string
+	for _, p := range list.Packed {
+		packed = append(packed, quote(p))
+	}
+
+	return typeScriptFixture.Execute(w, struct {
+		Name    string
+		List    *signedList
+		Packed  string
+		Types   []string
+		Entries []string
+	}{name, list, strings.Join(packed, ", "), types, entries})
+}
+
+var typeScriptFixture = template.Must(template.New("ts").Parse(`// Generated by ethier sign; DO NOT EDIT.
+
+export interface {{.Name}}Entry {
+  address: string;
+  signature: string;
+  {{- range .Types}}
+  {{.}}
+  {{- end}}
+}
+
+export const {{.Name}}: {
+  signer: string;
+  packed: readonly string[];
+  hashed: boolean;
+  entries: readonly {{.Name}}Entry[];
+} = {
+  signer: "{{.List.Signer.Hex}}",
+  packed: [{{.Packed}}],
+  hashed: {{.List.Hashed}},
+  entries: [
+    {{- range .Entries}}
+    {{.}},
+    {{- end}}
+  ],
+};
+`))
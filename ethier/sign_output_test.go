@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteSignedList(t *testing.T) {
+	list := &signedList{
+		Signer: common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"),
+		Packed: []string{"allowance:uint256", "tier:bytes2"},
+		Hashed: true,
+		Entries: []signedEntry{
+			{
+				Address:   common.HexToAddress("0xa1"),
+				Fields:    map[string]string{"allowance": "3", "tier": "0x0102", "note": `"vip"`},
+				Signature: hexutil.MustDecode("0xdead"),
+			},
+			{
+				Address:   common.HexToAddress("0xb0"),
+				Fields:    map[string]string{"allowance": "0x10", "tier": "0x0000"},
+				Signature: hexutil.MustDecode("0xbeef"),
+			},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "sol",
+			want: `// SPDX-License-Identifier: UNLICENSED
+// Generated by ethier sign; DO NOT EDIT.
+pragma solidity >=0.8.0 <0.9.0;
+
+/**
+@notice Signatures by 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed, with all arrays in the same order.
+@dev Signed messages are keccak256(abi.encodePacked(address, allowance:uint256, tier:bytes2)).
+ */
+library Allowlist {
+    address internal constant SIGNER = 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed;
+
+    function addresses() internal pure returns (address[] memory values) {
+        values = new address[](2);
+        values[0] = 0x00000000000000000000000000000000000000A1;
+        values[1] = 0x00000000000000000000000000000000000000B0;
+    }
+
+    function signatures() internal pure returns (bytes[] memory values) {
+        values = new bytes[](2);
+        values[0] = hex"dead";
+        values[1] = hex"beef";
+    }
+
+    function allowance() internal pure returns (uint256[] memory values) {
+        values = new uint256[](2);
+        values[0] = 3;
+        values[1] = 16;
+    }
+
+    function tier() internal pure returns (bytes2[] memory values) {
+        values = new bytes2[](2);
+        values[0] = hex"0102";
+        values[1] = hex"0000";
+    }
+}
+`,
+		},
+		{
+			format: "ts",
+			want: `// Generated by ethier sign; DO NOT EDIT.
+
+export interface AllowlistEntry {
+  address: string;
+  signature: string;
+  "allowance": string;
+  "tier": string;
+  "note": string;
+}
+
+export const Allowlist: {
+  signer: string;
+  packed: readonly string[];
+  hashed: boolean;
+  entries: readonly AllowlistEntry[];
+} = {
+  signer: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+  packed: ["allowance:uint256", "tier:bytes2"],
+  hashed: true,
+  entries: [
+    { address: "0x00000000000000000000000000000000000000A1", signature: "0xdead", "allowance": "3", "tier": "0x0102", "note": "\"vip\"" },
+    { address: "0x00000000000000000000000000000000000000B0", signature: "0xbeef", "allowance": "0x10", "tier": "0x0000", "note": "" },
+  ],
+};
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeSignedList(&buf, tt.format, "Allowlist", list, nil, nil, nil); err != nil {
+				t.Fatalf("writeSignedList(%q) error %v", tt.format, err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("writeSignedList(%q) diff (-want +got):\n%s", tt.format, diff)
+			}
+		})
+	}
+
+	t.Run("invalid Solidity identifier", func(t *testing.T) {
+		bad := *list
+		bad.Packed = []string{"signatures:uint256"}
+		if err := writeSignedList(new(bytes.Buffer), "sol", "Allowlist", &bad, nil, nil, nil); err == nil || !strings.Contains(err.Error(), "clashes") {
+			t.Errorf("writeSignedList(sol) with packed field named signatures got err %v; want clash error", err)
+		}
+	})
+}
+
+func TestSolidityLiteral(t *testing.T) {
+	tests := []struct {
+		typ, value, want string
+	}{
+		{typ: "uint8", value: "255", want: "255"},
+		{typ: "int64", value: "-3", want: "-3"},
+		{typ: "uint256", value: "0xff", want: "255"},
+		{typ: "bool", value: "true", want: "true"},
+		{typ: "string", value: `a"b`, want: `"a\"b"`},
+		{typ: "bytes", value: "0x", want: `hex""`},
+		{typ: "address", value: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", want: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+	}
+
+	for _, tt := range tests {
+		v, err := parsePackedArg(tt.typ, tt.value)
+		if err != nil {
+			t.Fatalf("parsePackedArg(%q, %q) error %v", tt.typ, tt.value, err)
+		}
+		if got, err := solidityLiteral(v); err != nil || got != tt.want {
+			t.Errorf("solidityLiteral(parsePackedArg(%q, %q)) got %s, err = %v; want %s, nil", tt.typ, tt.value, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// signRunHelp documents the flags registered by addSignRunFlags(), for
+// inclusion in commands' long help.
+const signRunHelp = `With --out, output is written to the file, atomically and only once all rows are signed, and signatures are checkpointed to <out>.partial as they are made. If the run is interrupted, e.g. with Ctrl+C, rerunning the same command with --resume reuses the checkpointed signatures, and any generated nonces and expiries, instead of signing those rows again. Progress is reported on stderr.`
+
+// addSignRunFlags registers the flags parsed by signRunFromFlags().
+func addSignRunFlags(cmd *cobra.Command) {
+	cmd.Flags().String("out", "", "File to which output is written instead of stdout, with signatures checkpointed to <out>.partial")
+	cmd.Flags().Bool("resume", false, "Resume an interrupted run from the --out checkpoint")
+}
+
+// A signRun signs rows and writes the output of `ethier sign` commands,
+// checkpointing signatures if writing to a file.
+type signRun struct {
+	out    string
+	resume bool
+	// progress, if non-nil, receives progress reports; see newSignProgress().
+	progress io.Writer
+}
+
+// signRunFromFlags returns the signRun defined by the flags registered with
+// addSignRunFlags(), reporting progress on stderr.
+func signRunFromFlags(cmd *cobra.Command) (*signRun, error) {
+	flags := cmd.Flags()
+	r := &signRun{progress: os.Stderr}
+	var err error
+	if r.out, err = flags.GetString("out"); err != nil {
+		return nil, err
+	}
+	if r.resume, err = flags.GetBool("resume"); err != nil {
+		return nil, err
+	}
+	if r.resume && r.out == "" {
+		return nil, errors.New("--resume requires --out")
+	}
+	return r, nil
+}
+
+// checkpointPath returns the path of the checkpoint file, or the empty string
+// if output isn't to a file.
+func (r *signRun) checkpointPath() string {
+	if r.out == "" {
+		return ""
+	}
+	return r.out + ".partial"
+}
+
+// signBatchSize is the maximum number of rows signed concurrently by
+// signRun.sign() between checkpoints.
+const signBatchSize = 1024
+
+// sign is equivalent to signRows() except that it reports progress, stops
+// cleanly on interrupt, and, if output is to a file, checkpoints every
+// signature. If the signed messages are only the addresses and the signer is
+// an *eth.Signer, rows are signed concurrently with SignAddressesBatch().
+// When resuming, the records of checkpointed rows are replaced, in place, with
+// those in the checkpoint so that generated values, e.g. random nonces, match
+// the signatures.
+func (r *signRun) sign(signer eth.SignerBackend, spec *messageSpec, header []string, rows []signRow) ([][]byte, error) {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var cp *signCheckpoint
+	if path := r.checkpointPath(); path != "" {
+		meta := newCheckpointMeta(signer.Address(), spec, header)
+		var err error
+		if cp, err = openSignCheckpoint(path, r.resume, meta); err != nil {
+			return nil, err
+		}
+		defer cp.close()
+		if r.resume {
+			log.Printf("Resuming with %d checkpointed signatures from %q", len(cp.done), path)
+		}
+	}
+
+	var progress *signProgress
+	if r.progress != nil {
+		progress = newSignProgress(r.progress, len(rows), time.Now())
+		defer progress.finish()
+	}
+
+	batch, batchable := signer.(*eth.Signer)
+	batchable = batchable && len(spec.packed) == 0 && !spec.hash
+
+	sigs := make([][]byte, len(rows))
+	for i := 0; i < len(rows); {
+		select {
+		case <-ctx.Done():
+			if cp == nil {
+				return nil, fmt.Errorf("interrupted after %d of %d signatures", i, len(rows))
+			}
+			return nil, fmt.Errorf("interrupted after %d of %d signatures; rerun with --resume to continue from %q", i, len(rows), cp.path)
+		default:
+		}
+
+		if e, ok := cp.lookup(rows[i].line); ok {
+			if err := e.matches(header, rows[i]); err != nil {
+				return nil, err
+			}
+			rows[i].record = e.Record
+			sigs[i] = e.Signature
+			i++
+			progress.update(i, time.Now())
+			continue
+		}
+
+		// Messages that are only the address can be signed concurrently, in
+		// chunks of consecutive rows that haven't been checkpointed so that
+		// progress is still saved and reported regularly. All other messages
+		// are signed one at a time.
+		end := i + 1
+		for batchable && end < len(rows) && end-i < signBatchSize {
+			if _, ok := cp.lookup(rows[end].line); ok {
+				break
+			}
+			end++
+		}
+
+		var (
+			s   [][]byte
+			err error
+		)
+		if batchable {
+			addrs := make([]common.Address, end-i)
+			for j := range addrs {
+				addrs[j] = rows[i+j].address
+			}
+			s, err = batch.SignAddressesBatch(ctx, addrs, 0)
+		} else {
+			s, err = signRows(signer, spec, rows[i:end])
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				continue // reported as an interruption
+			}
+			return nil, err
+		}
+
+		for j, sig := range s {
+			sigs[i+j] = sig
+			if err := cp.save(rows[i+j], sig); err != nil {
+				return nil, err
+			}
+		}
+		i = end
+		progress.update(i, time.Now())
+	}
+	return sigs, nil
+}
+
+// write calls fn with stdout or, if output is to a file, a temporary file that
+// is renamed to the output once fn returns successfully, after which the
+// checkpoint is removed.
+func (r *signRun) write(fn func(io.Writer) error) error {
+	if r.out == "" {
+		return fn(os.Stdout)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.out), filepath.Base(r.out)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temporary output: %v", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after successful rename
+
+	w := bufio.NewWriter(tmp)
+	if err := fn(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write output: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close output: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), r.out); err != nil {
+		return fmt.Errorf("rename output: %v", err)
+	}
+	log.Printf("Output written to %q", r.out)
+
+	if err := os.Remove(r.checkpointPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove checkpoint: %v", err)
+	}
+	return nil
+}
+
+// A checkpointMeta is the first line of a checkpoint file, recording the
+// parameters with which its signatures were made so that a run can only be
+// resumed with the same ones.
+type checkpointMeta struct {
+	Signer common.Address `json:"signer"`
+	Packed []string       `json:"packed,omitempty"`
+	Hashed bool           `json:"hashed,omitempty"`
+	Header []string       `json:"header"`
+}
+
+// newCheckpointMeta returns the checkpointMeta for signing rows with the
+// header, as described by the spec.
+func newCheckpointMeta(signer common.Address, spec *messageSpec, header []string) *checkpointMeta {
+	m := &checkpointMeta{
+		Signer: signer,
+		Hashed: spec.hash,
+		Header: header,
+	}
+	for _, c := range spec.packed {
+		m.Packed = append(m.Packed, c.name+":"+c.typ)
+	}
+	return m
+}
+
+// A checkpointEntry is a single signed row in a checkpoint file, following the
+// checkpointMeta.
+type checkpointEntry struct {
+	Line      int           `json:"line"`
+	Record    []string      `json:"record"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// matches returns an error if the entry isn't for the same address as the
+// row, which is the case if the input changed since the checkpoint was made.
+func (e *checkpointEntry) matches(header []string, r signRow) error {
+	idx := columnIndex(header, "address")
+	if len(e.Record) != len(header) || idx == -1 {
+		return fmt.Errorf("checkpoint entry for line %d has %d columns; want %d", e.Line, len(e.Record), len(header))
+	}
+	addr, err := eth.ParseAddress(e.Record[idx])
+	if err != nil || addr != r.address {
+		return fmt.Errorf("checkpoint entry for line %d is for address %q, not %v; was the input changed?", e.Line, e.Record[idx], r.address)
+	}
+	return nil
+}
+
+// A signCheckpoint is an append-only file of signatures, allowing an
+// interrupted run to be resumed. A nil *signCheckpoint is valid and has no
+// effect.
+type signCheckpoint struct {
+	path string
+	f    *os.File
+	enc  *json.Encoder
+	done map[int]*checkpointEntry
+}
+
+// openSignCheckpoint creates a new checkpoint file at path or, if resume is
+// true, opens the existing one, which MUST have been created with the same
+// meta. A truncated last entry, e.g. from a crash mid-write, is ignored.
+func openSignCheckpoint(path string, resume bool, meta *checkpointMeta) (*signCheckpoint, error) {
+	cp := &signCheckpoint{
+		path: path,
+		done: make(map[int]*checkpointEntry),
+	}
+
+	if !resume {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("checkpoint %q already exists; use --resume to continue the previous run, or delete it", path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("create checkpoint: %v", err)
+		}
+		cp.f, cp.enc = f, json.NewEncoder(f)
+		if err := cp.enc.Encode(meta); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("write checkpoint: %v", err)
+		}
+		return cp, nil
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %v", err)
+	}
+	lines := bytes.Split(buf, []byte("\n"))
+
+	want, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(lines[0], want) {
+		return nil, fmt.Errorf("checkpoint %q was made with a different signer, header, or packed values: %s", path, lines[0])
+	}
+
+	// Only the last line can be incomplete, which is ignored and then
+	// overwritten by truncating the file to the end of the last complete line.
+	complete := len(lines[0]) + 1
+	for _, l := range lines[1:] {
+		if len(l) == 0 {
+			continue
+		}
+		e := new(checkpointEntry)
+		if err := json.Unmarshal(l, e); err != nil {
+			break
+		}
+		cp.done[e.Line] = e
+		complete += len(l) + 1
+	}
+	if complete > len(buf) {
+		complete = len(buf)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint: %v", err)
+	}
+	if err := f.Truncate(int64(complete)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("truncate checkpoint: %v", err)
+	}
+	if _, err := f.Seek(int64(complete), io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("seek checkpoint: %v", err)
+	}
+	cp.f, cp.enc = f, json.NewEncoder(f)
+	return cp, nil
+}
+
+// lookup returns the checkpointed entry for the input line, if any.
+func (cp *signCheckpoint) lookup(line int) (*checkpointEntry, bool) {
+	if cp == nil {
+		return nil, false
+	}
+	e, ok := cp.done[line]
+	return e, ok
+}
+
+// save appends the signed row to the checkpoint. Each entry is a single write
+// so is preserved even if the process is killed.
+func (cp *signCheckpoint) save(r signRow, sig []byte) error {
+	if cp == nil {
+		return nil
+	}
+	e := &checkpointEntry{Line: r.line, Record: r.record, Signature: sig}
+	if err := cp.enc.Encode(e); err != nil {
+		return fmt.Errorf("write checkpoint: %v", err)
+	}
+	cp.done[r.line] = e
+	return nil
+}
+
+// close closes the checkpoint file, which is retained.
+func (cp *signCheckpoint) close() error {
+	if cp == nil {
+		return nil
+	}
+	return cp.f.Close()
+}
+
+// A signProgress reports progress of signing a known number of rows. On a
+// terminal, it redraws a progress bar at most every 100ms; otherwise it writes
+// a line every 10s. A nil *signProgress is valid and reports nothing.
+type signProgress struct {
+	w          io.Writer
+	tty        bool
+	total      int
+	done       int
+	start      time.Time
+	lastReport time.Time
+	reported   bool
+}
+
+// newSignProgress returns a signProgress writing to w, which is treated as a
+// terminal if it is an *os.File for which term.IsTerminal() is true.
+func newSignProgress(w io.Writer, total int, now time.Time) *signProgress {
+	p := &signProgress{
+		w:          w,
+		total:      total,
+		start:      now,
+		lastReport: now,
+	}
+	if f, ok := w.(*os.File); ok {
+		p.tty = term.IsTerminal(int(f.Fd()))
+	}
+	return p
+}
+
+// update records that done rows have been signed, reporting progress if
+// sufficient time has passed since the last report, or if all rows are done.
+func (p *signProgress) update(done int, now time.Time) {
+	if p == nil {
+		return
+	}
+	p.done = done
+
+	interval := 10 * time.Second
+	if p.tty {
+		interval = 100 * time.Millisecond
+	}
+	if now.Sub(p.lastReport) < interval && done != p.total {
+		return
+	}
+	p.lastReport = now
+	p.reported = true
+	p.report(now)
+}
+
+// report writes the current progress.
+func (p *signProgress) report(now time.Time) {
+	pct := 100.0
+	if p.total > 0 {
+		pct = 100 * float64(p.done) / float64(p.total)
+	}
+
+	var eta string
+	if elapsed := now.Sub(p.start); p.done > 0 && p.done < p.total {
+		remaining := time.Duration(float64(elapsed) / float64(p.done) * float64(p.total-p.done))
+		eta = fmt.Sprintf("; ~%v remaining", remaining.Round(time.Second))
+	}
+
+	if !p.tty {
+		fmt.Fprintf(p.w, "Signed %d of %d (%.1f%%)%s\n", p.done, p.total, pct, eta)
+		return
+	}
+	const width = 30
+	filled := int(pct / 100 * width)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+	fmt.Fprintf(p.w, "\r[%s] %d/%d (%.1f%%)%s\033[K", bar, p.done, p.total, pct, eta)
+}
+
+// finish ends a terminal progress bar's line so that subsequent output isn't
+// written over it.
+func (p *signProgress) finish() {
+	if p == nil || !p.tty || !p.reported {
+		return
+	}
+	fmt.Fprintln(p.w)
+}
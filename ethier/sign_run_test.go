@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// countingSigner counts the number of digests that it signs.
+type countingSigner struct {
+	eth.SignerBackend
+	n int
+}
+
+func (s *countingSigner) SignRawDigest(digest []byte) ([]byte, error) {
+	s.n++
+	return s.SignerBackend.SignRawDigest(digest)
+}
+
+func TestSignRunResume(t *testing.T) {
+	key, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+	signer := &countingSigner{SignerBackend: key}
+
+	header := []string{"address", "allowance"}
+	spec, err := newMessageSpec(header, []string{"allowance:uint256"})
+	if err != nil {
+		t.Fatalf("newMessageSpec() error %v", err)
+	}
+	rows := func(allowances ...string) []signRow {
+		var rows []signRow
+		for i, a := range allowances {
+			addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+			rows = append(rows, signRow{
+				line:    i + 2,
+				address: addr,
+				record:  []string{addr.Hex(), a},
+			})
+		}
+		return rows
+	}
+
+	out := filepath.Join(t.TempDir(), "signed.json")
+	run := &signRun{out: out}
+
+	// An invalid allowance in the third row stops the run, as an interrupt
+	// would, after checkpointing the first two.
+	if _, err := run.sign(signer, spec, header, rows("1", "2", "x", "4")); err == nil {
+		t.Fatalf("sign(<invalid allowance>) got nil error")
+	}
+	if _, err := run.sign(signer, spec, header, rows("1", "2", "3", "4")); err == nil || !strings.Contains(err.Error(), "--resume") {
+		t.Errorf("sign() with existing checkpoint and without resume got err %v; want error suggesting --resume", err)
+	}
+
+	t.Run("different signer", func(t *testing.T) {
+		other, err := eth.NewSigner(128)
+		if err != nil {
+			t.Fatalf("eth.NewSigner(128) error %v", err)
+		}
+		resume := &signRun{out: out, resume: true}
+		if _, err := resume.sign(other, spec, header, rows("1", "2", "3", "4")); err == nil {
+			t.Errorf("sign(<different signer>) with resume got nil error")
+		}
+	})
+
+	t.Run("different input", func(t *testing.T) {
+		resume := &signRun{out: out, resume: true}
+		changed := rows("1", "2", "3", "4")
+		changed[1].address = common.Address{}
+		if _, err := resume.sign(signer, spec, header, changed); err == nil {
+			t.Errorf("sign(<changed address>) with resume got nil error")
+		}
+	})
+
+	// Simulate a crash mid-write of an entry, which MUST be ignored.
+	f, err := os.OpenFile(run.checkpointPath(), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"line":4,"rec`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	signer.n = 0
+	resume := &signRun{out: out, resume: true}
+	want := rows("1", "2", "3", "4")
+	got, err := resume.sign(signer, spec, header, want)
+	if err != nil {
+		t.Fatalf("sign() with resume error %v", err)
+	}
+	if signer.n != 2 {
+		t.Errorf("sign() with resume signed %d digests; want 2, with others from checkpoint", signer.n)
+	}
+	fresh, err := signRows(key, spec, want)
+	if err != nil {
+		t.Fatalf("signRows() error %v", err)
+	}
+	if diff := cmp.Diff(fresh, got); diff != "" {
+		t.Errorf("sign() with resume diff from signRows() (-want +got):\n%s", diff)
+	}
+
+	if err := resume.write(func(w io.Writer) error {
+		_, err := io.WriteString(w, "done")
+		return err
+	}); err != nil {
+		t.Fatalf("write() error %v", err)
+	}
+	if buf, err := os.ReadFile(out); err != nil || string(buf) != "done" {
+		t.Errorf("os.ReadFile(--out) got %q, err = %v; want %q, nil", buf, err, "done")
+	}
+	if _, err := os.Stat(run.checkpointPath()); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(<checkpoint>) after write() got err %v; want not exist", err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("output directory has %d entries; want only --out, with temporary files removed", len(entries))
+	}
+}
+
+func TestSignRunBatch(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	header := []string{"address"}
+	spec, err := newMessageSpec(header, nil)
+	if err != nil {
+		t.Fatalf("newMessageSpec() error %v", err)
+	}
+	var rows []signRow
+	for i := 0; i < 2*signBatchSize+3; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+		rows = append(rows, signRow{
+			line:    i + 1,
+			address: addr,
+			record:  []string{addr.Hex()},
+		})
+	}
+
+	run := &signRun{out: filepath.Join(t.TempDir(), "signed.json")}
+	got, err := run.sign(signer, spec, header, rows)
+	if err != nil {
+		t.Fatalf("sign() error %v", err)
+	}
+	want, err := signRows(signer, spec, rows)
+	if err != nil {
+		t.Fatalf("signRows() error %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("sign() of addresses diff from signRows() (-want +got):\n%s", diff)
+	}
+
+	buf, err := os.ReadFile(run.checkpointPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := bytes.Count(buf, []byte("\n")), len(rows)+1; got != want {
+		t.Errorf("checkpoint has %d lines; want %d (meta plus every row)", got, want)
+	}
+}
+
+func TestSignProgress(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Unix(0, 0)
+	p := newSignProgress(&buf, 4, start)
+
+	p.update(1, start.Add(time.Second)) // too soon
+	p.update(2, start.Add(10*time.Second))
+	p.update(3, start.Add(11*time.Second)) // too soon
+	p.update(4, start.Add(12*time.Second)) // done
+	p.finish()
+
+	want := "Signed 2 of 4 (50.0%); ~10s remaining\nSigned 4 of 4 (100.0%)\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("signProgress output diff (-want +got):\n%s", diff)
+	}
+
+	var nilProgress *signProgress
+	nilProgress.update(1, start)
+	nilProgress.finish()
+}
@@ -0,0 +1,541 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestSignerFromFlags(t *testing.T) {
+	const hexKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	want, err := eth.NewSignerFromHex(hexKey)
+	if err != nil {
+		t.Fatalf("eth.NewSignerFromHex() error %v", err)
+	}
+
+	dir := t.TempDir()
+	write := func(name, contents string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatalf("os.WriteFile(%q) error %v", path, err)
+		}
+		return path
+	}
+	keyFile := write("key.hex", hexKey+"\n")
+	pwFile := write("password.txt", "hunter2\n")
+	keystore := filepath.Join(dir, "keystore.json")
+	if err := want.SaveKeystore(keystore, "hunter2"); err != nil {
+		t.Fatalf("%T.SaveKeystore() error %v", want, err)
+	}
+	keystoreDir := filepath.Join(dir, "wallet")
+	if err := os.Mkdir(keystoreDir, 0700); err != nil {
+		t.Fatalf("os.Mkdir(%q) error %v", keystoreDir, err)
+	}
+	if err := os.Link(keystore, filepath.Join(keystoreDir, "UTC--key")); err != nil {
+		t.Fatalf("os.Link(%q) error %v", keystore, err)
+	}
+
+	const mnemonic = "test test test test test test test test test test test junk"
+	fromMnemonic, err := eth.NewSignerFromMnemonic(mnemonic, "m/44'/60'/0'/0/1")
+	if err != nil {
+		t.Fatalf("eth.NewSignerFromMnemonic() error %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    *eth.Signer
+		wantErr bool
+	}{
+		{
+			name: "private key",
+			args: []string{"--private-key", hexKey},
+			want: want,
+		},
+		{
+			name: "key file",
+			args: []string{"--key-file", keyFile},
+			want: want,
+		},
+		{
+			name: "keystore",
+			args: []string{"--keystore", keystore, "--password-file", pwFile},
+			want: want,
+		},
+		{
+			name:    "keystore without password",
+			args:    []string{"--keystore", keystore},
+			wantErr: true,
+		},
+		{
+			name: "account",
+			args: []string{"--account", want.Address().Hex(), "--keystore-dir", keystoreDir, "--password-file", pwFile},
+			want: want,
+		},
+		{
+			name:    "account without password",
+			args:    []string{"--account", want.Address().Hex(), "--keystore-dir", keystoreDir},
+			wantErr: true,
+		},
+		{
+			name:    "account not in keystore directory",
+			args:    []string{"--account", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "--keystore-dir", keystoreDir, "--password-file", pwFile},
+			wantErr: true,
+		},
+		{
+			name: "mnemonic",
+			args: []string{"--mnemonic", mnemonic, "--derivation-path", "m/44'/60'/0'/0/1"},
+			want: fromMnemonic,
+		},
+		{
+			name:    "multiple sources",
+			args:    []string{"--private-key", hexKey, "--key-file", keyFile},
+			wantErr: true,
+		},
+		{
+			name:    "invalid KMS URI",
+			args:    []string{"--kms", "azure://key"},
+			wantErr: true,
+		},
+		{
+			name:    "remote without address",
+			args:    []string{"--remote", "http://localhost:8550"},
+			wantErr: true,
+		},
+		{
+			name:    "remote with unsupported protocol",
+			args:    []string{"--remote", "http://localhost:8550", "--remote-address", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "--remote-protocol", "ledger"},
+			wantErr: true,
+		},
+		{
+			name:    "interactive on non-loopback address",
+			args:    []string{"--interactive", "0.0.0.0:0"},
+			wantErr: true,
+		},
+		{
+			name:    "interactive with another source",
+			args:    []string{"--interactive", "localhost:0", "--private-key", hexKey},
+			wantErr: true,
+		},
+		{
+			name: "none",
+			args: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			addSignerFlags(cmd)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags(%q) error %v", tt.args, err)
+			}
+
+			got, err := signerFromFlags(cmd)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("signerFromFlags(%q) got err %v; want error = %t", tt.args, err, tt.wantErr)
+			}
+			if tt.wantErr || tt.want == nil {
+				return
+			}
+			if got.Address() != tt.want.Address() {
+				t.Errorf("signerFromFlags(%q).Address() got %v; want %v", tt.args, got.Address(), tt.want.Address())
+			}
+		})
+	}
+
+	t.Run("existing signer required", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		addSignerFlags(cmd)
+		if _, err := existingSignerFromFlags(cmd); err != errNoSigner {
+			t.Errorf("existingSignerFromFlags(<no flags>) got err %v; want %v", err, errNoSigner)
+		}
+	})
+}
+
+// readRows returns the header and all rows of the scanner, as does the
+// non-streaming `ethier sign addresses` command.
+func readRows(s *rowScanner, err error) ([]string, []signRow, error) {
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := s.all()
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.header, rows, nil
+}
+
+func TestSignAddressLines(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	in := `
+0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed
+
+0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359
+`
+	header, rows, err := readRows(scanAddressLines(strings.NewReader(in)), nil)
+	if err != nil {
+		t.Fatalf("scanAddressLines().all() error %v", err)
+	}
+	if got, want := len(rows), 2; got != want {
+		t.Fatalf("scanAddressLines().all() got %d rows; want %d", got, want)
+	}
+
+	spec := new(messageSpec)
+	sigs, err := signRows(signer, spec, rows)
+	if err != nil {
+		t.Fatalf("signRows() error %v", err)
+	}
+	list := newSignedList(signer.Address(), spec, header, rows, sigs)
+	if got, want := list.Signer, signer.Address(); got != want {
+		t.Errorf("newSignedList().Signer got %v; want %v", got, want)
+	}
+	for _, e := range list.Entries {
+		if !signer.VerifyPersonal(e.Address.Bytes(), e.Signature) {
+			t.Errorf("signature of %v not verified", e.Address)
+		}
+		if e.Fields != nil {
+			t.Errorf("entry for %v got non-nil Fields %v", e.Address, e.Fields)
+		}
+	}
+
+	for _, bad := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", // checksum
+		"0x1234",
+		"garbage",
+	} {
+		if _, _, err := readRows(scanAddressLines(strings.NewReader(bad)), nil); err == nil {
+			t.Errorf("scanAddressLines(%q).all() got nil error; want error", bad)
+		}
+	}
+}
+
+func TestSignAddressCSV(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	in := `Name,Address,Allowance,Tier
+alice,0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,3,1
+bob, 0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359,10,2
+`
+	header, rows, err := readRows(scanAddressCSV(strings.NewReader(in)))
+	if err != nil {
+		t.Fatalf("scanAddressCSV().all() error %v", err)
+	}
+	spec, err := newMessageSpec(header, []string{"allowance:uint256", "tier:uint8"})
+	if err != nil {
+		t.Fatalf("newMessageSpec() error %v", err)
+	}
+	sigs, err := signRows(signer, spec, rows)
+	if err != nil {
+		t.Fatalf("signRows() error %v", err)
+	}
+
+	list := newSignedList(signer.Address(), spec, header, rows, sigs)
+	wantFields := []map[string]string{
+		{"Name": "alice", "Allowance": "3", "Tier": "1"},
+		{"Name": "bob", "Allowance": "10", "Tier": "2"},
+	}
+	allowances := []int64{3, 10}
+	tiers := []uint8{1, 2}
+
+	for i, e := range list.Entries {
+		if diff := cmp.Diff(wantFields[i], e.Fields); diff != "" {
+			t.Errorf("entry %d Fields diff (-want +got):\n%s", i, diff)
+		}
+		msg, err := eth.EncodePacked(
+			[]string{"address", "uint256", "uint8"},
+			e.Address, big.NewInt(allowances[i]), tiers[i],
+		)
+		if err != nil {
+			t.Fatalf("eth.EncodePacked() error %v", err)
+		}
+		if !signer.VerifyPersonal(msg, e.Signature) {
+			t.Errorf("entry %d signature of packed (address, allowance, tier) not verified", i)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := writeSignedCSV(&out, header, rows, sigs); err != nil {
+		t.Fatalf("writeSignedCSV() error %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if got, want := lines[0], "Name,Address,Allowance,Tier,signature"; got != want {
+		t.Errorf("writeSignedCSV() header got %q; want %q", got, want)
+	}
+	if got, want := lines[1], fmt.Sprintf("alice,0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,3,1,%#x", sigs[0]); got != want {
+		t.Errorf("writeSignedCSV() first record got %q; want %q", got, want)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		if _, _, err := readRows(scanAddressCSV(strings.NewReader("wallet,tier\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,1\n"))); err == nil {
+			t.Error("scanAddressCSV().all() without address column got nil error; want error")
+		}
+		for _, packed := range [][]string{
+			{"missing:uint8"},
+			{"tier"},
+			{"tier:"},
+		} {
+			if _, err := newMessageSpec(header, packed); err == nil {
+				t.Errorf("newMessageSpec(%q) got nil error; want error", packed)
+			}
+		}
+
+		header, rows, err := readRows(scanAddressCSV(strings.NewReader("address,allowance\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,256\n")))
+		if err != nil {
+			t.Fatalf("scanAddressCSV().all() error %v", err)
+		}
+		spec, err := newMessageSpec(header, []string{"allowance:uint8"})
+		if err != nil {
+			t.Fatalf("newMessageSpec() error %v", err)
+		}
+		if _, err := signRows(signer, spec, rows); err == nil {
+			t.Error("signRows() with out-of-range uint8 got nil error; want error")
+		}
+	})
+}
+
+func TestSignAddressClaims(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+	now := time.Unix(1_000_000, 0)
+
+	in := `address,allowance
+0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,3
+0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359,10
+`
+	header, rows, err := readRows(scanAddressCSV(strings.NewReader(in)))
+	if err != nil {
+		t.Fatalf("scanAddressCSV().all() error %v", err)
+	}
+	claim := &claimSpec{
+		withNonce:       true,
+		allowanceColumn: "allowance",
+		expiry:          "1h",
+	}
+	header, packed, err := claim.columns(header, rows, now)
+	if err != nil {
+		t.Fatalf("claimSpec.columns() error %v", err)
+	}
+	if diff := cmp.Diff([]string{"address", "allowance", "nonce", "expiry"}, header); diff != "" {
+		t.Errorf("claimSpec.columns() header diff (-want +got):\n%s", diff)
+	}
+	spec, err := newMessageSpec(header, packed)
+	if err != nil {
+		t.Fatalf("newMessageSpec() error %v", err)
+	}
+	spec.hash = true
+
+	sigs, err := signRows(signer, spec, rows)
+	if err != nil {
+		t.Fatalf("signRows() error %v", err)
+	}
+
+	list := newSignedList(signer.Address(), spec, header, rows, sigs)
+	allowances := []int64{3, 10}
+	nonces := make(map[string]bool)
+
+	for i, e := range list.Entries {
+		if got, want := e.Fields["expiry"], "1003600"; got != want {
+			t.Errorf("entry %d expiry got %q; want %q", i, got, want)
+		}
+		nonce, ok := new(big.Int).SetString(e.Fields["nonce"], 10)
+		if !ok {
+			t.Fatalf("entry %d invalid nonce %q", i, e.Fields["nonce"])
+		}
+		if nonces[nonce.String()] {
+			t.Errorf("entry %d reused nonce %v", i, nonce)
+		}
+		nonces[nonce.String()] = true
+
+		buf, err := eth.EncodePacked(
+			[]string{"address", "uint256", "uint256", "uint256"},
+			e.Address, nonce, big.NewInt(allowances[i]), big.NewInt(1003600),
+		)
+		if err != nil {
+			t.Fatalf("eth.EncodePacked() error %v", err)
+		}
+		if !signer.VerifyPersonal(crypto.Keccak256(buf), e.Signature) {
+			t.Errorf("entry %d signature of keccak256(address, nonce, allowance, expiry) not verified", i)
+		}
+	}
+
+	t.Run("existing nonce column", func(t *testing.T) {
+		header, rows, err := readRows(scanAddressCSV(strings.NewReader("address,nonce\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,42\n")))
+		if err != nil {
+			t.Fatalf("scanAddressCSV().all() error %v", err)
+		}
+		header, packed, err := (&claimSpec{withNonce: true}).columns(header, rows, now)
+		if err != nil {
+			t.Fatalf("claimSpec.columns() error %v", err)
+		}
+		if diff := cmp.Diff([]string{"address", "nonce"}, header); diff != "" {
+			t.Errorf("claimSpec.columns() header diff (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"nonce:uint256"}, packed); diff != "" {
+			t.Errorf("claimSpec.columns() packed diff (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "42"}, rows[0].record); diff != "" {
+			t.Errorf("claimSpec.columns() modified record; diff (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestSignAddressStream(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+	now := time.Unix(1_000_000, 0)
+
+	const in = `address,allowance
+0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,3
+0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359,10
+`
+
+	t.Run("json matches buffered", func(t *testing.T) {
+		header, rows, err := readRows(scanAddressCSV(strings.NewReader(in)))
+		if err != nil {
+			t.Fatalf("scanAddressCSV().all() error %v", err)
+		}
+		spec, err := newMessageSpec(header, []string{"allowance:uint256"})
+		if err != nil {
+			t.Fatalf("newMessageSpec() error %v", err)
+		}
+		sigs, err := signRows(signer, spec, rows)
+		if err != nil {
+			t.Fatalf("signRows() error %v", err)
+		}
+		var want bytes.Buffer
+		enc := json.NewEncoder(&want)
+		list := newSignedList(signer.Address(), spec, header, rows, sigs)
+		if err := enc.Encode(signedStreamHeader{Signer: list.Signer, Packed: list.Packed, Hashed: list.Hashed}); err != nil {
+			t.Fatalf("json.Encode(%T) error %v", signedStreamHeader{}, err)
+		}
+		for _, e := range list.Entries {
+			if err := enc.Encode(e); err != nil {
+				t.Fatalf("json.Encode(%+v) error %v", e, err)
+			}
+		}
+
+		scan, err := scanAddressCSV(strings.NewReader(in))
+		if err != nil {
+			t.Fatalf("scanAddressCSV() error %v", err)
+		}
+		var got bytes.Buffer
+		n, err := signAddressStream(&got, "json", signer, scan, new(claimSpec), []string{"allowance:uint256"}, now)
+		if err != nil {
+			t.Fatalf("signAddressStream() error %v", err)
+		}
+		if n != 2 {
+			t.Errorf("signAddressStream() got %d signed; want 2", n)
+		}
+		if diff := cmp.Diff(want.String(), got.String()); diff != "" {
+			t.Errorf("signAddressStream() diff (-buffered +streamed):\n%s", diff)
+		}
+	})
+
+	t.Run("csv with claim", func(t *testing.T) {
+		scan, err := scanAddressCSV(strings.NewReader(in))
+		if err != nil {
+			t.Fatalf("scanAddressCSV() error %v", err)
+		}
+		claim := &claimSpec{
+			withNonce:       true,
+			allowanceColumn: "allowance",
+			expiry:          "1h",
+		}
+		var buf bytes.Buffer
+		if _, err := signAddressStream(&buf, "csv", signer, scan, claim, nil, now); err != nil {
+			t.Fatalf("signAddressStream() error %v", err)
+		}
+
+		header, rows, err := readRows(scanAddressCSV(&buf))
+		if err != nil {
+			t.Fatalf("scanAddressCSV(streamed output).all() error %v", err)
+		}
+		if diff := cmp.Diff([]string{"address", "allowance", "nonce", "expiry", "signature"}, header); diff != "" {
+			t.Errorf("streamed CSV header diff (-want +got):\n%s", diff)
+		}
+		for _, r := range rows {
+			nonce, _ := new(big.Int).SetString(r.record[2], 10)
+			allowance, _ := new(big.Int).SetString(r.record[1], 10)
+			buf, err := eth.EncodePacked(
+				[]string{"address", "uint256", "uint256", "uint256"},
+				r.address, nonce, allowance, big.NewInt(1003600),
+			)
+			if err != nil {
+				t.Fatalf("eth.EncodePacked() error %v", err)
+			}
+			sig, err := hexutil.Decode(r.record[4])
+			if err != nil {
+				t.Fatalf("hexutil.Decode(%q) error %v", r.record[4], err)
+			}
+			if got := r.record[3]; got != "1003600" {
+				t.Errorf("line %d expiry got %q; want 1003600", r.line, got)
+			}
+			if !signer.VerifyPersonal(crypto.Keccak256(buf), sig) {
+				t.Errorf("line %d signature of keccak256(address, nonce, allowance, expiry) not verified", r.line)
+			}
+		}
+	})
+
+	t.Run("invalid address stops stream", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := signAddressStream(&buf, "json", signer, scanAddressLines(strings.NewReader("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed\ngarbage\n")), new(claimSpec), nil, now)
+		if err == nil {
+			t.Errorf("signAddressStream() with invalid address got nil error")
+		}
+		if n != 1 || strings.Count(buf.String(), "\n") != 2 {
+			t.Errorf("signAddressStream() before invalid address got %d signed, output %q; want header and 1 entry", n, buf.String())
+		}
+	})
+}
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+
+	tests := []struct {
+		s       string
+		want    int64
+		wantErr bool
+	}{
+		{s: "1700000000", want: 1700000000},
+		{s: "2022-01-01T00:00:00Z", want: 1640995200},
+		{s: "24h", want: 1_086_400},
+		{s: "tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseExpiry(tt.s, now)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("parseExpiry(%q) got err %v; want error = %t", tt.s, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Unix() != tt.want {
+			t.Errorf("parseExpiry(%q) got %d; want %d", tt.s, got.Unix(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Reads address,tokenId pairs from stdin and outputs EIP-191 personal signatures of each."
+
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: short,
+		Long: short + `
+
+The signed message is abi.encodePacked(address, uint256(tokenId)), binding each signature to both the claimant and a specific token. Token IDs MAY be decimal or 0x-prefixed hex. An optional header row, with "address" as its first column, is ignored. Signatures are in compact (EIP-2098) form.
+
+` + outFormatHelp + `
+
+` + signRunHelp,
+		RunE: signTokens,
+		Args: cobra.NoArgs,
+	}
+	addSignerFlags(cmd)
+	addOutFormatFlags(cmd)
+	addSignRunFlags(cmd)
+
+	signCmd.AddCommand(cmd)
+}
+
+// signTokens implements the `ethier sign tokens` command.
+func signTokens(cmd *cobra.Command, args []string) error {
+	outFormat, fixtureName, err := outFormatFromFlags(cmd, "json")
+	if err != nil {
+		return err
+	}
+	run, err := signRunFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	header, rows, err := readTokenPairs(os.Stdin)
+	if err != nil {
+		return err
+	}
+	spec, err := newMessageSpec(header, []string{"tokenId:uint256"})
+	if err != nil {
+		return err
+	}
+
+	signer, err := signerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	sigs, err := run.sign(signer, spec, header, rows)
+	if err != nil {
+		return err
+	}
+	log.Printf("Signed %d tokens with %v", len(sigs), signer.Address())
+
+	list := newSignedList(signer.Address(), spec, header, rows, sigs)
+	return run.write(func(w io.Writer) error {
+		return writeSignedList(w, outFormat, fixtureName, list, header, rows, sigs)
+	})
+}
+
+// readTokenPairs reads address,tokenId CSV records from r, returning them in
+// the same form as scanAddressCSV(), with a header of address,tokenId.
+func readTokenPairs(r io.Reader) ([]string, []signRow, error) {
+	c := csv.NewReader(r)
+	c.TrimLeadingSpace = true
+	c.FieldsPerRecord = 2
+
+	var rows []signRow
+	for line := 1; ; line++ {
+		rec, err := c.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("read CSV: %v", err)
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(rec[0]), "address") {
+			continue
+		}
+
+		addr, err := eth.ParseAddress(rec[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if _, err := parsePackedArg("uint256", rec[1]); err != nil {
+			return nil, nil, fmt.Errorf("line %d: token ID: %v", line, err)
+		}
+		rows = append(rows, signRow{line: line, address: addr, record: rec})
+	}
+	return []string{"address", "tokenId"}, rows, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestSignTokens(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	const in = `address,tokenId
+0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,1
+0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed, 0x2a
+0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359,1
+`
+	header, rows, err := readTokenPairs(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("readTokenPairs() error %v", err)
+	}
+	spec, err := newMessageSpec(header, []string{"tokenId:uint256"})
+	if err != nil {
+		t.Fatalf("newMessageSpec() error %v", err)
+	}
+	sigs, err := signRows(signer, spec, rows)
+	if err != nil {
+		t.Fatalf("signRows() error %v", err)
+	}
+
+	list := newSignedList(signer.Address(), spec, header, rows, sigs)
+	wantIDs := []int64{1, 42, 1}
+	if got, want := len(list.Entries), len(wantIDs); got != want {
+		t.Fatalf("got %d entries; want %d", got, want)
+	}
+
+	for i, e := range list.Entries {
+		msg, err := eth.EncodePacked([]string{"address", "uint256"}, e.Address, big.NewInt(wantIDs[i]))
+		if err != nil {
+			t.Fatalf("eth.EncodePacked() error %v", err)
+		}
+		if !signer.VerifyPersonal(msg, e.Signature) {
+			t.Errorf("entry %d signature of packed (address, tokenId) not verified", i)
+		}
+		if _, ok := e.Fields["tokenId"]; !ok {
+			t.Errorf("entry %d missing tokenId field", i)
+		}
+	}
+
+	for _, bad := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,1,2",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,-1",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,one",
+		"0x1234,1",
+	} {
+		if _, _, err := readTokenPairs(strings.NewReader(bad)); err == nil {
+			t.Errorf("readTokenPairs(%q) got nil error; want error", bad)
+		}
+	}
+}
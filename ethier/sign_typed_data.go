@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Outputs EIP-712 signatures of structured messages."
+
+	cmd := &cobra.Command{
+		Use:   "typed-data",
+		Short: short,
+		Long: short + `
+
+The --schema file is a JSON object with "types", "primaryType", and "domain" fields, as in eth_signTypedData_v4 but without the "message". If "types" doesn't include EIP712Domain then it is derived from the fields present in "domain", in the canonical order of name, version, chainId, verifyingContract, salt.
+
+The --data file is either a single JSON message of the primary type or an array of them; "-" reads from stdin. The output includes each message with its digest and signature, the latter being 65 bytes with v in {27,28} so it can be split for functions such as ERC-2612 permit().`,
+		RunE: signTypedData,
+		Args: cobra.NoArgs,
+	}
+	addSignerFlags(cmd)
+	cmd.Flags().String("schema", "", "JSON file defining the types, primary type, and domain")
+	cmd.Flags().String("data", "-", `JSON file containing a message or array of messages; "-" for stdin`)
+
+	signCmd.AddCommand(cmd)
+}
+
+// A signedTypedData is a single signature output by `ethier sign typed-data`.
+type signedTypedData struct {
+	Message   apitypes.TypedDataMessage `json:"message"`
+	Digest    hexutil.Bytes             `json:"digest"`
+	Signature hexutil.Bytes             `json:"signature"`
+}
+
+// A signedTypedDataList is the output of `ethier sign typed-data`.
+type signedTypedDataList struct {
+	Signer      common.Address           `json:"signer"`
+	Types       apitypes.Types           `json:"types"`
+	PrimaryType string                   `json:"primaryType"`
+	Domain      apitypes.TypedDataDomain `json:"domain"`
+	Entries     []signedTypedData        `json:"entries"`
+}
+
+// signTypedData implements the `ethier sign typed-data` command.
+func signTypedData(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	schemaPath, err := flags.GetString("schema")
+	if err != nil {
+		return err
+	}
+	dataPath, err := flags.GetString("data")
+	if err != nil {
+		return err
+	}
+	if schemaPath == "" {
+		return errors.New("--schema is required")
+	}
+
+	schemaFile, err := os.Open(schemaPath)
+	if err != nil {
+		return fmt.Errorf("open --schema: %v", err)
+	}
+	defer schemaFile.Close()
+	schema, err := readTypedDataSchema(schemaFile)
+	if err != nil {
+		return err
+	}
+
+	data := io.Reader(os.Stdin)
+	if dataPath != "-" {
+		f, err := os.Open(dataPath)
+		if err != nil {
+			return fmt.Errorf("open --data: %v", err)
+		}
+		defer f.Close()
+		data = f
+	}
+	msgs, err := readTypedDataMessages(data)
+	if err != nil {
+		return err
+	}
+
+	signer, err := signerFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	list, err := signTypedDataList(signer, schema, msgs)
+	if err != nil {
+		return err
+	}
+	log.Printf("Signed %d %s messages with %v", len(list.Entries), schema.PrimaryType, signer.Address())
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+// readTypedDataSchema parses a TypedData, without a message, from r. If the
+// types don't include EIP712Domain, it is derived from the domain.
+func readTypedDataSchema(r io.Reader) (*apitypes.TypedData, error) {
+	var raw struct {
+		Types       apitypes.Types         `json:"types"`
+		PrimaryType string                 `json:"primaryType"`
+		Domain      map[string]interface{} `json:"domain"`
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decode schema: %v", err)
+	}
+
+	td := &apitypes.TypedData{
+		Types:       raw.Types,
+		PrimaryType: raw.PrimaryType,
+	}
+	// TypedDataDomain only accepts a string chainId, but wallets and most
+	// examples use a number.
+	if n, ok := raw.Domain["chainId"].(json.Number); ok {
+		raw.Domain["chainId"] = n.String()
+	}
+	buf, err := json.Marshal(raw.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("re-encode domain: %v", err)
+	}
+	if err := json.Unmarshal(buf, &td.Domain); err != nil {
+		return nil, fmt.Errorf("decode domain: %v", err)
+	}
+
+	if td.PrimaryType == "" {
+		return nil, fmt.Errorf("schema missing primaryType")
+	}
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return nil, fmt.Errorf("schema types missing primaryType %q", td.PrimaryType)
+	}
+	if _, ok := td.Types["EIP712Domain"]; !ok {
+		if td.Types == nil {
+			td.Types = make(apitypes.Types)
+		}
+		td.Types["EIP712Domain"] = domainType(td.Domain)
+	}
+	return td, nil
+}
+
+// domainType returns the EIP712Domain type definition including only the
+// fields set in d.
+func domainType(d apitypes.TypedDataDomain) []apitypes.Type {
+	var t []apitypes.Type
+	if d.Name != "" {
+		t = append(t, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if d.Version != "" {
+		t = append(t, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if d.ChainId != nil {
+		t = append(t, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if d.VerifyingContract != "" {
+		t = append(t, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if d.Salt != "" {
+		t = append(t, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return t
+}
+
+// readTypedDataMessages parses either a single JSON object or an array of them
+// from r.
+func readTypedDataMessages(r io.Reader) ([]apitypes.TypedDataMessage, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read data: %v", err)
+	}
+	buf = bytes.TrimSpace(buf)
+
+	if len(buf) > 0 && buf[0] == '[' {
+		var msgs []apitypes.TypedDataMessage
+		if err := json.Unmarshal(buf, &msgs); err != nil {
+			return nil, fmt.Errorf("decode data: %v", err)
+		}
+		return msgs, nil
+	}
+
+	var msg apitypes.TypedDataMessage
+	if err := json.Unmarshal(buf, &msg); err != nil {
+		return nil, fmt.Errorf("decode data: %v", err)
+	}
+	return []apitypes.TypedDataMessage{msg}, nil
+}
+
+// signTypedDataList signs every message as the schema's primary type.
+func signTypedDataList(signer eth.SignerBackend, schema *apitypes.TypedData, msgs []apitypes.TypedDataMessage) (*signedTypedDataList, error) {
+	list := &signedTypedDataList{
+		Signer:      signer.Address(),
+		Types:       schema.Types,
+		PrimaryType: schema.PrimaryType,
+		Domain:      schema.Domain,
+	}
+
+	for i, m := range msgs {
+		td := *schema
+		td.Message = m
+
+		digest, err := eth.TypedDataDigest(td)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %v", i, err)
+		}
+		sig, err := signer.SignTypedData(td)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: sign: %v", i, err)
+		}
+		// Backends MAY return compact signatures.
+		if sig, err = eth.NormalizeSignature(sig); err != nil {
+			return nil, fmt.Errorf("message %d: %v", i, err)
+		}
+		list.Entries = append(list.Entries, signedTypedData{
+			Message:   m,
+			Digest:    digest,
+			Signature: sig,
+		})
+	}
+	return list, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestSignTypedDataList(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	const schemaJSON = `{
+  "types": {
+    "Voucher": [
+      {"name": "to", "type": "address"},
+      {"name": "amount", "type": "uint256"}
+    ]
+  },
+  "primaryType": "Voucher",
+  "domain": {
+    "name": "Test",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  }
+}`
+	schema, err := readTypedDataSchema(strings.NewReader(schemaJSON))
+	if err != nil {
+		t.Fatalf("readTypedDataSchema() error %v", err)
+	}
+	if diff := cmp.Diff(eth.EIP712DomainType, schema.Types["EIP712Domain"]); diff != "" {
+		t.Errorf("readTypedDataSchema() derived EIP712Domain diff (-want +got):\n%s", diff)
+	}
+
+	const dataJSON = `[
+  {"to": "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "amount": "1000000000000000000"},
+  {"to": "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", "amount": 42}
+]`
+	msgs, err := readTypedDataMessages(strings.NewReader(dataJSON))
+	if err != nil {
+		t.Fatalf("readTypedDataMessages() error %v", err)
+	}
+
+	list, err := signTypedDataList(signer, schema, msgs)
+	if err != nil {
+		t.Fatalf("signTypedDataList() error %v", err)
+	}
+	if got, want := len(list.Entries), 2; got != want {
+		t.Fatalf("signTypedDataList() got %d entries; want %d", got, want)
+	}
+
+	domain := eth.EIP712Domain("Test", "1", big.NewInt(1), common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"))
+	for i, e := range list.Entries {
+		td := apitypes.TypedData{
+			Types: apitypes.Types{
+				"EIP712Domain": eth.EIP712DomainType,
+				"Voucher":      schema.Types["Voucher"],
+			},
+			PrimaryType: "Voucher",
+			Domain:      domain,
+			Message:     msgs[i],
+		}
+		digest, err := eth.TypedDataDigest(td)
+		if err != nil {
+			t.Fatalf("eth.TypedDataDigest() error %v", err)
+		}
+		if diff := cmp.Diff(digest, []byte(e.Digest)); diff != "" {
+			t.Errorf("entry %d digest diff (-want +got):\n%s", i, diff)
+		}
+		if got, want := len(e.Signature), 65; got != want {
+			t.Errorf("entry %d signature length got %d; want %d", i, got, want)
+		}
+		got, err := eth.RecoverAddress(digest, e.Signature)
+		if err != nil {
+			t.Fatalf("eth.RecoverAddress() error %v", err)
+		}
+		if want := signer.Address(); got != want {
+			t.Errorf("entry %d signature recovered %v; want %v", i, got, want)
+		}
+	}
+
+	t.Run("single message", func(t *testing.T) {
+		msgs, err := readTypedDataMessages(strings.NewReader(`{"to": "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "amount": 1}`))
+		if err != nil {
+			t.Fatalf("readTypedDataMessages() error %v", err)
+		}
+		if got, want := len(msgs), 1; got != want {
+			t.Errorf("readTypedDataMessages() got %d messages; want %d", got, want)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, s := range []string{
+			`{"types": {"Voucher": []}, "domain": {"name": "x"}}`,
+			`{"types": {}, "primaryType": "Voucher", "domain": {"name": "x"}}`,
+			`{"types": {"Voucher": []}, "primaryType": "Voucher", "message": {}}`,
+		} {
+			if _, err := readTypedDataSchema(strings.NewReader(s)); err == nil {
+				t.Errorf("readTypedDataSchema(%s) got nil error; want error", s)
+			}
+		}
+
+		msgs, err := readTypedDataMessages(strings.NewReader(`{"to": "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}`))
+		if err != nil {
+			t.Fatalf("readTypedDataMessages() error %v", err)
+		}
+		if _, err := signTypedDataList(signer, schema, msgs); err == nil {
+			t.Error("signTypedDataList() with missing field got nil error; want error")
+		}
+	})
+}
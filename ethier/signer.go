@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/eth/interactive"
+	"github.com/divergencetech/ethier/eth/kms"
+	"github.com/divergencetech/ethier/eth/remote"
+)
+
+// Flags selecting the signing key; see addSignerFlags().
+const (
+	privateKeyFlag     = "private-key"
+	keyFileFlag        = "key-file"
+	keystoreFlag       = "keystore"
+	passwordFileFlag   = "password-file"
+	accountFlag        = "account"
+	keystoreDirFlag    = "keystore-dir"
+	mnemonicFlag       = "mnemonic"
+	derivationPathFlag = "derivation-path"
+	kmsFlag            = "kms"
+	remoteFlag         = "remote"
+	remoteProtocolFlag = "remote-protocol"
+	remoteAddressFlag  = "remote-address"
+	interactiveFlag    = "interactive"
+)
+
+// addSignerFlags adds flags to the command for selecting an existing signing
+// key, which is then loaded with signerFromFlags().
+func addSignerFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.String(privateKeyFlag, "", "Hex-encoded private key; prefer --key-file or --keystore as command-line arguments may be logged")
+	f.String(keyFileFlag, "", "File containing a hex-encoded private key")
+	f.String(keystoreFlag, "", "Encrypted JSON keystore file, as produced by geth and most wallets; requires --password-file")
+	f.String(passwordFileFlag, "", "File containing the password with which to decrypt --keystore or --account")
+	f.String(accountFlag, "", "Address of a key in --keystore-dir, as managed by ethier wallet; requires --password-file")
+	f.String(keystoreDirFlag, defaultKeystoreDir(), "Directory of encrypted keys used with --account")
+	f.String(mnemonicFlag, "", "BIP39 mnemonic from which to derive the key at --derivation-path")
+	f.String(derivationPathFlag, string(eth.DefaultHDPathPrefix)+"0", "Derivation path used with --mnemonic")
+	f.String(kmsFlag, "", "Cloud KMS key URI; aws://<key ID, ARN, or alias> or gcp://projects/…/cryptoKeys/…")
+	f.String(remoteFlag, "", "URL of a remote signer, e.g. clef (which also supports hardware wallets) or Web3Signer; requires --remote-address")
+	f.String(remoteProtocolFlag, "clef", "Protocol of the --remote signer: clef or web3signer")
+	f.String(remoteAddressFlag, "", "Address of the account to use with --remote")
+	f.String(interactiveFlag, "", "Loopback address, e.g. localhost:8546, on which to serve a page through which a browser wallet approves every signature")
+}
+
+// signerFromFlags returns the signing key selected by the flags added with
+// addSignerFlags(), of which at most one source may be set. If none are set, a
+// new random key is generated and its address logged, which is only useful
+// when the signatures are verified against the logged address.
+func signerFromFlags(cmd *cobra.Command) (eth.SignerBackend, error) {
+	b, err := existingSignerFromFlags(cmd)
+	if err != errNoSigner {
+		return b, err
+	}
+	s, err := eth.NewSigner(256)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("No key specified; generated new signer %v", s.Address())
+	return s, nil
+}
+
+// errNoSigner is returned by existingSignerFromFlags() if none of the signer
+// flags are set.
+var errNoSigner = fmt.Errorf("no signer specified; set one of --%s, --%s, --%s, --%s, --%s, --%s, --%s, or --%s", privateKeyFlag, keyFileFlag, keystoreFlag, accountFlag, mnemonicFlag, kmsFlag, remoteFlag, interactiveFlag)
+
+// existingSignerFromFlags is equivalent to signerFromFlags() except that it
+// returns errNoSigner instead of generating a new key, for commands such as
+// `ethier send` that require an existing, funded account.
+func existingSignerFromFlags(cmd *cobra.Command) (eth.SignerBackend, error) {
+	f := cmd.Flags()
+	get := func(name string) string {
+		// The flags are always registered by addSignerFlags() so there's no
+		// error to propagate.
+		v, _ := f.GetString(name)
+		return v
+	}
+
+	var set []string
+	for _, name := range []string{privateKeyFlag, keyFileFlag, keystoreFlag, accountFlag, mnemonicFlag, kmsFlag, remoteFlag, interactiveFlag} {
+		if get(name) != "" {
+			set = append(set, "--"+name)
+		}
+	}
+	if len(set) > 1 {
+		return nil, fmt.Errorf("mutually exclusive key flags %s", strings.Join(set, ", "))
+	}
+
+	switch {
+	case get(privateKeyFlag) != "":
+		return eth.NewSignerFromHex(get(privateKeyFlag))
+
+	case get(keyFileFlag) != "":
+		buf, err := os.ReadFile(get(keyFileFlag))
+		if err != nil {
+			return nil, fmt.Errorf("read --%s: %v", keyFileFlag, err)
+		}
+		return eth.NewSignerFromHex(string(buf))
+
+	case get(keystoreFlag) != "":
+		if get(passwordFileFlag) == "" {
+			return nil, fmt.Errorf("--%s requires --%s", keystoreFlag, passwordFileFlag)
+		}
+		pw, err := readPasswordFile(get(passwordFileFlag))
+		if err != nil {
+			return nil, err
+		}
+		return eth.NewSignerFromKeystore(get(keystoreFlag), pw)
+
+	case get(accountFlag) != "":
+		if get(passwordFileFlag) == "" {
+			return nil, fmt.Errorf("--%s requires --%s", accountFlag, passwordFileFlag)
+		}
+		addr, err := eth.ParseAddress(get(accountFlag))
+		if err != nil {
+			return nil, fmt.Errorf("--%s: %v", accountFlag, err)
+		}
+		path, err := findWalletKey(get(keystoreDirFlag), addr)
+		if err != nil {
+			return nil, err
+		}
+		pw, err := readPasswordFile(get(passwordFileFlag))
+		if err != nil {
+			return nil, err
+		}
+		return eth.NewSignerFromKeystore(path, pw)
+
+	case get(mnemonicFlag) != "":
+		return eth.NewSignerFromMnemonic(get(mnemonicFlag), get(derivationPathFlag))
+
+	case get(kmsFlag) != "":
+		return kms.FromURI(context.Background(), get(kmsFlag))
+
+	case get(remoteFlag) != "":
+		var proto remote.Protocol
+		switch p := get(remoteProtocolFlag); p {
+		case "clef":
+			proto = remote.Clef
+		case "web3signer":
+			proto = remote.Web3Signer
+		default:
+			return nil, fmt.Errorf("unsupported --%s %q", remoteProtocolFlag, p)
+		}
+		if get(remoteAddressFlag) == "" {
+			return nil, fmt.Errorf("--%s requires --%s", remoteFlag, remoteAddressFlag)
+		}
+		addr, err := eth.ParseAddress(get(remoteAddressFlag))
+		if err != nil {
+			return nil, fmt.Errorf("--%s: %v", remoteAddressFlag, err)
+		}
+		return remote.Dial(context.Background(), get(remoteFlag), proto, addr)
+
+	case get(interactiveFlag) != "":
+		return interactiveSigner(get(interactiveFlag), cmd.CommandPath())
+
+	default:
+		return nil, errNoSigner
+	}
+}
+
+// interactiveSigner serves an interactive.Session on addr and blocks until a
+// browser wallet connects to it, returning a Signer for the wallet's account.
+// The description is shown alongside every signature request.
+func interactiveSigner(addr, description string) (eth.SignerBackend, error) {
+	session, err := interactive.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	l, err := interactive.Listen(addr)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %v", interactiveFlag, err)
+	}
+	go func() {
+		if err := http.Serve(l, session); err != nil {
+			log.Printf("Interactive signing session: %v", err)
+		}
+	}()
+
+	log.Printf("Open http://%s in a browser with a wallet to connect it and approve signatures", l.Addr())
+	s, err := session.Connect(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	s.Description = description
+	log.Printf("Connected to wallet account %v", s.Address())
+	return s, nil
+}
+
+// readPasswordFile returns the contents of the file with any trailing newline
+// removed.
+func readPasswordFile(path string) (string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read password: %v", err)
+	}
+	return strings.TrimRight(string(buf), "\r\n"), nil
+}
@@ -0,0 +1,800 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/chainlinktest"
+	"github.com/divergencetech/ethier/ethtest/openseatest"
+	"github.com/divergencetech/ethier/ethtest/wethtest"
+)
+
+func init() {
+	const short = "Serves an ethtest simulated backend over JSON-RPC as a local development chain."
+
+	cmd := &cobra.Command{
+		Use:   "sim",
+		Short: short,
+		Long: short + `
+
+The chain is the same as that used by Go tests with ethtest.NewSimulatedBackend(), including chain ID 1337 and 100 ETH for each of --accounts. By default the accounts are identical to those in tests; with --mnemonic they are instead derived from it, at m/44'/60'/0'/0/i, so they can be imported into a wallet. All accounts are unlocked for eth_sendTransaction.
+
+Every transaction is mined immediately in its own block. Only the latest block's state can be queried, but all blocks, transactions, receipts, and logs are available. The evm_mine and evm_increaseTime methods are also supported.
+
+With --preload, test doubles are deployed at the same addresses as in tests: opensea for the Wyvern proxy registry, chainlink for the LINK token and a VRF coordinator that fulfils requests, and weth for wETH.`,
+		RunE: simulate,
+		Args: cobra.NoArgs,
+	}
+	cmd.Flags().Int("accounts", 10, "Number of pre-funded accounts")
+	cmd.Flags().String("mnemonic", "", "BIP39 mnemonic from which accounts are derived")
+	cmd.Flags().String("host", "127.0.0.1", "Interface on which to listen")
+	cmd.Flags().Int("port", 8545, "Port on which to listen")
+	cmd.Flags().StringSlice("preload", nil, "Test doubles to deploy: opensea, chainlink, and/or weth")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// simulate implements the `ethier sim` command.
+func simulate(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	n, err := flags.GetInt("accounts")
+	if err != nil {
+		return err
+	}
+	mnemonic, err := flags.GetString("mnemonic")
+	if err != nil {
+		return err
+	}
+	host, err := flags.GetString("host")
+	if err != nil {
+		return err
+	}
+	port, err := flags.GetInt("port")
+	if err != nil {
+		return err
+	}
+	preload, err := flags.GetStringSlice("preload")
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return fmt.Errorf("--accounts must be positive; got %d", n)
+	}
+
+	var sim *ethtest.SimulatedBackend
+	if mnemonic == "" {
+		sim, err = ethtest.NewSimulatedBackend(n)
+	} else {
+		var accounts []*bind.TransactOpts
+		accounts, err = mnemonicAccounts(mnemonic, n)
+		if err == nil {
+			sim, err = ethtest.NewSimulatedBackendWithAccounts(accounts)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	defer sim.Close()
+
+	fmt.Println("Chain ID 1337; accounts with 100 ETH each:")
+	for i := 0; i < n; i++ {
+		fmt.Printf("  (%d) %v\n", i, sim.Addr(i))
+	}
+
+	preloaded, closePreloaded, err := preloadSim(sim, preload)
+	if err != nil {
+		return err
+	}
+	defer closePreloaded()
+	if len(preloaded) > 0 {
+		fmt.Println("Preloaded contracts:")
+		for _, p := range preloaded {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+
+	srv, err := newSimServer(sim, n)
+	if err != nil {
+		return err
+	}
+	defer srv.Stop()
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	httpSrv := &http.Server{
+		Handler: allowCORS(srv),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpSrv.Shutdown(shutdown)
+	}()
+
+	fmt.Printf("Listening on http://%s\n", l.Addr())
+	if err := httpSrv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// allowCORS wraps the handler, allowing requests from any origin so that
+// browser-based frontends can connect.
+func allowCORS(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "*")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// mnemonicAccounts returns the first n accounts derived from the mnemonic,
+// signing for the simulated backend's chain ID.
+func mnemonicAccounts(mnemonic string, n int) ([]*bind.TransactOpts, error) {
+	accounts := make([]*bind.TransactOpts, n)
+	for i := range accounts {
+		s, err := eth.DefaultHDPathPrefix.SignerFromSeedPhrase(mnemonic, "", uint(i))
+		if err != nil {
+			return nil, fmt.Errorf("--mnemonic: %v", err)
+		}
+		if accounts[i], err = s.TransactorWithChainID(big.NewInt(1337)); err != nil {
+			return nil, err
+		}
+	}
+	return accounts, nil
+}
+
+// preloadSim deploys the named test doubles, returning descriptions of the
+// deployed contracts and a function to release resources.
+func preloadSim(sim *ethtest.SimulatedBackend, names []string) ([]string, func(), error) {
+	var (
+		deployed []string
+		closers  []func() error
+	)
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	for _, name := range names {
+		switch name {
+		case "opensea":
+			if err := openseatest.DeployProxyRegistry(sim); err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			deployed = append(deployed, fmt.Sprintf("OpenSea proxy registry %v", openseatest.ProxyRegistryAddress()))
+
+		case "chainlink":
+			vrf, err := chainlinktest.DeployAll(sim)
+			if err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			closers = append(closers, vrf.Close)
+			addrs := chainlinktest.Addresses()
+			deployed = append(deployed,
+				fmt.Sprintf("Chainlink LINK token %v", addrs.LinkToken),
+				fmt.Sprintf("Chainlink VRF coordinator %v", addrs.VRFCoordinator),
+			)
+
+		case "weth":
+			if _, err := wethtest.DeployWETH(sim); err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			deployed = append(deployed, fmt.Sprintf("wETH %v", wethtest.Address()))
+
+		default:
+			closeAll()
+			return nil, nil, fmt.Errorf("unsupported --preload %q", name)
+		}
+	}
+	return deployed, closeAll, nil
+}
+
+// newSimServer returns an RPC server backed by the simulated backend, with its
+// first numAccounts accounts unlocked.
+func newSimServer(sim *ethtest.SimulatedBackend, numAccounts int) (*rpc.Server, error) {
+	api := &simAPI{
+		sim:      sim,
+		chainID:  big.NewInt(1337),
+		accounts: make(map[common.Address]*bind.TransactOpts),
+	}
+	for i := 0; i < numAccounts; i++ {
+		acc := sim.Acc(i)
+		api.order = append(api.order, acc.From)
+		api.accounts[acc.From] = acc
+	}
+
+	srv := rpc.NewServer()
+	for ns, svc := range map[string]interface{}{
+		"eth":  api,
+		"net":  &simNetAPI{},
+		"web3": &simWeb3API{},
+		"evm":  &simEVMAPI{api},
+	} {
+		if err := srv.RegisterName(ns, svc); err != nil {
+			return nil, fmt.Errorf("register %q RPC namespace: %v", ns, err)
+		}
+	}
+	return srv, nil
+}
+
+// simNetAPI implements the net RPC namespace.
+type simNetAPI struct{}
+
+// Version implements net_version.
+func (*simNetAPI) Version() string {
+	return "1337"
+}
+
+// simWeb3API implements the web3 RPC namespace.
+type simWeb3API struct{}
+
+// ClientVersion implements web3_clientVersion.
+func (*simWeb3API) ClientVersion() string {
+	return "ethier/sim"
+}
+
+// simEVMAPI implements the evm RPC namespace, as supported by Hardhat and
+// Anvil.
+type simEVMAPI struct {
+	api *simAPI
+}
+
+// Mine implements evm_mine, committing an empty block.
+func (e *simEVMAPI) Mine() {
+	e.api.mu.Lock()
+	defer e.api.mu.Unlock()
+	e.api.sim.Commit()
+}
+
+// IncreaseTime implements evm_increaseTime, committing a block with its
+// timestamp advanced by the number of seconds.
+func (e *simEVMAPI) IncreaseTime(seconds int64) error {
+	e.api.mu.Lock()
+	defer e.api.mu.Unlock()
+	if err := e.api.sim.AdjustTime(time.Duration(seconds) * time.Second); err != nil {
+		return err
+	}
+	e.api.sim.Commit()
+	return nil
+}
+
+// simAPI implements the eth RPC namespace.
+type simAPI struct {
+	sim     *ethtest.SimulatedBackend
+	chainID *big.Int
+	// mu serialises transactions, as the nonces of those sent with
+	// eth_sendTransaction are determined before sending.
+	mu       sync.Mutex
+	accounts map[common.Address]*bind.TransactOpts
+	order    []common.Address
+}
+
+// ChainId implements eth_chainId.
+func (a *simAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(a.chainID)
+}
+
+// BlockNumber implements eth_blockNumber.
+func (a *simAPI) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(a.sim.BlockNumber().Uint64())
+}
+
+// Syncing implements eth_syncing.
+func (a *simAPI) Syncing() bool {
+	return false
+}
+
+// Accounts implements eth_accounts.
+func (a *simAPI) Accounts() []common.Address {
+	return a.order
+}
+
+// GasPrice implements eth_gasPrice.
+func (a *simAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
+	p, err := a.sim.SuggestGasPrice(ctx)
+	return (*hexutil.Big)(p), err
+}
+
+// MaxPriorityFeePerGas implements eth_maxPriorityFeePerGas.
+func (a *simAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	tip, err := a.sim.SuggestGasTipCap(ctx)
+	return (*hexutil.Big)(tip), err
+}
+
+// blockNumber converts a block number or hash into the argument expected by
+// the simulated backend, which only supports the latest block; nil denotes
+// latest.
+func (a *simAPI) blockNumber(ctx context.Context, bnh *rpc.BlockNumberOrHash) (*big.Int, error) {
+	if bnh == nil {
+		return nil, nil
+	}
+	if h, ok := bnh.Hash(); ok {
+		head, err := a.sim.HeaderByHash(ctx, h)
+		if err != nil {
+			return nil, err
+		}
+		return head.Number, nil
+	}
+	if n, ok := bnh.Number(); ok && n >= 0 {
+		return big.NewInt(n.Int64()), nil
+	}
+	return nil, nil
+}
+
+// GetBalance implements eth_getBalance.
+func (a *simAPI) GetBalance(ctx context.Context, addr common.Address, bnh *rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	num, err := a.blockNumber(ctx, bnh)
+	if err != nil {
+		return nil, err
+	}
+	bal, err := a.sim.BalanceAt(ctx, addr, num)
+	return (*hexutil.Big)(bal), err
+}
+
+// GetCode implements eth_getCode.
+func (a *simAPI) GetCode(ctx context.Context, addr common.Address, bnh *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	num, err := a.blockNumber(ctx, bnh)
+	if err != nil {
+		return nil, err
+	}
+	return a.sim.CodeAt(ctx, addr, num)
+}
+
+// GetStorageAt implements eth_getStorageAt.
+func (a *simAPI) GetStorageAt(ctx context.Context, addr common.Address, slot string, bnh *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	num, err := a.blockNumber(ctx, bnh)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hexutil.DecodeBig(slot)
+	if err != nil {
+		return nil, fmt.Errorf("storage slot %q: %v", slot, err)
+	}
+	return a.sim.StorageAt(ctx, addr, common.BigToHash(key), num)
+}
+
+// GetTransactionCount implements eth_getTransactionCount.
+func (a *simAPI) GetTransactionCount(ctx context.Context, addr common.Address, bnh *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	if bnh != nil {
+		if n, ok := bnh.Number(); ok && n == rpc.PendingBlockNumber {
+			nonce, err := a.sim.PendingNonceAt(ctx, addr)
+			return hexutil.Uint64(nonce), err
+		}
+	}
+	num, err := a.blockNumber(ctx, bnh)
+	if err != nil {
+		return 0, err
+	}
+	nonce, err := a.sim.NonceAt(ctx, addr, num)
+	return hexutil.Uint64(nonce), err
+}
+
+// simTxArgs are the arguments to eth_call, eth_estimateGas, and
+// eth_sendTransaction.
+type simTxArgs struct {
+	From                 *common.Address `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  *hexutil.Uint64 `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                *hexutil.Uint64 `json:"nonce"`
+	Data                 *hexutil.Bytes  `json:"data"`
+	Input                *hexutil.Bytes  `json:"input"`
+}
+
+// data returns the input, preferring the input field over data.
+func (args *simTxArgs) data() []byte {
+	switch {
+	case args.Input != nil:
+		return *args.Input
+	case args.Data != nil:
+		return *args.Data
+	default:
+		return nil
+	}
+}
+
+// callMsg converts the arguments to a CallMsg.
+func (args *simTxArgs) callMsg() ethereum.CallMsg {
+	msg := ethereum.CallMsg{
+		To:        args.To,
+		GasPrice:  (*big.Int)(args.GasPrice),
+		GasFeeCap: (*big.Int)(args.MaxFeePerGas),
+		GasTipCap: (*big.Int)(args.MaxPriorityFeePerGas),
+		Value:     (*big.Int)(args.Value),
+		Data:      args.data(),
+	}
+	if args.From != nil {
+		msg.From = *args.From
+	}
+	if args.Gas != nil {
+		msg.Gas = uint64(*args.Gas)
+	}
+	return msg
+}
+
+// Call implements eth_call.
+func (a *simAPI) Call(ctx context.Context, args simTxArgs, bnh *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	num, err := a.blockNumber(ctx, bnh)
+	if err != nil {
+		return nil, err
+	}
+	return a.sim.CallContract(ctx, args.callMsg(), num)
+}
+
+// EstimateGas implements eth_estimateGas.
+func (a *simAPI) EstimateGas(ctx context.Context, args simTxArgs, _ *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	gas, err := a.sim.EstimateGas(ctx, args.callMsg())
+	return hexutil.Uint64(gas), err
+}
+
+// SendRawTransaction implements eth_sendRawTransaction.
+func (a *simAPI) SendRawTransaction(ctx context.Context, raw hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.sim.SendTransaction(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+// SendTransaction implements eth_sendTransaction, signing with one of the
+// backend's accounts.
+func (a *simAPI) SendTransaction(ctx context.Context, args simTxArgs) (common.Hash, error) {
+	if args.From == nil {
+		return common.Hash{}, errors.New("missing from address")
+	}
+	acc, ok := a.accounts[*args.From]
+	if !ok {
+		return common.Hash{}, fmt.Errorf("unknown account %v", *args.From)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var nonce uint64
+	if args.Nonce != nil {
+		nonce = uint64(*args.Nonce)
+	} else {
+		n, err := a.sim.PendingNonceAt(ctx, acc.From)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		nonce = n
+	}
+
+	var gas uint64
+	if args.Gas != nil {
+		gas = uint64(*args.Gas)
+	} else {
+		g, err := a.sim.EstimateGas(ctx, args.callMsg())
+		if err != nil {
+			return common.Hash{}, err
+		}
+		gas = g
+	}
+
+	var tx *types.Transaction
+	if args.GasPrice != nil {
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: (*big.Int)(args.GasPrice),
+			Gas:      gas,
+			To:       args.To,
+			Value:    (*big.Int)(args.Value),
+			Data:     args.data(),
+		})
+	} else {
+		tip := (*big.Int)(args.MaxPriorityFeePerGas)
+		if tip == nil {
+			t, err := a.sim.SuggestGasTipCap(ctx)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			tip = t
+		}
+		feeCap := (*big.Int)(args.MaxFeePerGas)
+		if feeCap == nil {
+			head, err := a.sim.HeaderByNumber(ctx, nil)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			feeCap = new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+		}
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   a.chainID,
+			Nonce:     nonce,
+			GasTipCap: tip,
+			GasFeeCap: feeCap,
+			Gas:       gas,
+			To:        args.To,
+			Value:     (*big.Int)(args.Value),
+			Data:      args.data(),
+		})
+	}
+
+	signed, err := acc.Signer(acc.From, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := a.sim.SendTransaction(ctx, signed); err != nil {
+		return common.Hash{}, err
+	}
+	return signed.Hash(), nil
+}
+
+// GetTransactionByHash implements eth_getTransactionByHash, returning nil if
+// the transaction is unknown.
+func (a *simAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	tx, _, err := a.sim.TransactionByHash(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.sim.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return a.txFields(tx, nil, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	head, err := a.sim.HeaderByHash(ctx, r.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	return a.txFields(tx, head, r.TransactionIndex)
+}
+
+// txFields returns the JSON-RPC representation of the transaction, which is
+// at the index in the block with the header, if non-nil.
+func (a *simAPI) txFields(tx *types.Transaction, head *types.Header, index uint) (map[string]interface{}, error) {
+	buf, err := tx.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		return nil, err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(a.chainID), tx)
+	if err != nil {
+		return nil, err
+	}
+	fields["from"] = from
+	fields["blockHash"] = nil
+	fields["blockNumber"] = nil
+	fields["transactionIndex"] = nil
+	if head != nil {
+		fields["blockHash"] = head.Hash()
+		fields["blockNumber"] = (*hexutil.Big)(head.Number)
+		fields["transactionIndex"] = hexutil.Uint64(index)
+		fields["gasPrice"] = (*hexutil.Big)(effectiveGasPrice(tx, head.BaseFee))
+	}
+	return fields, nil
+}
+
+// effectiveGasPrice returns the price per unit of gas paid by the transaction
+// in a block with the base fee.
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return tx.GasPrice()
+	}
+	p := new(big.Int).Add(tx.GasTipCap(), baseFee)
+	if p.Cmp(tx.GasFeeCap()) > 0 {
+		return tx.GasFeeCap()
+	}
+	return p
+}
+
+// GetTransactionReceipt implements eth_getTransactionReceipt, returning nil
+// if the transaction is unknown.
+func (a *simAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	r, err := a.sim.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	tx, _, err := a.sim.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	head, err := a.sim.HeaderByHash(ctx, r.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(a.chainID), tx)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := r.Logs
+	if logs == nil {
+		logs = []*types.Log{}
+	}
+	fields := map[string]interface{}{
+		"blockHash":         r.BlockHash,
+		"blockNumber":       (*hexutil.Big)(r.BlockNumber),
+		"transactionHash":   hash,
+		"transactionIndex":  hexutil.Uint64(r.TransactionIndex),
+		"from":              from,
+		"to":                tx.To(),
+		"gasUsed":           hexutil.Uint64(r.GasUsed),
+		"cumulativeGasUsed": hexutil.Uint64(r.CumulativeGasUsed),
+		"effectiveGasPrice": (*hexutil.Big)(effectiveGasPrice(tx, head.BaseFee)),
+		"contractAddress":   nil,
+		"logs":              logs,
+		"logsBloom":         r.Bloom,
+		"type":              hexutil.Uint(tx.Type()),
+		"status":            hexutil.Uint(r.Status),
+	}
+	if r.ContractAddress != (common.Address{}) {
+		fields["contractAddress"] = r.ContractAddress
+	}
+	return fields, nil
+}
+
+// GetBlockByNumber implements eth_getBlockByNumber, returning nil if the block
+// doesn't exist.
+func (a *simAPI) GetBlockByNumber(ctx context.Context, num rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	var n *big.Int
+	if num >= 0 {
+		n = big.NewInt(num.Int64())
+	}
+	b, err := a.sim.BlockByNumber(ctx, n)
+	if err != nil {
+		return nil, nil
+	}
+	return a.blockFields(b, fullTx)
+}
+
+// GetBlockByHash implements eth_getBlockByHash, returning nil if the block
+// doesn't exist.
+func (a *simAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	b, err := a.sim.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, nil
+	}
+	return a.blockFields(b, fullTx)
+}
+
+// blockFields returns the JSON-RPC representation of the block, with either
+// full transactions or only their hashes.
+func (a *simAPI) blockFields(b *types.Block, fullTx bool) (map[string]interface{}, error) {
+	head := b.Header()
+	buf, err := head.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		return nil, err
+	}
+
+	txs := make([]interface{}, len(b.Transactions()))
+	for i, tx := range b.Transactions() {
+		if !fullTx {
+			txs[i] = tx.Hash()
+			continue
+		}
+		if txs[i], err = a.txFields(tx, head, uint(i)); err != nil {
+			return nil, err
+		}
+	}
+	fields["transactions"] = txs
+	fields["uncles"] = []common.Hash{}
+	fields["size"] = hexutil.Uint64(b.Size())
+	fields["totalDifficulty"] = (*hexutil.Big)(a.sim.Blockchain().GetTd(b.Hash(), b.NumberU64()))
+	return fields, nil
+}
+
+// simFilterArgs are the arguments to eth_getLogs.
+type simFilterArgs struct {
+	BlockHash *common.Hash       `json:"blockHash"`
+	FromBlock *rpc.BlockNumber   `json:"fromBlock"`
+	ToBlock   *rpc.BlockNumber   `json:"toBlock"`
+	Address   json.RawMessage    `json:"address"`
+	Topics    []*json.RawMessage `json:"topics"`
+}
+
+// query converts the arguments to a FilterQuery, with missing and tagged block
+// numbers resolved to the latest block.
+func (args *simFilterArgs) query(latest *big.Int) (ethereum.FilterQuery, error) {
+	q := ethereum.FilterQuery{BlockHash: args.BlockHash}
+	if args.BlockHash == nil {
+		resolve := func(n *rpc.BlockNumber) *big.Int {
+			if n == nil || *n < 0 {
+				return latest
+			}
+			return big.NewInt(n.Int64())
+		}
+		q.FromBlock = resolve(args.FromBlock)
+		q.ToBlock = resolve(args.ToBlock)
+	}
+
+	if len(args.Address) > 0 && string(args.Address) != "null" {
+		var one common.Address
+		if err := json.Unmarshal(args.Address, &one); err == nil {
+			q.Addresses = []common.Address{one}
+		} else if err := json.Unmarshal(args.Address, &q.Addresses); err != nil {
+			return q, fmt.Errorf("address: %v", err)
+		}
+	}
+
+	for i, raw := range args.Topics {
+		var pos []common.Hash
+		if raw != nil && string(*raw) != "null" {
+			var one common.Hash
+			if err := json.Unmarshal(*raw, &one); err == nil {
+				pos = []common.Hash{one}
+			} else if err := json.Unmarshal(*raw, &pos); err != nil {
+				return q, fmt.Errorf("topic %d: %v", i, err)
+			}
+		}
+		q.Topics = append(q.Topics, pos)
+	}
+	return q, nil
+}
+
+// GetLogs implements eth_getLogs.
+func (a *simAPI) GetLogs(ctx context.Context, args simFilterArgs) ([]types.Log, error) {
+	q, err := args.query(a.sim.BlockNumber())
+	if err != nil {
+		return nil, err
+	}
+	logs, err := a.sim.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		logs = []types.Log{}
+	}
+	return logs, nil
+}
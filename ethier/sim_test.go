@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+)
+
+func TestSimServer(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, 2)
+
+	srv, err := newSimServer(sim, 2)
+	if err != nil {
+		t.Fatalf("newSimServer() error %v", err)
+	}
+	t.Cleanup(srv.Stop)
+	rpcClient := rpc.DialInProc(srv)
+	t.Cleanup(rpcClient.Close)
+	client := ethclient.NewClient(rpcClient)
+
+	if id, err := client.ChainID(ctx); err != nil || id.Int64() != 1337 {
+		t.Errorf("ChainID() got %v, err %v; want 1337, nil", id, err)
+	}
+
+	var accounts []common.Address
+	if err := rpcClient.CallContext(ctx, &accounts, "eth_accounts"); err != nil {
+		t.Fatalf("eth_accounts error %v", err)
+	}
+	if len(accounts) != 2 || accounts[0] != sim.Addr(0) || accounts[1] != sim.Addr(1) {
+		t.Fatalf("eth_accounts got %v; want [%v %v]", accounts, sim.Addr(0), sim.Addr(1))
+	}
+
+	// Deploy a contract that emits an empty LOG0 whenever called, using an
+	// unlocked account.
+	//   init:    PUSH1 6 PUSH1 12 PUSH1 0 CODECOPY PUSH1 6 PUSH1 0 RETURN
+	//   runtime: PUSH1 0 PUSH1 0 LOG0 STOP
+	code := hexutil.Bytes(common.FromHex("0x6006600c60003960066000f3" + "60006000a000"))
+	var deployHash common.Hash
+	if err := rpcClient.CallContext(ctx, &deployHash, "eth_sendTransaction", map[string]interface{}{
+		"from": accounts[0],
+		"data": code,
+	}); err != nil {
+		t.Fatalf("eth_sendTransaction(<deploy>) error %v", err)
+	}
+	deployRcpt, err := client.TransactionReceipt(ctx, deployHash)
+	if err != nil {
+		t.Fatalf("TransactionReceipt(<deploy>) error %v", err)
+	}
+	if deployRcpt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("deployment failed")
+	}
+	contract := deployRcpt.ContractAddress
+	if got, err := client.CodeAt(ctx, contract, nil); err != nil || len(got) != 6 {
+		t.Errorf("CodeAt(<deployed>) got %x, err %v; want 6 bytes", got, err)
+	}
+
+	// Call it with a raw transaction signed by the other account.
+	nonce, err := client.PendingNonceAt(ctx, accounts[1])
+	if err != nil {
+		t.Fatalf("PendingNonceAt() error %v", err)
+	}
+	tx, err := sim.Acc(1).Signer(accounts[1], types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1337),
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100 * params.GWei),
+		Gas:       100_000,
+		To:        &contract,
+		Value:     eth.Ether(1),
+	}))
+	if err != nil {
+		t.Fatalf("sign transaction error %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("SendTransaction() error %v", err)
+	}
+
+	rcpt, err := client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatalf("TransactionReceipt() error %v", err)
+	}
+	if rcpt.Status != types.ReceiptStatusSuccessful || len(rcpt.Logs) != 1 {
+		t.Errorf("TransactionReceipt() got status %d with %d logs; want success with 1 log", rcpt.Status, len(rcpt.Logs))
+	}
+	if bal, err := client.BalanceAt(ctx, contract, nil); err != nil || bal.Cmp(eth.Ether(1)) != 0 {
+		t.Errorf("BalanceAt(<contract>) got %v, err %v; want 1 ETH", bal, err)
+	}
+
+	got, pending, err := client.TransactionByHash(ctx, tx.Hash())
+	if err != nil || pending || got.Hash() != tx.Hash() {
+		t.Errorf("TransactionByHash() got %v, pending %t, err %v", got, pending, err)
+	}
+	if missing, _, err := client.TransactionByHash(ctx, common.Hash{1}); err != ethereum.NotFound {
+		t.Errorf("TransactionByHash(<unknown>) got %v, err %v; want err %v", missing, err, ethereum.NotFound)
+	}
+
+	// ethclient verifies that the transactions and header match the block's
+	// hash.
+	block, err := client.BlockByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("BlockByNumber(latest) error %v", err)
+	}
+	if n := len(block.Transactions()); n != 1 || block.Transactions()[0].Hash() != tx.Hash() {
+		t.Errorf("BlockByNumber(latest) got %d transactions; want only %v", n, tx.Hash())
+	}
+	if head, err := client.HeaderByHash(ctx, rcpt.BlockHash); err != nil || head.Number.Cmp(rcpt.BlockNumber) != 0 {
+		t.Errorf("HeaderByHash(%v) got %v, err %v; want number %v", rcpt.BlockHash, head, err, rcpt.BlockNumber)
+	}
+
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: big.NewInt(0),
+		Addresses: []common.Address{contract},
+	})
+	if err != nil {
+		t.Fatalf("FilterLogs() error %v", err)
+	}
+	if len(logs) != 1 || logs[0].TxHash != tx.Hash() {
+		t.Errorf("FilterLogs() got %+v; want single log from %v", logs, tx.Hash())
+	}
+	if logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{contract}}); err != nil || len(logs) != 1 {
+		t.Errorf("FilterLogs(<latest block>) got %d logs, err %v; want 1", len(logs), err)
+	}
+
+	before := sim.BlockNumber().Uint64()
+	if err := rpcClient.CallContext(ctx, nil, "evm_mine"); err != nil {
+		t.Fatalf("evm_mine error %v", err)
+	}
+	if got, err := client.BlockNumber(ctx); err != nil || got != before+1 {
+		t.Errorf("BlockNumber() after evm_mine got %d, err %v; want %d", got, err, before+1)
+	}
+
+	t.Run("unknown account", func(t *testing.T) {
+		err := rpcClient.CallContext(ctx, nil, "eth_sendTransaction", map[string]interface{}{
+			"from": common.HexToAddress("0x01"),
+			"to":   accounts[0],
+		})
+		if err == nil {
+			t.Errorf("eth_sendTransaction(<from unknown account>) got nil error")
+		}
+	})
+}
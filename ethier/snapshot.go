@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Reconstructs ERC20 or ERC721 holder balances, at a block, from Transfer logs."
+
+	cmd := &cobra.Command{
+		Use:   "snapshot --token <address>",
+		Short: short,
+		Long: short + `
+
+ERC20 and ERC721 Transfer events share a signature and are distinguished by the number of indexed topics, so the token standard is detected automatically. The zero address, to and from which tokens are minted and burned, is never included as a holder. Holders are sorted by decreasing balance.
+
+Output is one of:
+  csv:        address,balance columns, plus space-separated tokens for ERC721, as accepted by ethier sign addresses --format csv;
+  json:       an array of objects with the same fields; or
+  addresses:  one address per line, as accepted by ethier merkle and ethier sign addresses.
+
+Logs are requested in ranges of at most --batch-size blocks, so setting --from-block to the token's deployment block greatly reduces the number of requests.`,
+		RunE: snapshot,
+		Args: cobra.NoArgs,
+	}
+	addRPCFlag(cmd)
+	cmd.Flags().String("token", "", "Address of the token contract")
+	cmd.Flags().Int64("block", -1, "Block at which to snapshot balances; -1 = latest")
+	cmd.Flags().Uint64("from-block", 0, "First block from which to read logs, e.g. that of the token's deployment")
+	cmd.Flags().Uint64("batch-size", 2000, "Maximum number of blocks per log request")
+	cmd.Flags().String("format", "csv", "Output format: csv, json, or addresses")
+	cmd.Flags().String("min-balance", "1", "Minimum balance for a holder to be included")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// snapshot implements the `ethier snapshot` command.
+func snapshot(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	tokenHex, err := flags.GetString("token")
+	if err != nil {
+		return err
+	}
+	block, err := flags.GetInt64("block")
+	if err != nil {
+		return err
+	}
+	from, err := flags.GetUint64("from-block")
+	if err != nil {
+		return err
+	}
+	batch, err := flags.GetUint64("batch-size")
+	if err != nil {
+		return err
+	}
+	format, err := flags.GetString("format")
+	if err != nil {
+		return err
+	}
+	minStr, err := flags.GetString("min-balance")
+	if err != nil {
+		return err
+	}
+
+	token, err := eth.ParseAddress(tokenHex)
+	if err != nil {
+		return fmt.Errorf("--token: %v", err)
+	}
+	min, ok := new(big.Int).SetString(minStr, 0)
+	if !ok {
+		return fmt.Errorf("invalid --min-balance %q", minStr)
+	}
+	switch format {
+	case "csv", "json", "addresses":
+	default:
+		return fmt.Errorf("unsupported --format %q", format)
+	}
+
+	ctx := context.Background()
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	s := &logStreamer{
+		client: client,
+		query: ethereum.FilterQuery{
+			Addresses: []common.Address{token},
+			Topics:    [][]common.Hash{{transferTopic}},
+		},
+		batch: batch,
+		poll:  time.Second,
+	}
+	if block >= 0 {
+		last := uint64(block)
+		s.to = &last
+	}
+
+	h := newHolderSnapshot()
+	if err := s.stream(ctx, from, h.apply); err != nil {
+		return err
+	}
+	return h.write(os.Stdout, format, min)
+}
+
+// transferTopic is the topic of both ERC20 and ERC721 Transfer events.
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// A holderSnapshot accumulates token balances from Transfer logs.
+type holderSnapshot struct {
+	// erc20 and erc721 record which standards' Transfer logs have been seen;
+	// mixing them is an error.
+	erc20, erc721 bool
+
+	balances map[common.Address]*big.Int
+	// owners and ids are keyed by the decimal ERC721 token ID.
+	owners map[string]common.Address
+	ids    map[string]*big.Int
+}
+
+func newHolderSnapshot() *holderSnapshot {
+	return &holderSnapshot{
+		balances: make(map[common.Address]*big.Int),
+		owners:   make(map[string]common.Address),
+		ids:      make(map[string]*big.Int),
+	}
+}
+
+// apply updates balances with the Transfer log.
+func (h *holderSnapshot) apply(l types.Log) error {
+	if l.Removed || len(l.Topics) == 0 || l.Topics[0] != transferTopic {
+		return nil
+	}
+
+	var amount *big.Int
+	switch len(l.Topics) {
+	case 3: // ERC20
+		if len(l.Data) != 32 {
+			return fmt.Errorf("ERC20 Transfer log in tx %v has %d bytes of data; want 32", l.TxHash, len(l.Data))
+		}
+		h.erc20 = true
+		amount = new(big.Int).SetBytes(l.Data)
+	case 4: // ERC721
+		h.erc721 = true
+		amount = big.NewInt(1)
+		id := new(big.Int).SetBytes(l.Topics[3][:])
+		key := id.String()
+		h.owners[key] = common.BytesToAddress(l.Topics[2][:])
+		h.ids[key] = id
+	default:
+		return fmt.Errorf("Transfer log in tx %v has %d topics; want 3 (ERC20) or 4 (ERC721)", l.TxHash, len(l.Topics))
+	}
+	if h.erc20 && h.erc721 {
+		return fmt.Errorf("both ERC20 and ERC721 Transfer logs; last in tx %v", l.TxHash)
+	}
+
+	from := common.BytesToAddress(l.Topics[1][:])
+	to := common.BytesToAddress(l.Topics[2][:])
+	h.add(from, new(big.Int).Neg(amount))
+	h.add(to, amount)
+	return nil
+}
+
+// add adds delta to the balance of addr, unless it's the zero address.
+func (h *holderSnapshot) add(addr common.Address, delta *big.Int) {
+	if addr == (common.Address{}) {
+		return
+	}
+	b, ok := h.balances[addr]
+	if !ok {
+		b = new(big.Int)
+		h.balances[addr] = b
+	}
+	b.Add(b, delta)
+}
+
+// A holder is a single entry of a snapshot's output.
+type holder struct {
+	Address common.Address `json:"address"`
+	Balance *big.Int       `json:"balance"`
+	// Tokens are the IDs of ERC721 tokens owned by the holder.
+	Tokens []*big.Int `json:"tokens,omitempty"`
+}
+
+// holders returns all holders with at least the minimum balance, sorted by
+// decreasing balance and then by address.
+func (h *holderSnapshot) holders(min *big.Int) []*holder {
+	tokens := make(map[common.Address][]*big.Int)
+	for key, owner := range h.owners {
+		tokens[owner] = append(tokens[owner], h.ids[key])
+	}
+
+	var out []*holder
+	for addr, bal := range h.balances {
+		if bal.Sign() <= 0 || bal.Cmp(min) == -1 {
+			continue
+		}
+		ids := tokens[addr]
+		sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) == -1 })
+		out = append(out, &holder{
+			Address: addr,
+			Balance: bal,
+			Tokens:  ids,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if c := out[i].Balance.Cmp(out[j].Balance); c != 0 {
+			return c == 1
+		}
+		return bytes.Compare(out[i].Address[:], out[j].Address[:]) == -1
+	})
+	return out
+}
+
+// write writes the holders, with at least the minimum balance, in the format.
+func (h *holderSnapshot) write(w io.Writer, format string, min *big.Int) error {
+	holders := h.holders(min)
+
+	switch format {
+	case "json":
+		if holders == nil {
+			holders = []*holder{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(holders)
+
+	case "addresses":
+		for _, hl := range holders {
+			if _, err := fmt.Fprintln(w, hl.Address.Hex()); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "csv":
+		out := csv.NewWriter(w)
+		header := []string{"address", "balance"}
+		if h.erc721 {
+			header = append(header, "tokens")
+		}
+		if err := out.Write(header); err != nil {
+			return err
+		}
+		for _, hl := range holders {
+			row := []string{hl.Address.Hex(), hl.Balance.String()}
+			if h.erc721 {
+				ids := make([]string, len(hl.Tokens))
+				for i, id := range hl.Tokens {
+					ids[i] = id.String()
+				}
+				row = append(row, strings.Join(ids, " "))
+			}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+		out.Flush()
+		return out.Error()
+
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHolderSnapshot(t *testing.T) {
+	var zero common.Address
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	carol := common.HexToAddress("0xca")
+
+	erc20 := func(from, to common.Address, amount int64) types.Log {
+		return types.Log{
+			Topics: []common.Hash{transferTopic, from.Hash(), to.Hash()},
+			Data:   common.BigToHash(big.NewInt(amount)).Bytes(),
+		}
+	}
+	erc721 := func(from, to common.Address, id int64) types.Log {
+		return types.Log{
+			Topics: []common.Hash{transferTopic, from.Hash(), to.Hash(), common.BigToHash(big.NewInt(id))},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		logs   []types.Log
+		format string
+		min    int64
+		want   string
+	}{
+		{
+			name: "erc20",
+			logs: []types.Log{
+				erc20(zero, alice, 100),
+				erc20(alice, bob, 30),
+				erc20(zero, carol, 30),
+				erc20(alice, zero, 70), // burn
+			},
+			format: "csv",
+			min:    1,
+			want: `address,balance
+0x00000000000000000000000000000000000000B0,30
+0x00000000000000000000000000000000000000ca,30
+`,
+		},
+		{
+			name: "erc721",
+			logs: []types.Log{
+				erc721(zero, alice, 1),
+				erc721(zero, alice, 2),
+				erc721(zero, alice, 3),
+				erc721(alice, bob, 2),
+				erc721(zero, carol, 4),
+			},
+			format: "csv",
+			min:    1,
+			want: `address,balance,tokens
+0x00000000000000000000000000000000000000A1,2,1 3
+0x00000000000000000000000000000000000000B0,1,2
+0x00000000000000000000000000000000000000ca,1,4
+`,
+		},
+		{
+			name: "min balance",
+			logs: []types.Log{
+				erc721(zero, alice, 1),
+				erc721(zero, alice, 2),
+				erc721(zero, bob, 3),
+			},
+			format: "addresses",
+			min:    2,
+			want:   "0x00000000000000000000000000000000000000A1\n",
+		},
+		{
+			name: "json",
+			logs: []types.Log{
+				erc721(zero, alice, 7),
+				{Topics: []common.Hash{transferTopic, zero.Hash(), bob.Hash(), common.BigToHash(big.NewInt(8))}, Removed: true},
+			},
+			format: "json",
+			min:    1,
+			want: `[
+  {
+    "address": "0x00000000000000000000000000000000000000a1",
+    "balance": 1,
+    "tokens": [
+      7
+    ]
+  }
+]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHolderSnapshot()
+			for _, l := range tt.logs {
+				if err := h.apply(l); err != nil {
+					t.Fatalf("apply(%+v) error %v", l, err)
+				}
+			}
+			var buf bytes.Buffer
+			if err := h.write(&buf, tt.format, big.NewInt(tt.min)); err != nil {
+				t.Fatalf("write(%q) error %v", tt.format, err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("write(%q) diff (-want +got):\n%s", tt.format, diff)
+			}
+		})
+	}
+
+	t.Run("mixed standards", func(t *testing.T) {
+		h := newHolderSnapshot()
+		if err := h.apply(erc20(zero, alice, 1)); err != nil {
+			t.Fatalf("apply(ERC20) error %v", err)
+		}
+		if err := h.apply(erc721(zero, alice, 1)); err == nil {
+			t.Errorf("apply(ERC721) after ERC20 got nil error")
+		}
+	})
+}
@@ -0,0 +1,647 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Reads, and decodes, contract storage slots."
+
+	cmd := &cobra.Command{
+		Use:   "storage <address>",
+		Short: short,
+		Long: short + `
+
+The --layout file is the storage layout output by solc (--storage-layout), either on its own or as the storageLayout field of a build artifact. With a layout, --slot is a path to a variable, e.g. owner, _owners[42], config.price, or balances[0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed]; string and bytes mapping keys MAY be quoted. Structs and arrays are printed as JSON. Without --slot, every top-level variable other than mappings is printed.
+
+Without a layout, --slot is a raw slot number, e.g. the EIP-1967 implementation slot, and the 32-byte value is printed as hex.`,
+		RunE: storage,
+		Args: cobra.ExactArgs(1),
+	}
+	addRPCFlag(cmd)
+	cmd.Flags().String("layout", "", "JSON file containing the solc storage layout of the contract")
+	cmd.Flags().String("slot", "", "Path to the variable, or raw slot number without --layout")
+	cmd.Flags().Int64("block", -1, "Block number at which to read storage; -1 = latest")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// storage implements the `ethier storage` command.
+func storage(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	layoutPath, err := flags.GetString("layout")
+	if err != nil {
+		return err
+	}
+	path, err := flags.GetString("slot")
+	if err != nil {
+		return err
+	}
+	block, err := flags.GetInt64("block")
+	if err != nil {
+		return err
+	}
+
+	addr, err := eth.ParseAddress(args[0])
+	if err != nil {
+		return fmt.Errorf("contract address: %v", err)
+	}
+	if layoutPath == "" && path == "" {
+		return errors.New("--slot is required without --layout")
+	}
+
+	var layout *storageLayout
+	if layoutPath != "" {
+		f, err := os.Open(layoutPath)
+		if err != nil {
+			return fmt.Errorf("open --layout: %v", err)
+		}
+		defer f.Close()
+		if layout, err = readStorageLayout(f); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	client, err := dialFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	s := &storageDecoder{
+		client:  client,
+		address: addr,
+		layout:  layout,
+	}
+	if block >= 0 {
+		s.block = big.NewInt(block)
+	}
+
+	if layout == nil {
+		slot, ok := math.ParseBig256(path)
+		if !ok {
+			return fmt.Errorf("invalid --slot %q; paths to variables require --layout", path)
+		}
+		val, err := s.read(ctx, slot)
+		if err != nil {
+			return err
+		}
+		fmt.Println(common.BytesToHash(val).Hex())
+		return nil
+	}
+
+	if path != "" {
+		v, err := s.decodePath(ctx, path)
+		if err != nil {
+			return err
+		}
+		return printStorageValue("", v)
+	}
+
+	for _, v := range layout.Storage {
+		if layout.Types[v.Type].Encoding == "mapping" {
+			continue
+		}
+		val, err := s.decode(ctx, layout.location(big.NewInt(0), v))
+		if err != nil {
+			return fmt.Errorf("%s: %v", v.Label, err)
+		}
+		if err := printStorageValue(v.Label, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printStorageValue prints a value returned by storageDecoder.decode(),
+// prefixed by the label and a tab if non-empty. Composite values are printed
+// as JSON.
+func printStorageValue(label string, v interface{}) error {
+	if label != "" {
+		fmt.Printf("%s\t", label)
+	}
+	if s, ok := v.(string); ok {
+		fmt.Println(s)
+		return nil
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal value: %v", err)
+	}
+	fmt.Println(string(buf))
+	return nil
+}
+
+// A storageLayout is the storage layout output by solc.
+type storageLayout struct {
+	Storage []storageVariable      `json:"storage"`
+	Types   map[string]storageType `json:"types"`
+}
+
+// A storageVariable is either a top-level state variable or a struct member,
+// in which case Slot and Offset are relative to the start of the struct.
+type storageVariable struct {
+	Label  string `json:"label"`
+	Offset int    `json:"offset"`
+	Slot   string `json:"slot"`
+	Type   string `json:"type"`
+}
+
+// A storageType describes a type referenced by a storageVariable.
+type storageType struct {
+	// Encoding is one of inplace, mapping, dynamic_array, or bytes.
+	Encoding      string `json:"encoding"`
+	Label         string `json:"label"`
+	NumberOfBytes string `json:"numberOfBytes"`
+	// Key and Value are only set for mappings.
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+	// Base is only set for arrays.
+	Base string `json:"base,omitempty"`
+	// Members is only set for structs.
+	Members []storageVariable `json:"members,omitempty"`
+}
+
+// size returns the number of bytes occupied by the type.
+func (t storageType) size() (int, error) {
+	n, err := strconv.Atoi(t.NumberOfBytes)
+	if err != nil {
+		return 0, fmt.Errorf("numberOfBytes of %s: %v", t.Label, err)
+	}
+	return n, nil
+}
+
+// readStorageLayout parses a storage layout from r, which MAY be either the
+// layout itself or an object with a storageLayout field.
+func readStorageLayout(r io.Reader) (*storageLayout, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read storage layout: %v", err)
+	}
+
+	var wrapped struct {
+		StorageLayout *storageLayout `json:"storageLayout"`
+		storageLayout
+	}
+	if err := json.Unmarshal(buf, &wrapped); err != nil {
+		return nil, fmt.Errorf("decode storage layout: %v", err)
+	}
+	l := &wrapped.storageLayout
+	if wrapped.StorageLayout != nil {
+		l = wrapped.StorageLayout
+	}
+	if l.Types == nil && len(l.Storage) > 0 {
+		return nil, errors.New("storage layout without types")
+	}
+	return l, nil
+}
+
+// A storageLocation is the position of a value in storage.
+type storageLocation struct {
+	slot *big.Int
+	// offset is the number of bytes from the least-significant end of the
+	// slot, as used by solc for packed values.
+	offset int
+	typ    string
+}
+
+// location returns the location of v, relative to base.
+func (l *storageLayout) location(base *big.Int, v storageVariable) storageLocation {
+	slot, ok := math.ParseBig256(v.Slot)
+	if !ok {
+		slot = new(big.Int)
+	}
+	return storageLocation{
+		slot:   slot.Add(slot, base),
+		offset: v.Offset,
+		typ:    v.Type,
+	}
+}
+
+// member returns the location of the named member of the struct at loc.
+func (l *storageLayout) member(loc storageLocation, name string) (storageLocation, error) {
+	t := l.Types[loc.typ]
+	for _, m := range t.Members {
+		if m.Label == name {
+			return l.location(loc.slot, m), nil
+		}
+	}
+	return storageLocation{}, fmt.Errorf("%s has no member %q", t.Label, name)
+}
+
+// element returns the location of the i'th element of an array starting at
+// base, with the specified element type.
+func (l *storageLayout) element(base *big.Int, elemType string, i uint64) (storageLocation, error) {
+	size, err := l.Types[elemType].size()
+	if err != nil {
+		return storageLocation{}, err
+	}
+	if size <= 0 {
+		return storageLocation{}, fmt.Errorf("invalid size %d of %s", size, l.Types[elemType].Label)
+	}
+
+	loc := storageLocation{typ: elemType}
+	idx := new(big.Int).SetUint64(i)
+	if size < 32 {
+		// Multiple elements are packed into each slot.
+		perSlot := uint64(32 / size)
+		loc.slot = idx.Div(idx, new(big.Int).SetUint64(perSlot))
+		loc.offset = int(i%perSlot) * size
+	} else {
+		loc.slot = idx.Mul(idx, big.NewInt(int64(size/32)))
+	}
+	loc.slot.Add(loc.slot, base)
+	return loc, nil
+}
+
+// A storageReader is the subset of a client required by a storageDecoder.
+type storageReader interface {
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+}
+
+// A storageDecoder reads values from the storage of a contract, decoding them
+// according to its layout.
+type storageDecoder struct {
+	client  storageReader
+	address common.Address
+	block   *big.Int
+	layout  *storageLayout
+}
+
+// read returns the raw, 32-byte value of the slot.
+func (s *storageDecoder) read(ctx context.Context, slot *big.Int) ([]byte, error) {
+	key := common.BigToHash(slot)
+	val, err := s.client.StorageAt(ctx, s.address, key, s.block)
+	if err != nil {
+		return nil, fmt.Errorf("read slot %v: %v", key, err)
+	}
+	return common.LeftPadBytes(val, 32), nil
+}
+
+// decodePath resolves and decodes the value at the path, e.g. a.b[1].c.
+func (s *storageDecoder) decodePath(ctx context.Context, path string) (interface{}, error) {
+	loc, err := s.resolve(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(ctx, loc)
+}
+
+// resolve returns the location of the value at the path.
+func (s *storageDecoder) resolve(ctx context.Context, path string) (storageLocation, error) {
+	root, segments, err := parseStoragePath(path)
+	if err != nil {
+		return storageLocation{}, err
+	}
+
+	var (
+		loc   storageLocation
+		found bool
+	)
+	for _, v := range s.layout.Storage {
+		if v.Label == root {
+			loc, found = s.layout.location(big.NewInt(0), v), true
+			break
+		}
+	}
+	if !found {
+		return storageLocation{}, fmt.Errorf("no state variable %q in storage layout", root)
+	}
+
+	for _, seg := range segments {
+		t, ok := s.layout.Types[loc.typ]
+		if !ok {
+			return storageLocation{}, fmt.Errorf("type %q not in storage layout", loc.typ)
+		}
+
+		if seg.member != "" {
+			if len(t.Members) == 0 {
+				return storageLocation{}, fmt.Errorf("%s is not a struct; can't access .%s", t.Label, seg.member)
+			}
+			if loc, err = s.layout.member(loc, seg.member); err != nil {
+				return storageLocation{}, err
+			}
+			continue
+		}
+
+		switch {
+		case t.Encoding == "mapping":
+			key, err := s.mappingKey(t.Key, seg.index)
+			if err != nil {
+				return storageLocation{}, err
+			}
+			slot := crypto.Keccak256(key, common.BigToHash(loc.slot).Bytes())
+			loc = storageLocation{
+				slot: new(big.Int).SetBytes(slot),
+				typ:  t.Value,
+			}
+
+		case t.Encoding == "dynamic_array" || t.Base != "":
+			i, err := strconv.ParseUint(seg.index, 0, 64)
+			if err != nil {
+				return storageLocation{}, fmt.Errorf("index %q of %s: %v", seg.index, t.Label, err)
+			}
+			n, err := s.arrayLength(ctx, loc)
+			if err != nil {
+				return storageLocation{}, err
+			}
+			if i >= n {
+				return storageLocation{}, fmt.Errorf("index %d out of bounds of %s with length %d", i, t.Label, n)
+			}
+			if loc, err = s.layout.element(s.arrayBase(loc), t.Base, i); err != nil {
+				return storageLocation{}, err
+			}
+
+		default:
+			return storageLocation{}, fmt.Errorf("%s is neither a mapping nor an array; can't index [%s]", t.Label, seg.index)
+		}
+	}
+	return loc, nil
+}
+
+// arrayBase returns the slot of the first element of the array at loc.
+func (s *storageDecoder) arrayBase(loc storageLocation) *big.Int {
+	if s.layout.Types[loc.typ].Encoding == "dynamic_array" {
+		return new(big.Int).SetBytes(crypto.Keccak256(common.BigToHash(loc.slot).Bytes()))
+	}
+	return loc.slot
+}
+
+// arrayLength returns the number of elements in the array at loc.
+func (s *storageDecoder) arrayLength(ctx context.Context, loc storageLocation) (uint64, error) {
+	t := s.layout.Types[loc.typ]
+	if t.Encoding == "dynamic_array" {
+		val, err := s.read(ctx, loc.slot)
+		if err != nil {
+			return 0, err
+		}
+		n := new(big.Int).SetBytes(val)
+		if !n.IsUint64() {
+			return 0, fmt.Errorf("length of %s overflows uint64", t.Label)
+		}
+		return n.Uint64(), nil
+	}
+
+	open := strings.LastIndexByte(t.Label, '[')
+	if open == -1 || !strings.HasSuffix(t.Label, "]") {
+		return 0, fmt.Errorf("can't determine length of %s", t.Label)
+	}
+	return strconv.ParseUint(t.Label[open+1:len(t.Label)-1], 10, 64)
+}
+
+// maxDecodedElements is the maximum number of array elements decoded in full;
+// longer arrays MUST be indexed.
+const maxDecodedElements = 256
+
+// decode returns the value at loc. Value types are returned as strings,
+// structs as map[string]interface{}, and arrays as []interface{}.
+func (s *storageDecoder) decode(ctx context.Context, loc storageLocation) (interface{}, error) {
+	t, ok := s.layout.Types[loc.typ]
+	if !ok {
+		return nil, fmt.Errorf("type %q not in storage layout", loc.typ)
+	}
+
+	switch {
+	case t.Encoding == "mapping":
+		return nil, fmt.Errorf("%s requires a key to be decoded", t.Label)
+
+	case t.Encoding == "bytes":
+		return s.decodeBytes(ctx, loc, t)
+
+	case len(t.Members) > 0:
+		out := make(map[string]interface{})
+		for _, m := range t.Members {
+			if s.layout.Types[m.Type].Encoding == "mapping" {
+				continue
+			}
+			v, err := s.decode(ctx, s.layout.location(loc.slot, m))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", m.Label, err)
+			}
+			out[m.Label] = v
+		}
+		return out, nil
+
+	case t.Encoding == "dynamic_array" || t.Base != "":
+		n, err := s.arrayLength(ctx, loc)
+		if err != nil {
+			return nil, err
+		}
+		if n > maxDecodedElements {
+			return nil, fmt.Errorf("%s has %d elements; index it to read individual ones", t.Label, n)
+		}
+		base := s.arrayBase(loc)
+		out := make([]interface{}, n)
+		for i := range out {
+			el, err := s.layout.element(base, t.Base, uint64(i))
+			if err != nil {
+				return nil, err
+			}
+			if out[i], err = s.decode(ctx, el); err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+		}
+		return out, nil
+	}
+
+	size, err := t.size()
+	if err != nil {
+		return nil, err
+	}
+	if loc.offset+size > 32 {
+		return nil, fmt.Errorf("%s of %d bytes at offset %d overflows slot", t.Label, size, loc.offset)
+	}
+	val, err := s.read(ctx, loc.slot)
+	if err != nil {
+		return nil, err
+	}
+	return decodeStorageValue(t.Label, val[32-loc.offset-size:32-loc.offset]), nil
+}
+
+// decodeBytes returns the bytes or string at loc, which MAY be stored either
+// in the same slot as its length, if short, or starting at keccak256(slot).
+func (s *storageDecoder) decodeBytes(ctx context.Context, loc storageLocation, t storageType) (string, error) {
+	val, err := s.read(ctx, loc.slot)
+	if err != nil {
+		return "", err
+	}
+
+	var data []byte
+	if val[31]&1 == 0 {
+		data = val[:val[31]/2]
+	} else {
+		n := new(big.Int).SetBytes(val)
+		n.Rsh(n, 1)
+		if !n.IsUint64() || n.Uint64() > 32*maxDecodedElements {
+			return "", fmt.Errorf("%s of length %v too long to decode", t.Label, n)
+		}
+		length := n.Uint64()
+
+		slot := new(big.Int).SetBytes(crypto.Keccak256(common.BigToHash(loc.slot).Bytes()))
+		for uint64(len(data)) < length {
+			v, err := s.read(ctx, slot)
+			if err != nil {
+				return "", err
+			}
+			data = append(data, v...)
+			slot.Add(slot, big.NewInt(1))
+		}
+		data = data[:length]
+	}
+
+	if t.Label == "string" {
+		return string(data), nil
+	}
+	return hexutil.Encode(data), nil
+}
+
+// decodeStorageValue returns a human-readable representation of the value
+// type with the specified label, e.g. uint256 or address, stored in buf.
+func decodeStorageValue(label string, buf []byte) string {
+	switch {
+	case strings.HasPrefix(label, "address"), strings.HasPrefix(label, "contract "):
+		return common.BytesToAddress(buf).Hex()
+	case label == "bool":
+		return strconv.FormatBool(len(buf) > 0 && buf[len(buf)-1] != 0)
+	case strings.HasPrefix(label, "uint"), strings.HasPrefix(label, "enum "):
+		return new(big.Int).SetBytes(buf).String()
+	case strings.HasPrefix(label, "int"):
+		n := new(big.Int).SetBytes(buf)
+		if len(buf) > 0 && buf[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(buf))))
+		}
+		return n.String()
+	}
+	return hexutil.Encode(buf)
+}
+
+// mappingKey returns the encoding of the key, of the specified type, that is
+// hashed with the mapping's slot to compute the slot of its value.
+func (s *storageDecoder) mappingKey(typ, key string) ([]byte, error) {
+	t, ok := s.layout.Types[typ]
+	if !ok {
+		return nil, fmt.Errorf("mapping key type %q not in storage layout", typ)
+	}
+	label := t.Label
+
+	if unquoted, err := strconv.Unquote(key); err == nil {
+		key = unquoted
+	}
+
+	switch {
+	case t.Encoding == "bytes":
+		// Dynamic keys are hashed without padding.
+		if label == "string" {
+			return []byte(key), nil
+		}
+		return hexutil.Decode(key)
+
+	case strings.HasPrefix(label, "address"), strings.HasPrefix(label, "contract "):
+		addr, err := eth.ParseAddress(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s key: %v", label, err)
+		}
+		return common.LeftPadBytes(addr.Bytes(), 32), nil
+
+	case label == "bool":
+		b, err := strconv.ParseBool(key)
+		if err != nil {
+			return nil, fmt.Errorf("bool key: %v", err)
+		}
+		if b {
+			return common.LeftPadBytes([]byte{1}, 32), nil
+		}
+		return make([]byte, 32), nil
+
+	case strings.HasPrefix(label, "uint"), strings.HasPrefix(label, "int"), strings.HasPrefix(label, "enum "):
+		n, ok := new(big.Int).SetString(key, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s key %q", label, key)
+		}
+		if n.Sign() == -1 && !strings.HasPrefix(label, "int") {
+			return nil, fmt.Errorf("negative %s key %q", label, key)
+		}
+		return math.U256Bytes(n), nil
+
+	case strings.HasPrefix(label, "bytes"):
+		b, err := hexutil.Decode(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s key: %v", label, err)
+		}
+		if len(b) > 32 {
+			return nil, fmt.Errorf("%s key %q longer than 32 bytes", label, key)
+		}
+		return common.RightPadBytes(b, 32), nil
+	}
+	return nil, fmt.Errorf("unsupported mapping key type %s", label)
+}
+
+// A storagePathSegment is either a struct member or an index into a mapping
+// or array.
+type storagePathSegment struct {
+	member, index string
+}
+
+// parseStoragePath splits a path of the form root.member[index]... into its
+// root variable and subsequent segments.
+func parseStoragePath(path string) (string, []storagePathSegment, error) {
+	path = strings.TrimSpace(path)
+	end := strings.IndexAny(path, ".[")
+	if end == -1 {
+		end = len(path)
+	}
+	root := path[:end]
+	if root == "" {
+		return "", nil, fmt.Errorf("storage path %q missing variable name", path)
+	}
+
+	var segs []storagePathSegment
+	for rest := path[end:]; rest != ""; {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			n := strings.IndexAny(rest, ".[")
+			if n == -1 {
+				n = len(rest)
+			}
+			if n == 0 {
+				return "", nil, fmt.Errorf("empty member name in storage path %q", path)
+			}
+			segs = append(segs, storagePathSegment{member: rest[:n]})
+			rest = rest[n:]
+
+		case '[':
+			close := strings.IndexByte(rest, ']')
+			if close == -1 {
+				return "", nil, fmt.Errorf("unclosed [ in storage path %q", path)
+			}
+			idx := strings.TrimSpace(rest[1:close])
+			if idx == "" {
+				return "", nil, fmt.Errorf("empty index in storage path %q", path)
+			}
+			segs = append(segs, storagePathSegment{index: idx})
+			rest = rest[close+1:]
+
+		default:
+			return "", nil, fmt.Errorf("unexpected %q in storage path %q", rest[0], path)
+		}
+	}
+	return root, segs, nil
+}
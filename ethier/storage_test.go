@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeStorage is a storageReader backed by a map of slots, ignoring account
+// and block number.
+type fakeStorage map[common.Hash][32]byte
+
+func (f fakeStorage) StorageAt(_ context.Context, _ common.Address, key common.Hash, _ *big.Int) ([]byte, error) {
+	v := f[key]
+	return v[:], nil
+}
+
+// set stores buf in the slot, offset bytes from its least-significant end.
+func (f fakeStorage) set(slot *big.Int, offset int, buf []byte) {
+	key := common.BigToHash(slot)
+	v := f[key]
+	copy(v[32-offset-len(buf):], buf)
+	f[key] = v
+}
+
+func slotOf(buf []byte) *big.Int {
+	return new(big.Int).SetBytes(buf)
+}
+
+// testStorageLayout is the solc output for:
+//
+//	struct Config { uint128 price; uint64 start; address beneficiary; }
+//	address owner;
+//	bool paused;
+//	int16 delta;
+//	mapping(uint256 => address) _owners;
+//	Config config;
+//	uint16[] nums;
+//	string name;
+//	string description;
+//	mapping(address => mapping(string => uint256)) nested;
+//	Config[2] configs;
+const testStorageLayout = `{
+	"storageLayout": {
+		"storage": [
+			{"label": "owner", "offset": 0, "slot": "0", "type": "t_address"},
+			{"label": "paused", "offset": 20, "slot": "0", "type": "t_bool"},
+			{"label": "delta", "offset": 21, "slot": "0", "type": "t_int16"},
+			{"label": "_owners", "offset": 0, "slot": "1", "type": "t_mapping(t_uint256,t_address)"},
+			{"label": "config", "offset": 0, "slot": "2", "type": "t_struct(Config)10_storage"},
+			{"label": "nums", "offset": 0, "slot": "4", "type": "t_array(t_uint16)dyn_storage"},
+			{"label": "name", "offset": 0, "slot": "5", "type": "t_string_storage"},
+			{"label": "description", "offset": 0, "slot": "6", "type": "t_string_storage"},
+			{"label": "nested", "offset": 0, "slot": "7", "type": "t_mapping(t_address,t_mapping(t_string_memory_ptr,t_uint256))"},
+			{"label": "configs", "offset": 0, "slot": "8", "type": "t_array(t_struct(Config)10_storage)2_storage"}
+		],
+		"types": {
+			"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+			"t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+			"t_int16": {"encoding": "inplace", "label": "int16", "numberOfBytes": "2"},
+			"t_uint16": {"encoding": "inplace", "label": "uint16", "numberOfBytes": "2"},
+			"t_uint64": {"encoding": "inplace", "label": "uint64", "numberOfBytes": "8"},
+			"t_uint128": {"encoding": "inplace", "label": "uint128", "numberOfBytes": "16"},
+			"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"},
+			"t_string_storage": {"encoding": "bytes", "label": "string", "numberOfBytes": "32"},
+			"t_string_memory_ptr": {"encoding": "bytes", "label": "string", "numberOfBytes": "32"},
+			"t_mapping(t_uint256,t_address)": {"encoding": "mapping", "key": "t_uint256", "label": "mapping(uint256 => address)", "numberOfBytes": "32", "value": "t_address"},
+			"t_mapping(t_string_memory_ptr,t_uint256)": {"encoding": "mapping", "key": "t_string_memory_ptr", "label": "mapping(string => uint256)", "numberOfBytes": "32", "value": "t_uint256"},
+			"t_mapping(t_address,t_mapping(t_string_memory_ptr,t_uint256))": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => mapping(string => uint256))", "numberOfBytes": "32", "value": "t_mapping(t_string_memory_ptr,t_uint256)"},
+			"t_array(t_uint16)dyn_storage": {"base": "t_uint16", "encoding": "dynamic_array", "label": "uint16[]", "numberOfBytes": "32"},
+			"t_array(t_struct(Config)10_storage)2_storage": {"base": "t_struct(Config)10_storage", "encoding": "inplace", "label": "struct Config[2]", "numberOfBytes": "128"},
+			"t_struct(Config)10_storage": {
+				"encoding": "inplace",
+				"label": "struct Config",
+				"numberOfBytes": "64",
+				"members": [
+					{"label": "price", "offset": 0, "slot": "0", "type": "t_uint128"},
+					{"label": "start", "offset": 16, "slot": "0", "type": "t_uint64"},
+					{"label": "beneficiary", "offset": 0, "slot": "1", "type": "t_address"}
+				]
+			}
+		}
+	}
+}`
+
+func TestStorageDecoder(t *testing.T) {
+	ctx := context.Background()
+	layout, err := readStorageLayout(strings.NewReader(testStorageLayout))
+	if err != nil {
+		t.Fatalf("readStorageLayout() error %v", err)
+	}
+
+	owner := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	holder := common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+	pad := func(b []byte) []byte {
+		return common.LeftPadBytes(b, 32)
+	}
+	word := func(n int64) []byte {
+		return pad(big.NewInt(n).Bytes())
+	}
+
+	st := make(fakeStorage)
+	st.set(big.NewInt(0), 0, owner.Bytes())
+	st.set(big.NewInt(0), 20, []byte{1})
+	st.set(big.NewInt(0), 21, []byte{0xff, 0xfe}) // -2
+
+	st.set(slotOf(crypto.Keccak256(word(42), word(1))), 0, holder.Bytes())
+
+	st.set(big.NewInt(2), 0, big.NewInt(1e18).Bytes())
+	st.set(big.NewInt(2), 16, big.NewInt(1650000000).Bytes())
+	st.set(big.NewInt(3), 0, holder.Bytes())
+
+	st.set(big.NewInt(4), 0, word(17))
+	numsBase := slotOf(crypto.Keccak256(word(4)))
+	for i := int64(0); i < 17; i++ {
+		slot := new(big.Int).Add(numsBase, big.NewInt(i/16))
+		st.set(slot, int(i%16)*2, big.NewInt(100+i).Bytes())
+	}
+
+	short := common.RightPadBytes([]byte("ethier"), 32)
+	short[31] = 2 * 6
+	st.set(big.NewInt(5), 0, short)
+
+	desc := strings.Repeat("A long description. ", 3)
+	st.set(big.NewInt(6), 0, word(int64(2*len(desc)+1)))
+	descBase := slotOf(crypto.Keccak256(word(6)))
+	for i := 0; i*32 < len(desc); i++ {
+		chunk := []byte(desc[i*32:])
+		if len(chunk) > 32 {
+			chunk = chunk[:32]
+		}
+		st.set(new(big.Int).Add(descBase, big.NewInt(int64(i))), 0, common.RightPadBytes(chunk, 32))
+	}
+
+	inner := crypto.Keccak256(pad(holder.Bytes()), word(7))
+	st.set(slotOf(crypto.Keccak256([]byte("key"), inner)), 0, word(99))
+
+	st.set(big.NewInt(10), 0, big.NewInt(5).Bytes())
+	st.set(big.NewInt(11), 0, owner.Bytes())
+
+	s := &storageDecoder{
+		client: st,
+		layout: layout,
+	}
+
+	nums := make([]interface{}, 17)
+	for i := range nums {
+		nums[i] = big.NewInt(int64(100 + i)).String()
+	}
+
+	tests := []struct {
+		path    string
+		want    interface{}
+		wantErr bool
+	}{
+		{path: "owner", want: owner.Hex()},
+		{path: "paused", want: "true"},
+		{path: "delta", want: "-2"},
+		{path: "_owners[42]", want: holder.Hex()},
+		{path: "_owners[0x2a]", want: holder.Hex()},
+		{path: "_owners[43]", want: common.Address{}.Hex()},
+		{path: "config.price", want: "1000000000000000000"},
+		{path: "config.start", want: "1650000000"},
+		{
+			path: "config",
+			want: map[string]interface{}{
+				"price":       "1000000000000000000",
+				"start":       "1650000000",
+				"beneficiary": holder.Hex(),
+			},
+		},
+		{path: "nums[0]", want: "100"},
+		{path: "nums[15]", want: "115"},
+		{path: "nums[16]", want: "116"},
+		{path: "nums", want: nums},
+		{path: "name", want: "ethier"},
+		{path: "description", want: desc},
+		{path: `nested[` + holder.Hex() + `]["key"]`, want: "99"},
+		{path: `nested[` + holder.Hex() + `][key]`, want: "99"},
+		{path: "configs[1].price", want: "5"},
+		{path: "configs[1].beneficiary", want: owner.Hex()},
+		{path: "configs[0].price", want: "0"},
+		{path: "nums[17]", wantErr: true},
+		{path: "configs[2]", wantErr: true},
+		{path: "_owners", wantErr: true},
+		{path: "owner[1]", wantErr: true},
+		{path: "config.missing", wantErr: true},
+		{path: "missing", wantErr: true},
+		{path: "config[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := s.decodePath(ctx, tt.path)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("decodePath(%q) got err %v; want error = %t", tt.path, err, tt.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("decodePath(%q) diff (-want +got):\n%s", tt.path, diff)
+		}
+	}
+}
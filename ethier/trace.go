@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	const short = "Traces a mined transaction and prints its call tree with decoded functions, values, gas, and revert reasons."
+
+	cmd := &cobra.Command{
+		Use:   "trace <tx hash>",
+		Short: short,
+		Long: short + `
+
+The transaction is re-executed by the --rpc node with debug_traceTransaction and the built-in callTracer, which the node MUST support. Every call, including delegate and static calls, and contract creation is printed on its own line, indented by depth, along with any value sent in ETH and the gas used out of that available.
+
+Calldata, return data, and custom errors are decoded with the ABIs in --bindings, each of which is a directory of Go bindings output by ethier gen, and in the --abi files, each of which MAY be either a JSON ABI or a build artifact with an "abi" field. Function names fall back to the same database of common signatures used by ethier tx decode, extended with --signatures. Revert reasons are decoded from Error(string) and Panic(uint256) as well as custom errors.`,
+		RunE: trace,
+		Args: cobra.ExactArgs(1),
+	}
+	addRPCFlag(cmd)
+	cmd.Flags().StringSlice("bindings", nil, "Directories of Go bindings, generated by ethier gen, from which to read ABIs")
+	cmd.Flags().StringSlice("abi", nil, "JSON files containing ABIs with which to decode calls")
+	cmd.Flags().String("signatures", "", "File of additional signatures, one per line, as for ethier selector")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// trace implements the `ethier trace` command.
+func trace(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	bindingDirs, err := flags.GetStringSlice("bindings")
+	if err != nil {
+		return err
+	}
+	abiPaths, err := flags.GetStringSlice("abi")
+	if err != nil {
+		return err
+	}
+	extra, err := flags.GetString("signatures")
+	if err != nil {
+		return err
+	}
+
+	hash, err := hexutil.Decode(args[0])
+	if err != nil || len(hash) != common.HashLength {
+		return fmt.Errorf("invalid transaction hash %q", args[0])
+	}
+
+	d := new(txDecoder)
+	if d.db, err = loadSignatureDB(extra); err != nil {
+		return err
+	}
+	for _, dir := range bindingDirs {
+		abis, err := bindingABIs(dir)
+		if err != nil {
+			return err
+		}
+		d.abis = append(d.abis, abis...)
+	}
+	for _, p := range abiPaths {
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		a, err := readABI(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", p, err)
+		}
+		d.abis = append(d.abis, a)
+	}
+
+	ctx := context.Background()
+	client, err := rpcClientFromFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var root callFrame
+	cfg := map[string]string{"tracer": "callTracer"}
+	if err := client.CallContext(ctx, &root, "debug_traceTransaction", common.BytesToHash(hash), cfg); err != nil {
+		return fmt.Errorf("debug_traceTransaction: %v", err)
+	}
+	return (&callTreeWriter{w: os.Stdout, d: d}).write(&root, 0)
+}
+
+// bindingABIs parses the Go files in dir and returns the ABIs of all non-empty
+// bind.MetaData literals, as generated by ethier gen.
+func bindingABIs(dir string) ([]*abi.ABI, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("find Go files in %q: %v", dir, err)
+	}
+
+	var (
+		abis   []*abi.ABI
+		abiErr error
+	)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("parse %q: %v", file, err)
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			kv, ok := n.(*ast.KeyValueExpr)
+			if !ok || abiErr != nil {
+				return abiErr == nil
+			}
+			if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "ABI" {
+				return true
+			}
+			lit, ok := kv.Value.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			raw, err := strconv.Unquote(lit.Value)
+			if err != nil || raw == "[]" {
+				return false
+			}
+			a, err := abi.JSON(strings.NewReader(raw))
+			if err != nil {
+				abiErr = fmt.Errorf("%s: parse ABI at %v: %v", file, fset.Position(lit.Pos()), err)
+				return false
+			}
+			abis = append(abis, &a)
+			return false
+		})
+		if abiErr != nil {
+			return nil, abiErr
+		}
+	}
+	return abis, nil
+}
+
+// A callFrame is a single call in the output of geth's callTracer.
+type callFrame struct {
+	Type    string          `json:"type"`
+	From    common.Address  `json:"from"`
+	To      *common.Address `json:"to"`
+	Value   *hexutil.Big    `json:"value"`
+	Gas     hexutil.Uint64  `json:"gas"`
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Input   hexutil.Bytes   `json:"input"`
+	Output  hexutil.Bytes   `json:"output"`
+	Error   string          `json:"error"`
+	// RevertReason is only populated by some versions of geth; it is otherwise
+	// decoded from Output.
+	RevertReason string       `json:"revertReason"`
+	Calls        []*callFrame `json:"calls"`
+}
+
+// A callTreeWriter writes callFrames as an indented tree.
+type callTreeWriter struct {
+	w io.Writer
+	d *txDecoder
+}
+
+// write writes the frame, and recursively its sub-calls, at the specified
+// depth.
+func (c *callTreeWriter) write(f *callFrame, depth int) error {
+	indent := strings.Repeat("  ", depth)
+
+	var line strings.Builder
+	fmt.Fprintf(&line, "%s%s", indent, f.Type)
+	if f.To != nil {
+		fmt.Fprintf(&line, " %v", *f.To)
+	}
+	switch {
+	case strings.HasPrefix(f.Type, "CREATE"):
+		fmt.Fprintf(&line, " (%d bytes of initcode)", len(f.Input))
+	case f.Type == "SELFDESTRUCT":
+	case len(f.Input) == 0:
+		line.WriteString(" (no calldata)")
+	default:
+		fmt.Fprintf(&line, " %s", c.call(f.Input))
+	}
+	if v := (*big.Int)(f.Value); v != nil && v.Sign() != 0 {
+		fmt.Fprintf(&line, " value %s ETH", formatDecimal(new(big.Rat).SetFrac(v, big.NewInt(1e18))))
+	}
+	fmt.Fprintf(&line, " gas %d/%d", f.GasUsed, f.Gas)
+	if _, err := fmt.Fprintln(c.w, line.String()); err != nil {
+		return err
+	}
+
+	for _, sub := range f.Calls {
+		if err := c.write(sub, depth+1); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch {
+	case f.Error != "":
+		_, err = fmt.Fprintf(c.w, "%s  %s\n", indent, c.failure(f))
+	case len(f.Output) > 0 && !strings.HasPrefix(f.Type, "CREATE"):
+		_, err = fmt.Fprintf(c.w, "%s  returned %s\n", indent, c.returned(f.Input, f.Output))
+	}
+	return err
+}
+
+// call returns the calldata formatted as a function call with named
+// arguments, falling back on the raw selector if it can't be decoded.
+func (c *callTreeWriter) call(data []byte) string {
+	call := c.d.decodeCall(data)
+	if call.Function == "" || call.Error != "" {
+		if len(data) < 4 {
+			return fmt.Sprintf("%#x", data)
+		}
+		return fmt.Sprintf("%#x(%d bytes)", data[:4], len(data)-4)
+	}
+
+	name := call.Function
+	if i := strings.IndexByte(name, '('); i != -1 {
+		name = name[:i]
+	}
+	args := make([]string, len(call.Args))
+	for i, a := range call.Args {
+		args[i] = a.Name + "=" + a.Value
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(args, ", "))
+}
+
+// returned returns the return data formatted as values of the outputs of the
+// called method if it's in one of the ABIs, otherwise as hex.
+func (c *callTreeWriter) returned(input, output []byte) string {
+	if len(input) >= 4 {
+		for _, a := range c.d.abis {
+			m, err := a.MethodById(input[:4])
+			if err != nil {
+				continue
+			}
+			vals, err := m.Outputs.Unpack(output)
+			if err != nil {
+				break
+			}
+			strs := make([]string, len(vals))
+			for i, v := range vals {
+				strs[i] = formatABIValue(v)
+			}
+			return "(" + strings.Join(strs, ", ") + ")"
+		}
+	}
+	return hexutil.Encode(output)
+}
+
+// failure returns a description of why the call failed, including the revert
+// reason if there is one.
+func (c *callTreeWriter) failure(f *callFrame) string {
+	if f.RevertReason != "" {
+		return fmt.Sprintf("REVERTED: %s", f.RevertReason)
+	}
+	if len(f.Output) == 0 {
+		return fmt.Sprintf("FAILED: %s", f.Error)
+	}
+	return fmt.Sprintf("REVERTED: %s", c.revertReason(f.Output))
+}
+
+// panicSelector is the selector of Panic(uint256), used by Solidity for
+// failed assertions, arithmetic overflow, etc.
+var panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+// revertReason decodes revert data as Error(string), Panic(uint256), or a
+// custom error in one of the ABIs, falling back to hex.
+func (c *callTreeWriter) revertReason(data []byte) string {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return strconv.Quote(reason)
+	}
+	if len(data) == 36 && bytes.Equal(data[:4], panicSelector) {
+		return fmt.Sprintf("Panic(%#x)", new(big.Int).SetBytes(data[4:]))
+	}
+
+	if len(data) >= 4 {
+		for _, a := range c.d.abis {
+			for _, e := range a.Errors {
+				if !bytes.Equal(data[:4], e.ID[:4]) {
+					continue
+				}
+				vals, err := e.Inputs.Unpack(data[4:])
+				if err != nil {
+					continue
+				}
+				args := make([]string, len(vals))
+				for i, v := range vals {
+					args[i] = e.Inputs[i].Name + "=" + formatABIValue(v)
+				}
+				return fmt.Sprintf("%s(%s)", e.Name, strings.Join(args, ", "))
+			}
+		}
+	}
+	return hexutil.Encode(data)
+}
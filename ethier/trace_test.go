@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/google/go-cmp/cmp"
+)
+
+const traceTestABI = `[
+  {"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+  {"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+  {"type":"error","name":"Insufficient","inputs":[{"name":"have","type":"uint256"},{"name":"want","type":"uint256"}]}
+]`
+
+func TestBindingABIs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "generated.go", "package gen\n\n"+
+		"import \"github.com/ethereum/go-ethereum/accounts/abi/bind\"\n\n"+
+		"var TokenMetaData = &bind.MetaData{\n\tABI: "+strconv.Quote(traceTestABI)+",\n}\n\n"+
+		"var EmptyMetaData = &bind.MetaData{\n\tABI: \"[]\",\n}\n")
+	writeFile(t, dir, "generated_test.go", "package gen\n\nvar x = struct{ ABI string }{ABI: \"invalid\"}\n")
+
+	abis, err := bindingABIs(dir)
+	if err != nil {
+		t.Fatalf("bindingABIs() error %v", err)
+	}
+	if len(abis) != 1 {
+		t.Fatalf("bindingABIs() got %d ABIs; want 1, ignoring empty ABIs and tests", len(abis))
+	}
+	if _, ok := abis[0].Methods["transfer"]; !ok {
+		t.Errorf("bindingABIs()[0] missing transfer method")
+	}
+	if _, ok := abis[0].Errors["Insufficient"]; !ok {
+		t.Errorf("bindingABIs()[0] missing Insufficient error")
+	}
+}
+
+func TestCallTree(t *testing.T) {
+	db, err := loadSignatureDB("")
+	if err != nil {
+		t.Fatalf("loadSignatureDB() error %v", err)
+	}
+	a, err := abi.JSON(strings.NewReader(traceTestABI))
+	if err != nil {
+		t.Fatalf("abi.JSON() error %v", err)
+	}
+
+	// Calldata and return data are those of:
+	//   transfer(0xb0, 42) -> true
+	//   balanceOf(0xa1) -> 7
+	//   approve(0xb0, 1) via the signature database, returning nothing
+	// and reverts are Error("nope"), Panic(0x11), and Insufficient(1, 2).
+	const frame = `{
+  "type": "CALL",
+  "from": "0x00000000000000000000000000000000000000a1",
+  "to": "0x0000000000000000000000000000000000000abc",
+  "value": "0xde0b6b3a7640000",
+  "gas": "0x7530",
+  "gasUsed": "0x3039",
+  "input": "0xa9059cbb00000000000000000000000000000000000000000000000000000000000000b0000000000000000000000000000000000000000000000000000000000000002a",
+  "output": "0x0000000000000000000000000000000000000000000000000000000000000001",
+  "calls": [
+    {
+      "type": "STATICCALL",
+      "from": "0x0000000000000000000000000000000000000abc",
+      "to": "0x0000000000000000000000000000000000000def",
+      "gas": "0x3e8",
+      "gasUsed": "0x64",
+      "input": "0x70a0823100000000000000000000000000000000000000000000000000000000000000a1",
+      "output": "0x0000000000000000000000000000000000000000000000000000000000000007"
+    },
+    {
+      "type": "CALL",
+      "from": "0x0000000000000000000000000000000000000abc",
+      "to": "0x0000000000000000000000000000000000000def",
+      "gas": "0x3e8",
+      "gasUsed": "0x64",
+      "input": "0x095ea7b300000000000000000000000000000000000000000000000000000000000000b00000000000000000000000000000000000000000000000000000000000000001",
+      "output": "0x08c379a0000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000046e6f706500000000000000000000000000000000000000000000000000000000",
+      "error": "execution reverted"
+    },
+    {
+      "type": "DELEGATECALL",
+      "from": "0x0000000000000000000000000000000000000abc",
+      "to": "0x0000000000000000000000000000000000000def",
+      "gas": "0x3e8",
+      "gasUsed": "0x64",
+      "input": "0xdeadbeef",
+      "output": "0x4e487b710000000000000000000000000000000000000000000000000000000000000011",
+      "error": "execution reverted"
+    },
+    {
+      "type": "CALL",
+      "from": "0x0000000000000000000000000000000000000abc",
+      "to": "0x0000000000000000000000000000000000000def",
+      "gas": "0x3e8",
+      "gasUsed": "0x64",
+      "input": "0x",
+      "output": "0xe862080000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002",
+      "error": "execution reverted"
+    },
+    {
+      "type": "CREATE2",
+      "from": "0x0000000000000000000000000000000000000abc",
+      "to": "0x0000000000000000000000000000000000000123",
+      "gas": "0x3e8",
+      "gasUsed": "0x3e8",
+      "input": "0x6000",
+      "error": "out of gas"
+    }
+  ]
+}`
+
+	var root callFrame
+	if err := json.Unmarshal([]byte(frame), &root); err != nil {
+		t.Fatalf("json.Unmarshal(%T) error %v", &root, err)
+	}
+
+	var buf bytes.Buffer
+	c := &callTreeWriter{w: &buf, d: &txDecoder{abis: []*abi.ABI{&a}, db: db}}
+	if err := c.write(&root, 0); err != nil {
+		t.Fatalf("write() error %v", err)
+	}
+
+	want := `CALL 0x0000000000000000000000000000000000000aBc transfer(to=0x00000000000000000000000000000000000000B0, amount=42) value 1 ETH gas 12345/30000
+  STATICCALL 0x0000000000000000000000000000000000000deF balanceOf(owner=0x00000000000000000000000000000000000000A1) gas 100/1000
+    returned (7)
+  CALL 0x0000000000000000000000000000000000000deF approve(arg0=0x00000000000000000000000000000000000000B0, arg1=1) gas 100/1000
+    REVERTED: "nope"
+  DELEGATECALL 0x0000000000000000000000000000000000000deF 0xdeadbeef(0 bytes) gas 100/1000
+    REVERTED: Panic(0x11)
+  CALL 0x0000000000000000000000000000000000000deF (no calldata) gas 100/1000
+    REVERTED: Insufficient(have=1, want=2)
+  CREATE2 0x0000000000000000000000000000000000000123 (2 bytes of initcode) gas 1000/1000
+    FAILED: out of gas
+  returned (true)
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("write() diff (-want +got):\n%s", diff)
+	}
+}
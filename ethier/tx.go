@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+// txCmd is the parent of all `ethier tx` subcommands.
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Inspects transactions",
+}
+
+func init() {
+	const short = "Decodes a transaction's fields, calldata, and, if mined, its receipt and logs."
+
+	cmd := &cobra.Command{
+		Use:   "decode <raw tx> | --hash <hash>",
+		Short: short,
+		Long: short + `
+
+The transaction is either a hex-encoded signed transaction, as passed to eth_sendRawTransaction, or fetched by --hash from the --rpc node, in which case its receipt is also included if it has been mined.
+
+Calldata and logs are decoded with the --abi files, each of which MAY be either a JSON ABI or a build artifact with an "abi" field, falling back to the same database of common signatures used by ethier selector --reverse, extended with --signatures. Signatures from the database are only used for calldata if re-encoding the decoded arguments reproduces the calldata exactly; other signatures with the same selector are listed as candidates. As the database doesn't record which event parameters are indexed, the first parameters are assumed to be, as is the case for all common standards.
+
+Output is JSON, with amounts in wei as decimal strings.`,
+		RunE: txDecode,
+		Args: cobra.MaximumNArgs(1),
+	}
+	addRPCFlag(cmd)
+	cmd.Flags().String("hash", "", "Hash of the transaction to fetch from the --rpc node")
+	cmd.Flags().StringSlice("abi", nil, "JSON files containing ABIs with which to decode calldata and logs")
+	cmd.Flags().String("signatures", "", "File of additional signatures, one per line, as for ethier selector")
+
+	txCmd.AddCommand(cmd)
+	rootCmd.AddCommand(txCmd)
+}
+
+// txDecode implements the `ethier tx decode` command.
+func txDecode(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	hashHex, err := flags.GetString("hash")
+	if err != nil {
+		return err
+	}
+	abiPaths, err := flags.GetStringSlice("abi")
+	if err != nil {
+		return err
+	}
+	extra, err := flags.GetString("signatures")
+	if err != nil {
+		return err
+	}
+	if (hashHex == "") == (len(args) == 0) {
+		return errors.New("exactly one of a raw transaction or --hash required")
+	}
+
+	d := new(txDecoder)
+	if d.db, err = loadSignatureDB(extra); err != nil {
+		return err
+	}
+	for _, p := range abiPaths {
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		a, err := readABI(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", p, err)
+		}
+		d.abis = append(d.abis, a)
+	}
+
+	var out *decodedTx
+	if hashHex == "" {
+		raw, err := hexutil.Decode(strings.TrimSpace(args[0]))
+		if err != nil {
+			return fmt.Errorf("raw transaction: %v", err)
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return fmt.Errorf("decode raw transaction: %v", err)
+		}
+		out = d.decodeTx(tx)
+	} else {
+		hash, err := hexutil.Decode(hashHex)
+		if err != nil || len(hash) != common.HashLength {
+			return fmt.Errorf("invalid --hash %q", hashHex)
+		}
+
+		ctx := context.Background()
+		client, err := dialFromFlags(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		if out, err = d.fetch(ctx, client, common.BytesToHash(hash)); err != nil {
+			return err
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// A txDecoder decodes transactions, their calldata, and receipts.
+type txDecoder struct {
+	abis []*abi.ABI
+	db   *signatureDB
+}
+
+// A decodedTx is the output of `ethier tx decode`.
+type decodedTx struct {
+	Hash    common.Hash     `json:"hash"`
+	Type    uint8           `json:"type"`
+	ChainID string          `json:"chainId,omitempty"`
+	From    *common.Address `json:"from,omitempty"`
+	// To is nil for contract creation.
+	To                   *common.Address `json:"to"`
+	Nonce                uint64          `json:"nonce"`
+	Value                string          `json:"value"`
+	Gas                  uint64          `json:"gas"`
+	GasPrice             string          `json:"gasPrice,omitempty"`
+	MaxFeePerGas         string          `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string          `json:"maxPriorityFeePerGas,omitempty"`
+	Data                 hexutil.Bytes   `json:"data"`
+	Call                 *decodedCall    `json:"call,omitempty"`
+	Pending              bool            `json:"pending,omitempty"`
+	Receipt              *decodedReceipt `json:"receipt,omitempty"`
+}
+
+// A decodedCall is the function, and its arguments, called by calldata.
+type decodedCall struct {
+	Function string       `json:"function,omitempty"`
+	Args     []decodedArg `json:"args,omitempty"`
+	// Candidates are other signatures with the same selector.
+	Candidates []string `json:"candidates,omitempty"`
+	// Error describes why decoding failed, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// A decodedArg is a single argument of a decodedCall.
+type decodedArg struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// A decodedReceipt is the receipt of a mined transaction.
+type decodedReceipt struct {
+	Status          string          `json:"status"`
+	BlockNumber     uint64          `json:"blockNumber"`
+	GasUsed         uint64          `json:"gasUsed"`
+	ContractAddress *common.Address `json:"contractAddress,omitempty"`
+	Logs            []*decodedLog   `json:"logs"`
+}
+
+// fetch fetches the transaction and, if mined, its receipt, returning them
+// decoded.
+func (d *txDecoder) fetch(ctx context.Context, client ethereum.TransactionReader, hash common.Hash) (*decodedTx, error) {
+	tx, pending, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get transaction %v: %v", hash, err)
+	}
+	out := d.decodeTx(tx)
+	out.Pending = pending
+	if pending {
+		return out, nil
+	}
+
+	r, err := client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get receipt of %v: %v", hash, err)
+	}
+	out.Receipt = d.decodeReceipt(r)
+	return out, nil
+}
+
+// decodeTx returns the decoded form of the transaction, without a receipt.
+func (d *txDecoder) decodeTx(tx *types.Transaction) *decodedTx {
+	out := &decodedTx{
+		Hash:  tx.Hash(),
+		Type:  tx.Type(),
+		To:    tx.To(),
+		Nonce: tx.Nonce(),
+		Value: tx.Value().String(),
+		Gas:   tx.Gas(),
+		Data:  tx.Data(),
+	}
+	if id := tx.ChainId(); id != nil && id.Sign() != 0 {
+		out.ChainID = id.String()
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		out.MaxFeePerGas = tx.GasFeeCap().String()
+		out.MaxPriorityFeePerGas = tx.GasTipCap().String()
+	} else {
+		out.GasPrice = tx.GasPrice().String()
+	}
+
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	}
+	if from, err := types.Sender(signer, tx); err == nil {
+		out.From = &from
+	}
+
+	if tx.To() != nil && len(tx.Data()) > 0 {
+		out.Call = d.decodeCall(tx.Data())
+	}
+	return out
+}
+
+// decodeCall decodes calldata, preferring the ABIs over the signature
+// database.
+func (d *txDecoder) decodeCall(data []byte) *decodedCall {
+	if len(data) < 4 {
+		return &decodedCall{Error: fmt.Sprintf("calldata of %d bytes too short for selector", len(data))}
+	}
+
+	for _, a := range d.abis {
+		m, err := a.MethodById(data[:4])
+		if err != nil {
+			continue
+		}
+		return newDecodedCall(*m, data[4:], false)
+	}
+
+	var sigs []string
+	if d.db != nil {
+		sigs, _ = d.db.lookup(data[:4])
+	}
+	for i, sig := range sigs {
+		m, err := parseFunctionSignature(sig)
+		if err != nil {
+			continue
+		}
+		call := newDecodedCall(m, data[4:], true)
+		if call.Error != "" {
+			continue
+		}
+		for j, other := range sigs {
+			if j != i {
+				call.Candidates = append(call.Candidates, other)
+			}
+		}
+		return call
+	}
+
+	call := &decodedCall{
+		Error:      fmt.Sprintf("unknown selector %#x", data[:4]),
+		Candidates: sigs,
+	}
+	if len(sigs) > 0 {
+		call.Error = fmt.Sprintf("arguments don't match any signature with selector %#x", data[:4])
+	}
+	return call
+}
+
+// newDecodedCall unpacks the arguments, which exclude the selector, as inputs
+// to the method. If strict is true, the arguments MUST be exactly reproduced
+// by re-encoding the unpacked values, which avoids false positives when the
+// method is guessed from its selector.
+func newDecodedCall(m abi.Method, args []byte, strict bool) *decodedCall {
+	call := &decodedCall{Function: m.Sig}
+	vals, err := m.Inputs.Unpack(args)
+	if err != nil {
+		call.Error = fmt.Sprintf("unpack %s arguments: %v", m.Sig, err)
+		return call
+	}
+	if strict {
+		if packed, err := m.Inputs.Pack(vals...); err != nil || !bytes.Equal(packed, args) {
+			call.Error = fmt.Sprintf("arguments aren't a canonical encoding for %s", m.Sig)
+			return call
+		}
+	}
+
+	for i, v := range vals {
+		in := m.Inputs[i]
+		name := in.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		call.Args = append(call.Args, decodedArg{
+			Name:  name,
+			Type:  in.Type.String(),
+			Value: formatABIValue(v),
+		})
+	}
+	return call
+}
+
+// decodeReceipt returns the decoded form of the receipt.
+func (d *txDecoder) decodeReceipt(r *types.Receipt) *decodedReceipt {
+	out := &decodedReceipt{
+		Status:  "success",
+		GasUsed: r.GasUsed,
+		Logs:    []*decodedLog{},
+	}
+	if r.Status != types.ReceiptStatusSuccessful {
+		out.Status = "reverted"
+	}
+	if r.BlockNumber != nil {
+		out.BlockNumber = r.BlockNumber.Uint64()
+	}
+	if r.ContractAddress != (common.Address{}) {
+		addr := r.ContractAddress
+		out.ContractAddress = &addr
+	}
+	for _, l := range r.Logs {
+		out.Logs = append(out.Logs, d.decodeLog(*l))
+	}
+	return out
+}
+
+// decodeLog decodes the log with the first ABI including its event, falling
+// back to the signature database, and then to its raw topics and data.
+func (d *txDecoder) decodeLog(l types.Log) *decodedLog {
+	for _, a := range d.abis {
+		if out := (&logDecoder{abi: a}).decode(l); out.Event != "" {
+			return out
+		}
+	}
+
+	if d.db != nil && len(l.Topics) > 0 {
+		sigs, _ := d.db.lookup(l.Topics[0][:])
+		for _, sig := range sigs {
+			ev, err := guessEvent(sig, len(l.Topics)-1)
+			if err != nil {
+				continue
+			}
+			a := &abi.ABI{Events: map[string]abi.Event{ev.Name: ev}}
+			if out := (&logDecoder{abi: a}).decode(l); out.Event != "" && out.Error == "" {
+				return out
+			}
+		}
+	}
+	return (&logDecoder{}).decode(l)
+}
+
+// guessEvent returns the event with the signature, assuming that its first
+// nIndexed parameters are indexed.
+func guessEvent(sig string, nIndexed int) (abi.Event, error) {
+	m, err := parseFunctionSignature(sig)
+	if err != nil {
+		return abi.Event{}, err
+	}
+	if nIndexed > len(m.Inputs) {
+		return abi.Event{}, fmt.Errorf("%d indexed topics for %d parameters of %s", nIndexed, len(m.Inputs), sig)
+	}
+	inputs := make(abi.Arguments, len(m.Inputs))
+	copy(inputs, m.Inputs)
+	for i := range inputs[:nIndexed] {
+		inputs[i].Indexed = true
+	}
+	return abi.NewEvent(m.RawName, m.RawName, false, inputs), nil
+}
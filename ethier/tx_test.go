@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/ethtest"
+)
+
+func TestTxDecoder(t *testing.T) {
+	db, err := loadSignatureDB("")
+	if err != nil {
+		t.Fatalf("loadSignatureDB() error %v", err)
+	}
+	named, err := readABI(strings.NewReader(consoleTestABI))
+	if err != nil {
+		t.Fatalf("readABI() error %v", err)
+	}
+
+	alice := common.HexToAddress("0xa1")
+	bob := common.HexToAddress("0xb0")
+	_, transfer, err := packValues("transfer(address,uint256)", bob, big.NewInt(42))
+	if err != nil {
+		t.Fatalf("packValues() error %v", err)
+	}
+
+	t.Run("calldata", func(t *testing.T) {
+		tests := []struct {
+			name string
+			d    *txDecoder
+			data []byte
+			want *decodedCall
+		}{
+			{
+				name: "signature database",
+				d:    &txDecoder{db: db},
+				data: transfer,
+				want: &decodedCall{
+					Function: "transfer(address,uint256)",
+					Args: []decodedArg{
+						{Name: "arg0", Type: "address", Value: bob.Hex()},
+						{Name: "arg1", Type: "uint256", Value: "42"},
+					},
+				},
+			},
+			{
+				name: "ABI preferred",
+				d:    &txDecoder{abis: []*abi.ABI{named}, db: db},
+				data: transfer,
+				want: &decodedCall{
+					Function: "transfer(address,uint256)",
+					Args: []decodedArg{
+						{Name: "to", Type: "address", Value: bob.Hex()},
+						{Name: "amount", Type: "uint256", Value: "42"},
+					},
+				},
+			},
+			{
+				name: "non-canonical encoding",
+				d:    &txDecoder{db: db},
+				data: append(append([]byte{}, transfer...), 0),
+				want: &decodedCall{
+					Candidates: []string{"transfer(address,uint256)"},
+					Error:      "arguments don't match any signature with selector 0xa9059cbb",
+				},
+			},
+			{
+				name: "unknown selector",
+				d:    &txDecoder{db: db},
+				data: []byte{1, 2, 3, 4},
+				want: &decodedCall{Error: "unknown selector 0x01020304"},
+			},
+			{
+				name: "short",
+				d:    &txDecoder{db: db},
+				data: []byte{1, 2},
+				want: &decodedCall{Error: "calldata of 2 bytes too short for selector"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if diff := cmp.Diff(tt.want, tt.d.decodeCall(tt.data)); diff != "" {
+					t.Errorf("decodeCall(%#x) diff (-want +got):\n%s", tt.data, diff)
+				}
+			})
+		}
+	})
+
+	t.Run("logs", func(t *testing.T) {
+		d := &txDecoder{db: db}
+		tests := []struct {
+			name string
+			log  types.Log
+			want map[string]string
+		}{
+			{
+				name: "ERC20 Transfer",
+				log: types.Log{
+					Topics: []common.Hash{transferTopic, alice.Hash(), bob.Hash()},
+					Data:   common.BigToHash(big.NewInt(7)).Bytes(),
+				},
+				want: map[string]string{"arg0": alice.Hex(), "arg1": bob.Hex(), "arg2": "7"},
+			},
+			{
+				name: "ERC721 Transfer",
+				log: types.Log{
+					Topics: []common.Hash{transferTopic, alice.Hash(), bob.Hash(), common.BigToHash(big.NewInt(99))},
+				},
+				want: map[string]string{"arg0": alice.Hex(), "arg1": bob.Hex(), "arg2": "99"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got := d.decodeLog(tt.log)
+				if got.Event != "Transfer" || got.Error != "" {
+					t.Fatalf("decodeLog() got event %q, error %q; want Transfer, no error", got.Event, got.Error)
+				}
+				if diff := cmp.Diff(tt.want, got.Args); diff != "" {
+					t.Errorf("decodeLog() args diff (-want +got):\n%s", diff)
+				}
+			})
+		}
+
+		unknown := types.Log{Topics: []common.Hash{{1}}}
+		if got := d.decodeLog(unknown); got.Event != "" || len(got.Topics) != 1 {
+			t.Errorf("decodeLog(unknown event) got %+v; want raw topics", got)
+		}
+	})
+
+	t.Run("fetch", func(t *testing.T) {
+		ctx := context.Background()
+		sim := ethtest.NewSimulatedBackendTB(t, 1)
+		signer := newFundedSigner(ctx, t, sim)
+
+		tx, err := sendTx(ctx, sim, signer, big.NewInt(1337), &txParams{
+			to:    alice,
+			data:  transfer,
+			value: big.NewInt(1),
+			nonce: -1,
+		})
+		if err != nil {
+			t.Fatalf("sendTx() error %v", err)
+		}
+
+		d := &txDecoder{db: db}
+		got, err := d.fetch(ctx, sim, tx.Hash())
+		if err != nil {
+			t.Fatalf("fetch() error %v", err)
+		}
+		if got.From == nil || *got.From != signer.Address() {
+			t.Errorf("fetch().From got %v; want %v", got.From, signer.Address())
+		}
+		if got.Call == nil || got.Call.Function != "transfer(address,uint256)" {
+			t.Errorf("fetch().Call got %+v; want transfer(address,uint256)", got.Call)
+		}
+		if got.Receipt == nil || got.Receipt.Status != "success" {
+			t.Errorf("fetch().Receipt got %+v; want successful", got.Receipt)
+		}
+
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%T.MarshalBinary() error %v", tx, err)
+		}
+		decoded := new(types.Transaction)
+		if err := decoded.UnmarshalBinary(raw); err != nil {
+			t.Fatalf("%T.UnmarshalBinary() error %v", decoded, err)
+		}
+		fromRaw := d.decodeTx(decoded)
+		got.Receipt = nil
+		if diff := cmp.Diff(got, fromRaw); diff != "" {
+			t.Errorf("decodeTx(raw) diff from fetch() without receipt (-fetched +raw):\n%s", diff)
+		}
+	})
+}
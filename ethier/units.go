@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	const short = "Converts amounts between wei, gwei, ether, and token units."
+
+	cmd := &cobra.Command{
+		Use:   "units <amount>[unit]",
+		Short: short,
+		Long: short + `
+
+The amount is a decimal number, optionally followed by a unit, e.g. 1.5eth or 30gwei; amounts without a unit are in --from units. Units are wei, kwei, mwei, gwei, szabo, finney, eth (or ether), and token, which has --decimals decimal places, e.g. 6 for USDC.
+
+The converted amount is printed exactly, without a unit. Wei, or the token's equivalent smallest unit, can't be divided so amounts that aren't a whole number of wei are rejected.`,
+		RunE: units,
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().String("from", "wei", "Unit of amounts without a unit suffix")
+	cmd.Flags().String("to", "wei", "Unit to which the amount is converted")
+	cmd.Flags().Uint("decimals", 18, "Number of decimal places of the token unit")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// units implements the `ethier units` command.
+func units(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	from, err := flags.GetString("from")
+	if err != nil {
+		return err
+	}
+	to, err := flags.GetString("to")
+	if err != nil {
+		return err
+	}
+	decimals, err := flags.GetUint("decimals")
+	if err != nil {
+		return err
+	}
+
+	got, err := convertUnits(args[0], from, to, decimals)
+	if err != nil {
+		return err
+	}
+	fmt.Println(got)
+	return nil
+}
+
+// unitDecimals maps each named unit to its number of decimal places relative
+// to wei. The token unit is handled separately as its decimals are variable.
+var unitDecimals = map[string]uint{
+	"wei":    0,
+	"kwei":   3,
+	"mwei":   6,
+	"gwei":   9,
+	"szabo":  12,
+	"finney": 15,
+	"eth":    18,
+	"ether":  18,
+}
+
+// tokenUnit is the unit with a variable number of decimal places.
+const tokenUnit = "token"
+
+// unitScale returns 10^decimals for the unit, using tokenDecimals for the
+// token unit.
+func unitScale(unit string, tokenDecimals uint) (*big.Rat, error) {
+	unit = strings.ToLower(unit)
+	d, ok := unitDecimals[unit]
+	switch {
+	case unit == tokenUnit:
+		d = tokenDecimals
+	case !ok:
+		return nil, fmt.Errorf("unknown unit %q", unit)
+	}
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d)), nil)
+	return new(big.Rat).SetInt(exp), nil
+}
+
+// splitUnit splits an amount into its number and unit suffix, returning
+// defaultUnit if there is no suffix.
+func splitUnit(amount, defaultUnit string) (string, string) {
+	amount = strings.TrimSpace(amount)
+	lower := strings.ToLower(amount)
+
+	var best string
+	for u := range unitDecimals {
+		if strings.HasSuffix(lower, u) && len(u) > len(best) {
+			best = u
+		}
+	}
+	if strings.HasSuffix(lower, tokenUnit) {
+		best = tokenUnit
+	}
+	if best == "" {
+		return amount, defaultUnit
+	}
+	return strings.TrimSpace(amount[:len(amount)-len(best)]), best
+}
+
+// convertUnits converts the amount, with an optional unit suffix, to the
+// specified unit and returns its exact decimal representation.
+func convertUnits(amount, defaultUnit, to string, tokenDecimals uint) (string, error) {
+	num, from := splitUnit(amount, defaultUnit)
+	r, ok := new(big.Rat).SetString(num)
+	if !ok {
+		return "", fmt.Errorf("invalid amount %q", amount)
+	}
+
+	fromScale, err := unitScale(from, tokenDecimals)
+	if err != nil {
+		return "", err
+	}
+	toScale, err := unitScale(to, tokenDecimals)
+	if err != nil {
+		return "", err
+	}
+
+	// For tokens, wei is the smallest indivisible unit, as with ETH.
+	wei := new(big.Rat).Mul(r, fromScale)
+	if !wei.IsInt() {
+		return "", fmt.Errorf("%s is not a whole number of wei", amount)
+	}
+	return formatDecimal(wei.Quo(wei, toScale)), nil
+}
+
+// formatDecimal returns the exact decimal representation of r, which MUST
+// have a terminating decimal expansion, without trailing zeros.
+func formatDecimal(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	// The denominator is a product of powers of 2 and 5 so the number of
+	// decimal places needed is bounded by its bit length.
+	s := r.FloatString(r.Denom().BitLen())
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
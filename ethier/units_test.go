@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestConvertUnits(t *testing.T) {
+	tests := []struct {
+		amount, from, to string
+		decimals         uint
+		want             string
+		wantErr          bool
+	}{
+		{amount: "1.5eth", to: "wei", want: "1500000000000000000"},
+		{amount: "1.5 ether", to: "gwei", want: "1500000000"},
+		{amount: "30gwei", to: "eth", want: "0.00000003"},
+		{amount: "30GWei", to: "wei", want: "30000000000"},
+		{amount: "1", to: "eth", want: "0.000000000000000001"},
+		{amount: "1", from: "eth", to: "finney", want: "1000"},
+		{amount: "2.5e3wei", to: "kwei", want: "2.5"},
+		{amount: "12.345678token", to: "token", decimals: 6, want: "12.345678"},
+		{amount: "12.345678", from: "token", to: "wei", decimals: 6, want: "12345678"},
+		{amount: "1eth", to: "token", decimals: 6, want: "1000000000000"},
+		{amount: "100wei", to: "token", decimals: 4, want: "0.01"},
+		{amount: "0.0000001token", to: "wei", decimals: 6, wantErr: true},
+		{amount: "0.5wei", to: "gwei", wantErr: true},
+		{amount: "1.0000000001gwei", to: "wei", wantErr: true},
+		{amount: "1btc", to: "wei", wantErr: true},
+		{amount: "1", to: "btc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		from := tt.from
+		if from == "" {
+			from = "wei"
+		}
+		got, err := convertUnits(tt.amount, from, tt.to, tt.decimals)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("convertUnits(%q, %q, %q, %d) got err %v; want error = %t", tt.amount, from, tt.to, tt.decimals, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("convertUnits(%q, %q, %q, %d) got %q; want %q", tt.amount, from, tt.to, tt.decimals, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	const short = "Mines private keys or CREATE2 salts resulting in addresses with a chosen prefix and/or suffix."
+
+	cmd := &cobra.Command{
+		Use:   "vanity",
+		Short: short,
+		Long: short + `
+
+By default, random private keys are generated until the corresponding address matches, and the key is saved as an encrypted keystore file. With --create2, salts are instead searched for such that the contract deployed by --deployer with init code hashed to --init-code-hash will have a matching address.
+
+Each additional hex character multiplies the expected search time by 16. Progress, including the rate of attempts and the expected time to a match, is logged to stderr every --progress interval.`,
+		RunE: vanity,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().String("prefix", "", "Hex prefix of the address, with or without 0x")
+	cmd.Flags().String("suffix", "", "Hex suffix of the address")
+	cmd.Flags().IntP("workers", "w", 0, "Number of concurrent workers; 0 = number of CPUs")
+	cmd.Flags().Bool("create2", false, "Mine a CREATE2 salt instead of a private key")
+	cmd.Flags().String("deployer", "", "Address of the CREATE2 deployer; requires --create2")
+	cmd.Flags().String("init-code-hash", "", "Keccak256 hash of the contract init code; requires --create2")
+	cmd.Flags().String("keystore", "", "Path to which the mined key is saved as an encrypted JSON file")
+	cmd.Flags().String("password-file", "", "File containing the password with which to encrypt --keystore")
+	cmd.Flags().Duration("progress", 5*time.Second, "Interval at which progress is logged; 0 to disable")
+
+	rootCmd.AddCommand(cmd)
+}
+
+// vanity implements the `ethier vanity` command.
+func vanity(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	prefix, err := flags.GetString("prefix")
+	if err != nil {
+		return err
+	}
+	suffix, err := flags.GetString("suffix")
+	if err != nil {
+		return err
+	}
+	workers, err := flags.GetInt("workers")
+	if err != nil {
+		return err
+	}
+	create2, err := flags.GetBool("create2")
+	if err != nil {
+		return err
+	}
+	progress, err := flags.GetDuration("progress")
+	if err != nil {
+		return err
+	}
+
+	match, err := eth.AddressMatcher(prefix, suffix)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts uint64
+	count := func(addr common.Address) bool {
+		atomic.AddUint64(&attempts, 1)
+		return match(addr)
+	}
+	if progress > 0 {
+		go logVanityProgress(ctx, progress, &attempts, expectedAttempts(prefix, suffix))
+	}
+
+	if create2 {
+		deployerHex, err := flags.GetString("deployer")
+		if err != nil {
+			return err
+		}
+		deployer, err := eth.ParseAddress(deployerHex)
+		if err != nil {
+			return fmt.Errorf("--deployer: %v", err)
+		}
+		hashHex, err := flags.GetString("init-code-hash")
+		if err != nil {
+			return err
+		}
+		initCodeHash, err := hexutil.Decode(hashHex)
+		if err != nil {
+			return fmt.Errorf("--init-code-hash: %v", err)
+		}
+		if len(initCodeHash) != common.HashLength {
+			return fmt.Errorf("--init-code-hash must be %d bytes; got %d", common.HashLength, len(initCodeHash))
+		}
+
+		salt, addr, err := eth.MineCreate2Salt(ctx, deployer, common.BytesToHash(initCodeHash), count, workers)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Salt:    %#x\nAddress: %v\n", salt, addr)
+		return nil
+	}
+
+	path, err := flags.GetString("keystore")
+	if err != nil {
+		return err
+	}
+	pwFile, err := flags.GetString("password-file")
+	if err != nil {
+		return err
+	}
+	if path == "" || pwFile == "" {
+		return errors.New("--keystore and --password-file are required to save the mined key")
+	}
+	pw, err := readPasswordFile(pwFile)
+	if err != nil {
+		return err
+	}
+
+	s, err := eth.MineKey(ctx, count, workers)
+	if err != nil {
+		return err
+	}
+	if err := s.SaveKeystore(path, pw); err != nil {
+		return err
+	}
+	log.Printf("Key saved to %q", path)
+	fmt.Printf("Address: %v\n", s.Address())
+	return nil
+}
+
+// expectedAttempts returns the expected number of attempts to find an address
+// matching the prefix and suffix, which MUST already have been validated by
+// eth.AddressMatcher().
+func expectedAttempts(prefix, suffix string) float64 {
+	n := len(strings.TrimPrefix(prefix, "0x")) + len(suffix)
+	return math.Pow(16, float64(n))
+}
+
+// logVanityProgress logs the number of attempts, read atomically, every
+// interval until ctx is cancelled.
+func logVanityProgress(ctx context.Context, interval time.Duration, attempts *uint64, expected float64) {
+	start := time.Now()
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		n := atomic.LoadUint64(attempts)
+		elapsed := time.Since(start)
+		rate := float64(n) / elapsed.Seconds()
+
+		msg := fmt.Sprintf("%d attempts in %v (%.0f/s); %.0f expected", n, elapsed.Round(time.Second), rate, expected)
+		if rate > 0 {
+			eta := time.Duration(expected / rate * float64(time.Second))
+			msg += fmt.Sprintf(" (~%v total)", eta.Round(time.Second))
+		}
+		log.Print(msg)
+	}
+}
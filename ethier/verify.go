@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func init() {
+	const short = "Verifies every signature in the JSON output of `ethier sign` read from stdin."
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: short,
+		Long: short + `
+
+Messages are recomputed from each entry, and signers recovered, to confirm that every signature is valid, was produced by the expected signer, and has a low s value as required by OpenZeppelin's ECDSA library. All invalid entries are reported, not only the first. The expected signer defaults to the one recorded in the input.`,
+		RunE: verify,
+		Args: cobra.NoArgs,
+	}
+	cmd.Flags().String("signer", "", "Expected signer address; defaults to the signer in the input")
+	cmd.Flags().IntP("workers", "w", 0, "Number of concurrent workers; 0 = number of CPUs")
+
+	cmd.AddCommand(verifyEtherscanCmd())
+	rootCmd.AddCommand(cmd)
+}
+
+// verify implements the `ethier verify` command.
+func verify(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	signerHex, err := flags.GetString("signer")
+	if err != nil {
+		return err
+	}
+	workers, err := flags.GetInt("workers")
+	if err != nil {
+		return err
+	}
+
+	out, err := readSignedOutput(os.Stdin)
+	if err != nil {
+		return err
+	}
+	expected := out.Signer
+	if signerHex != "" {
+		if expected, err = eth.ParseAddress(signerHex); err != nil {
+			return fmt.Errorf("--signer: %v", err)
+		}
+		if expected != out.Signer {
+			log.Printf("Input recorded signer %v; verifying against --signer %v", out.Signer, expected)
+		}
+	}
+
+	err = verifySignedOutput(context.Background(), out, expected, workers)
+	var batchErr *eth.BatchVerificationError
+	if !errors.As(err, &batchErr) {
+		if err == nil {
+			log.Printf("All %d signatures valid for %v", len(out.Entries), expected)
+		}
+		return err
+	}
+	for _, f := range batchErr.Failures {
+		fmt.Printf("[%d] %v\n", f.Index, f.Err)
+	}
+	return fmt.Errorf("%d of %d signatures invalid", len(batchErr.Failures), batchErr.Total)
+}
+
+// A signedOutput is the union of the JSON outputs of all `ethier sign`
+// subcommands, which are differentiated by the fields present.
+type signedOutput struct {
+	Signer common.Address `json:"signer"`
+
+	// From signedList.
+	Packed []string `json:"packed"`
+	Hashed bool     `json:"hashed"`
+
+	// From signedMessageList.
+	EIP191 *bool `json:"eip191"`
+
+	// From signedTypedDataList.
+	Types       apitypes.Types           `json:"types"`
+	PrimaryType string                   `json:"primaryType"`
+	Domain      apitypes.TypedDataDomain `json:"domain"`
+
+	Entries []json.RawMessage `json:"entries"`
+}
+
+// readSignedOutput parses the JSON output of any `ethier sign` subcommand,
+// including the JSONL output of `ethier sign addresses --stream`, which has a
+// signedStreamHeader on the first line and an entry on every other line.
+func readSignedOutput(r io.Reader) (*signedOutput, error) {
+	out := new(signedOutput)
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(out); err != nil {
+		return nil, fmt.Errorf("decode input: %v", err)
+	}
+	if out.Signer == (common.Address{}) {
+		return nil, errors.New("input missing signer")
+	}
+
+	if out.Entries != nil {
+		return out, nil
+	}
+	for dec.More() {
+		var e json.RawMessage
+		if err := dec.Decode(&e); err != nil {
+			return nil, fmt.Errorf("decode input line %d: %v", len(out.Entries)+2, err)
+		}
+		out.Entries = append(out.Entries, e)
+	}
+	return out, nil
+}
+
+// verifySignedOutput recomputes the digest of every entry and checks its
+// signature. Entries for which the digest can't be computed, or with
+// malleable signatures, are reported as failures alongside those from
+// eth.VerifyBatch(), in the same *eth.BatchVerificationError.
+func verifySignedOutput(ctx context.Context, out *signedOutput, expected common.Address, workers int) error {
+	var (
+		failures []eth.SignatureFailure
+		digests  [][]byte
+		sigs     [][]byte
+		indices  []int // of entries for which digests were computed
+		labels   []string
+	)
+	for i, raw := range out.Entries {
+		label, digest, sig, err := out.digest(raw)
+		if err == nil {
+			err = eth.CheckSignatureMalleability(sig)
+		}
+		if err != nil {
+			if label != "" {
+				err = fmt.Errorf("%s: %v", label, err)
+			}
+			failures = append(failures, eth.SignatureFailure{Index: i, Err: err})
+			continue
+		}
+		digests = append(digests, digest)
+		sigs = append(sigs, sig)
+		indices = append(indices, i)
+		labels = append(labels, label)
+	}
+
+	err := eth.VerifyBatch(ctx, digests, sigs, expected, workers)
+	var batchErr *eth.BatchVerificationError
+	switch {
+	case errors.As(err, &batchErr):
+		for _, f := range batchErr.Failures {
+			failures = append(failures, eth.SignatureFailure{
+				Index: indices[f.Index],
+				Err:   fmt.Errorf("%s: %v", labels[f.Index], f.Err),
+			})
+		}
+	case err != nil:
+		return err
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Index < failures[j].Index
+	})
+	return &eth.BatchVerificationError{
+		Total:    len(out.Entries),
+		Failures: failures,
+	}
+}
+
+// digest returns a label describing the entry, for reporting, along with the
+// digest that its signature is expected to be of.
+func (o *signedOutput) digest(raw json.RawMessage) (string, []byte, []byte, error) {
+	switch {
+	case o.PrimaryType != "":
+		var e signedTypedData
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return "", nil, nil, fmt.Errorf("decode entry: %v", err)
+		}
+		label := fmt.Sprintf("%s %#x", o.PrimaryType, []byte(e.Digest))
+		digest, err := eth.TypedDataDigest(apitypes.TypedData{
+			Types:       o.Types,
+			PrimaryType: o.PrimaryType,
+			Domain:      o.Domain,
+			Message:     e.Message,
+		})
+		if err != nil {
+			return label, nil, nil, err
+		}
+		if !bytes.Equal(digest, e.Digest) {
+			return label, nil, nil, fmt.Errorf("recorded digest doesn't match message digest %#x", digest)
+		}
+		return label, digest, e.Signature, nil
+
+	case o.EIP191 != nil:
+		var e signedMessage
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return "", nil, nil, fmt.Errorf("decode entry: %v", err)
+		}
+		label := fmt.Sprintf("%q", e.Message)
+		msg, err := parseMessage(e.Message)
+		if err != nil {
+			return label, nil, nil, err
+		}
+		if *o.EIP191 {
+			msg = eth.WithPersonalMessagePrefix(msg)
+		}
+		return label, crypto.Keccak256(msg), e.Signature, nil
+
+	default:
+		var e signedEntry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return "", nil, nil, fmt.Errorf("decode entry: %v", err)
+		}
+		label := e.Address.Hex()
+		msg, err := o.packedMessage(e)
+		if err != nil {
+			return label, nil, nil, err
+		}
+		return label, crypto.Keccak256(eth.WithPersonalMessagePrefix(msg)), e.Signature, nil
+	}
+}
+
+// packedMessage recomputes the message signed by `ethier sign addresses` or
+// `ethier sign tokens` for the entry.
+func (o *signedOutput) packedMessage(e signedEntry) ([]byte, error) {
+	header := []string{"address"}
+	row := signRow{
+		address: e.Address,
+		record:  []string{e.Address.Hex()},
+	}
+
+	for _, p := range o.Packed {
+		name := strings.SplitN(p, ":", 2)[0]
+		v, ok := lookupField(e.Fields, name)
+		if !ok {
+			return nil, fmt.Errorf("missing packed field %q", name)
+		}
+		header = append(header, name)
+		row.record = append(row.record, v)
+	}
+
+	spec, err := newMessageSpec(header, o.Packed)
+	if err != nil {
+		return nil, err
+	}
+	spec.hash = o.Hashed
+	return spec.message(row)
+}
+
+// lookupField returns the named field, ignoring case.
+func lookupField(fields map[string]string, name string) (string, bool) {
+	for k, v := range fields {
+		if strings.EqualFold(strings.TrimSpace(k), name) {
+			return v, true
+		}
+	}
+	return "", false
+}
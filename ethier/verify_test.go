@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// roundTrip encodes v as JSON and parses it with readSignedOutput().
+func roundTrip(t *testing.T, v interface{}) *signedOutput {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		t.Fatalf("json.Encode(%T) error %v", v, err)
+	}
+	out, err := readSignedOutput(&buf)
+	if err != nil {
+		t.Fatalf("readSignedOutput() error %v", err)
+	}
+	return out
+}
+
+// failedIndices returns the indices of failures in err, which MUST be a
+// *eth.BatchVerificationError.
+func failedIndices(t *testing.T, err error) []int {
+	t.Helper()
+	var batchErr *eth.BatchVerificationError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("got error %v; want *eth.BatchVerificationError", err)
+	}
+	var idx []int
+	for _, f := range batchErr.Failures {
+		idx = append(idx, f.Index)
+	}
+	return idx
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+	other, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	// Each test returns valid output of an `ethier sign` subcommand with at
+	// least 3 entries.
+	tests := []struct {
+		name string
+		sign func(*testing.T) interface{}
+		// tamper modifies entry i of the output such that its signature is no
+		// longer valid, without modifying the signature itself.
+		tamper func(interface{}, int)
+	}{
+		{
+			name: "addresses with claim",
+			sign: func(t *testing.T) interface{} {
+				header, rows, err := readRows(scanAddressCSV(strings.NewReader(`address,allowance
+0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,3
+0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359,10
+0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB,1
+`)))
+				if err != nil {
+					t.Fatalf("scanAddressCSV().all() error %v", err)
+				}
+				claim := &claimSpec{withNonce: true, allowanceColumn: "Allowance", expiry: "1h"}
+				header, packed, err := claim.columns(header, rows, time.Unix(0, 0))
+				if err != nil {
+					t.Fatalf("claimSpec.columns() error %v", err)
+				}
+				spec, err := newMessageSpec(header, packed)
+				if err != nil {
+					t.Fatalf("newMessageSpec() error %v", err)
+				}
+				spec.hash = true
+				sigs, err := signRows(signer, spec, rows)
+				if err != nil {
+					t.Fatalf("signRows() error %v", err)
+				}
+				return newSignedList(signer.Address(), spec, header, rows, sigs)
+			},
+			tamper: func(v interface{}, i int) {
+				v.(*signedList).Entries[i].Fields["allowance"] = "1000"
+			},
+		},
+		{
+			name: "tokens",
+			sign: func(t *testing.T) interface{} {
+				header, rows, err := readTokenPairs(strings.NewReader("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,1\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,2\n0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,3\n"))
+				if err != nil {
+					t.Fatalf("readTokenPairs() error %v", err)
+				}
+				spec, err := newMessageSpec(header, []string{"tokenId:uint256"})
+				if err != nil {
+					t.Fatalf("newMessageSpec() error %v", err)
+				}
+				sigs, err := signRows(signer, spec, rows)
+				if err != nil {
+					t.Fatalf("signRows() error %v", err)
+				}
+				return newSignedList(signer.Address(), spec, header, rows, sigs)
+			},
+			tamper: func(v interface{}, i int) {
+				v.(*signedList).Entries[i].Fields["tokenId"] = "4"
+			},
+		},
+		{
+			name: "messages",
+			sign: func(t *testing.T) interface{} {
+				list, err := signMessageList(signer, strings.NewReader("a\nb\n0xc0ffee\n"), false)
+				if err != nil {
+					t.Fatalf("signMessageList() error %v", err)
+				}
+				return list
+			},
+			tamper: func(v interface{}, i int) {
+				v.(*signedMessageList).Entries[i].Message = "tampered"
+			},
+		},
+		{
+			name: "typed data",
+			sign: func(t *testing.T) interface{} {
+				schema, err := readTypedDataSchema(strings.NewReader(`{
+  "types": {"Mail": [{"name": "contents", "type": "string"}]},
+  "primaryType": "Mail",
+  "domain": {"name": "Test", "chainId": 1}
+}`))
+				if err != nil {
+					t.Fatalf("readTypedDataSchema() error %v", err)
+				}
+				msgs, err := readTypedDataMessages(strings.NewReader(`[{"contents": "a"}, {"contents": "b"}, {"contents": "c"}]`))
+				if err != nil {
+					t.Fatalf("readTypedDataMessages() error %v", err)
+				}
+				list, err := signTypedDataList(signer, schema, msgs)
+				if err != nil {
+					t.Fatalf("signTypedDataList() error %v", err)
+				}
+				return list
+			},
+			tamper: func(v interface{}, i int) {
+				e := &v.(*signedTypedDataList).Entries[i]
+				e.Message = map[string]interface{}{"contents": "tampered"}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tt.sign(t)
+			out := roundTrip(t, v)
+			if err := verifySignedOutput(ctx, out, signer.Address(), 0); err != nil {
+				t.Fatalf("verifySignedOutput() error %v", err)
+			}
+			if err := verifySignedOutput(ctx, out, other.Address(), 0); err == nil {
+				t.Errorf("verifySignedOutput(<other signer>) got nil error; want error")
+			}
+
+			tt.tamper(v, 0)
+			tt.tamper(v, 2)
+			got := failedIndices(t, verifySignedOutput(ctx, roundTrip(t, v), signer.Address(), 0))
+			if diff := cmp.Diff([]int{0, 2}, got); diff != "" {
+				t.Errorf("verifySignedOutput(<tampered>) failure indices diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestVerifyStream(t *testing.T) {
+	ctx := context.Background()
+
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+	const in = `address,allowance
+0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed,3
+0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359,10
+0x000000000000000000000000000000000000dEaD,1
+`
+
+	tests := []struct {
+		name   string
+		claim  *claimSpec
+		packed []string
+	}{
+		{
+			name:   "packed",
+			claim:  new(claimSpec),
+			packed: []string{"allowance:uint256"},
+		},
+		{
+			name: "claim",
+			claim: &claimSpec{
+				withNonce:       true,
+				allowanceColumn: "allowance",
+				expiry:          "1h",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scan, err := scanAddressCSV(strings.NewReader(in))
+			if err != nil {
+				t.Fatalf("scanAddressCSV() error %v", err)
+			}
+			var buf bytes.Buffer
+			if _, err := signAddressStream(&buf, "json", signer, scan, tt.claim, tt.packed, time.Now()); err != nil {
+				t.Fatalf("signAddressStream() error %v", err)
+			}
+			lines := strings.SplitAfter(buf.String(), "\n")
+
+			out, err := readSignedOutput(strings.NewReader(buf.String()))
+			if err != nil {
+				t.Fatalf("readSignedOutput(<streamed>) error %v", err)
+			}
+			if got, want := len(out.Entries), 3; got != want {
+				t.Fatalf("readSignedOutput(<streamed>) got %d entries; want %d", got, want)
+			}
+			if err := verifySignedOutput(ctx, out, signer.Address(), 0); err != nil {
+				t.Fatalf("verifySignedOutput(<streamed>) error %v", err)
+			}
+
+			// Line 0 is the header so entry i is on line i+1.
+			lines[2] = strings.Replace(lines[2], "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359", "0x000000000000000000000000000000000000bEEF", 1)
+			tampered, err := readSignedOutput(strings.NewReader(strings.Join(lines, "")))
+			if err != nil {
+				t.Fatalf("readSignedOutput(<tampered>) error %v", err)
+			}
+			got := failedIndices(t, verifySignedOutput(ctx, tampered, signer.Address(), 0))
+			if diff := cmp.Diff([]int{1}, got); diff != "" {
+				t.Errorf("verifySignedOutput(<tampered stream>) failure indices diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestVerifyMalleable(t *testing.T) {
+	signer, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+	list, err := signMessageList(signer, strings.NewReader("hello\nworld\n"), true)
+	if err != nil {
+		t.Fatalf("signMessageList() error %v", err)
+	}
+
+	// Flip the second signature to its high-s equivalent, which recovers to
+	// the same address but is rejected by OpenZeppelin's ECDSA library.
+	sig, err := eth.ExpandSignature(list.Entries[1].Signature)
+	if err != nil {
+		t.Fatalf("eth.ExpandSignature() error %v", err)
+	}
+	s := new(big.Int).SetBytes(sig[32:64])
+	s.Sub(crypto.S256().Params().N, s)
+	copy(sig[32:64], common.LeftPadBytes(s.Bytes(), 32))
+	sig[64] = 27 + 28 - sig[64]
+	list.Entries[1].Signature = hexutil.Bytes(sig)
+
+	got := failedIndices(t, verifySignedOutput(context.Background(), roundTrip(t, list), signer.Address(), 0))
+	if diff := cmp.Diff([]int{1}, got); diff != "" {
+		t.Errorf("verifySignedOutput(<malleable>) failure indices diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// walletCmd is the parent of all `ethier wallet` subcommands.
+var walletCmd = &cobra.Command{
+	Use:   "wallet",
+	Short: "Manages a local directory of encrypted keys",
+	Long: `Manages a local directory of encrypted keys, which are selected by other commands, e.g. ethier sign and send, with --account <address>.
+
+Keys are stored in the same format and with the same file names as geth's keystore, which can therefore be used as --keystore-dir, and vice versa.`,
+}
+
+func init() {
+	pf := walletCmd.PersistentFlags()
+	pf.String(keystoreDirFlag, defaultKeystoreDir(), "Directory of encrypted keys")
+	pf.String(passwordFileFlag, "", "File containing the password with which keys are encrypted")
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the addresses of all keys in the directory",
+		RunE:  walletList,
+		Args:  cobra.NoArgs,
+	}
+
+	newKey := &cobra.Command{
+		Use:   "new",
+		Short: "Generates a new key in the directory",
+		Long: `Generates a new key in the directory.
+
+With --mnemonic, the key is derived from a newly generated BIP39 mnemonic, which is printed so that it can be recorded as a backup; the mnemonic itself is not stored.`,
+		RunE: walletNew,
+		Args: cobra.NoArgs,
+	}
+	addKeygenFlags(newKey)
+
+	importKey := &cobra.Command{
+		Use:   "import",
+		Short: "Imports an existing key into the directory",
+		Long: `Imports an existing key into the directory.
+
+Exactly one source of the key is required. A --keystore file is decrypted with --password-file, which is also used to encrypt the imported key.`,
+		RunE: walletImport,
+		Args: cobra.NoArgs,
+	}
+	importKey.Flags().String(privateKeyFlag, "", "Hex-encoded private key; prefer --key-file as command-line arguments may be logged")
+	importKey.Flags().String(keyFileFlag, "", "File containing a hex-encoded private key")
+	importKey.Flags().String(mnemonicFlag, "", "BIP39 mnemonic from which to derive the key at --derivation-path")
+	importKey.Flags().String(derivationPathFlag, string(eth.DefaultHDPathPrefix)+"0", "Derivation path used with --mnemonic")
+	importKey.Flags().String(keystoreFlag, "", "Encrypted JSON keystore file, as produced by geth and most wallets")
+
+	export := &cobra.Command{
+		Use:   "export <address>",
+		Short: "Writes a key from the directory as an encrypted JSON keystore",
+		Long: `Writes a key from the directory as an encrypted JSON keystore.
+
+The key is decrypted with --password-file and re-encrypted with --new-password-file, if set. The keystore is written to stdout unless --out is set.`,
+		RunE: walletExport,
+		Args: cobra.ExactArgs(1),
+	}
+	export.Flags().String("new-password-file", "", "File containing the password with which to encrypt the exported key; defaults to --password-file")
+	export.Flags().String("out", "", "Path to which the keystore is written; MUST NOT already exist")
+
+	walletCmd.AddCommand(list, newKey, importKey, export)
+	rootCmd.AddCommand(walletCmd)
+}
+
+// defaultKeystoreDir returns $ETHIER_KEYSTORE_DIR, falling back to
+// ~/.ethier/keystore.
+func defaultKeystoreDir() string {
+	if d := os.Getenv("ETHIER_KEYSTORE_DIR"); d != "" {
+		return d
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ethier", "keystore")
+}
+
+// walletFlags returns the --keystore-dir and the password read from
+// --password-file, which is required.
+func walletFlags(cmd *cobra.Command) (string, string, error) {
+	flags := cmd.Flags()
+	dir, err := flags.GetString(keystoreDirFlag)
+	if err != nil {
+		return "", "", err
+	}
+	pwFile, err := flags.GetString(passwordFileFlag)
+	if err != nil {
+		return "", "", err
+	}
+	if dir == "" {
+		return "", "", fmt.Errorf("--%s required", keystoreDirFlag)
+	}
+	if pwFile == "" {
+		return "", "", fmt.Errorf("--%s required", passwordFileFlag)
+	}
+	pw, err := readPasswordFile(pwFile)
+	if err != nil {
+		return "", "", err
+	}
+	if pw == "" {
+		return "", "", errors.New("empty password")
+	}
+	return dir, pw, nil
+}
+
+// walletList implements the `ethier wallet list` command.
+func walletList(cmd *cobra.Command, args []string) error {
+	dir, err := cmd.Flags().GetString(keystoreDirFlag)
+	if err != nil {
+		return err
+	}
+	keys, err := listWallet(dir)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		fmt.Printf("%v\t%s\n", k.address, k.path)
+	}
+	return nil
+}
+
+// walletNew implements the `ethier wallet new` command.
+func walletNew(cmd *cobra.Command, args []string) error {
+	dir, pw, err := walletFlags(cmd)
+	if err != nil {
+		return err
+	}
+	return generateKey(cmd, func(s *eth.Signer) (string, error) {
+		return saveWalletKey(dir, s, pw, time.Now())
+	})
+}
+
+// walletImport implements the `ethier wallet import` command.
+func walletImport(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	get := func(name string) string {
+		v, _ := flags.GetString(name)
+		return v
+	}
+	dir, pw, err := walletFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	var set []string
+	for _, name := range []string{privateKeyFlag, keyFileFlag, mnemonicFlag, keystoreFlag} {
+		if get(name) != "" {
+			set = append(set, "--"+name)
+		}
+	}
+	if len(set) != 1 {
+		return fmt.Errorf("exactly one of --%s, --%s, --%s, or --%s required; got %d", privateKeyFlag, keyFileFlag, mnemonicFlag, keystoreFlag, len(set))
+	}
+
+	var s *eth.Signer
+	switch {
+	case get(privateKeyFlag) != "":
+		s, err = eth.NewSignerFromHex(get(privateKeyFlag))
+	case get(keyFileFlag) != "":
+		buf, readErr := os.ReadFile(get(keyFileFlag))
+		if readErr != nil {
+			return fmt.Errorf("read --%s: %v", keyFileFlag, readErr)
+		}
+		s, err = eth.NewSignerFromHex(string(buf))
+	case get(mnemonicFlag) != "":
+		s, err = eth.NewSignerFromMnemonic(get(mnemonicFlag), get(derivationPathFlag))
+	default:
+		s, err = eth.NewSignerFromKeystore(get(keystoreFlag), pw)
+	}
+	if err != nil {
+		return err
+	}
+
+	path, err := saveWalletKey(dir, s, pw, time.Now())
+	if err != nil {
+		return err
+	}
+	log.Printf("Key saved to %q", path)
+	fmt.Printf("Address: %v\n", s.Address())
+	return nil
+}
+
+// walletExport implements the `ethier wallet export` command.
+func walletExport(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	newPWFile, err := flags.GetString("new-password-file")
+	if err != nil {
+		return err
+	}
+	out, err := flags.GetString("out")
+	if err != nil {
+		return err
+	}
+	dir, pw, err := walletFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	addr, err := eth.ParseAddress(args[0])
+	if err != nil {
+		return err
+	}
+	path, err := findWalletKey(dir, addr)
+	if err != nil {
+		return err
+	}
+	s, err := eth.NewSignerFromKeystore(path, pw)
+	if err != nil {
+		return err
+	}
+
+	if newPWFile != "" {
+		if pw, err = readPasswordFile(newPWFile); err != nil {
+			return err
+		}
+		if pw == "" {
+			return errors.New("empty --new-password-file")
+		}
+	}
+	if out != "" {
+		if err := s.SaveKeystore(out, pw); err != nil {
+			return err
+		}
+		log.Printf("Key %v exported to %q", addr, out)
+		return nil
+	}
+
+	buf, err := s.KeystoreJSON(pw)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Printf("%s\n", buf)
+	return err
+}
+
+// A walletKey is a single key in a keystore directory.
+type walletKey struct {
+	address common.Address
+	path    string
+}
+
+// listWallet returns all keys in the directory, in order of file name and
+// therefore, for keys added by ethier or geth, of creation. Files that aren't
+// JSON keys, and hidden files, are ignored. A non-existent directory is
+// treated as empty.
+func listWallet(dir string) ([]walletKey, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read keystore directory: %v", err)
+	}
+
+	var keys []walletKey
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read key: %v", err)
+		}
+		var key struct {
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal(buf, &key); err != nil || !common.IsHexAddress(key.Address) {
+			continue
+		}
+		keys = append(keys, walletKey{
+			address: common.HexToAddress(key.Address),
+			path:    path,
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].path < keys[j].path
+	})
+	return keys, nil
+}
+
+// findWalletKey returns the path of the key for the address in the directory.
+func findWalletKey(dir string, addr common.Address) (string, error) {
+	keys, err := listWallet(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, k := range keys {
+		if k.address == addr {
+			return k.path, nil
+		}
+	}
+	return "", fmt.Errorf("no key for %v in %q", addr, dir)
+}
+
+// saveWalletKey encrypts the Signer's key with the password and saves it in
+// the directory, which is created if necessary, with the same file name as
+// geth would use. It returns an error if the directory already contains a key
+// for the same address.
+func saveWalletKey(dir string, s *eth.Signer, password string, now time.Time) (string, error) {
+	if _, err := findWalletKey(dir, s.Address()); err == nil {
+		return "", fmt.Errorf("%q already contains a key for %v", dir, s.Address())
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create keystore directory: %v", err)
+	}
+
+	name := fmt.Sprintf("UTC--%s--%x", now.UTC().Format("2006-01-02T15-04-05.000000000Z"), s.Address().Bytes())
+	path := filepath.Join(dir, name)
+	if err := s.SaveKeystore(path, password); err != nil {
+		return "", err
+	}
+	return path, nil
+}
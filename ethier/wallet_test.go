@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+func TestWallet(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keystore")
+
+	if keys, err := listWallet(dir); err != nil || len(keys) != 0 {
+		t.Fatalf("listWallet(<non-existent>) got %v, err = %v; want empty, nil", keys, err)
+	}
+
+	signer, err := eth.NewSignerFromHex("0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("eth.NewSignerFromHex() error %v", err)
+	}
+	now := time.Date(2022, 6, 1, 12, 30, 0, 0, time.UTC)
+	path, err := saveWalletKey(dir, signer, "hunter2", now)
+	if err != nil {
+		t.Fatalf("saveWalletKey() error %v", err)
+	}
+	if got, want := filepath.Base(path), "UTC--2022-06-01T12-30-00.000000000Z--2c7536e3605d9c16a7a3d7b1898e529396a65c23"; got != want {
+		t.Errorf("saveWalletKey() saved to %q; want geth-compatible file name %q", got, want)
+	}
+
+	// Non-key files are ignored.
+	for name, contents := range map[string]string{
+		".DS_Store":    "binary",
+		"README.txt":   "not JSON",
+		"other.json":   `{"address":"not an address"}`,
+		"subdirectory": "",
+	} {
+		p := filepath.Join(dir, name)
+		var err error
+		if contents == "" {
+			err = os.Mkdir(p, 0700)
+		} else {
+			err = os.WriteFile(p, []byte(contents), 0600)
+		}
+		if err != nil {
+			t.Fatalf("create %q: %v", p, err)
+		}
+	}
+
+	keys, err := listWallet(dir)
+	if err != nil {
+		t.Fatalf("listWallet() error %v", err)
+	}
+	want := []walletKey{{address: signer.Address(), path: path}}
+	if diff := cmp.Diff(want, keys, cmp.AllowUnexported(walletKey{})); diff != "" {
+		t.Errorf("listWallet() diff (-want +got):\n%s", diff)
+	}
+
+	if got, err := findWalletKey(dir, signer.Address()); err != nil || got != path {
+		t.Errorf("findWalletKey(%v) got %q, err = %v; want %q, nil", signer.Address(), got, err, path)
+	}
+	if _, err := findWalletKey(dir, common.Address{}); err == nil {
+		t.Errorf("findWalletKey(<unknown address>) got nil error")
+	}
+	if _, err := saveWalletKey(dir, signer, "hunter2", now.Add(time.Second)); err == nil {
+		t.Errorf("saveWalletKey(<duplicate address>) got nil error")
+	}
+
+	got, err := eth.NewSignerFromKeystore(path, "hunter2")
+	if err != nil {
+		t.Fatalf("eth.NewSignerFromKeystore(saveWalletKey()) error %v", err)
+	}
+	if got.Address() != signer.Address() {
+		t.Errorf("eth.NewSignerFromKeystore(saveWalletKey()).Address() got %v; want %v", got.Address(), signer.Address())
+	}
+}
@@ -0,0 +1,170 @@
+// Package chaintest provides test doubles for Chainlink's VRF v2 coordinator,
+// allowing the full request and callback cycle of contracts built on
+// VRFConsumerBaseV2 to be tested with arbitrary random words, and for price
+// feeds implementing AggregatorV3Interface.
+package chaintest
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/chaintest/chaintestabi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// vrfCoordinatorV2 is the address at which the simulated coordinator is
+// deployed by DeployVRFCoordinatorV2(). This is deterministic because mocked
+// entities in ethtest.SimulatedBackend have deterministic keys.
+var vrfCoordinatorV2 = common.HexToAddress("0xcd26dd12164e88B3835a26c6b84349FDD0fb7253")
+
+// VRFCoordinatorV2Address returns the address at which
+// DeployVRFCoordinatorV2() deploys the simulated coordinator, to be passed to
+// the VRFConsumerBaseV2 constructor.
+func VRFCoordinatorV2Address() common.Address {
+	return vrfCoordinatorV2
+}
+
+// A VRFCoordinatorV2 is a simulated Chainlink VRF v2 coordinator. Requests for
+// random words are recorded but only fulfilled by calls to
+// FulfillRandomWords().
+type VRFCoordinatorV2 struct {
+	*chaintestabi.SimulatedVRFCoordinatorV2
+	sim *ethtest.SimulatedBackend
+}
+
+// DeployVRFCoordinatorV2 deploys a simulated VRF v2 coordinator to the
+// SimulatedBackend.
+//
+// This function MUST only be called once for each SimulatedBackend; all future
+// calls will deploy to a different address to the one returned by
+// VRFCoordinatorV2Address().
+func DeployVRFCoordinatorV2(sim *ethtest.SimulatedBackend) (*VRFCoordinatorV2, error) {
+	var c *chaintestabi.SimulatedVRFCoordinatorV2
+	err := sim.AsMockedEntity(ethtest.ChainlinkVRFV2, func(opts *bind.TransactOpts) error {
+		addr, _, coord, err := chaintestabi.DeploySimulatedVRFCoordinatorV2(opts, sim)
+		if err != nil {
+			return fmt.Errorf("chaintestabi.DeploySimulatedVRFCoordinatorV2() error %v", err)
+		}
+		if addr != vrfCoordinatorV2 {
+			return fmt.Errorf("unexpected deployment address %v; want %v", addr, vrfCoordinatorV2)
+		}
+		c = coord
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &VRFCoordinatorV2{
+		SimulatedVRFCoordinatorV2: c,
+		sim:                       sim,
+	}, nil
+}
+
+// DeployVRFCoordinatorV2TB calls DeployVRFCoordinatorV2() and reports any
+// errors with tb.Fatal.
+func DeployVRFCoordinatorV2TB(tb testing.TB, sim *ethtest.SimulatedBackend) *VRFCoordinatorV2 {
+	tb.Helper()
+
+	c, err := DeployVRFCoordinatorV2(sim)
+	if err != nil {
+		tb.Fatalf("chaintest.DeployVRFCoordinatorV2() error %v", err)
+	}
+	return c
+}
+
+// CreateSubscriptionTB creates a subscription owned by the account, adds the
+// consumers to it, and returns its ID. Reports any errors with tb.Fatal.
+func (c *VRFCoordinatorV2) CreateSubscriptionTB(tb testing.TB, account int, consumers ...common.Address) uint64 {
+	tb.Helper()
+
+	c.sim.Must(tb, "CreateSubscription()")(c.CreateSubscription(c.sim.Acc(account)))
+	id, err := c.SubscriptionCount(nil)
+	if err != nil {
+		tb.Fatalf("%T.SubscriptionCount() error %v", c, err)
+	}
+	for _, con := range consumers {
+		c.AddConsumerTB(tb, account, id, con)
+	}
+	return id
+}
+
+// AddConsumerTB adds the consumer to the subscription, which MUST be owned by
+// the account. Reports any errors with tb.Fatal.
+func (c *VRFCoordinatorV2) AddConsumerTB(tb testing.TB, account int, subID uint64, consumer common.Address) {
+	tb.Helper()
+	c.sim.Must(tb, "AddConsumer(%d, %v)", subID, consumer)(c.AddConsumer(c.sim.Acc(account), subID, consumer))
+}
+
+// RequestIDs returns the IDs of all requests for random words made in the
+// transaction, in the order in which they were made. Reports any errors with
+// tb.Fatal.
+func (c *VRFCoordinatorV2) RequestIDs(tb testing.TB, tx *types.Transaction) []*big.Int {
+	tb.Helper()
+
+	parsed, err := chaintestabi.SimulatedVRFCoordinatorV2MetaData.GetAbi()
+	if err != nil {
+		tb.Fatalf("%T.GetAbi() error %v", chaintestabi.SimulatedVRFCoordinatorV2MetaData, err)
+	}
+	topic := parsed.Events["RandomWordsRequested"].ID
+
+	rcpt, err := c.sim.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		tb.Fatalf("%T.TransactionReceipt(%v) error %v", c.sim, tx.Hash(), err)
+	}
+
+	var ids []*big.Int
+	for _, l := range rcpt.Logs {
+		if l.Address != vrfCoordinatorV2 || len(l.Topics) == 0 || l.Topics[0] != topic {
+			continue
+		}
+		ev, err := c.ParseRandomWordsRequested(*l)
+		if err != nil {
+			tb.Fatalf("%T.ParseRandomWordsRequested() error %v", c, err)
+		}
+		ids = append(ids, ev.RequestId)
+	}
+	return ids
+}
+
+// FulfillRandomWords fulfills the request with the words, which MUST be equal
+// in number to those requested. Unlike the real coordinator, which succeeds
+// regardless, a failing callback to the consumer is reported with tb.Fatal, as
+// are any other errors.
+func (c *VRFCoordinatorV2) FulfillRandomWords(tb testing.TB, requestID *big.Int, words []*big.Int) {
+	tb.Helper()
+
+	var tx *types.Transaction
+	err := c.sim.AsMockedEntity(ethtest.ChainlinkVRFV2, func(opts *bind.TransactOpts) error {
+		var err error
+		tx, err = c.SimulatedVRFCoordinatorV2.FulfillRandomWords(opts, requestID, words)
+		return err
+	})
+	if err != nil {
+		tb.Fatalf("%T.FulfillRandomWords(%d, %d) error %v", c, requestID, words, err)
+	}
+
+	rcpt, err := c.sim.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		tb.Fatalf("%T.TransactionReceipt(%v) error %v", c.sim, tx.Hash(), err)
+	}
+	for _, l := range rcpt.Logs {
+		if l.Address != vrfCoordinatorV2 {
+			continue
+		}
+		ev, err := c.ParseRandomWordsFulfilled(*l)
+		if err != nil {
+			continue
+		}
+		if !ev.Success {
+			tb.Fatalf("%T.FulfillRandomWords(%d, %d): consumer callback failed", c, requestID, words)
+		}
+		return
+	}
+	tb.Fatalf("%T.FulfillRandomWords(%d, %d): no RandomWordsFulfilled event", c, requestID, words)
+}
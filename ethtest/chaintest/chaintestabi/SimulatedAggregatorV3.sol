@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+/**
+@notice A simulated Chainlink price feed, implementing AggregatorV3Interface,
+for use with ethier's chaintest Go package. Anyone can set the answer.
+ */
+contract SimulatedAggregatorV3 {
+    uint8 public immutable decimals;
+    string public description;
+    uint256 public constant version = 4;
+
+    struct Round {
+        int256 answer;
+        uint256 startedAt;
+        uint256 updatedAt;
+        uint80 answeredInRound;
+    }
+
+    /// @notice ID of the highest round for which data was set.
+    uint80 public latestRound;
+
+    mapping(uint80 => Round) private rounds;
+
+    event AnswerUpdated(
+        int256 indexed current,
+        uint256 indexed roundId,
+        uint256 updatedAt
+    );
+    event NewRound(
+        uint256 indexed roundId,
+        address indexed startedBy,
+        uint256 startedAt
+    );
+
+    constructor(
+        uint8 _decimals,
+        string memory _description,
+        int256 initialAnswer
+    ) {
+        decimals = _decimals;
+        description = _description;
+        setAnswer(initialAnswer);
+    }
+
+    /// @notice Sets the answer in a new round, updated now.
+    function setAnswer(int256 answer) public {
+        uint80 roundId = latestRound + 1;
+        setRoundData(
+            roundId,
+            answer,
+            block.timestamp,
+            block.timestamp,
+            roundId
+        );
+    }
+
+    /// @notice Sets arbitrary data for the round, e.g. to simulate stale data.
+    function setRoundData(
+        uint80 roundId,
+        int256 answer,
+        uint256 startedAt,
+        uint256 updatedAt,
+        uint80 answeredInRound
+    ) public {
+        rounds[roundId] = Round({
+            answer: answer,
+            startedAt: startedAt,
+            updatedAt: updatedAt,
+            answeredInRound: answeredInRound
+        });
+        if (roundId > latestRound) {
+            latestRound = roundId;
+        }
+
+        emit NewRound(roundId, msg.sender, startedAt);
+        emit AnswerUpdated(answer, roundId, updatedAt);
+    }
+
+    function getRoundData(uint80 _roundId)
+        public
+        view
+        returns (
+            uint80 roundId,
+            int256 answer,
+            uint256 startedAt,
+            uint256 updatedAt,
+            uint80 answeredInRound
+        )
+    {
+        Round memory r = rounds[_roundId];
+        require(r.updatedAt > 0, "No data present");
+        return (
+            _roundId,
+            r.answer,
+            r.startedAt,
+            r.updatedAt,
+            r.answeredInRound
+        );
+    }
+
+    function latestRoundData()
+        external
+        view
+        returns (
+            uint80 roundId,
+            int256 answer,
+            uint256 startedAt,
+            uint256 updatedAt,
+            uint80 answeredInRound
+        )
+    {
+        return getRoundData(latestRound);
+    }
+}
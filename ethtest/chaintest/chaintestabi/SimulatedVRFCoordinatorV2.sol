@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/access/Ownable.sol";
+
+/// @notice The callback implemented by Chainlink's VRFConsumerBaseV2.
+interface VRFConsumerV2 {
+    function rawFulfillRandomWords(
+        uint256 requestId,
+        uint256[] memory randomWords
+    ) external;
+}
+
+/**
+@notice A simulated Chainlink VRF v2 coordinator for use with ethier's chaintest
+Go package. Requests are recorded and only fulfilled, with arbitrary words, by
+the owner calling fulfillRandomWords().
+@dev Subscriptions aren't billed, but consumers MUST be added to them, as with
+the real coordinator.
+ */
+contract SimulatedVRFCoordinatorV2 is Ownable {
+    struct Request {
+        address consumer;
+        uint32 callbackGasLimit;
+        uint32 numWords;
+    }
+
+    /// @notice Number of subscriptions created, also the most recent ID.
+    uint64 public subscriptionCount;
+
+    /// @notice Subscription owners, who may add consumers.
+    mapping(uint64 => address) public subscriptionOwner;
+
+    /// @notice Consumers allowed to request random words per subscription.
+    mapping(uint64 => mapping(address => bool)) public isConsumer;
+
+    /// @notice Number of requests made, also the most recent ID.
+    uint256 public requestCount;
+
+    /// @notice Requests yet to be fulfilled.
+    mapping(uint256 => Request) public requests;
+
+    event SubscriptionCreated(uint64 indexed subId, address owner);
+    event SubscriptionConsumerAdded(uint64 indexed subId, address consumer);
+    event RandomWordsRequested(
+        bytes32 indexed keyHash,
+        uint256 requestId,
+        uint256 preSeed,
+        uint64 indexed subId,
+        uint16 minimumRequestConfirmations,
+        uint32 callbackGasLimit,
+        uint32 numWords,
+        address indexed sender
+    );
+    event RandomWordsFulfilled(
+        uint256 indexed requestId,
+        uint256 outputSeed,
+        uint96 payment,
+        bool success
+    );
+
+    function createSubscription() external returns (uint64 subId) {
+        subId = ++subscriptionCount;
+        subscriptionOwner[subId] = msg.sender;
+        emit SubscriptionCreated(subId, msg.sender);
+    }
+
+    function addConsumer(uint64 subId, address consumer) external {
+        require(
+            msg.sender == subscriptionOwner[subId],
+            "SimulatedVRFCoordinatorV2: only subscription owner"
+        );
+        isConsumer[subId][consumer] = true;
+        emit SubscriptionConsumerAdded(subId, consumer);
+    }
+
+    function requestRandomWords(
+        bytes32 keyHash,
+        uint64 subId,
+        uint16 minimumRequestConfirmations,
+        uint32 callbackGasLimit,
+        uint32 numWords
+    ) external returns (uint256 requestId) {
+        require(
+            isConsumer[subId][msg.sender],
+            "SimulatedVRFCoordinatorV2: invalid consumer"
+        );
+        require(numWords > 0, "SimulatedVRFCoordinatorV2: zero words");
+
+        requestId = ++requestCount;
+        requests[requestId] = Request({
+            consumer: msg.sender,
+            callbackGasLimit: callbackGasLimit,
+            numWords: numWords
+        });
+
+        emit RandomWordsRequested(
+            keyHash,
+            requestId,
+            requestId,
+            subId,
+            minimumRequestConfirmations,
+            callbackGasLimit,
+            numWords,
+            msg.sender
+        );
+    }
+
+    /**
+    @notice Fulfills the request by calling the consumer's
+    rawFulfillRandomWords() with the words. As with the real coordinator, a
+    failing callback doesn't revert, but is reported in the
+    RandomWordsFulfilled event.
+     */
+    function fulfillRandomWords(uint256 requestId, uint256[] memory words)
+        external
+        onlyOwner
+    {
+        Request memory req = requests[requestId];
+        require(
+            req.consumer != address(0),
+            "SimulatedVRFCoordinatorV2: unknown request"
+        );
+        require(
+            words.length == req.numWords,
+            "SimulatedVRFCoordinatorV2: incorrect number of words"
+        );
+        delete requests[requestId];
+
+        // solhint-disable-next-line avoid-low-level-calls
+        (bool success, ) = req.consumer.call{gas: req.callbackGasLimit}(
+            abi.encodeWithSelector(
+                VRFConsumerV2.rawFulfillRandomWords.selector,
+                requestId,
+                words
+            )
+        );
+        emit RandomWordsFulfilled(requestId, requestId, 0, success);
+    }
+}
@@ -0,0 +1,7 @@
+// Package chaintestabi is a generated package providing test doubles of
+// Chainlink's VRF v2 coordinator and price feeds. There is likely no need to
+// use this package directly as its functionality is exposed via the chaintest
+// package.
+package chaintestabi
+
+//go:generate ethier gen SimulatedAggregatorV3.sol SimulatedVRFCoordinatorV2.sol
@@ -0,0 +1,80 @@
+package chaintest
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/chaintest/chaintestabi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// An AggregatorV3 is a simulated Chainlink price feed, implementing
+// AggregatorV3Interface, whose answers are set from Go.
+type AggregatorV3 struct {
+	*chaintestabi.SimulatedAggregatorV3
+	// Address is the address of the deployed feed, to be passed to consuming
+	// contracts.
+	Address common.Address
+
+	sim     *ethtest.SimulatedBackend
+	account int
+}
+
+// DeployAggregatorV3 deploys a simulated price feed from the account, which is
+// also used to send all transactions setting answers. The feed's first round
+// has the initial answer, which is scaled by 10^decimals as with real feeds;
+// e.g. 8 decimals for USD pairs.
+func DeployAggregatorV3(sim *ethtest.SimulatedBackend, account int, decimals uint8, description string, answer *big.Int) (*AggregatorV3, error) {
+	addr, _, feed, err := chaintestabi.DeploySimulatedAggregatorV3(sim.Acc(account), sim, decimals, description, answer)
+	if err != nil {
+		return nil, fmt.Errorf("chaintestabi.DeploySimulatedAggregatorV3(%d, %q, %d) error %v", decimals, description, answer, err)
+	}
+	return &AggregatorV3{
+		SimulatedAggregatorV3: feed,
+		Address:               addr,
+		sim:                   sim,
+		account:               account,
+	}, nil
+}
+
+// DeployAggregatorV3TB calls DeployAggregatorV3() and reports any errors with
+// tb.Fatal.
+func DeployAggregatorV3TB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, decimals uint8, description string, answer *big.Int) *AggregatorV3 {
+	tb.Helper()
+
+	feed, err := DeployAggregatorV3(sim, account, decimals, description, answer)
+	if err != nil {
+		tb.Fatalf("chaintest.DeployAggregatorV3() error %v", err)
+	}
+	return feed
+}
+
+// SetAnswer sets the answer in a new round, started and updated at the time of
+// the latest block. Errors are reported with tb.Fatal.
+func (a *AggregatorV3) SetAnswer(tb testing.TB, answer *big.Int) {
+	tb.Helper()
+	a.sim.Must(tb, "SetAnswer(%d)", answer)(a.SimulatedAggregatorV3.SetAnswer(a.sim.Acc(a.account), answer))
+}
+
+// RoundData mirrors the values returned by AggregatorV3Interface's
+// getRoundData() and latestRoundData(); StartedAt and UpdatedAt are Unix
+// timestamps.
+type RoundData struct {
+	RoundID, Answer, StartedAt, UpdatedAt, AnsweredInRound *big.Int
+}
+
+// SetRoundData sets arbitrary data for a round, e.g. to simulate stale or
+// incomplete answers. If the round ID is higher than all previous ones, it
+// becomes the latest round. A nil AnsweredInRound defaults to RoundID. Errors
+// are reported with tb.Fatal.
+func (a *AggregatorV3) SetRoundData(tb testing.TB, r RoundData) {
+	tb.Helper()
+
+	answeredIn := r.AnsweredInRound
+	if answeredIn == nil {
+		answeredIn = r.RoundID
+	}
+	a.sim.Must(tb, "SetRoundData(%+v)", r)(a.SimulatedAggregatorV3.SetRoundData(a.sim.Acc(a.account), r.RoundID, r.Answer, r.StartedAt, r.UpdatedAt, answeredIn))
+}
@@ -0,0 +1,129 @@
+// Package delegatetest provides a simulated delegate.cash registry, allowing
+// contracts that honour delegation from vaults to hot wallets, e.g. for claims,
+// to be tested against realistic delegation state.
+//
+// The simulated backend can't reproduce the registry's canonical deployment
+// so, as with all other test doubles, it is instead deployed to a deterministic
+// address. Contracts under test MUST therefore accept the registry's address,
+// RegistryAddress(), instead of hard-coding the canonical one.
+package delegatetest
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/delegatetest/delegatetestabi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// registry is the address at which the simulated registry is deployed by
+// DeployRegistry(). This is deterministic because mocked entities in
+// ethtest.SimulatedBackend have deterministic keys.
+var registry = common.HexToAddress("0xb7D7238F76Cc9e951E502fc103973Cb092b60DA9")
+
+// RegistryAddress returns the address at which DeployRegistry() deploys the
+// simulated delegate.cash registry.
+func RegistryAddress() common.Address {
+	return registry
+}
+
+// DeployRegistry deploys a simulated delegate.cash registry to the
+// SimulatedBackend.
+//
+// This function MUST only be called once for each SimulatedBackend; all future
+// calls will deploy to a different address to the one returned by
+// RegistryAddress().
+func DeployRegistry(sim *ethtest.SimulatedBackend) (*delegatetestabi.SimulatedDelegationRegistry, error) {
+	var reg *delegatetestabi.SimulatedDelegationRegistry
+	err := sim.AsMockedEntity(ethtest.DelegateCash, func(opts *bind.TransactOpts) error {
+		addr, _, r, err := delegatetestabi.DeploySimulatedDelegationRegistry(opts, sim)
+		if err != nil {
+			return fmt.Errorf("delegatetestabi.DeploySimulatedDelegationRegistry() error %v", err)
+		}
+		if addr != registry {
+			return fmt.Errorf("unexpected deployment address %v; want %v", addr, registry)
+		}
+		reg = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// DeployRegistryTB calls DeployRegistry() and reports any errors with
+// tb.Fatal.
+func DeployRegistryTB(tb testing.TB, sim *ethtest.SimulatedBackend) *delegatetestabi.SimulatedDelegationRegistry {
+	tb.Helper()
+
+	reg, err := DeployRegistry(sim)
+	if err != nil {
+		tb.Fatalf("delegatetest.DeployRegistry() error %v", err)
+	}
+	return reg
+}
+
+// newRegistry returns a binding of the registry at RegistryAddress().
+func newRegistry(sim *ethtest.SimulatedBackend) (*delegatetestabi.SimulatedDelegationRegistry, error) {
+	reg, err := delegatetestabi.NewSimulatedDelegationRegistry(registry, sim)
+	if err != nil {
+		return nil, fmt.Errorf("delegatetestabi.NewSimulatedDelegationRegistry(%v): %v", registry, err)
+	}
+	return reg, nil
+}
+
+// DelegateForAll sets whether the delegate may act on behalf of the vault
+// account for all of its assets.
+func DelegateForAll(sim *ethtest.SimulatedBackend, vault int, delegate common.Address, value bool) (*types.Transaction, error) {
+	reg, err := newRegistry(sim)
+	if err != nil {
+		return nil, err
+	}
+	return reg.DelegateForAll(sim.Acc(vault), delegate, value)
+}
+
+// DelegateForAllTB calls DelegateForAll() and reports any errors with
+// tb.Fatal.
+func DelegateForAllTB(tb testing.TB, sim *ethtest.SimulatedBackend, vault int, delegate common.Address, value bool) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "DelegateForAll(vault %d, %v, %t)", vault, delegate, value)(DelegateForAll(sim, vault, delegate, value))
+}
+
+// DelegateForContract sets whether the delegate may act on behalf of the vault
+// account for all of its assets in the contract.
+func DelegateForContract(sim *ethtest.SimulatedBackend, vault int, delegate, contract common.Address, value bool) (*types.Transaction, error) {
+	reg, err := newRegistry(sim)
+	if err != nil {
+		return nil, err
+	}
+	return reg.DelegateForContract(sim.Acc(vault), delegate, contract, value)
+}
+
+// DelegateForContractTB calls DelegateForContract() and reports any errors
+// with tb.Fatal.
+func DelegateForContractTB(tb testing.TB, sim *ethtest.SimulatedBackend, vault int, delegate, contract common.Address, value bool) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "DelegateForContract(vault %d, %v, %v, %t)", vault, delegate, contract, value)(DelegateForContract(sim, vault, delegate, contract, value))
+}
+
+// DelegateForToken sets whether the delegate may act on behalf of the vault
+// account for a single token.
+func DelegateForToken(sim *ethtest.SimulatedBackend, vault int, delegate, contract common.Address, tokenID *big.Int, value bool) (*types.Transaction, error) {
+	reg, err := newRegistry(sim)
+	if err != nil {
+		return nil, err
+	}
+	return reg.DelegateForToken(sim.Acc(vault), delegate, contract, tokenID, value)
+}
+
+// DelegateForTokenTB calls DelegateForToken() and reports any errors with
+// tb.Fatal.
+func DelegateForTokenTB(tb testing.TB, sim *ethtest.SimulatedBackend, vault int, delegate, contract common.Address, tokenID *big.Int, value bool) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "DelegateForToken(vault %d, %v, %v, %d, %t)", vault, delegate, contract, tokenID, value)(DelegateForToken(sim, vault, delegate, contract, tokenID, value))
+}
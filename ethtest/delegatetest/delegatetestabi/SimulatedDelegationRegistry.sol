@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../../contracts/thirdparty/delegatecash/IDelegationRegistry.sol";
+
+/**
+@notice A simulated delegate.cash registry for use with ethier's delegatetest Go
+package. Unlike the real registry, delegations aren't enumerable and can't be
+revoked en masse.
+ */
+contract SimulatedDelegationRegistry is IDelegationRegistry {
+    /// @dev Keyed by vault then delegate.
+    mapping(address => mapping(address => bool)) private forAll;
+
+    /// @dev Keyed by vault, delegate, then contract.
+    mapping(address => mapping(address => mapping(address => bool)))
+        private forContract;
+
+    /// @dev Keyed by vault, delegate, contract, then token ID.
+    mapping(address => mapping(address => mapping(address => mapping(uint256 => bool))))
+        private forToken;
+
+    function delegateForAll(address delegate, bool value) external {
+        forAll[msg.sender][delegate] = value;
+        emit DelegateForAll(msg.sender, delegate, value);
+    }
+
+    function delegateForContract(
+        address delegate,
+        address contract_,
+        bool value
+    ) external {
+        forContract[msg.sender][delegate][contract_] = value;
+        emit DelegateForContract(msg.sender, delegate, contract_, value);
+    }
+
+    function delegateForToken(
+        address delegate,
+        address contract_,
+        uint256 tokenId,
+        bool value
+    ) external {
+        forToken[msg.sender][delegate][contract_][tokenId] = value;
+        emit DelegateForToken(msg.sender, delegate, contract_, tokenId, value);
+    }
+
+    function checkDelegateForAll(address delegate, address vault)
+        public
+        view
+        returns (bool)
+    {
+        return forAll[vault][delegate];
+    }
+
+    function checkDelegateForContract(
+        address delegate,
+        address vault,
+        address contract_
+    ) public view returns (bool) {
+        return
+            checkDelegateForAll(delegate, vault) ||
+            forContract[vault][delegate][contract_];
+    }
+
+    function checkDelegateForToken(
+        address delegate,
+        address vault,
+        address contract_,
+        uint256 tokenId
+    ) external view returns (bool) {
+        return
+            checkDelegateForContract(delegate, vault, contract_) ||
+            forToken[vault][delegate][contract_][tokenId];
+    }
+}
@@ -0,0 +1,6 @@
+// Package delegatetestabi is a generated package providing a simulated
+// delegate.cash registry. There is likely no need to use this package directly
+// as its functionality is exposed via the delegatetest package.
+package delegatetestabi
+
+//go:generate ethier gen SimulatedDelegationRegistry.sol
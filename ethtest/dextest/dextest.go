@@ -0,0 +1,171 @@
+// Package dextest provides minimal simulated Uniswap V2 pairs and V3 pools,
+// with directly configurable reserves and prices, for testing contracts that
+// quote or accept payment via DEX pricing.
+//
+// The simulated pairs and pools only expose the state read by price-dependent
+// contracts; they don't support liquidity provision, swaps, or (V3) oracle
+// observations. As with Uniswap, a pool's token0 MUST sort before its token1;
+// see SortTokens().
+package dextest
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/dextest/dextestabi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SortTokens returns the tokens in the order used by Uniswap pools; i.e.
+// token0 has the lower address.
+func SortTokens(a, b common.Address) (token0, token1 common.Address) {
+	if bytes.Compare(a.Bytes(), b.Bytes()) < 0 {
+		return a, b
+	}
+	return b, a
+}
+
+// checkSorted returns an error if token0 doesn't sort before token1.
+func checkSorted(token0, token1 common.Address) error {
+	if t0, _ := SortTokens(token0, token1); t0 != token0 || token0 == token1 {
+		return fmt.Errorf("tokens %v and %v not sorted; see dextest.SortTokens()", token0, token1)
+	}
+	return nil
+}
+
+// A V2Pair is a simulated Uniswap V2 pair.
+type V2Pair struct {
+	*dextestabi.SimulatedUniswapV2Pair
+	Address common.Address
+
+	sim     *ethtest.SimulatedBackend
+	account int
+}
+
+// DeployV2Pair deploys a simulated Uniswap V2 pair of the tokens, with the
+// respective reserves, from the account, which is also used to send all
+// transactions modifying the pair.
+func DeployV2Pair(sim *ethtest.SimulatedBackend, account int, token0, token1 common.Address, reserve0, reserve1 *big.Int) (*V2Pair, error) {
+	if err := checkSorted(token0, token1); err != nil {
+		return nil, err
+	}
+	addr, _, pair, err := dextestabi.DeploySimulatedUniswapV2Pair(sim.Acc(account), sim, token0, token1, reserve0, reserve1)
+	if err != nil {
+		return nil, fmt.Errorf("dextestabi.DeploySimulatedUniswapV2Pair(%v, %v, %d, %d) error %v", token0, token1, reserve0, reserve1, err)
+	}
+	return &V2Pair{
+		SimulatedUniswapV2Pair: pair,
+		Address:                addr,
+		sim:                    sim,
+		account:                account,
+	}, nil
+}
+
+// DeployV2PairTB calls DeployV2Pair() and reports any errors with tb.Fatal.
+func DeployV2PairTB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, token0, token1 common.Address, reserve0, reserve1 *big.Int) *V2Pair {
+	tb.Helper()
+
+	p, err := DeployV2Pair(sim, account, token0, token1, reserve0, reserve1)
+	if err != nil {
+		tb.Fatalf("dextest.DeployV2Pair() error %v", err)
+	}
+	return p
+}
+
+// SetReserves sets the pair's reserves, as if updated at the time of the
+// latest block. Errors are reported with tb.Fatal.
+func (p *V2Pair) SetReserves(tb testing.TB, reserve0, reserve1 *big.Int) {
+	tb.Helper()
+	p.sim.Must(tb, "SetReserves(%d, %d)", reserve0, reserve1)(p.SimulatedUniswapV2Pair.SetReserves(p.sim.Acc(p.account), reserve0, reserve1))
+}
+
+// A V3Pool is a simulated Uniswap V3 pool.
+type V3Pool struct {
+	*dextestabi.SimulatedUniswapV3Pool
+	Address common.Address
+
+	sim     *ethtest.SimulatedBackend
+	account int
+}
+
+// DeployV3Pool deploys a simulated Uniswap V3 pool of the tokens, with the fee
+// in hundredths of a basis point, from the account, which is also used to send
+// all transactions modifying the pool. The pool's current price is that of the
+// tick; i.e. 1.0001^tick units of token1 per unit of token0.
+func DeployV3Pool(sim *ethtest.SimulatedBackend, account int, token0, token1 common.Address, fee uint32, tick int) (*V3Pool, error) {
+	if err := checkSorted(token0, token1); err != nil {
+		return nil, err
+	}
+	addr, _, pool, err := dextestabi.DeploySimulatedUniswapV3Pool(sim.Acc(account), sim, token0, token1, big.NewInt(int64(fee)), SqrtPriceX96AtTick(tick), big.NewInt(int64(tick)))
+	if err != nil {
+		return nil, fmt.Errorf("dextestabi.DeploySimulatedUniswapV3Pool(%v, %v, %d, tick %d) error %v", token0, token1, fee, tick, err)
+	}
+	return &V3Pool{
+		SimulatedUniswapV3Pool: pool,
+		Address:                addr,
+		sim:                    sim,
+		account:                account,
+	}, nil
+}
+
+// DeployV3PoolTB calls DeployV3Pool() and reports any errors with tb.Fatal.
+func DeployV3PoolTB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, token0, token1 common.Address, fee uint32, tick int) *V3Pool {
+	tb.Helper()
+
+	p, err := DeployV3Pool(sim, account, token0, token1, fee, tick)
+	if err != nil {
+		tb.Fatalf("dextest.DeployV3Pool() error %v", err)
+	}
+	return p
+}
+
+// SetTick sets the pool's current tick, and the square-root price to
+// SqrtPriceX96AtTick(tick). Errors are reported with tb.Fatal.
+func (p *V3Pool) SetTick(tb testing.TB, tick int) {
+	tb.Helper()
+	p.sim.Must(tb, "SetPrice(<tick %d>)", tick)(p.SetPrice(p.sim.Acc(p.account), SqrtPriceX96AtTick(tick), big.NewInt(int64(tick))))
+}
+
+// SetLiquidity sets the pool's in-range liquidity. Errors are reported with
+// tb.Fatal.
+func (p *V3Pool) SetLiquidity(tb testing.TB, liquidity *big.Int) {
+	tb.Helper()
+	p.sim.Must(tb, "SetLiquidity(%d)", liquidity)(p.SimulatedUniswapV3Pool.SetLiquidity(p.sim.Acc(p.account), liquidity))
+}
+
+// sqrtTickBase is sqrt(1.0001), the square-root price ratio between adjacent
+// ticks.
+var sqrtTickBase = func() *big.Float {
+	f := new(big.Float).SetPrec(512)
+	f.SetString("1.0001")
+	return f.Sqrt(f)
+}()
+
+// SqrtPriceX96AtTick returns sqrt(1.0001^tick) as a Q64.96 fixed-point number,
+// rounded down. This is the exact value, which MAY differ from Uniswap's
+// TickMath.getSqrtRatioAtTick() in its least-significant bits.
+func SqrtPriceX96AtTick(tick int) *big.Int {
+	n := tick
+	if n < 0 {
+		n = -n
+	}
+
+	ratio := new(big.Float).SetPrec(512).SetInt64(1)
+	pow := new(big.Float).Copy(sqrtTickBase)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			ratio.Mul(ratio, pow)
+		}
+		pow.Mul(pow, pow)
+	}
+	if tick < 0 {
+		ratio.Quo(new(big.Float).SetPrec(512).SetInt64(1), ratio)
+	}
+
+	ratio.SetMantExp(ratio, 96)
+	x, _ := ratio.Int(nil)
+	return x
+}
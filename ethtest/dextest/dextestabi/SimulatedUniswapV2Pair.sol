@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+/**
+@notice A minimal simulated Uniswap V2 pair, exposing the pair's tokens and
+reserves, for use with ethier's dextest Go package. Reserves are set directly
+instead of by providing liquidity and swapping.
+ */
+contract SimulatedUniswapV2Pair {
+    address public immutable token0;
+    address public immutable token1;
+
+    uint112 private reserve0;
+    uint112 private reserve1;
+    uint32 private blockTimestampLast;
+
+    event Sync(uint112 reserve0, uint112 reserve1);
+
+    /// @dev As with Uniswap, token0 MUST sort before token1.
+    constructor(
+        address _token0,
+        address _token1,
+        uint112 _reserve0,
+        uint112 _reserve1
+    ) {
+        require(_token0 < _token1, "SimulatedUniswapV2Pair: unsorted tokens");
+        token0 = _token0;
+        token1 = _token1;
+        setReserves(_reserve0, _reserve1);
+    }
+
+    function getReserves()
+        external
+        view
+        returns (
+            uint112 _reserve0,
+            uint112 _reserve1,
+            uint32 _blockTimestampLast
+        )
+    {
+        return (reserve0, reserve1, blockTimestampLast);
+    }
+
+    /// @notice Sets the reserves, as if updated at the current block.
+    function setReserves(uint112 _reserve0, uint112 _reserve1) public {
+        reserve0 = _reserve0;
+        reserve1 = _reserve1;
+        blockTimestampLast = uint32(block.timestamp);
+        emit Sync(_reserve0, _reserve1);
+    }
+}
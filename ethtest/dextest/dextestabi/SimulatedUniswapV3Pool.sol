@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+/**
+@notice A minimal simulated Uniswap V3 pool, exposing the pool's tokens, fee,
+liquidity, and current price, for use with ethier's dextest Go package. The
+price is set directly instead of by swapping, and oracle observations are not
+supported.
+ */
+contract SimulatedUniswapV3Pool {
+    address public immutable token0;
+    address public immutable token1;
+    uint24 public immutable fee;
+
+    uint128 public liquidity;
+
+    struct Slot0 {
+        uint160 sqrtPriceX96;
+        int24 tick;
+        uint16 observationIndex;
+        uint16 observationCardinality;
+        uint16 observationCardinalityNext;
+        uint8 feeProtocol;
+        bool unlocked;
+    }
+
+    /// @notice Mirrors the Uniswap V3 pool's slot0() getter.
+    Slot0 public slot0;
+
+    /// @dev As with Uniswap, token0 MUST sort before token1.
+    constructor(
+        address _token0,
+        address _token1,
+        uint24 _fee,
+        uint160 sqrtPriceX96,
+        int24 tick
+    ) {
+        require(_token0 < _token1, "SimulatedUniswapV3Pool: unsorted tokens");
+        token0 = _token0;
+        token1 = _token1;
+        fee = _fee;
+        setPrice(sqrtPriceX96, tick);
+    }
+
+    /**
+    @notice Sets the current price. The caller is responsible for the
+    consistency of the square-root price and the tick.
+     */
+    function setPrice(uint160 sqrtPriceX96, int24 tick) public {
+        slot0 = Slot0({
+            sqrtPriceX96: sqrtPriceX96,
+            tick: tick,
+            observationIndex: 0,
+            observationCardinality: 1,
+            observationCardinalityNext: 1,
+            feeProtocol: 0,
+            unlocked: true
+        });
+    }
+
+    /// @notice Sets the in-range liquidity.
+    function setLiquidity(uint128 _liquidity) external {
+        liquidity = _liquidity;
+    }
+}
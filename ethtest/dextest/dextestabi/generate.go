@@ -0,0 +1,6 @@
+// Package dextestabi is a generated package providing simulated Uniswap V2
+// pairs and V3 pools. There is likely no need to use this package directly as
+// its functionality is exposed via the dextest package.
+package dextestabi
+
+//go:generate ethier gen SimulatedUniswapV2Pair.sol SimulatedUniswapV3Pool.sol
@@ -0,0 +1,201 @@
+// Package enstest provides a deployment of the ENS registry, a public resolver,
+// and the reverse registrar, allowing contracts and tooling that resolve ENS
+// names to be tested offline.
+//
+// Only the .eth TLD is available, and names are registered directly, with
+// Register(), instead of via the commit-reveal process of the real registrar.
+// As with all other test doubles, contracts are deployed to deterministic
+// addresses, not canonical ones, so the registry MUST be located via
+// Addresses().Registry.
+package enstest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/enstest/enstestabi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Contracts carries addresses for ENS contracts.
+type Contracts struct {
+	Registry, ReverseRegistrar, Resolver common.Address
+}
+
+// Addresses returns the addresses to which Deploy deploys ENS contracts.
+func Addresses() Contracts {
+	return addresses
+}
+
+var addresses = Contracts{
+	Registry:         common.HexToAddress("0xcE41F08B4eB01f88669E862340d08ECea8708Ac7"),
+	ReverseRegistrar: common.HexToAddress("0x544777c23AA89F4ab9A9DeAee6ba92AF4461115c"),
+	Resolver:         common.HexToAddress("0x25A00444Ba06f7D884219C43Ccc5976c039245EB"),
+}
+
+// simulatedENS is the address of the contract that deploys all others and acts
+// as the .eth registrar.
+var simulatedENS = common.HexToAddress("0xCD943262983C3D5a38A4FBFcfb409340C6292F1d")
+
+// Deploy deploys the ENS registry, public resolver, and reverse registrar to
+// the SimulatedBackend, returning a binding of the registry.
+//
+// This function MUST only be called once for each SimulatedBackend; all future
+// calls will deploy to different addresses to those returned by Addresses().
+func Deploy(sim *ethtest.SimulatedBackend) (*enstestabi.ENSRegistry, error) {
+	err := sim.AsMockedEntity(ethtest.ENS, func(opts *bind.TransactOpts) error {
+		addr, _, s, err := enstestabi.DeploySimulatedENS(opts, sim)
+		if err != nil {
+			return fmt.Errorf("enstestabi.DeploySimulatedENS() error %v", err)
+		}
+		if addr != simulatedENS {
+			return fmt.Errorf("unexpected deployment address %v; want %v", addr, simulatedENS)
+		}
+
+		reg, err := s.Registry(nil)
+		if err != nil {
+			return fmt.Errorf("%T.Registry(): %v", s, err)
+		}
+		rev, err := s.ReverseRegistrar(nil)
+		if err != nil {
+			return fmt.Errorf("%T.ReverseRegistrar(): %v", s, err)
+		}
+		res, err := s.Resolver(nil)
+		if err != nil {
+			return fmt.Errorf("%T.Resolver(): %v", s, err)
+		}
+
+		deployed := Contracts{
+			Registry:         reg,
+			ReverseRegistrar: rev,
+			Resolver:         res,
+		}
+		if want := addresses; !cmp.Equal(deployed, want) {
+			return fmt.Errorf("unexpected deployment addresses %+v; expecting %+v", deployed, want)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return enstestabi.NewENSRegistry(addresses.Registry, sim)
+}
+
+// DeployTB calls Deploy() and reports any errors with tb.Fatal.
+func DeployTB(tb testing.TB, sim *ethtest.SimulatedBackend) *enstestabi.ENSRegistry {
+	tb.Helper()
+
+	reg, err := Deploy(sim)
+	if err != nil {
+		tb.Fatalf("enstest.Deploy() error %v", err)
+	}
+	return reg
+}
+
+// Namehash returns the ENS namehash of the name, as defined by EIP-137. The
+// name MUST already be normalised.
+func Namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// reverseName returns the name of the address's reverse record.
+func reverseName(addr common.Address) string {
+	return fmt.Sprintf("%x.addr.reverse", addr.Bytes())
+}
+
+// Register registers <label>.eth to the owner, with the public resolver, and
+// sets the name's address record to the owner.
+func Register(sim *ethtest.SimulatedBackend, label string, owner common.Address) (*types.Transaction, error) {
+	s, err := enstestabi.NewSimulatedENS(simulatedENS, sim)
+	if err != nil {
+		return nil, fmt.Errorf("enstestabi.NewSimulatedENS(%v): %v", simulatedENS, err)
+	}
+
+	var tx *types.Transaction
+	err = sim.AsMockedEntity(ethtest.ENS, func(opts *bind.TransactOpts) error {
+		var err error
+		tx, err = s.Register(opts, label, owner)
+		return err
+	})
+	return tx, err
+}
+
+// RegisterTB calls Register() and reports any errors with tb.Fatal.
+func RegisterTB(tb testing.TB, sim *ethtest.SimulatedBackend, label string, owner common.Address) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "Register(%q, %v)", label, owner)(Register(sim, label, owner))
+}
+
+// SetReverseName sets the account's reverse record, used for lookups by
+// ReverseResolve(), to the name. As with the real reverse registrar, the name
+// isn't required to resolve to the account.
+func SetReverseName(sim *ethtest.SimulatedBackend, account int, name string) (*types.Transaction, error) {
+	rev, err := enstestabi.NewReverseRegistrar(addresses.ReverseRegistrar, sim)
+	if err != nil {
+		return nil, fmt.Errorf("enstestabi.NewReverseRegistrar(%v): %v", addresses.ReverseRegistrar, err)
+	}
+	return rev.SetName(sim.Acc(account), name)
+}
+
+// SetReverseNameTB calls SetReverseName() and reports any errors with
+// tb.Fatal.
+func SetReverseNameTB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, name string) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "SetReverseName(account %d, %q)", account, name)(SetReverseName(sim, account, name))
+}
+
+// resolver returns a binding of the resolver of the node, or nil if it has
+// none.
+func resolver(ctx context.Context, sim *ethtest.SimulatedBackend, node common.Hash) (*enstestabi.PublicResolver, error) {
+	reg, err := enstestabi.NewENSRegistry(addresses.Registry, sim)
+	if err != nil {
+		return nil, fmt.Errorf("enstestabi.NewENSRegistry(%v): %v", addresses.Registry, err)
+	}
+	addr, err := reg.Resolver(&bind.CallOpts{Context: ctx}, node)
+	if err != nil {
+		return nil, fmt.Errorf("%T.Resolver(%v): %v", reg, node, err)
+	}
+	if addr == (common.Address{}) {
+		return nil, nil
+	}
+	return enstestabi.NewPublicResolver(addr, sim)
+}
+
+// Resolve returns the address record of the name, or the zero address if it
+// has none.
+func Resolve(ctx context.Context, sim *ethtest.SimulatedBackend, name string) (common.Address, error) {
+	node := Namehash(name)
+	res, err := resolver(ctx, sim, node)
+	if err != nil || res == nil {
+		return common.Address{}, err
+	}
+	return res.Addr(&bind.CallOpts{Context: ctx}, node)
+}
+
+// ReverseResolve returns the name in the address's reverse record, or an
+// empty string if it has none. The name is not checked against its forward
+// resolution.
+func ReverseResolve(ctx context.Context, sim *ethtest.SimulatedBackend, addr common.Address) (string, error) {
+	node := Namehash(reverseName(addr))
+	res, err := resolver(ctx, sim, node)
+	if err != nil || res == nil {
+		return "", err
+	}
+	return res.Name(&bind.CallOpts{Context: ctx}, node)
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.17 <0.9.0;
+
+import "@ensdomains/ens-contracts/contracts/registry/ENSRegistry.sol";
+import "@ensdomains/ens-contracts/contracts/registry/ReverseRegistrar.sol";
+import "@ensdomains/ens-contracts/contracts/resolvers/PublicResolver.sol";
+import "@ensdomains/ens-contracts/contracts/wrapper/INameWrapper.sol";
+import "@openzeppelin/contracts/access/Ownable.sol";
+
+/**
+@notice Deploys the ENS registry, a public resolver, and the reverse registrar,
+for use with ethier's enstest Go package. The contract owns the .eth TLD and
+acts as its registrar, without the commit-reveal and fees of the real one.
+ */
+contract SimulatedENS is Ownable {
+    ENSRegistry public immutable registry;
+    ReverseRegistrar public immutable reverseRegistrar;
+    PublicResolver public immutable resolver;
+
+    /// @notice namehash("eth")
+    bytes32 public constant ETH_NODE =
+        0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae;
+
+    /// @notice namehash("reverse")
+    bytes32 private constant REVERSE_NODE =
+        0xa097f6721ce401e757d1223a763fef49b8b5f90bb18567ddb86fd205dff71d34;
+
+    constructor() {
+        registry = new ENSRegistry();
+        reverseRegistrar = new ReverseRegistrar(registry);
+        resolver = new PublicResolver(
+            registry,
+            INameWrapper(address(0)),
+            address(this),
+            address(reverseRegistrar)
+        );
+
+        registry.setSubnodeOwner(
+            bytes32(0),
+            keccak256("reverse"),
+            address(this)
+        );
+        registry.setSubnodeOwner(
+            REVERSE_NODE,
+            keccak256("addr"),
+            address(reverseRegistrar)
+        );
+        reverseRegistrar.setDefaultResolver(address(resolver));
+
+        registry.setSubnodeOwner(bytes32(0), keccak256("eth"), address(this));
+    }
+
+    /**
+    @notice Registers <label>.eth to the owner, using the public resolver, and
+    sets its address record to the owner.
+     */
+    function register(string calldata label, address owner)
+        external
+        onlyOwner
+        returns (bytes32)
+    {
+        bytes32 node = registry.setSubnodeRecord(
+            ETH_NODE,
+            keccak256(bytes(label)),
+            address(this),
+            address(resolver),
+            0
+        );
+        resolver.setAddr(node, owner);
+        registry.setOwner(node, owner);
+        return node;
+    }
+}
@@ -0,0 +1,7 @@
+// Package enstestabi is a generated package providing the ENS registry, public
+// resolver, and reverse registrar. There is likely no need to use this package
+// directly as its functionality is exposed via the enstest package.
+package enstestabi
+
+// The public resolver exceeds the contract-size limit unless optimised.
+//go:generate ethier gen --optimize SimulatedENS.sol
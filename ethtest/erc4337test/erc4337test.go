@@ -0,0 +1,336 @@
+// Package erc4337test provides a deployment of the ERC-4337 account-abstraction
+// EntryPoint, along with a factory of SimpleAccount smart accounts, allowing
+// ethier contracts to be tested end to end when called by smart accounts.
+//
+// UserOperations are built and signed from Go, with NewAccount() and
+// Account.UserOperation(), and submitted in bundles with HandleOps().
+//
+// The simulated backend can't reproduce the EntryPoint's canonical deployment
+// so, as with all other test doubles, contracts are instead deployed to
+// deterministic addresses. UserOperations MUST therefore be signed against
+// Addresses().EntryPoint.
+package erc4337test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/erc4337test/erc4337testabi"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Contracts carries addresses for ERC-4337 contracts.
+type Contracts struct {
+	EntryPoint, AccountFactory common.Address
+}
+
+// Addresses returns the addresses to which Deploy deploys ERC-4337 contracts.
+func Addresses() Contracts {
+	return addresses
+}
+
+var addresses = Contracts{
+	EntryPoint:     common.HexToAddress("0xf63F69CA7F10633ff79c718Bee2C596E65bD40c4"),
+	AccountFactory: common.HexToAddress("0xEb4EE85f9607b48E397a9Ee64ac5A7529138636d"),
+}
+
+// Deploy deploys the EntryPoint and a SimpleAccountFactory to the
+// SimulatedBackend, returning a binding of the former.
+//
+// This function MUST only be called once for each SimulatedBackend; all future
+// calls will deploy to different addresses to those returned by Addresses().
+func Deploy(sim *ethtest.SimulatedBackend) (*erc4337testabi.EntryPoint, error) {
+	err := sim.AsMockedEntity(ethtest.AccountAbstraction, func(opts *bind.TransactOpts) error {
+		_, _, s, err := erc4337testabi.DeploySimulatedEntryPoint(opts, sim)
+		if err != nil {
+			return fmt.Errorf("erc4337testabi.DeploySimulatedEntryPoint() error %v", err)
+		}
+
+		ep, err := s.EntryPoint(nil)
+		if err != nil {
+			return fmt.Errorf("%T.EntryPoint(): %v", s, err)
+		}
+		factory, err := s.AccountFactory(nil)
+		if err != nil {
+			return fmt.Errorf("%T.AccountFactory(): %v", s, err)
+		}
+
+		deployed := Contracts{
+			EntryPoint:     ep,
+			AccountFactory: factory,
+		}
+		if want := addresses; !cmp.Equal(deployed, want) {
+			return fmt.Errorf("unexpected deployment addresses %+v; expecting %+v", deployed, want)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return erc4337testabi.NewEntryPoint(addresses.EntryPoint, sim)
+}
+
+// DeployTB calls Deploy() and reports any errors with tb.Fatal.
+func DeployTB(tb testing.TB, sim *ethtest.SimulatedBackend) *erc4337testabi.EntryPoint {
+	tb.Helper()
+
+	ep, err := Deploy(sim)
+	if err != nil {
+		tb.Fatalf("erc4337test.Deploy() error %v", err)
+	}
+	return ep
+}
+
+// An Account is a SimpleAccount smart account, deployed by the factory at
+// Addresses().AccountFactory, and owned by a signer. The Account's Address is
+// counterfactual so it can be funded before the account's deployment, which
+// occurs with its first UserOperation.
+type Account struct {
+	Address common.Address
+	Owner   eth.SignerBackend
+	Salt    *big.Int
+
+	sim *ethtest.SimulatedBackend
+}
+
+// NewAccount returns the Account owned by the signer, with the salt
+// differentiating between multiple accounts with the same owner.
+func NewAccount(sim *ethtest.SimulatedBackend, owner eth.SignerBackend, salt *big.Int) (*Account, error) {
+	factory, err := erc4337testabi.NewSimpleAccountFactory(addresses.AccountFactory, sim)
+	if err != nil {
+		return nil, fmt.Errorf("erc4337testabi.NewSimpleAccountFactory(%v): %v", addresses.AccountFactory, err)
+	}
+	addr, err := factory.GetAddress(nil, owner.Address(), salt)
+	if err != nil {
+		return nil, fmt.Errorf("%T.GetAddress(%v, %d): %v", factory, owner.Address(), salt, err)
+	}
+	return &Account{
+		Address: addr,
+		Owner:   owner,
+		Salt:    salt,
+		sim:     sim,
+	}, nil
+}
+
+// NewAccountTB calls NewAccount() and reports any errors with tb.Fatal.
+func NewAccountTB(tb testing.TB, sim *ethtest.SimulatedBackend, owner eth.SignerBackend, salt *big.Int) *Account {
+	tb.Helper()
+
+	a, err := NewAccount(sim, owner, salt)
+	if err != nil {
+		tb.Fatalf("erc4337test.NewAccount() error %v", err)
+	}
+	return a
+}
+
+// Default gas limits of UserOperations returned by Account.UserOperation().
+const (
+	DefaultCallGasLimit         = 1e6
+	DefaultVerificationGasLimit = 1e6
+	DefaultPreVerificationGas   = 5e4
+)
+
+// UserOperation returns a signed UserOperation that calls the destination
+// contract from the Account, sending it the value and data. If the Account is
+// yet to be deployed, the UserOperation's InitCode deploys it.
+//
+// Gas limits are set to the Default* constants, and fees to the backend's
+// suggested gas price. Any changes to the returned UserOperation MUST be
+// followed by a call to SignUserOperation().
+func (a *Account) UserOperation(dest common.Address, value *big.Int, data []byte) (erc4337testabi.UserOperation, error) {
+	ctx := context.Background()
+
+	account, err := erc4337testabi.SimpleAccountMetaData.GetAbi()
+	if err != nil {
+		return erc4337testabi.UserOperation{}, fmt.Errorf("%T.GetAbi(): %v", erc4337testabi.SimpleAccountMetaData, err)
+	}
+	callData, err := account.Pack("execute", dest, value, data)
+	if err != nil {
+		return erc4337testabi.UserOperation{}, fmt.Errorf("%T.Pack(\"execute\", %v, %d, %#x): %v", account, dest, value, data, err)
+	}
+
+	var initCode []byte
+	code, err := a.sim.CodeAt(ctx, a.Address, nil)
+	if err != nil {
+		return erc4337testabi.UserOperation{}, fmt.Errorf("%T.CodeAt(%v): %v", a.sim, a.Address, err)
+	}
+	if len(code) == 0 {
+		factory, err := erc4337testabi.SimpleAccountFactoryMetaData.GetAbi()
+		if err != nil {
+			return erc4337testabi.UserOperation{}, fmt.Errorf("%T.GetAbi(): %v", erc4337testabi.SimpleAccountFactoryMetaData, err)
+		}
+		create, err := factory.Pack("createAccount", a.Owner.Address(), a.Salt)
+		if err != nil {
+			return erc4337testabi.UserOperation{}, fmt.Errorf("%T.Pack(\"createAccount\", %v, %d): %v", factory, a.Owner.Address(), a.Salt, err)
+		}
+		initCode = append(addresses.AccountFactory.Bytes(), create...)
+	}
+
+	ep, err := erc4337testabi.NewEntryPoint(addresses.EntryPoint, a.sim)
+	if err != nil {
+		return erc4337testabi.UserOperation{}, fmt.Errorf("erc4337testabi.NewEntryPoint(%v): %v", addresses.EntryPoint, err)
+	}
+	nonce, err := ep.GetNonce(nil, a.Address, big.NewInt(0))
+	if err != nil {
+		return erc4337testabi.UserOperation{}, fmt.Errorf("%T.GetNonce(%v, 0): %v", ep, a.Address, err)
+	}
+
+	gasPrice, err := a.sim.SuggestGasPrice(ctx)
+	if err != nil {
+		return erc4337testabi.UserOperation{}, fmt.Errorf("%T.SuggestGasPrice(): %v", a.sim, err)
+	}
+
+	op := erc4337testabi.UserOperation{
+		Sender:               a.Address,
+		Nonce:                nonce,
+		InitCode:             initCode,
+		CallData:             callData,
+		CallGasLimit:         big.NewInt(DefaultCallGasLimit),
+		VerificationGasLimit: big.NewInt(DefaultVerificationGasLimit),
+		PreVerificationGas:   big.NewInt(DefaultPreVerificationGas),
+		MaxFeePerGas:         gasPrice,
+		MaxPriorityFeePerGas: gasPrice,
+		PaymasterAndData:     []byte{},
+	}
+	return SignUserOperation(a.sim, a.Owner, op)
+}
+
+// UserOperationTB calls a.UserOperation() and reports any errors with
+// tb.Fatal.
+func (a *Account) UserOperationTB(tb testing.TB, dest common.Address, value *big.Int, data []byte) erc4337testabi.UserOperation {
+	tb.Helper()
+
+	op, err := a.UserOperation(dest, value, data)
+	if err != nil {
+		tb.Fatalf("%T.UserOperation(%v, %d, %#x) error %v", a, dest, value, data, err)
+	}
+	return op
+}
+
+// userOpArgs are the ABI-encoding arguments of a UserOperation, excluding its
+// signature, as packed by the EntryPoint before hashing.
+var userOpArgs = func() abi.Arguments {
+	var args abi.Arguments
+	for _, t := range []string{
+		"address", "uint256", "bytes32", "bytes32", "uint256", "uint256", "uint256", "uint256", "uint256", "bytes32",
+	} {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(fmt.Sprintf("abi.NewType(%q): %v", t, err))
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return args
+}()
+
+// userOpHashArgs are the ABI-encoding arguments of the packed UserOperation
+// hash, the EntryPoint address, and the chain ID.
+var userOpHashArgs = func() abi.Arguments {
+	var args abi.Arguments
+	for _, t := range []string{"bytes32", "address", "uint256"} {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(fmt.Sprintf("abi.NewType(%q): %v", t, err))
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return args
+}()
+
+// UserOpHash returns the hash of the UserOperation, as computed by
+// EntryPoint.getUserOpHash(), which is signed by the owner of the sending
+// account.
+func UserOpHash(op erc4337testabi.UserOperation, entryPoint common.Address, chainID *big.Int) (common.Hash, error) {
+	packed, err := userOpArgs.Pack(
+		op.Sender,
+		op.Nonce,
+		crypto.Keccak256Hash(op.InitCode),
+		crypto.Keccak256Hash(op.CallData),
+		op.CallGasLimit,
+		op.VerificationGasLimit,
+		op.PreVerificationGas,
+		op.MaxFeePerGas,
+		op.MaxPriorityFeePerGas,
+		crypto.Keccak256Hash(op.PaymasterAndData),
+	)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("pack UserOperation: %v", err)
+	}
+
+	buf, err := userOpHashArgs.Pack(crypto.Keccak256Hash(packed), entryPoint, chainID)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("pack UserOperation hash: %v", err)
+	}
+	return crypto.Keccak256Hash(buf), nil
+}
+
+// SignUserOperation returns the UserOperation with its Signature set to that
+// expected by a SimpleAccount owned by the signer; i.e. an EIP-191 personal
+// signature of UserOpHash(), using Addresses().EntryPoint and the backend's
+// chain ID.
+func SignUserOperation(sim *ethtest.SimulatedBackend, owner eth.SignerBackend, op erc4337testabi.UserOperation) (erc4337testabi.UserOperation, error) {
+	hash, err := UserOpHash(op, addresses.EntryPoint, sim.Blockchain().Config().ChainID)
+	if err != nil {
+		return erc4337testabi.UserOperation{}, err
+	}
+	sig, err := owner.SignRawDigest(crypto.Keccak256(eth.WithPersonalMessagePrefix(hash.Bytes())))
+	if err != nil {
+		return erc4337testabi.UserOperation{}, fmt.Errorf("%T.SignRawDigest(<UserOperation hash>): %v", owner, err)
+	}
+	op.Signature = sig
+	return op, nil
+}
+
+// HandleOps submits the UserOperations to the EntryPoint as a single bundle,
+// sent from the account, which also receives the bundle's fees.
+func HandleOps(sim *ethtest.SimulatedBackend, bundler int, ops ...erc4337testabi.UserOperation) (*types.Transaction, error) {
+	ep, err := erc4337testabi.NewEntryPoint(addresses.EntryPoint, sim)
+	if err != nil {
+		return nil, fmt.Errorf("erc4337testabi.NewEntryPoint(%v): %v", addresses.EntryPoint, err)
+	}
+	return ep.HandleOps(sim.Acc(bundler), ops, sim.Addr(bundler))
+}
+
+// HandleOpsTB calls HandleOps() and reports any errors with tb.Fatal.
+//
+// As the EntryPoint doesn't revert when a UserOperation's call fails, instead
+// reporting it in a UserOperationEvent, HandleOpsTB() also reports failed
+// UserOperations with tb.Fatal. This requires that the backend's AutoCommit is
+// true.
+func HandleOpsTB(tb testing.TB, sim *ethtest.SimulatedBackend, bundler int, ops ...erc4337testabi.UserOperation) *types.Transaction {
+	tb.Helper()
+
+	tx := sim.Must(tb, "HandleOps(bundler %d, %d ops)", bundler, len(ops))(HandleOps(sim, bundler, ops...))
+
+	ep, err := erc4337testabi.NewEntryPoint(addresses.EntryPoint, sim)
+	if err != nil {
+		tb.Fatalf("erc4337testabi.NewEntryPoint(%v) error %v", addresses.EntryPoint, err)
+	}
+	rcpt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		tb.Fatalf("%T.TransactionReceipt(%v) error %v", sim, tx.Hash(), err)
+	}
+	for _, l := range rcpt.Logs {
+		if l.Address != addresses.EntryPoint {
+			continue
+		}
+		ev, err := ep.ParseUserOperationEvent(*l)
+		if err != nil {
+			continue
+		}
+		if !ev.Success {
+			tb.Fatalf("HandleOps(): UserOperation from %v with nonce %d failed", ev.Sender, ev.Nonce)
+		}
+	}
+	return tx
+}
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.12 <0.9.0;
+
+import "@account-abstraction/contracts/core/EntryPoint.sol";
+import "@account-abstraction/contracts/samples/SimpleAccountFactory.sol";
+
+/**
+@notice Deploys the ERC-4337 EntryPoint and a factory of smart accounts that
+trust it, for use with ethier's erc4337test Go package.
+ */
+contract SimulatedEntryPoint {
+    EntryPoint public immutable entryPoint;
+    SimpleAccountFactory public immutable accountFactory;
+
+    constructor() {
+        entryPoint = new EntryPoint();
+        accountFactory = new SimpleAccountFactory(entryPoint);
+    }
+}
@@ -0,0 +1,6 @@
+// Package erc4337testabi is a generated package providing the ERC-4337
+// EntryPoint and SimpleAccount contracts. There is likely no need to use this
+// package directly as its functionality is exposed via the erc4337test package.
+package erc4337testabi
+
+//go:generate ethier gen SimulatedEntryPoint.sol
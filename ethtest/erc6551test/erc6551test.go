@@ -0,0 +1,148 @@
+// Package erc6551test provides a token-bound account (ERC-6551) test kit: the
+// registry at its canonical address, and a minimal account implementation
+// controlled by the owner of the token to which each account is bound.
+//
+// The registry is placed in the genesis block of every ethtest.SimulatedBackend
+// in any binary that imports this package, whereas the account implementation
+// MUST be deployed with Deploy[TB](). As with all other test doubles, the
+// implementation's address is deterministic but not canonical.
+package erc6551test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/erc6551test/erc6551testabi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// RegistryAddress is the canonical address of the ERC-6551 registry.
+var RegistryAddress = common.HexToAddress("0x000000006551c19487814612e58FE06813775758")
+
+func init() {
+	ethtest.RegisterGenesisContract(RegistryAddress, common.FromHex(erc6551testabi.ERC6551RegistryBin))
+}
+
+// implementation is the address at which Deploy() deploys the account
+// implementation. This is deterministic because mocked entities in
+// ethtest.SimulatedBackend have deterministic keys.
+var implementation = common.HexToAddress("0xECea1388404b50B7079F645973C600BED6c92aBa")
+
+// ImplementationAddress returns the address at which Deploy() deploys the
+// account implementation.
+func ImplementationAddress() common.Address {
+	return implementation
+}
+
+// Deploy deploys the token-bound account implementation to the
+// SimulatedBackend.
+//
+// This function MUST only be called once for each SimulatedBackend; all future
+// calls will deploy to a different address to the one returned by
+// ImplementationAddress().
+func Deploy(sim *ethtest.SimulatedBackend) error {
+	return sim.AsMockedEntity(ethtest.TokenBoundAccounts, func(opts *bind.TransactOpts) error {
+		addr, _, _, err := erc6551testabi.DeploySimulatedERC6551Account(opts, sim)
+		if err != nil {
+			return fmt.Errorf("erc6551testabi.DeploySimulatedERC6551Account() error %v", err)
+		}
+		if addr != implementation {
+			return fmt.Errorf("unexpected deployment address %v; want %v", addr, implementation)
+		}
+		return nil
+	})
+}
+
+// DeployTB calls Deploy() and reports any errors with tb.Fatal.
+func DeployTB(tb testing.TB, sim *ethtest.SimulatedBackend) {
+	tb.Helper()
+
+	if err := Deploy(sim); err != nil {
+		tb.Fatalf("erc6551test.Deploy() error %v", err)
+	}
+}
+
+// An Account is a token-bound account using the implementation deployed by
+// Deploy().
+type Account struct {
+	*erc6551testabi.SimulatedERC6551Account
+	Address common.Address
+
+	sim *ethtest.SimulatedBackend
+}
+
+// salt is used for all accounts created by this package, which therefore
+// supports only a single account per token.
+var salt [32]byte
+
+// AccountAddress returns the address of the token's account, regardless of
+// whether it has been created.
+func AccountAddress(sim *ethtest.SimulatedBackend, tokenContract common.Address, tokenID *big.Int) (common.Address, error) {
+	reg, err := erc6551testabi.NewERC6551Registry(RegistryAddress, sim)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("erc6551testabi.NewERC6551Registry(): %v", err)
+	}
+	addr, err := reg.Account(nil, implementation, salt, sim.Blockchain().Config().ChainID, tokenContract, tokenID)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%T.Account(%v, %d): %v", reg, tokenContract, tokenID, err)
+	}
+	return addr, nil
+}
+
+// CreateAccount creates the token's account via the registry, sending the
+// transaction from the account, which needn't own the token. As with the
+// registry itself, creation is idempotent.
+func CreateAccount(sim *ethtest.SimulatedBackend, account int, tokenContract common.Address, tokenID *big.Int) (*Account, error) {
+	reg, err := erc6551testabi.NewERC6551Registry(RegistryAddress, sim)
+	if err != nil {
+		return nil, fmt.Errorf("erc6551testabi.NewERC6551Registry(): %v", err)
+	}
+	if _, err := reg.CreateAccount(sim.Acc(account), implementation, salt, sim.Blockchain().Config().ChainID, tokenContract, tokenID); err != nil {
+		return nil, fmt.Errorf("%T.CreateAccount(%v, %d): %v", reg, tokenContract, tokenID, err)
+	}
+
+	addr, err := AccountAddress(sim, tokenContract, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	acc, err := erc6551testabi.NewSimulatedERC6551Account(addr, sim)
+	if err != nil {
+		return nil, fmt.Errorf("erc6551testabi.NewSimulatedERC6551Account(%v): %v", addr, err)
+	}
+	return &Account{
+		SimulatedERC6551Account: acc,
+		Address:                 addr,
+		sim:                     sim,
+	}, nil
+}
+
+// CreateAccountTB calls CreateAccount() and reports any errors with tb.Fatal.
+func CreateAccountTB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, tokenContract common.Address, tokenID *big.Int) *Account {
+	tb.Helper()
+
+	a, err := CreateAccount(sim, account, tokenContract, tokenID)
+	if err != nil {
+		tb.Fatalf("erc6551test.CreateAccount(%v, %d) error %v", tokenContract, tokenID, err)
+	}
+	return a
+}
+
+// Execute calls the destination, with the value and data, through the
+// token-bound account. The transaction is sent from the holder, which MUST own
+// the token, and the value is sent by the account itself, not the holder.
+func (a *Account) Execute(holder int, to common.Address, value *big.Int, data []byte) (*types.Transaction, error) {
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	return a.SimulatedERC6551Account.Execute(a.sim.Acc(holder), to, value, data, 0)
+}
+
+// ExecuteTB calls a.Execute() and reports any errors with tb.Fatal.
+func (a *Account) ExecuteTB(tb testing.TB, holder int, to common.Address, value *big.Int, data []byte) *types.Transaction {
+	tb.Helper()
+	return a.sim.Must(tb, "%T.Execute(%v)", a, to)(a.Execute(holder, to, value, data))
+}
@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/interfaces/IERC1271.sol";
+import "@openzeppelin/contracts/token/ERC721/IERC721.sol";
+import "@openzeppelin/contracts/utils/Create2.sol";
+import "@openzeppelin/contracts/utils/cryptography/SignatureChecker.sol";
+import "@openzeppelin/contracts/utils/introspection/IERC165.sol";
+
+/// @notice The ERC-6551 registry interface.
+interface IERC6551Registry {
+    event ERC6551AccountCreated(
+        address account,
+        address indexed implementation,
+        bytes32 salt,
+        uint256 chainId,
+        address indexed tokenContract,
+        uint256 indexed tokenId
+    );
+
+    function createAccount(
+        address implementation,
+        bytes32 salt,
+        uint256 chainId,
+        address tokenContract,
+        uint256 tokenId
+    ) external returns (address);
+
+    function account(
+        address implementation,
+        bytes32 salt,
+        uint256 chainId,
+        address tokenContract,
+        uint256 tokenId
+    ) external view returns (address);
+}
+
+/**
+@notice Equivalent to the canonical ERC-6551 registry, deploying token-bound
+accounts as ERC-1167 proxies with the token's details appended to their code.
+The addresses of accounts are identical to those of the canonical registry.
+ */
+contract ERC6551Registry is IERC6551Registry {
+    function createAccount(
+        address implementation,
+        bytes32 salt,
+        uint256 chainId,
+        address tokenContract,
+        uint256 tokenId
+    ) external returns (address) {
+        bytes memory code = _creationCode(
+            implementation,
+            salt,
+            chainId,
+            tokenContract,
+            tokenId
+        );
+        address acc = Create2.computeAddress(salt, keccak256(code));
+        if (acc.code.length != 0) {
+            return acc;
+        }
+
+        emit ERC6551AccountCreated(
+            acc,
+            implementation,
+            salt,
+            chainId,
+            tokenContract,
+            tokenId
+        );
+        return Create2.deploy(0, salt, code);
+    }
+
+    function account(
+        address implementation,
+        bytes32 salt,
+        uint256 chainId,
+        address tokenContract,
+        uint256 tokenId
+    ) external view returns (address) {
+        bytes memory code = _creationCode(
+            implementation,
+            salt,
+            chainId,
+            tokenContract,
+            tokenId
+        );
+        return Create2.computeAddress(salt, keccak256(code));
+    }
+
+    /**
+    @dev Returns the creation code of an ERC-1167 proxy to the implementation,
+    followed by the ABI-encoded salt, chain ID, token contract and token ID, all
+    of which are returned as the runtime code.
+     */
+    function _creationCode(
+        address implementation,
+        bytes32 salt,
+        uint256 chainId,
+        address tokenContract,
+        uint256 tokenId
+    ) internal pure returns (bytes memory) {
+        return
+            abi.encodePacked(
+                hex"3d60ad80600a3d3981f3363d3d373d3d3d363d73",
+                implementation,
+                hex"5af43d82803e903d91602b57fd5bf3",
+                abi.encode(salt, chainId, tokenContract, tokenId)
+            );
+    }
+}
+
+/**
+@notice A minimal ERC-6551 account implementation, controlled by the owner of
+the token to which the account is bound, for use with ethier's erc6551test Go
+package.
+ */
+contract SimulatedERC6551Account is IERC165, IERC1271 {
+    /// @notice Incremented upon every state-changing execution.
+    uint256 public state;
+
+    // solhint-disable-next-line no-empty-blocks
+    receive() external payable {}
+
+    /**
+    @notice Calls the destination, with the value and data, on behalf of the
+    account. Only call operations (0) are supported.
+     */
+    function execute(
+        address to,
+        uint256 value,
+        bytes calldata data,
+        uint8 operation
+    ) external payable returns (bytes memory result) {
+        require(
+            msg.sender == owner(),
+            "SimulatedERC6551Account: invalid signer"
+        );
+        require(operation == 0, "SimulatedERC6551Account: only calls");
+
+        ++state;
+        bool success;
+        // solhint-disable-next-line avoid-low-level-calls
+        (success, result) = to.call{value: value}(data);
+        if (!success) {
+            // solhint-disable-next-line no-inline-assembly
+            assembly {
+                revert(add(result, 32), mload(result))
+            }
+        }
+    }
+
+    /// @notice Returns the token to which the account is bound.
+    function token()
+        public
+        view
+        returns (
+            uint256 chainId,
+            address tokenContract,
+            uint256 tokenId
+        )
+    {
+        bytes memory footer = new bytes(0x60);
+        // The footer follows the 45-byte proxy and the 32-byte salt.
+        // solhint-disable-next-line no-inline-assembly
+        assembly {
+            extcodecopy(address(), add(footer, 0x20), 0x4d, 0x60)
+        }
+        return abi.decode(footer, (uint256, address, uint256));
+    }
+
+    /// @notice Returns the owner of the token to which the account is bound.
+    function owner() public view returns (address) {
+        (uint256 chainId, address tokenContract, uint256 tokenId) = token();
+        if (chainId != block.chainid) {
+            return address(0);
+        }
+        return IERC721(tokenContract).ownerOf(tokenId);
+    }
+
+    /// @notice ERC-6551 signer validation; only the owner is a valid signer.
+    function isValidSigner(address signer, bytes calldata)
+        external
+        view
+        returns (bytes4)
+    {
+        return signer == owner() ? this.isValidSigner.selector : bytes4(0);
+    }
+
+    /// @notice ERC-1271 signature validation against the owner.
+    function isValidSignature(bytes32 hash, bytes memory signature)
+        external
+        view
+        returns (bytes4)
+    {
+        return
+            SignatureChecker.isValidSignatureNow(owner(), hash, signature)
+                ? this.isValidSignature.selector
+                : bytes4(0);
+    }
+
+    /// @notice Supports ERC-165, ERC-1271 and the ERC-6551 account interfaces.
+    function supportsInterface(bytes4 interfaceId)
+        external
+        pure
+        returns (bool)
+    {
+        return
+            interfaceId == type(IERC165).interfaceId ||
+            interfaceId == type(IERC1271).interfaceId ||
+            interfaceId == 0x6faff5f1 || // IERC6551Account
+            interfaceId == 0x51945447; // IERC6551Executable
+    }
+}
@@ -0,0 +1,6 @@
+// Package erc6551testabi is a generated package providing the ERC-6551 registry
+// and a token-bound account implementation. There is likely no need to use this
+// package directly as its functionality is exposed via the erc6551test package.
+package erc6551testabi
+
+//go:generate ethier gen SimulatedERC6551.sol
@@ -1,8 +1,19 @@
 package ethtest
 
 import (
+	"bytes"
+	"context"
 	"math/big"
 	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/eth/merkle"
 )
 
 func TestFastForward(t *testing.T) {
@@ -40,3 +51,115 @@ func TestFastForward(t *testing.T) {
 		}
 	}
 }
+
+func TestNewSimulatedBackendWithAccounts(t *testing.T) {
+	const mnemonic = "test test test test test test test test test test test junk"
+
+	var accounts []*bind.TransactOpts
+	for i := uint(0); i < 2; i++ {
+		s, err := eth.DefaultHDPathPrefix.SignerFromSeedPhrase(mnemonic, "", i)
+		if err != nil {
+			t.Fatalf("SignerFromSeedPhrase(…, %d) error %v", i, err)
+		}
+		opts, err := s.TransactorWithChainID(big.NewInt(1337))
+		if err != nil {
+			t.Fatalf("%T.TransactorWithChainID(1337) error %v", s, err)
+		}
+		accounts = append(accounts, opts)
+	}
+
+	sim, err := NewSimulatedBackendWithAccounts(accounts)
+	if err != nil {
+		t.Fatalf("NewSimulatedBackendWithAccounts() error %v", err)
+	}
+	t.Cleanup(func() { sim.Close() })
+
+	ctx := context.Background()
+	want := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	if got := sim.Addr(0); got != want {
+		t.Errorf("%T.Addr(0) got %v; want %v", sim, got, want)
+	}
+	for i := range accounts {
+		if got, want := sim.BalanceOf(ctx, t, sim.Addr(i)), eth.Ether(100); got.Cmp(want) != 0 {
+			t.Errorf("%T.BalanceOf(%v) got %d; want %d", sim, sim.Addr(i), got, want)
+		}
+	}
+
+	// Accounts must be able to transact.
+	to := bind.NewBoundContract(sim.Addr(1), abi.ABI{}, sim, sim, sim)
+	opts := sim.WithValueFrom(0, eth.Ether(1))
+	opts.GasLimit = params.TxGas // otherwise estimation requires contract code
+	if _, err := to.Transfer(opts); err != nil {
+		t.Errorf("transfer from account 0 error %v", err)
+	}
+}
+
+// answerAddress is registered as a genesis contract that returns 42 to all
+// calls, and storedAnswerAddress as one that returns the 42 that its
+// constructor stored.
+var (
+	answerAddress       = common.HexToAddress("0x000000000000000000000000000000000000002a")
+	storedAnswerAddress = common.HexToAddress("0x000000000000000000000000000000000000002b")
+)
+
+func init() {
+	// Creation code copying and returning the 10-byte runtime code
+	// PUSH1 42; PUSH1 0; MSTORE; PUSH1 32; PUSH1 0; RETURN.
+	RegisterGenesisContract(answerAddress, common.FromHex("0x600a600c600039600a6000f3602a60005260206000f3"))
+	// Creation code storing 42 in slot 0 then copying and returning the
+	// 11-byte runtime code
+	// PUSH1 0; SLOAD; PUSH1 0; MSTORE; PUSH1 32; PUSH1 0; RETURN.
+	RegisterGenesisContract(storedAnswerAddress, common.FromHex("0x602a600055600b6011600039600b6000f360005460005260206000f3"))
+}
+
+func TestRegisterGenesisContract(t *testing.T) {
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		sim := NewSimulatedBackendTB(t, 1)
+
+		for _, addr := range []common.Address{answerAddress, storedAnswerAddress} {
+			addr := addr
+			got, err := sim.CallContract(ctx, ethereum.CallMsg{To: &addr}, nil)
+			if err != nil {
+				t.Fatalf("CallContract(<genesis contract %v>) error %v", addr, err)
+			}
+			if want := common.LeftPadBytes([]byte{42}, 32); !bytes.Equal(got, want) {
+				t.Errorf("CallContract(<genesis contract %v>) in backend %d got %#x; want %#x", addr, i, got, want)
+			}
+		}
+	}
+
+	t.Run("after construction", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("RegisterGenesisContract() after construction of a SimulatedBackend did not panic")
+			}
+		}()
+		RegisterGenesisContract(common.HexToAddress("0x2c"), nil)
+	})
+}
+
+func TestMerkleHelpers(t *testing.T) {
+	var allowances []merkle.Allowance
+	for i := 0; i < 5; i++ {
+		allowances = append(allowances, merkle.Allowance{
+			Address: common.BigToAddress(big.NewInt(int64(i + 1))),
+			Amount:  big.NewInt(int64(i)),
+		})
+	}
+	tree := MerkleAllowlistTB(t, allowances...)
+
+	for _, a := range allowances {
+		leaf := merkle.AllowanceLeaf(a.Address, a.Amount)
+		proof := MerkleProofTB(t, tree, leaf)
+
+		hashes := make([]common.Hash, len(proof))
+		for i, p := range proof {
+			hashes[i] = p
+		}
+		if !merkle.Verify(tree.Root(), leaf, hashes) {
+			t.Errorf("merkle.Verify(root, [leaf of %+v], MerkleProofTB(…)) got false; want true", a)
+		}
+	}
+}
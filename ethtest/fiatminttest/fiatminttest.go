@@ -0,0 +1,117 @@
+// Package fiatminttest simulates third-party fiat minting services, such as
+// Paper and Crossmint, that purchase tokens from sale contracts on behalf of
+// credit-card buyers. This allows allowlist and per-wallet-cap logic to be
+// tested under relayed mints, in which the relayer contract is the msg.sender,
+// the service's operator is the tx.origin, and the buyer is only identified by
+// the calldata.
+//
+// As with all other test doubles, the relayer is deployed to a deterministic
+// address, not the address of any particular service.
+package fiatminttest
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/fiatminttest/fiatminttestabi"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// relayer is the address at which Deploy() deploys the relayer. This is
+// deterministic because mocked entities in ethtest.SimulatedBackend have
+// deterministic keys.
+var relayer = common.HexToAddress("0xC2Cc5560470a9A2A7c1f563fd7C83e2C34496F55")
+
+// RelayerAddress returns the address at which Deploy() deploys the relayer;
+// i.e. the msg.sender of all relayed purchases.
+func RelayerAddress() common.Address {
+	return relayer
+}
+
+// OperatorAddress returns the address of the service's operator; i.e. the
+// tx.origin of all relayed purchases.
+func OperatorAddress(sim *ethtest.SimulatedBackend) (common.Address, error) {
+	var op common.Address
+	err := sim.AsMockedEntity(ethtest.FiatMinting, func(opts *bind.TransactOpts) error {
+		op = opts.From
+		return nil
+	})
+	return op, err
+}
+
+// Deploy deploys the relayer to the SimulatedBackend.
+//
+// This function MUST only be called once for each SimulatedBackend; all future
+// calls will deploy to a different address to the one returned by
+// RelayerAddress().
+func Deploy(sim *ethtest.SimulatedBackend) error {
+	return sim.AsMockedEntity(ethtest.FiatMinting, func(opts *bind.TransactOpts) error {
+		addr, _, _, err := fiatminttestabi.DeploySimulatedMintRelayer(opts, sim)
+		if err != nil {
+			return fmt.Errorf("fiatminttestabi.DeploySimulatedMintRelayer() error %v", err)
+		}
+		if addr != relayer {
+			return fmt.Errorf("unexpected deployment address %v; want %v", addr, relayer)
+		}
+		return nil
+	})
+}
+
+// DeployTB calls Deploy() and reports any errors with tb.Fatal.
+func DeployTB(tb testing.TB, sim *ethtest.SimulatedBackend) {
+	tb.Helper()
+
+	if err := Deploy(sim); err != nil {
+		tb.Fatalf("fiatminttest.Deploy() error %v", err)
+	}
+}
+
+// Relay has the relayer call the sale contract with the data, paying value
+// from the service's own funds as if the buyer had paid by credit card. The
+// relayer MUST already have been deployed with Deploy[TB](). Reverts by the
+// sale contract are propagated.
+func Relay(sim *ethtest.SimulatedBackend, sale common.Address, value *big.Int, data []byte) (*types.Transaction, error) {
+	r, err := fiatminttestabi.NewSimulatedMintRelayer(relayer, sim)
+	if err != nil {
+		return nil, fmt.Errorf("fiatminttestabi.NewSimulatedMintRelayer(): %v", err)
+	}
+
+	var tx *types.Transaction
+	err = sim.AsMockedEntity(ethtest.FiatMinting, func(opts *bind.TransactOpts) error {
+		// The mocked entity's TransactOpts are shared so MUST NOT be modified.
+		o := *opts
+		o.Value = value
+		var err error
+		tx, err = r.Relay(&o, sale, data)
+		return err
+	})
+	return tx, err
+}
+
+// RelayTB calls Relay() and reports any errors with tb.Fatal.
+func RelayTB(tb testing.TB, sim *ethtest.SimulatedBackend, sale common.Address, value *big.Int, data []byte) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "fiatminttest.Relay(%v)", sale)(Relay(sim, sale, value, data))
+}
+
+// Purchase is a convenience wrapper around Relay(), packing the method and
+// arguments with the sale contract's ABI. Typically the arguments include the
+// buyer's address, to which the purchased tokens are sent.
+func Purchase(sim *ethtest.SimulatedBackend, sale common.Address, saleABI *abi.ABI, value *big.Int, method string, args ...interface{}) (*types.Transaction, error) {
+	data, err := saleABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%T.Pack(%q, %v): %v", saleABI, method, args, err)
+	}
+	return Relay(sim, sale, value, data)
+}
+
+// PurchaseTB calls Purchase() and reports any errors with tb.Fatal.
+func PurchaseTB(tb testing.TB, sim *ethtest.SimulatedBackend, sale common.Address, saleABI *abi.ABI, value *big.Int, method string, args ...interface{}) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "fiatminttest.Purchase(%v, %q, %v)", sale, method, args)(Purchase(sim, sale, saleABI, value, method, args...))
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+/**
+@notice Mirrors the relayer contracts of fiat minting services, such as Paper
+and Crossmint, which purchase tokens with their own funds on behalf of
+credit-card buyers, for use with ethier's fiatminttest Go package.
+@dev The relayer is the msg.sender of all purchases and the service's operator
+is the tx.origin, whereas the buyer is only identified by the calldata.
+ */
+contract SimulatedMintRelayer {
+    /// @notice The service's operator, the only account allowed to relay.
+    address public immutable operator;
+
+    constructor() {
+        operator = msg.sender;
+    }
+
+    /// @notice Accepts refunds of overpayment.
+    // solhint-disable-next-line no-empty-blocks
+    receive() external payable {}
+
+    /**
+    @notice Calls the sale contract with the data, forwarding msg.value as
+    payment, and bubbling up any revert.
+     */
+    function relay(address sale, bytes calldata data)
+        external
+        payable
+        returns (bytes memory)
+    {
+        require(
+            msg.sender == operator,
+            "SimulatedMintRelayer: only operator"
+        );
+
+        // solhint-disable-next-line avoid-low-level-calls
+        (bool success, bytes memory result) = sale.call{value: msg.value}(
+            data
+        );
+        if (!success) {
+            // solhint-disable-next-line no-inline-assembly
+            assembly {
+                revert(add(result, 32), mload(result))
+            }
+        }
+        return result;
+    }
+}
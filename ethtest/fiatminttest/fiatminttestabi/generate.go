@@ -0,0 +1,6 @@
+// Package fiatminttestabi is a generated package providing a mock of fiat
+// minting services' relayer contracts. There is likely no need to use this
+// package directly as its functionality is exposed via the fiatminttest package.
+package fiatminttestabi
+
+//go:generate ethier gen SimulatedMintRelayer.sol
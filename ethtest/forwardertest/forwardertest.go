@@ -0,0 +1,165 @@
+// Package forwardertest provides a deployment of OpenZeppelin's EIP-2771
+// MinimalForwarder, allowing the trusted-forwarder path of contracts to be
+// tested with meta-transactions signed by eth.SignForwardRequest().
+//
+// The fixture lives outside of package ethtest, like all others with generated
+// bindings, because the ethier binary that generates bindings itself depends
+// on ethtest.
+package forwardertest
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/forwardertest/forwardertestabi"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// forwarder is the address at which the MinimalForwarder is deployed by
+// DeployMinimalForwarder(). This is deterministic because mocked entities in
+// ethtest.SimulatedBackend have deterministic keys.
+var forwarder = common.HexToAddress("0x3a28c97A2623A3a5C29256E742032942C8438Db5")
+
+// ForwarderAddress returns the address at which DeployMinimalForwarder()
+// deploys the forwarder, to be passed to ERC2771Context constructors.
+func ForwarderAddress() common.Address {
+	return forwarder
+}
+
+// A Forwarder is a deployed MinimalForwarder.
+type Forwarder struct {
+	*forwardertestabi.SimulatedMinimalForwarder
+	sim *ethtest.SimulatedBackend
+}
+
+// DeployMinimalForwarder deploys OpenZeppelin's MinimalForwarder to the
+// SimulatedBackend.
+//
+// This function MUST only be called once for each SimulatedBackend; all future
+// calls will deploy to a different address to the one returned by
+// ForwarderAddress().
+func DeployMinimalForwarder(sim *ethtest.SimulatedBackend) (*Forwarder, error) {
+	var f *forwardertestabi.SimulatedMinimalForwarder
+	err := sim.AsMockedEntity(ethtest.TrustedForwarder, func(opts *bind.TransactOpts) error {
+		addr, _, fwd, err := forwardertestabi.DeploySimulatedMinimalForwarder(opts, sim)
+		if err != nil {
+			return fmt.Errorf("forwardertestabi.DeploySimulatedMinimalForwarder() error %v", err)
+		}
+		if addr != forwarder {
+			return fmt.Errorf("unexpected deployment address %v; want %v", addr, forwarder)
+		}
+		f = fwd
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Forwarder{
+		SimulatedMinimalForwarder: f,
+		sim:                       sim,
+	}, nil
+}
+
+// DeployMinimalForwarderTB calls DeployMinimalForwarder() and reports any
+// errors with tb.Fatal.
+func DeployMinimalForwarderTB(tb testing.TB, sim *ethtest.SimulatedBackend) *Forwarder {
+	tb.Helper()
+
+	f, err := DeployMinimalForwarder(sim)
+	if err != nil {
+		tb.Fatalf("forwardertest.DeployMinimalForwarder() error %v", err)
+	}
+	return f
+}
+
+// Domain returns the forwarder's EIP-712 domain, for use with
+// eth.SignForwardRequest().
+func (f *Forwarder) Domain() eth.TrustedForwarder {
+	return eth.TrustedForwarder{
+		Name:    "MinimalForwarder",
+		Version: "0.0.1",
+		ChainID: f.sim.Blockchain().Config().ChainID,
+		Address: forwarder,
+	}
+}
+
+// DefaultGas is the gas limit of requests returned by NewRequest().
+const DefaultGas = 1e6
+
+// NewRequest returns an unsigned ForwardRequest, from the address, to call the
+// destination contract with the data. The request's Nonce is the forwarder's
+// current nonce for the address, and its Gas is DefaultGas.
+func (f *Forwarder) NewRequest(from, to common.Address, data []byte) (eth.ForwardRequest, error) {
+	nonce, err := f.GetNonce(nil, from)
+	if err != nil {
+		return eth.ForwardRequest{}, fmt.Errorf("%T.GetNonce(%v): %v", f, from, err)
+	}
+	return eth.ForwardRequest{
+		From:  from,
+		To:    to,
+		Value: big.NewInt(0),
+		Gas:   big.NewInt(DefaultGas),
+		Nonce: nonce,
+		Data:  data,
+	}, nil
+}
+
+// request converts the ForwardRequest into its binding equivalent.
+func request(req eth.ForwardRequest) forwardertestabi.MinimalForwarderForwardRequest {
+	return forwardertestabi.MinimalForwarderForwardRequest{
+		From:  req.From,
+		To:    req.To,
+		Value: req.Value,
+		Gas:   req.Gas,
+		Nonce: req.Nonce,
+		Data:  req.Data,
+	}
+}
+
+// Relay submits the signed request to the forwarder, sending the transaction,
+// and the request's Value, from the account.
+//
+// The MinimalForwarder doesn't revert when the relayed call fails so a nil
+// error only indicates that the request's signature and nonce were valid. See
+// RelayTB() for a stricter alternative.
+func (f *Forwarder) Relay(account int, req eth.ForwardRequest, sig []byte) (*types.Transaction, error) {
+	return f.Execute(f.sim.WithValueFrom(account, req.Value), request(req), sig)
+}
+
+// RelayTB builds a request with NewRequest(), signs it with the signer, and
+// relays it from the account. Unlike Relay(), a failing relayed call is
+// reported with tb.Fatal, as are any other errors.
+func (f *Forwarder) RelayTB(tb testing.TB, account int, signer eth.SignerBackend, to common.Address, data []byte) *types.Transaction {
+	tb.Helper()
+
+	req, err := f.NewRequest(signer.Address(), to, data)
+	if err != nil {
+		tb.Fatalf("%T.NewRequest() error %v", f, err)
+	}
+	sig, err := eth.SignForwardRequest(signer, f.Domain(), req)
+	if err != nil {
+		tb.Fatalf("eth.SignForwardRequest() error %v", err)
+	}
+
+	// The success of the relayed call is only available as a return value so
+	// must be checked with a call before sending the transaction.
+	var out []interface{}
+	opts := &bind.CallOpts{From: f.sim.Addr(account), Context: context.Background()}
+	raw := &forwardertestabi.SimulatedMinimalForwarderRaw{Contract: f.SimulatedMinimalForwarder}
+	if err := raw.Call(opts, &out, "execute", request(req), sig); err != nil {
+		tb.Fatalf("%T.Execute() call error %v", f, err)
+	}
+	if ok := *abi.ConvertType(out[0], new(bool)).(*bool); !ok {
+		tb.Fatalf("%T.Execute(): relayed call to %v failed", f, to)
+	}
+
+	return f.sim.Must(tb, "%T.Execute()", f)(f.Relay(account, req, sig))
+}
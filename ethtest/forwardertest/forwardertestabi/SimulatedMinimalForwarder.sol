@@ -0,0 +1,14 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/metatx/MinimalForwarder.sol";
+
+/**
+@notice OpenZeppelin's EIP-2771 MinimalForwarder for use with ethier's
+forwardertest Go package.
+ */
+// solhint-disable-next-line no-empty-blocks
+contract SimulatedMinimalForwarder is MinimalForwarder {
+
+}
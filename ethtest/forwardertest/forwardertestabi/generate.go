@@ -0,0 +1,6 @@
+// Package forwardertestabi is a generated package providing an EIP-2771
+// trusted forwarder. There is likely no need to use this package directly as
+// its functionality is exposed via the forwardertest package.
+package forwardertestabi
+
+//go:generate ethier gen SimulatedMinimalForwarder.sol
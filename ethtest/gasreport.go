@@ -0,0 +1,135 @@
+package ethtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/divergencetech/ethier/solcover"
+)
+
+// GasReportDirEnvVar is the environment variable that, if set, causes every
+// SimulatedBackend to record the gas used by each committed transaction as a
+// GasRecord. Records are written as JSON lines to a file, unique to the
+// process, in the directory named by the variable. This is typically set by
+// `ethier gas-report` and SHOULD NOT need to be used directly.
+const GasReportDirEnvVar = "ETHIER_GAS_REPORT_DIR"
+
+// A GasRecord describes the gas used by a single transaction.
+type GasRecord struct {
+	// Contract is the fully qualified name of the contract called, or
+	// deployed, if it was registered with solcover; otherwise it is the
+	// contract's address.
+	Contract string `json:"contract"`
+	// Selector is the 4-byte function selector, empty for deployments and
+	// calls without data.
+	Selector   hexutil.Bytes `json:"selector,omitempty"`
+	Deployment bool          `json:"deployment,omitempty"`
+	GasUsed    uint64        `json:"gasUsed"`
+}
+
+// gasRecorder writes GasRecords to the file defined by GasReportDirEnvVar. The
+// zero value is ready for use.
+type gasRecorder struct {
+	once sync.Once
+	mu   sync.Mutex
+	enc  *json.Encoder
+	err  error
+}
+
+// processGasRecorder is shared by all SimulatedBackends in the process.
+var processGasRecorder = new(gasRecorder)
+
+// enabled reports whether GasReportDirEnvVar is set.
+func (r *gasRecorder) enabled() bool {
+	return os.Getenv(GasReportDirEnvVar) != ""
+}
+
+// record writes the GasRecord, opening the process's file on first use.
+func (r *gasRecorder) record(rec GasRecord) error {
+	r.once.Do(func() {
+		dir := os.Getenv(GasReportDirEnvVar)
+		path := filepath.Join(dir, fmt.Sprintf("gas-%d.jsonl", os.Getpid()))
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			r.err = fmt.Errorf("open gas report %q: %v", path, err)
+			return
+		}
+		// The file is never explicitly closed as records are written
+		// throughout the life of the process, and the OS closes it on exit.
+		r.enc = json.NewEncoder(f)
+	})
+	if r.err != nil {
+		return r.err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(rec)
+}
+
+// Commit calls Commit() on the embedded backend and, if GasReportDirEnvVar is
+// set, records the gas used by all transactions in the committed block. The
+// first error in recording gas stops all further recording by the backend and
+// is returned by Close(), which NewSimulatedBackendTB() reports.
+func (sb *SimulatedBackend) Commit() {
+	sb.SimulatedBackend.Commit()
+
+	txs := sb.pendingGas
+	sb.pendingGas = nil
+	if len(txs) == 0 || sb.gasErr != nil {
+		return
+	}
+	sb.gasErr = sb.recordGas(context.Background(), txs)
+}
+
+// Close calls Close() on the embedded backend, returning its error or, if
+// there was none, any error in recording gas.
+func (sb *SimulatedBackend) Close() error {
+	if err := sb.SimulatedBackend.Close(); err != nil {
+		return err
+	}
+	if sb.gasErr != nil {
+		return fmt.Errorf("ethtest: gas report: %v", sb.gasErr)
+	}
+	return nil
+}
+
+// recordGas writes a GasRecord for each of the committed transactions.
+func (sb *SimulatedBackend) recordGas(ctx context.Context, txs []*types.Transaction) error {
+	for _, tx := range txs {
+		rcpt, err := sb.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return fmt.Errorf("%T.TransactionReceipt(%s) for gas report: %v", sb.SimulatedBackend, tx.Hash(), err)
+		}
+
+		rec := GasRecord{GasUsed: rcpt.GasUsed}
+		var addr common.Address
+		switch to := tx.To(); {
+		case to == nil:
+			rec.Deployment = true
+			addr = rcpt.ContractAddress
+		default:
+			addr = *to
+			if data := tx.Data(); len(data) >= 4 {
+				rec.Selector = data[:4]
+			}
+		}
+
+		rec.Contract = addr.Hex()
+		if name, ok := solcover.ContractName(addr); ok {
+			rec.Contract = name
+		}
+		if err := processGasRecorder.record(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package ethtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGasReport(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(GasReportDirEnvVar, dir)
+
+	sim := NewSimulatedBackendTB(t, 1)
+
+	// A contract whose runtime code is a single STOP; init code returns it.
+	code := hexutil.MustDecode("0x600180600b6000396000f300")
+	addr, _, contract, err := bind.DeployContract(sim.Acc(0), abi.ABI{}, code, sim)
+	if err != nil {
+		t.Fatalf("bind.DeployContract() error %v", err)
+	}
+
+	selector := []byte{0xa9, 0x05, 0x9c, 0xbb}
+	sim.AutoCommit = false
+	for i := 0; i < 2; i++ {
+		if _, err := contract.RawTransact(sim.Acc(0), selector); err != nil {
+			t.Fatalf("RawTransact() error %v", err)
+		}
+	}
+	sim.Commit()
+	sim.AutoCommit = true
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("filepath.Glob(%q) got %q, err = %v; want 1 file", dir, files, err)
+	}
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatalf("os.Open() error %v", err)
+	}
+	defer f.Close()
+
+	var got []GasRecord
+	for s := bufio.NewScanner(f); s.Scan(); {
+		var rec GasRecord
+		if err := json.NewDecoder(strings.NewReader(s.Text())).Decode(&rec); err != nil {
+			t.Fatalf("json.Decode(%q) error %v", s.Text(), err)
+		}
+		if rec.GasUsed == 0 {
+			t.Errorf("GasRecord %+v has zero gas used", rec)
+		}
+		rec.GasUsed = 0
+		got = append(got, rec)
+	}
+
+	call := GasRecord{
+		Contract: addr.Hex(),
+		Selector: selector,
+	}
+	want := []GasRecord{
+		{
+			Contract:   addr.Hex(),
+			Deployment: true,
+		},
+		call,
+		call,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Recorded gas diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGasReportError(t *testing.T) {
+	defer func(r *gasRecorder) { processGasRecorder = r }(processGasRecorder)
+	processGasRecorder = new(gasRecorder)
+
+	t.Setenv(GasReportDirEnvVar, filepath.Join(t.TempDir(), "missing"))
+
+	sim, err := NewSimulatedBackend(1)
+	if err != nil {
+		t.Fatalf("NewSimulatedBackend(1) error %v", err)
+	}
+	code := hexutil.MustDecode("0x600180600b6000396000f300")
+	if _, _, _, err := bind.DeployContract(sim.Acc(0), abi.ABI{}, code, sim); err != nil {
+		t.Fatalf("bind.DeployContract() error %v", err)
+	}
+
+	if err := sim.Close(); err == nil {
+		t.Errorf("%T.Close() after failing to record gas got nil error; want error", sim)
+	}
+}
@@ -0,0 +1,97 @@
+package ethtest
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+)
+
+var genesis struct {
+	sync.Mutex
+	once      sync.Once
+	creation  map[common.Address][]byte
+	contracts core.GenesisAlloc
+	err       error
+}
+
+// RegisterGenesisContract includes a contract, at the address, in the genesis
+// block of every SimulatedBackend. This allows contracts to be placed at
+// canonical addresses that can't otherwise be deployed to. The contract's
+// creation code is run without a transaction so its constructor MUST NOT
+// depend on msg.sender, msg.value, or its own address. Storage written by the
+// constructor is included in the genesis block, but it MUST NOT write to other
+// accounts, nor emit events that are expected to be observed.
+//
+// RegisterGenesisContract MUST only be called from init() functions, typically
+// of packages providing bindings of the contract, as it panics if called after
+// the first SimulatedBackend is constructed or if the address is already
+// registered.
+func RegisterGenesisContract(addr common.Address, creationCode []byte) {
+	genesis.Lock()
+	defer genesis.Unlock()
+
+	if genesis.contracts != nil || genesis.err != nil {
+		panic(fmt.Sprintf("ethtest.RegisterGenesisContract(%v) called after construction of a SimulatedBackend", addr))
+	}
+	if genesis.creation == nil {
+		genesis.creation = make(map[common.Address][]byte)
+	}
+	if _, ok := genesis.creation[addr]; ok {
+		panic(fmt.Sprintf("ethtest.RegisterGenesisContract(%v) called twice for the same address", addr))
+	}
+	genesis.creation[addr] = creationCode
+}
+
+// genesisContracts returns the runtime code and storage of contracts, keyed by
+// address, that are registered with RegisterGenesisContract().
+func genesisContracts() (core.GenesisAlloc, error) {
+	genesis.once.Do(func() {
+		genesis.Lock()
+		defer genesis.Unlock()
+
+		contracts := make(core.GenesisAlloc)
+		for addr, c := range genesis.creation {
+			acc, err := runCreation(c)
+			if err != nil {
+				genesis.err = fmt.Errorf("<creation code for %v>: %v", addr, err)
+				return
+			}
+			contracts[addr] = acc
+		}
+		genesis.contracts = contracts
+	})
+	return genesis.contracts, genesis.err
+}
+
+// runCreation runs the creation code without a transaction and returns the
+// resulting runtime code and storage.
+func runCreation(creation []byte) (core.GenesisAccount, error) {
+	cfg := new(runtime.Config)
+	code, created, _, err := runtime.Create(creation, cfg)
+	if err != nil {
+		return core.GenesisAccount{}, fmt.Errorf("runtime.Create(): %v", err)
+	}
+	if _, err := cfg.State.Commit(true); err != nil {
+		return core.GenesisAccount{}, fmt.Errorf("%T.Commit(): %v", cfg.State, err)
+	}
+
+	acc := core.GenesisAccount{
+		Code:    code,
+		Balance: new(big.Int),
+	}
+	err = cfg.State.ForEachStorage(created, func(k, v common.Hash) bool {
+		if acc.Storage == nil {
+			acc.Storage = make(map[common.Hash]common.Hash)
+		}
+		acc.Storage[k] = v
+		return true
+	})
+	if err != nil {
+		return core.GenesisAccount{}, fmt.Errorf("%T.ForEachStorage(): %v", cfg.State, err)
+	}
+	return acc, nil
+}
@@ -0,0 +1,214 @@
+// Package goldentest compares the metadata of fully on-chain tokens, as
+// returned by tokenURI() in an ethtest.SimulatedBackend, against golden files.
+//
+// Base64 data URIs are decoded so that the golden files are human-readable
+// JSON and, for data-URI images, separate image files (e.g. SVG) that can be
+// viewed directly. Setting the UpdateEnvVar environment variable writes the
+// golden files instead of comparing against them:
+//
+//	ETHIER_UPDATE_GOLDEN=1 go test ./...
+//
+// after which the changes SHOULD be reviewed before being committed.
+package goldentest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/google/go-cmp/cmp"
+)
+
+// UpdateEnvVar is the environment variable that, if non-empty, causes golden
+// files to be written instead of compared.
+const UpdateEnvVar = "ETHIER_UPDATE_GOLDEN"
+
+// update reports whether UpdateEnvVar is set.
+func update() bool {
+	return os.Getenv(UpdateEnvVar) != ""
+}
+
+// DecodeDataURI decodes an RFC 2397 data URI, returning its media type and
+// data. Both base64 and percent-encoded data are supported.
+func DecodeDataURI(uri string) (mediaType string, data []byte, err error) {
+	const scheme = "data:"
+	if !strings.HasPrefix(uri, scheme) {
+		return "", nil, fmt.Errorf("URI %q missing %q scheme", truncate(uri), scheme)
+	}
+	rest := uri[len(scheme):]
+	comma := strings.Index(rest, ",")
+	if comma == -1 {
+		return "", nil, fmt.Errorf("data URI %q missing comma separator", truncate(uri))
+	}
+	meta, payload := rest[:comma], rest[comma+1:]
+
+	params := strings.Split(meta, ";")
+	mediaType = params[0]
+	if mediaType == "" {
+		mediaType = "text/plain"
+	}
+
+	if params[len(params)-1] == "base64" {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return "", nil, fmt.Errorf("base64 decoding data URI: %v", err)
+		}
+		return mediaType, data, nil
+	}
+
+	s, err := url.PathUnescape(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("percent decoding data URI: %v", err)
+	}
+	return mediaType, []byte(s), nil
+}
+
+// truncate shortens long data URIs for inclusion in error messages.
+func truncate(s string) string {
+	const max = 64
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "…"
+}
+
+// A TokenURIer is any contract binding with a tokenURI() method, as generated
+// by abigen for ERC721 contracts.
+type TokenURIer interface {
+	TokenURI(*bind.CallOpts, *big.Int) (string, error)
+}
+
+// Metadata is the decoded form of a token's metadata.
+type Metadata struct {
+	// JSON is the indented metadata with keys in sorted order. If the image
+	// was a data URI, it is replaced by ImageFile.
+	JSON []byte
+	// Image and ImageMediaType are only populated if the image was a data
+	// URI, in which case ImageFile is the name of the golden file to which it
+	// is compared.
+	Image          []byte
+	ImageMediaType string
+	ImageFile      string
+}
+
+// imageExtensions maps media types to golden-file extensions. Media types not
+// in the map use ".bin".
+var imageExtensions = map[string]string{
+	"image/svg+xml": ".svg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"text/html":     ".html",
+}
+
+// TokenMetadata calls nft.TokenURI(tokenID), which MUST return a data URI of
+// JSON metadata, and decodes the metadata and any data-URI image.
+func TokenMetadata(nft TokenURIer, tokenID *big.Int) (*Metadata, error) {
+	uri, err := nft.TokenURI(nil, tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("%T.TokenURI(%d): %v", nft, tokenID, err)
+	}
+	mediaType, raw, err := DecodeDataURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if mediaType != "application/json" {
+		return nil, fmt.Errorf("tokenURI(%d) media type %q; want application/json", tokenID, mediaType)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("json decoding tokenURI(%d) metadata %q: %v", tokenID, raw, err)
+	}
+
+	md := new(Metadata)
+	if img, ok := fields["image"].(string); ok && strings.HasPrefix(img, "data:") {
+		md.ImageMediaType, md.Image, err = DecodeDataURI(img)
+		if err != nil {
+			return nil, fmt.Errorf("decoding tokenURI(%d) image: %v", tokenID, err)
+		}
+		ext, ok := imageExtensions[md.ImageMediaType]
+		if !ok {
+			ext = ".bin"
+		}
+		md.ImageFile = tokenID.String() + ext
+		fields["image"] = md.ImageFile
+	}
+
+	// HTML escaping would obscure SVG fragments, e.g. in descriptions.
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(fields); err != nil {
+		return nil, fmt.Errorf("json re-encoding tokenURI(%d) metadata: %v", tokenID, err)
+	}
+	md.JSON = buf.Bytes()
+	return md, nil
+}
+
+// Compare returns a line-by-line diff of the golden file at path against got,
+// or the empty string if they are equal. If UpdateEnvVar is set, the golden
+// file is instead written with got, creating directories as necessary, and the
+// empty string is returned.
+func Compare(path string, got []byte) (string, error) {
+	if update() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		return "", os.WriteFile(path, got, 0644)
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%v; set %s to create golden files", err, UpdateEnvVar)
+	}
+	if bytes.Equal(want, got) {
+		return "", nil
+	}
+	lines := func(b []byte) []string {
+		return strings.Split(string(b), "\n")
+	}
+	return cmp.Diff(lines(want), lines(got)), nil
+}
+
+// CheckTokenURI compares the token's metadata, and any data-URI image, against
+// golden files in dir, named after the token ID; e.g. 42.json and 42.svg. Any
+// errors or differences are reported with tb.Error.
+func CheckTokenURI(tb testing.TB, nft TokenURIer, tokenID *big.Int, dir string) {
+	tb.Helper()
+
+	md, err := TokenMetadata(nft, tokenID)
+	if err != nil {
+		tb.Errorf("goldentest.TokenMetadata(%d) error %v", tokenID, err)
+		return
+	}
+
+	files := map[string][]byte{
+		tokenID.String() + ".json": md.JSON,
+	}
+	if md.ImageFile != "" {
+		files[md.ImageFile] = md.Image
+	}
+
+	for name, got := range files {
+		path := filepath.Join(dir, name)
+		diff, err := Compare(path, got)
+		if err != nil {
+			tb.Errorf("goldentest.Compare(%q) error %v", path, err)
+			continue
+		}
+		if diff != "" {
+			tb.Errorf("Token %d metadata differs from golden file %q (-want +got):\n%s", tokenID, path, diff)
+		}
+	}
+}
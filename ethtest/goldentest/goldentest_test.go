@@ -0,0 +1,164 @@
+package goldentest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeDataURI(t *testing.T) {
+	b64 := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		uri           string
+		wantMediaType string
+		wantData      string
+		wantErr       bool
+	}{
+		{
+			uri:           "data:image/svg+xml;base64," + b64("<svg/>"),
+			wantMediaType: "image/svg+xml",
+			wantData:      "<svg/>",
+		},
+		{
+			uri:           "data:application/json;charset=utf-8;base64," + b64(`{"a":1}`),
+			wantMediaType: "application/json",
+			wantData:      `{"a":1}`,
+		},
+		{
+			uri:           "data:image/svg+xml;utf8,<svg/>",
+			wantMediaType: "image/svg+xml",
+			wantData:      "<svg/>",
+		},
+		{
+			uri:           "data:,hello%20world",
+			wantMediaType: "text/plain",
+			wantData:      "hello world",
+		},
+		{
+			uri:     "ipfs://Qm",
+			wantErr: true,
+		},
+		{
+			uri:     "data:image/svg+xml;base64",
+			wantErr: true,
+		},
+		{
+			uri:     "data:image/svg+xml;base64,!!!",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		mediaType, data, err := DecodeDataURI(tt.uri)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("DecodeDataURI(%q) got err %v; want err? %t", tt.uri, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if mediaType != tt.wantMediaType || string(data) != tt.wantData {
+			t.Errorf("DecodeDataURI(%q) got (%q, %q); want (%q, %q)", tt.uri, mediaType, data, tt.wantMediaType, tt.wantData)
+		}
+	}
+}
+
+// fakeToken mimics an abigen binding of an on-chain-art contract.
+type fakeToken struct {
+	colour string
+}
+
+func (f fakeToken) TokenURI(_ *bind.CallOpts, id *big.Int) (string, error) {
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg"><rect fill="%s"/></svg>`, f.colour)
+	img := "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+	json := fmt.Sprintf(`{"name":"Token #%d","image":"%s","attributes":[{"trait_type":"Size","value":%d}]}`, id, img, id)
+	return "data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(json)), nil
+}
+
+func TestTokenMetadata(t *testing.T) {
+	got, err := TokenMetadata(fakeToken{colour: "red"}, big.NewInt(7))
+	if err != nil {
+		t.Fatalf("TokenMetadata() error %v", err)
+	}
+
+	want := &Metadata{
+		JSON: []byte(`{
+  "attributes": [
+    {
+      "trait_type": "Size",
+      "value": 7
+    }
+  ],
+  "image": "7.svg",
+  "name": "Token #7"
+}
+`),
+		Image:          []byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect fill="red"/></svg>`),
+		ImageMediaType: "image/svg+xml",
+		ImageFile:      "7.svg",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("TokenMetadata() diff (-want +got):\n%s", diff)
+	}
+}
+
+// fakeTB captures errors reported by CheckTokenURI().
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheckTokenURI(t *testing.T) {
+	// Ensure that only the "update" test writes golden files, regardless of
+	// the environment in which tests are run.
+	t.Setenv(UpdateEnvVar, "")
+	dir := t.TempDir()
+	id := big.NewInt(42)
+
+	t.Run("update", func(t *testing.T) {
+		t.Setenv(UpdateEnvVar, "1")
+		CheckTokenURI(t, fakeToken{colour: "red"}, id, dir)
+
+		for _, f := range []string{"42.json", "42.svg"} {
+			if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+				t.Errorf("After CheckTokenURI() with %s set; os.Stat(%q) error %v", UpdateEnvVar, f, err)
+			}
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		CheckTokenURI(t, fakeToken{colour: "red"}, id, dir)
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		CheckTokenURI(tb, fakeToken{colour: "blue"}, id, dir)
+		// Only the image differs.
+		if len(tb.errors) != 1 {
+			t.Errorf("CheckTokenURI() with changed image; got errors %q; want exactly one", tb.errors)
+		}
+	})
+
+	t.Run("missing golden file", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		CheckTokenURI(tb, fakeToken{colour: "red"}, big.NewInt(0), dir)
+		if len(tb.errors) == 0 {
+			t.Error("CheckTokenURI() without golden files; got no errors; want errors")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+// Package markettest places mocks of the LooksRare and Blur transfer managers,
+// the operators that users approve to transfer tokens sold on those
+// marketplaces, at their mainnet addresses in the genesis block of every
+// ethtest.SimulatedBackend. This allows operator-filter and approval logic to
+// be tested against the operators that the marketplaces actually use.
+//
+// As with multicalltest, deployment is automatic in any binary that imports
+// this package. For OpenSea, see the openseatest and seaporttest packages.
+//
+// Unlike the real contracts, which only accept calls from their respective
+// exchanges, the mocks accept calls from any address. This is sufficient for
+// testing the collections being transferred, but not the marketplaces
+// themselves.
+package markettest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/markettest/markettestabi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Mainnet addresses of marketplace transfer managers.
+var (
+	LooksRareTransferManagerERC721  = common.HexToAddress("0xf42aa99F011A1fA7CDA90E5E98b277E306BcA83e")
+	LooksRareTransferManagerERC1155 = common.HexToAddress("0xFED24eC7E22f573c2e08AEF55aA6797Ca2b3A051")
+	BlurExecutionDelegate           = common.HexToAddress("0x00000000000111AbE46ff893f3B2fdF1F759a8A8")
+)
+
+func init() {
+	for addr, bin := range map[common.Address]string{
+		LooksRareTransferManagerERC721:  markettestabi.SimulatedLooksRareTransferManagerERC721Bin,
+		LooksRareTransferManagerERC1155: markettestabi.SimulatedLooksRareTransferManagerERC1155Bin,
+		BlurExecutionDelegate:           markettestabi.SimulatedBlurExecutionDelegateBin,
+	} {
+		ethtest.RegisterGenesisContract(addr, common.FromHex(bin))
+	}
+}
+
+// Operators returns the addresses of all marketplace transfer managers, e.g.
+// for approving all of them or for populating an operator filter.
+func Operators() []common.Address {
+	return []common.Address{
+		LooksRareTransferManagerERC721,
+		LooksRareTransferManagerERC1155,
+		BlurExecutionDelegate,
+	}
+}
+
+// LooksRareTransferERC721 transfers the ERC721 token via LooksRare's transfer
+// manager, sending the transaction from the account; i.e. as the LooksRare
+// exchange would when an order is filled. The owner of the token MUST have
+// approved LooksRareTransferManagerERC721.
+func LooksRareTransferERC721(sim *ethtest.SimulatedBackend, account int, collection, from, to common.Address, tokenID *big.Int) (*types.Transaction, error) {
+	tm, err := markettestabi.NewSimulatedLooksRareTransferManagerERC721(LooksRareTransferManagerERC721, sim)
+	if err != nil {
+		return nil, err
+	}
+	return tm.TransferNonFungibleToken(sim.Acc(account), collection, from, to, tokenID, big.NewInt(1))
+}
+
+// LooksRareTransferERC721TB calls LooksRareTransferERC721() and reports any
+// errors with tb.Fatal.
+func LooksRareTransferERC721TB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, collection, from, to common.Address, tokenID *big.Int) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "LooksRareTransferERC721(%v, %v, %v, %d)", collection, from, to, tokenID)(LooksRareTransferERC721(sim, account, collection, from, to, tokenID))
+}
+
+// LooksRareTransferERC1155 is the ERC1155 equivalent of
+// LooksRareTransferERC721(), transferring via
+// LooksRareTransferManagerERC1155.
+func LooksRareTransferERC1155(sim *ethtest.SimulatedBackend, account int, collection, from, to common.Address, tokenID, amount *big.Int) (*types.Transaction, error) {
+	tm, err := markettestabi.NewSimulatedLooksRareTransferManagerERC1155(LooksRareTransferManagerERC1155, sim)
+	if err != nil {
+		return nil, err
+	}
+	return tm.TransferNonFungibleToken(sim.Acc(account), collection, from, to, tokenID, amount)
+}
+
+// LooksRareTransferERC1155TB calls LooksRareTransferERC1155() and reports any
+// errors with tb.Fatal.
+func LooksRareTransferERC1155TB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, collection, from, to common.Address, tokenID, amount *big.Int) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "LooksRareTransferERC1155(%v, %v, %v, %d, %d)", collection, from, to, tokenID, amount)(LooksRareTransferERC1155(sim, account, collection, from, to, tokenID, amount))
+}
+
+// BlurTransferERC721 transfers the ERC721 token, with safeTransferFrom(), via
+// Blur's execution delegate, sending the transaction from the account; i.e. as
+// the Blur exchange would when an order is filled. The owner of the token MUST
+// have approved BlurExecutionDelegate.
+func BlurTransferERC721(sim *ethtest.SimulatedBackend, account int, collection, from, to common.Address, tokenID *big.Int) (*types.Transaction, error) {
+	d, err := markettestabi.NewSimulatedBlurExecutionDelegate(BlurExecutionDelegate, sim)
+	if err != nil {
+		return nil, err
+	}
+	return d.TransferERC721(sim.Acc(account), collection, from, to, tokenID)
+}
+
+// BlurTransferERC721TB calls BlurTransferERC721() and reports any errors with
+// tb.Fatal.
+func BlurTransferERC721TB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, collection, from, to common.Address, tokenID *big.Int) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "BlurTransferERC721(%v, %v, %v, %d)", collection, from, to, tokenID)(BlurTransferERC721(sim, account, collection, from, to, tokenID))
+}
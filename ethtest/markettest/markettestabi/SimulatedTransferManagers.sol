@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/token/ERC721/IERC721.sol";
+import "@openzeppelin/contracts/token/ERC1155/IERC1155.sol";
+
+/**
+@notice Mirrors LooksRare's TransferManagerERC721, the operator approved by
+users to transfer their ERC721 tokens, for use with ethier's markettest Go
+package.
+@dev Unlike the real transfer manager, which only accepts calls from the
+LooksRare exchange, this mock accepts calls from any address.
+ */
+contract SimulatedLooksRareTransferManagerERC721 {
+    function transferNonFungibleToken(
+        address collection,
+        address from,
+        address to,
+        uint256 tokenId,
+        uint256
+    ) external {
+        IERC721(collection).safeTransferFrom(from, to, tokenId);
+    }
+}
+
+/**
+@notice Mirrors LooksRare's TransferManagerERC1155, the operator approved by
+users to transfer their ERC1155 tokens, for use with ethier's markettest Go
+package.
+@dev Unlike the real transfer manager, which only accepts calls from the
+LooksRare exchange, this mock accepts calls from any address.
+ */
+contract SimulatedLooksRareTransferManagerERC1155 {
+    function transferNonFungibleToken(
+        address collection,
+        address from,
+        address to,
+        uint256 tokenId,
+        uint256 amount
+    ) external {
+        IERC1155(collection).safeTransferFrom(from, to, tokenId, amount, "");
+    }
+}
+
+/**
+@notice Mirrors Blur's ExecutionDelegate, the operator approved by users to
+transfer their tokens, for use with ethier's markettest Go package.
+@dev Unlike the real delegate, which only accepts calls from contracts approved
+by its owner, this mock accepts calls from any address.
+ */
+contract SimulatedBlurExecutionDelegate {
+    function transferERC721Unsafe(
+        address collection,
+        address from,
+        address to,
+        uint256 tokenId
+    ) external {
+        IERC721(collection).transferFrom(from, to, tokenId);
+    }
+
+    function transferERC721(
+        address collection,
+        address from,
+        address to,
+        uint256 tokenId
+    ) external {
+        IERC721(collection).safeTransferFrom(from, to, tokenId);
+    }
+
+    function transferERC1155(
+        address collection,
+        address from,
+        address to,
+        uint256 tokenId,
+        uint256 amount
+    ) external {
+        IERC1155(collection).safeTransferFrom(from, to, tokenId, amount, "");
+    }
+}
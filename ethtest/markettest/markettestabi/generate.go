@@ -0,0 +1,6 @@
+// Package markettestabi is a generated package providing mocks of marketplace
+// transfer managers. There is likely no need to use this package directly as
+// its functionality is exposed via the markettest package.
+package markettestabi
+
+//go:generate ethier gen SimulatedTransferManagers.sol
@@ -0,0 +1,50 @@
+package ethtest
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/divergencetech/ethier/eth/merkle"
+)
+
+// MerkleTreeTB returns merkle.FromAddresses(addrs), reporting any error with
+// tb.Fatal.
+func MerkleTreeTB(tb testing.TB, addrs ...common.Address) *merkle.Tree {
+	tb.Helper()
+
+	t, err := merkle.FromAddresses(addrs)
+	if err != nil {
+		tb.Fatalf("merkle.FromAddresses(%v) error %v", addrs, err)
+	}
+	return t
+}
+
+// MerkleAllowlistTB returns merkle.FromAllowances(allowances), for use with
+// contracts inheriting MerkleAllowlist, reporting any error with tb.Fatal.
+func MerkleAllowlistTB(tb testing.TB, allowances ...merkle.Allowance) *merkle.Tree {
+	tb.Helper()
+
+	t, err := merkle.FromAllowances(allowances)
+	if err != nil {
+		tb.Fatalf("merkle.FromAllowances(%+v) error %v", allowances, err)
+	}
+	return t
+}
+
+// MerkleProofTB returns the tree's proof for the leaf, reporting any error with
+// tb.Fatal. The proof is returned in the form expected by generated bindings
+// for bytes32[] arguments.
+func MerkleProofTB(tb testing.TB, tree *merkle.Tree, leaf common.Hash) [][32]byte {
+	tb.Helper()
+
+	proof, err := tree.ProofFor(leaf)
+	if err != nil {
+		tb.Fatalf("%T.ProofFor(%v) error %v", tree, leaf, err)
+	}
+	out := make([][32]byte, len(proof))
+	for i, p := range proof {
+		out[i] = p
+	}
+	return out
+}
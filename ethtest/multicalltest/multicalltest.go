@@ -0,0 +1,74 @@
+// Package multicalltest can place Multicall3 at its canonical address in the
+// genesis block of ethtest.SimulatedBackends, allowing frontends, indexers and
+// Go multicall clients to behave identically in tests and in production.
+//
+// Deployment is opt-in, by calling Register() before the first
+// SimulatedBackend is constructed, typically in TestMain():
+//
+//	func TestMain(m *testing.M) {
+//		multicalltest.Register()
+//		os.Exit(m.Run())
+//	}
+package multicalltest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/multicalltest/multicalltestabi"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Address is the canonical address of Multicall3.
+var Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+var register sync.Once
+
+// Register includes Multicall3, at Address, in the genesis block of every
+// SimulatedBackend. It MUST be called before the first SimulatedBackend is
+// constructed, as it is a thin wrapper around ethtest.RegisterGenesisContract(),
+// but is safe to call multiple times.
+func Register() {
+	register.Do(func() {
+		ethtest.RegisterGenesisContract(Address, common.FromHex(multicalltestabi.Multicall3Bin))
+	})
+}
+
+// New returns a binding of Multicall3 at Address, which is only deployed if
+// Register() was called.
+func New(sim *ethtest.SimulatedBackend) (*multicalltestabi.Multicall3, error) {
+	return multicalltestabi.NewMulticall3(Address, sim)
+}
+
+// NewTB calls New() and reports any errors with tb.Fatal.
+func NewTB(tb testing.TB, sim *ethtest.SimulatedBackend) *multicalltestabi.Multicall3 {
+	tb.Helper()
+
+	m, err := New(sim)
+	if err != nil {
+		tb.Fatalf("multicalltest.New() error %v", err)
+	}
+	return m
+}
+
+// Aggregate3 performs the calls with Multicall3's aggregate3() function, as an
+// eth_call against the latest block, and returns their results. An error is
+// returned if any call that doesn't allow failure fails.
+func Aggregate3(ctx context.Context, sim *ethtest.SimulatedBackend, calls ...multicalltestabi.Multicall3Call3) ([]multicalltestabi.Multicall3Result, error) {
+	m, err := New(sim)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []interface{}
+	raw := &multicalltestabi.Multicall3Raw{Contract: m}
+	if err := raw.Call(&bind.CallOpts{Context: ctx}, &out, "aggregate3", calls); err != nil {
+		return nil, fmt.Errorf("Multicall3.aggregate3(<%d calls>): %v", len(calls), err)
+	}
+	return *abi.ConvertType(out[0], new([]multicalltestabi.Multicall3Result)).(*[]multicalltestabi.Multicall3Result), nil
+}
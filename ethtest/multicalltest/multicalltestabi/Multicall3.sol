@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.12 <0.9.0;
+
+/**
+@notice Multicall3, with an interface identical to that deployed at
+0xcA11bde05977b3631167028862bE2a173976CA11 on most chains, for use with
+ethier's multicalltest Go package.
+@dev The deprecated aggregate(), tryAggregate(), blockAndAggregate(), and
+tryBlockAndAggregate() functions are included for compatibility with existing
+clients.
+ */
+contract Multicall3 {
+    struct Call {
+        address target;
+        bytes callData;
+    }
+
+    struct Call3 {
+        address target;
+        bool allowFailure;
+        bytes callData;
+    }
+
+    struct Call3Value {
+        address target;
+        bool allowFailure;
+        uint256 value;
+        bytes callData;
+    }
+
+    struct Result {
+        bool success;
+        bytes returnData;
+    }
+
+    /// @notice Aggregates calls, reverting if any of them fails.
+    function aggregate(Call[] calldata calls)
+        public
+        payable
+        returns (uint256 blockNumber, bytes[] memory returnData)
+    {
+        blockNumber = block.number;
+        returnData = new bytes[](calls.length);
+        for (uint256 i = 0; i < calls.length; i++) {
+            bool success;
+            // solhint-disable-next-line avoid-low-level-calls
+            (success, returnData[i]) = calls[i].target.call(
+                calls[i].callData
+            );
+            require(success, "Multicall3: call failed");
+        }
+    }
+
+    /**
+    @notice Aggregates calls, reverting on failure only if requireSuccess is
+    true.
+     */
+    function tryAggregate(bool requireSuccess, Call[] calldata calls)
+        public
+        payable
+        returns (Result[] memory returnData)
+    {
+        returnData = new Result[](calls.length);
+        for (uint256 i = 0; i < calls.length; i++) {
+            Result memory result = returnData[i];
+            // solhint-disable-next-line avoid-low-level-calls
+            (result.success, result.returnData) = calls[i].target.call(
+                calls[i].callData
+            );
+            if (requireSuccess) {
+                require(result.success, "Multicall3: call failed");
+            }
+        }
+    }
+
+    /// @notice As tryAggregate(), also returning the block number and hash.
+    function tryBlockAndAggregate(bool requireSuccess, Call[] calldata calls)
+        public
+        payable
+        returns (
+            uint256 blockNumber,
+            bytes32 blockHash,
+            Result[] memory returnData
+        )
+    {
+        blockNumber = block.number;
+        blockHash = blockhash(block.number);
+        returnData = tryAggregate(requireSuccess, calls);
+    }
+
+    /// @notice As tryBlockAndAggregate(true, calls).
+    function blockAndAggregate(Call[] calldata calls)
+        public
+        payable
+        returns (
+            uint256 blockNumber,
+            bytes32 blockHash,
+            Result[] memory returnData
+        )
+    {
+        (blockNumber, blockHash, returnData) = tryBlockAndAggregate(
+            true,
+            calls
+        );
+    }
+
+    /**
+    @notice Aggregates calls, reverting only if a call that doesn't allow
+    failure fails.
+     */
+    function aggregate3(Call3[] calldata calls)
+        public
+        payable
+        returns (Result[] memory returnData)
+    {
+        returnData = new Result[](calls.length);
+        for (uint256 i = 0; i < calls.length; i++) {
+            Result memory result = returnData[i];
+            Call3 calldata c = calls[i];
+            // solhint-disable-next-line avoid-low-level-calls
+            (result.success, result.returnData) = c.target.call(c.callData);
+            require(
+                result.success || c.allowFailure,
+                "Multicall3: call failed"
+            );
+        }
+    }
+
+    /**
+    @notice As aggregate3() but with a value sent with each call; the sum of
+    values MUST equal msg.value.
+     */
+    function aggregate3Value(Call3Value[] calldata calls)
+        public
+        payable
+        returns (Result[] memory returnData)
+    {
+        uint256 valAccumulator;
+        returnData = new Result[](calls.length);
+        for (uint256 i = 0; i < calls.length; i++) {
+            Result memory result = returnData[i];
+            Call3Value calldata c = calls[i];
+            valAccumulator += c.value;
+            // solhint-disable-next-line avoid-low-level-calls
+            (result.success, result.returnData) = c.target.call{
+                value: c.value
+            }(c.callData);
+            require(
+                result.success || c.allowFailure,
+                "Multicall3: call failed"
+            );
+        }
+        require(msg.value == valAccumulator, "Multicall3: value mismatch");
+    }
+
+    function getBlockHash(uint256 blockNumber)
+        public
+        view
+        returns (bytes32 blockHash)
+    {
+        blockHash = blockhash(blockNumber);
+    }
+
+    function getBlockNumber() public view returns (uint256 blockNumber) {
+        blockNumber = block.number;
+    }
+
+    function getCurrentBlockCoinbase() public view returns (address coinbase) {
+        coinbase = block.coinbase;
+    }
+
+    function getCurrentBlockDifficulty()
+        public
+        view
+        returns (uint256 difficulty)
+    {
+        difficulty = block.difficulty;
+    }
+
+    function getCurrentBlockGasLimit() public view returns (uint256 gaslimit) {
+        gaslimit = block.gaslimit;
+    }
+
+    function getCurrentBlockTimestamp()
+        public
+        view
+        returns (uint256 timestamp)
+    {
+        // solhint-disable-next-line not-rely-on-time
+        timestamp = block.timestamp;
+    }
+
+    function getEthBalance(address addr) public view returns (uint256 balance) {
+        balance = addr.balance;
+    }
+
+    function getLastBlockHash() public view returns (bytes32 blockHash) {
+        unchecked {
+            blockHash = blockhash(block.number - 1);
+        }
+    }
+
+    function getBasefee() public view returns (uint256 basefee) {
+        basefee = block.basefee;
+    }
+
+    function getChainId() public view returns (uint256 chainid) {
+        chainid = block.chainid;
+    }
+}
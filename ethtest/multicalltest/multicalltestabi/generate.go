@@ -0,0 +1,6 @@
+// Package multicalltestabi is a generated package providing Multicall3. There
+// is likely no need to use this package directly as its functionality is
+// exposed via the multicalltest package.
+package multicalltestabi
+
+//go:generate ethier gen Multicall3.sol
@@ -3,11 +3,15 @@ package openseatest
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/divergencetech/ethier/ethtest"
 	"github.com/divergencetech/ethier/ethtest/openseatest/openseatestabi"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -17,6 +21,12 @@ import (
 // ethtest.SimulatedBackend have deterministic keys.
 var proxyRegistry = common.HexToAddress("E1a2bbc877b29ADBC56D2659DBcb0ae14ee62071")
 
+// ProxyRegistryAddress returns the address at which DeployProxyRegistry()
+// deploys the simulated ProxyRegistry.
+func ProxyRegistryAddress() common.Address {
+	return proxyRegistry
+}
+
 // DeployProxyRegistry deploys a mocked Wyvern proxy registry to the
 // SimulatedBackend.
 //
@@ -71,3 +81,142 @@ func SetProxyTB(tb testing.TB, sim *ethtest.SimulatedBackend, owner, proxy commo
 		tb.Fatalf("openseatestabi.SetProxy(%v, %v) error %v", owner, proxy, err)
 	}
 }
+
+// wyvernRegistryABI is the subset of Wyvern's ProxyRegistry interface through
+// which users register their proxies and exchanges are authorised to call them.
+// It is used instead of the simulated registry's bindings to exercise the same
+// calls as made on mainnet.
+const wyvernRegistryABI = `[
+	{"type":"function","name":"registerProxy","stateMutability":"nonpayable","inputs":[],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"grantAuthentication","stateMutability":"nonpayable","inputs":[{"name":"addr","type":"address"}],"outputs":[]}
+]`
+
+// wyvernProxyABI is the subset of Wyvern's AuthenticatedProxy interface through
+// which the exchange acts on behalf of a user when an order is matched.
+const wyvernProxyABI = `[
+	{"type":"function","name":"proxyAssert","stateMutability":"nonpayable","inputs":[{"name":"dest","type":"address"},{"name":"howToCall","type":"uint8"},{"name":"calldata","type":"bytes"}],"outputs":[]}
+]`
+
+// factoryABI is the subset of the OpenSea FactoryERC721 interface used in a
+// sale.
+const factoryABI = `[
+	{"type":"function","name":"owner","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"transferFrom","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"outputs":[]}
+]`
+
+// bindABI returns a BoundContract for the JSON ABI at the address.
+func bindABI(sim *ethtest.SimulatedBackend, addr common.Address, abiJSON string) (*bind.BoundContract, abi.ABI, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, abi.ABI{}, fmt.Errorf("abi.JSON(): %v", err)
+	}
+	return bind.NewBoundContract(addr, parsed, sim, sim, sim), parsed, nil
+}
+
+// RegisterProxyAsUser registers a proxy for the account through the simulated
+// Wyvern proxy registry's registerProxy() function, which deploys a delegate
+// proxy, just as a user does before their first OpenSea listing. The registry
+// MUST already have been deployed with DeployProxyRegistry[TB](). The address
+// of the user's new proxy is returned.
+func RegisterProxyAsUser(sim *ethtest.SimulatedBackend, user int) (common.Address, error) {
+	registry, _, err := bindABI(sim, proxyRegistry, wyvernRegistryABI)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("bind Wyvern registry: %v", err)
+	}
+	if _, err := registry.Transact(sim.Acc(user), "registerProxy"); err != nil {
+		return common.Address{}, fmt.Errorf("%v.registerProxy() as account %d: %v", proxyRegistry, user, err)
+	}
+
+	reg, err := openseatestabi.NewSimulatedProxyRegistry(proxyRegistry, sim)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("openseatestabi.NewSimulatedProxyRegistry(): %v", err)
+	}
+	proxy, err := reg.Proxies(nil, sim.Addr(user))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%T.Proxies(%v): %v", reg, sim.Addr(user), err)
+	}
+	return proxy, nil
+}
+
+// RegisterProxyAsUserTB calls RegisterProxyAsUser() and reports any errors with
+// tb.Fatal.
+func RegisterProxyAsUserTB(tb testing.TB, sim *ethtest.SimulatedBackend, user int) common.Address {
+	tb.Helper()
+
+	proxy, err := RegisterProxyAsUser(sim, user)
+	if err != nil {
+		tb.Fatalf("openseatest.RegisterProxyAsUser(%d) error %v", user, err)
+	}
+	return proxy
+}
+
+// SimulateFactorySale reproduces the full purchase path of a lazily minted
+// option from an OpenSea ERC721 factory, as used by OpenSeaERC721Mintable, and
+// reports any errors with tb.Fatal.
+//
+// The OpenSea mocked entity acts as the Wyvern exchange: it is authorised by
+// the proxy registry and, as if matching the factory owner's listing with the
+// buyer's order, has the owner's proxy call factory.transferFrom(owner, buyer,
+// optionID), which the factory propagates to a mint. Payment isn't simulated.
+//
+// The factory owner's proxy MUST have been registered with
+// RegisterProxyAsUser[TB](); SetProxy[TB]() is insufficient as the proxy has
+// to be a contract.
+func SimulateFactorySale(tb testing.TB, sim *ethtest.SimulatedBackend, factory common.Address, optionID *big.Int, buyer common.Address) {
+	tb.Helper()
+	if err := simulateFactorySale(sim, factory, optionID, buyer); err != nil {
+		tb.Fatalf("openseatest.SimulateFactorySale(%v, %d, %v) error %v", factory, optionID, buyer, err)
+	}
+}
+
+func simulateFactorySale(sim *ethtest.SimulatedBackend, factory common.Address, optionID *big.Int, buyer common.Address) error {
+	fact, factABI, err := bindABI(sim, factory, factoryABI)
+	if err != nil {
+		return fmt.Errorf("bind factory: %v", err)
+	}
+	var out []interface{}
+	if err := fact.Call(nil, &out, "owner"); err != nil {
+		return fmt.Errorf("%v.owner(): %v", factory, err)
+	}
+	owner := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	reg, err := openseatestabi.NewSimulatedProxyRegistry(proxyRegistry, sim)
+	if err != nil {
+		return fmt.Errorf("openseatestabi.NewSimulatedProxyRegistry(): %v", err)
+	}
+	proxyAddr, err := reg.Proxies(nil, owner)
+	if err != nil {
+		return fmt.Errorf("%T.Proxies(%v): %v", reg, owner, err)
+	}
+	code, err := sim.CodeAt(context.Background(), proxyAddr, nil)
+	if err != nil {
+		return fmt.Errorf("%T.CodeAt(%v): %v", sim, proxyAddr, err)
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("proxy %v of factory owner %v is not a contract; register it with RegisterProxyAsUser()", proxyAddr, owner)
+	}
+
+	registry, _, err := bindABI(sim, proxyRegistry, wyvernRegistryABI)
+	if err != nil {
+		return fmt.Errorf("bind Wyvern registry: %v", err)
+	}
+	proxy, _, err := bindABI(sim, proxyAddr, wyvernProxyABI)
+	if err != nil {
+		return fmt.Errorf("bind Wyvern proxy: %v", err)
+	}
+	transfer, err := factABI.Pack("transferFrom", owner, buyer, optionID)
+	if err != nil {
+		return fmt.Errorf("%T.Pack(\"transferFrom\", %v, %v, %d): %v", factABI, owner, buyer, optionID, err)
+	}
+
+	return sim.AsMockedEntity(ethtest.OpenSea, func(opts *bind.TransactOpts) error {
+		if _, err := registry.Transact(opts, "grantAuthentication", opts.From); err != nil {
+			return fmt.Errorf("%v.grantAuthentication(<exchange>): %v", proxyRegistry, err)
+		}
+		// Wyvern's HowToCall.Call is 0.
+		if _, err := proxy.Transact(opts, "proxyAssert", factory, uint8(0), transfer); err != nil {
+			return fmt.Errorf("%v.proxyAssert(%v, Call, transferFrom(…)): %v", proxyAddr, factory, err)
+		}
+		return nil
+	})
+}
@@ -9,7 +9,159 @@ import "../../../contracts/thirdparty/opensea/ProxyRegistry.sol";
 ethtest.SimulatedBackend Go testing.
  */
 contract SimulatedProxyRegistry is ProxyRegistry {
+    /// @notice Implementation to which all registered proxies delegate.
+    address public immutable delegateProxyImplementation;
+
+    /// @notice Contracts, e.g. exchanges, authorised to call user proxies.
+    mapping(address => bool) public contracts;
+
+    constructor() {
+        delegateProxyImplementation = address(
+            new SimulatedAuthenticatedProxy()
+        );
+    }
+
     function setProxyFor(address owner, address proxy) public {
         proxies[owner] = OwnableDelegateProxy(proxy);
     }
+
+    /**
+    @notice Mirrors Wyvern's registerProxy(), deploying a proxy that acts on
+    behalf of the sender.
+     */
+    function registerProxy() external returns (OwnableDelegateProxy) {
+        require(
+            address(proxies[msg.sender]) == address(0),
+            "SimulatedProxyRegistry: proxy already registered"
+        );
+
+        SimulatedOwnableDelegateProxy proxy = new SimulatedOwnableDelegateProxy(
+            msg.sender,
+            delegateProxyImplementation,
+            abi.encodeWithSelector(
+                SimulatedAuthenticatedProxy.initialize.selector,
+                msg.sender,
+                this
+            )
+        );
+        proxies[msg.sender] = OwnableDelegateProxy(address(proxy));
+        return proxies[msg.sender];
+    }
+
+    /// @notice Authorises the contract to call user proxies.
+    function grantAuthentication(address addr) external {
+        contracts[addr] = true;
+    }
+}
+
+/**
+@notice Storage shared by SimulatedOwnableDelegateProxy and its implementation,
+SimulatedAuthenticatedProxy, mirroring Wyvern's OwnedUpgradeabilityStorage.
+ */
+contract SimulatedProxyStorage {
+    address public upgradeabilityOwner;
+    address public implementation;
+}
+
+/**
+@notice Mirrors Wyvern's OwnableDelegateProxy, delegating all calls to its
+implementation.
+ */
+contract SimulatedOwnableDelegateProxy is SimulatedProxyStorage {
+    constructor(
+        address owner,
+        address initialImplementation,
+        bytes memory data
+    ) {
+        upgradeabilityOwner = owner;
+        implementation = initialImplementation;
+
+        // solhint-disable-next-line avoid-low-level-calls
+        (bool success, ) = initialImplementation.delegatecall(data);
+        require(success, "SimulatedOwnableDelegateProxy: init failed");
+    }
+
+    // solhint-disable-next-line no-complex-fallback
+    fallback() external payable {
+        address impl = implementation;
+        // solhint-disable-next-line no-inline-assembly
+        assembly {
+            calldatacopy(0, 0, calldatasize())
+            let result := delegatecall(gas(), impl, 0, calldatasize(), 0, 0)
+            returndatacopy(0, 0, returndatasize())
+            switch result
+            case 0 {
+                revert(0, returndatasize())
+            }
+            default {
+                return(0, returndatasize())
+            }
+        }
+    }
+}
+
+/**
+@notice Mirrors Wyvern's AuthenticatedProxy, the implementation behind every
+user's proxy, which calls arbitrary contracts on behalf of the user.
+ */
+contract SimulatedAuthenticatedProxy is SimulatedProxyStorage {
+    bool public initialized;
+    address public user;
+    SimulatedProxyRegistry public registry;
+    bool public revoked;
+
+    enum HowToCall {
+        Call,
+        DelegateCall
+    }
+
+    function initialize(address addrUser, SimulatedProxyRegistry addrRegistry)
+        external
+    {
+        require(!initialized, "SimulatedAuthenticatedProxy: initialized");
+        initialized = true;
+        user = addrUser;
+        registry = addrRegistry;
+    }
+
+    /// @notice Revokes or reinstates access of authorised contracts.
+    function setRevoke(bool revoke) external {
+        require(msg.sender == user, "SimulatedAuthenticatedProxy: only user");
+        revoked = revoke;
+    }
+
+    /**
+    @notice Calls the destination on behalf of the user, returning whether the
+    call succeeded.
+     */
+    function proxy(
+        address dest,
+        HowToCall howToCall,
+        bytes memory data
+    ) public returns (bool result) {
+        require(
+            msg.sender == user || (!revoked && registry.contracts(msg.sender)),
+            "SimulatedAuthenticatedProxy: unauthorised"
+        );
+
+        // solhint-disable avoid-low-level-calls
+        if (howToCall == HowToCall.Call) {
+            (result, ) = dest.call(data);
+        } else {
+            (result, ) = dest.delegatecall(data);
+        }
+        // solhint-enable avoid-low-level-calls
+    }
+
+    /// @notice Equivalent to proxy() but reverts if the call fails.
+    function proxyAssert(
+        address dest,
+        HowToCall howToCall,
+        bytes memory data
+    ) external {
+        require(
+            proxy(dest, howToCall, data),
+            "SimulatedAuthenticatedProxy: call failed"
+        );
+    }
 }
@@ -0,0 +1,241 @@
+// Package seaporttest provides a deployment of OpenSea's Seaport marketplace
+// protocol, allowing the royalty and transfer behaviour of ethier NFTs to be
+// tested against real orders.
+//
+// The simulated backend can't reproduce Seaport's canonical deployment so, as
+// with all other test doubles, contracts are instead deployed to deterministic
+// addresses. Orders MUST therefore be signed against Addresses().Seaport, not
+// seaport.Address.
+package seaporttest
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/eth/seaport"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/openseatest/seaporttest/seaporttestabi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Contracts carries addresses for Seaport contracts.
+type Contracts struct {
+	Seaport, ConduitController common.Address
+}
+
+// Addresses returns the addresses to which DeploySeaport deploys Seaport
+// contracts.
+func Addresses() Contracts {
+	return addresses
+}
+
+var addresses = Contracts{
+	Seaport:           common.HexToAddress("0A96b6Bd8747a67aF57B194a47CCB3482C8AC4fE"),
+	ConduitController: common.HexToAddress("d05a36516F6596d65DF22E8aEc61A736d9595c21"),
+}
+
+// ListingDuration is the period for which orders returned by NewListing() are
+// valid.
+const ListingDuration = 30 * 24 * time.Hour
+
+// DeploySeaport deploys Seaport and its conduit controller to the
+// SimulatedBackend, returning a binding of the former.
+//
+// This function MUST only be called once for each SimulatedBackend; all future
+// calls will deploy to different addresses to those returned by Addresses().
+func DeploySeaport(sim *ethtest.SimulatedBackend) (*seaporttestabi.Seaport, error) {
+	err := sim.AsMockedEntity(ethtest.Seaport, func(opts *bind.TransactOpts) error {
+
+		_, _, s, err := seaporttestabi.DeploySimulatedSeaport(opts, sim)
+		if err != nil {
+			return fmt.Errorf("seaporttestabi.DeploySimulatedSeaport() error %v", err)
+		}
+
+		sp, err := s.Seaport(nil)
+		if err != nil {
+			return fmt.Errorf("%T.Seaport(): %v", s, err)
+		}
+		cc, err := s.ConduitController(nil)
+		if err != nil {
+			return fmt.Errorf("%T.ConduitController(): %v", s, err)
+		}
+
+		deployed := Contracts{
+			Seaport:           sp,
+			ConduitController: cc,
+		}
+		if want := addresses; !cmp.Equal(deployed, want) {
+			return fmt.Errorf("unexpected deployment addresses %+v; expecting %+v", deployed, want)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return seaporttestabi.NewSeaport(addresses.Seaport, sim)
+}
+
+// DeploySeaportTB calls DeploySeaport() and reports any errors with tb.Fatal.
+func DeploySeaportTB(tb testing.TB, sim *ethtest.SimulatedBackend) *seaporttestabi.Seaport {
+	tb.Helper()
+
+	s, err := DeploySeaport(sim)
+	if err != nil {
+		tb.Fatalf("seaporttest.DeploySeaport() error %v", err)
+	}
+	return s
+}
+
+// NewListing returns a signed order, as built by seaport.NewListing(), offering
+// the ERC721 token for a fixed price in ETH. The order is valid from the time
+// of the latest block, for ListingDuration. The offerer MUST own the token and
+// have approved Seaport to transfer it before the order can be fulfilled.
+func NewListing(sim *ethtest.SimulatedBackend, offerer eth.SignerBackend, token common.Address, tokenID, price *big.Int, fees ...seaport.Fee) (seaport.Order, error) {
+	sp, err := seaporttestabi.NewSeaport(addresses.Seaport, sim)
+	if err != nil {
+		return seaport.Order{}, fmt.Errorf("seaporttestabi.NewSeaport(%v): %v", addresses.Seaport, err)
+	}
+	counter, err := sp.GetCounter(nil, offerer.Address())
+	if err != nil {
+		return seaport.Order{}, fmt.Errorf("%T.GetCounter(%v): %v", sp, offerer.Address(), err)
+	}
+
+	head, err := sim.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return seaport.Order{}, fmt.Errorf("%T.HeaderByNumber(nil): %v", sim, err)
+	}
+	start := time.Unix(int64(head.Time), 0)
+
+	o, err := seaport.NewListing(offerer.Address(), token, tokenID, price, fees, start, start.Add(ListingDuration), counter)
+	if err != nil {
+		return seaport.Order{}, err
+	}
+	sig, err := o.Sign(offerer, sim.Blockchain().Config().ChainID, addresses.Seaport)
+	if err != nil {
+		return seaport.Order{}, err
+	}
+	return seaport.Order{
+		Parameters: o.Parameters(),
+		Signature:  sig,
+	}, nil
+}
+
+// NewListingTB calls NewListing() and reports any errors with tb.Fatal.
+func NewListingTB(tb testing.TB, sim *ethtest.SimulatedBackend, offerer eth.SignerBackend, token common.Address, tokenID, price *big.Int, fees ...seaport.Fee) seaport.Order {
+	tb.Helper()
+
+	o, err := NewListing(sim, offerer, token, tokenID, price, fees...)
+	if err != nil {
+		tb.Fatalf("seaporttest.NewListing(%v, %v, %d, %d, %+v) error %v", offerer.Address(), token, tokenID, price, fees, err)
+	}
+	return o
+}
+
+// FulfillBasicOrder fulfills the order with Seaport's fulfillBasicOrder(),
+// sending the transaction from the specified account with the total of all
+// consideration items as its value.
+//
+// The order MUST offer a single ERC721 or ERC1155 token in exchange for only
+// ETH, with the offerer receiving the first consideration item, as is the case
+// for orders returned by NewListing().
+func FulfillBasicOrder(sim *ethtest.SimulatedBackend, account int, order seaport.Order) (*types.Transaction, error) {
+	params, value, err := basicOrderParameters(order)
+	if err != nil {
+		return nil, err
+	}
+
+	sp, err := seaporttestabi.NewSeaport(addresses.Seaport, sim)
+	if err != nil {
+		return nil, fmt.Errorf("seaporttestabi.NewSeaport(%v): %v", addresses.Seaport, err)
+	}
+	return sp.FulfillBasicOrder(sim.WithValueFrom(account, value), params)
+}
+
+// FulfillBasicOrderTB calls FulfillBasicOrder() and reports any errors with
+// tb.Fatal.
+func FulfillBasicOrderTB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, order seaport.Order) *types.Transaction {
+	tb.Helper()
+
+	tx, err := FulfillBasicOrder(sim, account, order)
+	if err != nil {
+		tb.Fatalf("seaporttest.FulfillBasicOrder(%d, %+v) error %v", account, order, err)
+	}
+	return tx
+}
+
+// Basic-order routes, in the same order as the Solidity BasicOrderRouteType
+// enum; each spans four BasicOrderTypes, one for every seaport.OrderType.
+const (
+	ethToERC721Route = iota
+	ethToERC1155Route
+)
+
+// basicOrderParameters converts the order into parameters for
+// fulfillBasicOrder(), also returning the total value of its consideration.
+func basicOrderParameters(o seaport.Order) (seaporttestabi.BasicOrderParameters, *big.Int, error) {
+	p := o.Parameters
+	if n := len(p.Offer); n != 1 {
+		return seaporttestabi.BasicOrderParameters{}, nil, fmt.Errorf("basic orders require exactly 1 offer item; got %d", n)
+	}
+	offer := p.Offer[0]
+
+	var route uint8
+	switch offer.ItemType {
+	case seaport.ERC721:
+		route = ethToERC721Route
+	case seaport.ERC1155:
+		route = ethToERC1155Route
+	default:
+		return seaporttestabi.BasicOrderParameters{}, nil, fmt.Errorf("unsupported offer item type %d for basic order", offer.ItemType)
+	}
+
+	if len(p.Consideration) == 0 || p.Consideration[0].Recipient != p.Offerer {
+		return seaporttestabi.BasicOrderParameters{}, nil, fmt.Errorf("first consideration item of basic order must be received by offerer %v", p.Offerer)
+	}
+	value := new(big.Int)
+	var extra []seaporttestabi.AdditionalRecipient
+	for i, c := range p.Consideration {
+		if c.ItemType != seaport.Native {
+			return seaporttestabi.BasicOrderParameters{}, nil, fmt.Errorf("consideration item %d has type %d; basic orders only support native-token consideration", i, c.ItemType)
+		}
+		if c.StartAmount.Cmp(c.EndAmount) != 0 {
+			return seaporttestabi.BasicOrderParameters{}, nil, fmt.Errorf("consideration item %d has varying amount; unsupported by basic orders", i)
+		}
+		value.Add(value, c.EndAmount)
+		if i > 0 {
+			extra = append(extra, seaporttestabi.AdditionalRecipient{
+				Amount:    c.EndAmount,
+				Recipient: c.Recipient,
+			})
+		}
+	}
+
+	return seaporttestabi.BasicOrderParameters{
+		ConsiderationToken:                common.Address{},
+		ConsiderationIdentifier:           new(big.Int),
+		ConsiderationAmount:               p.Consideration[0].EndAmount,
+		Offerer:                           p.Offerer,
+		Zone:                              p.Zone,
+		OfferToken:                        offer.Token,
+		OfferIdentifier:                   offer.IdentifierOrCriteria,
+		OfferAmount:                       offer.EndAmount,
+		BasicOrderType:                    uint8(p.OrderType) + 4*route,
+		StartTime:                         p.StartTime,
+		EndTime:                           p.EndTime,
+		ZoneHash:                          p.ZoneHash,
+		Salt:                              p.Salt,
+		OffererConduitKey:                 p.ConduitKey,
+		FulfillerConduitKey:               [32]byte{},
+		TotalOriginalAdditionalRecipients: big.NewInt(int64(len(extra))),
+		AdditionalRecipients:              extra,
+		Signature:                         o.Signature,
+	}, value, nil
+}
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.13 <0.9.0;
+
+import "seaport/contracts/Seaport.sol";
+import "seaport/contracts/conduit/ConduitController.sol";
+
+/**
+@notice Deploys Seaport and its conduit controller for use with ethier's
+seaporttest Go package.
+ */
+contract SimulatedSeaport {
+    ConduitController public immutable conduitController;
+    Seaport public immutable seaport;
+
+    constructor() {
+        conduitController = new ConduitController();
+        seaport = new Seaport(address(conduitController));
+    }
+}
@@ -0,0 +1,8 @@
+// Package seaporttestabi is a generated package providing Seaport contracts.
+// There is likely no need to use this package directly as its functionality is
+// exposed via the seaporttest package.
+package seaporttestabi
+
+// Seaport exceeds the contract-size limit unless compiled as it is for the
+// canonical deployment.
+//go:generate ethier gen --optimize --optimize-runs 19066 --via-ir SimulatedSeaport.sol
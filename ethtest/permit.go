@@ -0,0 +1,72 @@
+package ethtest
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/divergencetech/ethier/eth"
+)
+
+// erc2612ABI is the subset of the ERC-2612 interface required for submitting
+// permits, allowing Permit() to be used with any token without generated
+// bindings.
+const erc2612ABI = `[
+	{"type":"function","name":"nonces","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"permit","stateMutability":"nonpayable","inputs":[
+		{"name":"owner","type":"address"},
+		{"name":"spender","type":"address"},
+		{"name":"value","type":"uint256"},
+		{"name":"deadline","type":"uint256"},
+		{"name":"v","type":"uint8"},
+		{"name":"r","type":"bytes32"},
+		{"name":"s","type":"bytes32"}
+	]}
+]`
+
+// Permit reads the owner's current nonce from the token, signs an ERC-2612
+// permit with eth.SignPermit(), and submits it to the token, sending the
+// transaction from the specified account. If token.ChainID is nil, the
+// backend's chain ID is used.
+func (sb *SimulatedBackend) Permit(ctx context.Context, account int, owner eth.SignerBackend, token eth.PermitToken, spender common.Address, value, deadline *big.Int) (*types.Transaction, error) {
+	parsed, err := abi.JSON(strings.NewReader(erc2612ABI))
+	if err != nil {
+		return nil, fmt.Errorf("parse ERC-2612 ABI: %v", err)
+	}
+	erc20 := bind.NewBoundContract(token.Address, parsed, sb, sb, sb)
+
+	var out []interface{}
+	if err := erc20.Call(&bind.CallOpts{Context: ctx}, &out, "nonces", owner.Address()); err != nil {
+		return nil, fmt.Errorf("%v.nonces(%v): %v", token.Address, owner.Address(), err)
+	}
+	nonce := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	if token.ChainID == nil {
+		token.ChainID = sb.Blockchain().Config().ChainID
+	}
+	v, r, s, err := eth.SignPermit(owner, token, owner.Address(), spender, value, nonce, deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := sb.Acc(account)
+	opts.Context = ctx
+	return erc20.Transact(opts, "permit", owner.Address(), spender, value, deadline, v, r, s)
+}
+
+// PermitTB calls sb.Permit(), reporting any error on tb.Fatal.
+func (sb *SimulatedBackend) PermitTB(tb testing.TB, account int, owner eth.SignerBackend, token eth.PermitToken, spender common.Address, value, deadline *big.Int) *types.Transaction {
+	tb.Helper()
+	tx, err := sb.Permit(context.Background(), account, owner, token, spender, value, deadline)
+	if err != nil {
+		tb.Fatalf("%T.Permit(…, owner=%v, spender=%v, value=%d, deadline=%d) error %v", sb, owner, spender, value, deadline, err)
+	}
+	return tx
+}
@@ -2,7 +2,12 @@
 package revert
 
 import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/h-fam/errdiff"
+
+	"github.com/divergencetech/ethier/ethtest"
 )
 
 // A Checker checks that a transaction reverts with the specified string. The
@@ -23,10 +28,26 @@ const (
 
 // Checkers for ethier libraries and contracts.
 const (
+	BuyerLimit           = Checker("Seller: Buyer limit")
+	DelegationExpired    = Checker("DelegationChecker: Expired")
+	DelegationScope      = Checker("DelegationChecker: Wrong scope")
 	ERC721ApproveOrOwner = Checker("ERC721ACommon: Not approved nor owner")
+	ExcessiveRoyalty     = Checker("ERC2981SinglePercentual: Excessive basis points")
+	GateAllowance        = Checker("SignatureGate: Exceeds allowance")
+	GateExpired          = Checker("SignatureGate: Expired")
+	GateSignature        = Checker("SignatureGate: Invalid signature")
+	InvalidMerkleProof   = Checker("MerkleAllowlist: Invalid proof")
 	InvalidSignature     = Checker("SignatureChecker: Invalid signature")
+	MerkleAllowance      = Checker("MerkleAllowlist: Exceeds allowance")
 	NotStarted           = Checker("LinearDutchAuction: Not started")
+	OriginLimit          = Checker("Seller: Origin limit")
+	SenderLimit          = Checker("Seller: Sender limit")
+	SessionSignature     = Checker("DelegationChecker: Invalid session signature")
 	SoldOut              = Checker("Seller: Sold out")
+	Soulbound            = Checker("TransferRestricted: Soulbound")
+	TransferLocked       = Checker("TransferRestricted: Locked")
+	VoucherExpired       = Checker("VoucherChecker: Expired")
+	ZeroRoyaltyReceiver  = Checker("ERC2981SinglePercentual: Zero receiver")
 )
 
 // Checkers for wETH test double. Use the wethtest package to deploy a modified
@@ -49,8 +70,56 @@ const (
 //	  t.Errorf("contract.Foo() %s", diff)
 //  }
 func (c Checker) Diff(_ interface{}, err error) string {
+	return errdiff.Substring(err, c.reason())
+}
+
+// OnCall returns a function that checks that a read-only contract call, made
+// via eth_call, reverted with the Checker's reason. Unlike Checker.Diff(), the
+// returned function also requires that the error carries the JSON-RPC
+// execution-error code, which is only the case for reverted calls and not for
+// other failures (e.g. ABI unpacking of the returned data).
+//
+// Typical usage with a view function:
+//
+//  if diff := revert.OnCall(revert.OnlyOwner)(contract.Foo(nil)); diff != "" {
+//	  t.Errorf("contract.Foo() %s", diff)
+//  }
+func OnCall(c Checker) func(interface{}, error) string {
+	return func(_ interface{}, err error) string {
+		if err != nil {
+			if _, ok := ethtest.ExecutionErrData(err); !ok {
+				return fmt.Sprintf("got non-execution err %v; want eth_call revert with %q", err, c.reason())
+			}
+		}
+		return c.Diff(nil, err)
+	}
+}
+
+// OnTx returns a function that checks that a state-changing transaction
+// reverted with the Checker's reason. A revert is only detectable before the
+// transaction is broadcast (i.e. during gas estimation) so the returned
+// function additionally requires that no transaction was returned; a non-nil
+// transaction means that it was sent and its reason is unavailable.
+//
+// Typical usage with a state-changing function:
+//
+//  if diff := revert.OnTx(revert.OnlyOwner)(contract.Foo(sim.Acc(1))); diff != "" {
+//	  t.Errorf("contract.Foo() %s", diff)
+//  }
+func OnTx(c Checker) func(*types.Transaction, error) string {
+	return func(tx *types.Transaction, err error) string {
+		if tx != nil {
+			return fmt.Sprintf("transaction %v was sent; want revert with %q before broadcast", tx.Hash(), c.reason())
+		}
+		return c.Diff(nil, err)
+	}
+}
+
+// reason returns the string against which c is checked, accounting for the
+// empty-string Checker being equivalent to Any.
+func (c Checker) reason() string {
 	if c == "" {
-		c = Any
+		return string(Any)
 	}
-	return errdiff.Substring(err, string(c))
+	return string(c)
 }
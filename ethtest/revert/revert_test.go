@@ -0,0 +1,109 @@
+package revert
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// executionError mimics the JSON-RPC error returned by a reverted eth_call.
+type executionError struct {
+	msg string
+}
+
+func (e executionError) Error() string          { return e.msg }
+func (e executionError) ErrorCode() int         { return 3 }
+func (e executionError) ErrorData() interface{} { return "0x" }
+
+func TestOnCall(t *testing.T) {
+	tests := []struct {
+		name     string
+		checker  Checker
+		err      error
+		wantDiff bool
+	}{
+		{
+			name:     "nil error",
+			checker:  OnlyOwner,
+			err:      nil,
+			wantDiff: true,
+		},
+		{
+			name:     "matching execution error",
+			checker:  OnlyOwner,
+			err:      executionError{"execution reverted: " + string(OnlyOwner)},
+			wantDiff: false,
+		},
+		{
+			name:     "any revert",
+			checker:  "",
+			err:      executionError{"execution reverted"},
+			wantDiff: false,
+		},
+		{
+			name:     "different reason",
+			checker:  OnlyOwner,
+			err:      executionError{"execution reverted: " + string(Paused)},
+			wantDiff: true,
+		},
+		{
+			name:     "non-execution error with matching message",
+			checker:  OnlyOwner,
+			err:      errors.New("execution reverted: " + string(OnlyOwner)),
+			wantDiff: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OnCall(tt.checker)(nil, tt.err); (got != "") != tt.wantDiff {
+				t.Errorf("OnCall(%q)(nil, %v) got diff %q; want non-empty diff = %t", tt.checker, tt.err, got, tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestOnTx(t *testing.T) {
+	sent := types.NewTx(&types.LegacyTx{})
+
+	tests := []struct {
+		name     string
+		checker  Checker
+		tx       *types.Transaction
+		err      error
+		wantDiff bool
+	}{
+		{
+			name:     "nil error",
+			checker:  OnlyOwner,
+			wantDiff: true,
+		},
+		{
+			name:     "matching error",
+			checker:  OnlyOwner,
+			err:      errors.New("execution reverted: " + string(OnlyOwner)),
+			wantDiff: false,
+		},
+		{
+			name:     "different reason",
+			checker:  OnlyOwner,
+			err:      errors.New("execution reverted: " + string(Paused)),
+			wantDiff: true,
+		},
+		{
+			name:     "transaction sent",
+			checker:  Any,
+			tx:       sent,
+			wantDiff: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OnTx(tt.checker)(tt.tx, tt.err); (got != "") != tt.wantDiff {
+				t.Errorf("OnTx(%q)(%v, %v) got diff %q; want non-empty diff = %t", tt.checker, tt.tx, tt.err, got, tt.wantDiff)
+			}
+		})
+	}
+}
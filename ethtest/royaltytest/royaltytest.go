@@ -0,0 +1,124 @@
+// Package royaltytest provides the Manifold Royalty Registry and Royalty
+// Engine, through which marketplaces discover the royalties payable on sales.
+package royaltytest
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/royaltytest/royaltytestabi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Contracts carries addresses for Royalty Registry contracts.
+type Contracts struct {
+	Registry, Engine common.Address
+}
+
+// Addresses returns the addresses to which Deploy deploys the Royalty Registry
+// contracts.
+func Addresses() Contracts {
+	return addresses
+}
+
+var addresses = Contracts{
+	Registry: common.HexToAddress("47a9Ec89C5bB44EfD27136D9E3080A6D9d9b6e8D"),
+	Engine:   common.HexToAddress("c74057ba74D5d55BF4E8ED579fcEebE5AE68Dc1e"),
+}
+
+// Deploy deploys the Royalty Registry and Royalty Engine to the
+// SimulatedBackend, returning a binding of the latter.
+//
+// This function MUST only be called once for each SimulatedBackend; all future
+// calls will deploy to different addresses to those returned by Addresses().
+func Deploy(sim *ethtest.SimulatedBackend) (*royaltytestabi.RoyaltyEngineV1, error) {
+	err := sim.AsMockedEntity(ethtest.Manifold, func(opts *bind.TransactOpts) error {
+
+		_, _, r, err := royaltytestabi.DeploySimulatedRoyaltyRegistry(opts, sim)
+		if err != nil {
+			return fmt.Errorf("royaltytestabi.DeploySimulatedRoyaltyRegistry() error %v", err)
+		}
+
+		reg, err := r.Registry(nil)
+		if err != nil {
+			return fmt.Errorf("%T.Registry(): %v", r, err)
+		}
+		eng, err := r.Engine(nil)
+		if err != nil {
+			return fmt.Errorf("%T.Engine(): %v", r, err)
+		}
+
+		deployed := Contracts{
+			Registry: reg,
+			Engine:   eng,
+		}
+		if want := addresses; !cmp.Equal(deployed, want) {
+			return fmt.Errorf("unexpected deployment addresses %+v; expecting %+v", deployed, want)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return royaltytestabi.NewRoyaltyEngineV1(addresses.Engine, sim)
+}
+
+// DeployTB calls Deploy() and reports any errors with tb.Fatal.
+func DeployTB(tb testing.TB, sim *ethtest.SimulatedBackend) *royaltytestabi.RoyaltyEngineV1 {
+	tb.Helper()
+
+	e, err := Deploy(sim)
+	if err != nil {
+		tb.Fatalf("royaltytest.Deploy() error %v", err)
+	}
+	return e
+}
+
+// A Royalty is a single payment due on a sale.
+type Royalty struct {
+	Recipient common.Address
+	Amount    *big.Int
+}
+
+// Lookup returns the royalties that the Royalty Engine reports as payable on a
+// sale of the token for the specified value, as a marketplace would look them
+// up. The Royalty Registry MUST already have been deployed with Deploy[TB]().
+func Lookup(sim *ethtest.SimulatedBackend, token common.Address, tokenID, value *big.Int) ([]Royalty, error) {
+	eng, err := royaltytestabi.NewRoyaltyEngineV1(addresses.Engine, sim)
+	if err != nil {
+		return nil, fmt.Errorf("royaltytestabi.NewRoyaltyEngineV1(%v): %v", addresses.Engine, err)
+	}
+
+	got, err := eng.GetRoyaltyView(nil, token, tokenID, value)
+	if err != nil {
+		return nil, fmt.Errorf("%T.GetRoyaltyView(%v, %d, %d): %v", eng, token, tokenID, value, err)
+	}
+	if n, m := len(got.Recipients), len(got.Amounts); n != m {
+		return nil, fmt.Errorf("%T.GetRoyaltyView(%v, %d, %d) returned %d recipients and %d amounts", eng, token, tokenID, value, n, m)
+	}
+
+	var rs []Royalty
+	for i, r := range got.Recipients {
+		rs = append(rs, Royalty{
+			Recipient: r,
+			Amount:    got.Amounts[i],
+		})
+	}
+	return rs, nil
+}
+
+// LookupTB calls Lookup() and reports any errors with tb.Fatal.
+func LookupTB(tb testing.TB, sim *ethtest.SimulatedBackend, token common.Address, tokenID, value *big.Int) []Royalty {
+	tb.Helper()
+
+	rs, err := Lookup(sim, token, tokenID, value)
+	if err != nil {
+		tb.Fatalf("royaltytest.Lookup(%v, %d, %d) error %v", token, tokenID, value, err)
+	}
+	return rs
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@manifoldxyz/royalty-registry-solidity/contracts/RoyaltyEngineV1.sol";
+import "@manifoldxyz/royalty-registry-solidity/contracts/RoyaltyRegistry.sol";
+
+/**
+@notice Deploys the Manifold Royalty Registry and Royalty Engine for use with
+ethier's royaltytest Go package.
+@dev The canonical deployments are behind upgradeable proxies, but the
+implementations are used directly as upgrades aren't needed in tests.
+ */
+contract SimulatedRoyaltyRegistry {
+    RoyaltyRegistry public immutable registry;
+    RoyaltyEngineV1 public immutable engine;
+
+    constructor() {
+        RoyaltyRegistry reg = new RoyaltyRegistry();
+        reg.initialize();
+        reg.transferOwnership(msg.sender);
+        registry = reg;
+
+        RoyaltyEngineV1 eng = new RoyaltyEngineV1();
+        eng.initialize(address(reg));
+        eng.transferOwnership(msg.sender);
+        engine = eng;
+    }
+}
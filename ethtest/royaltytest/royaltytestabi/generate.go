@@ -0,0 +1,6 @@
+// Package royaltytestabi is a generated package providing the Manifold Royalty
+// Registry and Royalty Engine. There is likely no need to use this package
+// directly as its functionality is exposed via the royaltytest package.
+package royaltytestabi
+
+//go:generate ethier gen SimulatedRoyaltyRegistry.sol
@@ -0,0 +1,236 @@
+// Package safetest provides a deployment of the Gnosis Safe multisig wallet,
+// allowing ethier contracts owned by a Safe to be tested with realistic
+// multisig execution.
+//
+// As with all other test doubles, the Safe singleton and proxy factory are
+// deployed to deterministic addresses, not canonical ones.
+package safetest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/safetest/safetestabi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Contracts carries addresses for Safe contracts.
+type Contracts struct {
+	Singleton, ProxyFactory common.Address
+}
+
+// Addresses returns the addresses to which Deploy deploys Safe contracts.
+func Addresses() Contracts {
+	return addresses
+}
+
+var addresses = Contracts{
+	Singleton:    common.HexToAddress("0x5a5b40254375b936e9dB0cF3B87F633ECebb21e3"),
+	ProxyFactory: common.HexToAddress("0x86C68876997d006B0eb8f72E9884050B8Ec074A2"),
+}
+
+// Deploy deploys the Safe singleton and proxy factory to the SimulatedBackend.
+// Individual Safes are then deployed with NewSafe().
+//
+// This function MUST only be called once for each SimulatedBackend; all future
+// calls will deploy to different addresses to those returned by Addresses().
+func Deploy(sim *ethtest.SimulatedBackend) error {
+	return sim.AsMockedEntity(ethtest.Safe, func(opts *bind.TransactOpts) error {
+		_, _, s, err := safetestabi.DeploySimulatedSafe(opts, sim)
+		if err != nil {
+			return fmt.Errorf("safetestabi.DeploySimulatedSafe() error %v", err)
+		}
+
+		singleton, err := s.Singleton(nil)
+		if err != nil {
+			return fmt.Errorf("%T.Singleton(): %v", s, err)
+		}
+		factory, err := s.ProxyFactory(nil)
+		if err != nil {
+			return fmt.Errorf("%T.ProxyFactory(): %v", s, err)
+		}
+
+		deployed := Contracts{
+			Singleton:    singleton,
+			ProxyFactory: factory,
+		}
+		if want := addresses; !cmp.Equal(deployed, want) {
+			return fmt.Errorf("unexpected deployment addresses %+v; expecting %+v", deployed, want)
+		}
+		return nil
+	})
+}
+
+// DeployTB calls Deploy() and reports any errors with tb.Fatal.
+func DeployTB(tb testing.TB, sim *ethtest.SimulatedBackend) {
+	tb.Helper()
+
+	if err := Deploy(sim); err != nil {
+		tb.Fatalf("safetest.Deploy() error %v", err)
+	}
+}
+
+// A Safe is a deployed Gnosis Safe proxy.
+type Safe struct {
+	*safetestabi.GnosisSafe
+	Address common.Address
+
+	sim *ethtest.SimulatedBackend
+}
+
+// NewSafe deploys a Safe, via the proxy factory, with the owners and
+// confirmation threshold. The account pays for deployment but has no special
+// rights over the Safe.
+func NewSafe(sim *ethtest.SimulatedBackend, account int, owners []common.Address, threshold int64) (*Safe, error) {
+	singleton, err := safetestabi.GnosisSafeMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("%T.GetAbi(): %v", safetestabi.GnosisSafeMetaData, err)
+	}
+	var zero common.Address
+	setup, err := singleton.Pack("setup", owners, big.NewInt(threshold), zero, []byte{}, zero, zero, big.NewInt(0), zero)
+	if err != nil {
+		return nil, fmt.Errorf("%T.Pack(\"setup\", %v, %d, …): %v", singleton, owners, threshold, err)
+	}
+
+	factory, err := safetestabi.NewGnosisSafeProxyFactory(addresses.ProxyFactory, sim)
+	if err != nil {
+		return nil, fmt.Errorf("safetestabi.NewGnosisSafeProxyFactory(%v): %v", addresses.ProxyFactory, err)
+	}
+	tx, err := factory.CreateProxy(sim.Acc(account), addresses.Singleton, setup)
+	if err != nil {
+		return nil, fmt.Errorf("%T.CreateProxy(): %v", factory, err)
+	}
+
+	rcpt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("%T.TransactionReceipt(%v): %v", sim, tx.Hash(), err)
+	}
+	for _, l := range rcpt.Logs {
+		if l.Address != addresses.ProxyFactory {
+			continue
+		}
+		ev, err := factory.ParseProxyCreation(*l)
+		if err != nil {
+			continue
+		}
+		safe, err := safetestabi.NewGnosisSafe(ev.Proxy, sim)
+		if err != nil {
+			return nil, fmt.Errorf("safetestabi.NewGnosisSafe(%v): %v", ev.Proxy, err)
+		}
+		return &Safe{
+			GnosisSafe: safe,
+			Address:    ev.Proxy,
+			sim:        sim,
+		}, nil
+	}
+	return nil, fmt.Errorf("no ProxyCreation event in %T.CreateProxy() transaction", factory)
+}
+
+// NewSafeTB calls NewSafe() and reports any errors with tb.Fatal.
+func NewSafeTB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, owners []common.Address, threshold int64) *Safe {
+	tb.Helper()
+
+	s, err := NewSafe(sim, account, owners, threshold)
+	if err != nil {
+		tb.Fatalf("safetest.NewSafe(%v, %d) error %v", owners, threshold, err)
+	}
+	return s
+}
+
+// Operation is the type of call made by a Safe Transaction.
+type Operation uint8
+
+// Operations supported by the Safe.
+const (
+	Call Operation = iota
+	DelegateCall
+)
+
+// A Transaction is a call to be executed by a Safe. Gas refunds aren't
+// supported.
+type Transaction struct {
+	To        common.Address
+	Value     *big.Int
+	Data      []byte
+	Operation Operation
+}
+
+// Hash returns the EIP-712 hash of the Transaction, as computed by the Safe,
+// for the Safe's current nonce.
+func (s *Safe) Hash(tx Transaction) (common.Hash, error) {
+	nonce, err := s.Nonce(nil)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%T.Nonce(): %v", s, err)
+	}
+
+	value := tx.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	var zero common.Address
+	h, err := s.GetTransactionHash(nil, tx.To, value, tx.Data, uint8(tx.Operation), big.NewInt(0), big.NewInt(0), big.NewInt(0), zero, zero, nonce)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%T.GetTransactionHash(): %v", s, err)
+	}
+	return h, nil
+}
+
+// Sign returns the concatenated signatures of the Transaction's Hash() by all
+// of the signers, ordered by ascending signer address as required by the Safe.
+func (s *Safe) Sign(tx Transaction, signers ...eth.SignerBackend) ([]byte, error) {
+	hash, err := s.Hash(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	signers = append([]eth.SignerBackend{}, signers...)
+	sort.Slice(signers, func(i, j int) bool {
+		a, b := signers[i].Address(), signers[j].Address()
+		return bytes.Compare(a.Bytes(), b.Bytes()) < 0
+	})
+
+	var sigs []byte
+	for _, sgn := range signers {
+		sig, err := sgn.SignRawDigest(hash.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%T.SignRawDigest(<Safe transaction hash>): %v", sgn, err)
+		}
+		if len(sig) != 65 {
+			return nil, fmt.Errorf("%T.SignRawDigest() returned %d-byte signature; Safe requires 65 bytes", sgn, len(sig))
+		}
+		sigs = append(sigs, sig...)
+	}
+	return sigs, nil
+}
+
+// ExecTransaction signs the Transaction with the signers, and executes it with
+// the Safe, sending the transaction from the account.
+func (s *Safe) ExecTransaction(account int, tx Transaction, signers ...eth.SignerBackend) (*types.Transaction, error) {
+	sigs, err := s.Sign(tx, signers...)
+	if err != nil {
+		return nil, err
+	}
+
+	value := tx.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	var zero common.Address
+	return s.GnosisSafe.ExecTransaction(s.sim.Acc(account), tx.To, value, tx.Data, uint8(tx.Operation), big.NewInt(0), big.NewInt(0), big.NewInt(0), zero, zero, sigs)
+}
+
+// ExecTransactionTB calls s.ExecTransaction() and reports any errors with
+// tb.Fatal.
+func (s *Safe) ExecTransactionTB(tb testing.TB, account int, tx Transaction, signers ...eth.SignerBackend) *types.Transaction {
+	tb.Helper()
+	return s.sim.Must(tb, "%T.ExecTransaction(%+v)", s, tx)(s.ExecTransaction(account, tx, signers...))
+}
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@gnosis.pm/safe-contracts/contracts/GnosisSafe.sol";
+import "@gnosis.pm/safe-contracts/contracts/proxies/GnosisSafeProxyFactory.sol";
+
+/**
+@notice Deploys the Gnosis Safe singleton and its proxy factory for use with
+ethier's safetest Go package.
+ */
+contract SimulatedSafe {
+    GnosisSafe public immutable singleton;
+    GnosisSafeProxyFactory public immutable proxyFactory;
+
+    constructor() {
+        singleton = new GnosisSafe();
+        proxyFactory = new GnosisSafeProxyFactory();
+    }
+}
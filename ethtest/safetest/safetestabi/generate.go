@@ -0,0 +1,7 @@
+// Package safetestabi is a generated package providing the Gnosis Safe and its
+// proxy factory. There is likely no need to use this package directly as its
+// functionality is exposed via the safetest package.
+package safetestabi
+
+// The Safe singleton exceeds the contract-size limit unless optimised.
+//go:generate ethier gen --optimize SimulatedSafe.sol
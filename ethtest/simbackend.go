@@ -33,6 +33,11 @@ type SimulatedBackend struct {
 	mockAccounts map[MockedEntity]*bind.TransactOpts
 
 	coverageReport func() []byte
+
+	// pendingGas are transactions sent since the last Commit(), for which gas
+	// is recorded iff GasReportDirEnvVar is set.
+	pendingGas []*types.Transaction
+	gasErr     error
 }
 
 var _ bind.ContractBackend = (*SimulatedBackend)(nil)
@@ -44,8 +49,25 @@ var _ bind.ContractBackend = (*SimulatedBackend)(nil)
 // Accounts are deterministically generated so have identical addresses between
 // backends, but balances are coupled to the specific instance of the backend.
 func NewSimulatedBackend(numAccounts int) (*SimulatedBackend, error) {
+	var accounts []*bind.TransactOpts
+	for i := 0; i < numAccounts; i++ {
+		txOpts, err := deterministicAccount([]byte(fmt.Sprintf("account:%d", i)))
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, txOpts)
+	}
+	return NewSimulatedBackendWithAccounts(accounts)
+}
+
+// NewSimulatedBackendWithAccounts is equivalent to NewSimulatedBackend() except
+// that the accounts are those provided instead of being deterministically
+// generated; e.g. to use keys derived from a mnemonic known to a wallet. Each
+// account's Signer MUST sign for chain ID 1337.
+func NewSimulatedBackendWithAccounts(accounts []*bind.TransactOpts) (*SimulatedBackend, error) {
 	sb := &SimulatedBackend{
 		AutoCommit:   true,
+		accounts:     accounts,
 		mockAccounts: make(map[MockedEntity]*bind.TransactOpts),
 	}
 	alloc := make(core.GenesisAlloc)
@@ -58,39 +80,31 @@ func NewSimulatedBackend(numAccounts int) (*SimulatedBackend, error) {
 		}
 	}
 
-	createAccount := func(seed []byte) (*bind.TransactOpts, error) {
-		entropy := bytes.NewReader(crypto.Keccak512(seed))
-		key, err := ecdsa.GenerateKey(crypto.S256(), entropy)
-		if err != nil {
-			return nil, fmt.Errorf("ecdsa.GenerateKey(crypto.S256, [deterministic entropy; Keccak512(%q)]): %v", seed, err)
-		}
-
-		txOpts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
-		if err != nil {
-			return nil, fmt.Errorf("NewKeyedTransactorWithChainID(<new key>, sim-backend-id=1337): %v", err)
-		}
+	for _, txOpts := range accounts {
 		alloc[txOpts.From] = core.GenesisAccount{
 			Balance: eth.Ether(100),
 		}
-		return txOpts, nil
 	}
 
-	for i := 0; i < numAccounts; i++ {
-		txOpts, err := createAccount([]byte(fmt.Sprintf("account:%d", i)))
-		if err != nil {
-			return nil, err
-		}
-		sb.accounts = append(sb.accounts, txOpts)
+	contracts, err := genesisContracts()
+	if err != nil {
+		return nil, err
+	}
+	for addr, acc := range contracts {
+		alloc[addr] = acc
 	}
 
 	// These accounts need to be deterministic so that any contracts they deploy
 	// have deterministic addresses.
-	for _, mock := range []MockedEntity{OpenSea, Chainlink, Ethier, WETH} {
-		txOpts, err := createAccount([]byte(mock))
+	for _, mock := range []MockedEntity{OpenSea, Chainlink, Ethier, WETH, Seaport, Manifold, ChainlinkVRFV2, AccountAbstraction, DelegateCash, ENS, Safe, TrustedForwarder, TokenBoundAccounts, FiatMinting} {
+		txOpts, err := deterministicAccount([]byte(mock))
 		if err != nil {
 			return nil, err
 		}
 		sb.mockAccounts[mock] = txOpts
+		alloc[txOpts.From] = core.GenesisAccount{
+			Balance: eth.Ether(100),
+		}
 	}
 
 	sb.SimulatedBackend = backends.NewSimulatedBackend(alloc, 3e7)
@@ -107,6 +121,22 @@ func NewSimulatedBackend(numAccounts int) (*SimulatedBackend, error) {
 	return sb, nil
 }
 
+// deterministicAccount returns an account with a private key derived from the
+// seed.
+func deterministicAccount(seed []byte) (*bind.TransactOpts, error) {
+	entropy := bytes.NewReader(crypto.Keccak512(seed))
+	key, err := ecdsa.GenerateKey(crypto.S256(), entropy)
+	if err != nil {
+		return nil, fmt.Errorf("ecdsa.GenerateKey(crypto.S256, [deterministic entropy; Keccak512(%q)]): %v", seed, err)
+	}
+
+	txOpts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	if err != nil {
+		return nil, fmt.Errorf("NewKeyedTransactorWithChainID(<new key>, sim-backend-id=1337): %v", err)
+	}
+	return txOpts, nil
+}
+
 // NewSimulatedBackendTB calls NewSimulatedBackend(), reports any errors with
 // tb.Fatal, and calls Close() with tb.Cleanup().
 func NewSimulatedBackendTB(tb testing.TB, numAccounts int) *SimulatedBackend {
@@ -126,13 +156,17 @@ func NewSimulatedBackendTB(tb testing.TB, numAccounts int) *SimulatedBackend {
 }
 
 // SendTransaction functions pipes its parameters to the embedded backend and
-// also calls Commit() if sb.AutoCommit==true.
+// also calls Commit() if sb.AutoCommit==true. See GasReportDirEnvVar re gas
+// reporting.
 func (sb *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
 	if err := sb.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
 		return err
 	}
+	if processGasRecorder.enabled() {
+		sb.pendingGas = append(sb.pendingGas, tx)
+	}
 	if sb.AutoCommit {
-		sb.SimulatedBackend.Commit()
+		sb.Commit()
 	}
 	return nil
 }
@@ -176,6 +210,18 @@ const (
 	Chainlink = MockedEntity("Chainlink")
 	Ethier    = MockedEntity("Ethier")
 	WETH      = MockedEntity("wETH")
+	Seaport   = MockedEntity("Seaport")
+	Manifold  = MockedEntity("Manifold")
+	// ChainlinkVRFV2 is distinct from Chainlink so that its deployments don't
+	// affect the addresses of the latter's.
+	ChainlinkVRFV2     = MockedEntity("ChainlinkVRFV2")
+	AccountAbstraction = MockedEntity("AccountAbstraction")
+	DelegateCash       = MockedEntity("DelegateCash")
+	ENS                = MockedEntity("ENS")
+	Safe               = MockedEntity("Safe")
+	TrustedForwarder   = MockedEntity("TrustedForwarder")
+	TokenBoundAccounts = MockedEntity("TokenBoundAccounts")
+	FiatMinting        = MockedEntity("FiatMinting")
 )
 
 // AsMockedEntity calls the provided function with the mocked entity's account
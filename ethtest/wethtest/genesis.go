@@ -0,0 +1,94 @@
+package wethtest
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/wethtest/wethtestabi"
+)
+
+// MainnetAddress is the address of WETH9 on Ethereum mainnet, at which it is
+// included in the genesis block of every ethtest.SimulatedBackend if
+// RegisterGenesis() is called.
+var MainnetAddress = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+
+var registerGenesis sync.Once
+
+// RegisterGenesis includes WETH9, at MainnetAddress, in the genesis block of
+// every SimulatedBackend. It MUST be called before the first SimulatedBackend
+// is constructed, typically in TestMain(), as it is a thin wrapper around
+// ethtest.RegisterGenesisContract(), but is safe to call multiple times.
+func RegisterGenesis() {
+	registerGenesis.Do(func() {
+		ethtest.RegisterGenesisContract(MainnetAddress, common.FromHex(wethtestabi.WETH9Bin))
+	})
+}
+
+// GenesisWETH returns a binding of WETH9 at its mainnet address,
+// MainnetAddress.
+//
+// Code can't be set on a SimulatedBackend after it is constructed so, unlike
+// DeployWETH(), WETH9 is instead included in the genesis block of all backends
+// by RegisterGenesis() and GenesisWETH() only confirms that it is present.
+// Note that, on the SimulatedBackend's chain, the ethier WETH library resolves
+// to the DeployWETH() deployment and not to this one.
+func GenesisWETH(sim *ethtest.SimulatedBackend) (*wethtestabi.IwETH, error) {
+	code, err := sim.CodeAt(context.Background(), MainnetAddress, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%T.CodeAt(%v): %v", sim, MainnetAddress, err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no code at WETH9 address %v; was wethtest.RegisterGenesis() called before constructing the SimulatedBackend?", MainnetAddress)
+	}
+	return wethtestabi.NewIwETH(MainnetAddress, sim)
+}
+
+// GenesisWETHTB calls GenesisWETH() and reports any errors with tb.Fatal.
+func GenesisWETHTB(tb testing.TB, sim *ethtest.SimulatedBackend) *wethtestabi.IwETH {
+	tb.Helper()
+
+	weth, err := GenesisWETH(sim)
+	if err != nil {
+		tb.Fatalf("wethtest.GenesisWETH() error %v", err)
+	}
+	return weth
+}
+
+// WrapETH deposits the amount of ETH from the account into WETH9 at
+// MainnetAddress.
+func WrapETH(sim *ethtest.SimulatedBackend, account int, amount *big.Int) (*types.Transaction, error) {
+	weth, err := wethtestabi.NewIwETH(MainnetAddress, sim)
+	if err != nil {
+		return nil, fmt.Errorf("wethtestabi.NewIwETH(%v): %v", MainnetAddress, err)
+	}
+	return weth.Deposit(sim.WithValueFrom(account, amount))
+}
+
+// WrapETHTB calls WrapETH() and reports any errors with tb.Fatal.
+func WrapETHTB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, amount *big.Int) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "WrapETH(account %d, %d)", account, amount)(WrapETH(sim, account, amount))
+}
+
+// UnwrapETH withdraws the amount of ETH to the account from WETH9 at
+// MainnetAddress.
+func UnwrapETH(sim *ethtest.SimulatedBackend, account int, amount *big.Int) (*types.Transaction, error) {
+	weth, err := wethtestabi.NewIwETH(MainnetAddress, sim)
+	if err != nil {
+		return nil, fmt.Errorf("wethtestabi.NewIwETH(%v): %v", MainnetAddress, err)
+	}
+	return weth.Withdraw(sim.Acc(account), amount)
+}
+
+// UnwrapETHTB calls UnwrapETH() and reports any errors with tb.Fatal.
+func UnwrapETHTB(tb testing.TB, sim *ethtest.SimulatedBackend, account int, amount *big.Int) *types.Transaction {
+	tb.Helper()
+	return sim.Must(tb, "UnwrapETH(account %d, %d)", account, amount)(UnwrapETH(sim, account, amount))
+}
@@ -0,0 +1,43 @@
+package wethtest
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+)
+
+func TestMain(m *testing.M) {
+	RegisterGenesis()
+	os.Exit(m.Run())
+}
+
+func TestGenesisWETH(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, 1)
+	weth := GenesisWETHTB(t, sim)
+
+	wantBalance := func(t *testing.T, want *big.Int) {
+		t.Helper()
+		if got, err := weth.BalanceOf(nil, sim.Addr(0)); err != nil || got.Cmp(want) != 0 {
+			t.Errorf("%T.BalanceOf(account 0) got %d, err %v; want %d, nil err", weth, got, err, want)
+		}
+	}
+
+	wantBalance(t, big.NewInt(0))
+	WrapETHTB(t, sim, 0, eth.Ether(3))
+	wantBalance(t, eth.Ether(3))
+	UnwrapETHTB(t, sim, 0, eth.Ether(1))
+	wantBalance(t, eth.Ether(2))
+
+	if got, err := sim.BalanceAt(ctx, MainnetAddress, nil); err != nil || got.Cmp(eth.Ether(2)) != 0 {
+		t.Errorf("BalanceAt(%v) got %d, err %v; want %d, nil err", MainnetAddress, got, err, eth.Ether(2))
+	}
+
+	if _, err := UnwrapETH(sim, 0, eth.Ether(3)); err == nil {
+		t.Errorf("UnwrapETH(<more than balance>) got nil error; want error")
+	}
+}
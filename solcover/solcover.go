@@ -274,6 +274,16 @@ func SourceByName(contractName string, pc uint64) (*Location, bool) {
 	return contractsByName[contractName].location(pc)
 }
 
+// ContractName returns the fully qualified name of the contract deployed at
+// the address, and true, iff it was matched by RegisterDeployedContract().
+func ContractName(addr common.Address) (string, bool) {
+	cc, ok := deployedContracts[addr]
+	if !ok {
+		return "", false
+	}
+	return cc.name, true
+}
+
 var (
 	// libraryPlaceHolder finds all places in which bind.Bind has inserted a
 	// string identifying a library address to be pushed (PUSH20 == 0x73). In
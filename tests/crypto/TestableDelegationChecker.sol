@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../contracts/crypto/SignerManager.sol";
+import "../../contracts/crypto/DelegationChecker.sol";
+import "@openzeppelin/contracts/utils/structs/EnumerableSet.sol";
+
+/**
+@notice Exposes functions allowing testing of DelegationChecker.
+ */
+contract TestableDelegationChecker is SignerManager {
+    using EnumerableSet for EnumerableSet.AddressSet;
+    using DelegationChecker for EnumerableSet.AddressSet;
+
+    /// @notice Scope required of all delegations.
+    bytes32 public constant SCOPE = keccak256("mint");
+
+    mapping(bytes32 => bool) private usedMessages;
+
+    /// @notice Number of times each address has been minted to.
+    mapping(address => uint256) public minted;
+
+    /// @dev Reverts if the session key's signature of the recipient is invalid.
+    function mint(
+        address recipient,
+        DelegationChecker.Delegation memory delegation,
+        bytes calldata authorisation,
+        bytes calldata signature
+    ) external {
+        signers.requireValidDelegatedSignature(
+            delegation,
+            authorisation,
+            SCOPE,
+            abi.encodePacked(recipient),
+            signature,
+            usedMessages
+        );
+        minted[recipient]++;
+    }
+}
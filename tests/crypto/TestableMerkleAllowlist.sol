@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../contracts/crypto/MerkleAllowlist.sol";
+
+/// @notice Exposes MerkleAllowlist._claimAllowlisted() for testing.
+contract TestableMerkleAllowlist is MerkleAllowlist {
+    /// @notice Number of items "minted" to each address.
+    mapping(address => uint256) public minted;
+
+    function claim(
+        uint256 n,
+        uint256 allowance,
+        bytes32[] calldata proof
+    ) external {
+        _claimAllowlisted(msg.sender, n, allowance, proof);
+        minted[msg.sender] += n;
+    }
+}
@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/utils/cryptography/MerkleProof.sol";
+
+/**
+@notice Exposes OpenZeppelin's MerkleProof to confirm compatibility of the Go
+eth/merkle package.
+ */
+contract TestableMerkleProof {
+    function verify(
+        bytes32[] calldata proof,
+        bytes32 root,
+        address addr
+    ) external pure returns (bool) {
+        return
+            MerkleProof.verify(
+                proof,
+                root,
+                keccak256(abi.encodePacked(addr))
+            );
+    }
+}
@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../contracts/crypto/SignatureGate.sol";
+
+/// @notice Exposes SignatureGate._claimWithVoucher() for testing.
+contract TestableSignatureGate is SignatureGate {
+    constructor(string memory name, string memory version)
+        SignatureGate(name, version)
+    {} // solhint-disable-line no-empty-blocks
+
+    /// @notice Number of items "minted" to each recipient.
+    mapping(address => uint256) public minted;
+
+    function claim(
+        SignatureGate.Voucher memory voucher,
+        uint256 n,
+        bytes calldata signature
+    ) external {
+        _claimWithVoucher(voucher, n, signature);
+        minted[voucher.recipient] += n;
+    }
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../contracts/crypto/SignerManager.sol";
+import "../../contracts/crypto/VoucherChecker.sol";
+import "@openzeppelin/contracts/utils/structs/EnumerableSet.sol";
+
+/**
+@notice Exposes functions allowing testing of VoucherChecker.
+ */
+contract TestableVoucherChecker is SignerManager {
+    using EnumerableSet for EnumerableSet.AddressSet;
+    using VoucherChecker for EnumerableSet.AddressSet;
+
+    mapping(bytes32 => bool) private usedMessages;
+
+    /// @notice Total value redeemed by each recipient.
+    mapping(address => uint256) public redeemed;
+
+    /// @dev Reverts if the voucher is invalid, expired, or already used.
+    function redeem(
+        VoucherChecker.Voucher memory voucher,
+        bytes calldata signature
+    ) external {
+        signers.requireValidVoucher(voucher, signature, usedMessages);
+        redeemed[voucher.recipient] += voucher.value;
+    }
+}
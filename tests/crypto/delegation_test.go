@@ -0,0 +1,110 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/h-fam/errdiff"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+)
+
+func TestDelegationChecker(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+
+	_, _, checker, err := DeployTestableDelegationChecker(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableDelegationChecker() error %v", err)
+	}
+	for _, a := range goodSignerAddrs {
+		sim.Must(t, "AddSigner()")(checker.AddSigner(sim.Acc(deployer), a))
+	}
+
+	// The simulated backend's clock is unrelated to the wall clock so expiry
+	// is relative to the latest block.
+	now := time.Unix(int64(sim.Blockchain().CurrentBlock().Time()), 0)
+	mint := eth.DelegationScope("mint")
+
+	newDelegated := func(t *testing.T, primary *eth.Signer, scope [32]byte, expiry time.Time) *eth.DelegatedSigner {
+		t.Helper()
+		d, err := eth.NewDelegatedSigner(primary, scope, expiry)
+		if err != nil {
+			t.Fatalf("eth.NewDelegatedSigner() error %v", err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name           string
+		delegated      *eth.DelegatedSigner
+		signer         *eth.Signer // if nil, delegated.Session
+		errDiffAgainst interface{}
+	}{
+		{
+			name:      "valid",
+			delegated: newDelegated(t, goodSigners[0], mint, now.Add(time.Hour)),
+		},
+		{
+			name:      "valid from different primary",
+			delegated: newDelegated(t, goodSigners[1], mint, now.Add(time.Hour)),
+		},
+		{
+			name:           "expired",
+			delegated:      newDelegated(t, goodSigners[0], mint, now.Add(-time.Hour)),
+			errDiffAgainst: string(revert.DelegationExpired),
+		},
+		{
+			name:           "wrong scope",
+			delegated:      newDelegated(t, goodSigners[0], eth.DelegationScope("burn"), now.Add(time.Hour)),
+			errDiffAgainst: string(revert.DelegationScope),
+		},
+		{
+			name:           "bad primary",
+			delegated:      newDelegated(t, badSigner, mint, now.Add(time.Hour)),
+			errDiffAgainst: string(revert.InvalidSignature),
+		},
+		{
+			name:           "not signed by session key",
+			delegated:      newDelegated(t, goodSigners[0], mint, now.Add(time.Hour)),
+			signer:         goodSigners[0],
+			errDiffAgainst: string(revert.SessionSignature),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := tt.signer
+			if signer == nil {
+				signer = tt.delegated.Session
+			}
+			recipient := sim.Addr(arbitrary)
+			sig, err := signer.PersonalSignAddress(recipient)
+			if err != nil {
+				t.Fatalf("%T.PersonalSignAddress() error %v", signer, err)
+			}
+
+			d := tt.delegated.Delegation
+			delegation := DelegationCheckerDelegation{
+				Session: d.Session,
+				Scope:   d.Scope,
+				Expiry:  new(big.Int).SetUint64(d.Expiry),
+			}
+
+			_, err = checker.Mint(sim.Acc(arbitrary), recipient, delegation, tt.delegated.Authorisation, sig)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Fatalf("Mint() on first call; %s", diff)
+			}
+			if tt.errDiffAgainst != nil {
+				return
+			}
+
+			_, err = checker.Mint(sim.Acc(arbitrary), recipient, delegation, tt.delegated.Authorisation, sig)
+			if diff := errdiff.Check(err, "SignatureChecker: Message already used"); diff != "" {
+				t.Errorf("Mint() on second call with same signature; %s", diff)
+			}
+		})
+	}
+}
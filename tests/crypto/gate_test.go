@@ -0,0 +1,215 @@
+package crypto
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/h-fam/errdiff"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+)
+
+func TestSignatureGate(t *testing.T) {
+	const (
+		name    = "TestableSignatureGate"
+		version = "1"
+	)
+
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	addr, _, gate, err := DeployTestableSignatureGate(sim.Acc(deployer), sim, name, version)
+	if err != nil {
+		t.Fatalf("DeployTestableSignatureGate() error %v", err)
+	}
+	for _, a := range goodSignerAddrs {
+		sim.Must(t, "AddSigner()")(gate.AddSigner(sim.Acc(deployer), a))
+	}
+
+	domain := eth.VoucherDomain{
+		Name:    name,
+		Version: version,
+		ChainID: sim.Blockchain().Config().ChainID,
+		Address: addr,
+	}
+	// The simulated backend's clock is unrelated to the wall clock so expiry
+	// is relative to the latest block.
+	now := time.Unix(int64(sim.Blockchain().CurrentBlock().Time()), 0)
+
+	asSolidity := func(v eth.Voucher) SignatureGateVoucher {
+		return SignatureGateVoucher{
+			Recipient: v.Recipient,
+			Allowance: v.Value,
+			Nonce:     v.Nonce,
+			Expiry:    new(big.Int).SetUint64(v.Expiry),
+		}
+	}
+
+	t.Run("digest", func(t *testing.T) {
+		v, err := eth.NewVoucher(sim.Addr(arbitrary), big.NewInt(1), now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("eth.NewVoucher() error %v", err)
+		}
+		want, err := eth.TypedDataDigest(eth.VoucherTypedData(domain, v))
+		if err != nil {
+			t.Fatalf("eth.TypedDataDigest(eth.VoucherTypedData(…)) error %v", err)
+		}
+		got, err := gate.VoucherDigest(nil, asSolidity(v))
+		if err != nil || !bytes.Equal(got[:], want) {
+			t.Errorf("VoucherDigest(%+v) got %#x, err %v; want %#x, nil err", v, got, err, want)
+		}
+	})
+
+	type claim struct {
+		n              int64
+		errDiffAgainst interface{}
+	}
+
+	tests := []struct {
+		name      string
+		signer    *eth.Signer
+		domain    func(eth.VoucherDomain) eth.VoucherDomain
+		allowance int64
+		expiry    time.Time
+		modify    func(*SignatureGateVoucher)
+		claims    []claim
+		wantTotal int64
+	}{
+		{
+			name:      "single claim of full allowance",
+			signer:    goodSigners[0],
+			allowance: 3,
+			expiry:    now.Add(time.Hour),
+			claims: []claim{
+				{n: 3},
+				{n: 1, errDiffAgainst: string(revert.GateAllowance)},
+			},
+			wantTotal: 3,
+		},
+		{
+			name:      "partial claims",
+			signer:    goodSigners[1],
+			allowance: 5,
+			expiry:    now.Add(time.Hour),
+			claims: []claim{
+				{n: 2},
+				{n: 4, errDiffAgainst: string(revert.GateAllowance)},
+				{n: 3},
+				{n: 1, errDiffAgainst: string(revert.GateAllowance)},
+			},
+			wantTotal: 5,
+		},
+		{
+			name:      "expired",
+			signer:    goodSigners[0],
+			allowance: 1,
+			expiry:    now.Add(-time.Hour),
+			claims:    []claim{{n: 1, errDiffAgainst: string(revert.GateExpired)}},
+		},
+		{
+			name:      "bad signer",
+			signer:    badSigner,
+			allowance: 1,
+			expiry:    now.Add(time.Hour),
+			claims:    []claim{{n: 1, errDiffAgainst: string(revert.GateSignature)}},
+		},
+		{
+			name:      "inflated allowance",
+			signer:    goodSigners[0],
+			allowance: 1,
+			expiry:    now.Add(time.Hour),
+			modify: func(v *SignatureGateVoucher) {
+				v.Allowance = big.NewInt(100)
+			},
+			claims: []claim{{n: 1, errDiffAgainst: string(revert.GateSignature)}},
+		},
+		{
+			name:      "modified recipient",
+			signer:    goodSigners[0],
+			allowance: 1,
+			expiry:    now.Add(time.Hour),
+			modify: func(v *SignatureGateVoucher) {
+				v.Recipient = sim.Addr(vandal)
+			},
+			claims: []claim{{n: 1, errDiffAgainst: string(revert.GateSignature)}},
+		},
+		{
+			name:   "signed for different contract",
+			signer: goodSigners[0],
+			domain: func(d eth.VoucherDomain) eth.VoucherDomain {
+				d.Address = common.HexToAddress("0x01")
+				return d
+			},
+			allowance: 1,
+			expiry:    now.Add(time.Hour),
+			claims:    []claim{{n: 1, errDiffAgainst: string(revert.GateSignature)}},
+		},
+		{
+			name:   "signed for different chain",
+			signer: goodSigners[0],
+			domain: func(d eth.VoucherDomain) eth.VoucherDomain {
+				d.ChainID = big.NewInt(1)
+				return d
+			},
+			allowance: 1,
+			expiry:    now.Add(time.Hour),
+			claims:    []claim{{n: 1, errDiffAgainst: string(revert.GateSignature)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Each test uses a fresh recipient so totals are independent.
+			recipient := common.BytesToAddress([]byte(tt.name))
+
+			v, err := eth.NewVoucher(recipient, big.NewInt(tt.allowance), tt.expiry)
+			if err != nil {
+				t.Fatalf("eth.NewVoucher() error %v", err)
+			}
+			d := domain
+			if tt.domain != nil {
+				d = tt.domain(d)
+			}
+			sig, err := tt.signer.SignTypedVoucher(d, v)
+			if err != nil {
+				t.Fatalf("%T.SignTypedVoucher(%+v) error %v", tt.signer, v, err)
+			}
+
+			sv := asSolidity(v)
+			if tt.modify != nil {
+				tt.modify(&sv)
+			}
+
+			for i, c := range tt.claims {
+				_, err := gate.Claim(sim.Acc(arbitrary), sv, big.NewInt(c.n), sig)
+				if diff := errdiff.Check(err, c.errDiffAgainst); diff != "" {
+					t.Errorf("Claim(…, %d, …) [claim %d]; %s", c.n, i, diff)
+				}
+			}
+
+			got, err := gate.Minted(nil, recipient)
+			if err != nil || got.Int64() != tt.wantTotal {
+				t.Errorf("Minted(<recipient>) got %d, err %v; want %d, nil err", got, err, tt.wantTotal)
+			}
+		})
+	}
+
+	t.Run("removed signer", func(t *testing.T) {
+		v, err := eth.NewVoucher(sim.Addr(arbitrary), big.NewInt(1), now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("eth.NewVoucher() error %v", err)
+		}
+		sig, err := goodSigners[1].SignTypedVoucher(domain, v)
+		if err != nil {
+			t.Fatalf("SignTypedVoucher() error %v", err)
+		}
+
+		sim.Must(t, "RemoveSigner()")(gate.RemoveSigner(sim.Acc(deployer), goodSignerAddrs[1]))
+		if diff := revert.GateSignature.Diff(gate.Claim(sim.Acc(arbitrary), asSolidity(v), big.NewInt(1), sig)); diff != "" {
+			t.Errorf("Claim() with voucher from removed signer; %s", diff)
+		}
+	})
+}
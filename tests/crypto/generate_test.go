@@ -1,3 +1,3 @@
 package crypto
 
-//go:generate ethier gen TestableSignatureChecker.sol
+//go:generate ethier gen TestableSignatureChecker.sol TestableVoucherChecker.sol TestableMerkleProof.sol TestableDelegationChecker.sol TestableMerkleAllowlist.sol TestableSignatureGate.sol
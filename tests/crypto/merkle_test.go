@@ -0,0 +1,182 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/divergencetech/ethier/eth/merkle"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+)
+
+func TestMerkleProofCompatibility(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+
+	_, _, mp, err := DeployTestableMerkleProof(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableMerkleProof() error %v", err)
+	}
+
+	var addrs []common.Address
+	for i := 0; i < 13; i++ {
+		addrs = append(addrs, common.BytesToAddress(crypto.Keccak256([]byte{byte(i)})))
+	}
+	tree, err := merkle.FromAddresses(addrs)
+	if err != nil {
+		t.Fatalf("merkle.FromAddresses() error %v", err)
+	}
+	root := tree.Root()
+
+	asArrays := func(proof []common.Hash) [][32]byte {
+		out := make([][32]byte, len(proof))
+		for i, p := range proof {
+			out[i] = p
+		}
+		return out
+	}
+
+	for i, a := range addrs {
+		proof, err := tree.Proof(i)
+		if err != nil {
+			t.Fatalf("%T.Proof(%d) error %v", tree, i, err)
+		}
+
+		got, err := mp.Verify(nil, asArrays(proof), root, a)
+		if err != nil {
+			t.Fatalf("Verify([proof %d], root, %v) error %v", i, a, err)
+		}
+		if !got {
+			t.Errorf("Verify([proof %d], root, %v) got false; want true", i, a)
+		}
+
+		got, err = mp.Verify(nil, asArrays(proof), root, sim.Addr(vandal))
+		if err != nil {
+			t.Fatalf("Verify([proof %d], root, [vandal]) error %v", i, err)
+		}
+		if got {
+			t.Errorf("Verify([proof %d], root, [vandal]) got true; want false", i)
+		}
+	}
+}
+
+func TestMerkleAllowlist(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+
+	_, _, al, err := DeployTestableMerkleAllowlist(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableMerkleAllowlist() error %v", err)
+	}
+
+	allowances := []merkle.Allowance{
+		{Address: sim.Addr(deployer), Amount: big.NewInt(1)},
+		{Address: sim.Addr(arbitrary), Amount: big.NewInt(3)},
+	}
+	for i := 0; i < 5; i++ {
+		allowances = append(allowances, merkle.Allowance{
+			Address: common.BytesToAddress(crypto.Keccak256([]byte{byte(i)})),
+			Amount:  big.NewInt(int64(i)),
+		})
+	}
+	tree := ethtest.MerkleAllowlistTB(t, allowances...)
+	sim.Must(t, "SetMerkleRoot()")(al.SetMerkleRoot(sim.Acc(deployer), tree.Root()))
+
+	t.Run("leaf compatibility", func(t *testing.T) {
+		for _, a := range allowances {
+			got, err := al.AllowlistLeaf(nil, a.Address, a.Amount)
+			if want := merkle.AllowanceLeaf(a.Address, a.Amount); err != nil || got != want {
+				t.Errorf("AllowlistLeaf(%v, %d) got %#x, err %v; want %v as returned by merkle.AllowanceLeaf(), nil err", a.Address, a.Amount, got, err, want)
+			}
+		}
+	})
+
+	proofFor := func(a merkle.Allowance) [][32]byte {
+		return ethtest.MerkleProofTB(t, tree, merkle.AllowanceLeaf(a.Address, a.Amount))
+	}
+	arbProof := proofFor(allowances[1])
+
+	tests := []struct {
+		name      string
+		account   int
+		n         int64
+		allowance int64
+		proof     [][32]byte
+		wantErr   revert.Checker
+	}{
+		{
+			name:      "inflated allowance",
+			account:   arbitrary,
+			n:         4,
+			allowance: 4,
+			proof:     arbProof,
+			wantErr:   revert.InvalidMerkleProof,
+		},
+		{
+			name:      "another's proof",
+			account:   vandal,
+			n:         1,
+			allowance: 3,
+			proof:     arbProof,
+			wantErr:   revert.InvalidMerkleProof,
+		},
+		{
+			name:      "exceeds allowance in one claim",
+			account:   arbitrary,
+			n:         4,
+			allowance: 3,
+			proof:     arbProof,
+			wantErr:   revert.MerkleAllowance,
+		},
+		{
+			name:      "partial claim",
+			account:   arbitrary,
+			n:         2,
+			allowance: 3,
+			proof:     arbProof,
+		},
+		{
+			name:      "exceeds remaining allowance",
+			account:   arbitrary,
+			n:         2,
+			allowance: 3,
+			proof:     arbProof,
+			wantErr:   revert.MerkleAllowance,
+		},
+		{
+			name:      "remaining allowance",
+			account:   arbitrary,
+			n:         1,
+			allowance: 3,
+			proof:     arbProof,
+		},
+		{
+			name:      "single allowance",
+			account:   deployer,
+			n:         1,
+			allowance: 1,
+			proof:     proofFor(allowances[0]),
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := al.Claim(sim.Acc(tt.account), big.NewInt(tt.n), big.NewInt(tt.allowance), tt.proof)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: Claim(%d, %d, [proof]) error %v", tt.name, tt.n, tt.allowance, err)
+			}
+			continue
+		}
+		if diff := tt.wantErr.Diff(nil, err); diff != "" {
+			t.Errorf("%s: Claim(%d, %d, [proof]) %s", tt.name, tt.n, tt.allowance, diff)
+		}
+	}
+
+	for acc, want := range map[int]int64{deployer: 1, arbitrary: 3, vandal: 0} {
+		got, err := al.AllowlistClaimed(nil, sim.Addr(acc))
+		if err != nil || got.Int64() != want {
+			t.Errorf("AllowlistClaimed([account %d]) got %d, err %v; want %d, nil err", acc, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/h-fam/errdiff"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+)
+
+func TestVoucherChecker(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+
+	_, _, checker, err := DeployTestableVoucherChecker(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableVoucherChecker() error %v", err)
+	}
+	for _, a := range goodSignerAddrs {
+		sim.Must(t, "AddSigner()")(checker.AddSigner(sim.Acc(deployer), a))
+	}
+
+	// The simulated backend's clock is unrelated to the wall clock so expiry
+	// is relative to the latest block.
+	now := time.Unix(int64(sim.Blockchain().CurrentBlock().Time()), 0)
+
+	newVoucher := func(t *testing.T, value int64, expiry time.Time) eth.Voucher {
+		t.Helper()
+		v, err := eth.NewVoucher(sim.Addr(arbitrary), big.NewInt(value), expiry)
+		if err != nil {
+			t.Fatalf("eth.NewVoucher() error %v", err)
+		}
+		return v
+	}
+
+	asSolidity := func(v eth.Voucher) VoucherCheckerVoucher {
+		return VoucherCheckerVoucher{
+			Recipient: v.Recipient,
+			Value:     v.Value,
+			Nonce:     v.Nonce,
+			Expiry:    new(big.Int).SetUint64(v.Expiry),
+		}
+	}
+
+	tests := []struct {
+		name           string
+		signer         *eth.Signer
+		voucher        eth.Voucher
+		modify         func(*VoucherCheckerVoucher)
+		errDiffAgainst interface{}
+	}{
+		{
+			name:    "valid",
+			signer:  goodSigners[0],
+			voucher: newVoucher(t, 42, now.Add(time.Hour)),
+		},
+		{
+			name:    "valid from different signer",
+			signer:  goodSigners[1],
+			voucher: newVoucher(t, 1, now.Add(time.Hour)),
+		},
+		{
+			name:           "expired",
+			signer:         goodSigners[0],
+			voucher:        newVoucher(t, 42, now.Add(-time.Hour)),
+			errDiffAgainst: string(revert.VoucherExpired),
+		},
+		{
+			name:           "bad signer",
+			signer:         badSigner,
+			voucher:        newVoucher(t, 42, now.Add(time.Hour)),
+			errDiffAgainst: string(revert.InvalidSignature),
+		},
+		{
+			name:    "modified value",
+			signer:  goodSigners[0],
+			voucher: newVoucher(t, 42, now.Add(time.Hour)),
+			modify: func(v *VoucherCheckerVoucher) {
+				v.Value = big.NewInt(1000)
+			},
+			errDiffAgainst: string(revert.InvalidSignature),
+		},
+		{
+			name:    "extended expiry",
+			signer:  goodSigners[0],
+			voucher: newVoucher(t, 42, now.Add(time.Hour)),
+			modify: func(v *VoucherCheckerVoucher) {
+				v.Expiry.Add(v.Expiry, big.NewInt(86400))
+			},
+			errDiffAgainst: string(revert.InvalidSignature),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := tt.signer.SignVoucher(tt.voucher)
+			if err != nil {
+				t.Fatalf("%T.SignVoucher(%+v) error %v", tt.signer, tt.voucher, err)
+			}
+
+			v := asSolidity(tt.voucher)
+			if tt.modify != nil {
+				tt.modify(&v)
+			}
+
+			_, err = checker.Redeem(sim.Acc(arbitrary), v, sig)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Fatalf("Redeem() on first call; %s", diff)
+			}
+			if tt.errDiffAgainst != nil {
+				return
+			}
+
+			_, err = checker.Redeem(sim.Acc(arbitrary), v, sig)
+			if diff := errdiff.Check(err, "SignatureChecker: Message already used"); diff != "" {
+				t.Errorf("Redeem() on second call with same voucher; %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../contracts/erc721/ERC721ARentable.sol";
+
+/// @notice Exposes minting for testing of ERC721ARentable.
+contract TestableERC721ARentable is ERC721ARentable {
+    // solhint-disable-next-line no-empty-blocks
+    constructor() ERC721ARentable("Token", "JRR") {}
+
+    function mintN(uint256 num) public {
+        ERC721A._safeMint(msg.sender, num);
+    }
+}
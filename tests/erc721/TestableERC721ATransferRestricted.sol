@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../contracts/erc721/ERC721ATransferRestricted.sol";
+
+/// @notice Exposes minting and burning for testing of transfer restrictions.
+contract TestableERC721ATransferRestricted is ERC721ATransferRestricted {
+    // solhint-disable-next-line no-empty-blocks
+    constructor() ERC721ATransferRestricted("Token", "JRR") {}
+
+    function mintN(uint256 num) public {
+        ERC721A._safeMint(msg.sender, num);
+    }
+
+    function burn(uint256 tokenId) public {
+        ERC721A._burn(tokenId, true);
+    }
+}
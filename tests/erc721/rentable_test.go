@@ -0,0 +1,182 @@
+package erc721
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func deployRentable(t *testing.T) (*ethtest.SimulatedBackend, *TestableERC721ARentable) {
+	t.Helper()
+
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	_, _, nft, err := DeployTestableERC721ARentable(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableERC721ARentable() error %v", err)
+	}
+	sim.Must(t, "MintN(2)")(nft.MintN(sim.Acc(tokenOwner), big.NewInt(2)))
+
+	return sim, nft
+}
+
+// now returns the timestamp of the latest block, which is the block.timestamp
+// seen by calls.
+func now(sim *ethtest.SimulatedBackend) uint64 {
+	return sim.Blockchain().CurrentBlock().Time()
+}
+
+func TestRentableExpiry(t *testing.T) {
+	sim, nft := deployRentable(t)
+
+	id := big.NewInt(exists)
+	expires := now(sim) + 100
+	sim.Must(t, "SetUser(%d, <receiver>, %d)", id, expires)(nft.SetUser(sim.Acc(tokenOwner), id, sim.Addr(tokenReceiver), expires))
+
+	// Every Commit() advances the clock by 10 seconds in addition to any
+	// adjustment, so the exact block times are computed as the test progresses.
+	for _, adjust := range []time.Duration{0, 30 * time.Second, 40 * time.Second, 20 * time.Second, time.Hour} {
+		if err := sim.AdjustTime(adjust); err != nil {
+			t.Fatalf("AdjustTime(%v) error %v", adjust, err)
+		}
+		sim.Commit()
+
+		tm := now(sim)
+		want := sim.Addr(tokenReceiver)
+		if tm > expires {
+			want = common.Address{}
+		}
+
+		if got, err := nft.UserOf(nil, id); err != nil || got != want {
+			t.Errorf("At %ds relative to expiry; UserOf(%d) got %v, err %v; want %v, nil err", int64(tm)-int64(expires), id, got, err, want)
+		}
+		if got, err := nft.UserExpires(nil, id); err != nil || got.Uint64() != expires {
+			t.Errorf("UserExpires(%d) got %d, err %v; want %d, nil err", id, got, err, expires)
+		}
+	}
+
+	if tm := now(sim); tm <= expires {
+		t.Errorf("Bad test setup; final block time %d <= expiry %d", tm, expires)
+	}
+}
+
+func TestRentableSetUserAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(*testing.T, *ethtest.SimulatedBackend, *TestableERC721ARentable)
+		account int
+		want    revert.Checker
+	}{
+		{
+			name:    "owner",
+			account: tokenOwner,
+		},
+		{
+			name: "approved for token",
+			setup: func(t *testing.T, sim *ethtest.SimulatedBackend, nft *TestableERC721ARentable) {
+				sim.Must(t, "Approve()")(nft.Approve(sim.Acc(tokenOwner), sim.Addr(approved), big.NewInt(exists)))
+			},
+			account: approved,
+		},
+		{
+			name: "approved for all",
+			setup: func(t *testing.T, sim *ethtest.SimulatedBackend, nft *TestableERC721ARentable) {
+				sim.Must(t, "SetApprovalForAll()")(nft.SetApprovalForAll(sim.Acc(tokenOwner), sim.Addr(approved), true))
+			},
+			account: approved,
+		},
+		{
+			name:    "unapproved",
+			account: vandal,
+			want:    revert.Checker("ERC721ARentable: Not approved nor owner"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim, nft := deployRentable(t)
+			if tt.setup != nil {
+				tt.setup(t, sim, nft)
+			}
+
+			_, err := nft.SetUser(sim.Acc(tt.account), big.NewInt(exists), sim.Addr(tokenReceiver), now(sim)+100)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("SetUser() as %s; error %v", accountName(tt.account), err)
+				}
+				return
+			}
+			if diff := tt.want.Diff(nil, err); diff != "" {
+				t.Errorf("SetUser() as %s; %s", accountName(tt.account), diff)
+			}
+		})
+	}
+}
+
+func TestRentableTransferResetsUser(t *testing.T) {
+	sim, nft := deployRentable(t)
+	start := sim.BlockNumber().Uint64()
+
+	id := big.NewInt(exists)
+	// deployRentable() mints two tokens.
+	other := big.NewInt(exists + 1)
+	expires := now(sim) + 1000
+
+	sim.Must(t, "SetUser(%d)", id)(nft.SetUser(sim.Acc(tokenOwner), id, sim.Addr(tokenReceiver), expires))
+	sim.Must(t, "SetUser(%d)", other)(nft.SetUser(sim.Acc(tokenOwner), other, sim.Addr(tokenReceiver), expires))
+	sim.Must(t, "TransferFrom(%d)", id)(nft.TransferFrom(sim.Acc(tokenOwner), sim.Addr(tokenOwner), sim.Addr(tokenOwner2), id))
+
+	if got, err := nft.UserOf(nil, id); err != nil || got != (common.Address{}) {
+		t.Errorf("UserOf(%d) after transfer got %v, err %v; want zero address, nil err", id, got, err)
+	}
+	if got, err := nft.UserExpires(nil, id); err != nil || got.Sign() != 0 {
+		t.Errorf("UserExpires(%d) after transfer got %d, err %v; want 0, nil err", id, got, err)
+	}
+	if got, err := nft.UserOf(nil, other); err != nil || got != sim.Addr(tokenReceiver) {
+		t.Errorf("UserOf(%d) of untransferred token got %v, err %v; want %v, nil err", other, got, err, sim.Addr(tokenReceiver))
+	}
+
+	iter, err := nft.FilterUpdateUser(&bind.FilterOpts{Start: start + 1}, nil, nil)
+	if err != nil {
+		t.Fatalf("FilterUpdateUser() error %v", err)
+	}
+	defer iter.Close()
+
+	var got []*TestableERC721ARentableUpdateUser
+	for iter.Next() {
+		got = append(got, iter.Event)
+	}
+	if err := iter.Error(); err != nil {
+		t.Fatalf("Iterating over FilterUpdateUser() error %v", err)
+	}
+
+	want := []*TestableERC721ARentableUpdateUser{
+		{TokenId: id, User: sim.Addr(tokenReceiver), Expires: expires},
+		{TokenId: other, User: sim.Addr(tokenReceiver), Expires: expires},
+		{TokenId: id, User: common.Address{}, Expires: 0},
+	}
+	opts := ethtest.Comparers(cmpopts.IgnoreFields(TestableERC721ARentableUpdateUser{}, "Raw"))
+	if diff := cmp.Diff(want, got, opts...); diff != "" {
+		t.Errorf("UpdateUser events diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRentableSupportsInterface(t *testing.T) {
+	_, nft := deployRentable(t)
+
+	for _, id := range [][4]byte{
+		{0xad, 0x09, 0x2b, 0x5c}, // ERC4907
+		{0x80, 0xac, 0x58, 0xcd}, // ERC721
+		{0x2a, 0x55, 0x20, 0x5a}, // ERC2981
+	} {
+		if got, err := nft.SupportsInterface(nil, id); err != nil || !got {
+			t.Errorf("SupportsInterface(%#x) got %t, err %v; want true, nil err", id, got, err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package erc721
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest/revert"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// wantRoyalty returns floor(price * basisPoints / 10000) without the overflow
+// concerns of the contract.
+func wantRoyalty(price *big.Int, basisPoints int64) *big.Int {
+	r := new(big.Int).Mul(price, big.NewInt(basisPoints))
+	return r.Div(r, big.NewInt(10_000))
+}
+
+func TestRoyaltyInfo(t *testing.T) {
+	sim, nft, _ := deploy(t)
+
+	prices := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(9_999),
+		big.NewInt(10_000),
+		big.NewInt(10_001),
+		big.NewInt(1e18),
+		new(big.Int).Sub(math.MaxBig256, big.NewInt(1)),
+		math.MaxBig256,
+	}
+
+	for _, bp := range []int64{0, 1, 250, 999, 5_000, 10_000} {
+		t.Run(fmt.Sprintf("%d basis points", bp), func(t *testing.T) {
+			sim.Must(t, "SetDefaultRoyalty(%d)", bp)(nft.SetDefaultRoyalty(sim.Acc(deployer), sim.Addr(tokenOwner), big.NewInt(bp)))
+
+			for _, price := range prices {
+				got, err := nft.RoyaltyInfo(nil, big.NewInt(exists), price)
+				if err != nil {
+					t.Errorf("RoyaltyInfo(%d, %d) error %v", exists, price, err)
+					continue
+				}
+				if want := wantRoyalty(price, bp); got.Receiver != sim.Addr(tokenOwner) || got.RoyaltyAmount.Cmp(want) != 0 {
+					t.Errorf("RoyaltyInfo(%d, %d) got (%v, %d); want (%v, %d)", exists, price, got.Receiver, got.RoyaltyAmount, sim.Addr(tokenOwner), want)
+				}
+			}
+		})
+	}
+}
+
+func TestPerTokenRoyalty(t *testing.T) {
+	sim, nft, _ := deploy(t)
+
+	const (
+		defaultBP  = 500
+		overrideBP = 1_000
+	)
+	price := big.NewInt(1e18)
+
+	sim.Must(t, "SetDefaultRoyalty()")(nft.SetDefaultRoyalty(sim.Acc(deployer), sim.Addr(tokenOwner), big.NewInt(defaultBP)))
+	sim.Must(t, "SetTokenRoyalty(%d)", notExists)(nft.SetTokenRoyalty(sim.Acc(deployer), big.NewInt(notExists), sim.Addr(tokenOwner2), big.NewInt(overrideBP)))
+
+	check := func(t *testing.T, tokenID int64, wantReceiver common.Address, wantBP int64) {
+		t.Helper()
+		got, err := nft.RoyaltyInfo(nil, big.NewInt(tokenID), price)
+		if want := wantRoyalty(price, wantBP); err != nil || got.Receiver != wantReceiver || got.RoyaltyAmount.Cmp(want) != 0 {
+			t.Errorf("RoyaltyInfo(%d, %d) got (%v, %d), err %v; want (%v, %d), nil err", tokenID, price, got.Receiver, got.RoyaltyAmount, err, wantReceiver, want)
+		}
+	}
+
+	check(t, exists, sim.Addr(tokenOwner), defaultBP)
+	check(t, notExists, sim.Addr(tokenOwner2), overrideBP)
+
+	sim.Must(t, "ResetTokenRoyalty(%d)", notExists)(nft.ResetTokenRoyalty(sim.Acc(deployer), big.NewInt(notExists)))
+	check(t, notExists, sim.Addr(tokenOwner), defaultBP)
+
+	sim.Must(t, "DeleteDefaultRoyalty()")(nft.DeleteDefaultRoyalty(sim.Acc(deployer)))
+	check(t, exists, common.Address{}, 0)
+}
+
+func TestRoyaltyConfig(t *testing.T) {
+	sim, nft, _ := deploy(t)
+
+	tests := []struct {
+		name        string
+		account     int
+		receiver    common.Address
+		basisPoints int64
+		want        revert.Checker
+	}{
+		{
+			name:        "non-owner",
+			account:     vandal,
+			receiver:    sim.Addr(vandal),
+			basisPoints: 100,
+			want:        revert.OnlyOwner,
+		},
+		{
+			name:        "excessive basis points",
+			account:     deployer,
+			receiver:    sim.Addr(tokenOwner),
+			basisPoints: 10_001,
+			want:        revert.ExcessiveRoyalty,
+		},
+		{
+			name:        "zero receiver",
+			account:     deployer,
+			basisPoints: 100,
+			want:        revert.ZeroRoyaltyReceiver,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := tt.want.Diff(nft.SetDefaultRoyalty(sim.Acc(tt.account), tt.receiver, big.NewInt(tt.basisPoints))); diff != "" {
+				t.Errorf("SetDefaultRoyalty(%v, %d) as %s; %s", tt.receiver, tt.basisPoints, accountName(tt.account), diff)
+			}
+			if diff := tt.want.Diff(nft.SetTokenRoyalty(sim.Acc(tt.account), big.NewInt(exists), tt.receiver, big.NewInt(tt.basisPoints))); diff != "" {
+				t.Errorf("SetTokenRoyalty(%d, %v, %d) as %s; %s", exists, tt.receiver, tt.basisPoints, accountName(tt.account), diff)
+			}
+		})
+	}
+
+	t.Run("supports ERC2981", func(t *testing.T) {
+		id := [4]byte{0x2a, 0x55, 0x20, 0x5a}
+		if got, err := nft.SupportsInterface(nil, id); err != nil || !got {
+			t.Errorf("SupportsInterface(%#x) got %t, err %v; want true, nil err", id, got, err)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package erc721
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/openseatest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// A restriction represents the TransferRestricted.TransferRestriction enum.
+type restriction uint8
+
+const (
+	noRestriction restriction = iota
+	locked
+	soulbound
+)
+
+func (r restriction) String() string {
+	switch r {
+	case noRestriction:
+		return "None"
+	case locked:
+		return "Locked"
+	case soulbound:
+		return "Soulbound"
+	default:
+		return fmt.Sprintf("[UNKNOWN RESTRICTION %d]", uint8(r))
+	}
+}
+
+func deployTransferRestricted(t *testing.T) (*ethtest.SimulatedBackend, *TestableERC721ATransferRestricted) {
+	t.Helper()
+
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	openseatest.DeployProxyRegistryTB(t, sim)
+
+	_, _, nft, err := DeployTestableERC721ATransferRestricted(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableERC721ATransferRestricted() error %v", err)
+	}
+	sim.Must(t, "MintN(1)")(nft.MintN(sim.Acc(tokenOwner), big.NewInt(1)))
+
+	return sim, nft
+}
+
+func setRestriction(t *testing.T, sim *ethtest.SimulatedBackend, nft *TestableERC721ATransferRestricted, r restriction) {
+	t.Helper()
+	sim.Must(t, "SetTransferRestriction(%v)", r)(nft.SetTransferRestriction(sim.Acc(deployer), uint8(r)))
+}
+
+// transferPaths returns every means by which the token can be moved from the
+// token owner to the token receiver.
+func transferPaths() []struct {
+	name     string
+	transfer func(*testing.T, *ethtest.SimulatedBackend, *TestableERC721ATransferRestricted) (*types.Transaction, error)
+} {
+	id := big.NewInt(exists)
+
+	return []struct {
+		name     string
+		transfer func(*testing.T, *ethtest.SimulatedBackend, *TestableERC721ATransferRestricted) (*types.Transaction, error)
+	}{
+		{
+			name: "transferFrom by owner",
+			transfer: func(t *testing.T, sim *ethtest.SimulatedBackend, nft *TestableERC721ATransferRestricted) (*types.Transaction, error) {
+				return nft.TransferFrom(sim.Acc(tokenOwner), sim.Addr(tokenOwner), sim.Addr(tokenReceiver), id)
+			},
+		},
+		{
+			name: "safeTransferFrom by owner",
+			transfer: func(t *testing.T, sim *ethtest.SimulatedBackend, nft *TestableERC721ATransferRestricted) (*types.Transaction, error) {
+				return nft.SafeTransferFrom(sim.Acc(tokenOwner), sim.Addr(tokenOwner), sim.Addr(tokenReceiver), id)
+			},
+		},
+		{
+			name: "safeTransferFrom with data by owner",
+			transfer: func(t *testing.T, sim *ethtest.SimulatedBackend, nft *TestableERC721ATransferRestricted) (*types.Transaction, error) {
+				return nft.SafeTransferFrom0(sim.Acc(tokenOwner), sim.Addr(tokenOwner), sim.Addr(tokenReceiver), id, []byte("data"))
+			},
+		},
+		{
+			name: "transferFrom by approved",
+			transfer: func(t *testing.T, sim *ethtest.SimulatedBackend, nft *TestableERC721ATransferRestricted) (*types.Transaction, error) {
+				sim.Must(t, "Approve()")(nft.Approve(sim.Acc(tokenOwner), sim.Addr(approved), id))
+				return nft.TransferFrom(sim.Acc(approved), sim.Addr(tokenOwner), sim.Addr(tokenReceiver), id)
+			},
+		},
+		{
+			name: "transferFrom by operator",
+			transfer: func(t *testing.T, sim *ethtest.SimulatedBackend, nft *TestableERC721ATransferRestricted) (*types.Transaction, error) {
+				sim.Must(t, "SetApprovalForAll()")(nft.SetApprovalForAll(sim.Acc(tokenOwner), sim.Addr(approved), true))
+				return nft.TransferFrom(sim.Acc(approved), sim.Addr(tokenOwner), sim.Addr(tokenReceiver), id)
+			},
+		},
+		{
+			name: "transferFrom by pre-approved OpenSea proxy",
+			transfer: func(t *testing.T, sim *ethtest.SimulatedBackend, nft *TestableERC721ATransferRestricted) (*types.Transaction, error) {
+				openseatest.SetProxyTB(t, sim, sim.Addr(tokenOwner), sim.Addr(proxy))
+				return nft.TransferFrom(sim.Acc(proxy), sim.Addr(tokenOwner), sim.Addr(tokenReceiver), id)
+			},
+		},
+	}
+}
+
+func TestTransferRestrictions(t *testing.T) {
+	tests := []struct {
+		restriction restriction
+		want        revert.Checker
+	}{
+		{restriction: noRestriction},
+		{restriction: locked, want: revert.TransferLocked},
+		{restriction: soulbound, want: revert.Soulbound},
+	}
+
+	for _, tt := range tests {
+		for _, p := range transferPaths() {
+			t.Run(fmt.Sprintf("%v/%s", tt.restriction, p.name), func(t *testing.T) {
+				sim, nft := deployTransferRestricted(t)
+				if tt.restriction != noRestriction {
+					setRestriction(t, sim, nft, tt.restriction)
+				}
+
+				wantOwner := sim.Addr(tokenReceiver)
+				_, err := p.transfer(t, sim, nft)
+				if tt.want == "" {
+					if err != nil {
+						t.Fatalf("Transfer with %v restriction; error %v", tt.restriction, err)
+					}
+				} else {
+					if diff := tt.want.Diff(nil, err); diff != "" {
+						t.Fatalf("Transfer with %v restriction; %s", tt.restriction, diff)
+					}
+					wantOwner = sim.Addr(tokenOwner)
+				}
+
+				if got, err := nft.OwnerOf(nil, big.NewInt(exists)); err != nil || got != wantOwner {
+					t.Errorf("OwnerOf(%d) got %v, err %v; want %v, nil err", exists, got, err, wantOwner)
+				}
+			})
+		}
+	}
+}
+
+func TestLockWindow(t *testing.T) {
+	for _, p := range transferPaths() {
+		t.Run(p.name, func(t *testing.T) {
+			sim, nft := deployTransferRestricted(t)
+
+			setRestriction(t, sim, nft, locked)
+			if diff := revert.TransferLocked.Diff(p.transfer(t, sim, nft)); diff != "" {
+				t.Errorf("Transfer during lock window; %s", diff)
+			}
+
+			setRestriction(t, sim, nft, noRestriction)
+			sim.Must(t, "Transfer after lock window")(p.transfer(t, sim, nft))
+		})
+	}
+}
+
+func TestSoulbound(t *testing.T) {
+	sim, nft := deployTransferRestricted(t)
+
+	if diff := revert.OnlyOwner.Diff(nft.SetTransferRestriction(sim.Acc(vandal), uint8(soulbound))); diff != "" {
+		t.Errorf("SetTransferRestriction(%v) as %s; %s", soulbound, accountName(vandal), diff)
+	}
+
+	setRestriction(t, sim, nft, soulbound)
+
+	t.Run("permanent", func(t *testing.T) {
+		c := revert.Checker("TransferRestricted: Soulbound is permanent")
+		for _, r := range []restriction{noRestriction, locked, soulbound} {
+			if diff := c.Diff(nft.SetTransferRestriction(sim.Acc(deployer), uint8(r))); diff != "" {
+				t.Errorf("SetTransferRestriction(%v) after %v; %s", r, soulbound, diff)
+			}
+		}
+		if got, err := nft.TransferRestriction(nil); err != nil || restriction(got) != soulbound {
+			t.Errorf("TransferRestriction() got %v, err %v; want %v, nil err", restriction(got), err, soulbound)
+		}
+	})
+
+	t.Run("mint and burn allowed", func(t *testing.T) {
+		sim.Must(t, "MintN(1)")(nft.MintN(sim.Acc(tokenOwner2), big.NewInt(1)))
+		id := big.NewInt(notExists)
+		if got, err := nft.OwnerOf(nil, id); err != nil || got != sim.Addr(tokenOwner2) {
+			t.Errorf("OwnerOf(%d) after MintN() got %v, err %v; want %v, nil err", id, got, err, sim.Addr(tokenOwner2))
+		}
+
+		sim.Must(t, "Burn(%d)", id)(nft.Burn(sim.Acc(tokenOwner2), id))
+		if got, err := nft.BalanceOf(nil, sim.Addr(tokenOwner2)); err != nil || got.Sign() != 0 {
+			t.Errorf("BalanceOf([second token owner]) after Burn() got %d, err %v; want 0, nil err", got, err)
+		}
+	})
+}
@@ -0,0 +1,15 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/token/ERC20/ERC20.sol";
+
+/// @notice An ERC20 token with unrestricted minting, for testing of payments.
+contract TestableERC20 is ERC20 {
+    // solhint-disable-next-line no-empty-blocks
+    constructor() ERC20("Token", "TKN") {}
+
+    function mint(address to, uint256 amount) external {
+        _mint(to, amount);
+    }
+}
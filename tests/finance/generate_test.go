@@ -0,0 +1,3 @@
+package finance
+
+//go:generate ethier gen ../../contracts/finance/PushPaymentSplitter.sol ../../contracts/finance/TokenVesting.sol TestableERC20.sol
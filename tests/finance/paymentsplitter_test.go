@@ -0,0 +1,169 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Payees don't send any transactions so their balance changes are exactly
+// equal to the amounts released to them.
+const (
+	deployer = iota
+	payer
+	payee0
+	payee1
+	payee2
+	vandal
+	numAccounts
+)
+
+var payees = []int{payee0, payee1, payee2}
+
+// shares are the respective shares of each of payees, totalling 6 so amounts
+// divisible by 6 are split exactly.
+var shares = []int64{1, 2, 3}
+
+func deploy(t *testing.T) (*ethtest.SimulatedBackend, common.Address, *PushPaymentSplitter) {
+	t.Helper()
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+
+	var (
+		addrs []common.Address
+		bigs  []*big.Int
+	)
+	for i, p := range payees {
+		addrs = append(addrs, sim.Addr(p))
+		bigs = append(bigs, big.NewInt(shares[i]))
+	}
+
+	addr, _, split, err := DeployPushPaymentSplitter(sim.Acc(deployer), sim, addrs, bigs)
+	if err != nil {
+		t.Fatalf("DeployPushPaymentSplitter() error %v", err)
+	}
+	return sim, addr, split
+}
+
+// balances returns the ETH balance of each of payees.
+func balances(ctx context.Context, t *testing.T, sim *ethtest.SimulatedBackend) []*big.Int {
+	t.Helper()
+	var b []*big.Int
+	for _, p := range payees {
+		b = append(b, sim.BalanceOf(ctx, t, sim.Addr(p)))
+	}
+	return b
+}
+
+func TestPushETH(t *testing.T) {
+	ctx := context.Background()
+	sim, _, split := deploy(t)
+
+	if got, err := split.NumPayees(nil); err != nil || got.Cmp(big.NewInt(int64(len(payees)))) != 0 {
+		t.Fatalf("NumPayees() got %d, err %v; want %d, nil err", got, err, len(payees))
+	}
+
+	if diff := revert.OnlyOwner.Diff(split.ReleaseAll(sim.Acc(vandal))); diff != "" {
+		t.Errorf("ReleaseAll() as non-owner; %s", diff)
+	}
+
+	for _, deposit := range []int64{6, 12, 0} {
+		t.Run(fmt.Sprintf("deposit %d ETH", deposit), func(t *testing.T) {
+			if deposit > 0 {
+				sim.Must(t, "Receive(%d ETH)", deposit)(split.Receive(sim.WithValueFrom(payer, eth.Ether(deposit))))
+			}
+
+			for i, p := range payees {
+				want := eth.EtherFraction(deposit*shares[i], 6)
+				if got, err := split.Releasable(nil, sim.Addr(p)); err != nil || got.Cmp(want) != 0 {
+					t.Errorf("Releasable([payee %d]) got %d, err %v; want %d, nil err", i, got, err, want)
+				}
+			}
+
+			before := balances(ctx, t, sim)
+			// Releasing when nothing is due MUST NOT revert.
+			sim.Must(t, "ReleaseAll()")(split.ReleaseAll(sim.Acc(deployer)))
+			after := balances(ctx, t, sim)
+
+			for i := range payees {
+				got := new(big.Int).Sub(after[i], before[i])
+				if want := eth.EtherFraction(deposit*shares[i], 6); got.Cmp(want) != 0 {
+					t.Errorf("After ReleaseAll(); payee %d balance increased by %d; want %d", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPushERC20(t *testing.T) {
+	sim, splitAddr, split := deploy(t)
+
+	var (
+		tokens     []*TestableERC20
+		tokenAddrs []common.Address
+	)
+	for i := 0; i < 2; i++ {
+		addr, _, tok, err := DeployTestableERC20(sim.Acc(deployer), sim)
+		if err != nil {
+			t.Fatalf("DeployTestableERC20() error %v", err)
+		}
+		tokens = append(tokens, tok)
+		tokenAddrs = append(tokenAddrs, addr)
+	}
+
+	// wantBalances checks the token balances of each payee, indexed by
+	// [token][payee].
+	wantBalances := func(t *testing.T, want [][]int64) {
+		t.Helper()
+		for i, tok := range tokens {
+			for j, p := range payees {
+				if got, err := tok.BalanceOf(nil, sim.Addr(p)); err != nil || got.Cmp(big.NewInt(want[i][j])) != 0 {
+					t.Errorf("Token %d BalanceOf([payee %d]) got %d, err %v; want %d, nil err", i, j, got, err, want[i][j])
+				}
+			}
+		}
+	}
+
+	sim.Must(t, "Mint(<splitter>, 600)")(tokens[0].Mint(sim.Acc(payer), splitAddr, big.NewInt(600)))
+	sim.Must(t, "Mint(<splitter>, 60)")(tokens[1].Mint(sim.Acc(payer), splitAddr, big.NewInt(60)))
+
+	t.Run("releasable", func(t *testing.T) {
+		for i, tok := range tokenAddrs {
+			for j, p := range payees {
+				want := big.NewInt([]int64{600, 60}[i] * shares[j] / 6)
+				if got, err := split.ReleasableERC20(nil, tok, sim.Addr(p)); err != nil || got.Cmp(want) != 0 {
+					t.Errorf("ReleasableERC20(<token %d>, [payee %d]) got %d, err %v; want %d, nil err", i, j, got, err, want)
+				}
+			}
+		}
+	})
+
+	t.Run("push", func(t *testing.T) {
+		if diff := revert.OnlyOwner.Diff(split.ReleaseAllERC20s(sim.Acc(vandal), tokenAddrs)); diff != "" {
+			t.Errorf("ReleaseAllERC20s() as non-owner; %s", diff)
+		}
+
+		sim.Must(t, "ReleaseAllERC20s()")(split.ReleaseAllERC20s(sim.Acc(deployer), tokenAddrs))
+		wantBalances(t, [][]int64{
+			{100, 200, 300},
+			{10, 20, 30},
+		})
+	})
+
+	t.Run("pull", func(t *testing.T) {
+		sim.Must(t, "Mint(<splitter>, 6)")(tokens[0].Mint(sim.Acc(payer), splitAddr, big.NewInt(6)))
+
+		// Anyone can trigger a pull, and tokens with nothing due are skipped
+		// instead of reverting.
+		sim.Must(t, "ReleaseERC20s(…, [payee 1])")(split.ReleaseERC20s(sim.Acc(vandal), tokenAddrs, sim.Addr(payee1)))
+		wantBalances(t, [][]int64{
+			{100, 202, 300},
+			{10, 20, 30},
+		})
+	})
+}
@@ -0,0 +1,135 @@
+package finance
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/divergencetech/ethier/eth/vesting"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// now returns the timestamp of the latest block, which is the block.timestamp
+// seen by calls and by the transaction most recently sent.
+func now(sim *ethtest.SimulatedBackend) uint64 {
+	return sim.Blockchain().CurrentBlock().Time()
+}
+
+func TestTokenVestingAgainstModel(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	beneficiary := sim.Addr(payee0)
+
+	sched := vesting.Schedule{
+		Start:    now(sim) + 60,
+		Cliff:    120,
+		Duration: 600,
+	}
+	addr, _, vest, err := DeployTokenVesting(sim.Acc(deployer), sim, beneficiary, sched.Start, sched.Cliff, sched.Duration)
+	if err != nil {
+		t.Fatalf("DeployTokenVesting() error %v", err)
+	}
+	tokenAddr, _, token, err := DeployTestableERC20(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableERC20() error %v", err)
+	}
+
+	total := big.NewInt(0)
+	deposit := func(t *testing.T, amount int64) {
+		t.Helper()
+		sim.Must(t, "Mint(<vesting>, %d)", amount)(token.Mint(sim.Acc(payer), addr, big.NewInt(amount)))
+		total.Add(total, big.NewInt(amount))
+	}
+	deposit(t, 1e6)
+
+	// Every Commit() advances the clock by 10 seconds in addition to any
+	// adjustment, so steps straddle both the cliff and the end of vesting.
+	for i := 0; ; i++ {
+		tm := now(sim)
+		if tm > sched.Start+sched.Duration+100 {
+			break
+		}
+
+		t.Run(fmt.Sprintf("t=%d", int64(tm)-int64(sched.Start)), func(t *testing.T) {
+			// Tokens received mid-vesting are vested as if held since Start.
+			if i == 10 {
+				deposit(t, 5e5)
+			}
+
+			released, err := token.BalanceOf(nil, beneficiary)
+			if err != nil {
+				t.Fatalf("BalanceOf(<beneficiary>) error %v", err)
+			}
+
+			tm := now(sim)
+			want := sched.Releasable(total, released, tm)
+			if got, err := vest.Releasable(nil, tokenAddr); err != nil || got.Cmp(want) != 0 {
+				t.Errorf("Releasable() got %d, err %v; want %d, nil err", got, err, want)
+			}
+			if got, err := vest.VestedAmount(nil, tokenAddr, tm); err != nil || got.Cmp(sched.Vested(total, tm)) != 0 {
+				t.Errorf("VestedAmount(%d) got %d, err %v; want %d, nil err", tm, got, err, sched.Vested(total, tm))
+			}
+
+			// Anyone can release, but tokens only go to the beneficiary.
+			sim.Must(t, "Release()")(vest.Release(sim.Acc(vandal), tokenAddr))
+			got, err := token.BalanceOf(nil, beneficiary)
+			if want := sched.Vested(total, now(sim)); err != nil || got.Cmp(want) != 0 {
+				t.Errorf("After Release() at %d; BalanceOf(<beneficiary>) got %d, err %v; want %d, nil err", now(sim), got, err, want)
+			}
+		})
+
+		if err := sim.AdjustTime(7 * time.Second); err != nil {
+			t.Fatalf("AdjustTime() error %v", err)
+		}
+		sim.Commit()
+	}
+
+	if got, err := token.BalanceOf(nil, beneficiary); err != nil || got.Cmp(total) != 0 {
+		t.Errorf("BalanceOf(<beneficiary>) after vesting got %d, err %v; want %d, nil err", got, err, total)
+	}
+}
+
+func TestTokenVestingConstructor(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+
+	tests := []struct {
+		name                   string
+		beneficiary            common.Address
+		start, cliff, duration uint64
+		want                   revert.Checker
+	}{
+		{
+			name:     "zero beneficiary",
+			duration: 1,
+			want:     "TokenVesting: zero beneficiary",
+		},
+		{
+			name:        "zero duration",
+			beneficiary: sim.Addr(payee0),
+			want:        "TokenVesting: zero duration",
+		},
+		{
+			name:        "cliff after duration",
+			beneficiary: sim.Addr(payee0),
+			cliff:       2,
+			duration:    1,
+			want:        "TokenVesting: cliff > duration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := DeployTokenVesting(sim.Acc(deployer), sim, tt.beneficiary, tt.start, tt.cliff, tt.duration)
+			if diff := tt.want.Diff(nil, err); diff != "" {
+				t.Errorf("DeployTokenVesting(%v, %d, %d, %d) %s", tt.beneficiary, tt.start, tt.cliff, tt.duration, diff)
+			}
+
+			s := vesting.Schedule{Start: tt.start, Cliff: tt.cliff, Duration: tt.duration}
+			if tt.beneficiary != (common.Address{}) && s.Validate() == nil {
+				t.Errorf("%+v.Validate() got nil error; want error matching contract", s)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../contracts/sales/FixedPriceSeller.sol";
+import "@openzeppelin/contracts/utils/Address.sol";
+
+/**
+@notice A FixedPriceSeller exposing _purchase() for testing of caps, payments
+and refunds.
+@dev Purchases by contracts invoke CapEvader.onPurchase() to mimic the
+interaction in an ERC721 safeMint().
+ */
+contract TestableCappedFixedPriceSeller is FixedPriceSeller {
+    using Address for address;
+
+    constructor(
+        uint256 price,
+        Seller.SellerConfig memory sellerConfig,
+        address payable beneficiary
+    )
+        FixedPriceSeller(price, sellerConfig, beneficiary)
+    {} // solhint-disable-line no-empty-blocks
+
+    mapping(address => uint256) public own;
+
+    function _handlePurchase(
+        address to,
+        uint256 n,
+        bool
+    ) internal override {
+        own[to] += n;
+        if (to.isContract()) {
+            CapEvader(payable(to)).onPurchase();
+        }
+    }
+
+    /// @dev Public API for testing of _purchase().
+    function buy(address to, uint256 n) public payable {
+        Seller._purchase(to, n);
+    }
+}
+
+/**
+@notice Attempts to circumvent Seller caps by making multiple purchases in a
+single transaction and by reentering upon receipt of purchased items.
+ */
+contract CapEvader {
+    TestableCappedFixedPriceSeller public seller;
+
+    constructor(address _seller) {
+        seller = TestableCappedFixedPriceSeller(_seller);
+    }
+
+    /// @notice Buys n items, `times` times, splitting msg.value evenly.
+    function buyRepeatedly(uint256 n, uint256 times) external payable {
+        for (uint256 i = 0; i < times; i++) {
+            seller.buy{value: msg.value / times}(address(this), n);
+        }
+    }
+
+    /// @notice Buys n items for each recipient, splitting msg.value evenly.
+    function buyForEach(address[] calldata recipients, uint256 n)
+        external
+        payable
+    {
+        for (uint256 i = 0; i < recipients.length; i++) {
+            seller.buy{value: msg.value / recipients.length}(
+                recipients[i],
+                n
+            );
+        }
+    }
+
+    /// @notice If true, onPurchase() attempts to buy another item.
+    bool public reenter;
+
+    function setReenter(bool _reenter) external {
+        reenter = _reenter;
+    }
+
+    /// @notice Called by the seller upon purchase, akin to onERC721Received().
+    function onPurchase() external {
+        if (reenter) {
+            seller.buy(address(this), 1);
+        }
+    }
+
+    /// @notice Accepts refunds.
+    receive() external payable {} // solhint-disable-line no-empty-blocks
+}
@@ -0,0 +1,275 @@
+package sales
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/h-fam/errdiff"
+)
+
+// Accounts used by tests of TestableCappedFixedPriceSeller.
+const (
+	sellerOwner = iota
+	evaderOwner
+	farm0
+	farm1
+	farm2
+	numCappedAccounts
+)
+
+// A cappedEnv is a freshly deployed TestableCappedFixedPriceSeller, selling at
+// cappedPrice with the package's default caps, and a CapEvader targeting it.
+type cappedEnv struct {
+	sim         *ethtest.SimulatedBackend
+	seller      *TestableCappedFixedPriceSeller
+	sellerAddr  common.Address
+	evader      *CapEvader
+	evaderAddr  common.Address
+	beneficiary common.Address
+}
+
+var cappedPrice = eth.EtherFraction(1, 10)
+
+// value returns the cost of n items at cappedPrice.
+func value(n int64) *big.Int {
+	return new(big.Int).Mul(cappedPrice, big.NewInt(n))
+}
+
+func deployCapped(t *testing.T, freeQuota int64) *cappedEnv {
+	t.Helper()
+	sim := ethtest.NewSimulatedBackendTB(t, numCappedAccounts)
+
+	// A beneficiary per deployment so revenues are independent of other tests.
+	s, err := eth.NewSigner(128)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(128) error %v", err)
+	}
+
+	cfg := SellerSellerConfig{
+		TotalInventory:   big.NewInt(totalInventory),
+		MaxPerAddress:    big.NewInt(maxPerAddress),
+		MaxPerTx:         big.NewInt(maxPerTx),
+		FreeQuota:        big.NewInt(freeQuota),
+		ReserveFreeQuota: true,
+	}
+	sellerAddr, _, seller, err := DeployTestableCappedFixedPriceSeller(sim.Acc(sellerOwner), sim, cappedPrice, cfg, s.Address())
+	if err != nil {
+		t.Fatalf("DeployTestableCappedFixedPriceSeller() error %v", err)
+	}
+	evaderAddr, _, evader, err := DeployCapEvader(sim.Acc(evaderOwner), sim, sellerAddr)
+	if err != nil {
+		t.Fatalf("DeployCapEvader() error %v", err)
+	}
+
+	return &cappedEnv{
+		sim:         sim,
+		seller:      seller,
+		sellerAddr:  sellerAddr,
+		evader:      evader,
+		evaderAddr:  evaderAddr,
+		beneficiary: s.Address(),
+	}
+}
+
+// wantOwn checks the number of items owned by each address.
+func (e *cappedEnv) wantOwn(t *testing.T, want map[common.Address]int64) {
+	t.Helper()
+	for addr, n := range want {
+		if got, err := e.seller.Own(nil, addr); err != nil || got.Cmp(big.NewInt(n)) != 0 {
+			t.Errorf("Own(%v) got %d, err %v; want %d, nil err", addr, got, err, n)
+		}
+	}
+}
+
+func TestCapEvasion(t *testing.T) {
+	farms := func(e *cappedEnv, n int) []common.Address {
+		var addrs []common.Address
+		for i := 0; i < n; i++ {
+			addrs = append(addrs, e.sim.Addr(farm0+i))
+		}
+		return addrs
+	}
+
+	tests := []struct {
+		name           string
+		attempt        func(*cappedEnv) (*types.Transaction, error)
+		errDiffAgainst interface{}
+		wantOwn        func(*cappedEnv) map[common.Address]int64
+	}{
+		{
+			name: "single purchase above per-tx cap",
+			attempt: func(e *cappedEnv) (*types.Transaction, error) {
+				return e.seller.Buy(e.sim.WithValueFrom(farm0, value(10)), e.sim.Addr(farm0), big.NewInt(10))
+			},
+			wantOwn: func(e *cappedEnv) map[common.Address]int64 {
+				return map[common.Address]int64{e.sim.Addr(farm0): maxPerTx}
+			},
+		},
+		{
+			name: "repeated purchases in one tx up to address cap",
+			attempt: func(e *cappedEnv) (*types.Transaction, error) {
+				return e.evader.BuyRepeatedly(e.sim.WithValueFrom(evaderOwner, value(12)), big.NewInt(maxPerTx), big.NewInt(4))
+			},
+			wantOwn: func(e *cappedEnv) map[common.Address]int64 {
+				return map[common.Address]int64{e.evaderAddr: maxPerAddress}
+			},
+		},
+		{
+			name: "repeated purchases in one tx beyond address cap",
+			attempt: func(e *cappedEnv) (*types.Transaction, error) {
+				return e.evader.BuyRepeatedly(e.sim.WithValueFrom(evaderOwner, value(15)), big.NewInt(maxPerTx), big.NewInt(5))
+			},
+			errDiffAgainst: string(revert.BuyerLimit),
+			wantOwn: func(e *cappedEnv) map[common.Address]int64 {
+				return map[common.Address]int64{e.evaderAddr: 0}
+			},
+		},
+		{
+			name: "many recipients in one tx within sender cap",
+			attempt: func(e *cappedEnv) (*types.Transaction, error) {
+				return e.evader.BuyForEach(e.sim.WithValueFrom(evaderOwner, value(12)), farms(e, 3), big.NewInt(maxPerTx))
+			},
+			wantOwn: func(e *cappedEnv) map[common.Address]int64 {
+				return map[common.Address]int64{
+					e.sim.Addr(farm0): maxPerTx,
+					e.sim.Addr(farm1): maxPerTx,
+					e.sim.Addr(farm2): maxPerTx,
+				}
+			},
+		},
+		{
+			name: "many recipients in one tx beyond sender cap",
+			attempt: func(e *cappedEnv) (*types.Transaction, error) {
+				// The tx.origin is deliberately excluded as its own buyer limit
+				// would be reached before the sender's.
+				recipients := append(farms(e, 3), e.sim.Addr(sellerOwner), common.HexToAddress("0x01"))
+				return e.evader.BuyForEach(e.sim.WithValueFrom(evaderOwner, value(15)), recipients, big.NewInt(maxPerTx))
+			},
+			errDiffAgainst: string(revert.SenderLimit),
+			wantOwn: func(e *cappedEnv) map[common.Address]int64 {
+				return map[common.Address]int64{e.sim.Addr(farm0): 0}
+			},
+		},
+		{
+			name: "reentrant purchase on receipt",
+			attempt: func(e *cappedEnv) (*types.Transaction, error) {
+				if _, err := e.evader.SetReenter(e.sim.Acc(evaderOwner), true); err != nil {
+					return nil, fmt.Errorf("SetReenter(true): %v", err)
+				}
+				return e.evader.BuyRepeatedly(e.sim.WithValueFrom(evaderOwner, value(2)), big.NewInt(1), big.NewInt(1))
+			},
+			errDiffAgainst: string(revert.Reentrant),
+			wantOwn: func(e *cappedEnv) map[common.Address]int64 {
+				return map[common.Address]int64{e.evaderAddr: 0}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := deployCapped(t, 0)
+
+			_, err := tt.attempt(e)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Fatalf("%s", diff)
+			}
+			e.wantOwn(t, tt.wantOwn(e))
+		})
+	}
+}
+
+func TestCapEvasionWithFreshContracts(t *testing.T) {
+	e := deployCapped(t, 0)
+
+	e.sim.Must(t, "BuyRepeatedly(…) via first contract")(e.evader.BuyRepeatedly(e.sim.WithValueFrom(evaderOwner, value(12)), big.NewInt(maxPerTx), big.NewInt(4)))
+
+	// A new contract, with no purchase history, is still limited by the
+	// tx.origin having reached its cap.
+	addr, _, evader, err := DeployCapEvader(e.sim.Acc(evaderOwner), e.sim, e.sellerAddr)
+	if err != nil {
+		t.Fatalf("DeployCapEvader() error %v", err)
+	}
+	if diff := revert.OriginLimit.Diff(evader.BuyRepeatedly(e.sim.WithValueFrom(evaderOwner, value(1)), big.NewInt(1), big.NewInt(1))); diff != "" {
+		t.Errorf("BuyRepeatedly() via second contract from same origin; %s", diff)
+	}
+
+	e.wantOwn(t, map[common.Address]int64{
+		e.evaderAddr: maxPerAddress,
+		addr:         0,
+	})
+}
+
+func TestWalletFarmingAndTotalSupply(t *testing.T) {
+	const freeQuota = 3
+	e := deployCapped(t, freeQuota)
+
+	// Address limits are documented as being vulnerable to wallet farming so
+	// distinct EOAs each receive up to maxPerAddress, but the reserved free
+	// quota is never eroded.
+	want := map[int]int64{
+		farm0: maxPerAddress,
+		farm1: maxPerAddress,
+		farm2: totalInventory - freeQuota - 2*maxPerAddress,
+	}
+
+	for _, acc := range []int{farm0, farm1, farm2} {
+		for i := 0; i < 4; i++ {
+			tx, err := e.seller.Buy(e.sim.WithValueFrom(acc, value(maxPerTx)), e.sim.Addr(acc), big.NewInt(maxPerTx))
+			if err != nil && revert.BuyerLimit.Diff(tx, err) != "" && revert.SoldOut.Diff(tx, err) != "" {
+				t.Fatalf("Buy([account %d]) got err %v; want nil, %q or %q", acc, err, revert.BuyerLimit, revert.SoldOut)
+			}
+		}
+	}
+
+	if diff := revert.SoldOut.Diff(e.seller.Buy(e.sim.WithValueFrom(sellerOwner, value(1)), e.sim.Addr(sellerOwner), big.NewInt(1))); diff != "" {
+		t.Errorf("Buy() after all non-reserved inventory sold; %s", diff)
+	}
+
+	// Free-of-charge purchases by the owner neither count towards, nor are
+	// limited by, address caps.
+	e.sim.Must(t, "PurchaseFreeOfCharge(%d)", freeQuota)(e.seller.PurchaseFreeOfCharge(e.sim.Acc(sellerOwner), e.sim.Addr(farm0), big.NewInt(freeQuota)))
+	want[farm0] += freeQuota
+
+	if got, err := e.seller.TotalSold(nil); err != nil || got.Cmp(big.NewInt(totalInventory)) != 0 {
+		t.Errorf("TotalSold() got %d, err %v; want %d, nil err", got, err, totalInventory)
+	}
+
+	wantOwn := make(map[common.Address]int64)
+	for acc, n := range want {
+		wantOwn[e.sim.Addr(acc)] = n
+	}
+	e.wantOwn(t, wantOwn)
+}
+
+func TestCappedPayments(t *testing.T) {
+	ctx := context.Background()
+	e := deployCapped(t, 0)
+
+	t.Run("underpayment", func(t *testing.T) {
+		wantMsg := fmt.Sprintf("Seller: Costs %d GWei", new(big.Int).Div(value(2), big.NewInt(1e9)))
+		if diff := revert.Checker(wantMsg).Diff(e.seller.Buy(e.sim.WithValueFrom(farm0, value(1)), e.sim.Addr(farm0), big.NewInt(2))); diff != "" {
+			t.Errorf("Buy(2) paying for 1; %s", diff)
+		}
+	})
+
+	t.Run("overpayment refunded to contract", func(t *testing.T) {
+		// Requesting more than the per-tx cap results in a refund for the
+		// items that weren't purchased.
+		e.sim.Must(t, "BuyRepeatedly()")(e.evader.BuyRepeatedly(e.sim.WithValueFrom(evaderOwner, value(5)), big.NewInt(5), big.NewInt(1)))
+
+		if got, want := e.sim.BalanceOf(ctx, t, e.evaderAddr), value(5-maxPerTx); got.Cmp(want) != 0 {
+			t.Errorf("BalanceOf(<evader>) after overpaying; got %d; want %d", got, want)
+		}
+		if got, want := e.sim.BalanceOf(ctx, t, e.beneficiary), value(maxPerTx); got.Cmp(want) != 0 {
+			t.Errorf("BalanceOf(<beneficiary>) got %d; want %d", got, want)
+		}
+		e.wantOwn(t, map[common.Address]int64{e.evaderAddr: maxPerTx})
+	})
+}
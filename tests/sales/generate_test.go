@@ -1,3 +1,3 @@
 package sales
 
-//go:generate ethier gen TestableDutchAuction.sol TestableFixedPriceSeller.sol TestableArbitraryPriceSeller.sol
+//go:generate ethier gen TestableDutchAuction.sol TestableFixedPriceSeller.sol TestableArbitraryPriceSeller.sol TestableCappedFixedPriceSeller.sol
@@ -130,6 +130,17 @@ func (c config) convert() LinearDutchAuctionDutchAuctionConfig {
 	}
 }
 
+// model returns the eth package's reference model of the config's pricing.
+func (c config) model() eth.LinearDutchAuction {
+	return eth.LinearDutchAuction{
+		StartPoint:       uint64(c.StartPoint),
+		StartPrice:       c.StartPrice,
+		DecreaseInterval: uint64(c.DecreaseInterval),
+		DecreaseSize:     c.DecreaseSize,
+		NumDecreases:     uint64(c.NumDecreases),
+	}
+}
+
 func TestLinearPriceDecrease(t *testing.T) {
 	const startBlock = 10
 
@@ -378,6 +389,76 @@ func TestTimeBasedDecrease(t *testing.T) {
 	}
 }
 
+func TestPriceModel(t *testing.T) {
+	const numDecreases = 4
+
+	// As noted in TestTimeBasedDecrease, every Commit() advances the simulated
+	// clock by 10 seconds, so the time-based decrease interval is a multiple of
+	// this to guarantee that every step of the auction is observed, including
+	// the boundaries between steps.
+	tests := []struct {
+		unit                      unit
+		decreaseInterval, advance int64
+	}{
+		{unit: Block, decreaseInterval: 5, advance: 3},
+		{unit: Time, decreaseInterval: 30, advance: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit.String(), func(t *testing.T) {
+			cfg := config{
+				StartPrice:       eth.Ether(3),
+				DecreaseSize:     eth.EtherFraction(1, 2),
+				NumDecreases:     numDecreases,
+				DecreaseInterval: tt.decreaseInterval,
+				Unit:             tt.unit,
+				ExpectedReserve:  eth.Ether(1),
+			}
+			sim, _, auction := deploy(t, cfg)
+
+			// current returns the point, in the config's unit, at which calls
+			// are made.
+			current := func() int64 {
+				if tt.unit == Block {
+					return sim.BlockNumber().Int64()
+				}
+				return int64(sim.Blockchain().CurrentBlock().Time())
+			}
+
+			// The start point is set after deployment so that the auction
+			// starts in the future, allowing the model's not-started state to
+			// be tested too.
+			cfg.StartPoint = current() + tt.advance
+			sim.Must(t, "SetAuctionStartPoint(%d)", cfg.StartPoint)(auction.SetAuctionStartPoint(sim.Acc(0), big.NewInt(cfg.StartPoint)))
+			model := cfg.model()
+
+			if reserve, err := model.Reserve(); err != nil || reserve.Cmp(cfg.ExpectedReserve) != 0 {
+				t.Fatalf("%T.Reserve() got %d, err %v; want %d, nil err", model, reserve, err, cfg.ExpectedReserve)
+			}
+
+			end := cfg.StartPoint + tt.decreaseInterval*(numDecreases+2)
+			for ; current() < end; sim.Commit() {
+				point := current()
+
+				for _, n := range []uint64{1, 2} {
+					want, wantErr := model.Cost(n, uint64(point))
+					got, err := auction.Cost(nil, new(big.Int).SetUint64(n), big.NewInt(0))
+
+					if wantErr != nil {
+						if diff := revert.NotStarted.Diff(nil, err); diff != "" {
+							t.Errorf("Cost(%d) at point %d when model returns %v; %s", n, point, wantErr, diff)
+						}
+						continue
+					}
+					if err != nil || got.Cmp(want) != 0 {
+						t.Errorf("Cost(%d) at point %d got %d, err %v; want %d (%T.Cost()), nil err", n, point, got, err, want, model)
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestReserveCheck(t *testing.T) {
 	sim, _, auction := deployConstantPrice(t, eth.Ether(0))
 
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+/// @notice The subset of VRFCoordinatorV2Interface used by consumers.
+interface IVRFCoordinatorV2 {
+    function requestRandomWords(
+        bytes32 keyHash,
+        uint64 subId,
+        uint16 minimumRequestConfirmations,
+        uint32 callbackGasLimit,
+        uint32 numWords
+    ) external returns (uint256 requestId);
+}
+
+/**
+@notice A VRF v2 consumer, equivalent to one built on VRFConsumerBaseV2, for
+testing the ethier chaintest VRF v2 coordinator.
+ */
+contract TestableVRFConsumerV2 {
+    IVRFCoordinatorV2 public immutable coordinator;
+    uint64 public immutable subId;
+
+    constructor(IVRFCoordinatorV2 _coordinator, uint64 _subId) {
+        coordinator = _coordinator;
+        subId = _subId;
+    }
+
+    /// @notice ID of the most recent request.
+    uint256 public lastRequestId;
+
+    /// @notice All fulfilled words, available for Go testing.
+    mapping(uint256 => uint256[]) private _words;
+
+    function requestRandomWords(uint32 numWords) external {
+        lastRequestId = coordinator.requestRandomWords(
+            bytes32(0),
+            subId,
+            3,
+            200_000,
+            numWords
+        );
+    }
+
+    /// @notice Mirrors VRFConsumerBaseV2.rawFulfillRandomWords().
+    function rawFulfillRandomWords(
+        uint256 requestId,
+        uint256[] memory randomWords
+    ) external {
+        require(
+            msg.sender == address(coordinator),
+            "TestableVRFConsumerV2: only coordinator"
+        );
+        require(
+            _words[requestId].length == 0,
+            "TestableVRFConsumerV2: already fulfilled"
+        );
+        _words[requestId] = randomWords;
+    }
+
+    function words(uint256 requestId) external view returns (uint256[] memory) {
+        return _words[requestId];
+    }
+}
@@ -1,3 +1,3 @@
 package chainlink
 
-//go:generate ethier gen TestableVRFConsumerHelper.sol
+//go:generate ethier gen TestableVRFConsumerHelper.sol TestableVRFConsumerV2.sol
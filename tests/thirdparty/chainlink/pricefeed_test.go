@@ -0,0 +1,85 @@
+package chainlink
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/chaintest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAggregatorV3(t *testing.T) {
+	const (
+		deployer = iota
+		numAccounts
+	)
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+
+	const desc = "ETH / USD"
+	feed := chaintest.DeployAggregatorV3TB(t, sim, deployer, 8, desc, big.NewInt(1000e8))
+
+	if got, err := feed.Decimals(nil); err != nil || got != 8 {
+		t.Errorf("%T.Decimals() got %d, err %v; want 8, nil err", feed, got, err)
+	}
+	if got, err := feed.Description(nil); err != nil || got != desc {
+		t.Errorf("%T.Description() got %q, err %v; want %q, nil err", feed, got, err, desc)
+	}
+
+	latest := func(t *testing.T) chaintest.RoundData {
+		t.Helper()
+		r, err := feed.LatestRoundData(nil)
+		if err != nil {
+			t.Fatalf("%T.LatestRoundData() error %v", feed, err)
+		}
+		return chaintest.RoundData{
+			RoundID:         r.RoundId,
+			Answer:          r.Answer,
+			StartedAt:       r.StartedAt,
+			UpdatedAt:       r.UpdatedAt,
+			AnsweredInRound: r.AnsweredInRound,
+		}
+	}
+
+	t.Run("SetAnswer", func(t *testing.T) {
+		if got := latest(t); got.RoundID.Cmp(big.NewInt(1)) != 0 || got.Answer.Cmp(big.NewInt(1000e8)) != 0 {
+			t.Errorf("LatestRoundData() after deployment got round %d, answer %d; want 1, 1000e8", got.RoundID, got.Answer)
+		}
+
+		feed.SetAnswer(t, big.NewInt(1234e8))
+		got := latest(t)
+		if got.RoundID.Cmp(big.NewInt(2)) != 0 || got.Answer.Cmp(big.NewInt(1234e8)) != 0 || got.AnsweredInRound.Cmp(got.RoundID) != 0 {
+			t.Errorf("LatestRoundData() after SetAnswer(1234e8) got %+v; want round 2, answer 1234e8, answered in round 2", got)
+		}
+		if got.UpdatedAt.Sign() == 0 {
+			t.Error("LatestRoundData() after SetAnswer() got zero UpdatedAt")
+		}
+	})
+
+	t.Run("SetRoundData", func(t *testing.T) {
+		want := chaintest.RoundData{
+			RoundID:         big.NewInt(42),
+			Answer:          big.NewInt(-1),
+			StartedAt:       big.NewInt(100),
+			UpdatedAt:       big.NewInt(200),
+			AnsweredInRound: big.NewInt(41),
+		}
+		feed.SetRoundData(t, want)
+
+		if diff := cmp.Diff(want, latest(t), ethtest.Comparers()...); diff != "" {
+			t.Errorf("LatestRoundData() after SetRoundData() diff (-want +got):\n%s", diff)
+		}
+
+		r, err := feed.GetRoundData(nil, want.RoundID)
+		if err != nil {
+			t.Fatalf("%T.GetRoundData(%d) error %v", feed, want.RoundID, err)
+		}
+		if r.Answer.Cmp(want.Answer) != 0 {
+			t.Errorf("%T.GetRoundData(%d) got answer %d; want %d", feed, want.RoundID, r.Answer, want.Answer)
+		}
+
+		if _, err := feed.GetRoundData(nil, big.NewInt(7)); err == nil {
+			t.Errorf("%T.GetRoundData(<unset round>) got nil error; want error", feed)
+		}
+	})
+}
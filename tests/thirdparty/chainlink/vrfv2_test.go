@@ -0,0 +1,59 @@
+package chainlink
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/chaintest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVRFCoordinatorV2(t *testing.T) {
+	const (
+		deployer = iota
+		numAccounts
+	)
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	vrf := chaintest.DeployVRFCoordinatorV2TB(t, sim)
+
+	subID := vrf.CreateSubscriptionTB(t, deployer)
+	addr, _, consumer, err := DeployTestableVRFConsumerV2(sim.Acc(deployer), sim, chaintest.VRFCoordinatorV2Address(), subID)
+	if err != nil {
+		t.Fatalf("DeployTestableVRFConsumerV2() error %v", err)
+	}
+
+	t.Run("unregistered consumer", func(t *testing.T) {
+		if _, err := consumer.RequestRandomWords(sim.Acc(deployer), 1); err == nil {
+			t.Errorf("%T.RequestRandomWords() before AddConsumer(); got nil error; want error", consumer)
+		}
+	})
+
+	vrf.AddConsumerTB(t, deployer, subID, addr)
+
+	for _, numWords := range []uint32{1, 3} {
+		tx := sim.Must(t, "RequestRandomWords(%d)", numWords)(consumer.RequestRandomWords(sim.Acc(deployer), numWords))
+
+		ids := vrf.RequestIDs(t, tx)
+		if len(ids) != 1 {
+			t.Fatalf("%T.RequestIDs(<RequestRandomWords() tx>) got %d IDs; want 1", vrf, len(ids))
+		}
+		if last, err := consumer.LastRequestId(nil); err != nil || last.Cmp(ids[0]) != 0 {
+			t.Errorf("%T.LastRequestId() got %d, err %v; want %d, nil err", consumer, last, err, ids[0])
+		}
+
+		var words []*big.Int
+		for i := uint32(0); i < numWords; i++ {
+			words = append(words, big.NewInt(int64(42+i)))
+		}
+		vrf.FulfillRandomWords(t, ids[0], words)
+
+		got, err := consumer.Words(nil, ids[0])
+		if err != nil {
+			t.Fatalf("%T.Words(%d) error %v", consumer, ids[0], err)
+		}
+		if diff := cmp.Diff(words, got, ethtest.Comparers()...); diff != "" {
+			t.Errorf("%T.Words(%d) after fulfillment; diff (-want +got):\n%s", consumer, ids[0], diff)
+		}
+	}
+}
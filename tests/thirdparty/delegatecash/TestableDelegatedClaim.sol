@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../../contracts/thirdparty/delegatecash/IDelegationRegistry.sol";
+import "@openzeppelin/contracts/token/ERC721/ERC721.sol";
+
+/**
+@notice An NFT whose holders may claim once per token, either directly or via a
+hot wallet to which they have delegated through delegate.cash.
+ */
+contract TestableDelegatedClaim is ERC721 {
+    IDelegationRegistry public immutable registry;
+
+    /// @notice Address to which each token's claim was sent, if claimed.
+    mapping(uint256 => address) public claimedBy;
+
+    constructor(IDelegationRegistry _registry) ERC721("Token", "JRR") {
+        registry = _registry;
+    }
+
+    function mint(address to, uint256 tokenId) external {
+        _mint(to, tokenId);
+    }
+
+    function claim(uint256 tokenId) external {
+        address vault = ownerOf(tokenId);
+        require(
+            msg.sender == vault ||
+                registry.checkDelegateForToken(
+                    msg.sender,
+                    vault,
+                    address(this),
+                    tokenId
+                ),
+            "TestableDelegatedClaim: not owner nor delegate"
+        );
+        require(
+            claimedBy[tokenId] == address(0),
+            "TestableDelegatedClaim: already claimed"
+        );
+        claimedBy[tokenId] = msg.sender;
+    }
+}
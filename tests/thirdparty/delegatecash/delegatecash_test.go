@@ -0,0 +1,125 @@
+package delegatecash
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/delegatetest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/h-fam/errdiff"
+)
+
+//go:generate ethier gen TestableDelegatedClaim.sol
+
+const (
+	deployer = iota
+	vault
+	hot
+	numAccounts
+)
+
+const notDelegate = "TestableDelegatedClaim: not owner nor delegate"
+
+func TestDelegatedClaim(t *testing.T) {
+	var (
+		token0 = big.NewInt(0)
+		token1 = big.NewInt(1)
+		other  = common.HexToAddress("0x01")
+	)
+
+	tests := []struct {
+		name string
+		// delegate is called before claiming, with the claim contract's address.
+		delegate       func(*testing.T, *ethtest.SimulatedBackend, common.Address)
+		claimer        int
+		errDiffAgainst interface{}
+	}{
+		{
+			name:    "vault claims directly",
+			claimer: vault,
+		},
+		{
+			name:           "no delegation",
+			claimer:        hot,
+			errDiffAgainst: notDelegate,
+		},
+		{
+			name: "delegated for all",
+			delegate: func(t *testing.T, sim *ethtest.SimulatedBackend, _ common.Address) {
+				delegatetest.DelegateForAllTB(t, sim, vault, sim.Addr(hot), true)
+			},
+			claimer: hot,
+		},
+		{
+			name: "delegation for all revoked",
+			delegate: func(t *testing.T, sim *ethtest.SimulatedBackend, _ common.Address) {
+				delegatetest.DelegateForAllTB(t, sim, vault, sim.Addr(hot), true)
+				delegatetest.DelegateForAllTB(t, sim, vault, sim.Addr(hot), false)
+			},
+			claimer:        hot,
+			errDiffAgainst: notDelegate,
+		},
+		{
+			name: "delegated for contract",
+			delegate: func(t *testing.T, sim *ethtest.SimulatedBackend, claim common.Address) {
+				delegatetest.DelegateForContractTB(t, sim, vault, sim.Addr(hot), claim, true)
+			},
+			claimer: hot,
+		},
+		{
+			name: "delegated for other contract",
+			delegate: func(t *testing.T, sim *ethtest.SimulatedBackend, _ common.Address) {
+				delegatetest.DelegateForContractTB(t, sim, vault, sim.Addr(hot), other, true)
+			},
+			claimer:        hot,
+			errDiffAgainst: notDelegate,
+		},
+		{
+			name: "delegated for token",
+			delegate: func(t *testing.T, sim *ethtest.SimulatedBackend, claim common.Address) {
+				delegatetest.DelegateForTokenTB(t, sim, vault, sim.Addr(hot), claim, token0, true)
+			},
+			claimer: hot,
+		},
+		{
+			name: "delegated for other token",
+			delegate: func(t *testing.T, sim *ethtest.SimulatedBackend, claim common.Address) {
+				delegatetest.DelegateForTokenTB(t, sim, vault, sim.Addr(hot), claim, token1, true)
+			},
+			claimer:        hot,
+			errDiffAgainst: notDelegate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+			delegatetest.DeployRegistryTB(t, sim)
+
+			addr, _, claim, err := DeployTestableDelegatedClaim(sim.Acc(deployer), sim, delegatetest.RegistryAddress())
+			if err != nil {
+				t.Fatalf("DeployTestableDelegatedClaim() error %v", err)
+			}
+			for _, id := range []*big.Int{token0, token1} {
+				sim.Must(t, "Mint(vault, %d)", id)(claim.Mint(sim.Acc(deployer), sim.Addr(vault), id))
+			}
+
+			if tt.delegate != nil {
+				tt.delegate(t, sim, addr)
+			}
+
+			_, err = claim.Claim(sim.Acc(tt.claimer), token0)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Fatalf("Claim(%d) as account %d; %s", token0, tt.claimer, diff)
+			}
+			if tt.errDiffAgainst != nil {
+				return
+			}
+
+			if got, err := claim.ClaimedBy(nil, token0); err != nil || got != sim.Addr(tt.claimer) {
+				t.Errorf("ClaimedBy(%d) got %v, err %v; want %v, nil err", token0, got, err, sim.Addr(tt.claimer))
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package ens
+
+import (
+	"context"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/enstest"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	deployer = iota
+	alice
+	bob
+	numAccounts
+)
+
+func TestNamehash(t *testing.T) {
+	// Test vectors from EIP-137.
+	tests := []struct {
+		name string
+		want common.Hash
+	}{
+		{
+			name: "",
+			want: common.Hash{},
+		},
+		{
+			name: "eth",
+			want: common.HexToHash("0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"),
+		},
+		{
+			name: "foo.eth",
+			want: common.HexToHash("0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"),
+		},
+	}
+
+	for _, tt := range tests {
+		if got := enstest.Namehash(tt.name); got != tt.want {
+			t.Errorf("Namehash(%q) got %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestENS(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	reg := enstest.DeployTB(t, sim)
+
+	enstest.RegisterTB(t, sim, "alice", sim.Addr(alice))
+
+	t.Run("forward", func(t *testing.T) {
+		tests := []struct {
+			name string
+			want common.Address
+		}{
+			{
+				name: "alice.eth",
+				want: sim.Addr(alice),
+			},
+			{
+				name: "bob.eth",
+				want: common.Address{},
+			},
+		}
+
+		for _, tt := range tests {
+			got, err := enstest.Resolve(ctx, sim, tt.name)
+			if err != nil || got != tt.want {
+				t.Errorf("Resolve(%q) got %v, err %v; want %v, nil err", tt.name, got, err, tt.want)
+			}
+		}
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		node := enstest.Namehash("alice.eth")
+		got, err := reg.Owner(nil, node)
+		if err != nil || got != sim.Addr(alice) {
+			t.Errorf("%T.Owner(namehash(alice.eth)) got %v, err %v; want %v, nil err", reg, got, err, sim.Addr(alice))
+		}
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		enstest.SetReverseNameTB(t, sim, alice, "alice.eth")
+
+		tests := []struct {
+			addr common.Address
+			want string
+		}{
+			{
+				addr: sim.Addr(alice),
+				want: "alice.eth",
+			},
+			{
+				addr: sim.Addr(bob),
+				want: "",
+			},
+		}
+
+		for _, tt := range tests {
+			got, err := enstest.ReverseResolve(ctx, sim, tt.addr)
+			if err != nil || got != tt.want {
+				t.Errorf("ReverseResolve(%v) got %q, err %v; want %q, nil err", tt.addr, got, err, tt.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../../contracts/erc721/ERC721ACommon.sol";
+
+/// @notice An ethier NFT with a paid, public mint.
+contract TestableERC721Mint is ERC721ACommon {
+    uint256 public constant PRICE = 0.1 ether;
+
+    // solhint-disable-next-line no-empty-blocks
+    constructor() ERC721ACommon("Token", "JRR") {}
+
+    function mint(uint256 n) external payable {
+        require(msg.value == n * PRICE, "TestableERC721Mint: bad payment");
+        _safeMint(msg.sender, n);
+    }
+}
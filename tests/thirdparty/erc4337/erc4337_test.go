@@ -0,0 +1,130 @@
+package erc4337
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/erc4337test"
+	"github.com/divergencetech/ethier/ethtest/erc4337test/erc4337testabi"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//go:generate ethier gen TestableERC721Mint.sol
+
+const (
+	deployer = iota
+	bundler
+	numAccounts
+)
+
+type env struct {
+	sim        *ethtest.SimulatedBackend
+	entryPoint *erc4337testabi.EntryPoint
+	account    *erc4337test.Account
+	nftAddr    common.Address
+	nft        *TestableERC721Mint
+}
+
+func deploy(t *testing.T) env {
+	t.Helper()
+
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	ep := erc4337test.DeployTB(t, sim)
+
+	owner, err := eth.NewSigner(256)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(256) error %v", err)
+	}
+	acc := erc4337test.NewAccountTB(t, sim, owner, big.NewInt(0))
+
+	// The account pays the EntryPoint for gas, and the NFT for mints, from its
+	// own balance, so is funded before it is deployed.
+	sim.Must(t, "fund smart account")(
+		bind.NewBoundContract(acc.Address, abi.ABI{}, sim, sim, sim).Transfer(sim.WithValueFrom(deployer, eth.Ether(1))),
+	)
+
+	addr, _, nft, err := DeployTestableERC721Mint(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableERC721Mint() error %v", err)
+	}
+	return env{
+		sim:        sim,
+		entryPoint: ep,
+		account:    acc,
+		nftAddr:    addr,
+		nft:        nft,
+	}
+}
+
+func TestUserOpHash(t *testing.T) {
+	e := deploy(t)
+	ep := e.entryPoint
+
+	op := e.account.UserOperationTB(t, e.nftAddr, big.NewInt(0), nil)
+	got, err := erc4337test.UserOpHash(op, erc4337test.Addresses().EntryPoint, e.sim.Blockchain().Config().ChainID)
+	if err != nil {
+		t.Fatalf("UserOpHash() error %v", err)
+	}
+
+	want, err := ep.GetUserOpHash(nil, op)
+	if err != nil {
+		t.Fatalf("%T.GetUserOpHash() error %v", ep, err)
+	}
+	if got != common.Hash(want) {
+		t.Errorf("UserOpHash() got %v; want %T.GetUserOpHash() = %v", got, ep, common.Hash(want))
+	}
+}
+
+func TestMintFromSmartAccount(t *testing.T) {
+	e := deploy(t)
+	sim, acc, nft := e.sim, e.account, e.nft
+
+	parsed, err := TestableERC721MintMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("%T.GetAbi() error %v", TestableERC721MintMetaData, err)
+	}
+
+	for i, n := range []int64{2, 1} {
+		data, err := parsed.Pack("mint", big.NewInt(n))
+		if err != nil {
+			t.Fatalf("%T.Pack(\"mint\", %d) error %v", parsed, n, err)
+		}
+		op := acc.UserOperationTB(t, e.nftAddr, eth.EtherFraction(n, 10), data)
+		if deployed := len(op.InitCode) == 0; deployed != (i > 0) {
+			t.Errorf("UserOperation() %d got empty InitCode = %t; want %t", i, deployed, i > 0)
+		}
+		erc4337test.HandleOpsTB(t, sim, bundler, op)
+	}
+
+	got, err := nft.BalanceOf(nil, acc.Address)
+	if err != nil {
+		t.Fatalf("%T.BalanceOf(<smart account>) error %v", nft, err)
+	}
+	if want := int64(3); got.Cmp(big.NewInt(want)) != 0 {
+		t.Errorf("%T.BalanceOf(<smart account>) got %d; want %d", nft, got, want)
+	}
+}
+
+func TestInvalidSignature(t *testing.T) {
+	e := deploy(t)
+	sim := e.sim
+
+	op := e.account.UserOperationTB(t, e.nftAddr, big.NewInt(0), nil)
+
+	imposter, err := eth.NewSigner(256)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(256) error %v", err)
+	}
+	op, err = erc4337test.SignUserOperation(sim, imposter, op)
+	if err != nil {
+		t.Fatalf("SignUserOperation() error %v", err)
+	}
+
+	if _, err := erc4337test.HandleOps(sim, bundler, op); err == nil {
+		t.Errorf("HandleOps(<UserOperation signed by non-owner>) got nil error; want error")
+	}
+}
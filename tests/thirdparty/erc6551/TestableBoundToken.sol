@@ -0,0 +1,14 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/token/ERC721/ERC721.sol";
+
+/// @notice An NFT to which ERC-6551 accounts are bound.
+contract TestableBoundToken is ERC721 {
+    constructor() ERC721("Token", "JRR") {} // solhint-disable-line no-empty-blocks
+
+    function mint(address to, uint256 tokenId) external {
+        _mint(to, tokenId);
+    }
+}
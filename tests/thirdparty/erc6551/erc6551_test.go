@@ -0,0 +1,105 @@
+package erc6551
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/erc6551test"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/h-fam/errdiff"
+)
+
+//go:generate ethier gen TestableBoundToken.sol
+
+const (
+	deployer = iota
+	holder
+	buyer
+	numAccounts
+)
+
+func TestTokenBoundAccount(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	erc6551test.DeployTB(t, sim)
+
+	tokenAddr, _, token, err := DeployTestableBoundToken(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableBoundToken() error %v", err)
+	}
+	tokenID := big.NewInt(42)
+	sim.Must(t, "Mint(%d)", tokenID)(token.Mint(sim.Acc(deployer), sim.Addr(holder), tokenID))
+
+	wantAddr, err := erc6551test.AccountAddress(sim, tokenAddr, tokenID)
+	if err != nil {
+		t.Fatalf("erc6551test.AccountAddress() error %v", err)
+	}
+	acc := erc6551test.CreateAccountTB(t, sim, deployer, tokenAddr, tokenID)
+	if acc.Address != wantAddr {
+		t.Errorf("erc6551test.CreateAccount() got address %v; want %v as returned by AccountAddress()", acc.Address, wantAddr)
+	}
+	if again := erc6551test.CreateAccountTB(t, sim, buyer, tokenAddr, tokenID); again.Address != acc.Address {
+		t.Errorf("Second erc6551test.CreateAccount() got address %v; want idempotent %v", again.Address, acc.Address)
+	}
+
+	t.Run("token", func(t *testing.T) {
+		got, err := acc.Token(nil)
+		if err != nil {
+			t.Fatalf("%T.Token() error %v", acc, err)
+		}
+		if got.TokenContract != tokenAddr || got.TokenId.Cmp(tokenID) != 0 || got.ChainId.Cmp(sim.Blockchain().Config().ChainID) != 0 {
+			t.Errorf("%T.Token() got %+v; want token %d of %v on simulated chain", acc, got, tokenID, tokenAddr)
+		}
+	})
+
+	// Fund the account and have it pay the recipient.
+	sim.Must(t, "fund token-bound account")(
+		bind.NewBoundContract(acc.Address, abi.ABI{}, sim, sim, sim).Transfer(sim.WithValueFrom(deployer, eth.Ether(2))),
+	)
+	recipient := common.HexToAddress("0x0123456789")
+	pay := func(from int) error {
+		_, err := acc.Execute(from, recipient, eth.Ether(1), nil)
+		return err
+	}
+	const invalidSigner = "SimulatedERC6551Account: invalid signer"
+
+	for _, step := range []struct {
+		name           string
+		from           int
+		errDiffAgainst interface{}
+	}{
+		{
+			name:           "non-holder",
+			from:           buyer,
+			errDiffAgainst: invalidSigner,
+		},
+		{
+			name: "holder",
+			from: holder,
+		},
+	} {
+		if diff := errdiff.Check(pay(step.from), step.errDiffAgainst); diff != "" {
+			t.Errorf("%T.Execute() as %s; %s", acc, step.name, diff)
+		}
+	}
+
+	if got, err := sim.BalanceAt(ctx, recipient, nil); err != nil || got.Cmp(eth.Ether(1)) != 0 {
+		t.Errorf("BalanceAt(<recipient>) got %d, err %v; want 1 ETH, nil err", got, err)
+	}
+
+	// Control follows the token.
+	sim.Must(t, "TransferFrom(holder, buyer, %d)", tokenID)(token.TransferFrom(sim.Acc(holder), sim.Addr(holder), sim.Addr(buyer), tokenID))
+	if diff := errdiff.Check(pay(holder), invalidSigner); diff != "" {
+		t.Errorf("%T.Execute() as previous holder; %s", acc, diff)
+	}
+	acc.ExecuteTB(t, buyer, recipient, eth.Ether(1), nil)
+
+	if got, err := acc.State(nil); err != nil || got.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("%T.State() got %d, err %v; want 2, nil err", acc, got, err)
+	}
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../../contracts/sales/FixedPriceSeller.sol";
+
+/// @notice A FixedPriceSeller for testing purchases via fiat minting services.
+contract TestableRelayedSale is FixedPriceSeller {
+    constructor(uint256 price, uint256 maxPerAddress)
+        FixedPriceSeller(
+            price,
+            SellerConfig(100, maxPerAddress, 0, 0, false, false, false),
+            payable(msg.sender)
+        )
+    {} // solhint-disable-line no-empty-blocks
+
+    /// @notice Number of items purchased for each recipient.
+    mapping(address => uint256) public purchased;
+
+    function purchase(address to, uint256 n) external payable {
+        _purchase(to, n);
+    }
+
+    function _handlePurchase(
+        address to,
+        uint256 n,
+        bool
+    ) internal override {
+        purchased[to] += n;
+    }
+}
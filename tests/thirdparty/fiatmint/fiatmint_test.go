@@ -0,0 +1,83 @@
+package fiatmint
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/fiatminttest"
+	"github.com/divergencetech/ethier/ethtest/fiatminttest/fiatminttestabi"
+	"github.com/divergencetech/ethier/ethtest/revert"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//go:generate ethier gen TestableRelayedSale.sol
+
+const (
+	deployer = iota
+	cardBuyer0
+	cardBuyer1
+	numAccounts
+)
+
+func TestRelayedPurchases(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	fiatminttest.DeployTB(t, sim)
+
+	price := eth.EtherFraction(1, 10)
+	const maxPerAddress = 2
+	addr, _, sale, err := DeployTestableRelayedSale(sim.Acc(deployer), sim, price, big.NewInt(maxPerAddress))
+	if err != nil {
+		t.Fatalf("DeployTestableRelayedSale() error %v", err)
+	}
+	saleABI, err := TestableRelayedSaleMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("%T.GetAbi() error %v", TestableRelayedSaleMetaData, err)
+	}
+
+	// Overpayment is refunded to the relayer, not the buyer.
+	relayerBefore := sim.BalanceOf(ctx, t, fiatminttest.RelayerAddress())
+	fiatminttest.PurchaseTB(t, sim, addr, saleABI, eth.Ether(1), "purchase", sim.Addr(cardBuyer0), big.NewInt(maxPerAddress))
+
+	if got, err := sale.Purchased(nil, sim.Addr(cardBuyer0)); err != nil || got.Cmp(big.NewInt(maxPerAddress)) != 0 {
+		t.Errorf("Purchased(cardBuyer0) got %d, err %v; want %d, nil err", got, err, maxPerAddress)
+	}
+	wantRefund := new(big.Int).Sub(eth.Ether(1), new(big.Int).Mul(price, big.NewInt(maxPerAddress)))
+	if got := new(big.Int).Sub(sim.BalanceOf(ctx, t, fiatminttest.RelayerAddress()), relayerBefore); got.Cmp(wantRefund) != 0 {
+		t.Errorf("Relayer balance change after overpayment; got %d; want %d refund", got, wantRefund)
+	}
+
+	// The Seller's per-address limit also applies to the relayer as msg.sender,
+	// so is shared by all buyers using the service.
+	_, err = fiatminttest.Purchase(sim, addr, saleABI, price, "purchase", sim.Addr(cardBuyer1), big.NewInt(1))
+	if diff := revert.Checker("Seller: Sender limit").Diff(nil, err); diff != "" {
+		t.Errorf("Relayed purchase for second buyer after relayer reached limit; %s", diff)
+	}
+
+	// Direct purchases are unaffected.
+	sim.Must(t, "Purchase() directly")(sale.Purchase(sim.WithValueFrom(cardBuyer1, price), sim.Addr(cardBuyer1), big.NewInt(1)))
+}
+
+func TestOnlyOperatorRelays(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	fiatminttest.DeployTB(t, sim)
+
+	relayer, err := fiatminttestabi.NewSimulatedMintRelayerTransactor(fiatminttest.RelayerAddress(), sim)
+	if err != nil {
+		t.Fatalf("fiatminttestabi.NewSimulatedMintRelayerTransactor() error %v", err)
+	}
+	if diff := revert.Checker("SimulatedMintRelayer: only operator").Diff(relayer.Relay(sim.Acc(cardBuyer0), common.Address{}, nil)); diff != "" {
+		t.Errorf("Relay() as non-operator; %s", diff)
+	}
+
+	op, err := fiatminttest.OperatorAddress(sim)
+	if err != nil {
+		t.Fatalf("fiatminttest.OperatorAddress() error %v", err)
+	}
+	if op == (common.Address{}) || op == fiatminttest.RelayerAddress() {
+		t.Errorf("fiatminttest.OperatorAddress() got %v; want non-zero EOA distinct from relayer", op)
+	}
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/metatx/ERC2771Context.sol";
+
+/// @notice Records the sender of calls, as determined by ERC2771Context.
+contract TestableERC2771 is ERC2771Context {
+    address public lastSender;
+
+    // solhint-disable-next-line no-empty-blocks
+    constructor(address forwarder) ERC2771Context(forwarder) {}
+
+    function record() external {
+        lastSender = _msgSender();
+    }
+}
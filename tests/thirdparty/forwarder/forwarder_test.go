@@ -0,0 +1,94 @@
+package forwarder
+
+import (
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/forwardertest"
+)
+
+//go:generate ethier gen TestableERC2771.sol
+
+const (
+	deployer = iota
+	relayer
+	numAccounts
+)
+
+func TestTrustedForwarder(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	fwd := forwardertest.DeployMinimalForwarderTB(t, sim)
+
+	addr, _, rec, err := DeployTestableERC2771(sim.Acc(deployer), sim, forwardertest.ForwarderAddress())
+	if err != nil {
+		t.Fatalf("DeployTestableERC2771() error %v", err)
+	}
+	parsed, err := TestableERC2771MetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("%T.GetAbi() error %v", TestableERC2771MetaData, err)
+	}
+	record, err := parsed.Pack("record")
+	if err != nil {
+		t.Fatalf("%T.Pack(\"record\") error %v", parsed, err)
+	}
+
+	user, err := eth.NewSigner(256)
+	if err != nil {
+		t.Fatalf("eth.NewSigner(256) error %v", err)
+	}
+
+	t.Run("direct call", func(t *testing.T) {
+		sim.Must(t, "Record()")(rec.Record(sim.Acc(deployer)))
+		if got, err := rec.LastSender(nil); err != nil || got != sim.Addr(deployer) {
+			t.Errorf("LastSender() after direct call got %v, err %v; want %v, nil err", got, err, sim.Addr(deployer))
+		}
+	})
+
+	t.Run("relayed", func(t *testing.T) {
+		// Twice to confirm nonce handling.
+		for i := 0; i < 2; i++ {
+			fwd.RelayTB(t, relayer, user, addr, record)
+			if got, err := rec.LastSender(nil); err != nil || got != user.Address() {
+				t.Errorf("LastSender() after relayed call got %v, err %v; want signer %v, nil err", got, err, user.Address())
+			}
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		req, err := fwd.NewRequest(user.Address(), addr, record)
+		if err != nil {
+			t.Fatalf("%T.NewRequest() error %v", fwd, err)
+		}
+		sig, err := eth.SignForwardRequest(user, fwd.Domain(), req)
+		if err != nil {
+			t.Fatalf("eth.SignForwardRequest() error %v", err)
+		}
+
+		sim.Must(t, "Relay()")(fwd.Relay(relayer, req, sig))
+		if _, err := fwd.Relay(relayer, req, sig); err == nil {
+			t.Errorf("%T.Relay() with replayed request got nil error; want error", fwd)
+		}
+	})
+
+	t.Run("wrong signer", func(t *testing.T) {
+		req, err := fwd.NewRequest(user.Address(), addr, record)
+		if err != nil {
+			t.Fatalf("%T.NewRequest() error %v", fwd, err)
+		}
+		imposter, err := eth.NewSigner(256)
+		if err != nil {
+			t.Fatalf("eth.NewSigner(256) error %v", err)
+		}
+		imposterReq := req
+		imposterReq.From = imposter.Address()
+		sig, err := eth.SignForwardRequest(imposter, fwd.Domain(), imposterReq)
+		if err != nil {
+			t.Fatalf("eth.SignForwardRequest() error %v", err)
+		}
+
+		if _, err := fwd.Relay(relayer, req, sig); err == nil {
+			t.Errorf("%T.Relay() with signature from non-sender got nil error; want error", fwd)
+		}
+	})
+}
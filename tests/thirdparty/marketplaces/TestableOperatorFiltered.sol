@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "@openzeppelin/contracts/access/Ownable.sol";
+import "@openzeppelin/contracts/token/ERC721/ERC721.sol";
+import "@openzeppelin/contracts/token/ERC1155/ERC1155.sol";
+
+/**
+@notice An ERC721 that blocks operators, either from being approved or from
+transferring tokens, once filtered by its owner.
+ */
+contract TestableOperatorFilteredERC721 is ERC721, Ownable {
+    mapping(address => bool) public filtered;
+
+    constructor() ERC721("Token", "JRR") {} // solhint-disable-line no-empty-blocks
+
+    function setFiltered(address operator, bool filter) external onlyOwner {
+        filtered[operator] = filter;
+    }
+
+    function mint(address to, uint256 tokenId) external {
+        _mint(to, tokenId);
+    }
+
+    function setApprovalForAll(address operator, bool approved)
+        public
+        override
+    {
+        require(
+            !approved || !filtered[operator],
+            "TestableOperatorFiltered: operator filtered"
+        );
+        super.setApprovalForAll(operator, approved);
+    }
+
+    function _beforeTokenTransfer(
+        address from,
+        address to,
+        uint256 tokenId
+    ) internal override {
+        require(
+            from == address(0) || from == msg.sender || !filtered[msg.sender],
+            "TestableOperatorFiltered: operator filtered"
+        );
+        super._beforeTokenTransfer(from, to, tokenId);
+    }
+}
+
+/// @notice A minimal ERC1155 for testing LooksRare's ERC1155 transfer manager.
+contract TestableERC1155 is ERC1155 {
+    constructor() ERC1155("") {} // solhint-disable-line no-empty-blocks
+
+    function mint(
+        address to,
+        uint256 id,
+        uint256 amount
+    ) external {
+        _mint(to, id, amount, "");
+    }
+}
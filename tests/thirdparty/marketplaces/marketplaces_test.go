@@ -0,0 +1,150 @@
+package marketplaces
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/markettest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/h-fam/errdiff"
+)
+
+//go:generate ethier gen TestableOperatorFiltered.sol
+
+const (
+	deployer = iota
+	seller
+	buyer
+	exchange
+	numAccounts
+)
+
+const filteredMsg = "TestableOperatorFiltered: operator filtered"
+
+func TestOperatorsDeployed(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, 0)
+
+	for _, op := range markettest.Operators() {
+		code, err := sim.CodeAt(context.Background(), op, nil)
+		if err != nil || len(code) == 0 {
+			t.Errorf("CodeAt(%v) got %d bytes, err %v; want non-empty code, nil err", op, len(code), err)
+		}
+	}
+}
+
+func TestERC721Transfers(t *testing.T) {
+	type transfer func(*ethtest.SimulatedBackend, int, common.Address, common.Address, common.Address, *big.Int) (*types.Transaction, error)
+
+	marketplaces := []struct {
+		name     string
+		operator common.Address
+		transfer transfer
+	}{
+		{
+			name:     "LooksRare",
+			operator: markettest.LooksRareTransferManagerERC721,
+			transfer: markettest.LooksRareTransferERC721,
+		},
+		{
+			name:     "Blur",
+			operator: markettest.BlurExecutionDelegate,
+			transfer: markettest.BlurTransferERC721,
+		},
+	}
+
+	tests := []struct {
+		name           string
+		approve        bool
+		filter         bool
+		errDiffAgainst interface{}
+	}{
+		{
+			name: "not approved",
+			// The exact message differs between OpenZeppelin versions.
+			errDiffAgainst: "ERC721:",
+		},
+		{
+			name:    "approved",
+			approve: true,
+		},
+		{
+			name:           "filtered after approval",
+			approve:        true,
+			filter:         true,
+			errDiffAgainst: filteredMsg,
+		},
+	}
+
+	for _, m := range marketplaces {
+		for _, tt := range tests {
+			t.Run(m.name+"/"+tt.name, func(t *testing.T) {
+				sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+				addr, _, nft, err := DeployTestableOperatorFilteredERC721(sim.Acc(deployer), sim)
+				if err != nil {
+					t.Fatalf("DeployTestableOperatorFilteredERC721() error %v", err)
+				}
+
+				tokenID := big.NewInt(42)
+				sim.Must(t, "Mint(%d)", tokenID)(nft.Mint(sim.Acc(deployer), sim.Addr(seller), tokenID))
+
+				if tt.approve {
+					sim.Must(t, "SetApprovalForAll(%v)", m.operator)(nft.SetApprovalForAll(sim.Acc(seller), m.operator, true))
+				}
+				if tt.filter {
+					sim.Must(t, "SetFiltered(%v)", m.operator)(nft.SetFiltered(sim.Acc(deployer), m.operator, true))
+				}
+
+				_, err = m.transfer(sim, exchange, addr, sim.Addr(seller), sim.Addr(buyer), tokenID)
+				if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+					t.Fatalf("%s transfer %s", m.name, diff)
+				}
+				if err != nil {
+					return
+				}
+
+				if got, err := nft.OwnerOf(nil, tokenID); err != nil || got != sim.Addr(buyer) {
+					t.Errorf("OwnerOf(%d) got %v, err %v; want %v, nil err", tokenID, got, err, sim.Addr(buyer))
+				}
+			})
+		}
+	}
+}
+
+func TestFilteredApproval(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	_, _, nft, err := DeployTestableOperatorFilteredERC721(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableOperatorFilteredERC721() error %v", err)
+	}
+
+	for _, op := range markettest.Operators() {
+		sim.Must(t, "SetFiltered(%v)", op)(nft.SetFiltered(sim.Acc(deployer), op, true))
+		if diff := revert.Checker(filteredMsg).Diff(nft.SetApprovalForAll(sim.Acc(seller), op, true)); diff != "" {
+			t.Errorf("SetApprovalForAll(%v, true) after filtering; %s", op, diff)
+		}
+	}
+}
+
+func TestLooksRareERC1155(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	addr, _, token, err := DeployTestableERC1155(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableERC1155() error %v", err)
+	}
+
+	id := big.NewInt(7)
+	sim.Must(t, "Mint(%d)", id)(token.Mint(sim.Acc(deployer), sim.Addr(seller), id, big.NewInt(10)))
+	sim.Must(t, "SetApprovalForAll(LooksRare)")(token.SetApprovalForAll(sim.Acc(seller), markettest.LooksRareTransferManagerERC1155, true))
+
+	markettest.LooksRareTransferERC1155TB(t, sim, exchange, addr, sim.Addr(seller), sim.Addr(buyer), id, big.NewInt(3))
+
+	for acc, want := range map[int]int64{seller: 7, buyer: 3} {
+		if got, err := token.BalanceOf(nil, sim.Addr(acc), id); err != nil || got.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("BalanceOf([account %d], %d) got %d, err %v; want %d, nil err", acc, id, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package multicall
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/multicalltest"
+	"github.com/divergencetech/ethier/ethtest/multicalltest/multicalltestabi"
+	"github.com/divergencetech/ethier/ethtest/wethtest"
+	"github.com/divergencetech/ethier/ethtest/wethtest/wethtestabi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	hodler0 = iota
+	hodler1
+	numAccounts
+)
+
+func TestMain(m *testing.M) {
+	multicalltest.Register()
+	wethtest.RegisterGenesis()
+	os.Exit(m.Run())
+}
+
+func TestMulticall3(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	m := multicalltest.NewTB(t, sim)
+
+	t.Run("chain ID", func(t *testing.T) {
+		got, err := m.GetChainId(nil)
+		if want := sim.Blockchain().Config().ChainID; err != nil || got.Cmp(want) != 0 {
+			t.Errorf("%T.GetChainId() got %d, err %v; want %d, nil err", m, got, err, want)
+		}
+	})
+
+	wethtest.GenesisWETHTB(t, sim)
+	wethtest.WrapETHTB(t, sim, hodler0, eth.Ether(1))
+	wethtest.WrapETHTB(t, sim, hodler1, eth.Ether(2))
+
+	weth, err := wethtestabi.IwETHMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("%T.GetAbi() error %v", wethtestabi.IwETHMetaData, err)
+	}
+	balanceOf := func(t *testing.T, acc int) multicalltestabi.Multicall3Call3 {
+		t.Helper()
+		data, err := weth.Pack("balanceOf", sim.Addr(acc))
+		if err != nil {
+			t.Fatalf("%T.Pack(\"balanceOf\", %v) error %v", weth, sim.Addr(acc), err)
+		}
+		return multicalltestabi.Multicall3Call3{
+			Target:   wethtest.MainnetAddress,
+			CallData: data,
+		}
+	}
+	// Withdrawing more than the Multicall3 contract's (zero) balance reverts.
+	withdraw, err := weth.Pack("withdraw", big.NewInt(1))
+	if err != nil {
+		t.Fatalf("%T.Pack(\"withdraw\", 1) error %v", weth, err)
+	}
+	failing := multicalltestabi.Multicall3Call3{
+		Target:   wethtest.MainnetAddress,
+		CallData: withdraw,
+	}
+
+	ctx := context.Background()
+
+	t.Run("balances", func(t *testing.T) {
+		got, err := multicalltest.Aggregate3(ctx, sim, balanceOf(t, hodler0), balanceOf(t, hodler1))
+		if err != nil {
+			t.Fatalf("Aggregate3() error %v", err)
+		}
+		want := []multicalltestabi.Multicall3Result{
+			{Success: true, ReturnData: common.LeftPadBytes(eth.Ether(1).Bytes(), 32)},
+			{Success: true, ReturnData: common.LeftPadBytes(eth.Ether(2).Bytes(), 32)},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Aggregate3(balanceOf(0), balanceOf(1)) diff (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("allowed failure", func(t *testing.T) {
+		allowed := failing
+		allowed.AllowFailure = true
+
+		got, err := multicalltest.Aggregate3(ctx, sim, allowed, balanceOf(t, hodler1))
+		if err != nil {
+			t.Fatalf("Aggregate3() error %v", err)
+		}
+		if len(got) != 2 || got[0].Success || !got[1].Success {
+			t.Errorf("Aggregate3(<allowed failure>, balanceOf(1)) got %+v; want [failure, success]", got)
+		}
+	})
+
+	t.Run("disallowed failure", func(t *testing.T) {
+		if _, err := multicalltest.Aggregate3(ctx, sim, failing, balanceOf(t, hodler1)); err == nil {
+			t.Errorf("Aggregate3(<disallowed failure>, …) got nil error; want error")
+		}
+	})
+}
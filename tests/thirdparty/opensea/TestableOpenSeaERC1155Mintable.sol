@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../../contracts/thirdparty/opensea/OpenSeaERC1155Mintable.sol";
+
+/// @notice A testable implementation of OpenSeaERC1155Mintable.
+contract TestableOpenSeaERC1155Mintable is OpenSeaERC1155Mintable {
+    constructor(uint256 _numFactoryOptions, string memory baseOptionURI)
+        OpenSeaERC1155Mintable("", "", _numFactoryOptions, baseOptionURI)
+    {} // solhint-disable-line no-empty-blocks
+
+    /**
+    @notice Required override to indicate if an amount of an option can
+    currently be minted.
+     */
+    function factoryCanMint(uint256 optionId, uint256 amount)
+        public
+        view
+        override
+        returns (bool)
+    {
+        return amount <= mintable[optionId];
+    }
+
+    mapping(uint256 => uint256) public mintable;
+
+    /// @notice Controls values returned by factoryCanMint().
+    function setMintable(uint256 optionId, uint256 amount) public {
+        mintable[optionId] = amount;
+    }
+
+    /// @notice Records calls to _factoryMint().
+    struct Mint {
+        uint256 optionId;
+        address to;
+        uint256 amount;
+    }
+    Mint[] public mints;
+
+    function numMinted() external view returns (uint256) {
+        return mints.length;
+    }
+
+    /// @notice Required override to perform actual minting.
+    function _factoryMint(
+        uint256 optionId,
+        address to,
+        uint256 amount
+    ) internal override {
+        mints.push(Mint({optionId: optionId, to: to, amount: amount}));
+    }
+
+    /**
+    @dev Workaround for a bug in geth's abigen / bind package that doesn't
+    create types unless they're used in function signatures.
+     */
+    // solhint-disable-next-line no-empty-blocks
+    function abigenBugHack(Mint memory) external pure {}
+}
@@ -1,3 +1,3 @@
 package opensea
 
-//go:generate ethier gen TestableOpenSeaMintable.sol
+//go:generate ethier gen TestableOpenSeaMintable.sol TestableOpenSeaERC1155Mintable.sol
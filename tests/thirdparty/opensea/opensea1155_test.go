@@ -0,0 +1,366 @@
+package opensea
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/openseatest"
+	"github.com/divergencetech/ethier/ethtest/revert"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/h-fam/errdiff"
+)
+
+func deploy1155(t *testing.T, numOptions int64, baseOptionURI string) (*ethtest.SimulatedBackend, *TestableOpenSeaERC1155Mintable, *OpenSeaERC1155Factory) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+
+	openseatest.DeployProxyRegistryTB(t, sim)
+	openseatest.SetProxyTB(t, sim, sim.Addr(deployer), sim.Addr(proxy))
+
+	_, _, nft, err := DeployTestableOpenSeaERC1155Mintable(
+		sim.Acc(deployer), sim,
+		big.NewInt(numOptions),
+		baseOptionURI,
+	)
+	if err != nil {
+		t.Fatalf("DeployTestableOpenSeaERC1155Mintable(%d, %q) error %v", numOptions, baseOptionURI, err)
+	}
+
+	addr, err := nft.Factory(nil)
+	if err != nil {
+		t.Fatalf("%T.Factory() error %v", nft, err)
+	}
+	factory, err := NewOpenSeaERC1155Factory(addr, sim)
+	if err != nil {
+		t.Fatalf("NewOpenSeaERC1155Factory([address from TestableOpenSeaERC1155Mintable]) error %v", err)
+	}
+
+	return sim, nft, factory
+}
+
+func TestERC1155FactoryReadOnly(t *testing.T) {
+	const (
+		numOptions = 5
+		baseURI    = "option/"
+	)
+	sim, nft, factory := deploy1155(t, numOptions, baseURI)
+
+	t.Run("numOptions propagated from primary contract", func(t *testing.T) {
+		got, err := factory.NumOptions(nil)
+		if want := big.NewInt(numOptions); err != nil || got.Cmp(want) != 0 {
+			t.Errorf("%T.NumOptions() got %d, err = %v; want %d, nil err", factory, got, err, want)
+		}
+	})
+
+	t.Run("canMint propagated from primary contract", func(t *testing.T) {
+		for i := int64(0); i < numOptions+5; i++ {
+			mintable := i % 3
+			sim.Must(t, "SetMintable(%d, %d)", i, mintable)(nft.SetMintable(sim.Acc(deployer), big.NewInt(i), big.NewInt(mintable)))
+
+			for amount := int64(1); amount <= 3; amount++ {
+				got, err := factory.CanMint(nil, big.NewInt(i), big.NewInt(amount))
+				if want := amount <= mintable && i < numOptions; err != nil || got != want {
+					t.Errorf("%T.CanMint(%d, %d) after setting %d mintable on primary contract; got %t, err = %v; want %t, nil err", factory, i, amount, mintable, got, err, want)
+				}
+			}
+
+			got, err := factory.BalanceOf(nil, sim.Addr(deployer), big.NewInt(i))
+			want := new(big.Int)
+			if mintable > 0 && i < numOptions {
+				want = math.MaxBig256
+			}
+			if err != nil || got.Cmp(want) != 0 {
+				t.Errorf("%T.BalanceOf(<owner>, %d) with %d mintable; got %d, err = %v; want %d, nil err", factory, i, mintable, got, err, want)
+			}
+		}
+	})
+
+	t.Run("option URI", func(t *testing.T) {
+		for i := int64(0); i < 3; i++ {
+			want := fmt.Sprintf("%s%d", baseURI, i)
+			got, err := factory.Uri(nil, big.NewInt(i))
+			if err != nil || got != want {
+				t.Errorf("%T.Uri(%d) got %q, err = %v; want %q, nil err", factory, i, got, err, want)
+			}
+		}
+	})
+
+	t.Run("schema name", func(t *testing.T) {
+		if got, err := factory.FactorySchemaName(nil); err != nil || got != "ERC1155" {
+			t.Errorf("%T.FactorySchemaName() got %q, err = %v; want %q, nil err", factory, got, err, "ERC1155")
+		}
+	})
+
+	t.Run("ownership transferred from deploying contract", func(t *testing.T) {
+		want := sim.Addr(deployer)
+		got, err := factory.Owner(nil)
+		if err != nil || got != want {
+			t.Errorf("%T.Owner() got %v, err = %v; want %v (deploying address, not primary contract), nil err", factory, got, err, want)
+		}
+	})
+}
+
+func TestERC1155TransferEvents(t *testing.T) {
+	const numOptions = 5
+	sim, nft, factory := deploy1155(t, numOptions, "")
+
+	sim.Must(t, "TransferOwnership()")(factory.TransferOwnership(sim.Acc(deployer), sim.Addr(newOwner)))
+
+	iter, err := factory.FilterTransferSingle(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("%T.FilterTransferSingle(nil, nil, nil, nil) error %v", factory, err)
+	}
+	defer iter.Close()
+
+	var got, want []*OpenSeaERC1155FactoryTransferSingle
+	for iter.Next() {
+		got = append(got, iter.Event)
+	}
+	if err := iter.Error(); err != nil {
+		t.Fatalf("%T.Error(): %v", iter, err)
+	}
+
+	nftAddr, err := factory.Token(nil)
+	if err != nil {
+		t.Fatalf("%T.Token() error %v", factory, err)
+	}
+
+	// As with the ERC721 factory, deployment and ownership transfer must each
+	// trigger a single event per option, with the respective operator.
+	for i := int64(0); i < numOptions; i++ {
+		want = append(want, &OpenSeaERC1155FactoryTransferSingle{
+			Operator: nftAddr,
+			From:     common.Address{},
+			To:       sim.Addr(deployer),
+			Id:       big.NewInt(i),
+			Value:    math.MaxBig256,
+		})
+	}
+	for i := int64(0); i < numOptions; i++ {
+		want = append(want, &OpenSeaERC1155FactoryTransferSingle{
+			Operator: sim.Addr(deployer),
+			From:     sim.Addr(deployer),
+			To:       sim.Addr(newOwner),
+			Id:       big.NewInt(i),
+			Value:    math.MaxBig256,
+		})
+	}
+
+	ignore := ethtest.Comparers(cmpopts.IgnoreFields(OpenSeaERC1155FactoryTransferSingle{}, "Raw"))
+
+	if diff := cmp.Diff(want, got, ignore...); diff != "" {
+		t.Errorf("After %T deployment by %T and single ownership transfer; TransferSingle events diff (-want +got):\n%s", factory, nft, diff)
+	}
+}
+
+func TestERC1155Mint(t *testing.T) {
+	const numOptions = 3
+	sim, nft, factory := deploy1155(t, numOptions, "")
+
+	tests := []struct {
+		name           string
+		contract       interface{} // only for error reporting
+		mint           func(*bind.TransactOpts, *big.Int, common.Address, *big.Int) (*types.Transaction, error)
+		mintAs         *bind.TransactOpts
+		mintOption     int64
+		mintTo         common.Address
+		mintAmount     int64
+		errDiffAgainst string
+	}{
+		{
+			name:           "factory.Mint() as end recipient",
+			contract:       factory,
+			mint:           factory.Mint,
+			mintAs:         sim.Acc(recipient0),
+			errDiffAgainst: "OpenSeaERC1155Factory: only owner or proxy",
+		},
+		{
+			name:           "nft.FactoryMint() as owner instead of factory",
+			contract:       nft,
+			mint:           nft.FactoryMint,
+			mintAs:         sim.Acc(deployer),
+			errDiffAgainst: "OpenSeaERC1155Mintable: only factory",
+		},
+		{
+			name:       "factory.Mint() as owner",
+			contract:   factory,
+			mint:       factory.Mint,
+			mintAs:     sim.Acc(deployer),
+			mintOption: 1,
+			mintTo:     sim.Addr(recipient0),
+			mintAmount: 4,
+		},
+		{
+			name:       "factory.Mint() as owner's proxy",
+			contract:   factory,
+			mint:       factory.Mint,
+			mintAs:     sim.Acc(proxy),
+			mintOption: 2,
+			mintTo:     sim.Addr(recipient1),
+			mintAmount: 1,
+		},
+		{
+			// Equivalent to the ERC721 factory's transferFrom().
+			name:     "factory.safeTransferFrom() propagates to mint()",
+			contract: factory,
+			mint: func(opts *bind.TransactOpts, optionID *big.Int, to common.Address, amount *big.Int) (*types.Transaction, error) {
+				return factory.SafeTransferFrom(opts, common.Address{}, to, optionID, amount, nil)
+			},
+			mintAs:     sim.Acc(proxy),
+			mintOption: 0,
+			mintTo:     sim.Addr(recipient2),
+			mintAmount: 7,
+		},
+		{
+			name:     "factory.safeTransferFrom() as end recipient",
+			contract: factory,
+			mint: func(opts *bind.TransactOpts, optionID *big.Int, to common.Address, amount *big.Int) (*types.Transaction, error) {
+				return factory.SafeTransferFrom(opts, common.Address{}, to, optionID, amount, nil)
+			},
+			mintAs:         sim.Acc(recipient2),
+			mintTo:         sim.Addr(recipient2),
+			errDiffAgainst: "OpenSeaERC1155Factory: only owner or proxy",
+		},
+	}
+
+	wantMinted := []TestableOpenSeaERC1155MintableMint{
+		{
+			OptionId: big.NewInt(1),
+			To:       sim.Addr(recipient0),
+			Amount:   big.NewInt(4),
+		},
+		{
+			OptionId: big.NewInt(2),
+			To:       sim.Addr(recipient1),
+			Amount:   big.NewInt(1),
+		},
+		{
+			OptionId: big.NewInt(0),
+			To:       sim.Addr(recipient2),
+			Amount:   big.NewInt(7),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.mint(tt.mintAs, big.NewInt(tt.mintOption), tt.mintTo, big.NewInt(tt.mintAmount))
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Errorf("%T.[Factory]Mint() %s", tt.contract, diff)
+			}
+		})
+	}
+
+	t.Run("factory.safeBatchTransferFrom()", func(t *testing.T) {
+		ids := []*big.Int{big.NewInt(2), big.NewInt(1)}
+		amounts := []*big.Int{big.NewInt(3), big.NewInt(5)}
+		sim.Must(t, "SafeBatchTransferFrom()")(factory.SafeBatchTransferFrom(sim.Acc(proxy), common.Address{}, sim.Addr(recipient0), ids, amounts, nil))
+
+		for i := range ids {
+			wantMinted = append(wantMinted, TestableOpenSeaERC1155MintableMint{
+				OptionId: ids[i],
+				To:       sim.Addr(recipient0),
+				Amount:   amounts[i],
+			})
+		}
+
+		_, err := factory.SafeBatchTransferFrom(sim.Acc(proxy), common.Address{}, sim.Addr(recipient0), ids, amounts[:1], nil)
+		if diff := errdiff.Check(err, "OpenSeaERC1155Factory: length mismatch"); diff != "" {
+			t.Errorf("%T.SafeBatchTransferFrom() with mismatched lengths; %s", factory, diff)
+		}
+	})
+
+	if t.Failed() {
+		return
+	}
+
+	n, err := nft.NumMinted(nil)
+	if err != nil {
+		t.Fatalf("%T.NumMinted() error %v", nft, err)
+	}
+	if !n.IsInt64() {
+		t.Fatalf("%T.NumMinted().IsInt64() got false; want true", nft)
+	}
+
+	var gotMinted []TestableOpenSeaERC1155MintableMint
+	for i := int64(0); i < n.Int64(); i++ {
+		got, err := nft.Mints(nil, big.NewInt(i))
+		if err != nil {
+			t.Fatalf("%T.Mints(%d) error %v", nft, i, err)
+		}
+		gotMinted = append(gotMinted, got)
+	}
+
+	if diff := cmp.Diff(wantMinted, gotMinted, ethtest.Comparers()...); diff != "" {
+		t.Errorf("All %T.Mints() after successful and blocked mints; (-want +got) diff:\n%s", nft, diff)
+	}
+}
+
+func TestERC1155MintPausing(t *testing.T) {
+	sim, nft, factory := deploy1155(t, 1, "")
+	sim.Must(t, "SetMintable(0, 1)")(nft.SetMintable(sim.Acc(deployer), big.NewInt(0), big.NewInt(1)))
+
+	mint := func() (*types.Transaction, error) {
+		return factory.Mint(sim.Acc(deployer), big.NewInt(0), sim.Addr(recipient0), big.NewInt(1))
+	}
+
+	sim.Must(t, "factory.Pause()")(factory.Pause(sim.Acc(deployer)))
+	if diff := revert.Paused.Diff(mint()); diff != "" {
+		t.Errorf("%T.Mint() when paused; %s", factory, diff)
+	}
+	if got, err := factory.CanMint(nil, big.NewInt(0), big.NewInt(1)); err != nil || got {
+		t.Errorf("%T.CanMint() when paused; got %t, err = %v; want false, nil err", factory, got, err)
+	}
+
+	sim.Must(t, "factory.Unpause()")(factory.Unpause(sim.Acc(deployer)))
+	if _, err := mint(); err != nil {
+		t.Errorf("%T.Mint() when not paused; error %v", factory, err)
+	}
+}
+
+func TestERC1155IsApprovedForAll(t *testing.T) {
+	sim, _, factory := deploy1155(t, 1, "")
+
+	tests := []struct {
+		owner, operator common.Address
+		want            bool
+	}{
+		{
+			owner:    sim.Addr(deployer),
+			operator: sim.Addr(deployer),
+			want:     true,
+		},
+		{
+			owner:    sim.Addr(deployer),
+			operator: sim.Addr(proxy),
+			want:     true,
+		},
+		{
+			owner:    sim.Addr(deployer),
+			operator: sim.Addr(vandal),
+			want:     false,
+		},
+		{
+			owner:    sim.Addr(vandal),
+			operator: sim.Addr(deployer),
+			want:     false,
+		},
+		{
+			owner:    sim.Addr(proxy),
+			operator: sim.Addr(proxy),
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := factory.IsApprovedForAll(nil, tt.owner, tt.operator)
+		if err != nil || got != tt.want {
+			t.Errorf("%T.IsApprovedForAll(%v, %v) got %t, err = %v; want %t, nil err", factory, tt.owner, tt.operator, got, err, tt.want)
+		}
+	}
+}
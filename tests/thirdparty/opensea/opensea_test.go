@@ -2,6 +2,7 @@ package opensea
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/big"
 	"testing"
@@ -342,3 +343,78 @@ func TestIsApprovedForAll(t *testing.T) {
 		}
 	}
 }
+
+func TestRegisterProxyAsUser(t *testing.T) {
+	ctx := context.Background()
+	sim, _, factory := deploy(t, 1, "")
+
+	registered := openseatest.RegisterProxyAsUserTB(t, sim, newOwner)
+	if registered == (common.Address{}) {
+		t.Fatalf("openseatest.RegisterProxyAsUser(newOwner) returned zero address")
+	}
+	if code, err := sim.CodeAt(ctx, registered, nil); err != nil || len(code) == 0 {
+		t.Errorf("CodeAt(<registered proxy>) got %d bytes, err = %v; want deployed code, nil err", len(code), err)
+	}
+
+	tests := []struct {
+		owner, operator common.Address
+		want            bool
+	}{
+		{
+			owner:    sim.Addr(newOwner),
+			operator: registered,
+			want:     true,
+		},
+		{
+			owner:    sim.Addr(newOwner),
+			operator: sim.Addr(proxy),
+			want:     false,
+		},
+		{
+			owner:    sim.Addr(deployer),
+			operator: registered,
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := factory.IsApprovedForAll(nil, tt.owner, tt.operator)
+		if err != nil || got != tt.want {
+			t.Errorf("%T.IsApprovedForAll(%v, %v) got %t, err = %v; want %t, nil err", factory, tt.owner, tt.operator, got, err, tt.want)
+		}
+	}
+
+	if _, err := openseatest.RegisterProxyAsUser(sim, newOwner); err == nil {
+		t.Errorf("openseatest.RegisterProxyAsUser(newOwner) when already registered; got nil error; want error")
+	}
+}
+
+func TestSimulateFactorySale(t *testing.T) {
+	sim, nft, factory := deploy(t, 3, "")
+
+	// The deployer's proxy is an EOA, set with SetProxy(), so ownership is
+	// transferred to an account with a Wyvern proxy contract.
+	openseatest.RegisterProxyAsUserTB(t, sim, newOwner)
+	sim.Must(t, "factory.TransferOwnership(newOwner)")(factory.TransferOwnership(sim.Acc(deployer), sim.Addr(newOwner)))
+
+	addr, err := nft.Factory(nil)
+	if err != nil {
+		t.Fatalf("%T.Factory() error %v", nft, err)
+	}
+	openseatest.SimulateFactorySale(t, sim, addr, big.NewInt(2), sim.Addr(recipient1))
+
+	want := TestableOpenSeaMintableMint{
+		OptionId: big.NewInt(2),
+		To:       sim.Addr(recipient1),
+	}
+	if n, err := nft.NumMinted(nil); err != nil || n.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("%T.NumMinted() got %d, err %v; want 1, nil err", nft, n, err)
+	}
+	got, err := nft.Mints(nil, big.NewInt(0))
+	if err != nil {
+		t.Fatalf("%T.Mints(0) error %v", nft, err)
+	}
+	if diff := cmp.Diff(want, got, ethtest.Comparers()...); diff != "" {
+		t.Errorf("%T.Mints(0) after openseatest.SimulateFactorySale(); (-want +got) diff:\n%s", nft, diff)
+	}
+}
@@ -0,0 +1,11 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../../contracts/erc721/ERC721ACommon.sol";
+
+/// @notice An ethier NFT, which includes ERC2981 royalties via ERC721ACommon.
+contract TestableERC2981 is ERC721ACommon {
+    // solhint-disable-next-line no-empty-blocks
+    constructor() ERC721ACommon("Token", "JRR") {}
+}
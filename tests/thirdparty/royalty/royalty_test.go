@@ -0,0 +1,62 @@
+package royalty
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/royaltytest"
+	"github.com/google/go-cmp/cmp"
+)
+
+//go:generate ethier gen TestableERC2981.sol
+
+const (
+	deployer = iota
+	artist
+	collaborator
+	numAccounts
+)
+
+func TestRoyaltyEngineLookup(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	royaltytest.DeployTB(t, sim)
+
+	addr, _, nft, err := DeployTestableERC2981(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableERC2981() error %v", err)
+	}
+	sim.Must(t, "SetDefaultRoyalty(artist, 500)")(nft.SetDefaultRoyalty(sim.Acc(deployer), sim.Addr(artist), big.NewInt(500)))
+	sim.Must(t, "SetTokenRoyalty(42, collaborator, 1000)")(nft.SetTokenRoyalty(sim.Acc(deployer), big.NewInt(42), sim.Addr(collaborator), big.NewInt(1000)))
+
+	tests := []struct {
+		tokenID int64
+		value   *big.Int
+		want    []royaltytest.Royalty
+	}{
+		{
+			tokenID: 0,
+			value:   eth.Ether(1),
+			want: []royaltytest.Royalty{{
+				Recipient: sim.Addr(artist),
+				Amount:    eth.EtherFraction(1, 20),
+			}},
+		},
+		{
+			tokenID: 42,
+			value:   eth.Ether(1),
+			want: []royaltytest.Royalty{{
+				Recipient: sim.Addr(collaborator),
+				Amount:    eth.EtherFraction(1, 10),
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := royaltytest.LookupTB(t, sim, addr, big.NewInt(tt.tokenID), tt.value)
+		if diff := cmp.Diff(tt.want, got, ethtest.Comparers()...); diff != "" {
+			t.Errorf("royaltytest.Lookup(<TestableERC2981>, %d, %d) diff (-want +got):\n%s", tt.tokenID, tt.value, diff)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package safe
+
+import (
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/safetest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/h-fam/errdiff"
+)
+
+//go:generate ethier gen ../../../contracts/utils/OwnerPausable.sol
+
+const (
+	deployer = iota
+	executor
+	numAccounts
+)
+
+func TestSafeOwnedContract(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	safetest.DeployTB(t, sim)
+
+	var (
+		owners []eth.SignerBackend
+		addrs  []common.Address
+	)
+	for i := 0; i < 3; i++ {
+		s, err := eth.NewSigner(256)
+		if err != nil {
+			t.Fatalf("eth.NewSigner(256) error %v", err)
+		}
+		owners = append(owners, s)
+		addrs = append(addrs, s.Address())
+	}
+	const threshold = 2
+	safe := safetest.NewSafeTB(t, sim, deployer, addrs, threshold)
+
+	t.Run("setup", func(t *testing.T) {
+		got, err := safe.GetThreshold(nil)
+		if err != nil || got.Int64() != threshold {
+			t.Errorf("%T.GetThreshold() got %d, err %v; want %d, nil err", safe, got, err, threshold)
+		}
+		for _, a := range addrs {
+			if ok, err := safe.IsOwner(nil, a); err != nil || !ok {
+				t.Errorf("%T.IsOwner(%v) got %t, err %v; want true, nil err", safe, a, ok, err)
+			}
+		}
+	})
+
+	addr, _, op, err := DeployOwnerPausable(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployOwnerPausable() error %v", err)
+	}
+	sim.Must(t, "TransferOwnership(<Safe>)")(op.TransferOwnership(sim.Acc(deployer), safe.Address))
+
+	parsed, err := OwnerPausableMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("%T.GetAbi() error %v", OwnerPausableMetaData, err)
+	}
+	pause, err := parsed.Pack("pause")
+	if err != nil {
+		t.Fatalf("%T.Pack(\"pause\") error %v", parsed, err)
+	}
+	tx := safetest.Transaction{
+		To:   addr,
+		Data: pause,
+	}
+
+	tests := []struct {
+		name string
+		// signers are indices into owners, deliberately not in address order
+		// to test sorting of signatures.
+		signers        []int
+		errDiffAgainst interface{}
+		wantPaused     bool
+	}{
+		{
+			name:           "below threshold",
+			signers:        []int{2},
+			errDiffAgainst: "GS020", // Signatures data too short
+		},
+		{
+			name:       "at threshold",
+			signers:    []int{2, 0},
+			wantPaused: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var signers []eth.SignerBackend
+			for _, i := range tt.signers {
+				signers = append(signers, owners[i])
+			}
+
+			_, err := safe.ExecTransaction(executor, tx, signers...)
+			if diff := errdiff.Check(err, tt.errDiffAgainst); diff != "" {
+				t.Fatalf("%T.ExecTransaction(<pause>, %d signers); %s", safe, len(signers), diff)
+			}
+
+			if got, err := op.Paused(nil); err != nil || got != tt.wantPaused {
+				t.Errorf("%T.Paused() got %t, err %v; want %t, nil err", op, got, err, tt.wantPaused)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package seaport
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/eth/seaport"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/openseatest"
+	"github.com/divergencetech/ethier/ethtest/openseatest/seaporttest"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+//go:generate ethier gen TestableSeaportERC721.sol
+
+const (
+	deployer = iota
+	buyer
+	marketplace
+	numAccounts
+)
+
+func TestBasicOrder(t *testing.T) {
+	ctx := context.Background()
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	openseatest.DeployProxyRegistryTB(t, sim)
+	seaporttest.DeploySeaportTB(t, sim)
+
+	seller, err := eth.NewDeterministicSigner([]byte("seller"))
+	if err != nil {
+		t.Fatalf("eth.NewDeterministicSigner() error %v", err)
+	}
+	sellerOpts, err := seller.TransactOptsWithChainID(ctx, sim.Blockchain().Config().ChainID)
+	if err != nil {
+		t.Fatalf("%T.TransactOptsWithChainID() error %v", seller, err)
+	}
+	// The seller needs ETH to pay for approving Seaport.
+	sim.Must(t, "fund seller")(
+		bind.NewBoundContract(seller.Address(), abi.ABI{}, sim, sim, sim).Transfer(sim.WithValueFrom(deployer, eth.Ether(1))),
+	)
+
+	nftAddr, _, nft, err := DeployTestableSeaportERC721(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableSeaportERC721() error %v", err)
+	}
+	sim.Must(t, "Mint(seller, 1)")(nft.Mint(sim.Acc(deployer), seller.Address(), big.NewInt(1)))
+	sim.Must(t, "SetApprovalForAll(Seaport, true)")(nft.SetApprovalForAll(sellerOpts, seaporttest.Addresses().Seaport, true))
+
+	const feeBasisPoints = 250
+	tokenID := big.NewInt(0)
+	price := eth.Ether(2)
+	order := seaporttest.NewListingTB(t, sim, seller, nftAddr, tokenID, price, seaport.Fee{
+		Recipient:   sim.Addr(marketplace),
+		BasisPoints: feeBasisPoints,
+	})
+
+	sellerBefore := sim.BalanceOf(ctx, t, seller.Address())
+	marketplaceBefore := sim.BalanceOf(ctx, t, sim.Addr(marketplace))
+	seaporttest.FulfillBasicOrderTB(t, sim, buyer, order)
+
+	if got, err := nft.OwnerOf(nil, tokenID); err != nil || got != sim.Addr(buyer) {
+		t.Errorf("%T.OwnerOf(%d) after fulfilling order got %v, err %v; want buyer %v, nil err", nft, tokenID, got, err, sim.Addr(buyer))
+	}
+
+	fee := new(big.Int).Mul(price, big.NewInt(feeBasisPoints))
+	fee.Div(fee, big.NewInt(10000))
+	proceeds := new(big.Int).Sub(price, fee)
+
+	for _, tt := range []struct {
+		name   string
+		before *big.Int
+		after  *big.Int
+		want   *big.Int
+	}{
+		{
+			name:   "seller",
+			before: sellerBefore,
+			after:  sim.BalanceOf(ctx, t, seller.Address()),
+			want:   proceeds,
+		},
+		{
+			name:   "marketplace",
+			before: marketplaceBefore,
+			after:  sim.BalanceOf(ctx, t, sim.Addr(marketplace)),
+			want:   fee,
+		},
+	} {
+		if got := new(big.Int).Sub(tt.after, tt.before); got.Cmp(tt.want) != 0 {
+			t.Errorf("%s balance increased by %d; want %d", tt.name, got, tt.want)
+		}
+	}
+
+	t.Run("refulfill filled order", func(t *testing.T) {
+		if _, err := seaporttest.FulfillBasicOrder(sim, buyer, order); err == nil {
+			t.Errorf("seaporttest.FulfillBasicOrder(<filled order>) got nil error; want error")
+		}
+	})
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+interface IUniswapV2Pair {
+    function getReserves()
+        external
+        view
+        returns (
+            uint112,
+            uint112,
+            uint32
+        );
+}
+
+interface IUniswapV3Pool {
+    function slot0()
+        external
+        view
+        returns (
+            uint160,
+            int24,
+            uint16,
+            uint16,
+            uint16,
+            uint8,
+            bool
+        );
+}
+
+/// @notice Quotes amounts of token1 for amounts of token0 from DEX prices.
+contract TestableDEXQuoter {
+    /// @notice Spot price from the V2 pair's reserves, ignoring fees.
+    function quoteV2(IUniswapV2Pair pair, uint256 amount0)
+        external
+        view
+        returns (uint256)
+    {
+        (uint112 reserve0, uint112 reserve1, ) = pair.getReserves();
+        return (amount0 * reserve1) / reserve0;
+    }
+
+    /**
+    @notice Spot price from the V3 pool's square-root price.
+    @dev Only suitable for prices such that sqrtPriceX96^2 >> 96 doesn't
+    overflow.
+     */
+    function quoteV3(IUniswapV3Pool pool, uint256 amount0)
+        external
+        view
+        returns (uint256)
+    {
+        (uint160 sqrtPriceX96, , , , , , ) = pool.slot0();
+        uint256 priceX96 = (uint256(sqrtPriceX96) * sqrtPriceX96) >> 96;
+        return (amount0 * priceX96) >> 96;
+    }
+}
@@ -0,0 +1,132 @@
+package uniswap
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/eth"
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/dextest"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//go:generate ethier gen TestableDEXQuoter.sol
+
+const (
+	deployer = iota
+	numAccounts
+)
+
+var (
+	weth = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	usdc = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+)
+
+func deploy(t *testing.T) (*ethtest.SimulatedBackend, *TestableDEXQuoter) {
+	t.Helper()
+
+	sim := ethtest.NewSimulatedBackendTB(t, numAccounts)
+	_, _, q, err := DeployTestableDEXQuoter(sim.Acc(deployer), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableDEXQuoter() error %v", err)
+	}
+	return sim, q
+}
+
+func TestSortTokens(t *testing.T) {
+	for _, in := range [][2]common.Address{{weth, usdc}, {usdc, weth}} {
+		t0, t1 := dextest.SortTokens(in[0], in[1])
+		if t0 != usdc || t1 != weth {
+			t.Errorf("SortTokens(%v, %v) got (%v, %v); want (%v, %v)", in[0], in[1], t0, t1, usdc, weth)
+		}
+	}
+}
+
+func TestV2Pair(t *testing.T) {
+	sim, q := deploy(t)
+
+	t.Run("unsorted tokens", func(t *testing.T) {
+		if _, err := dextest.DeployV2Pair(sim, deployer, weth, usdc, big.NewInt(1), big.NewInt(1)); err == nil {
+			t.Errorf("DeployV2Pair(<unsorted tokens>) got nil error; want error")
+		}
+	})
+
+	// 1 USDC (6 decimals) = 0.001 ETH
+	pair := dextest.DeployV2PairTB(t, sim, deployer, usdc, weth, big.NewInt(1e12), eth.Ether(1e3))
+
+	tests := []struct {
+		name               string
+		reserve0, reserve1 *big.Int // if nil, not set
+		want               *big.Int
+	}{
+		{
+			name: "initial reserves",
+			want: eth.EtherFraction(1, 1000),
+		},
+		{
+			name:     "updated reserves",
+			reserve0: big.NewInt(1e12),
+			reserve1: eth.Ether(2e3),
+			want:     eth.EtherFraction(2, 1000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.reserve0 != nil {
+				pair.SetReserves(t, tt.reserve0, tt.reserve1)
+			}
+			got, err := q.QuoteV2(nil, pair.Address, big.NewInt(1e6))
+			if err != nil || got.Cmp(tt.want) != 0 {
+				t.Errorf("QuoteV2(1 USDC) got %d, err %v; want %d, nil err", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestV3Pool(t *testing.T) {
+	sim, q := deploy(t)
+
+	t0, t1 := dextest.SortTokens(weth, usdc)
+	pool := dextest.DeployV3PoolTB(t, sim, deployer, t0, t1, 3000, 0)
+
+	tests := []struct {
+		tick int
+		want *big.Int
+	}{
+		{
+			tick: 0,
+			want: eth.Ether(1),
+		},
+		{
+			// 1.0001^6932 ≈ 2.00004
+			tick: 6932,
+			want: eth.Ether(2),
+		},
+		{
+			tick: -6932,
+			want: eth.EtherFraction(1, 2),
+		},
+	}
+
+	tolerance := eth.EtherFraction(1, 10000)
+	for _, tt := range tests {
+		pool.SetTick(t, tt.tick)
+
+		s, err := pool.Slot0(nil)
+		if err != nil {
+			t.Fatalf("%T.Slot0() error %v", pool, err)
+		}
+		if s.Tick.Int64() != int64(tt.tick) || s.SqrtPriceX96.Cmp(dextest.SqrtPriceX96AtTick(tt.tick)) != 0 {
+			t.Errorf("%T.SetTick(%d); Slot0() got tick %d, sqrtPriceX96 %d; want %d, %d", pool, tt.tick, s.Tick, s.SqrtPriceX96, tt.tick, dextest.SqrtPriceX96AtTick(tt.tick))
+		}
+
+		got, err := q.QuoteV3(nil, pool.Address, eth.Ether(1))
+		if err != nil {
+			t.Fatalf("QuoteV3() error %v", err)
+		}
+		if diff := new(big.Int).Sub(got, tt.want); diff.CmpAbs(tolerance) > 0 {
+			t.Errorf("SetTick(%d); QuoteV3(1e18) got %d; want %d ± %d", tt.tick, got, tt.want, tolerance)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT
+// Copyright (c) 2022 the ethier authors (github.com/divergencetech/ethier)
+pragma solidity >=0.8.0 <0.9.0;
+
+import "../../contracts/utils/SVG.sol";
+import "@openzeppelin/contracts/utils/Strings.sol";
+
+/// @notice Renders fully on-chain metadata with SVG, for golden-file tests.
+contract TestableSVG {
+    using Strings for uint256;
+
+    function image(uint256 tokenId) public pure returns (string memory) {
+        string memory fill = SVG.rgb(
+            uint8(tokenId * 40),
+            uint8(255 - (tokenId % 256)),
+            uint8(tokenId * 7)
+        );
+
+        return
+            SVG.svg(
+                100,
+                100,
+                string(
+                    abi.encodePacked(
+                        SVG.element(
+                            "rect",
+                            string(
+                                abi.encodePacked(
+                                    SVG.attr("width", 100),
+                                    SVG.attr("height", 100),
+                                    SVG.attr("fill", fill)
+                                )
+                            )
+                        ),
+                        SVG.element(
+                            "text",
+                            string(
+                                abi.encodePacked(
+                                    SVG.attr("x", 50),
+                                    SVG.attr("y", 50)
+                                )
+                            ),
+                            tokenId.toString()
+                        )
+                    )
+                )
+            );
+    }
+
+    function tokenURI(uint256 tokenId) public pure returns (string memory) {
+        return
+            SVG.jsonDataURI(
+                string(
+                    abi.encodePacked(
+                        '{"name":"Token #',
+                        tokenId.toString(),
+                        '","description":"On-chain SVG test token.","image":"',
+                        SVG.dataURI(image(tokenId)),
+                        '"}'
+                    )
+                )
+            );
+    }
+}
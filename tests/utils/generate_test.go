@@ -1,3 +1,3 @@
 package utils
 
-//go:generate ethier gen ../../contracts/utils/OwnerPausable.sol TestableDynamicBuffer.sol
+//go:generate ethier gen ../../contracts/utils/OwnerPausable.sol TestableDynamicBuffer.sol TestableSVG.sol
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/divergencetech/ethier/ethtest"
+	"github.com/divergencetech/ethier/ethtest/goldentest"
+)
+
+func TestSVGGolden(t *testing.T) {
+	sim := ethtest.NewSimulatedBackendTB(t, 1)
+	_, _, svg, err := DeployTestableSVG(sim.Acc(0), sim)
+	if err != nil {
+		t.Fatalf("DeployTestableSVG() error %v", err)
+	}
+
+	for _, id := range []int64{0, 1, 42} {
+		goldentest.CheckTokenURI(t, svg, big.NewInt(id), "testdata/svg")
+	}
+}